@@ -23,6 +23,30 @@ CORTEX_VERSION=master
 
 dir=$1
 image=$2
+shift 2
+version_args=("$@")  # e.g. TF_SERVING_VERSION=1.15.0, which builds cortexlabs/$image:$CORTEX_VERSION-1.15.0 (PYTHON_VERSION=3.8 is tagged as py3.8, and ARCH=arm64 builds the image for arm64 and is tagged as arm64)
 
-docker build "$ROOT" -f $dir/Dockerfile -t cortexlabs/$image \
-                                        -t cortexlabs/$image:$CORTEX_VERSION
+if [ ${#version_args[@]} -gt 0 ]; then
+  build_args=""
+  tag=$CORTEX_VERSION
+  for version_arg in "${version_args[@]}"; do
+    version=${version_arg#*=}
+    if [[ "$version_arg" == ARCH=* ]]; then
+      build_args="$build_args --platform linux/$version"
+    else
+      build_args="$build_args --build-arg $version_arg"
+    fi
+    if [[ "$version_arg" == PYTHON_VERSION=* ]]; then
+      version=py$version
+    fi
+    tag=$tag-$version
+  done
+  if [[ "$build_args" == *--platform* ]]; then
+    docker buildx build "$ROOT" -f $dir/Dockerfile $build_args --load -t cortexlabs/$image:$tag
+  else
+    docker build "$ROOT" -f $dir/Dockerfile $build_args -t cortexlabs/$image:$tag
+  fi
+else
+  docker build "$ROOT" -f $dir/Dockerfile -t cortexlabs/$image \
+                                          -t cortexlabs/$image:$CORTEX_VERSION
+fi
@@ -20,7 +20,8 @@ set -euo pipefail
 CORTEX_VERSION=master
 
 image=$1
+version=${2:-""}  # the versions of the image's serving runtime and python, if they aren't the default versions (e.g. 1.15.0, py3.8, or 1.0.0-py3.7), and its architecture if it isn't amd64 (e.g. arm64)
 
 echo "$DOCKER_PASSWORD" | docker login -u "$DOCKER_USERNAME" --password-stdin
 
-docker push cortexlabs/$image:$CORTEX_VERSION
+docker push cortexlabs/$image:$CORTEX_VERSION${version:+-$version}
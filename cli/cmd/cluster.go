@@ -21,15 +21,18 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
 	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
 	"github.com/cortexlabs/cortex/pkg/lib/json"
 	"github.com/cortexlabs/cortex/pkg/lib/prompt"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/table"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
@@ -204,9 +207,162 @@ var infoCmd = &cobra.Command{
 		items.AddAll(infoResponse.ClusterConfig.UserFacingTable())
 
 		items.Print()
+
+		costsResponse, err := getCostsResponse()
+		if err != nil {
+			fmt.Println("\n" + errors.Wrap(err, "unable to estimate costs").Error())
+		} else {
+			fmt.Println("\n" + strings.TrimSuffix(costsStr(costsResponse), "\n"))
+		}
+
+		capacityResponse, err := getCapacityResponse()
+		if err != nil {
+			fmt.Println("\n" + errors.Wrap(err, "unable to get the cluster's capacity").Error())
+			return
+		}
+		fmt.Println("\n" + capacityStr(capacityResponse))
+
+		quotasResponse, err := getQuotasResponse()
+		if err != nil {
+			fmt.Println("\n" + errors.Wrap(err, "unable to get the cluster's quotas").Error())
+			return
+		}
+		if len(quotasResponse.Quotas) > 0 {
+			fmt.Println("\n" + quotasStr(quotasResponse))
+		}
 	},
 }
 
+func getCostsResponse() (*schema.CostsResponse, error) {
+	httpResponse, err := HTTPGet("/costs")
+	if err != nil {
+		return nil, err
+	}
+	var costsResponse schema.CostsResponse
+	if err := json.Unmarshal(httpResponse, &costsResponse); err != nil {
+		return nil, errors.Wrap(err, "/costs", string(httpResponse))
+	}
+	return &costsResponse, nil
+}
+
+func costsStr(costs *schema.CostsResponse) string {
+	out := fmt.Sprintf("%s ~%s per hour (~%s per month, with on-demand pricing)\n", console.Bold("estimated cost:"), s.DollarsAndCents(costs.Hourly), s.DollarsAndCents(costs.Monthly))
+	for _, instance := range costs.Instances {
+		out += fmt.Sprintf("￮ %s worker instances (%d): %s per hour\n", instance.InstanceType, instance.Count, s.DollarsAndTenthsOfCents(instance.Hourly))
+	}
+	out += fmt.Sprintf("￮ the eks cluster, operator, load balancers, and nat gateway: %s per hour\n", s.DollarsAndTenthsOfCents(costs.FixedHourly))
+
+	if len(costs.APIs) == 0 {
+		return out
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "deployment"},
+			{Title: "api"},
+			{Title: "replicas"},
+			{Title: "per hour"},
+			{Title: "per month"},
+		},
+	}
+	for _, api := range costs.APIs {
+		t.Rows = append(t.Rows, []interface{}{api.AppName, api.APIName, api.Replicas, s.DollarsAndTenthsOfCents(api.Hourly), s.DollarsAndCents(api.Monthly)})
+	}
+	t.Rows = append(t.Rows, []interface{}{"", "(unrequested compute)", "", s.DollarsAndTenthsOfCents(costs.IdleHourly), s.DollarsAndCents(costs.IdleHourly * clusterconfig.HoursPerMonth)})
+
+	return out + "\n" + table.MustFormat(t)
+}
+
+func getCapacityResponse() (*schema.CapacityResponse, error) {
+	httpResponse, err := HTTPGet("/cluster/capacity")
+	if err != nil {
+		return nil, err
+	}
+	var capacityResponse schema.CapacityResponse
+	if err := json.Unmarshal(httpResponse, &capacityResponse); err != nil {
+		return nil, errors.Wrap(err, "/cluster/capacity", string(httpResponse))
+	}
+	return &capacityResponse, nil
+}
+
+func capacityStr(capacity *schema.CapacityResponse) string {
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "node group"},
+			{Title: "instance type"},
+			{Title: "arch"},
+			{Title: "cpu"},
+			{Title: "memory"},
+			{Title: "gpu"},
+			{Title: "reserved cpu"},
+			{Title: "reserved memory"},
+		},
+	}
+	for _, nodeGroup := range capacity.NodeGroups {
+		t.Rows = append(t.Rows, []interface{}{nodeGroup.Name, nodeGroup.InstanceType, nodeGroup.Arch, nodeGroup.CPU, nodeGroup.Mem, nodeGroup.GPU, nodeGroup.CPUReserve, nodeGroup.MemReserve})
+	}
+
+	return console.Bold("schedulable compute per node:") + "\n" + table.MustFormat(t)
+}
+
+func getQuotasResponse() (*schema.QuotasResponse, error) {
+	httpResponse, err := HTTPGet("/cluster/quotas")
+	if err != nil {
+		return nil, err
+	}
+	var quotasResponse schema.QuotasResponse
+	if err := json.Unmarshal(httpResponse, &quotasResponse); err != nil {
+		return nil, errors.Wrap(err, "/cluster/quotas", string(httpResponse))
+	}
+	return &quotasResponse, nil
+}
+
+func quotasStr(quotas *schema.QuotasResponse) string {
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "quota"},
+			{Title: "cpu"},
+			{Title: "memory"},
+			{Title: "gpu"},
+			{Title: "replicas"},
+		},
+	}
+	for _, quota := range quotas.Quotas {
+		name := "deployment " + quota.Deployment
+		if quota.Owner != "" {
+			name = "owner " + quota.Owner
+		}
+		t.Rows = append(t.Rows, []interface{}{
+			name,
+			quotaUsageStr(quota.Used.CPU, quota.Limit.CPU),
+			quotaUsageStr(quota.Used.Mem, quota.Limit.Mem),
+			quotaUsageStr(int64PtrStr(quota.Used.GPU), int64PtrStr(quota.Limit.GPU)),
+			quotaUsageStr(int64PtrStr(quota.Used.Replicas), int64PtrStr(quota.Limit.Replicas)),
+		})
+	}
+
+	return console.Bold("quotas (requested at maximum replicas / limit):") + "\n" + table.MustFormat(t)
+}
+
+func quotaUsageStr(used *string, limit *string) string {
+	usedStr, limitStr := "0", "-"
+	if used != nil {
+		usedStr = *used
+	}
+	if limit != nil {
+		limitStr = *limit
+	}
+	return usedStr + " / " + limitStr
+}
+
+func int64PtrStr(val *int64) *string {
+	if val == nil {
+		return nil
+	}
+	str := s.Int64(*val)
+	return &str
+}
+
 var downCmd = &cobra.Command{
 	Use:   "down",
 	Short: "spin down a cluster",
@@ -61,6 +61,7 @@ var configureCmd = &cobra.Command{
 				items.Add("environment", flagEnv)
 			}
 			items.Add("cortex operator endpoint", cliEnvConfig.OperatorEndpoint)
+			items.Add("cortex cluster name", cliEnvConfig.ClusterName)
 			items.Add("aws access key id", cliEnvConfig.AWSAccessKeyID)
 			items.Add("aws secret access key", s.MaskString(cliEnvConfig.AWSSecretAccessKey, 4))
 
@@ -72,7 +72,7 @@ var deleteCmd = &cobra.Command{
 			"appName":   appName,
 			"keepCache": s.Bool(flagKeepCache),
 		}
-		httpResponse, err := HTTPPostJSONData("/delete", nil, params)
+		httpResponse, err := HTTPPostJSONData("/v1/delete", nil, params)
 		if err != nil {
 			exit.Error(err)
 		}
@@ -80,8 +80,9 @@ var deleteCmd = &cobra.Command{
 		var deleteResponse schema.DeleteResponse
 		err = json.Unmarshal(httpResponse, &deleteResponse)
 		if err != nil {
-			exit.Error(err, "/delete", string(httpResponse))
+			exit.Error(err, "/v1/delete", string(httpResponse))
 		}
+		deleteRevisions(appName)
 		fmt.Println(console.Bold(deleteResponse.Message))
 	},
 }
@@ -18,31 +18,46 @@ package cmd
 
 import (
 	"fmt"
-	"io/ioutil"
-	"path/filepath"
+	"os"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
 
-	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	"github.com/cortexlabs/cortex/pkg/lib/console"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
 	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
-	"github.com/cortexlabs/cortex/pkg/lib/zip"
 	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
 )
 
 var MaxProjectSize = 1024 * 1024 * 50
 var flagDeployForce bool
+var flagDeployPrune bool
+var flagDeployDryRun bool
+var flagDeployWait bool
 var flagDeployRefresh bool
+var flagDeployCheckRequirements bool
+var flagDeployValues []string
+var flagDeployFreezeOverride string
+var flagDeployFederated bool
 
 func init() {
-	deployCmd.PersistentFlags().BoolVarP(&flagDeployForce, "force", "f", false, "override the in-progress deployment update")
+	deployCmd.PersistentFlags().BoolVarP(&flagDeployForce, "force", "f", false, "override the in-progress deployment update, and any changes which have been deployed by others since your last deploy")
+	deployCmd.PersistentFlags().BoolVar(&flagDeployPrune, "prune", true, "delete the deployment's apis which are no longer defined in your configuration (with --prune=false, the deploy is rejected if there are any)")
+	deployCmd.PersistentFlags().BoolVar(&flagDeployDryRun, "dry-run", false, "validate your configuration and list the apis which would be created, updated, and deleted, without deploying")
+	deployCmd.PersistentFlags().BoolVarP(&flagDeployWait, "wait", "w", false, "show the progress of the deployment's rollout, and wait for its apis to be ready")
 	deployCmd.PersistentFlags().BoolVarP(&flagDeployRefresh, "refresh", "r", false, "re-deploy all apis with cleared cache and rolling updates")
+	deployCmd.PersistentFlags().BoolVar(&flagDeployCheckRequirements, "check-requirements", false, "resolve the packages in requirements.txt in the cluster before deploying")
+	deployCmd.PersistentFlags().StringVar(&flagDeployFreezeOverride, "freeze-override", "", "the cluster's deploy freeze override token, to deploy while a deploy freeze window is active")
+	deployCmd.PersistentFlags().BoolVar(&flagDeployFederated, "federated", false, "deploy to the cluster and to each of its federated clusters which your apis select (see the clusters field)")
+	deployCmd.PersistentFlags().StringSliceVar(&flagDeployValues, "values", nil, "path to a YAML file of values for the variables in cortex.yaml (can be specified multiple times; later files take precedence)")
 	addEnvFlag(deployCmd)
 }
 
@@ -52,73 +67,131 @@ var deployCmd = &cobra.Command{
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		telemetry.EventNotify("cli.deploy")
-		deploy(flagDeployForce, flagDeployRefresh)
+		deploy(flagDeployForce, flagDeployPrune, flagDeployDryRun, flagDeployWait, flagDeployRefresh, flagDeployCheckRequirements)
 	},
 }
 
-func deploy(force bool, ignoreCache bool) {
+func deploy(force bool, prune bool, dryRun bool, wait bool, ignoreCache bool, checkRequirements bool) {
+	if flagDeployFederated && wait {
+		exit.Error(ErrorIncompatibleWithFederated("--wait"))
+	}
+
 	root := mustAppRoot()
-	_, err := readConfig() // Check proper cortex.yaml
+	config, err := readConfig() // Check proper cortex.yaml
 	if err != nil {
 		exit.Error(err)
 	}
 
 	params := map[string]string{
-		"force":       s.Bool(force),
-		"ignoreCache": s.Bool(ignoreCache),
+		"force":             s.Bool(force),
+		"prune":             s.Bool(prune),
+		"dryRun":            s.Bool(dryRun),
+		"ignoreCache":       s.Bool(ignoreCache),
+		"checkRequirements": s.Bool(checkRequirements),
+	}
+
+	uploadBytes, err := readConfigFiles(root)
+	if err != nil {
+		exit.Error(err)
 	}
 
-	configBytes, err := ioutil.ReadFile(filepath.Join(root, "cortex.yaml"))
+	variables, err := configVariables(uploadBytes)
 	if err != nil {
-		exit.Error(errors.Wrap(err, "cortex.yaml", cr.ErrorReadConfig().Error()))
+		exit.Error(err)
+	}
+	variablesBytes, err := json.Marshal(variables)
+	if err != nil {
+		exit.Error(err)
 	}
+	uploadBytes["variables.json"] = variablesBytes
 
-	uploadBytes := map[string][]byte{
-		"cortex.yaml": configBytes,
+	// the token is uploaded as a file rather than a query parameter so that it isn't logged with the request's URL
+	if flagDeployFreezeOverride != "" {
+		uploadBytes["freeze_override"] = []byte(flagDeployFreezeOverride)
 	}
 
-	projectPaths, err := files.ListDirRecursive(root, false,
-		files.IgnoreCortexYAML,
-		files.IgnoreCortexDebug,
-		files.IgnoreHiddenFiles,
-		files.IgnoreHiddenFolders,
-		files.IgnorePythonGeneratedFiles,
-	)
+	// the revisions are of the apis in the environment's cluster, so they aren't checked by federated deploys
+	if revisionsBytes := readRevisions(config.App.Name); revisionsBytes != nil && !flagDeployFederated {
+		uploadBytes["revisions.json"] = revisionsBytes
+	}
+
+	var include, exclude []string
+	if config.App.Project != nil {
+		include, exclude = config.App.Project.Include, config.App.Project.Exclude
+	}
+	ignoreFns, err := files.ProjectIgnoreFns(root, include, exclude)
 	if err != nil {
 		exit.Error(err)
 	}
 
-	projectZipBytes, err := zip.ToMem(&zip.Input{
-		FileLists: []zip.FileListInput{
-			{
-				Sources:      projectPaths,
-				RemovePrefix: root,
-			},
-		},
-	})
+	projectPaths, err := files.ListDirRecursive(root, false, ignoreFns...)
+	if err != nil {
+		exit.Error(err)
+	}
+
+	// only the files which the operator hasn't stored yet are uploaded
+	manifest, fileContents, err := projectManifest(projectPaths, root)
+	if err != nil {
+		exit.Error(err)
+	}
 
+	missingHashes, err := missingProjectFiles(fileContents)
 	if err != nil {
-		exit.Error(errors.Wrap(err, "failed to zip project folder"))
+		exit.Error(err)
 	}
 
-	if len(projectZipBytes) > MaxProjectSize {
-		exit.Error(errors.New("zipped project folder exceeds " + s.Int(MaxProjectSize) + " bytes"))
+	var uploadSize int64
+	for _, fileHash := range missingHashes {
+		uploadBytes[userconfig.ProjectFilesDirName+"/"+fileHash] = fileContents[fileHash]
+		uploadSize += int64(len(fileContents[fileHash]))
+	}
+	missingHashSet := strset.New(missingHashes...)
+	var uploadPaths []string
+	for _, path := range projectPaths {
+		if missingHashSet.Has(manifest[files.TrimDirPrefix(path, root)]) {
+			uploadPaths = append(uploadPaths, path)
+		}
+	}
+	if uploadSize > int64(MaxProjectSize) {
+		exit.Error(ErrorProjectZipTooLarge(uploadSize, int64(MaxProjectSize), largestFiles(uploadPaths, root, 5)))
 	}
 
-	uploadBytes["project.zip"] = projectZipBytes
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		exit.Error(err)
+	}
+	uploadBytes[userconfig.ProjectManifestFileName] = manifestBytes
 
 	uploadInput := &HTTPUploadInput{
 		Bytes: uploadBytes,
 	}
 
-	response, err := HTTPUpload("/deploy", uploadInput, params)
+	if flagDeployFederated {
+		federatedDeploy(uploadInput, params)
+		return
+	}
+
+	response, err := HTTPUpload("/v1/deploy", uploadInput, params)
 	if err != nil {
 		exit.Error(err)
 	}
 
 	var deployResponse schema.DeployResponse
 	if err := json.Unmarshal(response, &deployResponse); err != nil {
-		exit.Error(err, "/deploy", string(response))
+		exit.Error(err, "/v1/deploy", string(response))
+	}
+
+	if deployResponse.Context != nil {
+		if err := saveRevisions(deployResponse.Context); err != nil {
+			fmt.Println("warning: " + err.Error())
+		}
+	}
+
+	for _, warning := range deployResponse.Warnings {
+		fmt.Println("warning: " + warning)
+	}
+	if len(deployResponse.Warnings) > 0 {
+		fmt.Println()
 	}
 
 	msgParts := strings.Split(deployResponse.Message, "\n\n")
@@ -126,4 +199,129 @@ func deploy(force bool, ignoreCache bool) {
 	if len(msgParts) > 1 {
 		fmt.Println("\n" + strings.Join(msgParts[1:], "\n\n"))
 	}
+
+	// the context is only returned if the deployment was updated
+	if wait && deployResponse.Context != nil {
+		waitForDeploy(deployResponse.Context.App.Name)
+	}
+}
+
+// federatedDeploy deploys to the environment's cluster and its federated clusters, and prints the result of each cluster's deploy
+func federatedDeploy(uploadInput *HTTPUploadInput, params map[string]string) {
+	response, err := HTTPUpload("/v1/federation/deploy", uploadInput, params)
+	if err != nil {
+		exit.Error(err)
+	}
+
+	var federatedResponse schema.FederatedDeployResponse
+	if err := json.Unmarshal(response, &federatedResponse); err != nil {
+		exit.Error(err, "/v1/federation/deploy", string(response))
+	}
+
+	failed := false
+	for i, result := range federatedResponse.Clusters {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(console.Bold("cluster " + result.Cluster))
+
+		switch {
+		case result.Skipped:
+			fmt.Println("skipped (none of the apis select this cluster)")
+		case result.Error != "":
+			failed = true
+			fmt.Println("error: " + result.Error)
+		case result.Response != nil:
+			for _, warning := range result.Response.Warnings {
+				fmt.Println("warning: " + warning)
+			}
+			fmt.Println(result.Response.Message)
+		}
+	}
+
+	if failed {
+		exit.ErrorNoPrintNoTelemetry()
+	}
+}
+
+// waitForDeploy prints the progress of the deployment's rollout until each of its apis is ready or has failed
+func waitForDeploy(appName string) {
+	fmt.Println()
+	doneEvent, err := StreamDeployProgress(appName, func(event *schema.DeployProgressEvent) {
+		if event.Replica != "" {
+			fmt.Printf("%s %s (replica %s): %s\n", event.Time.Local().Format("15:04:05"), event.APIName, event.Replica, event.Message)
+		} else {
+			fmt.Printf("%s %s: %s\n", event.Time.Local().Format("15:04:05"), event.APIName, event.Message)
+		}
+	})
+	if err != nil {
+		exit.Error(err)
+	}
+
+	fmt.Println("\n" + console.Bold(doneEvent.Message))
+	if doneEvent.Failed {
+		exit.ErrorNoPrintNoTelemetry()
+	}
+}
+
+// projectManifest maps each of the project's files (relative to root) to the hash of its contents, and returns the contents of the files (keyed by hash)
+func projectManifest(projectPaths []string, root string) (map[string]string, map[string][]byte, error) {
+	manifest := make(map[string]string, len(projectPaths))
+	fileContents := make(map[string][]byte, len(projectPaths))
+	for _, path := range projectPaths {
+		fileBytes, err := files.ReadFileBytes(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		fileHash := hash.Bytes(fileBytes)
+		manifest[files.TrimDirPrefix(path, root)] = fileHash
+		fileContents[fileHash] = fileBytes
+	}
+	return manifest, fileContents, nil
+}
+
+// missingProjectFiles returns the hashes of the files which the operator hasn't stored yet
+func missingProjectFiles(fileContents map[string][]byte) ([]string, error) {
+	request := schema.ProjectFilesRequest{
+		Hashes: make([]string, 0, len(fileContents)),
+	}
+	for fileHash := range fileContents {
+		request.Hashes = append(request.Hashes, fileHash)
+	}
+
+	response, err := HTTPPostJSONData("/v1/projects/missing", request)
+	if err != nil {
+		return nil, err
+	}
+
+	var projectFilesResponse schema.ProjectFilesResponse
+	if err := json.Unmarshal(response, &projectFilesResponse); err != nil {
+		return nil, errors.Wrap(err, "/v1/projects/missing", string(response))
+	}
+	return projectFilesResponse.MissingHashes, nil
+}
+
+// largestFiles returns the n largest files (formatted with their sizes, relative to root)
+func largestFiles(paths []string, root string, n int) []string {
+	fileSizes := make(map[string]int64, len(paths))
+	for _, path := range paths {
+		if fileInfo, err := os.Stat(path); err == nil {
+			fileSizes[path] = fileInfo.Size()
+		}
+	}
+
+	sortedPaths := make([]string, 0, len(fileSizes))
+	for path := range fileSizes {
+		sortedPaths = append(sortedPaths, path)
+	}
+	sort.Slice(sortedPaths, func(i, j int) bool {
+		return fileSizes[sortedPaths[i]] > fileSizes[sortedPaths[j]]
+	})
+
+	var largest []string
+	for i := 0; i < len(sortedPaths) && i < n; i++ {
+		relPath := files.TrimDirPrefix(sortedPaths[i], root)
+		largest = append(largest, fmt.Sprintf("%s (%s)", relPath, s.ByteSize(fileSizes[sortedPaths[i]])))
+	}
+	return largest
 }
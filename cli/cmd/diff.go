@@ -0,0 +1,100 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+)
+
+var flagDiffFrom int64
+var flagDiffTo int64
+
+func init() {
+	diffCmd.PersistentFlags().Int64Var(&flagDiffFrom, "from", 0, "the earlier revision (default: the revision before --to)")
+	diffCmd.PersistentFlags().Int64Var(&flagDiffTo, "to", 0, "the later revision (default: the api's current revision)")
+	addAppNameFlag(diffCmd)
+	addEnvFlag(diffCmd)
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff API_NAME",
+	Short: "show the changes to an api's configuration between two of its revisions",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.diff")
+
+		apiName := args[0]
+		appName, err := AppNameFromFlagOrConfig()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		params := map[string]string{"appName": appName}
+		if flagDiffFrom != 0 {
+			params["from"] = s.Int64(flagDiffFrom)
+		}
+		if flagDiffTo != 0 {
+			params["to"] = s.Int64(flagDiffTo)
+		}
+		httpResponse, err := HTTPGet("/v1/apis/"+apiName+"/diff", params)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		var diffResponse schema.APIDiffResponse
+		err = json.Unmarshal(httpResponse, &diffResponse)
+		if err != nil {
+			exit.Error(err, "/v1/apis/"+apiName+"/diff", string(httpResponse))
+		}
+		fmt.Print(apiDiffStr(&diffResponse))
+	},
+}
+
+func apiDiffStr(diffResponse *schema.APIDiffResponse) string {
+	if len(diffResponse.Diffs) == 0 {
+		return fmt.Sprintf("%s api: revisions %d and %d have the same configuration\n", diffResponse.APIName, diffResponse.From, diffResponse.To)
+	}
+
+	rows := make([][]interface{}, len(diffResponse.Diffs))
+	for i, diff := range diffResponse.Diffs {
+		rows[i] = []interface{}{diff.Path, diffValueStr(diff.Before), diffValueStr(diff.After)}
+	}
+	return table.MustFormat(table.Table{
+		Headers: []table.Header{
+			{Title: "field"},
+			{Title: fmt.Sprintf("revision %d", diffResponse.From), MaxWidth: 60},
+			{Title: fmt.Sprintf("revision %d", diffResponse.To), MaxWidth: 60},
+		},
+		Rows: rows,
+	}) + "\n"
+}
+
+func diffValueStr(value interface{}) string {
+	if value == nil {
+		return "-"
+	}
+	return s.ObjFlatNoQuotes(value)
+}
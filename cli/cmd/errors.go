@@ -19,6 +19,7 @@ package cmd
 import (
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
@@ -45,6 +46,10 @@ const (
 	ErrConfigCannotBeChangedOnUpdate
 	ErrDuplicateCLIEnvNames
 	ErrCLINotInAppDir
+	ErrProjectZipTooLarge
+	ErrClusterLocalAPI
+	ErrIncompatibleWithFederated
+	ErrCLIEnvClusterNameMissing
 )
 
 var errorKinds = []string{
@@ -56,9 +61,13 @@ var errorKinds = []string{
 	"err_config_cannot_be_changed_on_update",
 	"err_duplicate_cli_env_names",
 	"err_cli_not_in_app_dir",
+	"err_project_zip_too_large",
+	"err_cluster_local_api",
+	"err_incompatible_with_federated",
+	"err_cli_env_cluster_name_missing",
 }
 
-var _ = [1]int{}[int(ErrCLINotInAppDir)-(len(errorKinds)-1)] // Ensure list length matches
+var _ = [1]int{}[int(ErrCLIEnvClusterNameMissing)-(len(errorKinds)-1)] // Ensure list length matches
 
 func (t ErrorKind) String() string {
 	return errorKinds[t]
@@ -161,3 +170,36 @@ func ErrorCliNotInAppDir() error {
 		message: "your current working directory is not in or under a cortex directory (identified via a top-level cortex.yaml file)",
 	})
 }
+
+func ErrorProjectZipTooLarge(size int64, maxSize int64, largestFiles []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrProjectZipTooLarge,
+		message: fmt.Sprintf("the new and changed files in your project total %s, which exceeds the upload limit of %s; the largest files are: %s (files can be excluded from the project by adding them to a .cortexignore file in your project's root directory)", s.ByteSize(size), s.ByteSize(maxSize), strings.Join(largestFiles, ", ")),
+	})
+}
+
+func ErrorClusterLocalAPI(apiName string, url string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrClusterLocalAPI,
+		message: fmt.Sprintf("%s is cluster-local (networking.expose is cluster_local), so it can only be called from within the cluster at %s", s.UserStr(apiName), url),
+	})
+}
+
+func ErrorIncompatibleWithFederated(option string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrIncompatibleWithFederated,
+		message: fmt.Sprintf("%s can't be used with --federated; use --env to select one of the federated clusters' environments instead", option),
+	})
+}
+
+func ErrorCLIEnvClusterNameMissing(environment string) error {
+	configureCmd := "cortex configure"
+	if environment != "default" {
+		configureCmd += " --env=" + environment
+	}
+
+	return errors.WithStack(Error{
+		Kind:    ErrCLIEnvClusterNameMissing,
+		message: fmt.Sprintf("the name of the cluster isn't configured for the %s environment (the operator only accepts requests which are signed for its cluster); run `%s` to set it", environment, configureCmd),
+	})
+}
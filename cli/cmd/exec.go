@@ -0,0 +1,88 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+)
+
+var flagExecReplica string
+var flagExecContainer string
+
+func init() {
+	execCmd.PersistentFlags().StringVar(&flagExecReplica, "replica", "", "name of the replica to run the command in (default: one of the api's running replicas)")
+	execCmd.PersistentFlags().StringVar(&flagExecContainer, "container", "api", "container to run the command in (api or serve)")
+	addAppNameFlag(execCmd)
+	addEnvFlag(execCmd)
+}
+
+var execCmd = &cobra.Command{
+	Use:   "exec API_NAME -- COMMAND [ARGS...]",
+	Short: "run a command in one of an api's replicas (requires debug_exec and an operator admin)",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cmd.ArgsLenAtDash() != 1 || len(args) < 2 {
+			return errors.New("requires an api name followed by -- and the command to run (e.g. cortex exec my-api -- ls /mnt/model)")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		// the command isn't sent with telemetry, since it may contain sensitive values
+		telemetry.Event("cli.exec")
+
+		apiName := args[0]
+		appName, err := AppNameFromFlagOrConfig()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		params := map[string]string{
+			"appName":   appName,
+			"container": flagExecContainer,
+		}
+		if flagExecReplica != "" {
+			params["replica"] = flagExecReplica
+		}
+
+		request := schema.ExecRequest{Command: args[1:]}
+		httpResponse, err := HTTPPostJSONData("/v1/apis/"+apiName+"/exec", request, params)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		var execResponse schema.ExecResponse
+		err = json.Unmarshal(httpResponse, &execResponse)
+		if err != nil {
+			exit.Error(err, "/v1/apis/"+apiName+"/exec", string(httpResponse))
+		}
+
+		fmt.Fprint(os.Stdout, execResponse.Stdout)
+		fmt.Fprint(os.Stderr, execResponse.Stderr)
+		if execResponse.Truncated {
+			fmt.Fprintf(os.Stderr, "\n(the output was truncated)\n")
+		}
+		exit.Code(execResponse.ExitCode)
+	},
+}
@@ -46,6 +46,7 @@ var flagWatch bool
 var flagVerbose bool
 var flagSummary bool
 var flagAllDeployments bool
+var flagGetFederated bool
 
 func init() {
 	addAppNameFlag(getCmd)
@@ -54,6 +55,7 @@ func init() {
 	getCmd.PersistentFlags().BoolVarP(&flagSummary, "summary", "s", false, "show summarized output")
 	getCmd.PersistentFlags().BoolVarP(&flagVerbose, "verbose", "v", false, "show verbose output")
 	getCmd.PersistentFlags().BoolVarP(&flagAllDeployments, "all-deployments", "a", false, "list all deployments")
+	getCmd.PersistentFlags().BoolVar(&flagGetFederated, "federated", false, "list the deployment's apis in the cluster and in each of its federated clusters")
 }
 
 var getCmd = &cobra.Command{
@@ -70,6 +72,20 @@ var getCmd = &cobra.Command{
 }
 
 func runGet(cmd *cobra.Command, args []string) (string, error) {
+	if flagGetFederated {
+		if len(args) > 0 {
+			return "", ErrorIncompatibleWithFederated("an api name")
+		}
+		if flagAllDeployments {
+			return "", ErrorIncompatibleWithFederated("--all-deployments")
+		}
+		appName, err := AppNameFromFlagOrConfig()
+		if err != nil {
+			exit.Error(err)
+		}
+		return federatedResourcesStr(appName)
+	}
+
 	if flagAllDeployments || !IsAppNameSpecified() {
 		return allDeploymentsStr()
 	}
@@ -108,7 +124,7 @@ func runGet(cmd *cobra.Command, args []string) (string, error) {
 }
 
 func allDeploymentsStr() (string, error) {
-	httpResponse, err := HTTPGet("/deployments", map[string]string{})
+	httpResponse, err := HTTPGet("/v1/deployments", map[string]string{})
 	if err != nil {
 		return "", err
 	}
@@ -147,9 +163,64 @@ func allDeploymentsStr() (string, error) {
 	return table.Format(fullTable)
 }
 
+// federatedResourcesStr lists the deployment's apis in each cluster of the federation, followed by the clusters which couldn't be listed
+func federatedResourcesStr(appName string) (string, error) {
+	httpResponse, err := HTTPGet("/v1/federation/apis", map[string]string{"appName": appName})
+	if err != nil {
+		return "", err
+	}
+
+	var federatedRes schema.FederatedResourcesResponse
+	if err = json.Unmarshal(httpResponse, &federatedRes); err != nil {
+		return "", err
+	}
+
+	fullTable := table.Table{}
+	var errorLines []string
+
+	for _, cluster := range federatedRes.Clusters {
+		if cluster.Resources == nil {
+			// note: if modifying this string, search the codebase for it and change all occurrences
+			if strings.HasSuffix(cluster.Error, "is not deployed") {
+				continue
+			}
+			errorLines = append(errorLines, fmt.Sprintf("%s: %s", cluster.Cluster, cluster.Error))
+			continue
+		}
+
+		t := apiResourceTable(cluster.Resources.APIGroupStatuses)
+
+		if len(fullTable.Headers) == 0 {
+			clusterHeader := table.Header{Title: "cluster"}
+			fullTable.Headers = append([]table.Header{clusterHeader}, t.Headers...)
+		}
+
+		for i := range t.Rows {
+			t.Rows[i] = append([]interface{}{cluster.Cluster}, t.Rows[i]...)
+		}
+
+		fullTable.Rows = append(fullTable.Rows, t.Rows...)
+	}
+
+	out := ""
+	if len(fullTable.Headers) > 0 {
+		out = table.MustFormat(fullTable)
+	}
+	if len(errorLines) > 0 {
+		if out != "" {
+			out += "\n"
+		}
+		out += titleStr("unavailable clusters") + strings.Join(errorLines, "\n")
+	}
+	if out == "" {
+		return console.Bold(appName + " deployment is not deployed to any cluster"), nil
+	}
+	return out, nil
+}
+
 func getResourcesResponse(appName string) (*schema.GetResourcesResponse, error) {
 	params := map[string]string{"appName": appName}
-	httpResponse, err := HTTPGet("/resources", params)
+	httpResponse, err := HTTPGet("/v1/apis", params)
 	if err != nil {
 		return nil, err
 	}
@@ -239,7 +310,7 @@ func describeAPI(name string, resourcesRes *schema.GetResourcesResponse, flagVer
 		{Title: "last update"},
 	}
 
-	apiEndpoint := urls.Join(resourcesRes.APIsBaseURL, *api.Endpoint)
+	apiEndpoint := apiEndpointURL(resourcesRes, api)
 
 	statusTable := table.Table{
 		Headers: headers,
@@ -260,22 +331,82 @@ func describeAPI(name string, resourcesRes *schema.GetResourcesResponse, flagVer
 
 	if api.Tracker != nil && len(predictionMetrics) == 0 {
 		predictionMetrics = "\n" + predictionMetricsTable(apiMetrics, api) + "\n"
+		if api.Feedback != nil {
+			predictionMetrics += "\n" + feedbackMetricsTable(apiMetrics, api) + "\n"
+		}
 	}
 
 	out += predictionMetrics
 
 	out += "\n" + console.Bold("endpoint: ") + apiEndpoint
+	if api.IsClusterLocal() {
+		out += " (only reachable from within the cluster)"
+	}
+	if api.Owner != "" {
+		out += "\n" + console.Bold("owner: ") + api.Owner
+	}
+	if api.GitCommit != "" {
+		out += "\n" + console.Bold("git commit: ") + api.GitCommit
+	}
 
 	if !flagVerbose {
+		if groupStatus.Code == resource.StatusKilledOOM || groupStatus.Code == resource.StatusCrashLooping || groupStatus.Code == resource.StatusStalled {
+			if apiStatus, err := getAPIStatus(ctx.App.Name, api.Name); err == nil {
+				if apiStatus.MemoryRecommendation != nil {
+					out += "\n" + memoryRecommendationStr(apiStatus.MemoryRecommendation)
+				}
+				if apiStatus.CrashLoop != nil {
+					out += "\n\n" + crashLoopStr(apiStatus.CrashLoop)
+				}
+				if apiStatus.RolloutStall != nil {
+					out += "\n\n" + rolloutStallStr(apiStatus.RolloutStall)
+				}
+			}
+		}
+		if api.Pause != nil {
+			out += "\n\n" + pauseStr(api)
+		} else if groupStatus.Code == resource.StatusPaused {
+			if apiStatus, err := getAPIStatus(ctx.App.Name, api.Name); err == nil && apiStatus.Idle != nil {
+				out += "\n\n" + idleStr(apiStatus.Idle)
+			}
+		}
 		return out, nil
 	}
 
 	out += fmt.Sprintf("\n%s curl %s?debug=true -X POST -H \"Content-Type: application/json\" -d @sample.json", console.Bold("curl:"), apiEndpoint)
 
-	if api.Predictor.Type == userconfig.TensorFlowPredictorType || api.Predictor.Type == userconfig.ONNXPredictorType {
+	if !api.IsClusterLocal() && (api.Predictor.Type == userconfig.TensorFlowPredictorType || api.Predictor.Type == userconfig.ONNXPredictorType) {
 		out += "\n\n" + describeModelInput(groupStatus, apiEndpoint)
 	}
 
+	apiStatus, err := getAPIStatus(ctx.App.Name, api.Name)
+	if err != nil {
+		out += fmt.Sprintf("\n\nerror fetching replica statuses: %s", err.Error())
+	} else {
+		if apiStatus.DashboardURL != "" {
+			out += fmt.Sprintf("\n\n%s %s", console.Bold("dashboard:"), apiStatus.DashboardURL)
+		}
+		if apiStatus.Drift != nil {
+			out += "\n\n" + driftStr(apiStatus.Drift)
+		}
+		if apiStatus.DataDrift != nil {
+			out += "\n\n" + dataDriftStr(apiStatus.DataDrift)
+		}
+		if apiStatus.FeedbackMetrics != nil {
+			out += "\n\n" + feedbackMetricsStr(apiStatus.FeedbackMetrics)
+		}
+		if api.Pause != nil {
+			out += "\n\n" + pauseStr(api)
+		}
+		if apiStatus.Idle != nil {
+			out += "\n\n" + idleStr(apiStatus.Idle)
+		}
+		if apiStatus.Cost != nil {
+			out += "\n\n" + apiCostStr(apiStatus.Cost)
+		}
+		out += "\n" + replicasStr(apiStatus)
+	}
+
 	if api != nil {
 		out += "\n" + titleStr("configuration") + strings.TrimSpace(api.UserConfigStr())
 	}
@@ -283,9 +414,233 @@ func describeAPI(name string, resourcesRes *schema.GetResourcesResponse, flagVer
 	return out, nil
 }
 
+func memoryRecommendationStr(recommendation *schema.MemoryRecommendation) string {
+	return fmt.Sprintf("%s %s", console.Bold("out of memory:"), recommendation.Message)
+}
+
+func crashLoopStr(crashLoop *schema.CrashLoop) string {
+	action := "its rollout was paused"
+	if crashLoop.RolledBack {
+		action = "it was rolled back to its previous version"
+	}
+	out := fmt.Sprintf("%s the API's replicas restarted %d times during its rollout, so %s %s ago; fix the error and re-deploy", console.Bold("crash looping:"), crashLoop.Restarts, action, libtime.Since(&crashLoop.Time))
+
+	if logs := strings.TrimSpace(crashLoop.Logs); logs != "" {
+		out += fmt.Sprintf("\n\n%s\n%s", console.Bold(fmt.Sprintf("last logs (%s, container %s):", crashLoop.Replica, crashLoop.Container)), logs)
+	}
+	return out
+}
+
+func driftStr(drift *schema.Drift) string {
+	action := "could not be restored"
+	if drift.Restored {
+		action = "was restored to the API's configuration"
+	}
+	verb := "was"
+	if len(drift.Resources) > 1 {
+		verb = "were"
+	}
+	return fmt.Sprintf("%s %s %s modified outside of cortex %s ago, and %s", console.Bold("drift:"), strings.Join(drift.Resources, ", "), verb, libtime.Since(&drift.Time), action)
+}
+
+func dataDriftStr(dataDrift *schema.DataDrift) string {
+	title := console.Bold("data drift:")
+	switch dataDrift.Status {
+	case schema.DataDriftStatusCollectingBaseline:
+		return fmt.Sprintf("%s collecting the baseline", title)
+	case schema.DataDriftStatusInsufficientSamples:
+		return fmt.Sprintf("%s not enough predictions were tracked during the window to compare with the baseline (%d)", title, dataDrift.WindowCount)
+	}
+
+	fieldStrs := make([]string, len(dataDrift.Fields))
+	var driftedFields []string
+	for i, field := range dataDrift.Fields {
+		fieldStrs[i] = fmt.Sprintf("%s: %s", field.Field, s.Round(field.PSI, 3, 0))
+		if field.Drifted {
+			driftedFields = append(driftedFields, field.Field)
+		}
+	}
+	psiStr := fmt.Sprintf("PSI %s (threshold: %s)", strings.Join(fieldStrs, ", "), s.Float64(dataDrift.Threshold))
+
+	if len(driftedFields) == 0 {
+		return fmt.Sprintf("%s none (checked %s ago); %s", title, libtime.Since(&dataDrift.Time), psiStr)
+	}
+	return fmt.Sprintf("%s %s drifted from the baseline (detected %s ago); %s", title, s.StrsAnd(driftedFields), libtime.Since(&dataDrift.Time), psiStr)
+}
+
+func feedbackMetricsStr(feedbackMetrics *schema.FeedbackMetrics) string {
+	title := console.Bold("feedback metrics:")
+	if feedbackMetrics.Count == 0 {
+		return fmt.Sprintf("%s no feedback was received on tracked predictions during the last %s", title, feedbackMetrics.Window)
+	}
+
+	var metricStrs []string
+	addMetric := func(name string, value *float64) {
+		if value != nil {
+			metricStrs = append(metricStrs, fmt.Sprintf("%s %s", name, s.Round(*value, 3, 0)))
+		}
+	}
+	addMetric("accuracy", feedbackMetrics.Accuracy)
+	addMetric("precision", feedbackMetrics.Precision)
+	addMetric("recall", feedbackMetrics.Recall)
+	addMetric("mae", feedbackMetrics.MAE)
+	addMetric("mse", feedbackMetrics.MSE)
+
+	return fmt.Sprintf("%s %s (%d predictions were given feedback during the last %s; computed %s ago)", title, strings.Join(metricStrs, ", "), feedbackMetrics.Count, feedbackMetrics.Window, libtime.Since(&feedbackMetrics.Time))
+}
+
+func pauseStr(api *context.API) string {
+	return fmt.Sprintf("%s the API was paused %s ago; run `cortex resume %s` to restore its replicas", console.Bold("paused:"), libtime.Since(&api.Pause.Time), api.Name)
+}
+
+func idleStr(idle *schema.Idle) string {
+	return fmt.Sprintf("%s %s (detected %s ago)", console.Bold("idle:"), idle.Recommendation, libtime.Since(&idle.Since))
+}
+
+func apiCostStr(cost *schema.APICost) string {
+	return fmt.Sprintf("%s ~%s per hour (~%s per month based on the last day's average, with on-demand pricing)", console.Bold("estimated cost:"), s.DollarsAndTenthsOfCents(cost.Hourly), s.DollarsAndCents(cost.Monthly))
+}
+
+var rolloutStallReasonMessages = map[string]string{
+	schema.RolloutStallReasonImagePull:         "an image could not be pulled",
+	schema.RolloutStallReasonUnschedulableGPU:  "there are no instances with enough available GPUs (consider increasing max_instances or reducing the API's gpu request)",
+	schema.RolloutStallReasonInsufficientNodes: "there are no instances with enough available compute (consider increasing max_instances or reducing the API's cpu or mem request)",
+	schema.RolloutStallReasonNotReady:          "the API's replicas are running but have not become ready (run `cortex logs` to view the logs)",
+	schema.RolloutStallReasonUnknown:           "the reason could not be determined",
+}
+
+func rolloutStallStr(stall *schema.RolloutStall) string {
+	reasonMessage, ok := rolloutStallReasonMessages[stall.Reason]
+	if !ok {
+		reasonMessage = stall.Reason
+	}
+	out := fmt.Sprintf("%s the rollout exceeded its progress deadline %s ago; %s", console.Bold("rollout stalled:"), libtime.Since(&stall.Time), reasonMessage)
+	if stall.Message != "" {
+		out += fmt.Sprintf("\n%s %s", console.Bold(stall.Replica+":"), stall.Message)
+	}
+	return out
+}
+
+func getAPIStatus(appName, apiName string) (*schema.APIStatusResponse, error) {
+	params := map[string]string{"appName": appName}
+	httpResponse, err := HTTPGet("/v1/apis/"+apiName+"/status", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiStatus schema.APIStatusResponse
+	err = json.Unmarshal(httpResponse, &apiStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiStatus, nil
+}
+
+func replicasStr(apiStatus *schema.APIStatusResponse) string {
+	var out string
+
+	if len(apiStatus.Replicas) > 0 {
+		anyFailed := false
+		rows := make([][]interface{}, len(apiStatus.Replicas))
+		for i, replica := range apiStatus.Replicas {
+			var exitCode string
+			if replica.ExitCode != nil {
+				exitCode = s.Int32(*replica.ExitCode)
+			}
+			if replica.State == schema.ReplicaStateFailed {
+				anyFailed = true
+			}
+			rows[i] = []interface{}{
+				replica.Name,
+				string(replica.State),
+				replica.Reason,
+				replica.Container,
+				exitCode,
+				replica.Restarts,
+				libtime.Since(replica.StartTime),
+			}
+		}
+
+		out += titleStr("replicas") + table.MustFormat(table.Table{
+			Headers: []table.Header{
+				{Title: "replica"},
+				{Title: "state"},
+				{Title: "reason", Hidden: !anyFailed},
+				{Title: "container", Hidden: !anyFailed},
+				{Title: "exit code", Hidden: !anyFailed},
+				{Title: "restarts"},
+				{Title: "age"},
+			},
+			Rows: rows,
+		}) + "\n"
+
+		for _, replica := range apiStatus.Replicas {
+			if replica.Message != "" {
+				out += fmt.Sprintf("\n%s %s", console.Bold(replica.Name+":"), replica.Message)
+			}
+		}
+		out += "\n"
+	}
+
+	if apiStatus.MemoryRecommendation != nil {
+		out += "\n" + memoryRecommendationStr(apiStatus.MemoryRecommendation) + "\n"
+	}
+
+	if apiStatus.CrashLoop != nil {
+		out += "\n" + crashLoopStr(apiStatus.CrashLoop) + "\n"
+	}
+
+	if apiStatus.RolloutStall != nil {
+		out += "\n" + rolloutStallStr(apiStatus.RolloutStall) + "\n"
+	}
+
+	if len(apiStatus.Events) > 0 {
+		rows := make([][]interface{}, len(apiStatus.Events))
+		for i, event := range apiStatus.Events {
+			rows[i] = []interface{}{
+				libtime.Since(&apiStatus.Events[i].Time),
+				event.Type,
+				event.Reason,
+				event.Object,
+				event.Message,
+			}
+		}
+
+		out += titleStr("recent events") + table.MustFormat(table.Table{
+			Headers: []table.Header{
+				{Title: "last seen"},
+				{Title: "type"},
+				{Title: "reason"},
+				{Title: "object"},
+				{Title: "message", MaxWidth: 100},
+			},
+			Rows: rows,
+		}) + "\n"
+	}
+
+	return out
+}
+
+// Private APIs are served by the internal load balancer
+func apisBaseURL(resourcesRes *schema.GetResourcesResponse, api *context.API) string {
+	if api.Networking != nil && api.Networking.Visibility == userconfig.PrivateVisibility {
+		return resourcesRes.PrivateAPIsBaseURL
+	}
+	return resourcesRes.APIsBaseURL
+}
+
+// Cluster-local APIs are only served by their Kubernetes service
+func apiEndpointURL(resourcesRes *schema.GetResourcesResponse, api *context.API) string {
+	if api.IsClusterLocal() {
+		return resourcesRes.ClusterLocalAPIURLs[api.Name]
+	}
+	return urls.Join(apisBaseURL(resourcesRes, api), *api.Endpoint)
+}
+
 func getAPIMetrics(appName, apiName string) (schema.APIMetrics, error) {
 	params := map[string]string{"appName": appName, "apiName": apiName}
-	httpResponse, err := HTTPGet("/metrics", params)
+	httpResponse, err := HTTPGet("/v1/metrics", params)
 	if err != nil {
 		return schema.APIMetrics{}, err
 	}
@@ -382,6 +737,40 @@ func regressionMetricsTable(apiMetrics schema.APIMetrics) string {
 	return table.MustFormat(t)
 }
 
+func feedbackMetricsTable(apiMetrics schema.APIMetrics, api *context.API) string {
+	avgTitle := "mean abs error"
+	if api.Tracker.ModelType == userconfig.ClassificationModelType {
+		avgTitle = "accuracy"
+	}
+
+	sampleCountStr := "-"
+	avgStr := "-"
+
+	if apiMetrics.FeedbackStats != nil {
+		if apiMetrics.FeedbackStats.SampleCount != 0 {
+			sampleCountStr = s.Int(apiMetrics.FeedbackStats.SampleCount)
+		}
+
+		if apiMetrics.FeedbackStats.Avg != nil {
+			if api.Tracker.ModelType == userconfig.ClassificationModelType {
+				avgStr = fmt.Sprintf("%.4g%%", *apiMetrics.FeedbackStats.Avg*100)
+			} else {
+				avgStr = fmt.Sprintf("%.9g", *apiMetrics.FeedbackStats.Avg)
+			}
+		}
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "feedback", MaxWidth: 10},
+			{Title: avgTitle, MaxWidth: 15},
+		},
+		Rows: [][]interface{}{{sampleCountStr, avgStr}},
+	}
+
+	return table.MustFormat(t)
+}
+
 func classificationMetricsTable(apiMetrics schema.APIMetrics) string {
 	classList := make([]string, len(apiMetrics.ClassDistribution))
 
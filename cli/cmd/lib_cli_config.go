@@ -36,6 +36,7 @@ type CLIConfig struct {
 type CLIEnvConfig struct {
 	Name               string `json:"name" yaml:"name"`
 	OperatorEndpoint   string `json:"operator_endpoint" yaml:"operator_endpoint"`
+	ClusterName        string `json:"cluster_name" yaml:"cluster_name"` // the operator only accepts requests which are signed for its cluster
 	AWSAccessKeyID     string `json:"aws_access_key_id" yaml:"aws_access_key_id"`
 	AWSSecretAccessKey string `json:"aws_secret_access_key" yaml:"aws_secret_access_key"`
 }
@@ -69,6 +70,12 @@ var cliConfigValidation = &cr.StructValidation{
 								Validator: cr.GetURLValidator(false, false),
 							},
 						},
+						{
+							StructField: "ClusterName",
+							StringValidation: &cr.StringValidation{
+								Required: false,
+							},
+						},
 						{
 							StructField: "AWSAccessKeyID",
 							StringValidation: &cr.StringValidation{
@@ -102,6 +109,9 @@ func cliEnvPromptValidation(defaults *CLIEnvConfig) *cr.PromptValidation {
 	if defaults.OperatorEndpoint == "" && os.Getenv("CORTEX_OPERATOR_ENDPOINT") != "" {
 		defaults.OperatorEndpoint = os.Getenv("CORTEX_OPERATOR_ENDPOINT")
 	}
+	if defaults.ClusterName == "" && os.Getenv("CORTEX_CLUSTER_NAME") != "" {
+		defaults.ClusterName = os.Getenv("CORTEX_CLUSTER_NAME")
+	}
 
 	return &cr.PromptValidation{
 		PromptItemValidations: []*cr.PromptItemValidation{
@@ -116,6 +126,16 @@ func cliEnvPromptValidation(defaults *CLIEnvConfig) *cr.PromptValidation {
 					Validator: cr.GetURLValidator(false, false),
 				},
 			},
+			{
+				StructField: "ClusterName",
+				PromptOpts: &prompt.Options{
+					Prompt: "cortex cluster name",
+				},
+				StringValidation: &cr.StringValidation{
+					Required: true,
+					Default:  defaults.ClusterName,
+				},
+			},
 			{
 				StructField: "AWSAccessKeyID",
 				PromptOpts: &prompt.Options{
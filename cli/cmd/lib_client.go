@@ -32,6 +32,7 @@ import (
 	"github.com/gorilla/websocket"
 
 	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
@@ -162,15 +163,57 @@ func StreamLogs(appName string, resourceName string, resourceType string) error
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt)
 
-	req, err := operatorRequest("GET", "/logs/read", nil, nil)
+	connection, err := dialOperatorWebSocket("/v1/logs", map[string]string{
+		"resourceName": resourceName,
+		"resourceType": resourceType,
+		"appName":      appName,
+	})
 	if err != nil {
 		return err
 	}
+	defer connection.Close()
+
+	done := make(chan struct{})
+	handleConnection(connection, done)
+	closeConnection(connection, done, interrupt)
+	return nil
+}
+
+// StreamDeployProgress calls handleEvent with each of the deployment's progress events, until the operator sends the done event (which is returned)
+func StreamDeployProgress(appName string, handleEvent func(*schema.DeployProgressEvent)) (*schema.DeployProgressEvent, error) {
+	connection, err := dialOperatorWebSocket("/v1/deploy/progress", map[string]string{"appName": appName})
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Close()
+
+	for {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		var event schema.DeployProgressEvent
+		if err := json.Unmarshal(message, &event); err != nil {
+			return nil, err
+		}
+		if event.Stage == schema.DeployStageDone {
+			connection.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return &event, nil
+		}
+		handleEvent(&event)
+	}
+}
+
+func dialOperatorWebSocket(endpoint string, qParams map[string]string) (*websocket.Conn, error) {
+	req, err := operatorRequest("GET", endpoint, nil, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	values := req.URL.Query()
-	values.Set("resourceName", resourceName)
-	values.Set("resourceType", resourceType)
-	values.Set("appName", appName)
+	for key, value := range qParams {
+		values.Set(key, value)
+	}
 
 	if isTelemetryEnabled() {
 		values.Set("clientID", clientID())
@@ -182,7 +225,7 @@ func StreamLogs(appName string, resourceName string, resourceType string) error
 
 	authHeader, err := authHeader()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	header := http.Header{}
@@ -195,28 +238,24 @@ func StreamLogs(appName string, resourceName string, resourceType string) error
 
 	connection, response, err := dialer.Dial(wsURL, header)
 	if err != nil && response == nil {
-		return ErrorFailedToConnectOperator(err, strings.Replace(operatorEndpointOrBlank(), "http", "ws", 1))
+		return nil, ErrorFailedToConnectOperator(err, strings.Replace(operatorEndpointOrBlank(), "http", "ws", 1))
 	}
 	defer response.Body.Close()
 
 	if err != nil {
 		bodyBytes, err := ioutil.ReadAll(response.Body)
 		if err != nil || bodyBytes == nil || string(bodyBytes) == "" {
-			return ErrorFailedToConnectOperator(err, strings.Replace(operatorEndpointOrBlank(), "http", "ws", 1))
+			return nil, ErrorFailedToConnectOperator(err, strings.Replace(operatorEndpointOrBlank(), "http", "ws", 1))
 		}
 		var output schema.ErrorResponse
 		err = json.Unmarshal(bodyBytes, &output)
 		if err != nil || output.Error == "" {
-			return errors.New(string(bodyBytes))
+			return nil, errors.New(string(bodyBytes))
 		}
-		return errors.New(output.Error)
+		return nil, errors.New(output.Error)
 	}
-	defer connection.Close()
 
-	done := make(chan struct{})
-	handleConnection(connection, done)
-	closeConnection(connection, done, interrupt)
-	return nil
+	return connection, nil
 }
 
 func handleConnection(connection *websocket.Conn, done chan struct{}) {
@@ -336,7 +375,17 @@ func authHeader() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("CortexAWS %s|%s", cliEnvConfig.AWSAccessKeyID, cliEnvConfig.AWSSecretAccessKey), err
+
+	if cliEnvConfig.ClusterName == "" {
+		return "", ErrorCLIEnvClusterNameMissing(cliEnvConfig.Name)
+	}
+
+	// the operator verifies the signed request with AWS STS, so the secret access key is never sent to the operator
+	presignedURL, err := aws.PresignCallerIdentityRequest(cliEnvConfig.AWSAccessKeyID, cliEnvConfig.AWSSecretAccessKey, cliEnvConfig.ClusterName, 5*time.Minute)
+	if err != nil {
+		return "", err
+	}
+	return "CortexSigV4 " + presignedURL, nil
 }
 
 // Returns empty string if not able to get operator endpoint
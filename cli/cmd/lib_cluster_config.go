@@ -32,6 +32,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
 )
 
 var _cachedClusterConfigRegex = regexp.MustCompile(`^cluster_\S+\.yaml$`)
@@ -145,6 +146,9 @@ func getInstallClusterConfig(awsCreds *AWSCredentials) (*clusterconfig.Config, e
 	}
 
 	err = clusterConfig.Validate(awsCreds.AWSAccessKeyID, awsCreds.AWSSecretAccessKey)
+	if err == nil {
+		err = clusterConfig.ValidateSNSTopic(awsCreds.CortexAWSAccessKeyID, awsCreds.CortexAWSSecretAccessKey)
+	}
 	if err != nil {
 		if flagClusterConfig != "" {
 			err = errors.Wrap(err, flagClusterConfig)
@@ -184,6 +188,11 @@ func getClusterUpdateConfig(cachedClusterConfig *clusterconfig.Config, awsCreds
 		}
 		userClusterConfig.InstanceType = cachedClusterConfig.InstanceType
 
+		if userClusterConfig.ARMInstanceType != nil && s.Obj(userClusterConfig.ARMInstanceType) != s.Obj(cachedClusterConfig.ARMInstanceType) {
+			return nil, ErrorConfigCannotBeChangedOnUpdate(clusterconfig.ARMInstanceTypeKey, cachedClusterConfig.ARMInstanceType)
+		}
+		userClusterConfig.ARMInstanceType = cachedClusterConfig.ARMInstanceType
+
 		if len(userClusterConfig.AvailabilityZones) > 0 && !strset.New(userClusterConfig.AvailabilityZones...).IsEqual(strset.New(cachedClusterConfig.AvailabilityZones...)) {
 			return nil, ErrorConfigCannotBeChangedOnUpdate(clusterconfig.AvailabilityZonesKey, cachedClusterConfig.AvailabilityZones)
 		}
@@ -250,6 +259,9 @@ func getClusterUpdateConfig(cachedClusterConfig *clusterconfig.Config, awsCreds
 	}
 
 	err = userClusterConfig.Validate(awsCreds.AWSAccessKeyID, awsCreds.AWSSecretAccessKey)
+	if err == nil {
+		err = userClusterConfig.ValidateSNSTopic(awsCreds.CortexAWSAccessKeyID, awsCreds.CortexAWSSecretAccessKey)
+	}
 	if err != nil {
 		if flagClusterConfig != "" {
 			err = errors.Wrap(err, flagClusterConfig)
@@ -272,8 +284,8 @@ func confirmInstallClusterConfig(clusterConfig *clusterconfig.Config, awsCreds *
 		}
 	}
 
-	operatorInstancePrice := aws.InstanceMetadatas[*clusterConfig.Region]["t3.medium"].Price
-	operatorEBSPrice := aws.EBSMetadatas[*clusterConfig.Region].Price * 20 / 30 / 24
+	operatorInstancePrice := clusterconfig.OperatorInstancePrice(*clusterConfig.Region)
+	operatorEBSPrice := clusterconfig.EBSHourlyPrice(*clusterConfig.Region, clusterconfig.OperatorVolumeSize)
 	elbPrice := aws.ELBMetadatas[*clusterConfig.Region].Price
 	natPrice := aws.NATMetadatas[*clusterConfig.Region].Price
 
@@ -289,18 +301,22 @@ func confirmInstallClusterConfig(clusterConfig *clusterconfig.Config, awsCreds *
 
 	fmt.Println()
 
-	apiInstancePrice := aws.InstanceMetadatas[*clusterConfig.Region][*clusterConfig.InstanceType].Price
-	apiEBSPrice := aws.EBSMetadatas[*clusterConfig.Region].Price * float64(clusterConfig.InstanceVolumeSize) / 30 / 24
-	fixedPrice := 0.20 + operatorInstancePrice + operatorEBSPrice + 2*elbPrice + natPrice
-	totalMinPrice := fixedPrice + float64(*clusterConfig.MinInstances)*(apiInstancePrice+apiEBSPrice)
-	totalMaxPrice := fixedPrice + float64(*clusterConfig.MaxInstances)*(apiInstancePrice+apiEBSPrice)
+	workerPrice := clusterConfig.WorkerHourlyPrice(*clusterConfig.InstanceType)
+	fixedPrice := clusterconfig.FixedHourlyPrice(*clusterConfig.Region)
+	totalMinPrice := fixedPrice + float64(*clusterConfig.MinInstances)*workerPrice
+	totalMaxPrice := fixedPrice + float64(*clusterConfig.MaxInstances)*workerPrice
+	if clusterConfig.ARMInstanceType != nil {
+		armWorkerPrice := clusterConfig.WorkerHourlyPrice(*clusterConfig.ARMInstanceType)
+		totalMinPrice += float64(clusterConfig.ARMMinInstances) * armWorkerPrice
+		totalMaxPrice += float64(clusterConfig.ARMMaxInstances) * armWorkerPrice
+	}
 
 	spotSuffix := ""
 	if clusterConfig.Spot != nil && *clusterConfig.Spot {
 		spotSuffix = " (on-demand pricing)"
 	}
 
-	if *clusterConfig.MinInstances == *clusterConfig.MaxInstances {
+	if totalMinPrice == totalMaxPrice {
 		fmt.Printf("this cluster will cost %s per hour%s\n\n", s.DollarsAndCents(totalMaxPrice), spotSuffix)
 	} else {
 		fmt.Printf("this cluster will cost %s - %s per hour based on the cluster size%s\n\n", s.DollarsAndCents(totalMinPrice), s.DollarsAndCents(totalMaxPrice), spotSuffix)
@@ -348,9 +364,20 @@ func clusterConfigConfirmaionStr(clusterConfig *clusterconfig.Config, awsCreds *
 	items.Add(clusterconfig.InstanceTypeUserFacingKey, *clusterConfig.InstanceType)
 	items.Add(clusterconfig.MinInstancesUserFacingKey, *clusterConfig.MinInstances)
 	items.Add(clusterconfig.MaxInstancesUserFacingKey, *clusterConfig.MaxInstances)
+	if clusterConfig.ARMInstanceType != nil {
+		items.Add(clusterconfig.ARMInstanceTypeUserFacingKey, *clusterConfig.ARMInstanceType)
+		items.Add(clusterconfig.ARMMinInstancesUserFacingKey, clusterConfig.ARMMinInstances)
+		items.Add(clusterconfig.ARMMaxInstancesUserFacingKey, clusterConfig.ARMMaxInstances)
+	}
 	if clusterConfig.InstanceVolumeSize != defaultConfig.InstanceVolumeSize {
 		items.Add(clusterconfig.InstanceVolumeSizeUserFacingKey, clusterConfig.InstanceVolumeSize)
 	}
+	if clusterConfig.CPUReserve != nil {
+		items.Add(clusterconfig.CPUReserveUserFacingKey, *clusterConfig.CPUReserve)
+	}
+	if clusterConfig.MemReserve != nil {
+		items.Add(clusterconfig.MemReserveUserFacingKey, *clusterConfig.MemReserve)
+	}
 
 	if clusterConfig.Spot != nil && *clusterConfig.Spot != *defaultConfig.Spot {
 		items.Add(clusterconfig.SpotUserFacingKey, s.YesNo(clusterConfig.Spot != nil && *clusterConfig.Spot))
@@ -385,6 +412,43 @@ func clusterConfigConfirmaionStr(clusterConfig *clusterconfig.Config, awsCreds *
 		}
 	}
 
+	if clusterConfig.MaxProjectSize != defaultConfig.MaxProjectSize {
+		items.Add(clusterconfig.MaxProjectSizeUserFacingKey, clusterConfig.MaxProjectSize)
+	}
+	if clusterConfig.MaxProjectFileSize != defaultConfig.MaxProjectFileSize {
+		items.Add(clusterconfig.MaxProjectFileSizeUserFacingKey, clusterConfig.MaxProjectFileSize)
+	}
+	if clusterConfig.MaxProjectFiles != defaultConfig.MaxProjectFiles {
+		items.Add(clusterconfig.MaxProjectFilesUserFacingKey, clusterConfig.MaxProjectFiles)
+	}
+	if len(clusterConfig.DeleteFailedPodReasons) > 0 {
+		items.Add(clusterconfig.DeleteFailedPodReasonsUserFacingKey, clusterConfig.DeleteFailedPodReasons)
+	}
+	if len(clusterConfig.OperatorDeployers) > 0 {
+		items.Add(clusterconfig.OperatorDeployersUserFacingKey, clusterConfig.OperatorDeployers)
+	}
+	if len(clusterConfig.OperatorViewers) > 0 {
+		items.Add(clusterconfig.OperatorViewersUserFacingKey, clusterConfig.OperatorViewers)
+	}
+	if len(clusterConfig.OperatorAdmins) > 0 {
+		items.Add(clusterconfig.OperatorAdminsUserFacingKey, clusterConfig.OperatorAdmins)
+	}
+	if len(clusterConfig.Webhooks) > 0 {
+		items.Add(clusterconfig.WebhooksUserFacingKey, clusterconfig.WebhookURLs(clusterConfig.Webhooks))
+	}
+	if clusterConfig.SNSTopicARN != nil {
+		items.Add(clusterconfig.SNSTopicARNUserFacingKey, *clusterConfig.SNSTopicARN)
+	}
+	if clusterConfig.GitOps != nil {
+		items.Add(clusterconfig.GitOpsRepositoryUserFacingKey, urls.TrimQueryParamsStr(clusterConfig.GitOps.Repository))
+		items.Add(clusterconfig.GitOpsBranchUserFacingKey, clusterConfig.GitOps.Branch)
+	}
+	if clusterConfig.DeployFreeze != nil {
+		for _, window := range clusterConfig.DeployFreeze.Windows {
+			items.Add(clusterconfig.DeployFreezeWindowUserFacingKey, window.String())
+		}
+	}
+
 	if clusterConfig.Telemetry != defaultConfig.Telemetry {
 		items.Add(clusterconfig.TelemetryUserFacingKey, clusterConfig.Telemetry)
 	}
@@ -395,6 +459,12 @@ func clusterConfigConfirmaionStr(clusterConfig *clusterconfig.Config, awsCreds *
 	if clusterConfig.ImagePythonServeGPU != defaultConfig.ImagePythonServeGPU {
 		items.Add(clusterconfig.ImagePythonServeGPUUserFacingKey, clusterConfig.ImagePythonServeGPU)
 	}
+	if clusterConfig.ImagePythonServeConda != defaultConfig.ImagePythonServeConda {
+		items.Add(clusterconfig.ImagePythonServeCondaUserFacingKey, clusterConfig.ImagePythonServeConda)
+	}
+	if clusterConfig.ImagePythonServeCondaGPU != defaultConfig.ImagePythonServeCondaGPU {
+		items.Add(clusterconfig.ImagePythonServeCondaGPUUserFacingKey, clusterConfig.ImagePythonServeCondaGPU)
+	}
 	if clusterConfig.ImageTFServe != defaultConfig.ImageTFServe {
 		items.Add(clusterconfig.ImageTFServeUserFacingKey, clusterConfig.ImageTFServe)
 	}
@@ -410,6 +480,27 @@ func clusterConfigConfirmaionStr(clusterConfig *clusterconfig.Config, awsCreds *
 	if clusterConfig.ImageONNXServeGPU != defaultConfig.ImageONNXServeGPU {
 		items.Add(clusterconfig.ImageONNXServeGPUUserFacingKey, clusterConfig.ImageONNXServeGPU)
 	}
+	if clusterConfig.ImageSKLearnServe != defaultConfig.ImageSKLearnServe {
+		items.Add(clusterconfig.ImageSKLearnServeUserFacingKey, clusterConfig.ImageSKLearnServe)
+	}
+	if clusterConfig.ImageXGBoostServe != defaultConfig.ImageXGBoostServe {
+		items.Add(clusterconfig.ImageXGBoostServeUserFacingKey, clusterConfig.ImageXGBoostServe)
+	}
+	if clusterConfig.ImageLightGBMServe != defaultConfig.ImageLightGBMServe {
+		items.Add(clusterconfig.ImageLightGBMServeUserFacingKey, clusterConfig.ImageLightGBMServe)
+	}
+	if clusterConfig.ImageTritonServe != defaultConfig.ImageTritonServe {
+		items.Add(clusterconfig.ImageTritonServeUserFacingKey, clusterConfig.ImageTritonServe)
+	}
+	if clusterConfig.ImageTritonServeCPU != nil {
+		items.Add(clusterconfig.ImageTritonServeCPUUserFacingKey, *clusterConfig.ImageTritonServeCPU)
+	}
+	if clusterConfig.ImageTritonAPI != defaultConfig.ImageTritonAPI {
+		items.Add(clusterconfig.ImageTritonAPIUserFacingKey, clusterConfig.ImageTritonAPI)
+	}
+	if clusterConfig.ImagePipelineAPI != defaultConfig.ImagePipelineAPI {
+		items.Add(clusterconfig.ImagePipelineAPIUserFacingKey, clusterConfig.ImagePipelineAPI)
+	}
 	if clusterConfig.ImageOperator != defaultConfig.ImageOperator {
 		items.Add(clusterconfig.ImageOperatorUserFacingKey, clusterConfig.ImageOperator)
 	}
@@ -428,12 +519,18 @@ func clusterConfigConfirmaionStr(clusterConfig *clusterconfig.Config, awsCreds *
 	if clusterConfig.ImageNvidia != defaultConfig.ImageNvidia {
 		items.Add(clusterconfig.ImageNvidiaUserFacingKey, clusterConfig.ImageNvidia)
 	}
+	if clusterConfig.ImageDCGMExporter != defaultConfig.ImageDCGMExporter {
+		items.Add(clusterconfig.ImageDCGMExporterUserFacingKey, clusterConfig.ImageDCGMExporter)
+	}
 	if clusterConfig.ImageFluentd != defaultConfig.ImageFluentd {
 		items.Add(clusterconfig.ImageFluentdUserFacingKey, clusterConfig.ImageFluentd)
 	}
 	if clusterConfig.ImageStatsd != defaultConfig.ImageStatsd {
 		items.Add(clusterconfig.ImageStatsdUserFacingKey, clusterConfig.ImageStatsd)
 	}
+	if clusterConfig.ImageRedis != defaultConfig.ImageRedis {
+		items.Add(clusterconfig.ImageRedisUserFacingKey, clusterConfig.ImageRedis)
+	}
 	if clusterConfig.ImageIstioProxy != defaultConfig.ImageIstioProxy {
 		items.Add(clusterconfig.ImageIstioProxyUserFacingKey, clusterConfig.ImageIstioProxy)
 	}
@@ -482,5 +579,15 @@ func workloadInstancesStr(clusterConfig *clusterconfig.Config, spotPrice float64
 
 	str := fmt.Sprintf("￮ %s %s ec2 %s for apis %s\n", instanceRangeStr, instanceTypeStr, instancesStr, instancePriceStr)
 	str += fmt.Sprintf("￮ %s %dgb ebs %s, one for each api instance (%s per hour each)", volumeRangeStr, clusterConfig.InstanceVolumeSize, volumesStr, s.DollarsAndTenthsOfCents(ebsPrice))
+
+	if clusterConfig.ARMInstanceType != nil {
+		armRangeStr := fmt.Sprintf("an autoscaling group of %d - %d", clusterConfig.ARMMinInstances, clusterConfig.ARMMaxInstances)
+		if clusterConfig.ARMMinInstances == clusterConfig.ARMMaxInstances {
+			armRangeStr = s.Int64(clusterConfig.ARMMinInstances)
+		}
+		armInstancePrice := aws.InstanceMetadatas[*clusterConfig.Region][*clusterConfig.ARMInstanceType].Price
+		str += fmt.Sprintf("\n￮ %s %s ec2 instances for arm64 apis, with a %dgb ebs volume each (%s per hour each)", armRangeStr, *clusterConfig.ARMInstanceType, clusterConfig.InstanceVolumeSize, s.DollarsMaxPrecision(armInstancePrice))
+	}
+
 	return str
 }
@@ -19,6 +19,7 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
@@ -66,9 +67,71 @@ func pythonPaths(dir string) []string {
 	return pyPaths
 }
 
+// readConfigFiles reads cortex.yaml and any YAML files in the cortex.d directory (keyed by their paths relative to the app root)
+func readConfigFiles(appRoot string) (map[string][]byte, error) {
+	configBytes, err := files.ReadFileBytesErrPath(filepath.Join(appRoot, "cortex.yaml"), "cortex.yaml")
+	if err != nil {
+		return nil, err
+	}
+	configFiles := map[string][]byte{
+		"cortex.yaml": configBytes,
+	}
+
+	configDir := filepath.Join(appRoot, userconfig.ConfigDirName)
+	if !files.IsDir(configDir) {
+		return configFiles, nil
+	}
+
+	for _, configPath := range yamlPaths(configDir) {
+		relativePath := strings.TrimPrefix(configPath, appRoot+"/")
+		configBytes, err := files.ReadFileBytesErrPath(configPath, relativePath)
+		if err != nil {
+			return nil, err
+		}
+		configFiles[relativePath] = configBytes
+	}
+
+	return configFiles, nil
+}
+
+// configVariables resolves the variables which are referenced in the configuration files from the values files (if provided) and the environment
+func configVariables(configFiles map[string][]byte) (map[string]string, error) {
+	values := map[string]string{}
+	for _, valuesPath := range flagDeployValues {
+		fileValues, err := userconfig.ReadValuesFile(valuesPath)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fileValues {
+			values[name] = value
+		}
+	}
+
+	variables := map[string]string{}
+	for _, configBytes := range configFiles {
+		for _, name := range userconfig.ReferencedVariables(configBytes) {
+			if value, ok := values[name]; ok {
+				variables[name] = value
+			} else if value, ok := os.LookupEnv(name); ok {
+				variables[name] = value
+			}
+		}
+	}
+
+	return variables, nil
+}
+
 func readConfig() (*userconfig.Config, error) {
 	appRoot := mustAppRoot()
-	config, err := userconfig.ReadConfigFile(filepath.Join(appRoot, "cortex.yaml"), "cortex.yaml")
+	configFiles, err := readConfigFiles(appRoot)
+	if err != nil {
+		return nil, err
+	}
+	variables, err := configVariables(configFiles)
+	if err != nil {
+		return nil, err
+	}
+	config, err := userconfig.NewFromFiles(configFiles, variables)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,64 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+)
+
+// The revisions of each API from this client's last deploy are sent with the next deploy,
+// so that the operator can reject the deploy if another client has changed the APIs since then
+
+func revisionsPath(appName string) string {
+	return filepath.Join(localDir, "revisions", flagEnv, appName+".json")
+}
+
+// Returns nil if there are no saved revisions for the app
+func readRevisions(appName string) []byte {
+	revisionsBytes, err := files.ReadFileBytes(revisionsPath(appName))
+	if err != nil {
+		return nil
+	}
+	return revisionsBytes
+}
+
+func saveRevisions(ctx *context.Context) error {
+	revisions := make(map[string]int64, len(ctx.APIs))
+	for apiName, api := range ctx.APIs {
+		revisions[apiName] = api.Revision
+	}
+
+	revisionsBytes, err := json.Marshal(revisions)
+	if err != nil {
+		return err
+	}
+
+	path := revisionsPath(ctx.App.Name)
+	if err := files.MkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return files.WriteFile(revisionsBytes, path)
+}
+
+func deleteRevisions(appName string) {
+	os.Remove(revisionsPath(appName))
+}
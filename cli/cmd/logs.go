@@ -24,18 +24,32 @@ import (
 
 	"github.com/cortexlabs/cortex/pkg/lib/console"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
 )
 
+var flagLogsSince string
+var flagLogsLevel string
+var flagLogsPod string
+var flagLogsQuery string
+var flagLogsLimit int64
+
 func init() {
+	logsCmd.PersistentFlags().StringVar(&flagLogsSince, "since", "", "show historical logs from this far back, e.g. 30m or 24h, rather than streaming the latest logs (default: 1h if another of the historical logs flags is set)")
+	logsCmd.PersistentFlags().StringVar(&flagLogsLevel, "level", "", "only show historical logs at or above this level (debug, info, warning, error, or critical)")
+	logsCmd.PersistentFlags().StringVar(&flagLogsPod, "pod", "", "only show historical logs from the pods whose names start with this prefix")
+	logsCmd.PersistentFlags().StringVarP(&flagLogsQuery, "query", "q", "", "only show historical logs which contain this text (case sensitive)")
+	logsCmd.PersistentFlags().Int64Var(&flagLogsLimit, "limit", 0, "the maximum number of historical log lines to show (default: 100)")
 	addAppNameFlag(logsCmd)
 	addEnvFlag(logsCmd)
 }
 
 var logsCmd = &cobra.Command{
 	Use:   "logs API_NAME",
-	Short: "stream logs from an api",
+	Short: "stream logs from an api, or query its historical logs",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		telemetry.Event("cli.logs")
@@ -47,6 +61,11 @@ var logsCmd = &cobra.Command{
 			exit.Error(err)
 		}
 
+		if flagLogsSince != "" || flagLogsLevel != "" || flagLogsPod != "" || flagLogsQuery != "" || flagLogsLimit != 0 {
+			queryLogs(appName, resourceName)
+			return
+		}
+
 		err = StreamLogs(appName, resourceName, resource.APIType.String())
 		if err != nil {
 			// note: if modifying this string, search the codebase for it and change all occurrences
@@ -58,3 +77,52 @@ var logsCmd = &cobra.Command{
 		}
 	},
 }
+
+func queryLogs(appName string, apiName string) {
+	params := map[string]string{"appName": appName}
+	if flagLogsSince != "" {
+		params["since"] = flagLogsSince
+	}
+	if flagLogsLevel != "" {
+		params["level"] = flagLogsLevel
+	}
+	if flagLogsPod != "" {
+		params["pod"] = flagLogsPod
+	}
+	if flagLogsQuery != "" {
+		params["q"] = flagLogsQuery
+	}
+	if flagLogsLimit != 0 {
+		params["limit"] = s.Int64(flagLogsLimit)
+	}
+
+	httpResponse, err := HTTPGet("/v1/logs/"+apiName, params)
+	if err != nil {
+		exit.Error(err)
+	}
+
+	var logsResponse schema.LogsResponse
+	err = json.Unmarshal(httpResponse, &logsResponse)
+	if err != nil {
+		exit.Error(err, "/v1/logs/"+apiName, string(httpResponse))
+	}
+
+	if len(logsResponse.Records) == 0 {
+		fmt.Println("no logs matched")
+		return
+	}
+	if logsResponse.Truncated {
+		fmt.Println(console.Bold(fmt.Sprintf("---- showing the latest %d lines which matched (use --limit to show more) ----", len(logsResponse.Records))))
+	}
+	for _, record := range logsResponse.Records {
+		fmt.Printf("%s %s %s: %s\n", record.Timestamp.Local().Format("2006-01-02 15:04:05"), record.Pod, record.Container, logRecordStr(record))
+	}
+}
+
+// logRecordStr returns the record's message, or its fields if it's a JSON line without a message
+func logRecordStr(record schema.LogRecord) string {
+	if record.Message != "" || len(record.Fields) == 0 {
+		return record.Message
+	}
+	return s.ObjFlatNoQuotes(record.Fields)
+}
@@ -106,7 +106,11 @@ var predictCmd = &cobra.Command{
 			exit.Error(ErrorAPINotReady(apiName, apiGroupStatus.Message()))
 		}
 
-		apiURL := urls.Join(resourcesRes.APIsBaseURL, *api.Endpoint)
+		if api.IsClusterLocal() {
+			exit.Error(ErrorClusterLocalAPI(apiName, resourcesRes.ClusterLocalAPIURLs[apiName]))
+		}
+
+		apiURL := urls.Join(apisBaseURL(resourcesRes, api), *api.Endpoint)
 		if predictDebug {
 			apiURL += "?debug=true"
 		}
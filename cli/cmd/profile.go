@@ -0,0 +1,88 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+)
+
+var flagProfileReplica string
+var flagProfileType string
+var flagProfileDuration string
+
+func init() {
+	profileCmd.PersistentFlags().StringVar(&flagProfileReplica, "replica", "", "name of the replica to profile (default: one of the api's running replicas)")
+	profileCmd.PersistentFlags().StringVar(&flagProfileType, "type", "cpu", "cpu (a flamegraph of where the replica's python processes spend their time) or dump (the current stack of each of their threads)")
+	profileCmd.PersistentFlags().StringVar(&flagProfileDuration, "duration", "30s", "how long to sample cpu profiles for (max: 5m)")
+	addAppNameFlag(profileCmd)
+	addEnvFlag(profileCmd)
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile API_NAME",
+	Short: "profile one of an api's replicas, and get a link to download the profile",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.profile")
+
+		apiName := args[0]
+		appName, err := AppNameFromFlagOrConfig()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		params := map[string]string{
+			"appName":  appName,
+			"type":     flagProfileType,
+			"duration": flagProfileDuration,
+		}
+		if flagProfileReplica != "" {
+			params["replica"] = flagProfileReplica
+		}
+
+		if flagProfileType == "cpu" {
+			fmt.Printf("profiling for %s ...\n", flagProfileDuration)
+		}
+		httpResponse, err := HTTPPostJSONData("/v1/apis/"+apiName+"/profile", nil, params)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		var profileResponse schema.ProfileResponse
+		err = json.Unmarshal(httpResponse, &profileResponse)
+		if err != nil {
+			exit.Error(err, "/v1/apis/"+apiName+"/profile", string(httpResponse))
+		}
+		fmt.Print(profileResponseStr(&profileResponse))
+	},
+}
+
+func profileResponseStr(profileResponse *schema.ProfileResponse) string {
+	out := console.Bold(fmt.Sprintf("profiled replica %s of %s api", profileResponse.Replica, profileResponse.APIName)) + "\n\n"
+	out += fmt.Sprintf("s3 path: %s\n", profileResponse.S3Path)
+	out += fmt.Sprintf("download (the link expires at %s): %s\n", libtime.LocalTimestamp(&profileResponse.ExpiresAt), profileResponse.URL)
+	return out
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+)
+
+func init() {
+	addAppNameFlag(promoteCmd)
+	addEnvFlag(promoteCmd)
+}
+
+var promoteCmd = &cobra.Command{
+	Use:   "promote API_NAME",
+	Short: "route an api's endpoint to its latest blue/green update",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.promote")
+
+		apiName := args[0]
+		appName, err := AppNameFromFlagOrConfig()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		params := map[string]string{
+			"appName": appName,
+			"apiName": apiName,
+		}
+		httpResponse, err := HTTPPostJSONData("/v1/promote", nil, params)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		var promoteResponse schema.PromoteResponse
+		err = json.Unmarshal(httpResponse, &promoteResponse)
+		if err != nil {
+			exit.Error(err, "/v1/promote", string(httpResponse))
+		}
+		fmt.Println(console.Bold(promoteResponse.Message))
+	},
+}
@@ -104,13 +104,21 @@ func Execute() {
 	rootCmd.AddCommand(deployCmd)
 	rootCmd.AddCommand(getCmd)
 	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(profileCmd)
+	rootCmd.AddCommand(execCmd)
 	rootCmd.AddCommand(predictCmd)
+	rootCmd.AddCommand(promoteCmd)
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
+	rootCmd.AddCommand(transferCmd)
+	rootCmd.AddCommand(diffCmd)
 	rootCmd.AddCommand(deleteCmd)
 
 	rootCmd.AddCommand(clusterCmd)
 	rootCmd.AddCommand(versionCmd)
 
 	rootCmd.AddCommand(configureCmd)
+	rootCmd.AddCommand(schemaCmd)
 	rootCmd.AddCommand(completionCmd)
 
 	updateRootUsage()
@@ -143,7 +151,7 @@ func updateRootUsage() {
 }
 
 func printLeadingNewLine() {
-	if len(os.Args) == 2 && os.Args[1] == "completion" {
+	if len(os.Args) == 2 && (os.Args[1] == "completion" || os.Args[1] == "schema") {
 		return
 	}
 	fmt.Println("")
@@ -0,0 +1,65 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+)
+
+func init() {
+	addAppNameFlag(transferCmd)
+	addEnvFlag(transferCmd)
+}
+
+var transferCmd = &cobra.Command{
+	Use:   "transfer API_NAME OWNER_ARN",
+	Short: "transfer ownership of an api to another IAM user or role",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.transfer")
+
+		apiName := args[0]
+		appName, err := AppNameFromFlagOrConfig()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		params := map[string]string{
+			"appName": appName,
+			"owner":   args[1],
+		}
+		httpResponse, err := HTTPPostJSONData("/v1/apis/"+apiName+"/owner", nil, params)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		var transferResponse schema.TransferAPIOwnershipResponse
+		err = json.Unmarshal(httpResponse, &transferResponse)
+		if err != nil {
+			exit.Error(err, "/v1/apis/"+apiName+"/owner", string(httpResponse))
+		}
+		fmt.Println(console.Bold(transferResponse.Message))
+	},
+}
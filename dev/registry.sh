@@ -37,19 +37,29 @@ function ecr_login() {
 function create_registry() {
   aws ecr create-repository --repository-name=cortexlabs/python-serve --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/python-serve-gpu --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/python-serve-conda --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/python-serve-conda-gpu --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/tf-serve --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/tf-serve-gpu --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/tf-api --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/onnx-serve --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/onnx-serve-gpu --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/sklearn-serve --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/xgboost-serve --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/lightgbm-serve --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/triton-serve --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/triton-api --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/pipeline-api --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/operator --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/manager --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/downloader --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/cluster-autoscaler --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/metrics-server --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/nvidia --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/dcgm-exporter --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/fluentd --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/statsd --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/redis --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/istio-proxy --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/istio-pilot --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/istio-citadel --region=$REGISTRY_REGION || true
@@ -131,8 +141,10 @@ elif [ "$cmd" = "update" ]; then
     build_and_push $ROOT/images/cluster-autoscaler cluster-autoscaler latest
     build_and_push $ROOT/images/metrics-server metrics-server latest
     build_and_push $ROOT/images/nvidia nvidia latest
+    build_and_push $ROOT/images/dcgm-exporter dcgm-exporter latest
     build_and_push $ROOT/images/fluentd fluentd latest
     build_and_push $ROOT/images/statsd statsd latest
+    build_and_push $ROOT/images/redis redis latest
     build_and_push $ROOT/images/istio-proxy istio-proxy latest
     build_and_push $ROOT/images/istio-pilot istio-pilot latest
     build_and_push $ROOT/images/istio-citadel istio-citadel latest
@@ -142,9 +154,17 @@ elif [ "$cmd" = "update" ]; then
   build_and_push $ROOT/images/manager manager latest
   build_and_push $ROOT/images/python-serve python-serve latest
   build_and_push $ROOT/images/python-serve-gpu python-serve-gpu latest
+  build_and_push $ROOT/images/python-serve-conda python-serve-conda latest
+  build_and_push $ROOT/images/python-serve-conda-gpu python-serve-conda-gpu latest
   build_and_push $ROOT/images/tf-api tf-api latest
   build_and_push $ROOT/images/onnx-serve onnx-serve latest
   build_and_push $ROOT/images/onnx-serve-gpu onnx-serve-gpu latest
+  build_and_push $ROOT/images/sklearn-serve sklearn-serve latest
+  build_and_push $ROOT/images/xgboost-serve xgboost-serve latest
+  build_and_push $ROOT/images/lightgbm-serve lightgbm-serve latest
+  build_and_push $ROOT/images/triton-serve triton-serve latest
+  build_and_push $ROOT/images/triton-api triton-api latest
+  build_and_push $ROOT/images/pipeline-api pipeline-api latest
   build_and_push $ROOT/images/downloader downloader latest
 
   cleanup
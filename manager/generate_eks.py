@@ -108,6 +108,21 @@ def apply_gpu_settings(nodegroup):
     return merge_override(nodegroup, gpu_settings)
 
 
+def apply_arm_settings(nodegroup, config):
+    arm_settings = {
+        "name": "ng-cortex-worker-arm64",
+        "instanceType": config["arm_instance_type"],
+        "minSize": config["arm_min_instances"],
+        "maxSize": config["arm_max_instances"],
+        "desiredCapacity": config["arm_min_instances"],
+        "tags": {
+            "k8s.io/cluster-autoscaler/node-template/label/kubernetes.io/arch": "arm64",
+        },
+    }
+
+    return merge_override(nodegroup, arm_settings)
+
+
 def is_gpu(instance_type):
     return instance_type.startswith("g") or instance_type.startswith("p")
 
@@ -163,6 +178,14 @@ def generate_eks(configmap_yaml_path):
 
         eks["nodeGroups"].append(backup_nodegroup)
 
+    if cluster_configmap.get("arm_instance_type") is not None:
+        arm_nodegroup = deepcopy(default_nodegroup)
+        apply_worker_settings(arm_nodegroup)
+        apply_clusterconfig(arm_nodegroup, cluster_configmap)
+        apply_arm_settings(arm_nodegroup, cluster_configmap)
+
+        eks["nodeGroups"].append(arm_nodegroup)
+
     print(yaml.dump(eks, Dumper=IgnoreAliases, default_flow_style=False, default_style=""))
 
 
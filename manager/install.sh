@@ -115,6 +115,23 @@ function ensure_eks() {
     fi
     echo "✓"
   fi
+
+  if [ -n "$CORTEX_ARM_INSTANCE_TYPE" ]; then
+    asg_arm_info=$(aws autoscaling describe-auto-scaling-groups --region $CORTEX_REGION --query "AutoScalingGroups[?contains(Tags[?Key==\`alpha.eksctl.io/cluster-name\`].Value, \`$CORTEX_CLUSTER_NAME\`)]|[?contains(Tags[?Key==\`alpha.eksctl.io/nodegroup-name\`].Value, \`ng-cortex-worker-arm64\`)]")
+    asg_arm_name=$(echo "$asg_arm_info" | jq -r 'first | .AutoScalingGroupName')
+
+    if [ "$(echo "$asg_arm_info" | jq -r 'first | .MinSize')" != "$CORTEX_ARM_MIN_INSTANCES" ]; then
+      echo -n "￮ updating arm min instances to $CORTEX_ARM_MIN_INSTANCES "
+      aws autoscaling update-auto-scaling-group --region $CORTEX_REGION --auto-scaling-group-name $asg_arm_name --min-size=$CORTEX_ARM_MIN_INSTANCES
+      echo "✓"
+    fi
+
+    if [ "$(echo "$asg_arm_info" | jq -r 'first | .MaxSize')" != "$CORTEX_ARM_MAX_INSTANCES" ]; then
+      echo -n "￮ updating arm max instances to $CORTEX_ARM_MAX_INSTANCES "
+      aws autoscaling update-auto-scaling-group --region $CORTEX_REGION --auto-scaling-group-name $asg_arm_name --max-size=$CORTEX_ARM_MAX_INSTANCES
+      echo "✓"
+    fi
+  fi
 }
 
 function main() {
@@ -125,6 +142,7 @@ function main() {
   eksctl utils write-kubeconfig --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION | grep -v "saved kubeconfig as" | grep -v "using region" | grep -v "eksctl version" || true
 
   envsubst < manifests/namespace.yaml | kubectl apply -f - >/dev/null
+  kubectl apply -f manifests/priority-classes.yaml >/dev/null
 
   # pre-download images on cortex cluster up
   if [ "$arg1" != "--update" ]; then
@@ -146,6 +164,7 @@ function main() {
   echo -n "￮ configuring networking "
   setup_istio
   envsubst < manifests/apis.yaml | kubectl apply -f - >/dev/null
+  kubectl apply -f manifests/cortex-api-crd.yaml >/dev/null
   echo "✓"
 
   echo -n "￮ configuring autoscaling "
@@ -157,6 +176,10 @@ function main() {
   kubectl -n=cortex delete --ignore-not-found=true daemonset fluentd >/dev/null 2>&1  # Pods in DaemonSets cannot be modified
   until [ "$(kubectl -n=cortex get pods -l app=fluentd -o json | jq -j '.items | length')" -eq "0" ]; do echo -n "."; sleep 2; done
   envsubst < manifests/fluentd.yaml | kubectl apply -f - >/dev/null
+  if [ -n "$CORTEX_ARM_INSTANCE_TYPE" ]; then
+    kubectl -n=cortex delete --ignore-not-found=true daemonset fluentd-arm64 >/dev/null 2>&1
+    envsubst < manifests/fluentd-arm64.yaml | kubectl apply -f - >/dev/null
+  fi
   echo "✓"
 
   echo -n "￮ configuring metrics "
@@ -164,6 +187,10 @@ function main() {
   until [ "$(kubectl -n=cortex get pods -l name=cloudwatch-agent-statsd -o json | jq -j '.items | length')" -eq "0" ]; do echo -n "."; sleep 2; done
   envsubst < manifests/metrics-server.yaml | kubectl apply -f - >/dev/null
   envsubst < manifests/statsd.yaml | kubectl apply -f - >/dev/null
+  if [ -n "$CORTEX_ARM_INSTANCE_TYPE" ]; then
+    kubectl -n=cortex delete --ignore-not-found=true daemonset cloudwatch-agent-statsd-arm64 >/dev/null 2>&1
+    envsubst < manifests/statsd-arm64.yaml | kubectl apply -f - >/dev/null
+  fi
   echo "✓"
 
   if [[ "$CORTEX_INSTANCE_TYPE" == p* ]] || [[ "$CORTEX_INSTANCE_TYPE" == g* ]]; then
@@ -171,6 +198,9 @@ function main() {
     kubectl -n=kube-system delete --ignore-not-found=true daemonset nvidia-device-plugin-daemonset >/dev/null 2>&1  # Pods in DaemonSets cannot be modified
     until [ "$(kubectl -n=kube-system get pods -l name=nvidia-device-plugin-ds -o json | jq -j '.items | length')" -eq "0" ]; do echo -n "."; sleep 2; done
     envsubst < manifests/nvidia.yaml | kubectl apply -f - >/dev/null
+    kubectl -n=kube-system delete --ignore-not-found=true daemonset dcgm-exporter >/dev/null 2>&1  # Pods in DaemonSets cannot be modified
+    until [ "$(kubectl -n=kube-system get pods -l app=dcgm-exporter -o json | jq -j '.items | length')" -eq "0" ]; do echo -n "."; sleep 2; done
+    envsubst < manifests/dcgm-exporter.yaml | kubectl apply -f - >/dev/null
     echo "✓"
   fi
 
@@ -196,7 +226,7 @@ function main() {
   fi
 
   echo -n "￮ configuring cli "
-  python update_cli_config.py "/.cortex/cli.yaml" "$CORTEX_ENVIRONMENT" "$operator_endpoint" "$CORTEX_AWS_ACCESS_KEY_ID" "$CORTEX_AWS_SECRET_ACCESS_KEY"
+  python update_cli_config.py "/.cortex/cli.yaml" "$CORTEX_ENVIRONMENT" "$operator_endpoint" "$CORTEX_CLUSTER_NAME" "$CORTEX_AWS_ACCESS_KEY_ID" "$CORTEX_AWS_SECRET_ACCESS_KEY"
   echo "✓"
 
   echo -e "\ncortex is ready!"
@@ -280,6 +310,13 @@ function setup_istio() {
     sleep 3
   done
 
+  # istio's proxies sample a percentage of requests, and report their spans to the collector's zipkin receiver
+  export CORTEX_TRACING_SAMPLE_PERCENTAGE=1.0
+  if [ -n "$CORTEX_TRACING_SAMPLE_RATE" ]; then
+    export CORTEX_TRACING_SAMPLE_PERCENTAGE=$(python -c "print($CORTEX_TRACING_SAMPLE_RATE * 100)")
+  fi
+  export CORTEX_TRACING_ZIPKIN_ADDRESS
+
   envsubst < manifests/istio-values.yaml | helm template istio-manifests/istio --values - --name istio --namespace istio-system | kubectl apply -f - >/dev/null
 }
 
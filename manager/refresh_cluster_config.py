@@ -63,7 +63,14 @@ def refresh_yaml(configmap_yaml_path, output_yaml_path):
     cluster_configmap_str = cluster_configmap["data"]["cluster.yaml"]
     cluster_config = yaml.safe_load(cluster_configmap_str)
 
-    asgs = get_autoscaling_group()
+    asgs = []
+    for group in get_autoscaling_group():
+        if extract_nodegroup_name(group) == "ng-cortex-worker-arm64":
+            cluster_config["arm_min_instances"] = group["MinSize"]
+            cluster_config["arm_max_instances"] = group["MaxSize"]
+        else:
+            asgs.append(group)
+
     # only possible when backup is enabled
 
     if cluster_config["spot"] and cluster_config.get("spot_config", {}).get(
@@ -20,12 +20,14 @@ def update_cli_config(
     cli_config_file_path,
     cortex_environment,
     operator_endpoint,
+    cluster_name,
     aws_access_key_id,
     aws_secret_access_key,
 ):
     cli_env_config = {
         "name": cortex_environment,
         "operator_endpoint": operator_endpoint,
+        "cluster_name": cluster_name,
         "aws_access_key_id": aws_access_key_id,
         "aws_secret_access_key": aws_secret_access_key,
     }
@@ -66,6 +68,7 @@ if __name__ == "__main__":
         cli_config_file_path=sys.argv[1],
         cortex_environment=sys.argv[2],
         operator_endpoint=sys.argv[3],
-        aws_access_key_id=sys.argv[4],
-        aws_secret_access_key=sys.argv[5],
+        cluster_name=sys.argv[4],
+        aws_access_key_id=sys.argv[5],
+        aws_secret_access_key=sys.argv[6],
     )
@@ -27,14 +27,22 @@ var (
 	ClusterConfigPath = "/configs/cluster/cluster.yaml"
 	ClusterConfigName = "cluster-config"
 
+	PredictorConfigMountPath = "/configs/predictor"
+
 	AppsDir             = "apps"
 	DeploymentsDir      = "deployments"
 	APIsDir             = "apis"
 	ProjectsDir         = "projects"
+	ProjectFilesDir     = "project_files"
 	ContextsDir         = "contexts"
 	ResourceStatusesDir = "resource_statuses"
 	WorkloadSpecsDir    = "workload_specs"
 	MetadataDir         = "metadata"
+	CondaEnvsDir        = "conda_envs"
+	SageMakerModelsDir  = "sagemaker_models"
+	APIRevisionsDir     = "api_revisions"
+	ProfilesDir         = "profiles"
+	AuditDir            = "audit"
 
 	K8sNamespace = "cortex"
 
@@ -0,0 +1,38 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"regexp"
+)
+
+// The CPU architectures of EC2 instances (they match the nodes' kubernetes.io/arch labels)
+const (
+	AMD64Arch = "amd64"
+	ARM64Arch = "arm64"
+)
+
+// Graviton instance families, e.g. a1, m6g, c6gd, r6g, and t4g
+var _gravitonInstanceTypeRegex = regexp.MustCompile(`^(a1|[a-z]+[0-9]+g[a-z]*)\.`)
+
+// InstanceArch returns the CPU architecture of the instance type (Graviton instance types are arm64, and all others are amd64)
+func InstanceArch(instanceType string) string {
+	if _gravitonInstanceTypeRegex.MatchString(instanceType) {
+		return ARM64Arch
+	}
+	return AMD64Arch
+}
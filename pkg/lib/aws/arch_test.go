@@ -0,0 +1,33 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceArch(t *testing.T) {
+	for _, instanceType := range []string{"a1.large", "m6g.xlarge", "c6gd.2xlarge", "r6g.medium", "t4g.micro"} {
+		require.Equal(t, ARM64Arch, InstanceArch(instanceType), instanceType)
+	}
+
+	for _, instanceType := range []string{"m5.large", "c5d.2xlarge", "g4dn.xlarge", "p3dn.24xlarge", "t3.medium", "inf1.xlarge"} {
+		require.Equal(t, AMD64Arch, InstanceArch(instanceType), instanceType)
+	}
+}
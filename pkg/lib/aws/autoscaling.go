@@ -17,7 +17,9 @@ limitations under the License.
 package aws
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 )
 
@@ -59,3 +61,56 @@ func (c *Client) AutoscalingGroups(tags map[string]string) ([]*autoscaling.Group
 
 	return asgs, nil
 }
+
+// AutoscalingGroupInstanceType returns the instance type which the cluster autoscaler uses to model the group's instances
+// (for groups with a mixed instances policy, this is the first instance type in the policy)
+func (c *Client) AutoscalingGroupInstanceType(asg *autoscaling.Group) (string, error) {
+	launchTemplate := asg.LaunchTemplate
+
+	if policy := asg.MixedInstancesPolicy; policy != nil && policy.LaunchTemplate != nil {
+		for _, override := range policy.LaunchTemplate.Overrides {
+			if override.InstanceType != nil {
+				return *override.InstanceType, nil
+			}
+		}
+		launchTemplate = policy.LaunchTemplate.LaunchTemplateSpecification
+	}
+
+	if launchTemplate != nil {
+		input := &ec2.DescribeLaunchTemplateVersionsInput{
+			LaunchTemplateId:   launchTemplate.LaunchTemplateId,
+			LaunchTemplateName: launchTemplate.LaunchTemplateName,
+		}
+		if launchTemplate.Version != nil {
+			input.Versions = []*string{launchTemplate.Version}
+		} else {
+			input.Versions = []*string{aws.String("$Default")}
+		}
+
+		output, err := c.ec2.DescribeLaunchTemplateVersions(input)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		for _, version := range output.LaunchTemplateVersions {
+			if version.LaunchTemplateData != nil && version.LaunchTemplateData.InstanceType != nil {
+				return *version.LaunchTemplateData.InstanceType, nil
+			}
+		}
+	}
+
+	if asg.LaunchConfigurationName != nil {
+		output, err := c.autoscaling.DescribeLaunchConfigurations(&autoscaling.DescribeLaunchConfigurationsInput{
+			LaunchConfigurationNames: []*string{asg.LaunchConfigurationName},
+		})
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		for _, launchConfiguration := range output.LaunchConfigurations {
+			if launchConfiguration.InstanceType != nil {
+				return *launchConfiguration.InstanceType, nil
+			}
+		}
+	}
+
+	return "", errors.New("unable to determine the instance type of autoscaling group " + aws.StringValue(asg.AutoScalingGroupName)) // unexpected
+}
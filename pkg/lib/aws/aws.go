@@ -22,12 +22,14 @@ package aws
 import (
 	"sort"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/aws/aws-sdk-go/service/sts"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
@@ -41,6 +43,10 @@ type Client struct {
 	S3                   *s3.S3
 	stsClient            *sts.STS
 	autoscaling          *autoscaling.AutoScaling
+	ec2                  *ec2.EC2
+	secretsManager       *secretsmanager.SecretsManager
+	ssm                  *ssm.SSM
+	sns                  *sns.SNS
 	CloudWatchLogsClient *cloudwatchlogs.CloudWatchLogs
 	CloudWatchMetrics    *cloudwatch.CloudWatch
 	AccountID            string
@@ -60,20 +66,14 @@ func init() {
 }
 
 func New(region string, bucket string, withAccountID bool) (*Client, error) {
-	sess := session.Must(session.NewSession(&aws.Config{
-		Region:     aws.String(region),
-		DisableSSL: aws.Bool(false),
-	}))
+	sess := regionSession(region)
 
 	bucketLocation, err := GetBucketRegion(bucket)
 	if err != nil {
 		return nil, err
 	}
 
-	bucketSess := session.Must(session.NewSession(&aws.Config{
-		Region:     aws.String(bucketLocation),
-		DisableSSL: aws.Bool(false),
-	}))
+	bucketSess := regionSession(bucketLocation)
 
 	awsClient := &Client{
 		Bucket:               bucket,
@@ -81,6 +81,10 @@ func New(region string, bucket string, withAccountID bool) (*Client, error) {
 		S3:                   s3.New(bucketSess),
 		stsClient:            sts.New(sess),
 		autoscaling:          autoscaling.New(sess),
+		ec2:                  ec2.New(sess),
+		secretsManager:       secretsmanager.New(sess),
+		ssm:                  ssm.New(sess),
+		sns:                  sns.New(sess),
 		CloudWatchMetrics:    cloudwatch.New(sess),
 		CloudWatchLogsClient: cloudwatchlogs.New(sess),
 	}
@@ -97,16 +101,12 @@ func New(region string, bucket string, withAccountID bool) (*Client, error) {
 	return awsClient, nil
 }
 
+// NewFromS3Path returns a client in the region of the S3 path's bucket; clients are pooled per bucket, so the returned client may be shared
 func NewFromS3Path(s3Path string, withAccountID bool) (*Client, error) {
 	bucket, _, err := SplitS3Path(s3Path)
 	if err != nil {
 		return nil, err
 	}
 
-	region, err := GetBucketRegion(bucket)
-	if err != nil {
-		return nil, err
-	}
-
-	return New(region, bucket, withAccountID)
+	return pooledClient(bucket, withAccountID)
 }
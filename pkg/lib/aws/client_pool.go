@@ -0,0 +1,134 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// The default transport only keeps 2 idle connections per host, so concurrent requests to a bucket would keep re-dialing
+var _httpClient = &http.Client{
+	Transport: func() *http.Transport {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConns = 256
+		transport.MaxIdleConnsPerHost = 64
+		return transport
+	}(),
+}
+
+var _sessions = map[string]*session.Session{}
+var _sessionsMutex sync.Mutex
+
+// regionSession returns a session for the region which is shared by all clients in the region (sessions are safe for concurrent use)
+func regionSession(region string) *session.Session {
+	_sessionsMutex.Lock()
+	defer _sessionsMutex.Unlock()
+
+	if sess, ok := _sessions[region]; ok {
+		return sess
+	}
+
+	config := &aws.Config{
+		DisableSSL: aws.Bool(false),
+		HTTPClient: _httpClient,
+	}
+	if region != "" {
+		config.Region = aws.String(region)
+	}
+
+	sess := session.Must(session.NewSession(withRetries(config)))
+	_sessions[region] = sess
+	return sess
+}
+
+// bucket name -> region
+var _bucketRegions = map[string]string{}
+var _bucketRegionsMutex sync.RWMutex
+
+// lookupBucketRegion is overridden in tests
+var lookupBucketRegion = func(bucket string) (string, error) {
+	return s3manager.GetBucketRegion(aws.BackgroundContext(), regionSession(""), bucket, endpoints.UsWest2RegionID)
+}
+
+// GetBucketRegion returns the bucket's region; successful lookups are cached, since a bucket's region cannot change
+func GetBucketRegion(bucket string) (string, error) {
+	_bucketRegionsMutex.RLock()
+	region, ok := _bucketRegions[bucket]
+	_bucketRegionsMutex.RUnlock()
+	if ok {
+		return region, nil
+	}
+
+	region, err := lookupBucketRegion(bucket)
+	if err != nil {
+		return "", ErrorBucketInaccessible(bucket)
+	}
+
+	_bucketRegionsMutex.Lock()
+	_bucketRegions[bucket] = region
+	_bucketRegionsMutex.Unlock()
+
+	return region, nil
+}
+
+type clientPoolKey struct {
+	bucket        string
+	withAccountID bool
+}
+
+var _clientPool = map[clientPoolKey]*Client{}
+var _clientPoolMutex sync.Mutex
+
+// pooledClient returns a client for the bucket (in the bucket's region), creating it if it is not in the pool
+func pooledClient(bucket string, withAccountID bool) (*Client, error) {
+	key := clientPoolKey{bucket: bucket, withAccountID: withAccountID}
+
+	_clientPoolMutex.Lock()
+	client, ok := _clientPool[key]
+	if !ok && !withAccountID {
+		// a client which has the account ID can be used in place of one which doesn't
+		client, ok = _clientPool[clientPoolKey{bucket: bucket, withAccountID: true}]
+	}
+	_clientPoolMutex.Unlock()
+	if ok {
+		return client, nil
+	}
+
+	region, err := GetBucketRegion(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err = New(region, bucket, withAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	_clientPoolMutex.Lock()
+	defer _clientPoolMutex.Unlock()
+	if existing, ok := _clientPool[key]; ok {
+		return existing, nil // another caller created the client concurrently
+	}
+	_clientPool[key] = client
+	return client, nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func stubBucketRegions(t *testing.T, regions map[string]string) map[string]int {
+	lookups := map[string]int{}
+
+	original := lookupBucketRegion
+	lookupBucketRegion = func(bucket string) (string, error) {
+		lookups[bucket]++
+		if region, ok := regions[bucket]; ok {
+			return region, nil
+		}
+		return "", fmt.Errorf("bucket %s not found", bucket)
+	}
+
+	t.Cleanup(func() {
+		lookupBucketRegion = original
+		_bucketRegions = map[string]string{}
+		_clientPool = map[clientPoolKey]*Client{}
+	})
+
+	return lookups
+}
+
+func TestGetBucketRegion(t *testing.T) {
+	lookups := stubBucketRegions(t, map[string]string{"bucket-a": "us-east-1"})
+
+	for i := 0; i < 3; i++ {
+		region, err := GetBucketRegion("bucket-a")
+		require.NoError(t, err)
+		require.Equal(t, "us-east-1", region)
+	}
+	require.Equal(t, 1, lookups["bucket-a"])
+
+	// failed lookups are not cached
+	for i := 0; i < 2; i++ {
+		_, err := GetBucketRegion("missing")
+		require.Equal(t, ErrBucketInaccessible, errors.Cause(err).(Error).Kind)
+	}
+	require.Equal(t, 2, lookups["missing"])
+}
+
+func TestNewFromS3PathPooling(t *testing.T) {
+	lookups := stubBucketRegions(t, map[string]string{
+		"bucket-a": "us-east-1",
+		"bucket-b": "us-east-1",
+		"bucket-c": "eu-west-1",
+	})
+
+	clientA, err := NewFromS3Path("s3://bucket-a/model-1", false)
+	require.NoError(t, err)
+	require.Equal(t, "bucket-a", clientA.Bucket)
+	require.Equal(t, "us-east-1", clientA.Region)
+
+	clientA2, err := NewFromS3Path("s3://bucket-a/model-2/", false)
+	require.NoError(t, err)
+	require.Same(t, clientA, clientA2)
+	require.Equal(t, 1, lookups["bucket-a"])
+
+	clientB, err := NewFromS3Path("s3://bucket-b/model", false)
+	require.NoError(t, err)
+	require.True(t, clientA != clientB)
+	require.Same(t, clientA.S3.Client.Config.HTTPClient, clientB.S3.Client.Config.HTTPClient)
+	require.Equal(t, "us-east-1", *clientB.S3.Client.Config.Region)
+
+	clientC, err := NewFromS3Path("s3://bucket-c/model", false)
+	require.NoError(t, err)
+	require.Equal(t, "eu-west-1", clientC.Region)
+	require.Equal(t, "eu-west-1", *clientC.S3.Client.Config.Region)
+
+	_, err = NewFromS3Path("s3://missing/model", false)
+	require.Error(t, err)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// PutDashboard creates or replaces a CloudWatch dashboard
+func (c *Client) PutDashboard(name string, body string) error {
+	_, err := c.CloudWatchMetrics.PutDashboard(&cloudwatch.PutDashboardInput{
+		DashboardName: aws.String(name),
+		DashboardBody: aws.String(body),
+	})
+	if err != nil {
+		return errors.Wrap(err, "dashboard "+name)
+	}
+	return nil
+}
+
+// ListDashboards returns the names of the CloudWatch dashboards whose names start with the prefix
+func (c *Client) ListDashboards(prefix string) ([]string, error) {
+	var names []string
+	err := c.CloudWatchMetrics.ListDashboardsPages(&cloudwatch.ListDashboardsInput{
+		DashboardNamePrefix: aws.String(prefix),
+	}, func(output *cloudwatch.ListDashboardsOutput, lastPage bool) bool {
+		for _, entry := range output.DashboardEntries {
+			if entry.DashboardName != nil {
+				names = append(names, *entry.DashboardName)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return names, nil
+}
+
+// DeleteDashboards deletes CloudWatch dashboards (which must exist)
+func (c *Client) DeleteDashboards(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	_, err := c.CloudWatchMetrics.DeleteDashboards(&cloudwatch.DeleteDashboardsInput{
+		DashboardNames: aws.StringSlice(names),
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// DashboardURL returns the URL of a CloudWatch dashboard in the AWS console
+func DashboardURL(region string, name string) string {
+	return fmt.Sprintf("https://console.aws.amazon.com/cloudwatch/home?region=%s#dashboards:name=%s", region, url.PathEscape(name))
+}
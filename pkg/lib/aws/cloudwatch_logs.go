@@ -0,0 +1,141 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+const (
+	_logsQueryPollPeriod = 500 * time.Millisecond
+	_logsQueryTimeout    = time.Minute
+)
+
+// LogGroupRetentionDays are the retention periods which CloudWatch supports for log groups
+var LogGroupRetentionDays = []int64{1, 3, 5, 7, 14, 30, 60, 90, 120, 150, 180, 365, 400, 545, 731, 1827, 3653}
+
+// CreateLogGroup creates the log group if it doesn't already exist
+func (c *Client) CreateLogGroup(name string) error {
+	_, err := c.CloudWatchLogsClient.CreateLogGroup(&cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(name),
+	})
+	if err != nil && !CheckErrCode(err, cloudwatchlogs.ErrCodeResourceAlreadyExistsException) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// ListLogGroups returns the retention (in days) of each of the log groups whose names start with the prefix (nil if its logs never expire)
+func (c *Client) ListLogGroups(prefix string) (map[string]*int64, error) {
+	logGroups := make(map[string]*int64)
+	err := c.CloudWatchLogsClient.DescribeLogGroupsPages(&cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(prefix),
+	}, func(output *cloudwatchlogs.DescribeLogGroupsOutput, lastPage bool) bool {
+		for _, logGroup := range output.LogGroups {
+			if logGroup.LogGroupName != nil {
+				logGroups[*logGroup.LogGroupName] = logGroup.RetentionInDays
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return logGroups, nil
+}
+
+// SetLogGroupRetention sets the number of days for which the log group's logs are kept (if retentionDays is nil, they never expire)
+func (c *Client) SetLogGroupRetention(name string, retentionDays *int64) error {
+	var err error
+	if retentionDays == nil {
+		_, err = c.CloudWatchLogsClient.DeleteRetentionPolicy(&cloudwatchlogs.DeleteRetentionPolicyInput{
+			LogGroupName: aws.String(name),
+		})
+	} else {
+		_, err = c.CloudWatchLogsClient.PutRetentionPolicy(&cloudwatchlogs.PutRetentionPolicyInput{
+			LogGroupName:    aws.String(name),
+			RetentionInDays: retentionDays,
+		})
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// DeleteLogGroup deletes the log group (and its logs), if it exists
+func (c *Client) DeleteLogGroup(name string) error {
+	_, err := c.CloudWatchLogsClient.DeleteLogGroup(&cloudwatchlogs.DeleteLogGroupInput{
+		LogGroupName: aws.String(name),
+	})
+	if err != nil && !CheckErrCode(err, cloudwatchlogs.ErrCodeResourceNotFoundException) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// QueryLogs runs the CloudWatch Logs Insights query against the log group's events between startTime and endTime,
+// and returns the fields of each of its results (the query is stopped if it doesn't complete within a minute)
+func (c *Client) QueryLogs(logGroupName string, query string, startTime time.Time, endTime time.Time) ([]map[string]string, error) {
+	startQueryOutput, err := c.CloudWatchLogsClient.StartQuery(&cloudwatchlogs.StartQueryInput{
+		LogGroupName: aws.String(logGroupName),
+		QueryString:  aws.String(query),
+		StartTime:    aws.Int64(startTime.Unix()),
+		EndTime:      aws.Int64(endTime.Unix()),
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	deadline := time.Now().Add(_logsQueryTimeout)
+	for {
+		output, err := c.CloudWatchLogsClient.GetQueryResults(&cloudwatchlogs.GetQueryResultsInput{
+			QueryId: startQueryOutput.QueryId,
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		switch status := aws.StringValue(output.Status); status {
+		case cloudwatchlogs.QueryStatusComplete:
+			results := make([]map[string]string, len(output.Results))
+			for i, fields := range output.Results {
+				results[i] = make(map[string]string, len(fields))
+				for _, field := range fields {
+					if field.Field != nil && field.Value != nil {
+						results[i][*field.Field] = *field.Value
+					}
+				}
+			}
+			return results, nil
+		case cloudwatchlogs.QueryStatusScheduled, cloudwatchlogs.QueryStatusRunning:
+			if time.Now().After(deadline) {
+				c.CloudWatchLogsClient.StopQuery(&cloudwatchlogs.StopQueryInput{QueryId: startQueryOutput.QueryId})
+				return nil, ErrorLogsQueryFailed(logGroupName, "timeout")
+			}
+		default:
+			return nil, ErrorLogsQueryFailed(logGroupName, status)
+		}
+
+		time.Sleep(_logsQueryPollPeriod)
+	}
+}
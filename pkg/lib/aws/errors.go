@@ -18,6 +18,7 @@ package aws
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
 
@@ -36,6 +37,13 @@ const (
 	ErrInstanceTypeLimitIsZero
 	ErrNoValidSpotPrices
 	ErrReadCredentials
+	ErrSecretInaccessible
+	ErrSSMParameterInaccessible
+	ErrSNSTopicInaccessible
+	ErrSNSPublishNotAllowed
+	ErrFirehoseStreamInaccessible
+	ErrFirehosePutNotAllowed
+	ErrLogsQueryFailed
 )
 
 var errorKinds = []string{
@@ -47,9 +55,16 @@ var errorKinds = []string{
 	"err_instance_type_limit_is_zero",
 	"err_no_valid_spot_prices",
 	"err_read_credentials",
+	"err_secret_inaccessible",
+	"err_ssm_parameter_inaccessible",
+	"err_sns_topic_inaccessible",
+	"err_sns_publish_not_allowed",
+	"err_firehose_stream_inaccessible",
+	"err_firehose_put_not_allowed",
+	"err_logs_query_failed",
 }
 
-var _ = [1]int{}[int(ErrReadCredentials)-(len(errorKinds)-1)] // Ensure list length matches
+var _ = [1]int{}[int(ErrLogsQueryFailed)-(len(errorKinds)-1)] // Ensure list length matches
 
 func (t ErrorKind) String() string {
 	return errorKinds[t]
@@ -169,3 +184,52 @@ func ErrorReadCredentials() error {
 		message: "unable to read AWS credentials from credentials file",
 	})
 }
+
+func ErrorSecretInaccessible(secretID string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrSecretInaccessible,
+		message: fmt.Sprintf("secret \"%s\" not found in AWS Secrets Manager or insufficient permissions", secretID),
+	})
+}
+
+func ErrorSSMParameterInaccessible(name string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrSSMParameterInaccessible,
+		message: fmt.Sprintf("parameter \"%s\" not found in AWS Systems Manager Parameter Store or insufficient permissions", name),
+	})
+}
+
+func ErrorSNSTopicInaccessible(topicARN string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrSNSTopicInaccessible,
+		message: fmt.Sprintf("SNS topic \"%s\" not found or insufficient permissions", topicARN),
+	})
+}
+
+func ErrorSNSPublishNotAllowed(topicARN string, principalARN string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrSNSPublishNotAllowed,
+		message: fmt.Sprintf("%s is not allowed to publish to SNS topic \"%s\" (sns:Publish permission is required)", principalARN, topicARN),
+	})
+}
+
+func ErrorFirehoseStreamInaccessible(streamARN string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrFirehoseStreamInaccessible,
+		message: fmt.Sprintf("Kinesis Data Firehose delivery stream \"%s\" not found or insufficient permissions", streamARN),
+	})
+}
+
+func ErrorFirehosePutNotAllowed(streamARN string, principalARN string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrFirehosePutNotAllowed,
+		message: fmt.Sprintf("%s is not allowed to write to Kinesis Data Firehose delivery stream \"%s\" (firehose:PutRecordBatch permission is required)", principalARN, streamARN),
+	})
+}
+
+func ErrorLogsQueryFailed(logGroupName string, status string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrLogsQueryFailed,
+		message: fmt.Sprintf("the CloudWatch Logs Insights query of log group \"%s\" did not complete (status: %s)", logGroupName, strings.ToLower(status)),
+	})
+}
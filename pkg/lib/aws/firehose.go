@@ -0,0 +1,75 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/firehose"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var _firehoseStreamARNRegex = regexp.MustCompile(`^arn:aws[a-z-]*:firehose:[a-z0-9-]+:[0-9]{12}:deliverystream/[A-Za-z0-9_.-]{1,64}$`)
+
+// IsFirehoseStreamARN returns true if the string is the ARN of a Kinesis Data Firehose delivery stream
+func IsFirehoseStreamARN(str string) bool {
+	return _firehoseStreamARNRegex.MatchString(str)
+}
+
+// FirehoseStreamRegion returns the region of a delivery stream ARN
+func FirehoseStreamRegion(streamARN string) string {
+	parts := strings.Split(streamARN, ":")
+	if len(parts) < 4 {
+		return ""
+	}
+	return parts[3]
+}
+
+// FirehoseStreamName returns the name of a delivery stream ARN
+func FirehoseStreamName(streamARN string) string {
+	return streamARN[strings.LastIndex(streamARN, "/")+1:]
+}
+
+// VerifyFirehoseStreamWritable checks that the delivery stream exists and that the default credentials' principal is allowed to write to it
+func VerifyFirehoseStreamWritable(streamARN string) error {
+	sess, err := session.NewSession(withRetries(&aws.Config{
+		Region:     aws.String(FirehoseStreamRegion(streamARN)),
+		DisableSSL: aws.Bool(false),
+	}))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = firehose.New(sess).DescribeDeliveryStream(&firehose.DescribeDeliveryStreamInput{
+		DeliveryStreamName: aws.String(FirehoseStreamName(streamARN)),
+	})
+	if err != nil {
+		return errors.Wrap(err, ErrorFirehoseStreamInaccessible(streamARN).Error())
+	}
+
+	principalARN, allowed, err := simulatePrincipalAction(sess, "firehose:PutRecordBatch", streamARN, nil)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrorFirehosePutNotAllowed(streamARN, principalARN)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirehoseStreamARN(t *testing.T) {
+	streamARN := "arn:aws:firehose:us-west-2:123456789012:deliverystream/api-feedback"
+	require.True(t, IsFirehoseStreamARN(streamARN))
+	require.Equal(t, "us-west-2", FirehoseStreamRegion(streamARN))
+	require.Equal(t, "api-feedback", FirehoseStreamName(streamARN))
+
+	for _, str := range []string{
+		"s3://my-bucket/feedback",
+		"api-feedback",
+		"arn:aws:sns:us-west-2:123456789012:api-feedback",
+		"arn:aws:firehose:us-west-2:123456789012:deliverystream/",
+		"arn:aws:firehose:us-west-2:1234:deliverystream/api-feedback",
+	} {
+		require.False(t, IsFirehoseStreamARN(str), str)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+
+	"github.com/cortexlabs/cortex/pkg/lib/retry"
+)
+
+// Error codes which indicate that a request was throttled, in addition to the ones recognized by the SDK
+var _throttleErrCodes = []string{"SlowDown", "RequestLimitExceeded", "TooManyRequestsException", "ThrottledException"}
+
+// retryer applies retry.Default to AWS requests (it satisfies request.Retryer)
+type retryer struct {
+	config retry.Config
+}
+
+func (r retryer) MaxRetries() int {
+	return r.config.MaxAttempts - 1
+}
+
+func (r retryer) RetryRules(req *request.Request) time.Duration {
+	return r.config.Delay(req.RetryCount + 1)
+}
+
+func (r retryer) ShouldRetry(req *request.Request) bool {
+	if req.Retryable != nil {
+		return *req.Retryable
+	}
+	return IsRetryableErr(req.Error)
+}
+
+// IsRetryableErr returns true if the error is transient (e.g. throttling, a 5xx response, or a connection error)
+func IsRetryableErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, code := range _throttleErrCodes {
+		if CheckErrCode(err, code) {
+			return true
+		}
+	}
+	return request.IsErrorThrottle(err) || request.IsErrorRetryable(err)
+}
+
+// isRetryableHTTPErr is used for requests which aren't made with the SDK (all errors are retryable, since non-retryable responses aren't returned as errors)
+func isRetryableHTTPErr(err error) bool {
+	return err != nil
+}
+
+func isRetryableStatusCode(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// withRetries configures the AWS config to retry failed requests with retry.Default
+func withRetries(config *aws.Config) *aws.Config {
+	return request.WithRetryer(config, retryer{config: retry.Default})
+}
@@ -19,14 +19,14 @@ package aws
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
@@ -121,6 +121,27 @@ func (c *Client) IsS3PathFile(s3Paths ...string) (bool, error) {
 	return c.IsS3File(keys...)
 }
 
+// GetS3PathETag returns the ETag of the file at the S3 path ("" if the file doesn't exist)
+func (c *Client) GetS3PathETag(s3Path string) (string, error) {
+	keys, err := c.ExractS3PathPrefixes(s3Path)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := c.S3.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(keys[0]),
+	})
+	if IsNotFoundErr(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, s3Path)
+	}
+
+	return *out.ETag, nil
+}
+
 func (c *Client) IsS3PathPrefix(s3Paths ...string) (bool, error) {
 	prefixes, err := c.ExractS3PathPrefixes(s3Paths...)
 	if err != nil {
@@ -137,6 +158,48 @@ func (c *Client) IsS3PathDir(s3Paths ...string) (bool, error) {
 	return c.IsS3Prefix(dirPaths...)
 }
 
+// GetS3PathSize returns the size in bytes of the file at the S3 path
+func (c *Client) GetS3PathSize(s3Path string) (int64, error) {
+	keys, err := c.ExractS3PathPrefixes(s3Path)
+	if err != nil {
+		return 0, err
+	}
+
+	out, err := c.S3.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(keys[0]),
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, s3Path)
+	}
+
+	return aws.Int64Value(out.ContentLength), nil
+}
+
+// ReadBytesRangeFromS3Path reads length bytes of the file at the S3 path, starting at offset (fewer bytes are returned if the file ends first)
+func (c *Client) ReadBytesRangeFromS3Path(s3Path string, offset int64, length int64) ([]byte, error) {
+	keys, err := c.ExractS3PathPrefixes(s3Path)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.S3.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(keys[0]),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, s3Path)
+	}
+	defer response.Body.Close()
+
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(response.Body); err != nil {
+		return nil, errors.Wrap(err, s3Path)
+	}
+	return buf.Bytes(), nil
+}
+
 func (c *Client) UploadBytesToS3(data []byte, key string) error {
 	_, err := c.S3.PutObject(&s3.PutObjectInput{
 		Body:                 bytes.NewReader(data),
@@ -149,6 +212,19 @@ func (c *Client) UploadBytesToS3(data []byte, key string) error {
 	return errors.Wrap(err, key)
 }
 
+// PresignS3GetURL returns a URL which downloads the object from the bucket (without credentials) until it expires
+func (c *Client) PresignS3GetURL(key string, expiration time.Duration) (string, error) {
+	request, _ := c.S3.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(key),
+	})
+	url, err := request.Presign(expiration)
+	if err != nil {
+		return "", errors.Wrap(err, key)
+	}
+	return url, nil
+}
+
 func (c *Client) UploadBytesesToS3(data []byte, keys ...string) error {
 	fns := make([]func() error, len(keys))
 	for i, key := range keys {
@@ -239,6 +315,43 @@ func (c *Client) ReadBytesFromS3(key string) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// ReadBytesFromS3Path reads an object from any bucket (in the client's region) which the client can access
+func (c *Client) ReadBytesFromS3Path(s3Path string) ([]byte, error) {
+	bucket, key, err := SplitS3Path(s3Path)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.S3.GetObject(&s3.GetObjectInput{
+		Key:    aws.String(key),
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, s3Path)
+	}
+
+	buf := new(bytes.Buffer)
+	buf.ReadFrom(response.Body)
+	return buf.Bytes(), nil
+}
+
+// OpenS3Path opens an object in any bucket (in the client's region) which the client can access, so that it can be streamed (the caller must close it)
+func (c *Client) OpenS3Path(s3Path string) (io.ReadCloser, error) {
+	bucket, key, err := SplitS3Path(s3Path)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.S3.GetObject(&s3.GetObjectInput{
+		Key:    aws.String(key),
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, s3Path)
+	}
+	return response.Body, nil
+}
+
 func (c *Client) ListPrefix(prefix string, maxResults int64) ([]*s3.Object, error) {
 	listObjectsInput := &s3.ListObjectsV2Input{
 		Bucket:  aws.String(c.Bucket),
@@ -254,6 +367,68 @@ func (c *Client) ListPrefix(prefix string, maxResults int64) ([]*s3.Object, erro
 	return output.Contents, nil
 }
 
+// ListPrefixAfter lists the objects under the prefix whose keys are lexicographically after startAfter
+func (c *Client) ListPrefixAfter(prefix string, startAfter string, maxResults int64) ([]*s3.Object, error) {
+	listObjectsInput := &s3.ListObjectsV2Input{
+		Bucket:     aws.String(c.Bucket),
+		Prefix:     aws.String(prefix),
+		StartAfter: aws.String(startAfter),
+		MaxKeys:    aws.Int64(maxResults),
+	}
+
+	output, err := c.S3.ListObjectsV2(listObjectsInput)
+	if err != nil {
+		return nil, errors.Wrap(err, prefix)
+	}
+
+	return output.Contents, nil
+}
+
+// ListS3PathDir returns the keys of all of the objects under the S3 directory, relative to the directory
+func (c *Client) ListS3PathDir(s3Path string) ([]string, error) {
+	_, prefix, err := SplitS3Path(s3Path)
+	if err != nil {
+		return nil, err
+	}
+	prefix = s.EnsureSuffix(prefix, "/")
+
+	var keys []string
+	err = c.S3.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(c.Bucket),
+		Prefix: aws.String(prefix),
+	}, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range output.Contents {
+			keys = append(keys, strings.TrimPrefix(*object.Key, prefix))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, s3Path)
+	}
+
+	return keys, nil
+}
+
+// DeleteS3Keys deletes up to 1000 objects
+func (c *Client) DeleteS3Keys(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	deleteObjects := make([]*s3.ObjectIdentifier, len(keys))
+	for i, key := range keys {
+		deleteObjects[i] = &s3.ObjectIdentifier{Key: aws.String(key)}
+	}
+	_, err := c.S3.DeleteObjects(&s3.DeleteObjectsInput{
+		Bucket: aws.String(c.Bucket),
+		Delete: &s3.Delete{
+			Objects: deleteObjects,
+			Quiet:   aws.Bool(true),
+		},
+	})
+	return errors.WithStack(err)
+}
+
 func (c *Client) DeleteFromS3ByPrefix(prefix string, continueIfFailure bool) error {
 	listObjectsInput := &s3.ListObjectsV2Input{
 		Bucket:  aws.String(c.Bucket),
@@ -358,12 +533,3 @@ func (c *Client) ExractS3PathPrefixes(s3Paths ...string) ([]string, error) {
 	}
 	return prefixes, nil
 }
-
-func GetBucketRegion(bucket string) (string, error) {
-	sess := session.Must(session.NewSession())
-	region, err := s3manager.GetBucketRegion(aws.BackgroundContext(), sess, bucket, endpoints.UsWest2RegionID)
-	if err != nil {
-		return "", ErrorBucketInaccessible(bucket)
-	}
-	return region, nil
-}
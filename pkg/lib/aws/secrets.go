@@ -0,0 +1,55 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/ssm"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// GetSecretString returns the current value of a Secrets Manager secret (by name or ARN)
+func (c *Client) GetSecretString(secretID string) (string, error) {
+	response, err := c.secretsManager.GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, ErrorSecretInaccessible(secretID).Error())
+	}
+	if response.SecretString == nil {
+		// binary secrets are not supported
+		return "", ErrorSecretInaccessible(secretID)
+	}
+	return *response.SecretString, nil
+}
+
+// GetSSMParameter returns the (decrypted) value of a Systems Manager parameter (by name or ARN)
+func (c *Client) GetSSMParameter(name string) (string, error) {
+	response, err := c.ssm.GetParameter(&ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, ErrorSSMParameterInaccessible(name).Error())
+	}
+	if response.Parameter == nil || response.Parameter.Value == nil {
+		return "", ErrorSSMParameterInaccessible(name)
+	}
+	return *response.Parameter.Value, nil
+}
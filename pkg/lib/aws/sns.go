@@ -0,0 +1,162 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var _snsTopicARNRegex = regexp.MustCompile(`^arn:aws[a-z-]*:sns:[a-z0-9-]+:[0-9]{12}:[A-Za-z0-9_-]{1,256}(\.fifo)?$`)
+
+// IsSNSTopicARN returns true if the string is the ARN of an SNS topic
+func IsSNSTopicARN(str string) bool {
+	return _snsTopicARNRegex.MatchString(str)
+}
+
+// SNSTopicRegion returns the region of an SNS topic ARN
+func SNSTopicRegion(topicARN string) string {
+	parts := strings.Split(topicARN, ":")
+	if len(parts) < 4 {
+		return ""
+	}
+	return parts[3]
+}
+
+// VerifySNSTopicPublishable checks that the topic exists and that the credentials' principal is allowed to publish to it
+func VerifySNSTopicPublishable(accessKeyID string, secretAccessKey string, topicARN string) error {
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(SNSTopicRegion(topicARN)),
+		DisableSSL:  aws.Bool(false),
+		Credentials: credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return verifySNSTopicPublishable(sess, topicARN)
+}
+
+// VerifySNSTopicPublishableByDefaultCredentials checks that the topic exists and that the default credentials' principal (e.g. the operator's) is allowed to publish to it
+func VerifySNSTopicPublishableByDefaultCredentials(topicARN string) error {
+	sess, err := session.NewSession(withRetries(&aws.Config{
+		Region:     aws.String(SNSTopicRegion(topicARN)),
+		DisableSSL: aws.Bool(false),
+	}))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return verifySNSTopicPublishable(sess, topicARN)
+}
+
+func verifySNSTopicPublishable(sess *session.Session, topicARN string) error {
+	attributes, err := sns.New(sess).GetTopicAttributes(&sns.GetTopicAttributesInput{
+		TopicArn: aws.String(topicARN),
+	})
+	if err != nil {
+		return errors.Wrap(err, ErrorSNSTopicInaccessible(topicARN).Error())
+	}
+
+	principalARN, allowed, err := simulatePrincipalAction(sess, "sns:Publish", topicARN, attributes.Attributes["Policy"])
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrorSNSPublishNotAllowed(topicARN, principalARN)
+	}
+	return nil
+}
+
+// simulatePrincipalAction checks whether the session's principal is allowed to perform the action on the resource (whose resource policy may be nil);
+// the action is assumed to be allowed if it can't be simulated (e.g. for the account's root user, or principals which aren't allowed to simulate their policies)
+func simulatePrincipalAction(sess *session.Session, action string, resourceARN string, resourcePolicy *string) (string, bool, error) {
+	identity, err := sts.New(sess).GetCallerIdentity(nil)
+	if err != nil {
+		if awsErr, ok := err.(awserr.RequestFailure); ok && awsErr.StatusCode() == 403 {
+			return "", true, nil
+		}
+		return "", false, errors.WithStack(err)
+	}
+	principalARN := NormalizePrincipalARN(*identity.Arn)
+	if !IsPrincipalARN(principalARN) {
+		return principalARN, true, nil
+	}
+
+	simulation, err := iam.New(sess).SimulatePrincipalPolicy(&iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principalARN),
+		ActionNames:     aws.StringSlice([]string{action}),
+		ResourceArns:    aws.StringSlice([]string{resourceARN}),
+		ResourcePolicy:  resourcePolicy,
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "AccessDenied" {
+			return principalARN, true, nil
+		}
+		return "", false, errors.WithStack(err)
+	}
+
+	for _, result := range simulation.EvaluationResults {
+		if result.EvalDecision == nil || *result.EvalDecision != iam.PolicyEvaluationDecisionTypeAllowed {
+			return principalARN, false, nil
+		}
+	}
+	return principalARN, true, nil
+}
+
+// PublishSNS publishes a message to an SNS topic (in the topic's region), with string attributes which subscriptions can filter on
+func (c *Client) PublishSNS(topicARN string, subject string, message string, attributes map[string]string) error {
+	messageAttributes := make(map[string]*sns.MessageAttributeValue, len(attributes))
+	for key, value := range attributes {
+		messageAttributes[key] = &sns.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(value),
+		}
+	}
+
+	snsClient := c.sns
+	if region := SNSTopicRegion(topicARN); region != c.Region {
+		sess, err := session.NewSession(withRetries(&aws.Config{
+			Region:     aws.String(region),
+			DisableSSL: aws.Bool(false),
+		}))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		snsClient = sns.New(sess)
+	}
+
+	_, err := snsClient.Publish(&sns.PublishInput{
+		TopicArn:          aws.String(topicARN),
+		Subject:           aws.String(subject),
+		Message:           aws.String(message),
+		MessageAttributes: messageAttributes,
+	})
+	if err != nil {
+		return errors.Wrap(err, topicARN)
+	}
+	return nil
+}
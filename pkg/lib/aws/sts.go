@@ -17,23 +17,184 @@ limitations under the License.
 package aws
 
 import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/retry"
 )
 
+// CallerIdentity is the IAM principal which made a request
+type CallerIdentity struct {
+	Account string `xml:"GetCallerIdentityResult>Account"`
+	ARN     string `xml:"GetCallerIdentityResult>Arn"`
+	UserID  string `xml:"GetCallerIdentityResult>UserId"`
+}
+
+// OperatorHeader is signed into presigned GetCallerIdentity requests, and identifies the operator which the request is intended for (the cluster name),
+// so that an operator can't use a request which it receives to authenticate as the caller to another operator
+const OperatorHeader = "X-Cortex-Operator"
+
+// presigned GetCallerIdentity requests are only forwarded to STS endpoints
+var _stsHostRegex = regexp.MustCompile(`^sts(\.[a-z0-9-]+)?\.amazonaws\.com$`)
+
+var _stsHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// PresignCallerIdentityRequest returns a signed (SigV4) STS GetCallerIdentity URL, which proves the caller's identity to whoever requests it (without exposing the credentials)
+// The request can only be verified by the operator of the cluster named clusterName
+func PresignCallerIdentityRequest(accessKeyID string, secretAccessKey string, clusterName string, expiration time.Duration) (string, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"), // the global STS endpoint
+		Credentials: credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	request, _ := sts.New(sess).GetCallerIdentityRequest(&sts.GetCallerIdentityInput{})
+	request.HTTPRequest.Header.Set(OperatorHeader, clusterName)
+	presignedURL, err := request.Presign(expiration)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return presignedURL, nil
+}
+
+// VerifyCallerIdentityRequest sends a presigned GetCallerIdentity request to STS, with the OperatorHeader set to clusterName
+// (so that the signature is only valid if the request was presigned for this cluster's operator)
+// Returns the caller's identity, whether the signature was valid, any other error that occurred
+func VerifyCallerIdentityRequest(presignedURL string, clusterName string) (*CallerIdentity, bool, error) {
+	parsedURL, err := url.Parse(presignedURL)
+	if err != nil || parsedURL.Scheme != "https" || !_stsHostRegex.MatchString(parsedURL.Host) || (parsedURL.Path != "/" && parsedURL.Path != "") {
+		return nil, false, nil
+	}
+	query := parsedURL.Query()
+	if query.Get("Action") != "GetCallerIdentity" || query.Get("X-Amz-Algorithm") != "AWS4-HMAC-SHA256" {
+		return nil, false, nil
+	}
+	if !isHeaderSigned(query.Get("X-Amz-SignedHeaders"), OperatorHeader) {
+		return nil, false, nil
+	}
+
+	var response *http.Response
+	err = retry.Do(retry.Default, isRetryableHTTPErr, func() error {
+		request, err := http.NewRequest(http.MethodGet, parsedURL.String(), nil)
+		if err != nil {
+			return err
+		}
+		request.Header.Set(OperatorHeader, clusterName)
+
+		response, err = _stsHTTPClient.Do(request)
+		if err == nil && isRetryableStatusCode(response.StatusCode) {
+			response.Body.Close()
+			return errors.New("sts returned status code " + response.Status)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusForbidden {
+		return nil, false, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, false, errors.New("sts returned status code " + response.Status)
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+
+	var identity CallerIdentity
+	if err := xml.Unmarshal(body, &identity); err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+	if identity.ARN == "" || identity.Account == "" {
+		return nil, false, nil
+	}
+
+	return &identity, true, nil
+}
+
+// signedHeaders is the X-Amz-SignedHeaders query param of a presigned request (lowercase header names separated by semicolons)
+func isHeaderSigned(signedHeaders string, header string) bool {
+	for _, signedHeader := range strings.Split(signedHeaders, ";") {
+		if signedHeader == strings.ToLower(header) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizePrincipalARN converts assumed-role session ARNs (arn:aws:sts::<account>:assumed-role/<role>/<session>) to their role's ARN,
+// and removes paths from role ARNs (since assumed-role ARNs don't include them), so that principals can be compared
+func NormalizePrincipalARN(principalARN string) string {
+	parts := strings.SplitN(principalARN, ":", 6)
+	if len(parts) != 6 {
+		return principalARN
+	}
+
+	resource := strings.Split(parts[5], "/")
+	switch {
+	case parts[2] == "sts" && resource[0] == "assumed-role" && len(resource) >= 2:
+		return strings.Join([]string{parts[0], parts[1], "iam", "", parts[4], "role/" + resource[1]}, ":")
+	case parts[2] == "iam" && resource[0] == "role" && len(resource) > 2:
+		return strings.Join([]string{parts[0], parts[1], "iam", "", parts[4], "role/" + resource[len(resource)-1]}, ":")
+	}
+
+	return principalARN
+}
+
+var _principalARNRegex = regexp.MustCompile(`^arn:aws[a-z-]*:iam::[0-9]{12}:(user|role)/.+$`)
+
+// IsPrincipalARN returns true if the string is the ARN of an IAM user or role
+func IsPrincipalARN(str string) bool {
+	return _principalARNRegex.MatchString(str)
+}
+
+// PrincipalMatches returns true if the caller is one of the principals ("*" matches any caller)
+func PrincipalMatches(principals []string, callerARN string) bool {
+	normalizedCallerARN := NormalizePrincipalARN(callerARN)
+	for _, principal := range principals {
+		if principal == "*" || NormalizePrincipalARN(principal) == normalizedCallerARN {
+			return true
+		}
+	}
+	return false
+}
+
 // Returns account ID, whether the credentials were valid, any other error that occurred
 func AccountID(accessKeyID string, secretAccessKey string, region string) (string, bool, error) {
-	sess, err := session.NewSession(&aws.Config{
+	identity, validCreds, err := GetCallerIdentity(accessKeyID, secretAccessKey, region)
+	if identity == nil {
+		return "", validCreds, err
+	}
+	return identity.Account, validCreds, err
+}
+
+// Returns the caller's identity, whether the credentials were valid, any other error that occurred
+func GetCallerIdentity(accessKeyID string, secretAccessKey string, region string) (*CallerIdentity, bool, error) {
+	sess, err := session.NewSession(withRetries(&aws.Config{
 		Region:      aws.String(region),
 		DisableSSL:  aws.Bool(false),
 		Credentials: credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
-	})
+	}))
 	if err != nil {
-		return "", false, errors.WithStack(err)
+		return nil, false, errors.WithStack(err)
 	}
 
 	stsClient := sts.New(sess)
@@ -41,12 +202,16 @@ func AccountID(accessKeyID string, secretAccessKey string, region string) (strin
 	response, err := stsClient.GetCallerIdentity(nil)
 	if awsErr, ok := err.(awserr.RequestFailure); ok {
 		if awsErr.StatusCode() == 403 {
-			return "", false, nil
+			return nil, false, nil
 		}
 	}
 	if err != nil {
-		return "", false, errors.WithStack(err)
+		return nil, false, errors.WithStack(err)
 	}
 
-	return *response.Account, true, nil
+	return &CallerIdentity{
+		Account: *response.Account,
+		ARN:     *response.Arn,
+		UserID:  *response.UserId,
+	}, true, nil
 }
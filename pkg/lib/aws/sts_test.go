@@ -0,0 +1,111 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// stubSTS responds to GetCallerIdentity requests (without checking their signatures), and returns the OperatorHeader values which it received
+func stubSTS(t *testing.T) *[]string {
+	operatorHeaders := []string{}
+
+	original := _stsHTTPClient
+	_stsHTTPClient = &http.Client{Transport: roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		operatorHeaders = append(operatorHeaders, request.Header.Get(OperatorHeader))
+		body := `<GetCallerIdentityResponse><GetCallerIdentityResult><Arn>arn:aws:iam::123456789012:user/alice</Arn><UserId>AIDAEXAMPLE</UserId><Account>123456789012</Account></GetCallerIdentityResult></GetCallerIdentityResponse>`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     "200 OK",
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+			Header:     http.Header{},
+		}, nil
+	})}
+
+	t.Cleanup(func() {
+		_stsHTTPClient = original
+	})
+
+	return &operatorHeaders
+}
+
+func TestPresignCallerIdentityRequestSignsOperatorHeader(t *testing.T) {
+	presignedURL, err := PresignCallerIdentityRequest("AKIAEXAMPLE", "secret", "us-west", 5*time.Minute)
+	require.NoError(t, err)
+
+	parsedURL, err := url.Parse(presignedURL)
+	require.NoError(t, err)
+	require.True(t, isHeaderSigned(parsedURL.Query().Get("X-Amz-SignedHeaders"), OperatorHeader))
+
+	// the cluster name is only part of the signature, it isn't sent in the URL
+	require.NotContains(t, presignedURL, "us-west")
+}
+
+func TestVerifyCallerIdentityRequestSendsOperatorHeader(t *testing.T) {
+	operatorHeaders := stubSTS(t)
+
+	presignedURL, err := PresignCallerIdentityRequest("AKIAEXAMPLE", "secret", "us-west", 5*time.Minute)
+	require.NoError(t, err)
+
+	identity, validCreds, err := VerifyCallerIdentityRequest(presignedURL, "eu-west")
+	require.NoError(t, err)
+	require.True(t, validCreds)
+	require.Equal(t, "arn:aws:iam::123456789012:user/alice", identity.ARN)
+
+	// STS rejects the signature unless the verifying operator's cluster name matches the signed one
+	require.Equal(t, []string{"eu-west"}, *operatorHeaders)
+}
+
+func TestVerifyCallerIdentityRequestRequiresOperatorHeader(t *testing.T) {
+	operatorHeaders := stubSTS(t)
+
+	presignedURL, err := PresignCallerIdentityRequest("AKIAEXAMPLE", "secret", "us-west", 5*time.Minute)
+	require.NoError(t, err)
+
+	parsedURL, err := url.Parse(presignedURL)
+	require.NoError(t, err)
+	query := parsedURL.Query()
+	query.Set("X-Amz-SignedHeaders", "host")
+	parsedURL.RawQuery = query.Encode()
+
+	identity, validCreds, err := VerifyCallerIdentityRequest(parsedURL.String(), "us-west")
+	require.NoError(t, err)
+	require.False(t, validCreds)
+	require.Nil(t, identity)
+	require.Empty(t, *operatorHeaders)
+}
+
+func TestIsHeaderSigned(t *testing.T) {
+	require.True(t, isHeaderSigned("host;x-cortex-operator", OperatorHeader))
+	require.True(t, isHeaderSigned("x-cortex-operator", OperatorHeader))
+	require.False(t, isHeaderSigned("host", OperatorHeader))
+	require.False(t, isHeaderSigned("host;x-cortex-operator-other", OperatorHeader))
+	require.False(t, isHeaderSigned("", OperatorHeader))
+}
@@ -17,59 +17,128 @@ limitations under the License.
 package clusterconfig
 
 import (
+	"net"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/aws/amazon-vpc-cni-k8s/pkg/awsutils"
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/cron"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	"github.com/cortexlabs/cortex/pkg/lib/prompt"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	"github.com/cortexlabs/cortex/pkg/lib/webhooks"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
 )
 
 var (
 	_spotInstanceDistributionLength = 2
 	_maxInstancePools               = 20
+	_maxFreezeWindowDuration        = 31 * 24 * time.Hour
 )
 
+// Ingress backends, which route requests to APIs
+const (
+	IstioIngressBackend      = "istio"       // Istio virtual services on cortex's gateways
+	KubernetesIngressBackend = "ingress"     // Kubernetes ingresses, served by an ingress controller which the user installs
+	GatewayAPIIngressBackend = "gateway_api" // Gateway API HTTP routes, attached to a gateway which the user creates
+)
+
+var IngressBackends = []string{IstioIngressBackend, KubernetesIngressBackend, GatewayAPIIngressBackend}
+
+// IngressClasses are the ingress classes which are supported by the ingress backend (the ingress controller must be able to rewrite paths)
+var IngressClasses = []string{"nginx"}
+
+// Priorities are the priorities which APIs may be deployed with, from lowest to highest
+var Priorities = []string{"low", "medium", "high"}
+
+// DeletableFailedPodReasons are the failure reasons (in addition to Evicted) for which the operator can be configured to delete failed pods
+var DeletableFailedPodReasons = []string{"NodeLost", "UnexpectedAdmissionError"}
+
 type Config struct {
-	InstanceType           *string     `json:"instance_type" yaml:"instance_type"`
-	MinInstances           *int64      `json:"min_instances" yaml:"min_instances"`
-	MaxInstances           *int64      `json:"max_instances" yaml:"max_instances"`
-	InstanceVolumeSize     int64       `json:"instance_volume_size" yaml:"instance_volume_size"`
-	Spot                   *bool       `json:"spot" yaml:"spot"`
-	SpotConfig             *SpotConfig `json:"spot_config" yaml:"spot_config"`
-	ClusterName            string      `json:"cluster_name" yaml:"cluster_name"`
-	Region                 *string     `json:"region" yaml:"region"`
-	AvailabilityZones      []string    `json:"availability_zones" yaml:"availability_zones"`
-	Bucket                 *string     `json:"bucket" yaml:"bucket"`
-	LogGroup               string      `json:"log_group" yaml:"log_group"`
-	Telemetry              bool        `json:"telemetry" yaml:"telemetry"`
-	ImagePythonServe       string      `json:"image_python_serve" yaml:"image_python_serve"`
-	ImagePythonServeGPU    string      `json:"image_python_serve_gpu" yaml:"image_python_serve_gpu"`
-	ImageTFServe           string      `json:"image_tf_serve" yaml:"image_tf_serve"`
-	ImageTFServeGPU        string      `json:"image_tf_serve_gpu" yaml:"image_tf_serve_gpu"`
-	ImageTFAPI             string      `json:"image_tf_api" yaml:"image_tf_api"`
-	ImageONNXServe         string      `json:"image_onnx_serve" yaml:"image_onnx_serve"`
-	ImageONNXServeGPU      string      `json:"image_onnx_serve_gpu" yaml:"image_onnx_serve_gpu"`
-	ImageOperator          string      `json:"image_operator" yaml:"image_operator"`
-	ImageManager           string      `json:"image_manager" yaml:"image_manager"`
-	ImageDownloader        string      `json:"image_downloader" yaml:"image_downloader"`
-	ImageClusterAutoscaler string      `json:"image_cluster_autoscaler" yaml:"image_cluster_autoscaler"`
-	ImageMetricsServer     string      `json:"image_metrics_server" yaml:"image_metrics_server"`
-	ImageNvidia            string      `json:"image_nvidia" yaml:"image_nvidia"`
-	ImageFluentd           string      `json:"image_fluentd" yaml:"image_fluentd"`
-	ImageStatsd            string      `json:"image_statsd" yaml:"image_statsd"`
-	ImageIstioProxy        string      `json:"image_istio_proxy" yaml:"image_istio_proxy"`
-	ImageIstioPilot        string      `json:"image_istio_pilot" yaml:"image_istio_pilot"`
-	ImageIstioCitadel      string      `json:"image_istio_citadel" yaml:"image_istio_citadel"`
-	ImageIstioGalley       string      `json:"image_istio_galley" yaml:"image_istio_galley"`
+	InstanceType             *string               `json:"instance_type" yaml:"instance_type"`
+	MinInstances             *int64                `json:"min_instances" yaml:"min_instances"`
+	MaxInstances             *int64                `json:"max_instances" yaml:"max_instances"`
+	InstanceVolumeSize       int64                 `json:"instance_volume_size" yaml:"instance_volume_size"`
+	CPUReserve               *string               `json:"cpu_reserve" yaml:"cpu_reserve"` // the cpu on each worker node which isn't available to APIs (derived from the nodes if not set)
+	MemReserve               *string               `json:"mem_reserve" yaml:"mem_reserve"` // the memory on each worker node which isn't available to APIs (derived from the nodes if not set)
+	Spot                     *bool                 `json:"spot" yaml:"spot"`
+	SpotConfig               *SpotConfig           `json:"spot_config" yaml:"spot_config"`
+	ARMInstanceType          *string               `json:"arm_instance_type" yaml:"arm_instance_type"` // the instance type of the arm64 node group (the node group is only created if it's set)
+	ARMMinInstances          int64                 `json:"arm_min_instances" yaml:"arm_min_instances"`
+	ARMMaxInstances          int64                 `json:"arm_max_instances" yaml:"arm_max_instances"`
+	ClusterName              string                `json:"cluster_name" yaml:"cluster_name"`
+	Region                   *string               `json:"region" yaml:"region"`
+	AvailabilityZones        []string              `json:"availability_zones" yaml:"availability_zones"`
+	Bucket                   *string               `json:"bucket" yaml:"bucket"`
+	LogGroup                 string                `json:"log_group" yaml:"log_group"`
+	MaxProjectSize           int64                 `json:"max_project_size" yaml:"max_project_size"`
+	MaxProjectFileSize       int64                 `json:"max_project_file_size" yaml:"max_project_file_size"`
+	MaxProjectFiles          int64                 `json:"max_project_files" yaml:"max_project_files"`
+	DeleteFailedPodReasons   []string              `json:"delete_failed_pod_reasons" yaml:"delete_failed_pod_reasons"`
+	OperatorDeployers        []string              `json:"operator_deployers" yaml:"operator_deployers"`
+	OperatorViewers          []string              `json:"operator_viewers" yaml:"operator_viewers"`
+	OperatorAdmins           []string              `json:"operator_admins" yaml:"operator_admins"`
+	PriorityPermissions      []*PriorityPermission `json:"priority_permissions" yaml:"priority_permissions"`
+	Quotas                   []*Quota              `json:"quotas" yaml:"quotas"`
+	Webhooks                 []*webhooks.Webhook   `json:"webhooks" yaml:"webhooks"`
+	SNSTopicARN              *string               `json:"sns_topic_arn" yaml:"sns_topic_arn"`
+	APIDashboards            bool                  `json:"api_dashboards" yaml:"api_dashboards"` // whether the operator maintains a CloudWatch dashboard for each API
+	DebugExec                bool                  `json:"debug_exec" yaml:"debug_exec"`         // whether operator admins may run commands in API replicas
+	FederatedClusters        []*FederatedCluster   `json:"federated_clusters" yaml:"federated_clusters"`
+	GitOps                   *GitOps               `json:"gitops" yaml:"gitops"`
+	DeployFreeze             *DeployFreeze         `json:"deploy_freeze" yaml:"deploy_freeze"`
+	IngressBackend           string                `json:"ingress_backend" yaml:"ingress_backend"`
+	IngressClass             string                `json:"ingress_class" yaml:"ingress_class"`
+	IngressService           string                `json:"ingress_service" yaml:"ingress_service"` // <namespace>/<name> of the ingress controller's load balancer service
+	IngressGateway           *string               `json:"ingress_gateway" yaml:"ingress_gateway"` // <namespace>/<name> of the Gateway API gateway
+	MTLS                     bool                  `json:"mtls" yaml:"mtls"`                       // whether traffic to the APIs' pods must use Istio mutual TLS
+	OperatorLogLevel         string                `json:"operator_log_level" yaml:"operator_log_level"`
+	Tracing                  *Tracing              `json:"tracing" yaml:"tracing"`
+	MLflow                   *MLflow               `json:"mlflow" yaml:"mlflow"`
+	Grafana                  *Grafana              `json:"grafana" yaml:"grafana"`
+	Telemetry                bool                  `json:"telemetry" yaml:"telemetry"`
+	ImagePythonServe         string                `json:"image_python_serve" yaml:"image_python_serve"`
+	ImagePythonServeGPU      string                `json:"image_python_serve_gpu" yaml:"image_python_serve_gpu"`
+	ImagePythonServeConda    string                `json:"image_python_serve_conda" yaml:"image_python_serve_conda"`
+	ImagePythonServeCondaGPU string                `json:"image_python_serve_conda_gpu" yaml:"image_python_serve_conda_gpu"`
+	ImageTFServe             string                `json:"image_tf_serve" yaml:"image_tf_serve"`
+	ImageTFServeGPU          string                `json:"image_tf_serve_gpu" yaml:"image_tf_serve_gpu"`
+	ImageTFAPI               string                `json:"image_tf_api" yaml:"image_tf_api"`
+	ImageONNXServe           string                `json:"image_onnx_serve" yaml:"image_onnx_serve"`
+	ImageONNXServeGPU        string                `json:"image_onnx_serve_gpu" yaml:"image_onnx_serve_gpu"`
+	ImageSKLearnServe        string                `json:"image_sklearn_serve" yaml:"image_sklearn_serve"`
+	ImageXGBoostServe        string                `json:"image_xgboost_serve" yaml:"image_xgboost_serve"`
+	ImageLightGBMServe       string                `json:"image_lightgbm_serve" yaml:"image_lightgbm_serve"`
+	ImageTritonServe         string                `json:"image_triton_serve" yaml:"image_triton_serve"`
+	ImageTritonServeCPU      *string               `json:"image_triton_serve_cpu" yaml:"image_triton_serve_cpu"`
+	ImageTritonAPI           string                `json:"image_triton_api" yaml:"image_triton_api"`
+	ImagePipelineAPI         string                `json:"image_pipeline_api" yaml:"image_pipeline_api"`
+	ImageOperator            string                `json:"image_operator" yaml:"image_operator"`
+	ImageManager             string                `json:"image_manager" yaml:"image_manager"`
+	ImageDownloader          string                `json:"image_downloader" yaml:"image_downloader"`
+	ImageClusterAutoscaler   string                `json:"image_cluster_autoscaler" yaml:"image_cluster_autoscaler"`
+	ImageMetricsServer       string                `json:"image_metrics_server" yaml:"image_metrics_server"`
+	ImageNvidia              string                `json:"image_nvidia" yaml:"image_nvidia"`
+	ImageDCGMExporter        string                `json:"image_dcgm_exporter" yaml:"image_dcgm_exporter"`
+	ImageFluentd             string                `json:"image_fluentd" yaml:"image_fluentd"`
+	ImageStatsd              string                `json:"image_statsd" yaml:"image_statsd"`
+	ImageRedis               string                `json:"image_redis" yaml:"image_redis"`
+	ImageIstioProxy          string                `json:"image_istio_proxy" yaml:"image_istio_proxy"`
+	ImageIstioPilot          string                `json:"image_istio_pilot" yaml:"image_istio_pilot"`
+	ImageIstioCitadel        string                `json:"image_istio_citadel" yaml:"image_istio_citadel"`
+	ImageIstioGalley         string                `json:"image_istio_galley" yaml:"image_istio_galley"`
 }
 
 type SpotConfig struct {
@@ -81,6 +150,76 @@ type SpotConfig struct {
 	OnDemandBackup                      *bool    `json:"on_demand_backup" yaml:"on_demand_backup"`
 }
 
+// PriorityPermission allows APIs to be deployed with a priority (priorities which aren't listed in any permission may be used by anyone);
+// if a priority is listed in multiple permissions, the API may be deployed if any of them allows it
+type PriorityPermission struct {
+	Priority    string   `json:"priority" yaml:"priority"`
+	Principals  []string `json:"principals" yaml:"principals"`   // IAM users or roles (by ARN) which may deploy APIs with the priority (empty allows all principals)
+	Deployments []string `json:"deployments" yaml:"deployments"` // the deployments which may contain APIs with the priority (empty allows all deployments)
+}
+
+// Quota limits the compute which the APIs of a deployment, or of an owner, may request when they are at their maximum replicas
+type Quota struct {
+	Deployment *string `json:"deployment" yaml:"deployment"`
+	Owner      *string `json:"owner" yaml:"owner"` // the ARN of an IAM user or role (APIs are owned by the principal which created them)
+	CPU        *string `json:"cpu" yaml:"cpu"`
+	Mem        *string `json:"mem" yaml:"mem"`
+	GPU        *int64  `json:"gpu" yaml:"gpu"`
+	Replicas   *int64  `json:"replicas" yaml:"replicas"`
+}
+
+type GitOps struct {
+	Repository string        `json:"repository" yaml:"repository"`
+	Branch     string        `json:"branch" yaml:"branch"`
+	Path       string        `json:"path" yaml:"path"`       // the directory which contains cortex.yaml, relative to the repository's root
+	SSHKey     *string       `json:"ssh_key" yaml:"ssh_key"` // a reference to a deploy key in AWS Secrets Manager or Systems Manager Parameter Store
+	Interval   time.Duration `json:"interval" yaml:"interval"`
+}
+
+// FederatedCluster is another cortex cluster which this cluster's operator can list and deploy APIs to
+type FederatedCluster struct {
+	Name               string `json:"name" yaml:"name"` // the cluster_name of the other cluster, which APIs select in their clusters field
+	OperatorEndpoint   string `json:"operator_endpoint" yaml:"operator_endpoint"`
+	AWSAccessKeyID     string `json:"aws_access_key_id" yaml:"aws_access_key_id"`         // the credentials of an IAM user in the other cluster's account, which the operator calls the other cluster's operator as
+	AWSSecretAccessKey string `json:"aws_secret_access_key" yaml:"aws_secret_access_key"` // a reference to the secret access key in AWS Secrets Manager or Systems Manager Parameter Store
+}
+
+// DeployFreeze rejects deploys while any of its windows is active, unless the deploy is made with the override token
+type DeployFreeze struct {
+	Windows       []*FreezeWindow `json:"windows" yaml:"windows"`
+	OverrideToken *string         `json:"override_token" yaml:"override_token"` // a reference to a token in AWS Secrets Manager or Systems Manager Parameter Store
+}
+
+// FreezeWindow is active for Duration each time Cron fires (cron expressions are evaluated in UTC)
+type FreezeWindow struct {
+	Cron     string        `json:"cron" yaml:"cron"`
+	Duration time.Duration `json:"duration" yaml:"duration"`
+}
+
+type Tracing struct {
+	Endpoint      string  `json:"endpoint" yaml:"endpoint"`             // the base URL of an OTLP/HTTP collector
+	ZipkinAddress *string `json:"zipkin_address" yaml:"zipkin_address"` // <host>:<port> of a Zipkin receiver which the Istio gateways report their spans to
+	SampleRate    float64 `json:"sample_rate" yaml:"sample_rate"`
+}
+
+// Grafana configures the provisioning of a Grafana dashboard for each API (and optionally, of a Prometheus datasource),
+// as ConfigMaps which are loaded by Grafana's sidecar
+type Grafana struct {
+	Namespace       string  `json:"namespace" yaml:"namespace"`               // the namespace which Grafana's sidecar watches for ConfigMaps
+	DashboardLabel  string  `json:"dashboard_label" yaml:"dashboard_label"`   // the label which marks ConfigMaps as dashboards
+	DatasourceLabel string  `json:"datasource_label" yaml:"datasource_label"` // the label which marks ConfigMaps as datasources
+	Folder          string  `json:"folder" yaml:"folder"`                     // the folder which the dashboards are shown in
+	Datasource      string  `json:"datasource" yaml:"datasource"`             // the name of the Prometheus datasource which the dashboards query
+	PrometheusURL   *string `json:"prometheus_url" yaml:"prometheus_url"`     // if set, a datasource (named Datasource) is provisioned for the Prometheus server at this URL
+}
+
+type MLflow struct {
+	TrackingURI   string        `json:"tracking_uri" yaml:"tracking_uri"` // the base URL of the MLflow tracking server which hosts the model registry
+	Token         *string       `json:"token" yaml:"token"`               // a reference to a bearer token in AWS Secrets Manager or Systems Manager Parameter Store
+	Watch         bool          `json:"watch" yaml:"watch"`               // redeploy APIs which serve a model's stage when a different version is transitioned to the stage
+	WatchInterval time.Duration `json:"watch_interval" yaml:"watch_interval"`
+}
+
 type InternalConfig struct {
 	Config
 
@@ -126,6 +265,18 @@ var UserValidation = &cr.StructValidation{
 				LessThanOrEqualTo:    pointer.Int64(16384),
 			},
 		},
+		{
+			StructField: "CPUReserve",
+			StringPtrValidation: &cr.StringPtrValidation{
+				Validator: validateQuantity,
+			},
+		},
+		{
+			StructField: "MemReserve",
+			StringPtrValidation: &cr.StringPtrValidation{
+				Validator: validateQuantity,
+			},
+		},
 		{
 			StructField: "Spot",
 			BoolPtrValidation: &cr.BoolPtrValidation{
@@ -185,6 +336,26 @@ var UserValidation = &cr.StructValidation{
 				},
 			},
 		},
+		{
+			StructField: "ARMInstanceType",
+			StringPtrValidation: &cr.StringPtrValidation{
+				Validator: validateARMInstanceType,
+			},
+		},
+		{
+			StructField: "ARMMinInstances",
+			Int64Validation: &cr.Int64Validation{
+				Default:              0,
+				GreaterThanOrEqualTo: pointer.Int64(0),
+			},
+		},
+		{
+			StructField: "ARMMaxInstances",
+			Int64Validation: &cr.Int64Validation{
+				Default:     5,
+				GreaterThan: pointer.Int64(0),
+			},
+		},
 		{
 			StructField: "ClusterName",
 			StringValidation: &cr.StringValidation{
@@ -212,6 +383,434 @@ var UserValidation = &cr.StructValidation{
 			StringValidation: &cr.StringValidation{},
 			DefaultField:     "ClusterName",
 		},
+		{
+			StructField: "MaxProjectSize",
+			Int64Validation: &cr.Int64Validation{
+				Default:     256,
+				GreaterThan: pointer.Int64(0),
+			},
+		},
+		{
+			StructField: "MaxProjectFileSize",
+			Int64Validation: &cr.Int64Validation{
+				Default:     64,
+				GreaterThan: pointer.Int64(0),
+			},
+		},
+		{
+			StructField: "MaxProjectFiles",
+			Int64Validation: &cr.Int64Validation{
+				Default:     10000,
+				GreaterThan: pointer.Int64(0),
+			},
+		},
+		{
+			StructField: "DeleteFailedPodReasons",
+			StringListValidation: &cr.StringListValidation{
+				Default:      []string{},
+				AllowEmpty:   true,
+				DisallowDups: true,
+				Validator:    validateDeleteFailedPodReasons,
+			},
+		},
+		{
+			StructField: "OperatorDeployers",
+			StringListValidation: &cr.StringListValidation{
+				Default:      []string{},
+				AllowEmpty:   true,
+				DisallowDups: true,
+				Validator:    validateIAMPrincipals,
+			},
+		},
+		{
+			StructField: "OperatorViewers",
+			StringListValidation: &cr.StringListValidation{
+				Default:      []string{},
+				AllowEmpty:   true,
+				DisallowDups: true,
+				Validator:    validateIAMPrincipals,
+			},
+		},
+		{
+			StructField: "OperatorAdmins",
+			StringListValidation: &cr.StringListValidation{
+				Default:      []string{},
+				AllowEmpty:   true,
+				DisallowDups: true,
+				Validator:    validateIAMPrincipals,
+			},
+		},
+		{
+			StructField: "PriorityPermissions",
+			StructListValidation: &cr.StructListValidation{
+				AllowExplicitNull: true,
+				StructValidation: &cr.StructValidation{
+					StructFieldValidations: []*cr.StructFieldValidation{
+						{
+							StructField: "Priority",
+							StringValidation: &cr.StringValidation{
+								Required:      true,
+								AllowedValues: Priorities,
+							},
+						},
+						{
+							StructField: "Principals",
+							StringListValidation: &cr.StringListValidation{
+								Default:      []string{},
+								AllowEmpty:   true,
+								DisallowDups: true,
+								Validator:    validateIAMPrincipals,
+							},
+						},
+						{
+							StructField: "Deployments",
+							StringListValidation: &cr.StringListValidation{
+								Default:      []string{},
+								AllowEmpty:   true,
+								DisallowDups: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			StructField: "Quotas",
+			StructListValidation: &cr.StructListValidation{
+				AllowExplicitNull: true,
+				StructValidation: &cr.StructValidation{
+					StructFieldValidations: []*cr.StructFieldValidation{
+						{
+							StructField:         "Deployment",
+							StringPtrValidation: &cr.StringPtrValidation{},
+						},
+						{
+							StructField: "Owner",
+							StringPtrValidation: &cr.StringPtrValidation{
+								Validator: validateIAMPrincipal,
+							},
+						},
+						{
+							StructField: "CPU",
+							StringPtrValidation: &cr.StringPtrValidation{
+								CastNumeric: true,
+								Validator:   validateQuantity,
+							},
+						},
+						{
+							StructField: "Mem",
+							StringPtrValidation: &cr.StringPtrValidation{
+								CastNumeric: true,
+								Validator:   validateQuantity,
+							},
+						},
+						{
+							StructField: "GPU",
+							Int64PtrValidation: &cr.Int64PtrValidation{
+								GreaterThanOrEqualTo: pointer.Int64(0),
+							},
+						},
+						{
+							StructField: "Replicas",
+							Int64PtrValidation: &cr.Int64PtrValidation{
+								GreaterThanOrEqualTo: pointer.Int64(0),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			StructField:          "Webhooks",
+			StructListValidation: webhooks.Validation,
+		},
+		{
+			StructField: "SNSTopicARN",
+			StringPtrValidation: &cr.StringPtrValidation{
+				Validator: validateSNSTopicARN,
+			},
+		},
+		{
+			StructField: "APIDashboards",
+			BoolValidation: &cr.BoolValidation{
+				Default: true,
+			},
+		},
+		{
+			StructField: "DebugExec",
+			BoolValidation: &cr.BoolValidation{
+				Default: false,
+			},
+		},
+		{
+			StructField: "FederatedClusters",
+			StructListValidation: &cr.StructListValidation{
+				AllowExplicitNull: true,
+				StructValidation: &cr.StructValidation{
+					StructFieldValidations: []*cr.StructFieldValidation{
+						{
+							StructField: "Name",
+							StringValidation: &cr.StringValidation{
+								Required: true,
+							},
+						},
+						{
+							StructField: "OperatorEndpoint",
+							StringValidation: &cr.StringValidation{
+								Required:  true,
+								Validator: validateOperatorEndpoint,
+							},
+						},
+						{
+							StructField: "AWSAccessKeyID",
+							StringValidation: &cr.StringValidation{
+								Required: true,
+							},
+						},
+						{
+							StructField: "AWSSecretAccessKey",
+							StringValidation: &cr.StringValidation{
+								Required:  true,
+								Validator: validateSecretReference,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			StructField: "GitOps",
+			StructValidation: &cr.StructValidation{
+				DefaultNil:        true,
+				AllowExplicitNull: true,
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "Repository",
+						StringValidation: &cr.StringValidation{
+							Required: true,
+						},
+					},
+					{
+						StructField: "Branch",
+						StringValidation: &cr.StringValidation{
+							Default: "master",
+						},
+					},
+					{
+						StructField: "Path",
+						StringValidation: &cr.StringValidation{
+							Default:    "",
+							AllowEmpty: true,
+							Validator:  validateGitOpsPath,
+						},
+					},
+					{
+						StructField: "SSHKey",
+						StringPtrValidation: &cr.StringPtrValidation{
+							Validator: validateSecretReference,
+						},
+					},
+					{
+						StructField: "Interval",
+						DurationValidation: &cr.DurationValidation{
+							Default:              time.Minute,
+							GreaterThanOrEqualTo: pointer.Duration(10 * time.Second),
+						},
+					},
+				},
+			},
+		},
+		{
+			StructField: "DeployFreeze",
+			StructValidation: &cr.StructValidation{
+				DefaultNil:        true,
+				AllowExplicitNull: true,
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "Windows",
+						StructListValidation: &cr.StructListValidation{
+							Required: true,
+							StructValidation: &cr.StructValidation{
+								StructFieldValidations: []*cr.StructFieldValidation{
+									{
+										StructField: "Cron",
+										StringValidation: &cr.StringValidation{
+											Required:  true,
+											Validator: validateFreezeWindowCron,
+										},
+									},
+									{
+										StructField: "Duration",
+										DurationValidation: &cr.DurationValidation{
+											Required:             true,
+											GreaterThanOrEqualTo: pointer.Duration(time.Minute),
+											LessThanOrEqualTo:    pointer.Duration(_maxFreezeWindowDuration),
+										},
+									},
+								},
+							},
+						},
+					},
+					{
+						StructField: "OverrideToken",
+						StringPtrValidation: &cr.StringPtrValidation{
+							Validator: validateSecretReference,
+						},
+					},
+				},
+			},
+		},
+		{
+			StructField: "IngressBackend",
+			StringValidation: &cr.StringValidation{
+				Default:       IstioIngressBackend,
+				AllowedValues: IngressBackends,
+			},
+		},
+		{
+			StructField: "IngressClass",
+			StringValidation: &cr.StringValidation{
+				Default:       "nginx",
+				AllowedValues: IngressClasses,
+			},
+		},
+		{
+			StructField: "IngressService",
+			StringValidation: &cr.StringValidation{
+				Default:   "ingress-nginx/ingress-nginx-controller",
+				Validator: validateNamespacedName,
+			},
+		},
+		{
+			StructField: "IngressGateway",
+			StringPtrValidation: &cr.StringPtrValidation{
+				Validator: validateNamespacedName,
+			},
+		},
+		{
+			StructField: "MTLS",
+			BoolValidation: &cr.BoolValidation{
+				Default: false,
+			},
+		},
+		{
+			StructField: "OperatorLogLevel",
+			StringValidation: &cr.StringValidation{
+				Default:       logging.InfoLevel.String(),
+				AllowedValues: logging.Levels,
+			},
+		},
+		{
+			StructField: "Tracing",
+			StructValidation: &cr.StructValidation{
+				DefaultNil:        true,
+				AllowExplicitNull: true,
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "Endpoint",
+						StringValidation: &cr.StringValidation{
+							Required:  true,
+							Validator: validateTracingEndpoint,
+						},
+					},
+					{
+						StructField: "ZipkinAddress",
+						StringPtrValidation: &cr.StringPtrValidation{
+							Validator: validateHostPort,
+						},
+					},
+					{
+						StructField: "SampleRate",
+						Float64Validation: &cr.Float64Validation{
+							Default:              1,
+							GreaterThanOrEqualTo: pointer.Float64(0),
+							LessThanOrEqualTo:    pointer.Float64(1),
+						},
+					},
+				},
+			},
+		},
+		{
+			StructField: "Grafana",
+			StructValidation: &cr.StructValidation{
+				DefaultNil:        true,
+				AllowExplicitNull: true,
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "Namespace",
+						StringValidation: &cr.StringValidation{
+							Default: "monitoring",
+							DNS1123: true,
+						},
+					},
+					{
+						StructField: "DashboardLabel",
+						StringValidation: &cr.StringValidation{
+							Default: "grafana_dashboard",
+						},
+					},
+					{
+						StructField: "DatasourceLabel",
+						StringValidation: &cr.StringValidation{
+							Default: "grafana_datasource",
+						},
+					},
+					{
+						StructField: "Folder",
+						StringValidation: &cr.StringValidation{
+							Default: "Cortex",
+						},
+					},
+					{
+						StructField: "Datasource",
+						StringValidation: &cr.StringValidation{
+							Default: "Prometheus",
+						},
+					},
+					{
+						StructField: "PrometheusURL",
+						StringPtrValidation: &cr.StringPtrValidation{
+							Validator: validateTracingEndpoint, // any http(s) URL
+						},
+					},
+				},
+			},
+		},
+		{
+			StructField: "MLflow",
+			StructValidation: &cr.StructValidation{
+				DefaultNil:        true,
+				AllowExplicitNull: true,
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "TrackingURI",
+						StringValidation: &cr.StringValidation{
+							Required:  true,
+							Validator: validateMLflowTrackingURI,
+						},
+					},
+					{
+						StructField: "Token",
+						StringPtrValidation: &cr.StringPtrValidation{
+							Validator: validateSecretReference,
+						},
+					},
+					{
+						StructField: "Watch",
+						BoolValidation: &cr.BoolValidation{
+							Default: false,
+						},
+					},
+					{
+						StructField: "WatchInterval",
+						DurationValidation: &cr.DurationValidation{
+							Default:              time.Minute,
+							GreaterThanOrEqualTo: pointer.Duration(10 * time.Second),
+						},
+					},
+				},
+			},
+		},
 		{
 			StructField: "ImagePythonServe",
 			StringValidation: &cr.StringValidation{
@@ -224,6 +823,18 @@ var UserValidation = &cr.StructValidation{
 				Default: "cortexlabs/python-serve-gpu:" + consts.CortexVersion,
 			},
 		},
+		{
+			StructField: "ImagePythonServeConda",
+			StringValidation: &cr.StringValidation{
+				Default: "cortexlabs/python-serve-conda:" + consts.CortexVersion,
+			},
+		},
+		{
+			StructField: "ImagePythonServeCondaGPU",
+			StringValidation: &cr.StringValidation{
+				Default: "cortexlabs/python-serve-conda-gpu:" + consts.CortexVersion,
+			},
+		},
 		{
 			StructField: "ImageTFServe",
 			StringValidation: &cr.StringValidation{
@@ -254,6 +865,47 @@ var UserValidation = &cr.StructValidation{
 				Default: "cortexlabs/onnx-serve-gpu:" + consts.CortexVersion,
 			},
 		},
+		{
+			StructField: "ImageSKLearnServe",
+			StringValidation: &cr.StringValidation{
+				Default: "cortexlabs/sklearn-serve:" + consts.CortexVersion,
+			},
+		},
+		{
+			StructField: "ImageXGBoostServe",
+			StringValidation: &cr.StringValidation{
+				Default: "cortexlabs/xgboost-serve:" + consts.CortexVersion,
+			},
+		},
+		{
+			StructField: "ImageLightGBMServe",
+			StringValidation: &cr.StringValidation{
+				Default: "cortexlabs/lightgbm-serve:" + consts.CortexVersion,
+			},
+		},
+		{
+			StructField: "ImageTritonServe",
+			StringValidation: &cr.StringValidation{
+				Default: "cortexlabs/triton-serve:" + consts.CortexVersion,
+			},
+		},
+		{
+			// Triton's release images are built for gpus, so triton APIs without gpus require a cpu-only build of Triton
+			StructField:         "ImageTritonServeCPU",
+			StringPtrValidation: &cr.StringPtrValidation{},
+		},
+		{
+			StructField: "ImageTritonAPI",
+			StringValidation: &cr.StringValidation{
+				Default: "cortexlabs/triton-api:" + consts.CortexVersion,
+			},
+		},
+		{
+			StructField: "ImagePipelineAPI",
+			StringValidation: &cr.StringValidation{
+				Default: "cortexlabs/pipeline-api:" + consts.CortexVersion,
+			},
+		},
 		{
 			StructField: "ImageOperator",
 			StringValidation: &cr.StringValidation{
@@ -290,6 +942,12 @@ var UserValidation = &cr.StructValidation{
 				Default: "cortexlabs/nvidia:" + consts.CortexVersion,
 			},
 		},
+		{
+			StructField: "ImageDCGMExporter",
+			StringValidation: &cr.StringValidation{
+				Default: "cortexlabs/dcgm-exporter:" + consts.CortexVersion,
+			},
+		},
 		{
 			StructField: "ImageFluentd",
 			StringValidation: &cr.StringValidation{
@@ -302,6 +960,12 @@ var UserValidation = &cr.StructValidation{
 				Default: "cortexlabs/statsd:" + consts.CortexVersion,
 			},
 		},
+		{
+			StructField: "ImageRedis",
+			StringValidation: &cr.StringValidation{
+				Default: "cortexlabs/redis:" + consts.CortexVersion,
+			},
+		},
 		{
 			StructField: "ImageIstioProxy",
 			StringValidation: &cr.StringValidation{
@@ -391,7 +1055,7 @@ func (cc *Config) ToAccessConfig() AccessConfig {
 
 func (cc *Config) Validate(accessKeyID string, secretAccessKey string) error {
 	if *cc.MinInstances > *cc.MaxInstances {
-		return ErrorMinInstancesGreaterThanMax(*cc.MinInstances, *cc.MaxInstances)
+		return ErrorMinInstancesGreaterThanMax(MinInstancesKey, MaxInstancesKey, *cc.MinInstances, *cc.MaxInstances)
 	}
 
 	if _, ok := aws.InstanceMetadatas[*cc.Region][*cc.InstanceType]; !ok {
@@ -403,6 +1067,20 @@ func (cc *Config) Validate(accessKeyID string, secretAccessKey string) error {
 		return errors.Wrap(err, InstanceTypeKey)
 	}
 
+	if cc.ARMInstanceType != nil {
+		if cc.ARMMinInstances > cc.ARMMaxInstances {
+			return ErrorMinInstancesGreaterThanMax(ARMMinInstancesKey, ARMMaxInstancesKey, cc.ARMMinInstances, cc.ARMMaxInstances)
+		}
+
+		if _, ok := aws.InstanceMetadatas[*cc.Region][*cc.ARMInstanceType]; !ok {
+			return errors.Wrap(ErrorInstanceTypeNotSupportedInRegion(*cc.ARMInstanceType, *cc.Region), ARMInstanceTypeKey)
+		}
+
+		if err := aws.VerifyInstanceQuota(accessKeyID, secretAccessKey, *cc.Region, *cc.ARMInstanceType); err != nil {
+			return errors.Wrap(err, ARMInstanceTypeKey)
+		}
+	}
+
 	if len(cc.AvailabilityZones) > 0 {
 		zones, err := aws.GetAvailabilityZones(accessKeyID, secretAccessKey, *cc.Region)
 		if err != nil {
@@ -417,6 +1095,33 @@ func (cc *Config) Validate(accessKeyID string, secretAccessKey string) error {
 		}
 	}
 
+	if cc.SNSTopicARN != nil && aws.SNSTopicRegion(*cc.SNSTopicARN) != *cc.Region {
+		return errors.Wrap(ErrorSNSTopicRegionMismatch(*cc.SNSTopicARN, *cc.Region), SNSTopicARNKey)
+	}
+
+	for i, quota := range cc.Quotas {
+		if (quota.Deployment == nil) == (quota.Owner == nil) {
+			return errors.Wrap(ErrorQuotaDeploymentOrOwner(), QuotasKey, s.Index(i))
+		}
+	}
+
+	if cc.IngressBackend == GatewayAPIIngressBackend && cc.IngressGateway == nil {
+		return errors.Wrap(ErrorRequiredForIngressBackend(cc.IngressBackend), IngressGatewayKey)
+	}
+
+	// the requests from other ingress controllers aren't sent through an Istio proxy, so they can't use mTLS
+	if cc.MTLS && cc.IngressBackend != IstioIngressBackend {
+		return errors.Wrap(ErrorRequiresIngressBackend(IstioIngressBackend), MTLSKey)
+	}
+
+	if cc.DebugExec && len(cc.OperatorAdmins) == 0 {
+		return errors.Wrap(ErrorDebugExecRequiresOperatorAdmins(), DebugExecKey)
+	}
+
+	if err := validateFederatedClusters(cc.FederatedClusters, cc.ClusterName); err != nil {
+		return err
+	}
+
 	if cc.Spot != nil && *cc.Spot {
 		chosenInstance := aws.InstanceMetadatas[*cc.Region][*cc.InstanceType]
 		compatibleSpots := CompatibleSpotInstances(accessKeyID, secretAccessKey, chosenInstance, cc.SpotConfig.MaxPrice, _spotInstanceDistributionLength)
@@ -472,7 +1177,8 @@ func CheckCortexSupport(instanceMetadata aws.InstanceMetadata) error {
 		return ErrorInstanceTypeNotSupported(instanceMetadata.Type)
 	}
 
-	if _, ok := awsutils.InstanceENIsAvailable[instanceMetadata.Type]; !ok {
+	// the ENI limits of graviton instance types newer than a1 aren't listed by this version of the VPC CNI (eksctl sets their max pods)
+	if _, ok := awsutils.InstanceENIsAvailable[instanceMetadata.Type]; !ok && aws.InstanceArch(instanceMetadata.Type) != aws.ARM64Arch {
 		return ErrorInstanceTypeNotSupported(instanceMetadata.Type)
 	}
 
@@ -480,6 +1186,10 @@ func CheckCortexSupport(instanceMetadata aws.InstanceMetadata) error {
 }
 
 func CheckSpotInstanceCompatibility(accessKeyID string, secretAccessKey string, target aws.InstanceMetadata, suggested aws.InstanceMetadata, maxPrice *float64) error {
+	if aws.InstanceArch(target.Type) != aws.InstanceArch(suggested.Type) {
+		return ErrorIncompatibleSpotInstanceTypeArch(target, suggested)
+	}
+
 	if target.GPU > suggested.GPU {
 		return ErrorIncompatibleSpotInstanceTypeGPU(target, suggested)
 	}
@@ -587,6 +1297,17 @@ func AutoGenerateSpotConfig(accessKeyID string, secretAccessKey string, spotConf
 	return nil
 }
 
+// ValidateSNSTopic checks that the operator's credentials can publish to the SNS topic
+func (cc *Config) ValidateSNSTopic(operatorAccessKeyID string, operatorSecretAccessKey string) error {
+	if cc.SNSTopicARN == nil {
+		return nil
+	}
+	if err := aws.VerifySNSTopicPublishable(operatorAccessKeyID, operatorSecretAccessKey, *cc.SNSTopicARN); err != nil {
+		return errors.Wrap(err, SNSTopicARNKey)
+	}
+	return nil
+}
+
 func (cc *Config) AutoFillSpot(accessKeyID string, secretAccessKey string) error {
 	if cc.SpotConfig == nil {
 		cc.SpotConfig = &SpotConfig{}
@@ -770,7 +1491,17 @@ var AccessPromptValidation = &cr.PromptValidation{
 	},
 }
 
+// validateInstanceType validates the instance type of the cluster's primary node group (and its spot instances), which must be amd64
 func validateInstanceType(instanceType string) (string, error) {
+	return validateInstanceTypeArch(instanceType, aws.AMD64Arch)
+}
+
+// validateARMInstanceType validates the instance type of the cluster's arm64 node group, which must be a graviton instance type without gpus
+func validateARMInstanceType(instanceType string) (string, error) {
+	return validateInstanceTypeArch(instanceType, aws.ARM64Arch)
+}
+
+func validateInstanceTypeArch(instanceType string, arch string) (string, error) {
 	var foundInstance *aws.InstanceMetadata
 	for _, instanceMap := range aws.InstanceMetadatas {
 		if instanceMetadata, ok := instanceMap[instanceType]; ok {
@@ -788,6 +1519,14 @@ func validateInstanceType(instanceType string) (string, error) {
 		return "", err
 	}
 
+	if instanceArch := aws.InstanceArch(instanceType); instanceArch != arch {
+		return "", ErrorInstanceTypeArch(instanceType, instanceArch, arch)
+	}
+
+	if arch == aws.ARM64Arch && foundInstance.GPU > 0 {
+		return "", ErrorInstanceTypeNotSupported(instanceType)
+	}
+
 	return instanceType, nil
 }
 
@@ -801,6 +1540,219 @@ func validateInstanceDistribution(instances []string) ([]string, error) {
 	return instances, nil
 }
 
+func validateDeleteFailedPodReasons(reasons []string) ([]string, error) {
+	for _, reason := range reasons {
+		if !slices.HasString(DeletableFailedPodReasons, reason) {
+			return nil, cr.ErrorInvalidStr(reason, DeletableFailedPodReasons...)
+		}
+	}
+	return reasons, nil
+}
+
+// WebhookURLs returns the webhooks' URLs without their query params (which may contain tokens)
+func (permission *PriorityPermission) String() string {
+	str := permission.Priority
+	if len(permission.Principals) > 0 {
+		str += " (principals: " + strings.Join(permission.Principals, ", ") + ")"
+	}
+	if len(permission.Deployments) > 0 {
+		str += " (deployments: " + strings.Join(permission.Deployments, ", ") + ")"
+	}
+	return str
+}
+
+func (quota *Quota) String() string {
+	var limits []string
+	if quota.CPU != nil {
+		limits = append(limits, *quota.CPU+" cpu")
+	}
+	if quota.Mem != nil {
+		limits = append(limits, *quota.Mem+" memory")
+	}
+	if quota.GPU != nil {
+		limits = append(limits, s.Int64(*quota.GPU)+" gpu")
+	}
+	if quota.Replicas != nil {
+		limits = append(limits, s.Int64(*quota.Replicas)+" replicas")
+	}
+
+	var str string
+	if quota.Deployment != nil {
+		str = "deployment " + *quota.Deployment
+	} else if quota.Owner != nil {
+		str = "owner " + *quota.Owner
+	}
+	if len(limits) == 0 {
+		return str + ": unlimited"
+	}
+	return str + ": " + strings.Join(limits, ", ")
+}
+
+func WebhookURLs(hooks []*webhooks.Webhook) []string {
+	webhookURLs := make([]string, len(hooks))
+	for i, webhook := range hooks {
+		webhookURLs[i] = urls.TrimQueryParamsStr(webhook.URL)
+	}
+	return webhookURLs
+}
+
+func validateIAMPrincipals(principals []string) ([]string, error) {
+	for _, principal := range principals {
+		if principal != "*" && !aws.IsPrincipalARN(principal) {
+			return nil, ErrorInvalidIAMPrincipal(principal)
+		}
+	}
+	return principals, nil
+}
+
+func validateIAMPrincipal(principal string) (string, error) {
+	if !aws.IsPrincipalARN(principal) {
+		return "", ErrorInvalidIAMPrincipal(principal)
+	}
+	return principal, nil
+}
+
+func validateSNSTopicARN(topicARN string) (string, error) {
+	if !aws.IsSNSTopicARN(topicARN) {
+		return "", ErrorInvalidSNSTopicARN(topicARN)
+	}
+	return topicARN, nil
+}
+
+func validateGitOpsPath(path string) (string, error) {
+	path = strings.Trim(path, "/")
+	for _, part := range strings.Split(path, "/") {
+		if part == ".." {
+			return "", ErrorInvalidGitOpsPath(path)
+		}
+	}
+	return path, nil
+}
+
+func validateFreezeWindowCron(expr string) (string, error) {
+	if _, err := cron.Parse(expr); err != nil {
+		return "", err
+	}
+	return expr, nil
+}
+
+// ActiveWindow returns the first of the freeze's windows which is active at t, along with the time at which it ends
+func (freeze *DeployFreeze) ActiveWindow(t time.Time) (*FreezeWindow, time.Time, bool) {
+	t = t.UTC()
+	for _, window := range freeze.Windows {
+		schedule, err := cron.Parse(window.Cron)
+		if err != nil {
+			continue
+		}
+		if start, ok := schedule.MostRecent(t, window.Duration); ok {
+			return window, start.Add(window.Duration), true
+		}
+	}
+	return nil, time.Time{}, false
+}
+
+func (window *FreezeWindow) String() string {
+	return window.Cron + " for " + window.Duration.String()
+}
+
+func validateNamespacedName(value string) (string, error) {
+	if _, _, ok := SplitNamespacedName(value); !ok {
+		return "", ErrorInvalidNamespacedName(value)
+	}
+	return value, nil
+}
+
+// SplitNamespacedName splits <namespace>/<name> into its namespace and name
+func SplitNamespacedName(value string) (string, string, bool) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func validateTracingEndpoint(endpoint string) (string, error) {
+	u, err := urls.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", urls.ErrorInvalidURL(endpoint)
+	}
+	return endpoint, nil
+}
+
+func validateOperatorEndpoint(endpoint string) (string, error) {
+	u, err := urls.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", urls.ErrorInvalidURL(endpoint)
+	}
+	return strings.TrimSuffix(endpoint, "/"), nil
+}
+
+// validateFederatedClusters checks that each federated cluster has a unique name, which isn't the name of this cluster (its APIs are deployed by its own operator)
+func validateFederatedClusters(clusters []*FederatedCluster, clusterName string) error {
+	names := strset.New()
+	for i, cluster := range clusters {
+		if cluster.Name == clusterName {
+			return errors.Wrap(ErrorFederatedClusterIsSelf(cluster.Name), FederatedClustersKey, s.Index(i), NameKey)
+		}
+		if names.Has(cluster.Name) {
+			return errors.Wrap(ErrorDuplicateFederatedCluster(cluster.Name), FederatedClustersKey, s.Index(i), NameKey)
+		}
+		names.Add(cluster.Name)
+	}
+	return nil
+}
+
+// FederatedClusterNames returns the names of the federated clusters (in the order in which they're configured)
+func FederatedClusterNames(clusters []*FederatedCluster) []string {
+	names := make([]string, len(clusters))
+	for i, cluster := range clusters {
+		names[i] = cluster.Name
+	}
+	return names
+}
+
+func validateMLflowTrackingURI(trackingURI string) (string, error) {
+	u, err := urls.Parse(trackingURI)
+	if err != nil {
+		return "", err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", urls.ErrorInvalidURL(trackingURI)
+	}
+	return strings.TrimSuffix(trackingURI, "/"), nil
+}
+
+func validateHostPort(value string) (string, error) {
+	host, port, err := net.SplitHostPort(value)
+	if err != nil || host == "" || port == "" {
+		return "", ErrorInvalidHostPort(value)
+	}
+	return value, nil
+}
+
+var _secretReferenceRegex = regexp.MustCompile(`^\$\{(secret|ssm):[^}]+\}$`)
+
+func validateQuantity(value string) (string, error) {
+	quantity, err := kresource.ParseQuantity(value)
+	if err != nil || quantity.Sign() < 0 {
+		return "", ErrorInvalidQuantity(value)
+	}
+	return quantity.String(), nil
+}
+
+func validateSecretReference(value string) (string, error) {
+	if !_secretReferenceRegex.MatchString(value) {
+		return "", ErrorMustBeSecretReference()
+	}
+	return value, nil
+}
+
 // This does not set defaults for fields that are prompted from the user
 func SetDefaults(cc *Config) error {
 	var emptyMap interface{} = map[interface{}]interface{}{}
@@ -857,6 +1809,17 @@ func (cc *Config) UserFacingTable() table.KeyValuePairs {
 	items.Add(MinInstancesUserFacingKey, *cc.MinInstances)
 	items.Add(MaxInstancesUserFacingKey, *cc.MaxInstances)
 	items.Add(InstanceVolumeSizeUserFacingKey, cc.InstanceVolumeSize)
+	if cc.CPUReserve != nil {
+		items.Add(CPUReserveUserFacingKey, *cc.CPUReserve)
+	}
+	if cc.MemReserve != nil {
+		items.Add(MemReserveUserFacingKey, *cc.MemReserve)
+	}
+	if cc.ARMInstanceType != nil {
+		items.Add(ARMInstanceTypeUserFacingKey, *cc.ARMInstanceType)
+		items.Add(ARMMinInstancesUserFacingKey, cc.ARMMinInstances)
+		items.Add(ARMMaxInstancesUserFacingKey, cc.ARMMaxInstances)
+	}
 	items.Add(SpotUserFacingKey, s.YesNo(*cc.Spot))
 
 	if cc.Spot != nil && *cc.Spot {
@@ -868,22 +1831,113 @@ func (cc *Config) UserFacingTable() table.KeyValuePairs {
 		items.Add(OnDemandBackupUserFacingKey, s.YesNo(*cc.SpotConfig.OnDemandBackup))
 	}
 	items.Add(LogGroupUserFacingKey, cc.LogGroup)
+	items.Add(MaxProjectSizeUserFacingKey, cc.MaxProjectSize)
+	items.Add(MaxProjectFileSizeUserFacingKey, cc.MaxProjectFileSize)
+	items.Add(MaxProjectFilesUserFacingKey, cc.MaxProjectFiles)
+	if len(cc.DeleteFailedPodReasons) > 0 {
+		items.Add(DeleteFailedPodReasonsUserFacingKey, cc.DeleteFailedPodReasons)
+	}
+	if len(cc.OperatorDeployers) > 0 {
+		items.Add(OperatorDeployersUserFacingKey, cc.OperatorDeployers)
+	}
+	if len(cc.OperatorViewers) > 0 {
+		items.Add(OperatorViewersUserFacingKey, cc.OperatorViewers)
+	}
+	if len(cc.OperatorAdmins) > 0 {
+		items.Add(OperatorAdminsUserFacingKey, cc.OperatorAdmins)
+	}
+	for _, permission := range cc.PriorityPermissions {
+		items.Add(PriorityPermissionsUserFacingKey, permission.String())
+	}
+	for _, quota := range cc.Quotas {
+		items.Add(QuotaUserFacingKey, quota.String())
+	}
+	if len(cc.Webhooks) > 0 {
+		items.Add(WebhooksUserFacingKey, WebhookURLs(cc.Webhooks))
+	}
+	if cc.SNSTopicARN != nil {
+		items.Add(SNSTopicARNUserFacingKey, *cc.SNSTopicARN)
+	}
+	items.Add(APIDashboardsUserFacingKey, cc.APIDashboards)
+	items.Add(DebugExecUserFacingKey, cc.DebugExec)
+	if len(cc.FederatedClusters) > 0 {
+		items.Add(FederatedClustersUserFacingKey, FederatedClusterNames(cc.FederatedClusters))
+	}
+	if cc.GitOps != nil {
+		items.Add(GitOpsRepositoryUserFacingKey, urls.TrimQueryParamsStr(cc.GitOps.Repository))
+		items.Add(GitOpsBranchUserFacingKey, cc.GitOps.Branch)
+		if cc.GitOps.Path != "" {
+			items.Add(GitOpsPathUserFacingKey, cc.GitOps.Path)
+		}
+		items.Add(GitOpsIntervalUserFacingKey, cc.GitOps.Interval.String())
+	}
+	if cc.DeployFreeze != nil {
+		for _, window := range cc.DeployFreeze.Windows {
+			items.Add(DeployFreezeWindowUserFacingKey, window.String())
+		}
+	}
+	items.Add(IngressBackendUserFacingKey, cc.IngressBackend)
+	switch cc.IngressBackend {
+	case KubernetesIngressBackend:
+		items.Add(IngressClassUserFacingKey, cc.IngressClass)
+		items.Add(IngressServiceUserFacingKey, cc.IngressService)
+	case GatewayAPIIngressBackend:
+		if cc.IngressGateway != nil {
+			items.Add(IngressGatewayUserFacingKey, *cc.IngressGateway)
+		}
+	}
+	items.Add(MTLSUserFacingKey, cc.MTLS)
+	items.Add(OperatorLogLevelUserFacingKey, cc.OperatorLogLevel)
+	if cc.Tracing != nil {
+		items.Add(TracingEndpointUserFacingKey, cc.Tracing.Endpoint)
+		if cc.Tracing.ZipkinAddress != nil {
+			items.Add(TracingZipkinAddressUserFacingKey, *cc.Tracing.ZipkinAddress)
+		}
+		items.Add(TracingSampleRateUserFacingKey, cc.Tracing.SampleRate)
+	}
+	if cc.MLflow != nil {
+		items.Add(MLflowTrackingURIUserFacingKey, cc.MLflow.TrackingURI)
+		items.Add(MLflowWatchUserFacingKey, cc.MLflow.Watch)
+		if cc.MLflow.Watch {
+			items.Add(MLflowWatchIntervalUserFacingKey, cc.MLflow.WatchInterval.String())
+		}
+	}
+	if cc.Grafana != nil {
+		items.Add(GrafanaNamespaceUserFacingKey, cc.Grafana.Namespace)
+		items.Add(GrafanaDatasourceUserFacingKey, cc.Grafana.Datasource)
+		if cc.Grafana.PrometheusURL != nil {
+			items.Add(GrafanaPrometheusURLUserFacingKey, *cc.Grafana.PrometheusURL)
+		}
+	}
 	items.Add(TelemetryUserFacingKey, cc.Telemetry)
 	items.Add(ImagePythonServeUserFacingKey, cc.ImagePythonServe)
 	items.Add(ImagePythonServeGPUUserFacingKey, cc.ImagePythonServeGPU)
+	items.Add(ImagePythonServeCondaUserFacingKey, cc.ImagePythonServeConda)
+	items.Add(ImagePythonServeCondaGPUUserFacingKey, cc.ImagePythonServeCondaGPU)
 	items.Add(ImageTFServeUserFacingKey, cc.ImageTFServe)
 	items.Add(ImageTFServeGPUUserFacingKey, cc.ImageTFServeGPU)
 	items.Add(ImageTFAPIUserFacingKey, cc.ImageTFAPI)
 	items.Add(ImageONNXServeUserFacingKey, cc.ImageONNXServe)
 	items.Add(ImageONNXServeGPUUserFacingKey, cc.ImageONNXServeGPU)
+	items.Add(ImageSKLearnServeUserFacingKey, cc.ImageSKLearnServe)
+	items.Add(ImageXGBoostServeUserFacingKey, cc.ImageXGBoostServe)
+	items.Add(ImageLightGBMServeUserFacingKey, cc.ImageLightGBMServe)
+	items.Add(ImageTritonServeUserFacingKey, cc.ImageTritonServe)
+	if cc.ImageTritonServeCPU != nil {
+		items.Add(ImageTritonServeCPUUserFacingKey, *cc.ImageTritonServeCPU)
+	}
+	items.Add(ImageTritonAPIUserFacingKey, cc.ImageTritonAPI)
+	items.Add(ImagePipelineAPIUserFacingKey, cc.ImagePipelineAPI)
 	items.Add(ImageOperatorUserFacingKey, cc.ImageOperator)
 	items.Add(ImageManagerUserFacingKey, cc.ImageManager)
 	items.Add(ImageDownloaderUserFacingKey, cc.ImageDownloader)
 	items.Add(ImageClusterAutoscalerUserFacingKey, cc.ImageClusterAutoscaler)
 	items.Add(ImageMetricsServerUserFacingKey, cc.ImageMetricsServer)
 	items.Add(ImageNvidiaUserFacingKey, cc.ImageNvidia)
+	items.Add(ImageDCGMExporterUserFacingKey, cc.ImageDCGMExporter)
 	items.Add(ImageFluentdUserFacingKey, cc.ImageFluentd)
 	items.Add(ImageStatsdUserFacingKey, cc.ImageStatsd)
+	items.Add(ImageRedisUserFacingKey, cc.ImageRedis)
 	items.Add(ImageIstioProxyUserFacingKey, cc.ImageIstioProxy)
 	items.Add(ImageIstioPilotUserFacingKey, cc.ImageIstioPilot)
 	items.Add(ImageIstioCitadelUserFacingKey, cc.ImageIstioCitadel)
@@ -21,6 +21,8 @@ const (
 	MinInstancesKey                        = "min_instances"
 	MaxInstancesKey                        = "max_instances"
 	InstanceVolumeSizeKey                  = "instance_volume_size"
+	CPUReserveKey                          = "cpu_reserve"
+	MemReserveKey                          = "mem_reserve"
 	SpotKey                                = "spot"
 	SpotConfigKey                          = "spot_config"
 	InstanceDistributionKey                = "instance_distribution"
@@ -29,27 +31,95 @@ const (
 	MaxPriceKey                            = "max_price"
 	InstancePoolsKey                       = "instance_pools"
 	OnDemandBackupKey                      = "on_demand_backup"
+	ARMInstanceTypeKey                     = "arm_instance_type"
+	ARMMinInstancesKey                     = "arm_min_instances"
+	ARMMaxInstancesKey                     = "arm_max_instances"
 	ClusterNameKey                         = "cluster_name"
 	RegionKey                              = "region"
 	AvailabilityZonesKey                   = "availability_zones"
 	BucketKey                              = "bucket"
 	LogGroupKey                            = "log_group"
+	MaxProjectSizeKey                      = "max_project_size"
+	MaxProjectFileSizeKey                  = "max_project_file_size"
+	MaxProjectFilesKey                     = "max_project_files"
+	DeleteFailedPodReasonsKey              = "delete_failed_pod_reasons"
+	OperatorDeployersKey                   = "operator_deployers"
+	OperatorViewersKey                     = "operator_viewers"
+	OperatorAdminsKey                      = "operator_admins"
+	PriorityPermissionsKey                 = "priority_permissions"
+	PriorityKey                            = "priority"
+	PrincipalsKey                          = "principals"
+	DeploymentsKey                         = "deployments"
+	QuotasKey                              = "quotas"
+	DeploymentKey                          = "deployment"
+	OwnerKey                               = "owner"
+	CPUKey                                 = "cpu"
+	MemKey                                 = "mem"
+	GPUKey                                 = "gpu"
+	ReplicasKey                            = "replicas"
+	WebhooksKey                            = "webhooks"
+	SNSTopicARNKey                         = "sns_topic_arn"
+	APIDashboardsKey                       = "api_dashboards"
+	DebugExecKey                           = "debug_exec"
+	FederatedClustersKey                   = "federated_clusters"
+	NameKey                                = "name"
+	OperatorEndpointKey                    = "operator_endpoint"
+	AWSAccessKeyIDKey                      = "aws_access_key_id"
+	AWSSecretAccessKeyKey                  = "aws_secret_access_key"
+	GitOpsKey                              = "gitops"
+	RepositoryKey                          = "repository"
+	BranchKey                              = "branch"
+	PathKey                                = "path"
+	SSHKeyKey                              = "ssh_key"
+	IntervalKey                            = "interval"
+	DeployFreezeKey                        = "deploy_freeze"
+	WindowsKey                             = "windows"
+	CronKey                                = "cron"
+	DurationKey                            = "duration"
+	OverrideTokenKey                       = "override_token"
+	IngressBackendKey                      = "ingress_backend"
+	IngressClassKey                        = "ingress_class"
+	IngressServiceKey                      = "ingress_service"
+	IngressGatewayKey                      = "ingress_gateway"
+	MTLSKey                                = "mtls"
+	OperatorLogLevelKey                    = "operator_log_level"
+	TracingKey                             = "tracing"
+	EndpointKey                            = "endpoint"
+	ZipkinAddressKey                       = "zipkin_address"
+	SampleRateKey                          = "sample_rate"
+	MLflowKey                              = "mlflow"
+	GrafanaKey                             = "grafana"
+	TrackingURIKey                         = "tracking_uri"
+	TokenKey                               = "token"
+	WatchKey                               = "watch"
+	WatchIntervalKey                       = "watch_interval"
 	TelemetryKey                           = "telemetry"
 	ImagePythonServeKey                    = "image_python_serve"
 	ImagePythonServeGPUKey                 = "image_python_serve_gpu"
+	ImagePythonServeCondaKey               = "image_python_serve_conda"
+	ImagePythonServeCondaGPUKey            = "image_python_serve_conda_gpu"
 	ImageTFServeKey                        = "image_tf_serve"
 	ImageTFServeGPUKey                     = "image_tf_serve_gpu"
 	ImageTFAPIKey                          = "image_tf_api"
 	ImageONNXServeKey                      = "image_onnx_serve"
 	ImageONNXServeGPUKey                   = "image_onnx_serve_gpu"
+	ImageSKLearnServeKey                   = "image_sklearn_serve"
+	ImageXGBoostServeKey                   = "image_xgboost_serve"
+	ImageLightGBMServeKey                  = "image_lightgbm_serve"
+	ImageTritonServeKey                    = "image_triton_serve"
+	ImageTritonServeCPUKey                 = "image_triton_serve_cpu"
+	ImageTritonAPIKey                      = "image_triton_api"
+	ImagePipelineAPIKey                    = "image_pipeline_api"
 	ImageOperatorKey                       = "image_operator"
 	ImageManagerKey                        = "image_manager"
 	ImageDownloaderKey                     = "image_downloader"
 	ImageClusterAutoscalerKey              = "image_cluster_autoscaler"
 	ImageMetricsServerKey                  = "image_metrics_server"
 	ImageNvidiaKey                         = "image_nvidia"
+	ImageDCGMExporterKey                   = "image_dcgm_exporter"
 	ImageFluentdKey                        = "image_fluentd"
 	ImageStatsdKey                         = "image_statsd"
+	ImageRedisKey                          = "image_redis"
 	ImageIstioProxyKey                     = "image_istio_proxy"
 	ImageIstioPilotKey                     = "image_istio_pilot"
 	ImageIstioCitadelKey                   = "image_istio_citadel"
@@ -66,6 +136,11 @@ const (
 	MinInstancesUserFacingKey                        = "min instances"
 	MaxInstancesUserFacingKey                        = "max instances"
 	InstanceVolumeSizeUserFacingKey                  = "instance volume size (Gi)"
+	CPUReserveUserFacingKey                          = "cpu reserve"
+	MemReserveUserFacingKey                          = "memory reserve"
+	ARMInstanceTypeUserFacingKey                     = "arm instance type"
+	ARMMinInstancesUserFacingKey                     = "arm min instances"
+	ARMMaxInstancesUserFacingKey                     = "arm max instances"
 	InstanceDistributionUserFacingKey                = "spot instance distribution"
 	OnDemandBaseCapacityUserFacingKey                = "spot on demand base capacity"
 	OnDemandPercentageAboveBaseCapacityUserFacingKey = "spot on demand percentage above base capacity"
@@ -73,22 +148,67 @@ const (
 	InstancePoolsUserFacingKey                       = "spot instance pools"
 	OnDemandBackupUserFacingKey                      = "on demand backup"
 	LogGroupUserFacingKey                            = "cloudwatch log group"
+	MaxProjectSizeUserFacingKey                      = "max project size (Mi)"
+	MaxProjectFileSizeUserFacingKey                  = "max project file size (Mi)"
+	MaxProjectFilesUserFacingKey                     = "max project files"
+	DeleteFailedPodReasonsUserFacingKey              = "delete failed pod reasons"
+	OperatorDeployersUserFacingKey                   = "operator deployers"
+	OperatorViewersUserFacingKey                     = "operator viewers"
+	OperatorAdminsUserFacingKey                      = "operator admins"
+	PriorityPermissionsUserFacingKey                 = "priority permission"
+	QuotaUserFacingKey                               = "quota"
+	WebhooksUserFacingKey                            = "webhooks"
+	SNSTopicARNUserFacingKey                         = "sns topic arn"
+	APIDashboardsUserFacingKey                       = "api dashboards"
+	DebugExecUserFacingKey                           = "debug exec"
+	FederatedClustersUserFacingKey                   = "federated clusters"
+	GitOpsRepositoryUserFacingKey                    = "gitops repository"
+	GitOpsBranchUserFacingKey                        = "gitops branch"
+	GitOpsPathUserFacingKey                          = "gitops path"
+	GitOpsIntervalUserFacingKey                      = "gitops interval"
+	DeployFreezeWindowUserFacingKey                  = "deploy freeze window"
+	IngressBackendUserFacingKey                      = "ingress backend"
+	IngressClassUserFacingKey                        = "ingress class"
+	IngressServiceUserFacingKey                      = "ingress service"
+	IngressGatewayUserFacingKey                      = "ingress gateway"
+	MTLSUserFacingKey                                = "mtls"
+	OperatorLogLevelUserFacingKey                    = "operator log level"
+	TracingEndpointUserFacingKey                     = "tracing endpoint"
+	TracingZipkinAddressUserFacingKey                = "tracing zipkin address"
+	TracingSampleRateUserFacingKey                   = "tracing sample rate"
+	MLflowTrackingURIUserFacingKey                   = "mlflow tracking uri"
+	MLflowWatchUserFacingKey                         = "mlflow watch"
+	MLflowWatchIntervalUserFacingKey                 = "mlflow watch interval"
+	GrafanaNamespaceUserFacingKey                    = "grafana namespace"
+	GrafanaDatasourceUserFacingKey                   = "grafana datasource"
+	GrafanaPrometheusURLUserFacingKey                = "grafana prometheus url"
 	TelemetryUserFacingKey                           = "telemetry"
 	ImagePythonServeUserFacingKey                    = "python serving image"
 	ImagePythonServeGPUUserFacingKey                 = "python serving gpu image"
+	ImagePythonServeCondaUserFacingKey               = "python serving conda image"
+	ImagePythonServeCondaGPUUserFacingKey            = "python serving conda gpu image"
 	ImageTFServeUserFacingKey                        = "tensorflow serving image"
 	ImageTFServeGPUUserFacingKey                     = "tensorflow serving gpu image"
 	ImageTFAPIUserFacingKey                          = "tensorflow api image"
 	ImageONNXServeUserFacingKey                      = "onnx serving image"
 	ImageONNXServeGPUUserFacingKey                   = "onnx serving gpu image"
+	ImageSKLearnServeUserFacingKey                   = "sklearn serving image"
+	ImageXGBoostServeUserFacingKey                   = "xgboost serving image"
+	ImageLightGBMServeUserFacingKey                  = "lightgbm serving image"
+	ImageTritonServeUserFacingKey                    = "triton serving image"
+	ImageTritonServeCPUUserFacingKey                 = "triton serving cpu image"
+	ImageTritonAPIUserFacingKey                      = "triton api image"
+	ImagePipelineAPIUserFacingKey                    = "pipeline api image"
 	ImageOperatorUserFacingKey                       = "operator image"
 	ImageManagerUserFacingKey                        = "manager image"
 	ImageDownloaderUserFacingKey                     = "downloader image"
 	ImageClusterAutoscalerUserFacingKey              = "cluster autoscaler image"
 	ImageMetricsServerUserFacingKey                  = "metrics server image"
 	ImageNvidiaUserFacingKey                         = "nvidia image"
+	ImageDCGMExporterUserFacingKey                   = "dcgm exporter image"
 	ImageFluentdUserFacingKey                        = "fluentd image"
 	ImageStatsdUserFacingKey                         = "statsd image"
+	ImageRedisUserFacingKey                          = "redis image"
 	ImageIstioProxyUserFacingKey                     = "istio proxy image"
 	ImageIstioPilotUserFacingKey                     = "istio pilot image"
 	ImageIstioCitadelUserFacingKey                   = "istio citadel image"
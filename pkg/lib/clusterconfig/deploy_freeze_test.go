@@ -0,0 +1,53 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeployFreezeActiveWindow(t *testing.T) {
+	freeze := &DeployFreeze{
+		Windows: []*FreezeWindow{
+			{Cron: "0 16 * * 5", Duration: 64 * time.Hour}, // weekends (from friday 16:00 to monday 08:00)
+			{Cron: "0 0 20 12 *", Duration: 14 * 24 * time.Hour},
+		},
+	}
+
+	// saturday
+	window, end, ok := freeze.ActiveWindow(time.Date(2020, 3, 7, 12, 0, 0, 0, time.UTC))
+	require.True(t, ok)
+	require.Equal(t, freeze.Windows[0], window)
+	require.Equal(t, time.Date(2020, 3, 9, 8, 0, 0, 0, time.UTC), end)
+
+	// monday morning, once the weekend window has ended
+	_, _, ok = freeze.ActiveWindow(time.Date(2020, 3, 9, 8, 0, 0, 0, time.UTC))
+	require.False(t, ok)
+
+	// windows are evaluated in UTC
+	_, _, ok = freeze.ActiveWindow(time.Date(2020, 3, 6, 10, 0, 0, 0, time.FixedZone("PST", -8*60*60)))
+	require.True(t, ok)
+
+	// a wednesday during the release freeze
+	window, end, ok = freeze.ActiveWindow(time.Date(2020, 12, 30, 12, 0, 0, 0, time.UTC))
+	require.True(t, ok)
+	require.Equal(t, freeze.Windows[1], window)
+	require.Equal(t, time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC), end)
+}
@@ -46,6 +46,22 @@ const (
 	ErrConfigCannotBeChangedOnUpdate
 	ErrInvalidAvailabilityZone
 	ErrInvalidInstanceType
+	ErrInvalidIAMPrincipal
+	ErrInvalidSNSTopicARN
+	ErrSNSTopicRegionMismatch
+	ErrInvalidGitOpsPath
+	ErrMustBeSecretReference
+	ErrInvalidNamespacedName
+	ErrRequiredForIngressBackend
+	ErrInvalidHostPort
+	ErrInvalidQuantity
+	ErrQuotaDeploymentOrOwner
+	ErrInstanceTypeArch
+	ErrIncompatibleSpotInstanceTypeArch
+	ErrRequiresIngressBackend
+	ErrDebugExecRequiresOperatorAdmins
+	ErrFederatedClusterIsSelf
+	ErrDuplicateFederatedCluster
 )
 
 var (
@@ -68,10 +84,26 @@ var (
 		"err_config_cannot_be_changed_on_update",
 		"err_invalid_availability_zone",
 		"err_invalid_instance_type",
+		"err_invalid_iam_principal",
+		"err_invalid_sns_topic_arn",
+		"err_sns_topic_region_mismatch",
+		"err_invalid_gitops_path",
+		"err_must_be_secret_reference",
+		"err_invalid_namespaced_name",
+		"err_required_for_ingress_backend",
+		"err_invalid_host_port",
+		"err_invalid_quantity",
+		"err_quota_deployment_or_owner",
+		"err_instance_type_arch",
+		"err_incompatible_spot_instance_type_arch",
+		"err_requires_ingress_backend",
+		"err_debug_exec_requires_operator_admins",
+		"err_federated_cluster_is_self",
+		"err_duplicate_federated_cluster",
 	}
 )
 
-var _ = [1]int{}[int(ErrInvalidInstanceType)-(len(errorKinds)-1)] // Ensure list length matches
+var _ = [1]int{}[int(ErrDuplicateFederatedCluster)-(len(errorKinds)-1)] // Ensure list length matches
 
 func (t ErrorKind) String() string {
 	return errorKinds[t]
@@ -130,10 +162,10 @@ func ErrorInvalidAWSCredentials() error {
 	})
 }
 
-func ErrorMinInstancesGreaterThanMax(min int64, max int64) error {
+func ErrorMinInstancesGreaterThanMax(minKey string, maxKey string, min int64, max int64) error {
 	return errors.WithStack(Error{
 		Kind:    ErrMinInstancesGreaterThanMax,
-		message: fmt.Sprintf("%s cannot be greater than %s (%d > %d)", MinInstancesKey, MaxInstancesKey, min, max),
+		message: fmt.Sprintf("%s cannot be greater than %s (%d > %d)", minKey, maxKey, min, max),
 	})
 }
 
@@ -235,3 +267,116 @@ func ErrorInvalidInstanceType(instanceType string) error {
 		message: fmt.Sprintf("%s is not a valid instance type", instanceType),
 	})
 }
+
+func ErrorInvalidIAMPrincipal(principal string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidIAMPrincipal,
+		message: fmt.Sprintf("%s is not a valid IAM principal; specify the ARN of an IAM user or role (e.g. arn:aws:iam::123456789012:role/developers), or * to allow every principal in the cluster's AWS account", s.UserStr(principal)),
+	})
+}
+
+func ErrorInvalidSNSTopicARN(topicARN string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidSNSTopicARN,
+		message: fmt.Sprintf("%s is not a valid SNS topic ARN (e.g. arn:aws:sns:us-west-2:123456789012:cortex-alerts)", s.UserStr(topicARN)),
+	})
+}
+
+func ErrorSNSTopicRegionMismatch(topicARN string, region string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrSNSTopicRegionMismatch,
+		message: fmt.Sprintf("SNS topic %s must be in the cluster's region (%s)", topicARN, region),
+	})
+}
+
+func ErrorInvalidGitOpsPath(path string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidGitOpsPath,
+		message: fmt.Sprintf("%s is not a valid path; it must be a directory within the repository", s.UserStr(path)),
+	})
+}
+
+// The value isn't included in the message, since it may be a secret
+func ErrorMustBeSecretReference() error {
+	return errors.WithStack(Error{
+		Kind:    ErrMustBeSecretReference,
+		message: "must be a reference to a secret in AWS Secrets Manager or AWS Systems Manager Parameter Store (e.g. ${secret:cortex-deploy-key} or ${ssm:/cortex/deploy-key})",
+	})
+}
+
+func ErrorInvalidNamespacedName(value string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidNamespacedName,
+		message: fmt.Sprintf("%s must be formatted as <namespace>/<name> (e.g. ingress-nginx/ingress-nginx-controller)", s.UserStr(value)),
+	})
+}
+
+func ErrorRequiredForIngressBackend(ingressBackend string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrRequiredForIngressBackend,
+		message: fmt.Sprintf("must be provided when %s is %s", IngressBackendKey, ingressBackend),
+	})
+}
+
+func ErrorInvalidHostPort(value string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidHostPort,
+		message: fmt.Sprintf("%s must be formatted as <host>:<port> (e.g. otel-collector.monitoring:9411)", s.UserStr(value)),
+	})
+}
+
+func ErrorInvalidQuantity(value string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidQuantity,
+		message: fmt.Sprintf("%s must be a non-negative quantity (e.g. 500m for cpu, or 1Gi for memory)", s.UserStr(value)),
+	})
+}
+
+func ErrorQuotaDeploymentOrOwner() error {
+	return errors.WithStack(Error{
+		Kind:    ErrQuotaDeploymentOrOwner,
+		message: fmt.Sprintf("exactly one of %s or %s must be specified", DeploymentKey, OwnerKey),
+	})
+}
+
+func ErrorInstanceTypeArch(instanceType string, instanceArch string, requiredArch string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInstanceTypeArch,
+		message: fmt.Sprintf("%s is an %s instance type, but an %s instance type is required (%s must be an amd64 instance type, and %s must be a graviton instance type)", instanceType, instanceArch, requiredArch, InstanceTypeKey, ARMInstanceTypeKey),
+	})
+}
+
+func ErrorIncompatibleSpotInstanceTypeArch(target aws.InstanceMetadata, suggested aws.InstanceMetadata) error {
+	return errors.WithStack(Error{
+		Kind:    ErrIncompatibleSpotInstanceTypeArch,
+		message: fmt.Sprintf("all instances must have the same architecture as %s (%s is %s, but %s is %s)", target.Type, target.Type, aws.InstanceArch(target.Type), suggested.Type, aws.InstanceArch(suggested.Type)),
+	})
+}
+
+func ErrorRequiresIngressBackend(ingressBackend string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrRequiresIngressBackend,
+		message: fmt.Sprintf("can only be enabled when %s is %s", IngressBackendKey, ingressBackend),
+	})
+}
+
+func ErrorDebugExecRequiresOperatorAdmins() error {
+	return errors.WithStack(Error{
+		Kind:    ErrDebugExecRequiresOperatorAdmins,
+		message: fmt.Sprintf("can only be enabled when %s is set, since only operator admins may run commands in API replicas", OperatorAdminsKey),
+	})
+}
+
+func ErrorFederatedClusterIsSelf(clusterName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrFederatedClusterIsSelf,
+		message: fmt.Sprintf("%s is this cluster's %s; only other clusters can be federated (this cluster's apis are deployed by its own operator)", s.UserStr(clusterName), ClusterNameKey),
+	})
+}
+
+func ErrorDuplicateFederatedCluster(clusterName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDuplicateFederatedCluster,
+		message: fmt.Sprintf("%s is already the name of a federated cluster", s.UserStr(clusterName)),
+	})
+}
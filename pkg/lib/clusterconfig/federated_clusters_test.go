@@ -0,0 +1,40 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+func TestValidateFederatedClusters(t *testing.T) {
+	clusters := []*FederatedCluster{
+		{Name: "us-east"},
+		{Name: "eu-west"},
+	}
+	require.NoError(t, validateFederatedClusters(clusters, "cortex"))
+	require.Equal(t, []string{"us-east", "eu-west"}, FederatedClusterNames(clusters))
+
+	err := validateFederatedClusters(clusters, "eu-west")
+	require.Equal(t, ErrFederatedClusterIsSelf, errors.Cause(err).(Error).Kind)
+
+	err = validateFederatedClusters(append(clusters, &FederatedCluster{Name: "us-east"}), "cortex")
+	require.Equal(t, ErrDuplicateFederatedCluster, errors.Cause(err).(Error).Kind)
+}
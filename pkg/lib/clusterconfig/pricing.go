@@ -0,0 +1,51 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+)
+
+const (
+	EKSHourlyPrice       = 0.20
+	OperatorInstanceType = "t3.medium"
+	OperatorVolumeSize   = 20  // GB
+	HoursPerMonth        = 730 // the average number of hours in a month, which AWS uses to estimate monthly prices
+
+	_ebsHoursPerMonth = 30 * 24 // EBS prices are per GB-month
+)
+
+// OperatorInstancePrice is the hourly price of the operator's instance
+func OperatorInstancePrice(region string) float64 {
+	return aws.InstanceMetadatas[region][OperatorInstanceType].Price
+}
+
+// EBSHourlyPrice is the hourly price of an EBS volume (sizeGB is in GB)
+func EBSHourlyPrice(region string, sizeGB int64) float64 {
+	return aws.EBSMetadatas[region].Price * float64(sizeGB) / _ebsHoursPerMonth
+}
+
+// FixedHourlyPrice is the hourly price of the cluster's resources other than its worker instances
+// (the EKS cluster, the operator's instance and volume, an ELB for the operator and an ELB for APIs, and the NAT gateway)
+func FixedHourlyPrice(region string) float64 {
+	return EKSHourlyPrice + OperatorInstancePrice(region) + EBSHourlyPrice(region, OperatorVolumeSize) + 2*aws.ELBMetadatas[region].Price + aws.NATMetadatas[region].Price
+}
+
+// WorkerHourlyPrice is the on-demand hourly price of a worker instance of the instance type, including its volume
+func (cc *Config) WorkerHourlyPrice(instanceType string) float64 {
+	return aws.InstanceMetadatas[*cc.Region][instanceType].Price + EBSHourlyPrice(*cc.Region, cc.InstanceVolumeSize)
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPricing(t *testing.T) {
+	region := "us-west-2"
+	ebsPrice := aws.EBSMetadatas[region].Price
+
+	require.InDelta(t, ebsPrice*20/(30*24), EBSHourlyPrice(region, 20), 1e-9)
+
+	fixedPrice := EKSHourlyPrice + aws.InstanceMetadatas[region]["t3.medium"].Price + ebsPrice*20/(30*24) + 2*aws.ELBMetadatas[region].Price + aws.NATMetadatas[region].Price
+	require.InDelta(t, fixedPrice, FixedHourlyPrice(region), 1e-9)
+
+	cc := &Config{Region: pointer.String(region), InstanceVolumeSize: 50}
+	require.InDelta(t, aws.InstanceMetadatas[region]["m5.large"].Price+ebsPrice*50/(30*24), cc.WorkerHourlyPrice("m5.large"), 1e-9)
+}
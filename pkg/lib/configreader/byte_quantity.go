@@ -0,0 +1,212 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configreader
+
+import (
+	"io/ioutil"
+
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/cortexlabs/cortex/pkg/lib/cast"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+)
+
+// ByteQuantityValidation parses durations such as "30s", "5m", or "1h30m" (see time.ParseByteQuantity)
+type ByteQuantityValidation struct {
+	Required             bool
+	Default              int64
+	GreaterThan          *int64
+	GreaterThanOrEqualTo *int64
+	LessThan             *int64
+	LessThanOrEqualTo    *int64
+	Validator            func(int64) (int64, error)
+}
+
+func ByteQuantity(inter interface{}, v *ByteQuantityValidation) (int64, error) {
+	if inter == nil {
+		return 0, ErrorCannotBeNull()
+	}
+	if casted, castOk := cast.InterfaceToInt64(inter); castOk {
+		return ValidateByteQuantity(casted, v)
+	}
+	casted, castOk := inter.(string)
+	if !castOk {
+		return 0, ErrorInvalidByteQuantity(inter)
+	}
+	return ByteQuantityFromStr(casted, v)
+}
+
+func ByteQuantityFromInterfaceMap(key string, iMap map[string]interface{}, v *ByteQuantityValidation) (int64, error) {
+	inter, ok := ReadInterfaceMapValue(key, iMap)
+	if !ok {
+		val, err := ValidateByteQuantityMissing(v)
+		if err != nil {
+			return 0, errors.Wrap(err, key)
+		}
+		return val, nil
+	}
+	val, err := ByteQuantity(inter, v)
+	if err != nil {
+		return 0, errors.Wrap(err, key)
+	}
+	return val, nil
+}
+
+func ByteQuantityFromStrMap(key string, sMap map[string]string, v *ByteQuantityValidation) (int64, error) {
+	valStr, ok := sMap[key]
+	if !ok || valStr == "" {
+		val, err := ValidateByteQuantityMissing(v)
+		if err != nil {
+			return 0, errors.Wrap(err, key)
+		}
+		return val, nil
+	}
+	val, err := ByteQuantityFromStr(valStr, v)
+	if err != nil {
+		return 0, errors.Wrap(err, key)
+	}
+	return val, nil
+}
+
+func ByteQuantityFromStr(valStr string, v *ByteQuantityValidation) (int64, error) {
+	if valStr == "" {
+		return ValidateByteQuantityMissing(v)
+	}
+	quantity, err := kresource.ParseQuantity(valStr)
+	if err != nil {
+		return 0, ErrorInvalidByteQuantity(valStr)
+	}
+	casted, ok := quantity.AsInt64()
+	if !ok {
+		return 0, ErrorInvalidByteQuantity(valStr)
+	}
+	return ValidateByteQuantity(casted, v)
+}
+
+func ByteQuantityFromEnv(envVarName string, v *ByteQuantityValidation) (int64, error) {
+	valStr := ReadEnvVar(envVarName)
+	if valStr == nil || *valStr == "" {
+		val, err := ValidateByteQuantityMissing(v)
+		if err != nil {
+			return 0, errors.Wrap(err, EnvVar(envVarName))
+		}
+		return val, nil
+	}
+	val, err := ByteQuantityFromStr(*valStr, v)
+	if err != nil {
+		return 0, errors.Wrap(err, EnvVar(envVarName))
+	}
+	return val, nil
+}
+
+func ByteQuantityFromFile(filePath string, v *ByteQuantityValidation) (int64, error) {
+	valBytes, err := ioutil.ReadFile(filePath)
+	if err != nil || len(valBytes) == 0 {
+		val, err := ValidateByteQuantityMissing(v)
+		if err != nil {
+			return 0, errors.Wrap(err, filePath)
+		}
+		return val, nil
+	}
+	valStr := string(valBytes)
+	val, err := ByteQuantityFromStr(valStr, v)
+	if err != nil {
+		return 0, errors.Wrap(err, filePath)
+	}
+	return val, nil
+}
+
+func ByteQuantityFromEnvOrFile(envVarName string, filePath string, v *ByteQuantityValidation) (int64, error) {
+	valStr := ReadEnvVar(envVarName)
+	if valStr != nil && *valStr != "" {
+		return ByteQuantityFromEnv(envVarName, v)
+	}
+	return ByteQuantityFromFile(filePath, v)
+}
+
+func ValidateByteQuantityMissing(v *ByteQuantityValidation) (int64, error) {
+	if v.Required {
+		return 0, ErrorMustBeDefined()
+	}
+	return ValidateByteQuantity(v.Default, v)
+}
+
+func ValidateByteQuantity(val int64, v *ByteQuantityValidation) (int64, error) {
+	err := ValidateByteQuantityVal(val, v)
+	if err != nil {
+		return 0, err
+	}
+
+	if v.Validator != nil {
+		return v.Validator(val)
+	}
+	return val, nil
+}
+
+func ValidateByteQuantityVal(val int64, v *ByteQuantityValidation) error {
+	if v.GreaterThan != nil {
+		if val <= *v.GreaterThan {
+			return ErrorMustBeGreaterThan(val, *v.GreaterThan)
+		}
+	}
+	if v.GreaterThanOrEqualTo != nil {
+		if val < *v.GreaterThanOrEqualTo {
+			return ErrorMustBeGreaterThanOrEqualTo(val, *v.GreaterThanOrEqualTo)
+		}
+	}
+	if v.LessThan != nil {
+		if val >= *v.LessThan {
+			return ErrorMustBeLessThan(val, *v.LessThan)
+		}
+	}
+	if v.LessThanOrEqualTo != nil {
+		if val > *v.LessThanOrEqualTo {
+			return ErrorMustBeLessThanOrEqualTo(val, *v.LessThanOrEqualTo)
+		}
+	}
+
+	return nil
+}
+
+//
+// Musts
+//
+
+func MustByteQuantityFromEnv(envVarName string, v *ByteQuantityValidation) int64 {
+	val, err := ByteQuantityFromEnv(envVarName, v)
+	if err != nil {
+		exit.Panic(err)
+	}
+	return val
+}
+
+func MustByteQuantityFromFile(filePath string, v *ByteQuantityValidation) int64 {
+	val, err := ByteQuantityFromFile(filePath, v)
+	if err != nil {
+		exit.Panic(err)
+	}
+	return val
+}
+
+func MustByteQuantityFromEnvOrFile(envVarName string, filePath string, v *ByteQuantityValidation) int64 {
+	val, err := ByteQuantityFromEnvOrFile(envVarName, filePath, v)
+	if err != nil {
+		exit.Panic(err)
+	}
+	return val
+}
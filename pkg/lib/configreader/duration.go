@@ -0,0 +1,203 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configreader
+
+import (
+	"io/ioutil"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+)
+
+// DurationValidation parses durations such as "30s", "5m", or "1h30m" (see time.ParseDuration)
+type DurationValidation struct {
+	Required             bool
+	Default              time.Duration
+	GreaterThan          *time.Duration
+	GreaterThanOrEqualTo *time.Duration
+	LessThan             *time.Duration
+	LessThanOrEqualTo    *time.Duration
+	Validator            func(time.Duration) (time.Duration, error)
+}
+
+func Duration(inter interface{}, v *DurationValidation) (time.Duration, error) {
+	if inter == nil {
+		return 0, ErrorCannotBeNull()
+	}
+	casted, castOk := inter.(string)
+	if !castOk {
+		return 0, ErrorInvalidDuration(inter)
+	}
+	return DurationFromStr(casted, v)
+}
+
+func DurationFromInterfaceMap(key string, iMap map[string]interface{}, v *DurationValidation) (time.Duration, error) {
+	inter, ok := ReadInterfaceMapValue(key, iMap)
+	if !ok {
+		val, err := ValidateDurationMissing(v)
+		if err != nil {
+			return 0, errors.Wrap(err, key)
+		}
+		return val, nil
+	}
+	val, err := Duration(inter, v)
+	if err != nil {
+		return 0, errors.Wrap(err, key)
+	}
+	return val, nil
+}
+
+func DurationFromStrMap(key string, sMap map[string]string, v *DurationValidation) (time.Duration, error) {
+	valStr, ok := sMap[key]
+	if !ok || valStr == "" {
+		val, err := ValidateDurationMissing(v)
+		if err != nil {
+			return 0, errors.Wrap(err, key)
+		}
+		return val, nil
+	}
+	val, err := DurationFromStr(valStr, v)
+	if err != nil {
+		return 0, errors.Wrap(err, key)
+	}
+	return val, nil
+}
+
+func DurationFromStr(valStr string, v *DurationValidation) (time.Duration, error) {
+	if valStr == "" {
+		return ValidateDurationMissing(v)
+	}
+	casted, err := time.ParseDuration(valStr)
+	if err != nil {
+		return 0, ErrorInvalidDuration(valStr)
+	}
+	return ValidateDuration(casted, v)
+}
+
+func DurationFromEnv(envVarName string, v *DurationValidation) (time.Duration, error) {
+	valStr := ReadEnvVar(envVarName)
+	if valStr == nil || *valStr == "" {
+		val, err := ValidateDurationMissing(v)
+		if err != nil {
+			return 0, errors.Wrap(err, EnvVar(envVarName))
+		}
+		return val, nil
+	}
+	val, err := DurationFromStr(*valStr, v)
+	if err != nil {
+		return 0, errors.Wrap(err, EnvVar(envVarName))
+	}
+	return val, nil
+}
+
+func DurationFromFile(filePath string, v *DurationValidation) (time.Duration, error) {
+	valBytes, err := ioutil.ReadFile(filePath)
+	if err != nil || len(valBytes) == 0 {
+		val, err := ValidateDurationMissing(v)
+		if err != nil {
+			return 0, errors.Wrap(err, filePath)
+		}
+		return val, nil
+	}
+	valStr := string(valBytes)
+	val, err := DurationFromStr(valStr, v)
+	if err != nil {
+		return 0, errors.Wrap(err, filePath)
+	}
+	return val, nil
+}
+
+func DurationFromEnvOrFile(envVarName string, filePath string, v *DurationValidation) (time.Duration, error) {
+	valStr := ReadEnvVar(envVarName)
+	if valStr != nil && *valStr != "" {
+		return DurationFromEnv(envVarName, v)
+	}
+	return DurationFromFile(filePath, v)
+}
+
+func ValidateDurationMissing(v *DurationValidation) (time.Duration, error) {
+	if v.Required {
+		return 0, ErrorMustBeDefined()
+	}
+	return ValidateDuration(v.Default, v)
+}
+
+func ValidateDuration(val time.Duration, v *DurationValidation) (time.Duration, error) {
+	err := ValidateDurationVal(val, v)
+	if err != nil {
+		return 0, err
+	}
+
+	if v.Validator != nil {
+		return v.Validator(val)
+	}
+	return val, nil
+}
+
+func ValidateDurationVal(val time.Duration, v *DurationValidation) error {
+	if v.GreaterThan != nil {
+		if val <= *v.GreaterThan {
+			return ErrorMustBeGreaterThan(val.String(), v.GreaterThan.String())
+		}
+	}
+	if v.GreaterThanOrEqualTo != nil {
+		if val < *v.GreaterThanOrEqualTo {
+			return ErrorMustBeGreaterThanOrEqualTo(val.String(), v.GreaterThanOrEqualTo.String())
+		}
+	}
+	if v.LessThan != nil {
+		if val >= *v.LessThan {
+			return ErrorMustBeLessThan(val.String(), v.LessThan.String())
+		}
+	}
+	if v.LessThanOrEqualTo != nil {
+		if val > *v.LessThanOrEqualTo {
+			return ErrorMustBeLessThanOrEqualTo(val.String(), v.LessThanOrEqualTo.String())
+		}
+	}
+
+	return nil
+}
+
+//
+// Musts
+//
+
+func MustDurationFromEnv(envVarName string, v *DurationValidation) time.Duration {
+	val, err := DurationFromEnv(envVarName, v)
+	if err != nil {
+		exit.Panic(err)
+	}
+	return val
+}
+
+func MustDurationFromFile(filePath string, v *DurationValidation) time.Duration {
+	val, err := DurationFromFile(filePath, v)
+	if err != nil {
+		exit.Panic(err)
+	}
+	return val
+}
+
+func MustDurationFromEnvOrFile(envVarName string, filePath string, v *DurationValidation) time.Duration {
+	val, err := DurationFromEnvOrFile(envVarName, filePath, v)
+	if err != nil {
+		exit.Panic(err)
+	}
+	return val
+}
@@ -57,6 +57,13 @@ const (
 	ErrMustBeEmpty
 	ErrCortexResourceOnlyAllowed
 	ErrCortexResourceNotAllowed
+	ErrIgnoredKey
+	ErrDeprecatedKey
+	ErrInvalidDuration
+	ErrInvalidByteQuantity
+	ErrInvalidPercentage
+	ErrMustBeDefinedWhen
+	ErrCannotBeDefinedWhen
 )
 
 var errorKinds = []string{
@@ -90,9 +97,16 @@ var errorKinds = []string{
 	"err_must_be_empty",
 	"err_cortex_resource_only_allowed",
 	"err_cortex_resource_not_allowed",
+	"err_ignored_key",
+	"err_deprecated_key",
+	"err_invalid_duration",
+	"err_invalid_byte_quantity",
+	"err_invalid_percentage",
+	"err_must_be_defined_when",
+	"err_cannot_be_defined_when",
 }
 
-var _ = [1]int{}[int(ErrCortexResourceNotAllowed)-(len(errorKinds)-1)] // Ensure list length matches
+var _ = [1]int{}[int(ErrCannotBeDefinedWhen)-(len(errorKinds)-1)] // Ensure list length matches
 
 func (t ErrorKind) String() string {
 	return errorKinds[t]
@@ -344,3 +358,56 @@ func ErrorCortexResourceNotAllowed(resourceName string) error {
 		message: fmt.Sprintf("@%s: cortex resource references (which start with @) are not allowed in this context", resourceName),
 	})
 }
+
+func ErrorIgnoredKey(key interface{}) error {
+	return errors.WithStack(Error{
+		Kind:    ErrIgnoredKey,
+		message: fmt.Sprintf("key %s is not supported and will be ignored", s.UserStr(key)),
+	})
+}
+
+func ErrorDeprecatedKey(key interface{}, message string) error {
+	msg := fmt.Sprintf("key %s is deprecated", s.UserStr(key))
+	if message != "" {
+		msg += " (" + message + ")"
+	}
+	return errors.WithStack(Error{
+		Kind:    ErrDeprecatedKey,
+		message: msg,
+	})
+}
+
+func ErrorInvalidDuration(provided interface{}) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidDuration,
+		message: fmt.Sprintf("%s: invalid duration (e.g. 30s, 5m, or 1h30m)", s.UserStr(provided)),
+	})
+}
+
+func ErrorInvalidByteQuantity(provided interface{}) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidByteQuantity,
+		message: fmt.Sprintf("%s: invalid size (e.g. 512Mi, 1G, or a number of bytes)", s.UserStr(provided)),
+	})
+}
+
+func ErrorInvalidPercentage(provided interface{}) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidPercentage,
+		message: fmt.Sprintf("%s: invalid percentage (e.g. 75%% or 75)", s.UserStr(provided)),
+	})
+}
+
+func ErrorMustBeDefinedWhen(conditionKey string, conditionVal interface{}) error {
+	return errors.WithStack(Error{
+		Kind:    ErrMustBeDefinedWhen,
+		message: fmt.Sprintf("must be defined when %s is %s", conditionKey, s.UserStr(conditionVal)),
+	})
+}
+
+func ErrorCannotBeDefinedWhen(conditionKey string, conditionVal interface{}) error {
+	return errors.WithStack(Error{
+		Kind:    ErrCannotBeDefinedWhen,
+		message: fmt.Sprintf("cannot be defined when %s is %s", conditionKey, s.UserStr(conditionVal)),
+	})
+}
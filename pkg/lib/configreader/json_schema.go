@@ -0,0 +1,324 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configreader
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// Regular expressions for string validations which are enforced by the reader (used to populate JSON schema patterns)
+var stringPatterns = map[string]string{
+	"AlphaNumericDashDotUnderscore": `^[a-zA-Z0-9_\-\.]+$`,
+	"AlphaNumericDashUnderscore":    `^[a-zA-Z0-9_\-]+$`,
+	"DNS1035":                       `^[a-z]([-a-z0-9]*[a-z0-9])?$`,
+	"DNS1123":                       `^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`,
+}
+
+// Patterns for the string forms of durations (e.g. 1h30m), byte quantities (e.g. 512Mi), and percentages (e.g. 75%)
+const (
+	durationPattern     = `^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	byteQuantityPattern = `^[0-9]+(\.[0-9]+)?([eE][0-9]+|k|Ki|[MGTPE]i?)?$`
+	percentagePattern   = `^[0-9]+(\.[0-9]+)?%?$`
+)
+
+// StructJSONSchema generates a JSON schema (draft-07) which describes the configuration accepted by Struct(dest, ..., v).
+// dest is a pointer to the destination struct type (e.g. &MyStruct{} or (*MyStruct)(nil)).
+// Custom validators and parsers can't be represented, so the schema may accept values which are rejected by the reader.
+func StructJSONSchema(dest interface{}, v *StructValidation) map[string]interface{} {
+	schema := structSchema(reflect.TypeOf(dest), v.StructFieldValidations, v.AllowExtraFields)
+	if v.AllowExplicitNull {
+		schema["type"] = []string{"object", "null"}
+	}
+	return schema
+}
+
+func structSchema(destType reflect.Type, fieldValidations []*StructFieldValidation, allowExtraFields bool) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+
+	for _, fieldValidation := range fieldValidations {
+		key := inferKey(destType, fieldValidation.StructField, fieldValidation.Key)
+		if fieldValidation.Nil {
+			properties[key] = map[string]interface{}{}
+			continue
+		}
+		fieldSchema, isRequired := structFieldSchema(destType, fieldValidation)
+		properties[key] = fieldSchema
+		if isRequired {
+			required = append(required, key)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	if !allowExtraFields {
+		schema["additionalProperties"] = false
+	}
+	return schema
+}
+
+func structFieldSchema(destType reflect.Type, fieldValidation *StructFieldValidation) (map[string]interface{}, bool) {
+	validationName, validation := fieldValidationValue(fieldValidation)
+	if validationName == "" {
+		return map[string]interface{}{}, false
+	}
+
+	var fieldType reflect.Type
+	if field, ok := destType.Elem().FieldByName(fieldValidation.StructField); ok {
+		fieldType = field.Type
+		if fieldType.Kind() == reflect.Struct {
+			fieldType = reflect.PtrTo(fieldType)
+		}
+	}
+
+	var schema map[string]interface{}
+	typeName := strings.TrimSuffix(validationName, "Validation")
+
+	switch typeName {
+	case "Struct":
+		v := validation.Interface().(*StructValidation)
+		schema = structSchema(fieldType, v.StructFieldValidations, v.AllowExtraFields)
+		applyNullable(schema, v.AllowExplicitNull)
+		return schema, v.Required
+	case "StructList":
+		v := validation.Interface().(*StructListValidation)
+		itemType := fieldType.Elem()
+		if itemType.Kind() != reflect.Ptr {
+			itemType = reflect.PtrTo(itemType)
+		}
+		schema = map[string]interface{}{
+			"type":  "array",
+			"items": structSchema(itemType, v.StructValidation.StructFieldValidations, v.StructValidation.AllowExtraFields),
+		}
+		applyNullable(schema, v.AllowExplicitNull)
+		return schema, v.Required
+	case "InterfaceStruct":
+		v := validation.Interface().(*InterfaceStructValidation)
+		schema = interfaceStructSchema(v)
+		applyNullable(schema, v.AllowExplicitNull)
+		return schema, v.Required
+	case "InterfaceStructList":
+		v := validation.Interface().(*InterfaceStructListValidation)
+		schema = map[string]interface{}{
+			"type":  "array",
+			"items": interfaceStructSchema(v.InterfaceStructValidation),
+		}
+		applyNullable(schema, v.AllowExplicitNull)
+		return schema, v.Required
+	}
+
+	isList := strings.HasSuffix(typeName, "List")
+	baseName := strings.TrimSuffix(strings.TrimSuffix(typeName, "List"), "Ptr")
+
+	schema = map[string]interface{}{}
+	elemSchema := schema
+	if isList {
+		elemSchema = map[string]interface{}{}
+		schema["type"] = "array"
+		schema["items"] = elemSchema
+	}
+
+	switch baseName {
+	case "String":
+		elemSchema["type"] = "string"
+		if boolField(validation, "CastScalar") {
+			elemSchema["type"] = []string{"string", "number", "boolean"}
+		} else if boolField(validation, "CastNumeric") {
+			elemSchema["type"] = []string{"string", "number"}
+		}
+		if prefix := stringField(validation, "Prefix"); prefix != "" {
+			elemSchema["pattern"] = "^" + regexp.QuoteMeta(prefix)
+		}
+		var patterns []string
+		for _, fieldName := range sortedPatternFields() {
+			if boolField(validation, fieldName) {
+				patterns = append(patterns, stringPatterns[fieldName])
+			}
+		}
+		if len(patterns) == 1 {
+			elemSchema["pattern"] = patterns[0]
+		} else if len(patterns) > 1 {
+			var allOf []interface{}
+			for _, pattern := range patterns {
+				allOf = append(allOf, map[string]interface{}{"pattern": pattern})
+			}
+			elemSchema["allOf"] = allOf
+		}
+		if !isList && !boolField(validation, "AllowEmpty") {
+			elemSchema["minLength"] = 1
+		}
+	case "Bool":
+		elemSchema["type"] = "boolean"
+	case "Int", "Int32", "Int64":
+		elemSchema["type"] = "integer"
+	case "Float32", "Float64":
+		elemSchema["type"] = "number"
+	case "Duration":
+		elemSchema["type"] = "string"
+		elemSchema["pattern"] = durationPattern
+	case "ByteQuantity":
+		elemSchema["type"] = []string{"string", "integer"}
+		elemSchema["pattern"] = byteQuantityPattern
+	case "Percentage":
+		elemSchema["type"] = []string{"string", "number"}
+		elemSchema["pattern"] = percentagePattern
+	case "StringMap":
+		elemSchema["type"] = "object"
+		elemSchema["additionalProperties"] = map[string]interface{}{"type": "string"}
+	case "InterfaceMap":
+		elemSchema["type"] = "object"
+	}
+
+	if isList && !boolField(validation, "AllowEmpty") && hasField(validation, "AllowEmpty") {
+		schema["minItems"] = 1
+	}
+
+	if allowedValues := sliceField(validation, "AllowedValues"); allowedValues != nil {
+		elemSchema["enum"] = allowedValues
+	}
+
+	numericBounds := map[string]string{
+		"GreaterThan":          "exclusiveMinimum",
+		"GreaterThanOrEqualTo": "minimum",
+		"LessThan":             "exclusiveMaximum",
+		"LessThanOrEqualTo":    "maximum",
+	}
+	for fieldName, schemaKey := range numericBounds {
+		if baseName == "Duration" {
+			break // durations are strings, so bounds can't be expressed
+		}
+		if bound := validation.Elem().FieldByName(fieldName); bound.IsValid() && bound.Kind() == reflect.Ptr && !bound.IsNil() {
+			elemSchema[schemaKey] = bound.Elem().Interface()
+		}
+	}
+
+	if defaultVal := validation.Elem().FieldByName("Default"); defaultVal.IsValid() && !defaultVal.IsZero() {
+		if duration, ok := reflect.Indirect(defaultVal).Interface().(time.Duration); ok {
+			schema["default"] = duration.String()
+		} else {
+			schema["default"] = reflect.Indirect(defaultVal).Interface()
+		}
+	}
+
+	applyNullable(schema, boolField(validation, "AllowExplicitNull"))
+
+	return schema, boolField(validation, "Required")
+}
+
+func interfaceStructSchema(v *InterfaceStructValidation) map[string]interface{} {
+	var options []interface{}
+
+	typeStrs := make([]string, 0, len(v.InterfaceStructTypes))
+	for typeStr := range v.InterfaceStructTypes {
+		typeStrs = append(typeStrs, typeStr)
+	}
+	sort.Strings(typeStrs)
+
+	for _, typeStr := range typeStrs {
+		structType := v.InterfaceStructTypes[typeStr]
+		fieldValidations := append([]*StructFieldValidation{{Key: v.TypeKey, Nil: true}}, structType.StructFieldValidations...)
+		option := structSchema(reflect.TypeOf(structType.Type), fieldValidations, v.AllowExtraFields)
+		option["properties"].(map[string]interface{})[v.TypeKey] = map[string]interface{}{"const": typeStr}
+		option["required"] = append([]string{v.TypeKey}, stringsOrNil(option["required"])...)
+		options = append(options, option)
+	}
+
+	if len(options) == 0 {
+		return map[string]interface{}{"type": "object"}
+	}
+	return map[string]interface{}{"oneOf": options}
+}
+
+func sortedPatternFields() []string {
+	fieldNames := make([]string, 0, len(stringPatterns))
+	for fieldName := range stringPatterns {
+		fieldNames = append(fieldNames, fieldName)
+	}
+	sort.Strings(fieldNames)
+	return fieldNames
+}
+
+func fieldValidationValue(fieldValidation *StructFieldValidation) (string, reflect.Value) {
+	fieldValidationValue := reflect.ValueOf(fieldValidation).Elem()
+	fieldValidationType := fieldValidationValue.Type()
+	for i := 0; i < fieldValidationType.NumField(); i++ {
+		field := fieldValidationType.Field(i)
+		if !strings.HasSuffix(field.Name, "Validation") || field.Type.Kind() != reflect.Ptr {
+			continue
+		}
+		if value := fieldValidationValue.Field(i); !value.IsNil() {
+			return field.Name, value
+		}
+	}
+	return "", reflect.Value{}
+}
+
+func applyNullable(schema map[string]interface{}, nullable bool) {
+	if !nullable {
+		return
+	}
+	switch schemaType := schema["type"].(type) {
+	case string:
+		schema["type"] = []string{schemaType, "null"}
+	case []string:
+		schema["type"] = append(schemaType, "null")
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		schema["oneOf"] = append(oneOf, map[string]interface{}{"type": "null"})
+	}
+}
+
+func hasField(validation reflect.Value, fieldName string) bool {
+	return validation.Elem().FieldByName(fieldName).IsValid()
+}
+
+func boolField(validation reflect.Value, fieldName string) bool {
+	field := validation.Elem().FieldByName(fieldName)
+	return field.IsValid() && field.Kind() == reflect.Bool && field.Bool()
+}
+
+func stringField(validation reflect.Value, fieldName string) string {
+	field := validation.Elem().FieldByName(fieldName)
+	if !field.IsValid() || field.Kind() != reflect.String {
+		return ""
+	}
+	return field.String()
+}
+
+func sliceField(validation reflect.Value, fieldName string) interface{} {
+	field := validation.Elem().FieldByName(fieldName)
+	if !field.IsValid() || field.Kind() != reflect.Slice || field.Len() == 0 {
+		return nil
+	}
+	return field.Interface()
+}
+
+func stringsOrNil(val interface{}) []string {
+	strs, _ := val.([]string)
+	return strs
+}
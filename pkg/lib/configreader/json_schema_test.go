@@ -0,0 +1,149 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configreader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+)
+
+type SchemaConfig struct {
+	Name     string            `json:"name"`
+	Replicas *int64            `json:"replicas"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+	Nested   *SchemaNested     `json:"nested"`
+	Items    []*SchemaNested   `json:"items"`
+}
+
+type SchemaNested struct {
+	Type  string  `json:"type"`
+	Ratio float64 `json:"ratio"`
+}
+
+func TestStructJSONSchema(t *testing.T) {
+	nestedValidation := &StructValidation{
+		StructFieldValidations: []*StructFieldValidation{
+			{
+				StructField: "Type",
+				StringValidation: &StringValidation{
+					Required:      true,
+					AllowedValues: []string{"a", "b"},
+				},
+			},
+			{
+				StructField: "Ratio",
+				Float64Validation: &Float64Validation{
+					Default:           0.5,
+					LessThanOrEqualTo: pointer.Float64(1),
+				},
+			},
+		},
+	}
+
+	schema := StructJSONSchema((*SchemaConfig)(nil), &StructValidation{
+		StructFieldValidations: []*StructFieldValidation{
+			{
+				StructField: "Name",
+				StringValidation: &StringValidation{
+					Required: true,
+					DNS1123:  true,
+				},
+			},
+			{
+				Key:         "count",
+				StructField: "Replicas",
+				Int64PtrValidation: &Int64PtrValidation{
+					AllowExplicitNull: true,
+					GreaterThan:       pointer.Int64(0),
+				},
+			},
+			{
+				StructField:          "Tags",
+				StringListValidation: &StringListValidation{},
+			},
+			{
+				StructField:         "Labels",
+				StringMapValidation: &StringMapValidation{AllowEmpty: true},
+			},
+			{
+				StructField:      "Nested",
+				StructValidation: nestedValidation,
+			},
+			{
+				StructField: "Items",
+				StructListValidation: &StructListValidation{
+					StructValidation: nestedValidation,
+				},
+			},
+		},
+	})
+
+	nestedSchema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{
+				"type":      "string",
+				"minLength": 1,
+				"enum":      []string{"a", "b"},
+			},
+			"ratio": map[string]interface{}{
+				"type":    "number",
+				"maximum": float64(1),
+				"default": 0.5,
+			},
+		},
+		"required":             []string{"type"},
+		"additionalProperties": false,
+	}
+
+	expected := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{
+				"type":      "string",
+				"minLength": 1,
+				"pattern":   `^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`,
+			},
+			"count": map[string]interface{}{
+				"type":             []string{"integer", "null"},
+				"exclusiveMinimum": int64(0),
+			},
+			"tags": map[string]interface{}{
+				"type":     "array",
+				"items":    map[string]interface{}{"type": "string"},
+				"minItems": 1,
+			},
+			"labels": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+			},
+			"nested": nestedSchema,
+			"items": map[string]interface{}{
+				"type":  "array",
+				"items": nestedSchema,
+			},
+		},
+		"required":             []string{"name"},
+		"additionalProperties": false,
+	}
+
+	require.Equal(t, expected, schema)
+}
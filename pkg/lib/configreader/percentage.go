@@ -0,0 +1,208 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configreader
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/cast"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// PercentageValidation parses percentages such as "75%" or 75 (the parsed value is the percentage, e.g. 75)
+type PercentageValidation struct {
+	Required             bool
+	Default              float64
+	GreaterThan          *float64
+	GreaterThanOrEqualTo *float64
+	LessThan             *float64
+	LessThanOrEqualTo    *float64
+	Validator            func(float64) (float64, error)
+}
+
+func Percentage(inter interface{}, v *PercentageValidation) (float64, error) {
+	if inter == nil {
+		return 0, ErrorCannotBeNull()
+	}
+	if casted, castOk := cast.InterfaceToFloat64(inter); castOk {
+		return ValidatePercentage(casted, v)
+	}
+	casted, castOk := inter.(string)
+	if !castOk {
+		return 0, ErrorInvalidPercentage(inter)
+	}
+	return PercentageFromStr(casted, v)
+}
+
+func PercentageFromInterfaceMap(key string, iMap map[string]interface{}, v *PercentageValidation) (float64, error) {
+	inter, ok := ReadInterfaceMapValue(key, iMap)
+	if !ok {
+		val, err := ValidatePercentageMissing(v)
+		if err != nil {
+			return 0, errors.Wrap(err, key)
+		}
+		return val, nil
+	}
+	val, err := Percentage(inter, v)
+	if err != nil {
+		return 0, errors.Wrap(err, key)
+	}
+	return val, nil
+}
+
+func PercentageFromStrMap(key string, sMap map[string]string, v *PercentageValidation) (float64, error) {
+	valStr, ok := sMap[key]
+	if !ok || valStr == "" {
+		val, err := ValidatePercentageMissing(v)
+		if err != nil {
+			return 0, errors.Wrap(err, key)
+		}
+		return val, nil
+	}
+	val, err := PercentageFromStr(valStr, v)
+	if err != nil {
+		return 0, errors.Wrap(err, key)
+	}
+	return val, nil
+}
+
+func PercentageFromStr(valStr string, v *PercentageValidation) (float64, error) {
+	if valStr == "" {
+		return ValidatePercentageMissing(v)
+	}
+	casted, ok := s.ParseFloat64(strings.TrimSuffix(strings.TrimSpace(valStr), "%"))
+	if !ok {
+		return 0, ErrorInvalidPercentage(valStr)
+	}
+	return ValidatePercentage(casted, v)
+}
+
+func PercentageFromEnv(envVarName string, v *PercentageValidation) (float64, error) {
+	valStr := ReadEnvVar(envVarName)
+	if valStr == nil || *valStr == "" {
+		val, err := ValidatePercentageMissing(v)
+		if err != nil {
+			return 0, errors.Wrap(err, EnvVar(envVarName))
+		}
+		return val, nil
+	}
+	val, err := PercentageFromStr(*valStr, v)
+	if err != nil {
+		return 0, errors.Wrap(err, EnvVar(envVarName))
+	}
+	return val, nil
+}
+
+func PercentageFromFile(filePath string, v *PercentageValidation) (float64, error) {
+	valBytes, err := ioutil.ReadFile(filePath)
+	if err != nil || len(valBytes) == 0 {
+		val, err := ValidatePercentageMissing(v)
+		if err != nil {
+			return 0, errors.Wrap(err, filePath)
+		}
+		return val, nil
+	}
+	valStr := string(valBytes)
+	val, err := PercentageFromStr(valStr, v)
+	if err != nil {
+		return 0, errors.Wrap(err, filePath)
+	}
+	return val, nil
+}
+
+func PercentageFromEnvOrFile(envVarName string, filePath string, v *PercentageValidation) (float64, error) {
+	valStr := ReadEnvVar(envVarName)
+	if valStr != nil && *valStr != "" {
+		return PercentageFromEnv(envVarName, v)
+	}
+	return PercentageFromFile(filePath, v)
+}
+
+func ValidatePercentageMissing(v *PercentageValidation) (float64, error) {
+	if v.Required {
+		return 0, ErrorMustBeDefined()
+	}
+	return ValidatePercentage(v.Default, v)
+}
+
+func ValidatePercentage(val float64, v *PercentageValidation) (float64, error) {
+	err := ValidatePercentageVal(val, v)
+	if err != nil {
+		return 0, err
+	}
+
+	if v.Validator != nil {
+		return v.Validator(val)
+	}
+	return val, nil
+}
+
+func ValidatePercentageVal(val float64, v *PercentageValidation) error {
+	if v.GreaterThan != nil {
+		if val <= *v.GreaterThan {
+			return ErrorMustBeGreaterThan(val, *v.GreaterThan)
+		}
+	}
+	if v.GreaterThanOrEqualTo != nil {
+		if val < *v.GreaterThanOrEqualTo {
+			return ErrorMustBeGreaterThanOrEqualTo(val, *v.GreaterThanOrEqualTo)
+		}
+	}
+	if v.LessThan != nil {
+		if val >= *v.LessThan {
+			return ErrorMustBeLessThan(val, *v.LessThan)
+		}
+	}
+	if v.LessThanOrEqualTo != nil {
+		if val > *v.LessThanOrEqualTo {
+			return ErrorMustBeLessThanOrEqualTo(val, *v.LessThanOrEqualTo)
+		}
+	}
+
+	return nil
+}
+
+//
+// Musts
+//
+
+func MustPercentageFromEnv(envVarName string, v *PercentageValidation) float64 {
+	val, err := PercentageFromEnv(envVarName, v)
+	if err != nil {
+		exit.Panic(err)
+	}
+	return val
+}
+
+func MustPercentageFromFile(filePath string, v *PercentageValidation) float64 {
+	val, err := PercentageFromFile(filePath, v)
+	if err != nil {
+		exit.Panic(err)
+	}
+	return val
+}
+
+func MustPercentageFromEnvOrFile(envVarName string, filePath string, v *PercentageValidation) float64 {
+	val, err := PercentageFromEnvOrFile(envVarName, filePath, v)
+	if err != nil {
+		exit.Panic(err)
+	}
+	return val
+}
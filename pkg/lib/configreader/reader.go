@@ -42,6 +42,9 @@ type StructFieldValidation struct {
 	StructField      string                        // Required
 	DefaultField     string                        // Optional. Will set the default to the runtime value of this field
 	DefaultFieldFunc func(interface{}) interface{} // Optional. Will call the func with the value of DefaultField
+	Deprecated       string                        // Optional. If set, a warning (with this message, e.g. what to use instead) is returned when the key is present
+	RequiredIf       *FieldCondition               // Optional. The key must be defined if the condition holds
+	AllowedIf        *FieldCondition               // Optional. The key may only be defined if the condition holds
 
 	// Provide one of the following:
 	StringValidation              *StringValidation
@@ -65,6 +68,9 @@ type StructFieldValidation struct {
 	Float64Validation             *Float64Validation
 	Float64PtrValidation          *Float64PtrValidation
 	Float64ListValidation         *Float64ListValidation
+	DurationValidation            *DurationValidation
+	ByteQuantityValidation        *ByteQuantityValidation
+	PercentageValidation          *PercentageValidation
 	StringMapValidation           *StringMapValidation
 	InterfaceMapValidation        *InterfaceMapValidation
 	InterfaceMapListValidation    *InterfaceMapListValidation
@@ -79,6 +85,13 @@ type StructFieldValidation struct {
 	Parser func(string) (interface{}, error)
 }
 
+// FieldCondition holds when the parsed value of the sibling field with key Key is one of Values (or none of Values if Not is set)
+type FieldCondition struct {
+	Key    string
+	Values []interface{}
+	Not    bool
+}
+
 type StructValidation struct {
 	StructFieldValidations []*StructFieldValidation
 	Required               bool
@@ -86,7 +99,7 @@ type StructValidation struct {
 	TreatNullAsEmpty       bool // If explicit null or if it's top level and the file is empty, treat as empty map
 	DefaultNil             bool // If this struct is nested and its key is not defined, set it to nil instead of defaults or erroring (e.g. if any subfields are required)
 	ShortCircuit           bool
-	AllowExtraFields       bool
+	AllowExtraFields       bool // Extra fields are ignored (and reported as warnings by StructWithWarnings)
 }
 
 type StructListValidation struct {
@@ -124,6 +137,19 @@ type InterfaceStructListValidation struct {
 }
 
 func Struct(dest interface{}, inter interface{}, v *StructValidation) []error {
+	return readStruct(dest, inter, v, nil)
+}
+
+// StructWithWarnings is like Struct, but also returns warnings for the parsed config
+// (e.g. ignored extra fields and deprecated keys) which don't prevent it from being used
+func StructWithWarnings(dest interface{}, inter interface{}, v *StructValidation) ([]error, []error) {
+	warnings := []error{}
+	errs := readStruct(dest, inter, v, &warnings)
+	return errs, warnings
+}
+
+// warnings may be nil, in which case warnings are discarded
+func readStruct(dest interface{}, inter interface{}, v *StructValidation, warnings *[]error) []error {
 	allowedFields := []string{}
 	allErrs := []error{}
 	var ok bool
@@ -152,6 +178,14 @@ func Struct(dest interface{}, inter interface{}, v *StructValidation) []error {
 			continue
 		}
 
+		if structFieldValidation.Deprecated != "" {
+			if _, ok := interMap[key]; ok {
+				addWarnings(warnings, []error{ErrorDeprecatedKey(key, structFieldValidation.Deprecated)})
+			}
+		}
+
+		var nestedWarnings []error
+
 		var err error
 		var errs []error
 		var val interface{}
@@ -260,6 +294,18 @@ func Struct(dest interface{}, inter interface{}, v *StructValidation) []error {
 			validation := *structFieldValidation.Float64ListValidation
 			updateValidation(&validation, dest, structFieldValidation)
 			val, err = Float64ListFromInterfaceMap(key, interMap, &validation)
+		} else if structFieldValidation.DurationValidation != nil {
+			validation := *structFieldValidation.DurationValidation
+			updateValidation(&validation, dest, structFieldValidation)
+			val, err = DurationFromInterfaceMap(key, interMap, &validation)
+		} else if structFieldValidation.ByteQuantityValidation != nil {
+			validation := *structFieldValidation.ByteQuantityValidation
+			updateValidation(&validation, dest, structFieldValidation)
+			val, err = ByteQuantityFromInterfaceMap(key, interMap, &validation)
+		} else if structFieldValidation.PercentageValidation != nil {
+			validation := *structFieldValidation.PercentageValidation
+			updateValidation(&validation, dest, structFieldValidation)
+			val, err = PercentageFromInterfaceMap(key, interMap, &validation)
 		} else if structFieldValidation.StringMapValidation != nil {
 			validation := *structFieldValidation.StringMapValidation
 			updateValidation(&validation, dest, structFieldValidation)
@@ -290,7 +336,7 @@ func Struct(dest interface{}, inter interface{}, v *StructValidation) []error {
 					interMapVal = make(map[string]interface{}) // Here validation.DefaultNil == false, so create an empty map to hold the nested default values
 				}
 				val = reflect.New(nestedType.Elem()).Interface()
-				errs = Struct(val, interMapVal, &validation)
+				errs = readStruct(val, interMapVal, &validation, &nestedWarnings)
 				if interMapVal == nil {
 					val = nil // If the object was nil, set val to nil rather than a pointer to the initialized zero value
 				}
@@ -306,7 +352,7 @@ func Struct(dest interface{}, inter interface{}, v *StructValidation) []error {
 				err = errors.Wrap(ErrorMustBeDefined(), key)
 			} else {
 				val = reflect.Indirect(reflect.New(nestedType)).Interface()
-				val, errs = StructList(val, interMapVal, &validation)
+				val, errs = readStructList(val, interMapVal, &validation, &nestedWarnings)
 				errs = errors.WrapAll(errs, key)
 			}
 
@@ -317,7 +363,7 @@ func Struct(dest interface{}, inter interface{}, v *StructValidation) []error {
 			if !ok && validation.Required {
 				err = errors.Wrap(ErrorMustBeDefined(), key)
 			} else {
-				val, errs = InterfaceStruct(interMapVal, &validation)
+				val, errs = readInterfaceStruct(interMapVal, &validation, &nestedWarnings)
 				errs = errors.WrapAll(errs, key)
 			}
 
@@ -330,7 +376,7 @@ func Struct(dest interface{}, inter interface{}, v *StructValidation) []error {
 				err = errors.Wrap(ErrorMustBeDefined(), key)
 			} else {
 				val = reflect.Indirect(reflect.New(nestedType)).Interface()
-				val, errs = InterfaceStructList(val, interMapVal, &validation)
+				val, errs = readInterfaceStructList(val, interMapVal, &validation, &nestedWarnings)
 				errs = errors.WrapAll(errs, key)
 			}
 
@@ -338,6 +384,8 @@ func Struct(dest interface{}, inter interface{}, v *StructValidation) []error {
 			exit.Panic("Undefined or unsupported validation type")
 		}
 
+		addWarnings(warnings, nestedWarnings, key)
+
 		allErrs, _ = errors.AddError(allErrs, err)
 		allErrs, _ = errors.AddErrors(allErrs, errs)
 		if errors.HasErrors(allErrs) {
@@ -359,9 +407,15 @@ func Struct(dest interface{}, inter interface{}, v *StructValidation) []error {
 		}
 	}
 
-	if !v.AllowExtraFields {
-		extraFields := slices.SubtractStrSlice(maps.InterfaceMapKeys(interMap), allowedFields)
-		for _, extraField := range extraFields {
+	if !errors.HasErrors(allErrs) {
+		allErrs = validateFieldConditions(dest, interMap, v)
+	}
+
+	extraFields := slices.SubtractStrSlice(maps.InterfaceMapKeys(interMap), allowedFields)
+	for _, extraField := range extraFields {
+		if v.AllowExtraFields {
+			addWarnings(warnings, []error{ErrorIgnoredKey(extraField)})
+		} else {
 			allErrs = append(allErrs, ErrorUnsupportedKey(extraField))
 		}
 	}
@@ -371,7 +425,69 @@ func Struct(dest interface{}, inter interface{}, v *StructValidation) []error {
 	return nil
 }
 
+// validateFieldConditions checks the RequiredIf and AllowedIf conditions, after all fields have been parsed into dest
+func validateFieldConditions(dest interface{}, interMap map[string]interface{}, v *StructValidation) []error {
+	structFields := map[string]string{}
+	for _, structFieldValidation := range v.StructFieldValidations {
+		key := inferKey(reflect.TypeOf(dest), structFieldValidation.StructField, structFieldValidation.Key)
+		structFields[key] = structFieldValidation.StructField
+	}
+
+	var errs []error
+	for _, structFieldValidation := range v.StructFieldValidations {
+		if structFieldValidation.RequiredIf == nil && structFieldValidation.AllowedIf == nil {
+			continue
+		}
+
+		key := inferKey(reflect.TypeOf(dest), structFieldValidation.StructField, structFieldValidation.Key)
+		isDefined := interMap[key] != nil
+
+		if cond := structFieldValidation.RequiredIf; cond != nil && !isDefined {
+			if holds, condVal := fieldConditionHolds(dest, structFields, cond); holds {
+				errs = append(errs, errors.Wrap(ErrorMustBeDefinedWhen(cond.Key, condVal), key))
+			}
+		}
+
+		if cond := structFieldValidation.AllowedIf; cond != nil && isDefined {
+			if holds, condVal := fieldConditionHolds(dest, structFields, cond); !holds {
+				errs = append(errs, errors.Wrap(ErrorCannotBeDefinedWhen(cond.Key, condVal), key))
+			}
+		}
+	}
+
+	return errs
+}
+
+func fieldConditionHolds(dest interface{}, structFields map[string]string, cond *FieldCondition) (bool, interface{}) {
+	structField, ok := structFields[cond.Key]
+	if !ok {
+		exit.Panic(fmt.Sprintf("field condition references unknown key %s", s.UserStr(cond.Key)))
+	}
+
+	condVal := reflect.ValueOf(dest).Elem().FieldByName(structField).Interface()
+	isMatch := false
+	for _, value := range cond.Values {
+		if reflect.DeepEqual(condVal, value) {
+			isMatch = true
+			break
+		}
+	}
+
+	return isMatch != cond.Not, condVal
+}
+
+func addWarnings(warnings *[]error, newWarnings []error, strs ...string) {
+	if warnings == nil || len(newWarnings) == 0 {
+		return
+	}
+	*warnings = append(*warnings, errors.WrapAll(newWarnings, strs...)...)
+}
+
 func StructList(dest interface{}, inter interface{}, v *StructListValidation) (interface{}, []error) {
+	return readStructList(dest, inter, v, nil)
+}
+
+func readStructList(dest interface{}, inter interface{}, v *StructListValidation, warnings *[]error) (interface{}, []error) {
 	if inter == nil {
 		if v.TreatNullAsEmpty {
 			inter = make([]interface{}, 0)
@@ -391,7 +507,9 @@ func StructList(dest interface{}, inter interface{}, v *StructListValidation) (i
 	errs := []error{}
 	for i, interItem := range interSlice {
 		val := reflect.New(reflect.ValueOf(dest).Type().Elem().Elem()).Interface()
-		subErrs := Struct(val, interItem, v.StructValidation)
+		var itemWarnings []error
+		subErrs := readStruct(val, interItem, v.StructValidation, &itemWarnings)
+		addWarnings(warnings, itemWarnings, s.Index(i))
 		var ok bool
 		if errs, ok = errors.AddErrors(errs, subErrs, s.Index(i)); ok {
 			if v.ShortCircuit {
@@ -409,6 +527,10 @@ func StructList(dest interface{}, inter interface{}, v *StructListValidation) (i
 }
 
 func InterfaceStruct(inter interface{}, v *InterfaceStructValidation) (interface{}, []error) {
+	return readInterfaceStruct(inter, v, nil)
+}
+
+func readInterfaceStruct(inter interface{}, v *InterfaceStructValidation, warnings *[]error) (interface{}, []error) {
 	if inter == nil {
 		if v.TreatNullAsEmpty {
 			inter = make(map[interface{}]interface{}, 0)
@@ -487,11 +609,15 @@ func InterfaceStruct(inter interface{}, v *InterfaceStructValidation) (interface
 		ShortCircuit:           v.ShortCircuit,
 		AllowExtraFields:       v.AllowExtraFields,
 	}
-	errs := Struct(val, inter, structValidation)
+	errs := readStruct(val, inter, structValidation, warnings)
 	return val, errs
 }
 
 func InterfaceStructList(dest interface{}, inter interface{}, v *InterfaceStructListValidation) (interface{}, []error) {
+	return readInterfaceStructList(dest, inter, v, nil)
+}
+
+func readInterfaceStructList(dest interface{}, inter interface{}, v *InterfaceStructListValidation, warnings *[]error) (interface{}, []error) {
 	if inter == nil {
 		if v.TreatNullAsEmpty {
 			inter = make([]interface{}, 0)
@@ -510,7 +636,9 @@ func InterfaceStructList(dest interface{}, inter interface{}, v *InterfaceStruct
 
 	errs := []error{}
 	for i, interItem := range interSlice {
-		val, subErrs := InterfaceStruct(interItem, v.InterfaceStructValidation)
+		var itemWarnings []error
+		val, subErrs := readInterfaceStruct(interItem, v.InterfaceStructValidation, &itemWarnings)
+		addWarnings(warnings, itemWarnings, s.Index(i))
 		var ok bool
 		if errs, ok = errors.AddErrors(errs, subErrs, s.Index(i)); ok {
 			if v.ShortCircuit {
@@ -802,6 +930,30 @@ func StructFromStringMap(dest interface{}, strMap map[string]string, v *StructVa
 			} else {
 				val, err = ValidateFloat64PtrMissing(&validation)
 			}
+		} else if structFieldValidation.DurationValidation != nil {
+			validation := *structFieldValidation.DurationValidation
+			updateValidation(&validation, dest, structFieldValidation)
+			if keyExists {
+				val, err = DurationFromStr(strMapVal, &validation)
+			} else {
+				val, err = ValidateDurationMissing(&validation)
+			}
+		} else if structFieldValidation.ByteQuantityValidation != nil {
+			validation := *structFieldValidation.ByteQuantityValidation
+			updateValidation(&validation, dest, structFieldValidation)
+			if keyExists {
+				val, err = ByteQuantityFromStr(strMapVal, &validation)
+			} else {
+				val, err = ValidateByteQuantityMissing(&validation)
+			}
+		} else if structFieldValidation.PercentageValidation != nil {
+			validation := *structFieldValidation.PercentageValidation
+			updateValidation(&validation, dest, structFieldValidation)
+			if keyExists {
+				val, err = PercentageFromStr(strMapVal, &validation)
+			} else {
+				val, err = ValidatePercentageMissing(&validation)
+			}
 		} else {
 			exit.Panic("Undefined or unsupported validation type")
 		}
@@ -20,8 +20,12 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 )
 
 type SimpleConfig struct {
@@ -1038,3 +1042,226 @@ func testConfigError(structValidation *StructValidation, configData interface{},
 	errs := Struct(config, configData, structValidation)
 	require.NotEmpty(t, errs)
 }
+
+func TestStructWithWarnings(t *testing.T) {
+	structValidation := &StructValidation{
+		StructFieldValidations: []*StructFieldValidation{
+			{
+				StructField: "Key21",
+				StringValidation: &StringValidation{
+					AllowEmpty: true,
+				},
+				Deprecated: "use key22 instead",
+			},
+			{
+				StructField: "Key22",
+				StructValidation: &StructValidation{
+					DefaultNil:       true,
+					AllowExtraFields: true,
+					StructFieldValidations: []*StructFieldValidation{
+						{
+							StructField:   "Key31",
+							IntValidation: &IntValidation{},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	configData := MustReadYAMLStr(
+		`
+    key21: test
+    key22:
+      key31: 1
+      key32: 2
+    `)
+
+	config := &Nested2{}
+	errs, warnings := StructWithWarnings(config, configData, structValidation)
+	require.Empty(t, errs)
+	require.Equal(t, "test", config.Key21)
+	require.Equal(t, 1, config.Key22.Key31)
+	require.Len(t, warnings, 2)
+	require.Equal(t, ErrDeprecatedKey, errors.Cause(warnings[0]).(Error).Kind)
+	require.Equal(t, ErrIgnoredKey, errors.Cause(warnings[1]).(Error).Kind)
+	require.Contains(t, warnings[1].Error(), "key22")
+	require.Contains(t, warnings[1].Error(), "key32")
+
+	configData = MustReadYAMLStr(
+		`
+    key22:
+      key31: 1
+    `)
+
+	errs, warnings = StructWithWarnings(&Nested2{}, configData, structValidation)
+	require.Empty(t, errs)
+	require.Empty(t, warnings)
+
+	// Struct() discards warnings
+	configData = MustReadYAMLStr(
+		`
+    key21: test
+    key22:
+      key32: 2
+    `)
+	errs = Struct(&Nested2{}, configData, structValidation)
+	require.Empty(t, errs)
+}
+
+type QuantitiesConfig struct {
+	Timeout   time.Duration `json:"timeout"`
+	Window    time.Duration `json:"window"`
+	Size      int64         `json:"size"`
+	Threshold float64       `json:"threshold"`
+}
+
+func TestQuantities(t *testing.T) {
+	structValidation := &StructValidation{
+		StructFieldValidations: []*StructFieldValidation{
+			{
+				StructField: "Timeout",
+				DurationValidation: &DurationValidation{
+					Default:     30 * time.Second,
+					GreaterThan: pointer.Duration(0),
+				},
+			},
+			{
+				StructField: "Window",
+				DurationValidation: &DurationValidation{
+					LessThanOrEqualTo: pointer.Duration(time.Hour),
+				},
+			},
+			{
+				StructField: "Size",
+				ByteQuantityValidation: &ByteQuantityValidation{
+					Default:     1024,
+					GreaterThan: pointer.Int64(0),
+				},
+			},
+			{
+				StructField: "Threshold",
+				PercentageValidation: &PercentageValidation{
+					Default:           50,
+					LessThanOrEqualTo: pointer.Float64(100),
+				},
+			},
+		},
+	}
+
+	configData := MustReadYAMLStr(
+		`
+    window: 1m30s
+    `)
+	expected := &QuantitiesConfig{
+		Timeout:   30 * time.Second,
+		Window:    90 * time.Second,
+		Size:      1024,
+		Threshold: 50,
+	}
+	testConfig(structValidation, configData, expected, t)
+
+	configData = MustReadYAMLStr(
+		`
+    timeout: 500ms
+    size: 512Mi
+    threshold: 75%
+    `)
+	expected = &QuantitiesConfig{
+		Timeout:   500 * time.Millisecond,
+		Size:      512 * 1024 * 1024,
+		Threshold: 75,
+	}
+	testConfig(structValidation, configData, expected, t)
+
+	configData = MustReadYAMLStr(
+		`
+    size: 1000
+    threshold: 12.5
+    `)
+	expected = &QuantitiesConfig{
+		Timeout:   30 * time.Second,
+		Size:      1000,
+		Threshold: 12.5,
+	}
+	testConfig(structValidation, configData, expected, t)
+
+	for _, invalidConfig := range []string{
+		"timeout: 30",
+		"timeout: 0s",
+		"window: 2h",
+		"size: 1.5.5Gi",
+		"size: 0",
+		"threshold: 150%",
+		"threshold: seventy",
+	} {
+		errs := Struct(&QuantitiesConfig{}, MustReadYAMLStr(invalidConfig), structValidation)
+		require.NotEmpty(t, errs, invalidConfig)
+	}
+}
+
+type ConditionalConfig struct {
+	Type  string  `json:"type"`
+	Model *string `json:"model"`
+	Key   *string `json:"key"`
+}
+
+func TestFieldConditions(t *testing.T) {
+	structValidation := &StructValidation{
+		StructFieldValidations: []*StructFieldValidation{
+			{
+				StructField: "Type",
+				StringValidation: &StringValidation{
+					Default:       "python",
+					AllowedValues: []string{"python", "tensorflow", "onnx"},
+				},
+			},
+			{
+				StructField:         "Model",
+				StringPtrValidation: &StringPtrValidation{},
+				RequiredIf:          &FieldCondition{Key: "type", Values: []interface{}{"python"}, Not: true},
+				AllowedIf:           &FieldCondition{Key: "type", Values: []interface{}{"python"}, Not: true},
+			},
+			{
+				StructField:         "Key",
+				StringPtrValidation: &StringPtrValidation{},
+				AllowedIf:           &FieldCondition{Key: "type", Values: []interface{}{"tensorflow"}},
+			},
+		},
+	}
+
+	configData := MustReadYAMLStr(
+		`
+    type: tensorflow
+    model: s3://bucket/model
+    key: serving_default
+    `)
+	expected := &ConditionalConfig{
+		Type:  "tensorflow",
+		Model: pointer.String("s3://bucket/model"),
+		Key:   pointer.String("serving_default"),
+	}
+	testConfig(structValidation, configData, expected, t)
+
+	configData = MustReadYAMLStr(`type: python`)
+	expected = &ConditionalConfig{
+		Type: "python",
+	}
+	testConfig(structValidation, configData, expected, t)
+
+	configData = MustReadYAMLStr(`type: onnx`)
+	errs := Struct(&ConditionalConfig{}, configData, structValidation)
+	require.Len(t, errs, 1)
+	require.Equal(t, ErrMustBeDefinedWhen, errors.Cause(errs[0]).(Error).Kind)
+	require.Equal(t, `model: must be defined when type is "onnx"`, errs[0].Error())
+
+	configData = MustReadYAMLStr(
+		`
+    model: s3://bucket/model
+    key: serving_default
+    `)
+	errs = Struct(&ConditionalConfig{}, configData, structValidation)
+	require.Len(t, errs, 2)
+	require.Equal(t, `model: cannot be defined when type is "python"`, errs[0].Error())
+	require.Equal(t, `key: cannot be defined when type is "python"`, errs[1].Error())
+}
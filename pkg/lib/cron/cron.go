@@ -0,0 +1,172 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression with the standard 5 fields (minute, hour, day of month, month, and day of week)
+type Schedule struct {
+	expr        string
+	minutes     uint64
+	hours       uint64
+	daysOfMonth uint64
+	months      uint64
+	daysOfWeek  uint64
+	domStar     bool
+	monthStar   bool
+	dowStar     bool
+}
+
+type fieldBounds struct {
+	name string
+	min  int
+	max  int
+}
+
+var (
+	minuteBounds     = fieldBounds{"minute", 0, 59}
+	hourBounds       = fieldBounds{"hour", 0, 23}
+	dayOfMonthBounds = fieldBounds{"day of month", 1, 31}
+	monthBounds      = fieldBounds{"month", 1, 12}
+	dayOfWeekBounds  = fieldBounds{"day of week", 0, 7} // 0 and 7 are both Sunday
+)
+
+func Parse(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, ErrorInvalidExpression(expr)
+	}
+
+	schedule := &Schedule{
+		expr:      expr,
+		domStar:   fields[2] == "*",
+		monthStar: fields[3] == "*",
+		dowStar:   fields[4] == "*",
+	}
+
+	var err error
+	if schedule.minutes, err = parseField(fields[0], minuteBounds); err != nil {
+		return nil, err
+	}
+	if schedule.hours, err = parseField(fields[1], hourBounds); err != nil {
+		return nil, err
+	}
+	if schedule.daysOfMonth, err = parseField(fields[2], dayOfMonthBounds); err != nil {
+		return nil, err
+	}
+	if schedule.months, err = parseField(fields[3], monthBounds); err != nil {
+		return nil, err
+	}
+	if schedule.daysOfWeek, err = parseField(fields[4], dayOfWeekBounds); err != nil {
+		return nil, err
+	}
+
+	if schedule.daysOfWeek&(1<<7) != 0 {
+		schedule.daysOfWeek |= 1 << 0
+	}
+
+	return schedule, nil
+}
+
+func parseField(field string, bounds fieldBounds) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangeStr := part
+		step := 1
+
+		if slashIndex := strings.Index(part, "/"); slashIndex != -1 {
+			rangeStr = part[:slashIndex]
+			var err error
+			step, err = strconv.Atoi(part[slashIndex+1:])
+			if err != nil || step <= 0 {
+				return 0, ErrorInvalidField(bounds.name, field, bounds.min, bounds.max)
+			}
+		}
+
+		start, end := bounds.min, bounds.max
+		if rangeStr != "*" {
+			split := strings.SplitN(rangeStr, "-", 2)
+			var err error
+			start, err = strconv.Atoi(split[0])
+			if err != nil {
+				return 0, ErrorInvalidField(bounds.name, field, bounds.min, bounds.max)
+			}
+			end = start
+			if len(split) == 2 {
+				end, err = strconv.Atoi(split[1])
+				if err != nil {
+					return 0, ErrorInvalidField(bounds.name, field, bounds.min, bounds.max)
+				}
+			} else if step != 1 {
+				// e.g. "5/15" means every 15, starting at 5
+				end = bounds.max
+			}
+		}
+
+		if start < bounds.min || end > bounds.max || start > end {
+			return 0, ErrorInvalidField(bounds.name, field, bounds.min, bounds.max)
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// IsWeekly is true if the schedule only depends on the time of day and the day of week (i.e. it repeats every week)
+func (s *Schedule) IsWeekly() bool {
+	return s.domStar && s.monthStar
+}
+
+// Matches returns whether the schedule fires during the minute which contains t (in t's location)
+func (s *Schedule) Matches(t time.Time) bool {
+	if s.minutes&(1<<uint(t.Minute())) == 0 || s.hours&(1<<uint(t.Hour())) == 0 || s.months&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	domMatch := s.daysOfMonth&(1<<uint(t.Day())) != 0
+	dowMatch := s.daysOfWeek&(1<<uint(t.Weekday())) != 0
+
+	// As in standard cron, if both the day of month and day of week are restricted, either may match
+	if !s.domStar && !s.dowStar {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// MostRecent returns the latest time (truncated to the minute) at or before t at which the schedule fired,
+// as long as it is less than `within` before t
+func (s *Schedule) MostRecent(t time.Time, within time.Duration) (time.Time, bool) {
+	earliest := t.Add(-within)
+	for cur := t.Truncate(time.Minute); cur.After(earliest); cur = cur.Add(-time.Minute) {
+		if s.Matches(cur) {
+			return cur, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	_, err := Parse("0 9 * * 1-5")
+	require.NoError(t, err)
+	_, err = Parse("*/15 0-6,18-23 1 */2 0,7")
+	require.NoError(t, err)
+	_, err = Parse("5/10 * * * *")
+	require.NoError(t, err)
+
+	_, err = Parse("0 9 * *")
+	require.Error(t, err)
+	_, err = Parse("60 9 * * *")
+	require.Error(t, err)
+	_, err = Parse("0 9 0 * *")
+	require.Error(t, err)
+	_, err = Parse("0 17-9 * * *")
+	require.Error(t, err)
+	_, err = Parse("*/0 * * * *")
+	require.Error(t, err)
+	_, err = Parse("0 9 * * mon")
+	require.Error(t, err)
+}
+
+func TestMatches(t *testing.T) {
+	monday9am := time.Date(2020, time.January, 6, 9, 0, 30, 0, time.UTC)
+
+	schedule, err := Parse("0 9 * * 1-5")
+	require.NoError(t, err)
+	require.True(t, schedule.IsWeekly())
+	require.True(t, schedule.Matches(monday9am))
+	require.False(t, schedule.Matches(monday9am.Add(time.Minute)))
+	require.False(t, schedule.Matches(monday9am.AddDate(0, 0, -1)))
+
+	sunday, err := Parse("0 9 * * 7")
+	require.NoError(t, err)
+	require.True(t, sunday.Matches(monday9am.AddDate(0, 0, -1)))
+
+	// day of month and day of week are OR'd when both are restricted
+	firstOrMonday, err := Parse("0 9 1 * 1")
+	require.NoError(t, err)
+	require.False(t, firstOrMonday.IsWeekly())
+	require.True(t, firstOrMonday.Matches(monday9am))
+	require.True(t, firstOrMonday.Matches(time.Date(2020, time.January, 1, 9, 0, 0, 0, time.UTC)))
+	require.False(t, firstOrMonday.Matches(time.Date(2020, time.January, 2, 9, 0, 0, 0, time.UTC)))
+}
+
+func TestMostRecent(t *testing.T) {
+	schedule, err := Parse("0 9 * * 1-5")
+	require.NoError(t, err)
+
+	monday5pm := time.Date(2020, time.January, 6, 17, 0, 0, 0, time.UTC)
+	mostRecent, ok := schedule.MostRecent(monday5pm, 8*time.Hour+time.Minute)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2020, time.January, 6, 9, 0, 0, 0, time.UTC), mostRecent)
+
+	_, ok = schedule.MostRecent(monday5pm, 8*time.Hour)
+	require.False(t, ok)
+
+	saturday, ok := schedule.MostRecent(time.Date(2020, time.January, 11, 12, 0, 0, 0, time.UTC), 48*time.Hour)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2020, time.January, 10, 9, 0, 0, 0, time.UTC), saturday)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cron
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+type ErrorKind int
+
+const (
+	ErrUnknown ErrorKind = iota
+	ErrInvalidExpression
+	ErrInvalidField
+)
+
+var errorKinds = []string{
+	"err_unknown",
+	"err_invalid_expression",
+	"err_invalid_field",
+}
+
+var _ = [1]int{}[int(ErrInvalidField)-(len(errorKinds)-1)] // Ensure list length matches
+
+func (t ErrorKind) String() string {
+	return errorKinds[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t ErrorKind) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *ErrorKind) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(errorKinds); i++ {
+		if enum == errorKinds[i] {
+			*t = ErrorKind(i)
+			return nil
+		}
+	}
+
+	*t = ErrUnknown
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *ErrorKind) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t ErrorKind) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+type Error struct {
+	Kind    ErrorKind
+	message string
+}
+
+func (e Error) Error() string {
+	return e.message
+}
+
+func ErrorInvalidExpression(expr string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidExpression,
+		message: fmt.Sprintf("%s is not a valid cron expression (expected 5 space-separated fields: minute, hour, day of month, month, and day of week, e.g. \"0 9 * * 1-5\")", s.UserStr(expr)),
+	})
+}
+
+func ErrorInvalidField(fieldName string, field string, min int, max int) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidField,
+		message: fmt.Sprintf("%s is not a valid %s field (values must be between %d and %d, and may be combined using *, commas, ranges, and steps, e.g. \"*/15\" or \"1-5\")", s.UserStr(field), fieldName, min, max),
+	})
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distribution
+
+import (
+	"math"
+	"sort"
+)
+
+// MaxSamples is the maximum number of numeric samples which are kept when distributions are merged
+const MaxSamples = 10000
+
+// The proportion which is used for bins which are empty in one of the distributions (so that PSI is finite)
+const _epsilon = 0.0001
+
+// The number of quantile bins which numeric values are compared in
+const _numBins = 10
+
+// Distribution summarizes observed values: numeric values are represented by a uniform sample, and all other values are counted
+type Distribution struct {
+	Samples      []float64        `json:"samples"`       // a uniform sample of the numeric values
+	NumericCount int64            `json:"numeric_count"` // the number of numeric values (including the ones which weren't sampled)
+	Categories   map[string]int64 `json:"categories"`    // the number of times each non-numeric value was observed
+}
+
+func (dist *Distribution) Count() int64 {
+	if dist == nil {
+		return 0
+	}
+	count := dist.NumericCount
+	for _, categoryCount := range dist.Categories {
+		count += categoryCount
+	}
+	return count
+}
+
+// Merge combines distributions; if there are more than MaxSamples samples in total, each distribution's samples are thinned in proportion to its numeric count
+func Merge(dists ...*Distribution) *Distribution {
+	merged := &Distribution{Categories: map[string]int64{}}
+
+	numSamples := 0
+	for _, dist := range dists {
+		if dist == nil {
+			continue
+		}
+		merged.NumericCount += dist.NumericCount
+		numSamples += len(dist.Samples)
+		for category, count := range dist.Categories {
+			merged.Categories[category] += count
+		}
+	}
+
+	for _, dist := range dists {
+		if dist == nil || len(dist.Samples) == 0 {
+			continue
+		}
+		if numSamples <= MaxSamples {
+			merged.Samples = append(merged.Samples, dist.Samples...)
+			continue
+		}
+		keep := int(float64(MaxSamples) * float64(dist.NumericCount) / float64(merged.NumericCount))
+		merged.Samples = append(merged.Samples, thin(dist.Samples, keep)...)
+	}
+
+	return merged
+}
+
+// thin returns n evenly spaced elements of samples (the samples are uniformly sampled, so their order is arbitrary)
+func thin(samples []float64, n int) []float64 {
+	if n >= len(samples) {
+		return samples
+	}
+	thinned := make([]float64, n)
+	for i := range thinned {
+		thinned[i] = samples[i*len(samples)/n]
+	}
+	return thinned
+}
+
+// PSI returns the population stability index of current relative to baseline (0 if either distribution is empty).
+// Numeric values are compared in bins whose edges are the baseline's deciles, and each category is compared as its own bin;
+// a PSI below 0.1 is generally considered insignificant, and a PSI above 0.2 is generally considered a significant shift
+func PSI(baseline *Distribution, current *Distribution) float64 {
+	baselineCount := baseline.Count()
+	currentCount := current.Count()
+	if baselineCount == 0 || currentCount == 0 {
+		return 0
+	}
+
+	var psi float64
+
+	edges := quantileEdges(baseline.Samples)
+	baselineBins := numericProportions(baseline, edges, baselineCount)
+	currentBins := numericProportions(current, edges, currentCount)
+	for i := range baselineBins {
+		psi += psiTerm(baselineBins[i], currentBins[i])
+	}
+
+	categories := map[string]bool{}
+	for category := range baseline.Categories {
+		categories[category] = true
+	}
+	for category := range current.Categories {
+		categories[category] = true
+	}
+	for category := range categories {
+		psi += psiTerm(float64(baseline.Categories[category])/float64(baselineCount), float64(current.Categories[category])/float64(currentCount))
+	}
+
+	return psi
+}
+
+func psiTerm(expected float64, actual float64) float64 {
+	expected = math.Max(expected, _epsilon)
+	actual = math.Max(actual, _epsilon)
+	return (actual - expected) * math.Log(actual/expected)
+}
+
+// quantileEdges returns the distinct inner edges of the samples' quantile bins
+func quantileEdges(samples []float64) []float64 {
+	if len(samples) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+
+	var edges []float64
+	for i := 1; i < _numBins; i++ {
+		edge := sorted[i*len(sorted)/_numBins]
+		if len(edges) == 0 || edge > edges[len(edges)-1] {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// numericProportions returns the proportion of all of the distribution's values (numeric and non-numeric) which fall in each bin;
+// there are len(edges)+1 bins, and values equal to an edge fall in the bin above it
+func numericProportions(dist *Distribution, edges []float64, totalCount int64) []float64 {
+	numericProportion := float64(dist.NumericCount) / float64(totalCount)
+	proportions := make([]float64, len(edges)+1)
+	if len(dist.Samples) == 0 {
+		proportions[0] = numericProportion
+		return proportions
+	}
+
+	for _, sample := range dist.Samples {
+		bin := sort.Search(len(edges), func(i int) bool { return edges[i] > sample })
+		proportions[bin] += numericProportion / float64(len(dist.Samples))
+	}
+	return proportions
+}
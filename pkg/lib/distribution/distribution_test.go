@@ -0,0 +1,71 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distribution
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func numericDistribution(start float64, n int) *Distribution {
+	dist := &Distribution{NumericCount: int64(n)}
+	for i := 0; i < n; i++ {
+		dist.Samples = append(dist.Samples, start+float64(i))
+	}
+	return dist
+}
+
+func TestCount(t *testing.T) {
+	require.Equal(t, int64(0), (*Distribution)(nil).Count())
+	require.Equal(t, int64(7), (&Distribution{NumericCount: 4, Categories: map[string]int64{"a": 1, "b": 2}}).Count())
+}
+
+func TestMerge(t *testing.T) {
+	merged := Merge(
+		&Distribution{Samples: []float64{1, 2}, NumericCount: 2, Categories: map[string]int64{"a": 1}},
+		nil,
+		&Distribution{Samples: []float64{3}, NumericCount: 1, Categories: map[string]int64{"a": 2, "b": 1}},
+	)
+	require.Equal(t, []float64{1, 2, 3}, merged.Samples)
+	require.Equal(t, int64(3), merged.NumericCount)
+	require.Equal(t, map[string]int64{"a": 3, "b": 1}, merged.Categories)
+
+	// samples are thinned in proportion to each distribution's numeric count
+	large := numericDistribution(0, MaxSamples)
+	large.NumericCount = 3 * MaxSamples
+	merged = Merge(large, numericDistribution(0, MaxSamples))
+	require.Len(t, merged.Samples, MaxSamples)
+	require.Equal(t, int64(4*MaxSamples), merged.NumericCount)
+}
+
+func TestPSI(t *testing.T) {
+	baseline := numericDistribution(0, 1000)
+	require.Equal(t, float64(0), PSI(baseline, &Distribution{}))
+	require.Equal(t, float64(0), PSI(&Distribution{}, baseline))
+	require.InDelta(t, 0, PSI(baseline, numericDistribution(0, 1000)), 0.0001)
+	require.InDelta(t, 0, PSI(baseline, numericDistribution(0.5, 1000)), 0.01)
+	require.Greater(t, PSI(baseline, numericDistribution(500, 1000)), 0.2)
+
+	classes := &Distribution{Categories: map[string]int64{"a": 50, "b": 50}}
+	require.InDelta(t, 0, PSI(classes, &Distribution{Categories: map[string]int64{"a": 5, "b": 5}}), 0.0001)
+	require.Greater(t, PSI(classes, &Distribution{Categories: map[string]int64{"a": 90, "b": 10}}), 0.2)
+	require.Greater(t, PSI(classes, &Distribution{Categories: map[string]int64{"a": 50, "b": 25, "c": 25}}), 0.2)
+
+	// numeric values are compared as a single bin when the baseline has none
+	require.Greater(t, PSI(classes, &Distribution{Samples: []float64{1}, NumericCount: 1, Categories: map[string]int64{"a": 1}}), 0.2)
+}
@@ -18,6 +18,7 @@ package errors
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 
 	pkgerrors "github.com/pkg/errors"
@@ -52,6 +53,33 @@ func Cause(err error) error {
 	return pkgerrors.Cause(err)
 }
 
+// Kind returns the kind of the error's cause (e.g. "err_app_not_deployed") if it has a Kind field which is a fmt.Stringer, otherwise ""
+func Kind(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	cause := reflect.ValueOf(Cause(err))
+	for cause.Kind() == reflect.Ptr || cause.Kind() == reflect.Interface {
+		if cause.IsNil() {
+			return ""
+		}
+		cause = cause.Elem()
+	}
+	if cause.Kind() != reflect.Struct {
+		return ""
+	}
+
+	kind := cause.FieldByName("Kind")
+	if !kind.IsValid() || !kind.CanInterface() {
+		return ""
+	}
+	if stringer, ok := kind.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return ""
+}
+
 func AddError(errs []error, err error, strs ...string) ([]error, bool) {
 	ok := false
 	if err != nil {
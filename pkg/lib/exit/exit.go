@@ -29,6 +29,12 @@ func Ok() {
 	os.Exit(0)
 }
 
+// Code exits with the status code (e.g. the exit code of a command which was run in an api replica)
+func Code(code int) {
+	telemetry.Close()
+	os.Exit(code)
+}
+
 func Error(err interface{}, errs ...interface{}) {
 	mergedErr := errors.MergeErrItems(append(errs, err))
 
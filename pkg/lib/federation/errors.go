@@ -0,0 +1,96 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federation
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+type ErrorKind int
+
+const (
+	ErrUnknown ErrorKind = iota
+	ErrOperatorUnreachable
+	ErrOperatorRequest
+)
+
+var errorKinds = []string{
+	"err_unknown",
+	"err_operator_unreachable",
+	"err_operator_request",
+}
+
+var _ = [1]int{}[int(ErrOperatorRequest)-(len(errorKinds)-1)] // Ensure list length matches
+
+func (t ErrorKind) String() string {
+	return errorKinds[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t ErrorKind) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *ErrorKind) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(errorKinds); i++ {
+		if enum == errorKinds[i] {
+			*t = ErrorKind(i)
+			return nil
+		}
+	}
+
+	*t = ErrUnknown
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *ErrorKind) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t ErrorKind) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+type Error struct {
+	Kind    ErrorKind
+	message string
+}
+
+func (e Error) Error() string {
+	return e.message
+}
+
+func ErrorOperatorUnreachable(clusterName string, operatorEndpoint string, err error) error {
+	return errors.WithStack(Error{
+		Kind:    ErrOperatorUnreachable,
+		message: fmt.Sprintf("unable to reach the operator of the %s cluster at %s: %s", clusterName, operatorEndpoint, err.Error()),
+	})
+}
+
+func ErrorOperatorRequest(clusterName string, status string, message string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrOperatorRequest,
+		message: fmt.Sprintf("the operator of the %s cluster responded with status %s: %s", clusterName, status, message),
+	})
+}
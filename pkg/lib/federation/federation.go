@@ -0,0 +1,142 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federation
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+)
+
+// the operators' load balancers close idle connections after 10 minutes, and deploys can take minutes to validate
+var _httpClient = &http.Client{
+	Timeout: 600 * time.Second,
+	Transport: &http.Transport{
+		// like the CLI, the operators' certificates aren't verified (the credentials aren't sent, only a presigned STS request which expires)
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	},
+}
+
+// Client calls the operator of a federated cluster, as an IAM user in the cluster's account
+type Client struct {
+	ClusterName        string
+	OperatorEndpoint   string
+	AWSAccessKeyID     string
+	AWSSecretAccessKey string
+	CortexVersion      string // the other cluster's operator rejects requests from other versions
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Get calls the endpoint (e.g. /v1/apis) and parses its response into out
+func (c *Client) Get(endpoint string, query url.Values, out interface{}) error {
+	request, err := http.NewRequest(http.MethodGet, c.url(endpoint, query), nil)
+	if err != nil {
+		return ErrorOperatorUnreachable(c.ClusterName, c.OperatorEndpoint, err)
+	}
+	return c.do(request, out)
+}
+
+// PostMultipart calls the endpoint (e.g. /v1/deploy) with the files as a multipart form, and parses its response into out
+func (c *Client) PostMultipart(endpoint string, query url.Values, files map[string][]byte, out interface{}) error {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	fileNames := make([]string, 0, len(files))
+	for fileName := range files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	for _, fileName := range fileNames {
+		part, err := writer.CreateFormFile(fileName, fileName)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if _, err := io.Copy(part, bytes.NewReader(files[fileName])); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, c.url(endpoint, query), body)
+	if err != nil {
+		return ErrorOperatorUnreachable(c.ClusterName, c.OperatorEndpoint, err)
+	}
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	return c.do(request, out)
+}
+
+func (c *Client) url(endpoint string, query url.Values) string {
+	requestURL := strings.TrimSuffix(c.OperatorEndpoint, "/") + endpoint
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+	return requestURL
+}
+
+func (c *Client) do(request *http.Request, out interface{}) error {
+	// the other cluster's operator verifies the signed request with AWS STS, so the secret access key is never sent to it
+	presignedURL, err := aws.PresignCallerIdentityRequest(c.AWSAccessKeyID, c.AWSSecretAccessKey, c.ClusterName, 5*time.Minute)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "CortexSigV4 "+presignedURL)
+	request.Header.Set("CortexAPIVersion", c.CortexVersion)
+
+	response, err := _httpClient.Do(request)
+	if err != nil {
+		return ErrorOperatorUnreachable(c.ClusterName, c.OperatorEndpoint, err)
+	}
+	defer response.Body.Close()
+
+	responseBytes, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return ErrorOperatorUnreachable(c.ClusterName, c.OperatorEndpoint, err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		var errResponse errorResponse
+		if json.Unmarshal(responseBytes, &errResponse) != nil || errResponse.Error == "" {
+			errResponse.Error = strings.TrimSpace(string(responseBytes))
+		}
+		return ErrorOperatorRequest(c.ClusterName, response.Status, errResponse.Error)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(responseBytes, out); err != nil {
+		return errors.Wrap(err, "unable to parse the response of the "+c.ClusterName+" cluster's operator")
+	}
+	return nil
+}
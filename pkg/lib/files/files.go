@@ -326,6 +326,116 @@ func IgnoreNonYAML(path string, fi os.FileInfo) (bool, error) {
 	return false, nil
 }
 
+// IgnorePatterns ignores files and directories which match any of the patterns (using .gitignore conventions):
+// a pattern which contains a "/" (other than a trailing one) is matched against the path relative to rootDir,
+// otherwise it is matched against the file or directory name; a trailing "/" only matches directories
+func IgnorePatterns(rootDir string, patterns []string) IgnoreFn {
+	rootDir = filepath.Clean(rootDir)
+
+	return func(path string, fi os.FileInfo) (bool, error) {
+		relPath := strings.TrimPrefix(strings.TrimPrefix(path, rootDir), "/")
+		if relPath == "" {
+			return false, nil // never ignore the root directory
+		}
+
+		for _, pattern := range patterns {
+			matched, err := matchesPattern(relPath, fi.IsDir(), pattern)
+			if err != nil {
+				return false, err
+			}
+			if matched {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+}
+
+// IncludePatterns ignores the files which don't match one of the patterns (or aren't in a directory which matches one)
+func IncludePatterns(rootDir string, patterns []string) IgnoreFn {
+	rootDir = filepath.Clean(rootDir)
+
+	return func(path string, fi os.FileInfo) (bool, error) {
+		if fi.IsDir() {
+			return false, nil // directories are walked so that the files in them can be matched
+		}
+		relPath := strings.TrimPrefix(strings.TrimPrefix(path, rootDir), "/")
+		matched, err := PathMatchesPatterns(relPath, patterns)
+		if err != nil {
+			return false, err
+		}
+		return !matched, nil
+	}
+}
+
+// PathMatchesPatterns returns whether the file at relPath, or one of its parent directories, matches one of the patterns
+func PathMatchesPatterns(relPath string, patterns []string) (bool, error) {
+	isDir := false
+	for path := relPath; path != "." && path != "/" && path != ""; path = filepath.Dir(path) {
+		for _, pattern := range patterns {
+			matched, err := matchesPattern(path, isDir, pattern)
+			if err != nil {
+				return false, err
+			}
+			if matched {
+				return true, nil
+			}
+		}
+		isDir = true
+	}
+	return false, nil
+}
+
+// Patterns which contain a slash are matched against the path relative to the root, and others against the file's name;
+// patterns which end with a slash only match directories
+func matchesPattern(relPath string, isDir bool, pattern string) (bool, error) {
+	if strings.HasSuffix(pattern, "/") {
+		if !isDir {
+			return false, nil
+		}
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+
+	target := filepath.Base(relPath)
+	if strings.Contains(pattern, "/") {
+		target = relPath
+		pattern = strings.TrimPrefix(pattern, "/")
+	}
+
+	matched, err := filepath.Match(pattern, target)
+	if err != nil {
+		return false, errors.Wrap(err, pattern)
+	}
+	return matched, nil
+}
+
+// ValidatePattern returns an error if the pattern isn't a valid glob
+func ValidatePattern(pattern string) error {
+	if _, err := filepath.Match(strings.Trim(pattern, "/"), ""); err != nil {
+		return errors.Wrap(err, pattern)
+	}
+	return nil
+}
+
+// ReadIgnoreFile returns the patterns in an ignore file (e.g. .cortexignore), skipping blank lines and comments
+func ReadIgnoreFile(path string) ([]string, error) {
+	contents, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, nil
+}
+
 type DirsOrder string
 
 var DirsSorted DirsOrder = "sorted"
@@ -521,3 +631,55 @@ func ReadReqFile(r *http.Request, fileName string) ([]byte, error) {
 	}
 	return fileBytes, nil
 }
+
+// ReadReqFilesWithPrefix reads all form files whose names start with prefix (keyed by file name)
+func ReadReqFilesWithPrefix(r *http.Request, prefix string) (map[string][]byte, error) {
+	if r.MultipartForm == nil {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	fileMap := map[string][]byte{}
+	for fileName := range r.MultipartForm.File {
+		if !strings.HasPrefix(fileName, prefix) {
+			continue
+		}
+		fileBytes, err := ReadReqFile(r, fileName)
+		if err != nil {
+			return nil, err
+		}
+		fileMap[fileName] = fileBytes
+	}
+	return fileMap, nil
+}
+
+// ProjectIgnoreFns returns the functions which exclude files from a project's upload, including the patterns in the project's .cortexignore file (if it exists),
+// the exclude patterns, and (if there are any) the files which don't match the include patterns
+func ProjectIgnoreFns(projectRoot string, include []string, exclude []string) ([]IgnoreFn, error) {
+	ignoreFns := []IgnoreFn{
+		IgnoreCortexYAML,
+		IgnoreCortexDebug,
+		IgnoreHiddenFiles,
+		IgnoreHiddenFolders,
+		IgnorePythonGeneratedFiles,
+	}
+
+	cortexIgnorePath := filepath.Join(projectRoot, ".cortexignore")
+	if IsFile(cortexIgnorePath) {
+		ignorePatterns, err := ReadIgnoreFile(cortexIgnorePath)
+		if err != nil {
+			return nil, err
+		}
+		ignoreFns = append(ignoreFns, IgnorePatterns(projectRoot, ignorePatterns))
+	}
+
+	if len(exclude) > 0 {
+		ignoreFns = append(ignoreFns, IgnorePatterns(projectRoot, exclude))
+	}
+	if len(include) > 0 {
+		ignoreFns = append(ignoreFns, IncludePatterns(projectRoot, include))
+	}
+
+	return ignoreFns, nil
+}
@@ -230,6 +230,49 @@ func TestListDirRecursive(t *testing.T) {
 	require.NoError(t, err)
 	require.ElementsMatch(t, expected, filesListRecursive)
 
+	filesListRecursive, err = ListDirRecursive(tmpDir, false, IgnorePatterns(tmpDir, []string{"*.txt", "/3/2/", "4/*.pyc", ".git/"}))
+	expected = []string{
+		filepath.Join(tmpDir, "2.py"),
+		filepath.Join(tmpDir, "3/1.py"),
+		filepath.Join(tmpDir, "4/1.yaml"),
+	}
+	require.NoError(t, err)
+	require.ElementsMatch(t, expected, filesListRecursive)
+
+	filesListRecursive, err = ListDirRecursive(tmpDir, false, IgnorePatterns(tmpDir, []string{"2.py/", "1.py"}))
+	expected = []string{
+		filepath.Join(tmpDir, "1.txt"),
+		filepath.Join(tmpDir, "2.py"),
+		filepath.Join(tmpDir, "3/2/2.txt"),
+		filepath.Join(tmpDir, "3/2/3/.tmp"),
+		filepath.Join(tmpDir, "4/1.yaml"),
+		filepath.Join(tmpDir, "4/2.pyc"),
+		filepath.Join(tmpDir, "4/.git/HEAD"),
+	}
+	require.NoError(t, err)
+	require.ElementsMatch(t, expected, filesListRecursive)
+
+	filesListRecursive, err = ListDirRecursive(tmpDir, false, IncludePatterns(tmpDir, []string{"*.py", "/4/"}))
+	expected = []string{
+		filepath.Join(tmpDir, "2.py"),
+		filepath.Join(tmpDir, "3/1.py"),
+		filepath.Join(tmpDir, "3/2/1.py"),
+		filepath.Join(tmpDir, "4/1.yaml"),
+		filepath.Join(tmpDir, "4/2.pyc"),
+		filepath.Join(tmpDir, "4/.git/HEAD"),
+	}
+	require.NoError(t, err)
+	require.ElementsMatch(t, expected, filesListRecursive)
+
+	filesListRecursive, err = ListDirRecursive(tmpDir, false, IncludePatterns(tmpDir, []string{"3/*"}), IgnorePatterns(tmpDir, []string{"*.txt"}))
+	expected = []string{
+		filepath.Join(tmpDir, "3/1.py"),
+		filepath.Join(tmpDir, "3/2/1.py"),
+		filepath.Join(tmpDir, "3/2/3/.tmp"),
+	}
+	require.NoError(t, err)
+	require.ElementsMatch(t, expected, filesListRecursive)
+
 	filesListRecursive, err = ListDirRecursive(tmpDir, false, IgnoreNonPython)
 	expected = []string{
 		filepath.Join(tmpDir, "2.py"),
@@ -248,3 +291,28 @@ func TestListDirRecursive(t *testing.T) {
 	require.NoError(t, err)
 	require.ElementsMatch(t, expected, filesListRecursive)
 }
+
+func TestPathMatchesPatterns(t *testing.T) {
+	matched, err := PathMatchesPatterns("data/images/1.png", []string{"data/"})
+	require.NoError(t, err)
+	require.True(t, matched)
+
+	matched, err = PathMatchesPatterns("data", []string{"data/"})
+	require.NoError(t, err)
+	require.False(t, matched)
+
+	matched, err = PathMatchesPatterns("src/data/1.csv", []string{"/data/*"})
+	require.NoError(t, err)
+	require.False(t, matched)
+
+	matched, err = PathMatchesPatterns("src/data/1.csv", []string{"*.json", "*.csv"})
+	require.NoError(t, err)
+	require.True(t, matched)
+
+	_, err = PathMatchesPatterns("1.csv", []string{"[*.csv"})
+	require.Error(t, err)
+
+	require.NoError(t, ValidatePattern("/data/**/*.csv"))
+	require.NoError(t, ValidatePattern("models/"))
+	require.Error(t, ValidatePattern("data/[a-"))
+}
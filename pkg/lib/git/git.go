@@ -0,0 +1,101 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+)
+
+type Repo struct {
+	URL    string
+	Branch string
+	SSHKey string // private key (e.g. a deploy key) used to authenticate with SSH remotes (optional)
+}
+
+// Sync clones the repo's branch into dir (or updates an existing clone), discarding any local changes, and returns the commit SHA which was checked out
+func Sync(repo *Repo, dir string) (string, error) {
+	env := os.Environ()
+	if repo.SSHKey != "" {
+		keyFile, err := ioutil.TempFile("", "git-ssh-key-")
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		defer os.Remove(keyFile.Name())
+
+		if _, err := keyFile.WriteString(strings.TrimSpace(repo.SSHKey) + "\n"); err != nil {
+			keyFile.Close()
+			return "", errors.WithStack(err)
+		}
+		if err := keyFile.Close(); err != nil {
+			return "", errors.WithStack(err)
+		}
+
+		env = append(env, "GIT_SSH_COMMAND=ssh -i "+keyFile.Name()+" -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new")
+	}
+
+	if !files.IsDir(filepath.Join(dir, ".git")) {
+		if err := os.RemoveAll(dir); err != nil {
+			return "", errors.WithStack(err)
+		}
+		if _, err := run(env, "", "clone", "--depth", "1", "--branch", repo.Branch, "--single-branch", repo.URL, dir); err != nil {
+			return "", err
+		}
+	} else {
+		if _, err := run(env, dir, "fetch", "--depth", "1", repo.URL, repo.Branch); err != nil {
+			return "", err
+		}
+		if _, err := run(env, dir, "reset", "--hard", "FETCH_HEAD"); err != nil {
+			return "", err
+		}
+		if _, err := run(env, dir, "clean", "-ffdx"); err != nil {
+			return "", err
+		}
+	}
+
+	return HeadCommit(dir)
+}
+
+// HeadCommit returns the SHA of the commit which is checked out in dir
+func HeadCommit(dir string) (string, error) {
+	output, err := run(os.Environ(), dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+func run(env []string, dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(env, "GIT_TERMINAL_PROMPT=0")
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if len(output) == 0 {
+			return "", errors.Wrap(err, ErrorGitCommand(args, "").Error())
+		}
+		return "", ErrorGitCommand(args, string(output))
+	}
+	return string(output), nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func commitFile(t *testing.T, repoDir string, name string, content string) {
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repoDir, name), []byte(content), 0644))
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", name},
+	} {
+		_, err := run(os.Environ(), repoDir, args...)
+		require.NoError(t, err)
+	}
+}
+
+func TestSync(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "git-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	remoteDir := filepath.Join(tmpDir, "remote")
+	require.NoError(t, os.Mkdir(remoteDir, 0755))
+	_, err = run(os.Environ(), remoteDir, "init", "-q")
+	require.NoError(t, err)
+	_, err = run(os.Environ(), remoteDir, "checkout", "-q", "-b", "main")
+	require.NoError(t, err)
+	commitFile(t, remoteDir, "cortex.yaml", "- kind: deployment\n  name: iris\n")
+
+	repo := &Repo{URL: "file://" + remoteDir, Branch: "main"}
+	cloneDir := filepath.Join(tmpDir, "clone")
+
+	sha, err := Sync(repo, cloneDir)
+	require.NoError(t, err)
+	remoteSHA, err := HeadCommit(remoteDir)
+	require.NoError(t, err)
+	require.Equal(t, remoteSHA, sha)
+
+	// local changes are discarded, and new commits are pulled
+	require.NoError(t, ioutil.WriteFile(filepath.Join(cloneDir, "untracked.txt"), []byte("x"), 0644))
+	commitFile(t, remoteDir, "predictor.py", "")
+
+	sha, err = Sync(repo, cloneDir)
+	require.NoError(t, err)
+	remoteSHA, err = HeadCommit(remoteDir)
+	require.NoError(t, err)
+	require.Equal(t, remoteSHA, sha)
+	require.FileExists(t, filepath.Join(cloneDir, "predictor.py"))
+	_, err = os.Stat(filepath.Join(cloneDir, "untracked.txt"))
+	require.True(t, os.IsNotExist(err))
+
+	_, err = Sync(&Repo{URL: "file://" + remoteDir, Branch: "missing"}, filepath.Join(tmpDir, "missing"))
+	require.Error(t, err)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package json
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// FieldDiff is a change to the field at Path (object keys are separated by dots, and list indices are in brackets)
+type FieldDiff struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before"` // nil if the field was added
+	After  interface{} `json:"after"`  // nil if the field was removed
+}
+
+// Diff returns the fields which differ between the JSON encodings of before and after, sorted by path
+// (fields which are null are treated as missing)
+func Diff(before interface{}, after interface{}) ([]FieldDiff, error) {
+	beforeValue, err := toJSONValue(before)
+	if err != nil {
+		return nil, err
+	}
+	afterValue, err := toJSONValue(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []FieldDiff
+	diffValues("", beforeValue, afterValue, &diffs)
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs, nil
+}
+
+func toJSONValue(obj interface{}) (interface{}, error) {
+	jsonBytes, err := Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := Unmarshal(jsonBytes, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func diffValues(path string, before interface{}, after interface{}, diffs *[]FieldDiff) {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		keys := map[string]bool{}
+		for key := range beforeMap {
+			keys[key] = true
+		}
+		for key := range afterMap {
+			keys[key] = true
+		}
+		for key := range keys {
+			diffValues(joinPath(path, key), beforeMap[key], afterMap[key], diffs)
+		}
+		return
+	}
+
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList {
+		for i := 0; i < len(beforeList) || i < len(afterList); i++ {
+			var beforeItem, afterItem interface{}
+			if i < len(beforeList) {
+				beforeItem = beforeList[i]
+			}
+			if i < len(afterList) {
+				afterItem = afterList[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), beforeItem, afterItem, diffs)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		*diffs = append(*diffs, FieldDiff{Path: path, Before: before, After: after})
+	}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
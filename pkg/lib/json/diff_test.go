@@ -0,0 +1,68 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	type compute struct {
+		CPU      string   `json:"cpu"`
+		GPU      *int64   `json:"gpu"`
+		Schedule []string `json:"schedule"`
+	}
+	type api struct {
+		Name    string            `json:"name"`
+		Env     map[string]string `json:"env"`
+		Compute *compute          `json:"compute"`
+	}
+
+	gpu := int64(1)
+	before := api{
+		Name:    "iris",
+		Env:     map[string]string{"A": "1", "B": "2"},
+		Compute: &compute{CPU: "1", Schedule: []string{"0 8 * * *", "0 20 * * *"}},
+	}
+	after := api{
+		Name:    "iris",
+		Env:     map[string]string{"A": "1", "C": "3"},
+		Compute: &compute{CPU: "2", GPU: &gpu, Schedule: []string{"0 9 * * *"}},
+	}
+
+	diffs, err := Diff(before, after)
+	require.NoError(t, err)
+	require.Equal(t, []FieldDiff{
+		{Path: "compute.cpu", Before: "1", After: "2"},
+		{Path: "compute.gpu", Before: nil, After: float64(1)},
+		{Path: "compute.schedule[0]", Before: "0 8 * * *", After: "0 9 * * *"},
+		{Path: "compute.schedule[1]", Before: "0 20 * * *", After: nil},
+		{Path: "env.B", Before: "2", After: nil},
+		{Path: "env.C", Before: nil, After: "3"},
+	}, diffs)
+
+	diffs, err = Diff(before, before)
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+
+	// a field which changes type is reported as a whole
+	diffs, err = Diff(map[string]interface{}{"config": map[string]interface{}{"a": 1}}, map[string]interface{}{"config": "a"})
+	require.NoError(t, err)
+	require.Equal(t, []FieldDiff{{Path: "config", Before: map[string]interface{}{"a": float64(1)}, After: "a"}}, diffs)
+}
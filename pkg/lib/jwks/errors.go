@@ -0,0 +1,114 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jwks
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+type ErrorKind int
+
+const (
+	ErrUnknown ErrorKind = iota
+	ErrInvalidURI
+	ErrUnreachable
+	ErrRequest
+	ErrInvalidKeySet
+)
+
+var errorKinds = []string{
+	"err_unknown",
+	"err_invalid_uri",
+	"err_unreachable",
+	"err_request",
+	"err_invalid_key_set",
+}
+
+var _ = [1]int{}[int(ErrInvalidKeySet)-(len(errorKinds)-1)] // Ensure list length matches
+
+func (t ErrorKind) String() string {
+	return errorKinds[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t ErrorKind) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *ErrorKind) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(errorKinds); i++ {
+		if enum == errorKinds[i] {
+			*t = ErrorKind(i)
+			return nil
+		}
+	}
+
+	*t = ErrUnknown
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *ErrorKind) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t ErrorKind) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+type Error struct {
+	Kind    ErrorKind
+	message string
+}
+
+func (e Error) Error() string {
+	return e.message
+}
+
+func ErrorInvalidURI(uri string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidURI,
+		message: fmt.Sprintf("%s is not a valid JWKS URI (it must be an http:// or https:// URL)", uri),
+	})
+}
+
+func ErrorUnreachable(uri string, err error) error {
+	return errors.WithStack(Error{
+		Kind:    ErrUnreachable,
+		message: fmt.Sprintf("unable to fetch the JSON Web Key Set at %s: %s", uri, err.Error()),
+	})
+}
+
+func ErrorRequest(uri string, status string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrRequest,
+		message: fmt.Sprintf("unable to fetch the JSON Web Key Set at %s: the server responded with status %s", uri, status),
+	})
+}
+
+func ErrorInvalidKeySet(uri string, reason string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidKeySet,
+		message: fmt.Sprintf("%s is not a valid JSON Web Key Set: %s", uri, reason),
+	})
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jwks
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+)
+
+var _httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Key sets larger than this aren't read (identity providers' key sets are a few kilobytes)
+const _maxKeySetSize = 1024 * 1024
+
+type KeySet struct {
+	Keys []Key `json:"keys"`
+}
+
+// Key holds the fields of a JSON Web Key which identify it (the key's parameters aren't parsed)
+type Key struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+}
+
+// ValidateURI checks that the URI is an absolute http:// or https:// URL
+func ValidateURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrorInvalidURI(uri)
+	}
+	return nil
+}
+
+// Fetch downloads the JSON Web Key Set at the URI, and checks that it has at least one key
+func Fetch(uri string) (*KeySet, error) {
+	if err := ValidateURI(uri); err != nil {
+		return nil, err
+	}
+
+	response, err := _httpClient.Get(uri)
+	if err != nil {
+		return nil, ErrorUnreachable(uri, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, ErrorRequest(uri, response.Status)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, _maxKeySetSize))
+	if err != nil {
+		return nil, ErrorUnreachable(uri, err)
+	}
+
+	var keySet KeySet
+	if err := json.Unmarshal(body, &keySet); err != nil {
+		return nil, ErrorInvalidKeySet(uri, "the response is not a JSON object with a keys field")
+	}
+	if len(keySet.Keys) == 0 {
+		return nil, ErrorInvalidKeySet(uri, "it does not contain any keys")
+	}
+	for _, key := range keySet.Keys {
+		if key.KeyType == "" {
+			return nil, ErrorInvalidKeySet(uri, "each key must have a kty field")
+		}
+	}
+
+	return &keySet, nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jwks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateURI(t *testing.T) {
+	require.NoError(t, ValidateURI("https://login.example.com/.well-known/jwks.json"))
+	require.NoError(t, ValidateURI("http://keycloak.auth:8080/realms/ml/protocol/openid-connect/certs"))
+
+	for _, invalid := range []string{"login.example.com/jwks.json", "ftp://login.example.com/jwks.json", "https://", "https://login.example.com/%zz"} {
+		require.Error(t, ValidateURI(invalid), invalid)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/jwks.json":
+			w.Write([]byte(`{"keys": [{"kty": "RSA", "kid": "key-1", "alg": "RS256", "use": "sig", "n": "...", "e": "AQAB"}]}`))
+		case "/empty.json":
+			w.Write([]byte(`{"keys": []}`))
+		case "/no-kty.json":
+			w.Write([]byte(`{"keys": [{"kid": "key-1"}]}`))
+		case "/html":
+			w.Write([]byte(`<html></html>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	keySet, err := Fetch(server.URL + "/jwks.json")
+	require.NoError(t, err)
+	require.Equal(t, []Key{{KeyType: "RSA", KeyID: "key-1", Algorithm: "RS256", Use: "sig"}}, keySet.Keys)
+
+	_, err = Fetch(server.URL + "/missing.json")
+	require.Equal(t, ErrRequest, errors.Cause(err).(Error).Kind)
+	require.Contains(t, err.Error(), "404")
+
+	for _, path := range []string{"/empty.json", "/no-kty.json", "/html"} {
+		_, err = Fetch(server.URL + path)
+		require.Equal(t, ErrInvalidKeySet, errors.Cause(err).(Error).Kind, path)
+	}
+
+	_, err = Fetch("not a url")
+	require.Equal(t, ErrInvalidURI, errors.Cause(err).(Error).Kind)
+}
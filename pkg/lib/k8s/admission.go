@@ -0,0 +1,111 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	kadmissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+type ValidatingWebhookSpec struct {
+	Name             string
+	ServiceName      string
+	ServiceNamespace string
+	ServicePath      string
+	CABundle         []byte
+	APIGroups        []string
+	APIVersions      []string
+	Resources        []string
+	Operations       []kadmissionregistration.OperationType   // defaults to create and update
+	FailurePolicy    kadmissionregistration.FailurePolicyType // defaults to Fail
+	NamespaceLabels  map[string]string                        // only send requests for objects in namespaces with these labels (if set)
+}
+
+func ValidatingWebhookConfiguration(spec *ValidatingWebhookSpec) *kadmissionregistration.ValidatingWebhookConfiguration {
+	failurePolicy := spec.FailurePolicy
+	if failurePolicy == "" {
+		failurePolicy = kadmissionregistration.Fail
+	}
+	sideEffects := kadmissionregistration.SideEffectClassNone
+
+	operations := spec.Operations
+	if len(operations) == 0 {
+		operations = []kadmissionregistration.OperationType{kadmissionregistration.Create, kadmissionregistration.Update}
+	}
+
+	var namespaceSelector *kmeta.LabelSelector
+	if len(spec.NamespaceLabels) > 0 {
+		namespaceSelector = &kmeta.LabelSelector{MatchLabels: spec.NamespaceLabels}
+	}
+
+	return &kadmissionregistration.ValidatingWebhookConfiguration{
+		TypeMeta: kmeta.TypeMeta{
+			Kind:       "ValidatingWebhookConfiguration",
+			APIVersion: "admissionregistration.k8s.io/v1beta1",
+		},
+		ObjectMeta: kmeta.ObjectMeta{
+			Name: spec.Name,
+		},
+		Webhooks: []kadmissionregistration.Webhook{
+			{
+				Name: spec.Name,
+				ClientConfig: kadmissionregistration.WebhookClientConfig{
+					Service: &kadmissionregistration.ServiceReference{
+						Name:      spec.ServiceName,
+						Namespace: spec.ServiceNamespace,
+						Path:      &spec.ServicePath,
+					},
+					CABundle: spec.CABundle,
+				},
+				Rules: []kadmissionregistration.RuleWithOperations{
+					{
+						Operations: operations,
+						Rule: kadmissionregistration.Rule{
+							APIGroups:   spec.APIGroups,
+							APIVersions: spec.APIVersions,
+							Resources:   spec.Resources,
+						},
+					},
+				},
+				NamespaceSelector: namespaceSelector,
+				FailurePolicy:     &failurePolicy,
+				SideEffects:       &sideEffects,
+			},
+		},
+	}
+}
+
+// ApplyValidatingWebhookConfiguration creates or updates the (cluster-scoped) validating webhook configuration
+func (c *Client) ApplyValidatingWebhookConfiguration(webhookConfig *kadmissionregistration.ValidatingWebhookConfiguration) error {
+	webhookClient := c.clientset.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations()
+
+	existing, err := webhookClient.Get(webhookConfig.Name, kmeta.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return errors.WithStack(err)
+		}
+		_, err = webhookClient.Create(webhookConfig)
+		return errors.WithStack(err)
+	}
+
+	webhookConfig.ResourceVersion = existing.ResourceVersion
+	_, err = webhookClient.Update(webhookConfig)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"sort"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var (
+	authorizationPolicyTypeMeta = kmeta.TypeMeta{
+		APIVersion: "v1beta1",
+		Kind:       "AuthorizationPolicy",
+	}
+
+	authorizationPolicyGVR = kschema.GroupVersionResource{
+		Group:    "security.istio.io",
+		Version:  "v1beta1",
+		Resource: "authorizationpolicies",
+	}
+
+	authorizationPolicyGVK = kschema.GroupVersionKind{
+		Group:   "security.istio.io",
+		Version: "v1beta1",
+		Kind:    "AuthorizationPolicy",
+	}
+)
+
+type AuthorizationPolicySpec struct {
+	Name        string
+	Namespace   string
+	Selector    map[string]string // labels of the workloads which the policy applies to
+	Rules       []AuthorizationRule
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// A request is allowed if it matches any rule; if there are no rules, all requests are denied
+type AuthorizationRule struct {
+	Paths             []string          // all paths are allowed if empty
+	IPBlocks          []string          // all sources are allowed if empty
+	RequestPrincipals []string          // the <issuer>/<subject> of the request's validated token; requests without a token are allowed if empty
+	Claims            map[string]string // claim name -> the value which the request's token must have (or contain, if the claim is a list)
+}
+
+func AuthorizationPolicy(spec *AuthorizationPolicySpec) *kunstructured.Unstructured {
+	authorizationPolicyConfig := &kunstructured.Unstructured{}
+	authorizationPolicyConfig.SetGroupVersionKind(authorizationPolicyGVK)
+	authorizationPolicyConfig.SetName(spec.Name)
+	authorizationPolicyConfig.SetNamespace(spec.Namespace)
+	authorizationPolicyConfig.Object["metadata"] = map[string]interface{}{
+		"name":        spec.Name,
+		"namespace":   spec.Namespace,
+		"labels":      spec.Labels,
+		"annotations": spec.Annotations,
+	}
+
+	authorizationPolicySpec := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": spec.Selector,
+		},
+	}
+
+	if len(spec.Rules) > 0 {
+		rules := make([]map[string]interface{}, len(spec.Rules))
+		for i, rule := range spec.Rules {
+			rules[i] = map[string]interface{}{}
+			source := map[string]interface{}{}
+			if len(rule.IPBlocks) > 0 {
+				source["ipBlocks"] = rule.IPBlocks
+			}
+			if len(rule.RequestPrincipals) > 0 {
+				source["requestPrincipals"] = rule.RequestPrincipals
+			}
+			if len(source) > 0 {
+				rules[i]["from"] = []map[string]interface{}{
+					{"source": source},
+				}
+			}
+			if len(rule.Paths) > 0 {
+				rules[i]["to"] = []map[string]interface{}{
+					{"operation": map[string]interface{}{"paths": rule.Paths}},
+				}
+			}
+			if len(rule.Claims) > 0 {
+				claimNames := make([]string, 0, len(rule.Claims))
+				for name := range rule.Claims {
+					claimNames = append(claimNames, name)
+				}
+				sort.Strings(claimNames)
+
+				conditions := make([]map[string]interface{}, len(claimNames))
+				for j, name := range claimNames {
+					conditions[j] = map[string]interface{}{
+						"key":    "request.auth.claims[" + name + "]",
+						"values": []string{rule.Claims[name]},
+					}
+				}
+				rules[i]["when"] = conditions
+			}
+		}
+		authorizationPolicySpec["rules"] = rules
+	}
+
+	authorizationPolicyConfig.Object["spec"] = authorizationPolicySpec
+
+	return authorizationPolicyConfig
+}
+
+func (c *Client) CreateAuthorizationPolicy(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	authorizationPolicy, err := c.dynamicClient.
+		Resource(authorizationPolicyGVR).
+		Namespace(spec.GetNamespace()).
+		Create(spec, kmeta.CreateOptions{
+			TypeMeta: authorizationPolicyTypeMeta,
+		})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return authorizationPolicy, nil
+}
+
+func (c *Client) updateAuthorizationPolicy(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	authorizationPolicy, err := c.dynamicClient.
+		Resource(authorizationPolicyGVR).
+		Namespace(spec.GetNamespace()).
+		Update(spec, kmeta.UpdateOptions{
+			TypeMeta: authorizationPolicyTypeMeta,
+		})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return authorizationPolicy, nil
+}
+
+func (c *Client) ApplyAuthorizationPolicy(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	existing, err := c.GetAuthorizationPolicy(spec.GetName(), spec.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateAuthorizationPolicy(spec)
+	}
+	spec.SetResourceVersion(existing.GetResourceVersion())
+	return c.updateAuthorizationPolicy(spec)
+}
+
+func (c *Client) GetAuthorizationPolicy(name, namespace string) (*kunstructured.Unstructured, error) {
+	authorizationPolicy, err := c.dynamicClient.Resource(authorizationPolicyGVR).Namespace(namespace).Get(name, kmeta.GetOptions{
+		TypeMeta: authorizationPolicyTypeMeta,
+	})
+
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return authorizationPolicy, nil
+}
+
+func (c *Client) DeleteAuthorizationPolicy(name, namespace string) (bool, error) {
+	err := c.dynamicClient.Resource(authorizationPolicyGVR).Namespace(namespace).Delete(name, &kmeta.DeleteOptions{
+		TypeMeta: authorizationPolicyTypeMeta,
+	})
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (c *Client) ListAuthorizationPolicies(namespace string, opts *kmeta.ListOptions) ([]kunstructured.Unstructured, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+
+	apList, err := c.dynamicClient.Resource(authorizationPolicyGVR).Namespace(namespace).List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range apList.Items {
+		apList.Items[i].SetGroupVersionKind(authorizationPolicyGVK)
+	}
+	return apList.Items, nil
+}
+
+func (c *Client) ListAuthorizationPoliciesByLabels(namespace string, labels map[string]string) ([]kunstructured.Unstructured, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: LabelSelector(labels),
+	}
+	return c.ListAuthorizationPolicies(namespace, opts)
+}
+
+func (c *Client) ListAuthorizationPoliciesByLabel(namespace string, labelKey string, labelValue string) ([]kunstructured.Unstructured, error) {
+	return c.ListAuthorizationPoliciesByLabels(namespace, map[string]string{labelKey: labelValue})
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var (
+	cortexAPIGVR = kschema.GroupVersionResource{
+		Group:    "cortex.dev",
+		Version:  "v1alpha1",
+		Resource: "cortexapis",
+	}
+
+	cortexAPIGVK = kschema.GroupVersionKind{
+		Group:   "cortex.dev",
+		Version: "v1alpha1",
+		Kind:    "CortexAPI",
+	}
+)
+
+// ListCortexAPIs lists the CortexAPI custom resources in the client's namespace
+func (c *Client) ListCortexAPIs(opts *kmeta.ListOptions) ([]kunstructured.Unstructured, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+
+	cortexAPIList, err := c.dynamicClient.Resource(cortexAPIGVR).Namespace(c.Namespace).List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range cortexAPIList.Items {
+		cortexAPIList.Items[i].SetGroupVersionKind(cortexAPIGVK)
+	}
+	return cortexAPIList.Items, nil
+}
+
+// UpdateCortexAPIStatus replaces the status of a CortexAPI custom resource
+func (c *Client) UpdateCortexAPIStatus(cortexAPI *kunstructured.Unstructured, status map[string]interface{}) error {
+	cortexAPI = cortexAPI.DeepCopy()
+	cortexAPI.Object["status"] = status
+
+	_, err := c.dynamicClient.Resource(cortexAPIGVR).Namespace(cortexAPI.GetNamespace()).UpdateStatus(cortexAPI, kmeta.UpdateOptions{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
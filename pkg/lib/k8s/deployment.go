@@ -27,19 +27,22 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 )
 
+const ReasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+
 var deploymentTypeMeta = kmeta.TypeMeta{
 	APIVersion: "apps/v1",
 	Kind:       "Deployment",
 }
 
 type DeploymentSpec struct {
-	Name        string
-	Namespace   string
-	Replicas    int32
-	PodSpec     PodSpec
-	Selector    map[string]string
-	Labels      map[string]string
-	Annotations map[string]string
+	Name             string
+	Namespace        string
+	Replicas         int32
+	ProgressDeadline *int32 // in seconds (the kubernetes default is used if nil)
+	PodSpec          PodSpec
+	Selector         map[string]string
+	Labels           map[string]string
+	Annotations      map[string]string
 }
 
 func Deployment(spec *DeploymentSpec) *kapps.Deployment {
@@ -65,7 +68,8 @@ func Deployment(spec *DeploymentSpec) *kapps.Deployment {
 			Annotations: spec.Annotations,
 		},
 		Spec: kapps.DeploymentSpec{
-			Replicas: &spec.Replicas,
+			Replicas:                &spec.Replicas,
+			ProgressDeadlineSeconds: spec.ProgressDeadline,
 			Template: kcore.PodTemplateSpec{
 				ObjectMeta: kmeta.ObjectMeta{
 					Name:        spec.PodSpec.Name,
@@ -158,6 +162,9 @@ func (c *Client) ListDeployments(opts *kmeta.ListOptions) ([]kapps.Deployment, e
 }
 
 func (c *Client) ListDeploymentsByLabels(labels map[string]string) ([]kapps.Deployment, error) {
+	if c.informerCache != nil {
+		return c.informerCache.listDeployments(labels)
+	}
 	opts := &kmeta.ListOptions{
 		LabelSelector: LabelSelector(labels),
 	}
@@ -186,3 +193,16 @@ func DeploymentStartTime(deployment *kapps.Deployment) *time.Time {
 	}
 	return &deployment.CreationTimestamp.Time
 }
+
+// DeploymentStalledTime returns when the deployment exceeded its progress deadline (nil if it hasn't)
+func DeploymentStalledTime(deployment *kapps.Deployment) *time.Time {
+	if deployment == nil {
+		return nil
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == kapps.DeploymentProgressing && condition.Status == kcore.ConditionFalse && condition.Reason == ReasonProgressDeadlineExceeded {
+			return &condition.LastUpdateTime.Time
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"time"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// destinationRuleGVK is used for the destination rules which are built by this package (the client converts them to the cluster's version of Istio's networking API)
+var destinationRuleGVK = IstioNetworkingV1Alpha3.DestinationRuleGVK()
+
+type DestinationRuleSpec struct {
+	Name             string
+	Namespace        string
+	ServiceName      string
+	IdleTimeout      time.Duration // connections to the service are closed after this long without any requests; not set if 0
+	MTLS             bool          // connections to the service use Istio mutual TLS
+	ConnectionLimits *ConnectionLimits
+	OutlierDetection *OutlierDetection
+	Labels           map[string]string
+	Annotations      map[string]string
+}
+
+// ConnectionLimits are the limits of each client proxy's connection pool for the service (unset limits are unlimited)
+type ConnectionLimits struct {
+	MaxConnections     *int32 // the maximum number of connections to the service
+	MaxPendingRequests *int32 // the maximum number of requests which wait for a connection
+	MaxRequests        *int32 // the maximum number of concurrent requests to the service
+}
+
+// OutlierDetection ejects the service's endpoints which respond with consecutive 5xx errors from the load balancing pool
+type OutlierDetection struct {
+	Consecutive5xxErrors int32
+	Interval             time.Duration // how often endpoints are checked for ejection
+	BaseEjectionTime     time.Duration // an endpoint is ejected for this long times the number of times it has been ejected
+	MaxEjectionPercent   int32         // the maximum percentage of the service's endpoints which can be ejected at once
+}
+
+func DestinationRule(spec *DestinationRuleSpec) *kunstructured.Unstructured {
+	destinationRuleConfig := &kunstructured.Unstructured{}
+	destinationRuleConfig.SetGroupVersionKind(destinationRuleGVK)
+	destinationRuleConfig.SetName(spec.Name)
+	destinationRuleConfig.SetNamespace(spec.Namespace)
+	destinationRuleConfig.Object["metadata"] = map[string]interface{}{
+		"name":        spec.Name,
+		"namespace":   spec.Namespace,
+		"labels":      spec.Labels,
+		"annotations": spec.Annotations,
+	}
+
+	destinationRuleSpec := map[string]interface{}{
+		"host": spec.ServiceName,
+	}
+
+	trafficPolicy := map[string]interface{}{}
+	if connectionPool := destinationRuleConnectionPool(spec); len(connectionPool) > 0 {
+		trafficPolicy["connectionPool"] = connectionPool
+	}
+	if spec.OutlierDetection != nil {
+		trafficPolicy["outlierDetection"] = map[string]interface{}{
+			"consecutive5xxErrors": int64(spec.OutlierDetection.Consecutive5xxErrors),
+			"interval":             istioDuration(spec.OutlierDetection.Interval),
+			"baseEjectionTime":     istioDuration(spec.OutlierDetection.BaseEjectionTime),
+			"maxEjectionPercent":   int64(spec.OutlierDetection.MaxEjectionPercent),
+		}
+	}
+	if spec.MTLS {
+		trafficPolicy["tls"] = map[string]interface{}{
+			"mode": "ISTIO_MUTUAL",
+		}
+	}
+	if len(trafficPolicy) > 0 {
+		destinationRuleSpec["trafficPolicy"] = trafficPolicy
+	}
+
+	destinationRuleConfig.Object["spec"] = destinationRuleSpec
+
+	return destinationRuleConfig
+}
+
+func destinationRuleConnectionPool(spec *DestinationRuleSpec) map[string]interface{} {
+	tcp := map[string]interface{}{}
+	http := map[string]interface{}{}
+	if spec.IdleTimeout != 0 {
+		http["idleTimeout"] = istioDuration(spec.IdleTimeout)
+	}
+	if limits := spec.ConnectionLimits; limits != nil {
+		if limits.MaxConnections != nil {
+			tcp["maxConnections"] = int64(*limits.MaxConnections)
+		}
+		if limits.MaxPendingRequests != nil {
+			http["http1MaxPendingRequests"] = int64(*limits.MaxPendingRequests)
+		}
+		if limits.MaxRequests != nil {
+			http["http2MaxRequests"] = int64(*limits.MaxRequests)
+		}
+	}
+
+	connectionPool := map[string]interface{}{}
+	if len(tcp) > 0 {
+		connectionPool["tcp"] = tcp
+	}
+	if len(http) > 0 {
+		connectionPool["http"] = http
+	}
+	return connectionPool
+}
+
+func (c *Client) CreateDestinationRule(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	istio := c.IstioNetworkingAPI()
+	spec.SetGroupVersionKind(istio.DestinationRuleGVK())
+	destinationRule, err := c.dynamicClient.
+		Resource(istio.DestinationRuleGVR()).
+		Namespace(spec.GetNamespace()).
+		Create(spec, kmeta.CreateOptions{
+			TypeMeta: istioTypeMeta(istio.DestinationRuleGVK()),
+		})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return destinationRule, nil
+}
+
+func (c *Client) updateDestinationRule(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	istio := c.IstioNetworkingAPI()
+	spec.SetGroupVersionKind(istio.DestinationRuleGVK())
+	destinationRule, err := c.dynamicClient.
+		Resource(istio.DestinationRuleGVR()).
+		Namespace(spec.GetNamespace()).
+		Update(spec, kmeta.UpdateOptions{
+			TypeMeta: istioTypeMeta(istio.DestinationRuleGVK()),
+		})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return destinationRule, nil
+}
+
+func (c *Client) ApplyDestinationRule(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	existing, err := c.GetDestinationRule(spec.GetName(), spec.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateDestinationRule(spec)
+	}
+	spec.SetResourceVersion(existing.GetResourceVersion())
+	return c.updateDestinationRule(spec)
+}
+
+func (c *Client) GetDestinationRule(name, namespace string) (*kunstructured.Unstructured, error) {
+	istio := c.IstioNetworkingAPI()
+	destinationRule, err := c.dynamicClient.Resource(istio.DestinationRuleGVR()).Namespace(namespace).Get(name, kmeta.GetOptions{
+		TypeMeta: istioTypeMeta(istio.DestinationRuleGVK()),
+	})
+
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return destinationRule, nil
+}
+
+func (c *Client) DeleteDestinationRule(name, namespace string) (bool, error) {
+	istio := c.IstioNetworkingAPI()
+	err := c.dynamicClient.Resource(istio.DestinationRuleGVR()).Namespace(namespace).Delete(name, &kmeta.DeleteOptions{
+		TypeMeta: istioTypeMeta(istio.DestinationRuleGVK()),
+	})
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (c *Client) ListDestinationRules(namespace string, opts *kmeta.ListOptions) ([]kunstructured.Unstructured, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+
+	istio := c.IstioNetworkingAPI()
+	drList, err := c.dynamicClient.Resource(istio.DestinationRuleGVR()).Namespace(namespace).List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range drList.Items {
+		drList.Items[i].SetGroupVersionKind(istio.DestinationRuleGVK())
+	}
+	return drList.Items, nil
+}
+
+func (c *Client) ListDestinationRulesByLabels(namespace string, labels map[string]string) ([]kunstructured.Unstructured, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: LabelSelector(labels),
+	}
+	return c.ListDestinationRules(namespace, opts)
+}
+
+func (c *Client) ListDestinationRulesByLabel(namespace string, labelKey string, labelValue string) ([]kunstructured.Unstructured, error) {
+	return c.ListDestinationRulesByLabels(namespace, map[string]string{labelKey: labelValue})
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+)
+
+func TestDestinationRule(t *testing.T) {
+	spec := &DestinationRuleSpec{
+		Name:        "api",
+		Namespace:   "default",
+		ServiceName: "api",
+	}
+	_, ok := DestinationRule(spec).Object["spec"].(map[string]interface{})["trafficPolicy"]
+	require.False(t, ok)
+
+	spec.IdleTimeout = time.Hour
+	spec.ConnectionLimits = &ConnectionLimits{
+		MaxConnections: pointer.Int32(100),
+		MaxRequests:    pointer.Int32(50),
+	}
+	spec.OutlierDetection = &OutlierDetection{
+		Consecutive5xxErrors: 5,
+		Interval:             10 * time.Second,
+		BaseEjectionTime:     30 * time.Second,
+		MaxEjectionPercent:   50,
+	}
+
+	trafficPolicy := DestinationRule(spec).Object["spec"].(map[string]interface{})["trafficPolicy"]
+	require.Equal(t, map[string]interface{}{
+		"connectionPool": map[string]interface{}{
+			"tcp": map[string]interface{}{
+				"maxConnections": int64(100),
+			},
+			"http": map[string]interface{}{
+				"idleTimeout":      "3600s",
+				"http2MaxRequests": int64(50),
+			},
+		},
+		"outlierDetection": map[string]interface{}{
+			"consecutive5xxErrors": int64(5),
+			"interval":             "10s",
+			"baseEjectionTime":     "30s",
+			"maxEjectionPercent":   int64(50),
+		},
+	}, trafficPolicy)
+}
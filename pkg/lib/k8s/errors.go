@@ -17,6 +17,8 @@ limitations under the License.
 package k8s
 
 import (
+	"fmt"
+
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 )
 
@@ -25,14 +27,16 @@ type ErrorKind int
 const (
 	ErrUnknown       ErrorKind = iota
 	ErrParseQuantity ErrorKind = iota
+	ErrExecFailed
 )
 
 var errorKinds = []string{
 	"err_unknown",
 	"err_parse_quantity",
+	"err_exec_failed",
 }
 
-var _ = [1]int{}[int(ErrParseQuantity)-(len(errorKinds)-1)] // Ensure list length matches
+var _ = [1]int{}[int(ErrExecFailed)-(len(errorKinds)-1)] // Ensure list length matches
 
 func (t ErrorKind) String() string {
 	return errorKinds[t]
@@ -83,3 +87,14 @@ func ErrorParseQuantity(qtyStr string) error {
 		message: qtyStr + ": invalid kubernetes quantity, some valid examples are 1, 200m, 500Mi, 2G (see here for more information: https://www.cortex.dev/deployments/compute)",
 	})
 }
+
+func ErrorExecFailed(podName string, containerName string, output string, err error) error {
+	message := fmt.Sprintf("command failed in the %s container of pod %s: %s", containerName, podName, err.Error())
+	if output != "" {
+		message += "\n" + output
+	}
+	return errors.WithStack(Error{
+		Kind:    ErrExecFailed,
+		message: message,
+	})
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+
+	kcore "k8s.io/api/core/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+const (
+	dcgmExporterNamespace = "kube-system"
+	dcgmExporterPort      = "9400"
+	dcgmGPUUtilMetric     = "DCGM_FI_DEV_GPU_UTIL"
+)
+
+// ListPodsGPUUtilization returns the average utilization (as a percentage) of the GPUs allocated to each pod in the client's namespace,
+// as reported by the dcgm-exporter daemonset pods (selected by labels)
+func (c *Client) ListPodsGPUUtilization(exporterLabels map[string]string) (map[string]float64, error) {
+	exporterPods, err := c.clientset.CoreV1().Pods(dcgmExporterNamespace).List(kmeta.ListOptions{
+		LabelSelector: LabelSelector(exporterLabels),
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	utilizationSums := make(map[string]float64)
+	gpuCounts := make(map[string]int)
+
+	for _, exporterPod := range exporterPods.Items {
+		if exporterPod.Status.Phase != kcore.PodRunning {
+			continue
+		}
+
+		metrics, err := c.clientset.CoreV1().RESTClient().Get().
+			Namespace(dcgmExporterNamespace).
+			Resource("pods").
+			Name(exporterPod.Name + ":" + dcgmExporterPort).
+			SubResource("proxy").
+			Suffix("metrics").
+			DoRaw()
+		if err != nil {
+			return nil, errors.Wrap(err, exporterPod.Name)
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(metrics))
+		for scanner.Scan() {
+			podName, utilization, ok := parseGPUUtilizationLine(scanner.Text(), c.Namespace)
+			if !ok {
+				continue
+			}
+			utilizationSums[podName] += utilization
+			gpuCounts[podName]++
+		}
+	}
+
+	podsUtilization := make(map[string]float64, len(utilizationSums))
+	for podName, utilizationSum := range utilizationSums {
+		podsUtilization[podName] = utilizationSum / float64(gpuCounts[podName])
+	}
+
+	return podsUtilization, nil
+}
+
+// parseGPUUtilizationLine parses a line in the prometheus text format, e.g.
+// DCGM_FI_DEV_GPU_UTIL{gpu="0",UUID="GPU-...",pod="my-api-abc",namespace="default"} 42
+func parseGPUUtilizationLine(line string, namespace string) (string, float64, bool) {
+	if !strings.HasPrefix(line, dcgmGPUUtilMetric+"{") {
+		return "", 0, false
+	}
+
+	labelsEnd := strings.LastIndex(line, "}")
+	if labelsEnd == -1 {
+		return "", 0, false
+	}
+
+	fields := strings.Fields(line[labelsEnd+1:])
+	if len(fields) == 0 {
+		return "", 0, false
+	}
+	utilization, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return "", 0, false
+	}
+
+	labels := make(map[string]string)
+	for _, label := range strings.Split(line[len(dcgmGPUUtilMetric)+1:labelsEnd], ",") {
+		split := strings.SplitN(label, "=", 2)
+		if len(split) != 2 {
+			continue
+		}
+		labels[strings.TrimSpace(split[0])] = strings.Trim(strings.TrimSpace(split[1]), `"`)
+	}
+
+	// older versions of dcgm-exporter use pod_name and pod_namespace
+	podName := labels["pod"]
+	if podName == "" {
+		podName = labels["pod_name"]
+	}
+	podNamespace := labels["namespace"]
+	if podNamespace == "" {
+		podNamespace = labels["pod_namespace"]
+	}
+
+	if podName == "" || podNamespace != namespace {
+		return "", 0, false
+	}
+
+	return podName, utilization, true
+}
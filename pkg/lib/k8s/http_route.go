@@ -0,0 +1,247 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+)
+
+var (
+	httpRouteGVR = kschema.GroupVersionResource{
+		Group:    "gateway.networking.k8s.io",
+		Version:  "v1beta1",
+		Resource: "httproutes",
+	}
+
+	httpRouteGVK = kschema.GroupVersionKind{
+		Group:   "gateway.networking.k8s.io",
+		Version: "v1beta1",
+		Kind:    "HTTPRoute",
+	}
+
+	gatewayGVR = kschema.GroupVersionResource{
+		Group:    "gateway.networking.k8s.io",
+		Version:  "v1beta1",
+		Resource: "gateways",
+	}
+)
+
+// GatewayHTTPRouteSpec describes a Gateway API HTTP route which matches a path exactly and forwards requests to a service
+type GatewayHTTPRouteSpec struct {
+	Name             string
+	Namespace        string
+	GatewayName      string
+	GatewayNamespace string
+	ServiceName      string
+	ServicePort      int32
+	Path             string
+	Rewrite          *string // the path which requests are forwarded to
+	Labels           map[string]string
+	Annotations      map[string]string
+}
+
+func GatewayHTTPRoute(spec *GatewayHTTPRouteSpec) *kunstructured.Unstructured {
+	httpRoute := &kunstructured.Unstructured{}
+	httpRoute.SetGroupVersionKind(httpRouteGVK)
+	httpRoute.Object["metadata"] = map[string]interface{}{
+		"name":        spec.Name,
+		"namespace":   spec.Namespace,
+		"labels":      spec.Labels,
+		"annotations": spec.Annotations,
+	}
+
+	rule := map[string]interface{}{
+		"matches": []interface{}{
+			map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":  "Exact",
+					"value": urls.CanonicalizeEndpoint(spec.Path),
+				},
+			},
+		},
+		"backendRefs": []interface{}{
+			map[string]interface{}{
+				"name": spec.ServiceName,
+				"port": int64(spec.ServicePort),
+			},
+		},
+	}
+
+	if spec.Rewrite != nil && urls.CanonicalizeEndpoint(*spec.Rewrite) != urls.CanonicalizeEndpoint(spec.Path) {
+		rule["filters"] = []interface{}{
+			map[string]interface{}{
+				"type": "URLRewrite",
+				"urlRewrite": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":            "ReplaceFullPath",
+						"replaceFullPath": urls.CanonicalizeEndpoint(*spec.Rewrite),
+					},
+				},
+			},
+		}
+	}
+
+	parentRef := map[string]interface{}{
+		"name": spec.GatewayName,
+	}
+	if spec.GatewayNamespace != "" {
+		parentRef["namespace"] = spec.GatewayNamespace // defaults to the route's namespace
+	}
+
+	httpRoute.Object["spec"] = map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+		"rules":      []interface{}{rule},
+	}
+
+	return httpRoute
+}
+
+func (c *Client) CreateHTTPRoute(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	httpRoute, err := c.dynamicClient.Resource(httpRouteGVR).Namespace(spec.GetNamespace()).Create(spec, kmeta.CreateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return httpRoute, nil
+}
+
+func (c *Client) updateHTTPRoute(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	httpRoute, err := c.dynamicClient.Resource(httpRouteGVR).Namespace(spec.GetNamespace()).Update(spec, kmeta.UpdateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return httpRoute, nil
+}
+
+func (c *Client) ApplyHTTPRoute(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	existing, err := c.GetHTTPRoute(spec.GetName(), spec.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateHTTPRoute(spec)
+	}
+	spec.SetResourceVersion(existing.GetResourceVersion())
+	return c.updateHTTPRoute(spec)
+}
+
+func (c *Client) GetHTTPRoute(name, namespace string) (*kunstructured.Unstructured, error) {
+	httpRoute, err := c.dynamicClient.Resource(httpRouteGVR).Namespace(namespace).Get(name, kmeta.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return httpRoute, nil
+}
+
+func (c *Client) DeleteHTTPRoute(name, namespace string) (bool, error) {
+	err := c.dynamicClient.Resource(httpRouteGVR).Namespace(namespace).Delete(name, deleteOpts)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (c *Client) ListHTTPRoutesByLabels(namespace string, labels map[string]string) ([]kunstructured.Unstructured, error) {
+	routeList, err := c.dynamicClient.Resource(httpRouteGVR).Namespace(namespace).List(kmeta.ListOptions{
+		LabelSelector: LabelSelector(labels),
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range routeList.Items {
+		routeList.Items[i].SetGroupVersionKind(httpRouteGVK)
+	}
+	return routeList.Items, nil
+}
+
+// GetHTTPRouteGateways returns the <namespace>/<name> of each of the HTTP route's parent gateways
+func GetHTTPRouteGateways(httpRoute *kunstructured.Unstructured) []string {
+	parentRefs, _, _ := kunstructured.NestedSlice(httpRoute.Object, "spec", "parentRefs")
+	var gateways []string
+	for _, parentRefInterface := range parentRefs {
+		parentRef, ok := parentRefInterface.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := kunstructured.NestedString(parentRef, "name")
+		namespace, _, _ := kunstructured.NestedString(parentRef, "namespace")
+		if namespace == "" {
+			namespace = httpRoute.GetNamespace()
+		}
+		gateways = append(gateways, namespace+"/"+name)
+	}
+	return gateways
+}
+
+// GetHTTPRouteEndpoints returns the paths which the HTTP route matches exactly ("/" if it has a rule which doesn't match a path exactly)
+func GetHTTPRouteEndpoints(httpRoute *kunstructured.Unstructured) []string {
+	rules, _, _ := kunstructured.NestedSlice(httpRoute.Object, "spec", "rules")
+	var endpoints []string
+	for _, ruleInterface := range rules {
+		rule, ok := ruleInterface.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		matches, _, _ := kunstructured.NestedSlice(rule, "matches")
+		if len(matches) == 0 {
+			return []string{"/"}
+		}
+		for _, matchInterface := range matches {
+			match, ok := matchInterface.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			pathType, _, _ := kunstructured.NestedString(match, "path", "type")
+			path, _, _ := kunstructured.NestedString(match, "path", "value")
+			if pathType != "Exact" || path == "" {
+				return []string{"/"}
+			}
+			endpoints = append(endpoints, urls.CanonicalizeEndpoint(path))
+		}
+	}
+	return endpoints
+}
+
+// GetGatewayAddress returns the first address in the Gateway API gateway's status ("" if it doesn't have one yet)
+func (c *Client) GetGatewayAddress(name, namespace string) (string, error) {
+	gateway, err := c.dynamicClient.Resource(gatewayGVR).Namespace(namespace).Get(name, kmeta.GetOptions{})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	addresses, _, _ := kunstructured.NestedSlice(gateway.Object, "status", "addresses")
+	for _, addressInterface := range addresses {
+		address, ok := addressInterface.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if value, _, _ := kunstructured.NestedString(address, "value"); value != "" {
+			return value, nil
+		}
+	}
+	return "", nil
+}
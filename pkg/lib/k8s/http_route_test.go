@@ -0,0 +1,64 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+)
+
+func TestGatewayHTTPRoute(t *testing.T) {
+	httpRoute := GatewayHTTPRoute(&GatewayHTTPRouteSpec{
+		Name:             "app----api",
+		Namespace:        "default",
+		GatewayName:      "gateway",
+		GatewayNamespace: "gateways",
+		ServiceName:      "app----api",
+		ServicePort:      8888,
+		Path:             "/deployment/api/",
+		Rewrite:          pointer.String("predict"),
+	})
+
+	require.Equal(t, []string{"gateways/gateway"}, GetHTTPRouteGateways(httpRoute))
+	require.Equal(t, []string{"/deployment/api"}, GetHTTPRouteEndpoints(httpRoute))
+
+	rules := httpRoute.Object["spec"].(map[string]interface{})["rules"].([]interface{})
+	filters := rules[0].(map[string]interface{})["filters"].([]interface{})
+	require.Equal(t, "/predict", filters[0].(map[string]interface{})["urlRewrite"].(map[string]interface{})["path"].(map[string]interface{})["replaceFullPath"])
+}
+
+func TestGetHTTPRouteEndpointsPrefix(t *testing.T) {
+	httpRoute := GatewayHTTPRoute(&GatewayHTTPRouteSpec{
+		Name:        "route",
+		Namespace:   "default",
+		GatewayName: "gateway",
+		ServiceName: "service",
+		ServicePort: 80,
+		Path:        "/predict",
+	})
+	require.Equal(t, []string{"default/gateway"}, GetHTTPRouteGateways(httpRoute))
+
+	rules := httpRoute.Object["spec"].(map[string]interface{})["rules"].([]interface{})
+	rules[0].(map[string]interface{})["matches"] = []interface{}{
+		map[string]interface{}{"path": map[string]interface{}{"type": "PathPrefix", "value": "/"}},
+	}
+	// routes which don't match a path exactly are reported as matching /
+	require.Equal(t, []string{"/"}, GetHTTPRouteEndpoints(httpRoute))
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"sort"
+	"time"
+
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+	kapimeta "k8s.io/apimachinery/pkg/api/meta"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	klabels "k8s.io/apimachinery/pkg/labels"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+	kdynamicinformer "k8s.io/client-go/dynamic/dynamicinformer"
+	kinformers "k8s.io/client-go/informers"
+	kcache "k8s.io/client-go/tools/cache"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+const (
+	_informerResyncPeriod = 10 * time.Minute
+	_labelIndex           = "labels"
+)
+
+// informerCache holds indexers which are kept up to date by shared informers (objects are indexed by each of their labels)
+type informerCache struct {
+	deployments     kcache.Indexer
+	pods            kcache.Indexer
+	virtualServices kcache.Indexer
+
+	virtualServiceGVK kschema.GroupVersionKind
+}
+
+// StartInformers watches the deployments, pods, and virtual services (if the cluster serves them) in the client's namespace, and returns once the informers have synced.
+// Afterwards, ListDeploymentsByLabels, ListPodsByLabels, ListPodsByPhase, and ListVirtualServicesByLabels (for the client's namespace) are served from the informers' caches
+func (c *Client) StartInformers(stopCh <-chan struct{}) error {
+	istio := c.IstioNetworkingAPI()
+	factory := kinformers.NewSharedInformerFactoryWithOptions(c.clientset, _informerResyncPeriod, kinformers.WithNamespace(c.Namespace))
+	dynamicFactory := kdynamicinformer.NewFilteredDynamicSharedInformerFactory(c.dynamicClient, _informerResyncPeriod, c.Namespace, nil)
+
+	deploymentInformer := factory.Apps().V1().Deployments().Informer()
+	podInformer := factory.Core().V1().Pods().Informer()
+	informers := []kcache.SharedIndexInformer{deploymentInformer, podInformer}
+
+	// Istio isn't installed when the cluster uses a different ingress backend, in which case virtual services are listed from the API server
+	var virtualServiceInformer kcache.SharedIndexInformer
+	if c.servesResource(istio.VirtualServiceGVR()) {
+		virtualServiceInformer = dynamicFactory.ForResource(istio.VirtualServiceGVR()).Informer()
+		informers = append(informers, virtualServiceInformer)
+	}
+
+	hasSynced := make([]kcache.InformerSynced, len(informers))
+	for i, informer := range informers {
+		if err := informer.AddIndexers(kcache.Indexers{_labelIndex: labelIndexFunc}); err != nil {
+			return errors.WithStack(err)
+		}
+		hasSynced[i] = informer.HasSynced
+	}
+
+	factory.Start(stopCh)
+	dynamicFactory.Start(stopCh)
+
+	if !kcache.WaitForCacheSync(stopCh, hasSynced...) {
+		return errors.New("unable to sync kubernetes informers")
+	}
+
+	c.informerCache = &informerCache{
+		deployments:       deploymentInformer.GetIndexer(),
+		pods:              podInformer.GetIndexer(),
+		virtualServiceGVK: istio.VirtualServiceGVK(),
+	}
+	if virtualServiceInformer != nil {
+		c.informerCache.virtualServices = virtualServiceInformer.GetIndexer()
+	}
+	return nil
+}
+
+func labelIndexFunc(obj interface{}) ([]string, error) {
+	metaObj, err := kapimeta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(metaObj.GetLabels()))
+	for key, value := range metaObj.GetLabels() {
+		keys = append(keys, labelIndexKey(key, value))
+	}
+	return keys, nil
+}
+
+func labelIndexKey(key string, value string) string {
+	return key + "=" + value
+}
+
+// listByLabels returns the objects in the indexer which have all of the labels (the first label, in sorted order, is looked up in the index)
+func listByLabels(indexer kcache.Indexer, labels map[string]string) ([]interface{}, error) {
+	if len(labels) == 0 {
+		return indexer.List(), nil
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	objs, err := indexer.ByIndex(_labelIndex, labelIndexKey(keys[0], labels[keys[0]]))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	selector := klabels.SelectorFromSet(labels)
+	matches := make([]interface{}, 0, len(objs))
+	for _, obj := range objs {
+		metaObj, err := kapimeta.Accessor(obj)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if selector.Matches(klabels.Set(metaObj.GetLabels())) {
+			matches = append(matches, obj)
+		}
+	}
+	return matches, nil
+}
+
+// the objects in the informers' caches are shared, so copies are returned
+
+func (cache *informerCache) listDeployments(labels map[string]string) ([]kapps.Deployment, error) {
+	objs, err := listByLabels(cache.deployments, labels)
+	if err != nil {
+		return nil, err
+	}
+	deployments := make([]kapps.Deployment, 0, len(objs))
+	for _, obj := range objs {
+		if deployment, ok := obj.(*kapps.Deployment); ok {
+			deployment = deployment.DeepCopy()
+			deployment.TypeMeta = deploymentTypeMeta
+			deployments = append(deployments, *deployment)
+		}
+	}
+	return deployments, nil
+}
+
+func (cache *informerCache) listPods(labels map[string]string) ([]kcore.Pod, error) {
+	objs, err := listByLabels(cache.pods, labels)
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]kcore.Pod, 0, len(objs))
+	for _, obj := range objs {
+		if pod, ok := obj.(*kcore.Pod); ok {
+			pod = pod.DeepCopy()
+			pod.TypeMeta = podTypeMeta
+			pods = append(pods, *pod)
+		}
+	}
+	return pods, nil
+}
+
+func (cache *informerCache) listPodsByPhase(phase kcore.PodPhase) ([]kcore.Pod, error) {
+	pods, err := cache.listPods(nil)
+	if err != nil {
+		return nil, err
+	}
+	podsInPhase := pods[:0]
+	for _, pod := range pods {
+		if pod.Status.Phase == phase {
+			podsInPhase = append(podsInPhase, pod)
+		}
+	}
+	return podsInPhase, nil
+}
+
+func (cache *informerCache) listVirtualServices(labels map[string]string) ([]kunstructured.Unstructured, error) {
+	objs, err := listByLabels(cache.virtualServices, labels)
+	if err != nil {
+		return nil, err
+	}
+	virtualServices := make([]kunstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		if virtualService, ok := obj.(*kunstructured.Unstructured); ok {
+			virtualService = virtualService.DeepCopy()
+			virtualService.SetGroupVersionKind(cache.virtualServiceGVK)
+			virtualServices = append(virtualServices, *virtualService)
+		}
+	}
+	return virtualServices, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"strconv"
+	"time"
+
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const _istioNetworkingGroup = "networking.istio.io"
+
+// IstioNetworkingAPI is a version of Istio's networking API (the fields of virtual services and destination rules which cortex uses are the same in each version)
+type IstioNetworkingAPI interface {
+	Version() string
+	VirtualServiceGVR() kschema.GroupVersionResource
+	VirtualServiceGVK() kschema.GroupVersionKind
+	DestinationRuleGVR() kschema.GroupVersionResource
+	DestinationRuleGVK() kschema.GroupVersionKind
+}
+
+var (
+	IstioNetworkingV1Alpha3 IstioNetworkingAPI = istioNetworkingAPI("v1alpha3")
+	IstioNetworkingV1Beta1  IstioNetworkingAPI = istioNetworkingAPI("v1beta1")
+)
+
+// The supported versions, from newest to oldest
+var _istioNetworkingAPIs = []IstioNetworkingAPI{IstioNetworkingV1Beta1, IstioNetworkingV1Alpha3}
+
+type istioNetworkingAPI string
+
+func (api istioNetworkingAPI) Version() string {
+	return string(api)
+}
+
+func (api istioNetworkingAPI) VirtualServiceGVR() kschema.GroupVersionResource {
+	return kschema.GroupVersionResource{Group: _istioNetworkingGroup, Version: string(api), Resource: "virtualservices"}
+}
+
+func (api istioNetworkingAPI) VirtualServiceGVK() kschema.GroupVersionKind {
+	return kschema.GroupVersionKind{Group: _istioNetworkingGroup, Version: string(api), Kind: "VirtualService"}
+}
+
+func (api istioNetworkingAPI) DestinationRuleGVR() kschema.GroupVersionResource {
+	return kschema.GroupVersionResource{Group: _istioNetworkingGroup, Version: string(api), Resource: "destinationrules"}
+}
+
+func (api istioNetworkingAPI) DestinationRuleGVK() kschema.GroupVersionKind {
+	return kschema.GroupVersionKind{Group: _istioNetworkingGroup, Version: string(api), Kind: "DestinationRule"}
+}
+
+func istioTypeMeta(gvk kschema.GroupVersionKind) kmeta.TypeMeta {
+	return kmeta.TypeMeta{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+	}
+}
+
+// IstioNetworkingAPI returns the newest version of Istio's networking API which the cluster serves virtual services with
+// (it's detected on the first call; v1alpha3 is assumed if none of the supported versions are found)
+func (c *Client) IstioNetworkingAPI() IstioNetworkingAPI {
+	c.istioNetworkingAPIOnce.Do(func() {
+		c.istioNetworkingAPI = IstioNetworkingV1Alpha3
+		for _, api := range _istioNetworkingAPIs {
+			if c.servesResource(api.VirtualServiceGVR()) {
+				c.istioNetworkingAPI = api
+				return
+			}
+		}
+	})
+	return c.istioNetworkingAPI
+}
+
+func (c *Client) servesResource(gvr kschema.GroupVersionResource) bool {
+	resources, err := c.clientset.Discovery().ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return false // the group version isn't served
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == gvr.Resource {
+			return true
+		}
+	}
+	return false
+}
+
+// istioDuration formats a duration for istio's resources (in seconds, e.g. 1.5s)
+func istioDuration(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'f', -1, 64) + "s"
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+)
+
+// IstioVirtualService is a typed networking.istio.io VirtualService (only the fields which cortex uses are included; other fields are ignored when parsing)
+type IstioVirtualService struct {
+	kmeta.TypeMeta   `json:",inline"`
+	kmeta.ObjectMeta `json:"metadata,omitempty"`
+	Spec             IstioVirtualServiceSpec `json:"spec"`
+}
+
+type IstioVirtualServiceSpec struct {
+	Hosts    []string         `json:"hosts,omitempty"`
+	Gateways []string         `json:"gateways,omitempty"`
+	HTTP     []IstioHTTPRoute `json:"http,omitempty"`
+}
+
+type IstioHTTPRoute struct {
+	Match      []IstioHTTPMatchRequest     `json:"match,omitempty"`
+	Route      []IstioHTTPRouteDestination `json:"route,omitempty"`
+	Rewrite    *IstioHTTPRewrite           `json:"rewrite,omitempty"`
+	CORSPolicy *IstioCORSPolicy            `json:"corsPolicy,omitempty"`
+	Timeout    string                      `json:"timeout,omitempty"`
+	Retries    *IstioHTTPRetry             `json:"retries,omitempty"`
+}
+
+type IstioHTTPRetry struct {
+	Attempts      int32  `json:"attempts"`
+	PerTryTimeout string `json:"perTryTimeout,omitempty"`
+	RetryOn       string `json:"retryOn,omitempty"`
+}
+
+type IstioHTTPMatchRequest struct {
+	URI *IstioStringMatch `json:"uri,omitempty"`
+}
+
+// IstioStringMatch matches a string exactly, by prefix, or by regex (only one is set)
+type IstioStringMatch struct {
+	Exact  string `json:"exact,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+	Regex  string `json:"regex,omitempty"`
+}
+
+type IstioHTTPRouteDestination struct {
+	Destination IstioDestination `json:"destination"`
+	Weight      int32            `json:"weight,omitempty"`
+}
+
+type IstioDestination struct {
+	Host string            `json:"host"`
+	Port IstioPortSelector `json:"port"`
+}
+
+type IstioPortSelector struct {
+	Number int32 `json:"number"`
+}
+
+type IstioHTTPRewrite struct {
+	URI string `json:"uri,omitempty"`
+}
+
+type IstioCORSPolicy struct {
+	AllowOrigins []IstioStringMatch `json:"allowOrigins,omitempty"`
+	AllowMethods []string           `json:"allowMethods,omitempty"`
+	AllowHeaders []string           `json:"allowHeaders,omitempty"`
+	MaxAge       string             `json:"maxAge,omitempty"`
+}
+
+// ToIstioVirtualService parses an unstructured virtual service
+func ToIstioVirtualService(virtualService *kunstructured.Unstructured) (*IstioVirtualService, error) {
+	var istioVirtualService IstioVirtualService
+	if err := kruntime.DefaultUnstructuredConverter.FromUnstructured(virtualService.UnstructuredContent(), &istioVirtualService); err != nil {
+		return nil, errors.Wrap(err, "virtual service "+virtualService.GetName())
+	}
+	return &istioVirtualService, nil
+}
+
+// ToUnstructured converts the virtual service to an unstructured object (for the dynamic client)
+func (virtualService *IstioVirtualService) ToUnstructured() (*kunstructured.Unstructured, error) {
+	obj, err := kruntime.DefaultUnstructuredConverter.ToUnstructured(virtualService)
+	if err != nil {
+		return nil, errors.Wrap(err, "virtual service "+virtualService.Name)
+	}
+	unstructured := &kunstructured.Unstructured{Object: obj}
+	unstructured.SetGroupVersionKind(virtualServiceGVK)
+	return unstructured, nil
+}
+
+// GatewaySet returns the gateways which the virtual service is bound to
+func (virtualService *IstioVirtualService) GatewaySet() strset.Set {
+	return strset.New(virtualService.Spec.Gateways...)
+}
+
+// Endpoints returns the exact URIs which the virtual service matches ("/" if it matches any URI, i.e. it has a route without an exact URI match)
+func (virtualService *IstioVirtualService) Endpoints() strset.Set {
+	if len(virtualService.Spec.HTTP) == 0 {
+		return strset.New("/")
+	}
+
+	endpoints := strset.New()
+	for _, route := range virtualService.Spec.HTTP {
+		if len(route.Match) == 0 {
+			return strset.New("/")
+		}
+		for _, match := range route.Match {
+			if match.URI == nil || match.URI.Exact == "" {
+				return strset.New("/")
+			}
+			endpoints.Add(urls.CanonicalizeEndpoint(match.URI.Exact))
+		}
+	}
+
+	return endpoints
+}
+
+func (c *Client) GetIstioVirtualService(name, namespace string) (*IstioVirtualService, error) {
+	virtualService, err := c.GetVirtualService(name, namespace)
+	if err != nil || virtualService == nil {
+		return nil, err
+	}
+	return ToIstioVirtualService(virtualService)
+}
+
+func (c *Client) CreateIstioVirtualService(virtualService *IstioVirtualService) (*IstioVirtualService, error) {
+	spec, err := virtualService.ToUnstructured()
+	if err != nil {
+		return nil, err
+	}
+	created, err := c.CreateVirtualService(spec)
+	if err != nil {
+		return nil, err
+	}
+	return ToIstioVirtualService(created)
+}
+
+func (c *Client) ApplyIstioVirtualService(virtualService *IstioVirtualService) (*IstioVirtualService, error) {
+	spec, err := virtualService.ToUnstructured()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := c.ApplyVirtualService(spec)
+	if err != nil {
+		return nil, err
+	}
+	return ToIstioVirtualService(applied)
+}
+
+func (c *Client) ListIstioVirtualServices(namespace string, opts *kmeta.ListOptions) ([]IstioVirtualService, error) {
+	virtualServices, err := c.ListVirtualServices(namespace, opts)
+	if err != nil {
+		return nil, err
+	}
+	return toIstioVirtualServices(virtualServices)
+}
+
+func (c *Client) ListIstioVirtualServicesByLabels(namespace string, labels map[string]string) ([]IstioVirtualService, error) {
+	virtualServices, err := c.ListVirtualServicesByLabels(namespace, labels)
+	if err != nil {
+		return nil, err
+	}
+	return toIstioVirtualServices(virtualServices)
+}
+
+func (c *Client) ListIstioVirtualServicesByLabel(namespace string, labelKey string, labelValue string) ([]IstioVirtualService, error) {
+	return c.ListIstioVirtualServicesByLabels(namespace, map[string]string{labelKey: labelValue})
+}
+
+func toIstioVirtualServices(virtualServices []kunstructured.Unstructured) ([]IstioVirtualService, error) {
+	istioVirtualServices := make([]IstioVirtualService, len(virtualServices))
+	for i := range virtualServices {
+		istioVirtualService, err := ToIstioVirtualService(&virtualServices[i])
+		if err != nil {
+			return nil, err
+		}
+		istioVirtualServices[i] = *istioVirtualService
+	}
+	return istioVirtualServices, nil
+}
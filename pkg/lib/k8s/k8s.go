@@ -17,9 +17,11 @@ limitations under the License.
 package k8s
 
 import (
+	"net/http"
 	"path"
 	"regexp"
 	"strings"
+	"sync"
 
 	kresource "k8s.io/apimachinery/pkg/api/resource"
 	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -54,11 +56,19 @@ type Client struct {
 	nodeClient       kclientcore.NodeInterface
 	serviceClient    kclientcore.ServiceInterface
 	configMapClient  kclientcore.ConfigMapInterface
+	secretClient     kclientcore.SecretInterface
+	eventClient      kclientcore.EventInterface
 	deploymentClient kclientapps.DeploymentInterface
+	replicaSetClient kclientapps.ReplicaSetInterface
 	jobClient        kclientbatch.JobInterface
 	ingressClient    kclientextensions.IngressInterface
 	hpaClient        kclientautoscaling.HorizontalPodAutoscalerInterface
-	Namespace        string
+	informerCache    *informerCache // set by StartInformers
+
+	istioNetworkingAPI     IstioNetworkingAPI
+	istioNetworkingAPIOnce sync.Once
+
+	Namespace string
 }
 
 func New(namespace string, inCluster bool) (*Client, error) {
@@ -77,6 +87,14 @@ func New(namespace string, inCluster bool) (*Client, error) {
 		return nil, errors.Wrap(err, "kubeconfig")
 	}
 
+	wrapTransport := client.RestConfig.WrapTransport
+	client.RestConfig.WrapTransport = func(transport http.RoundTripper) http.RoundTripper {
+		if wrapTransport != nil {
+			transport = wrapTransport(transport)
+		}
+		return withRetries(transport)
+	}
+
 	client.clientset, err = kclientset.NewForConfig(client.RestConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "kubeconfig")
@@ -91,7 +109,10 @@ func New(namespace string, inCluster bool) (*Client, error) {
 	client.nodeClient = client.clientset.CoreV1().Nodes()
 	client.serviceClient = client.clientset.CoreV1().Services(namespace)
 	client.configMapClient = client.clientset.CoreV1().ConfigMaps(namespace)
+	client.secretClient = client.clientset.CoreV1().Secrets(namespace)
+	client.eventClient = client.clientset.CoreV1().Events(namespace)
 	client.deploymentClient = client.clientset.AppsV1().Deployments(namespace)
+	client.replicaSetClient = client.clientset.AppsV1().ReplicaSets(namespace)
 	client.jobClient = client.clientset.BatchV1().Jobs(namespace)
 	client.ingressClient = client.clientset.ExtensionsV1beta1().Ingresses(namespace)
 	client.hpaClient = client.clientset.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace)
@@ -0,0 +1,154 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// Peer authentication modes
+const (
+	PeerAuthenticationStrict     = "STRICT"     // workloads only accept mutual TLS traffic
+	PeerAuthenticationPermissive = "PERMISSIVE" // workloads accept both mutual TLS and plaintext traffic
+)
+
+var (
+	peerAuthenticationTypeMeta = kmeta.TypeMeta{
+		APIVersion: "v1beta1",
+		Kind:       "PeerAuthentication",
+	}
+
+	peerAuthenticationGVR = kschema.GroupVersionResource{
+		Group:    "security.istio.io",
+		Version:  "v1beta1",
+		Resource: "peerauthentications",
+	}
+
+	peerAuthenticationGVK = kschema.GroupVersionKind{
+		Group:   "security.istio.io",
+		Version: "v1beta1",
+		Kind:    "PeerAuthentication",
+	}
+)
+
+type PeerAuthenticationSpec struct {
+	Name        string
+	Namespace   string
+	Selector    map[string]string // labels of the workloads which the policy applies to (all of the namespace's workloads if empty)
+	Mode        string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+func PeerAuthentication(spec *PeerAuthenticationSpec) *kunstructured.Unstructured {
+	peerAuthenticationConfig := &kunstructured.Unstructured{}
+	peerAuthenticationConfig.SetGroupVersionKind(peerAuthenticationGVK)
+	peerAuthenticationConfig.SetName(spec.Name)
+	peerAuthenticationConfig.SetNamespace(spec.Namespace)
+	peerAuthenticationConfig.Object["metadata"] = map[string]interface{}{
+		"name":        spec.Name,
+		"namespace":   spec.Namespace,
+		"labels":      spec.Labels,
+		"annotations": spec.Annotations,
+	}
+
+	peerAuthenticationSpec := map[string]interface{}{
+		"mtls": map[string]interface{}{
+			"mode": spec.Mode,
+		},
+	}
+
+	if len(spec.Selector) > 0 {
+		peerAuthenticationSpec["selector"] = map[string]interface{}{
+			"matchLabels": spec.Selector,
+		}
+	}
+
+	peerAuthenticationConfig.Object["spec"] = peerAuthenticationSpec
+
+	return peerAuthenticationConfig
+}
+
+func (c *Client) CreatePeerAuthentication(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	peerAuthentication, err := c.dynamicClient.
+		Resource(peerAuthenticationGVR).
+		Namespace(spec.GetNamespace()).
+		Create(spec, kmeta.CreateOptions{
+			TypeMeta: peerAuthenticationTypeMeta,
+		})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return peerAuthentication, nil
+}
+
+func (c *Client) updatePeerAuthentication(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	peerAuthentication, err := c.dynamicClient.
+		Resource(peerAuthenticationGVR).
+		Namespace(spec.GetNamespace()).
+		Update(spec, kmeta.UpdateOptions{
+			TypeMeta: peerAuthenticationTypeMeta,
+		})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return peerAuthentication, nil
+}
+
+func (c *Client) ApplyPeerAuthentication(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	existing, err := c.GetPeerAuthentication(spec.GetName(), spec.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreatePeerAuthentication(spec)
+	}
+	spec.SetResourceVersion(existing.GetResourceVersion())
+	return c.updatePeerAuthentication(spec)
+}
+
+func (c *Client) GetPeerAuthentication(name, namespace string) (*kunstructured.Unstructured, error) {
+	peerAuthentication, err := c.dynamicClient.Resource(peerAuthenticationGVR).Namespace(namespace).Get(name, kmeta.GetOptions{
+		TypeMeta: peerAuthenticationTypeMeta,
+	})
+
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return peerAuthentication, nil
+}
+
+func (c *Client) DeletePeerAuthentication(name, namespace string) (bool, error) {
+	err := c.dynamicClient.Resource(peerAuthenticationGVR).Namespace(namespace).Delete(name, &kmeta.DeleteOptions{
+		TypeMeta: peerAuthenticationTypeMeta,
+	})
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
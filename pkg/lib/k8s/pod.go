@@ -17,12 +17,19 @@ limitations under the License.
 package k8s
 
 import (
+	"bytes"
+	"encoding/json"
 	"regexp"
+	"strings"
 	"time"
 
 	kcore "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	kscheme "k8s.io/client-go/kubernetes/scheme"
+	kremotecommand "k8s.io/client-go/tools/remotecommand"
+	kexec "k8s.io/client-go/util/exec"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
@@ -263,6 +270,129 @@ func (c *Client) GetPod(name string) (*kcore.Pod, error) {
 	return pod, nil
 }
 
+// GetPodLogs returns the last tailLines lines of the pod's logs (for pods with a single container)
+func (c *Client) GetPodLogs(name string, tailLines int64) (string, error) {
+	logs, err := c.podClient.GetLogs(name, &kcore.PodLogOptions{
+		TailLines: &tailLines,
+	}).Do().Raw()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(logs), nil
+}
+
+// GetPodContainerLogs returns the last tailLines lines of the container's logs (or of its previous run, if previous is true)
+func (c *Client) GetPodContainerLogs(name string, container string, previous bool, tailLines int64) (string, error) {
+	logs, err := c.podClient.GetLogs(name, &kcore.PodLogOptions{
+		Container: container,
+		Previous:  previous,
+		TailLines: &tailLines,
+	}).Do().Raw()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(logs), nil
+}
+
+// ExecPodContainer runs the command in the container, and returns what it wrote to stdout (what it wrote to stderr is included in the error if it fails)
+func (c *Client) ExecPodContainer(name string, container string, command []string) ([]byte, error) {
+	request := c.clientset.CoreV1().RESTClient().Post().
+		Namespace(c.Namespace).
+		Resource("pods").
+		Name(name).
+		SubResource("exec").
+		VersionedParams(&kcore.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, kscheme.ParameterCodec)
+
+	executor, err := kremotecommand.NewSPDYExecutor(c.RestConfig, "POST", request.URL())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = executor.Stream(kremotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return nil, ErrorExecFailed(name, container, strings.TrimSpace(stderr.String()), err)
+	}
+	return stdout.Bytes(), nil
+}
+
+type ExecResult struct {
+	Stdout    []byte
+	Stderr    []byte
+	ExitCode  int
+	Truncated bool // whether stdout or stderr exceeded the output limit
+}
+
+// RunPodContainerCommand runs the command in the container, and returns what it wrote to stdout and stderr (each up to maxOutputBytes) along with its exit code; unlike ExecPodContainer, a non-zero exit code is not an error
+func (c *Client) RunPodContainerCommand(name string, container string, command []string, maxOutputBytes int) (*ExecResult, error) {
+	request := c.clientset.CoreV1().RESTClient().Post().
+		Namespace(c.Namespace).
+		Resource("pods").
+		Name(name).
+		SubResource("exec").
+		VersionedParams(&kcore.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, kscheme.ParameterCodec)
+
+	executor, err := kremotecommand.NewSPDYExecutor(c.RestConfig, "POST", request.URL())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	stdout := &limitedBuffer{limit: maxOutputBytes}
+	stderr := &limitedBuffer{limit: maxOutputBytes}
+	err = executor.Stream(kremotecommand.StreamOptions{
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+
+	result := &ExecResult{
+		Stdout:    stdout.Bytes(),
+		Stderr:    stderr.Bytes(),
+		Truncated: stdout.truncated || stderr.truncated,
+	}
+
+	if err != nil {
+		if exitErr, ok := err.(kexec.ExitError); ok && exitErr.Exited() {
+			result.ExitCode = exitErr.ExitStatus()
+			return result, nil
+		}
+		return nil, ErrorExecFailed(name, container, strings.TrimSpace(stderr.String()), err)
+	}
+
+	return result, nil
+}
+
+// limitedBuffer discards what is written to it beyond its limit
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	remaining := b.limit - b.Len()
+	if len(p) > remaining {
+		b.truncated = true
+		if remaining > 0 {
+			b.Buffer.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
 func (c *Client) DeletePod(name string) (bool, error) {
 	err := c.podClient.Delete(name, deleteOpts)
 	if kerrors.IsNotFound(err) {
@@ -274,6 +404,27 @@ func (c *Client) DeletePod(name string) (bool, error) {
 	return true, nil
 }
 
+// AnnotatePod sets the annotation on the pod (without restarting its containers)
+func (c *Client) AnnotatePod(name string, key string, value string) (bool, error) {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{key: value},
+		},
+	})
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	_, err = c.podClient.Patch(name, ktypes.MergePatchType, patch)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
 func (c *Client) PodExists(name string) (bool, error) {
 	pod, err := c.GetPod(name)
 	if err != nil {
@@ -296,7 +447,25 @@ func (c *Client) ListPods(opts *kmeta.ListOptions) ([]kcore.Pod, error) {
 	return podList.Items, nil
 }
 
+// ListPodsInAllNamespaces lists pods regardless of the client's namespace (e.g. to find the daemon set pods which run on each node)
+func (c *Client) ListPodsInAllNamespaces(opts *kmeta.ListOptions) ([]kcore.Pod, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+	podList, err := c.clientset.CoreV1().Pods(kcore.NamespaceAll).List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range podList.Items {
+		podList.Items[i].TypeMeta = podTypeMeta
+	}
+	return podList.Items, nil
+}
+
 func (c *Client) ListPodsByLabels(labels map[string]string) ([]kcore.Pod, error) {
+	if c.informerCache != nil {
+		return c.informerCache.listPods(labels)
+	}
 	opts := &kmeta.ListOptions{
 		LabelSelector: LabelSelector(labels),
 	}
@@ -307,6 +476,15 @@ func (c *Client) ListPodsByLabel(labelKey string, labelValue string) ([]kcore.Po
 	return c.ListPodsByLabels(map[string]string{labelKey: labelValue})
 }
 
+func (c *Client) ListPodsByPhase(phase kcore.PodPhase) ([]kcore.Pod, error) {
+	if c.informerCache != nil {
+		return c.informerCache.listPodsByPhase(phase)
+	}
+	return c.ListPods(&kmeta.ListOptions{
+		FieldSelector: "status.phase=" + string(phase),
+	})
+}
+
 func PodMap(pods []kcore.Pod) map[string]kcore.Pod {
 	podMap := map[string]kcore.Pod{}
 	for _, pod := range pods {
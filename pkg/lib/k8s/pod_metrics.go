@@ -0,0 +1,84 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var podMetricsGVR = kschema.GroupVersionResource{
+	Group:    "metrics.k8s.io",
+	Version:  "v1beta1",
+	Resource: "pods",
+}
+
+// ListPodsMemoryUsage returns the current memory usage of each container (pod name -> container name -> usage), as reported by metrics-server
+func (c *Client) ListPodsMemoryUsage(labels map[string]string) (map[string]map[string]kresource.Quantity, error) {
+	return c.listPodsUsage(labels, kcore.ResourceMemory)
+}
+
+// ListPodsCPUUsage returns the current CPU usage of each container (pod name -> container name -> usage), as reported by metrics-server
+func (c *Client) ListPodsCPUUsage(labels map[string]string) (map[string]map[string]kresource.Quantity, error) {
+	return c.listPodsUsage(labels, kcore.ResourceCPU)
+}
+
+func (c *Client) listPodsUsage(labels map[string]string, resourceName kcore.ResourceName) (map[string]map[string]kresource.Quantity, error) {
+	opts := kmeta.ListOptions{
+		LabelSelector: LabelSelector(labels),
+	}
+
+	podMetricsList, err := c.dynamicClient.Resource(podMetricsGVR).Namespace(c.Namespace).List(opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	usage := make(map[string]map[string]kresource.Quantity, len(podMetricsList.Items))
+	for _, podMetrics := range podMetricsList.Items {
+		containers, _, err := kunstructured.NestedSlice(podMetrics.Object, "containers")
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		usage[podMetrics.GetName()] = make(map[string]kresource.Quantity, len(containers))
+		for _, containerInterface := range containers {
+			container, ok := containerInterface.(map[string]interface{})
+			if !ok {
+				return nil, errors.New("container metrics is not a map[string]interface{}") // unexpected
+			}
+
+			name, _, _ := kunstructured.NestedString(container, "name")
+			quantityStr, found, _ := kunstructured.NestedString(container, "usage", string(resourceName))
+			if name == "" || !found {
+				continue
+			}
+
+			quantity, err := kresource.ParseQuantity(quantityStr)
+			if err != nil {
+				return nil, ErrorParseQuantity(quantityStr)
+			}
+			usage[podMetrics.GetName()][name] = quantity
+		}
+	}
+
+	return usage, nil
+}
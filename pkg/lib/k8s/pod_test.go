@@ -0,0 +1,43 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitedBuffer(t *testing.T) {
+	buf := &limitedBuffer{limit: 5}
+
+	n, err := buf.Write([]byte("abc"))
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.False(t, buf.truncated)
+
+	n, err = buf.Write([]byte("defg"))
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	require.True(t, buf.truncated)
+	require.Equal(t, "abcde", buf.String())
+
+	n, err = buf.Write([]byte("h"))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, "abcde", buf.String())
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	kscheduling "k8s.io/api/scheduling/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var priorityClassTypeMeta = kmeta.TypeMeta{
+	APIVersion: "scheduling.k8s.io/v1",
+	Kind:       "PriorityClass",
+}
+
+// Priority classes aren't namespaced
+type PriorityClassSpec struct {
+	Name        string
+	Value       int32 // pods with higher values may preempt pods with lower values
+	Description string
+	Labels      map[string]string
+}
+
+func PriorityClass(spec *PriorityClassSpec) *kscheduling.PriorityClass {
+	return &kscheduling.PriorityClass{
+		TypeMeta: priorityClassTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:   spec.Name,
+			Labels: spec.Labels,
+		},
+		Value:       spec.Value,
+		Description: spec.Description,
+	}
+}
+
+func (c *Client) CreatePriorityClass(priorityClass *kscheduling.PriorityClass) (*kscheduling.PriorityClass, error) {
+	priorityClass.TypeMeta = priorityClassTypeMeta
+	priorityClass, err := c.clientset.SchedulingV1().PriorityClasses().Create(priorityClass)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return priorityClass, nil
+}
+
+// ApplyPriorityClass creates the priority class, or replaces it if its value has changed (a priority class's value can't be updated)
+func (c *Client) ApplyPriorityClass(priorityClass *kscheduling.PriorityClass) (*kscheduling.PriorityClass, error) {
+	existing, err := c.GetPriorityClass(priorityClass.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreatePriorityClass(priorityClass)
+	}
+	if existing.Value == priorityClass.Value && existing.Description == priorityClass.Description {
+		return existing, nil
+	}
+	if _, err := c.DeletePriorityClass(priorityClass.Name); err != nil {
+		return nil, err
+	}
+	return c.CreatePriorityClass(priorityClass)
+}
+
+func (c *Client) GetPriorityClass(name string) (*kscheduling.PriorityClass, error) {
+	priorityClass, err := c.clientset.SchedulingV1().PriorityClasses().Get(name, kmeta.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	priorityClass.TypeMeta = priorityClassTypeMeta
+	return priorityClass, nil
+}
+
+func (c *Client) DeletePriorityClass(name string) (bool, error) {
+	err := c.clientset.SchedulingV1().PriorityClasses().Delete(name, deleteOpts)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
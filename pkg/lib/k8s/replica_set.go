@@ -0,0 +1,76 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"strconv"
+
+	kapps "k8s.io/api/apps/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+const (
+	RevisionAnnotation = "deployment.kubernetes.io/revision"
+	PodTemplateHashKey = "pod-template-hash"
+)
+
+var replicaSetTypeMeta = kmeta.TypeMeta{
+	APIVersion: "apps/v1",
+	Kind:       "ReplicaSet",
+}
+
+func (c *Client) ListReplicaSets(opts *kmeta.ListOptions) ([]kapps.ReplicaSet, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+	replicaSetList, err := c.replicaSetClient.List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range replicaSetList.Items {
+		replicaSetList.Items[i].TypeMeta = replicaSetTypeMeta
+	}
+	return replicaSetList.Items, nil
+}
+
+func (c *Client) ListReplicaSetsByLabels(labels map[string]string) ([]kapps.ReplicaSet, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: LabelSelector(labels),
+	}
+	return c.ListReplicaSets(opts)
+}
+
+// Revision returns the deployment revision of the object (a deployment or one of its replica sets), or 0 if it is not set
+func Revision(obj kmeta.Object) int64 {
+	revision, err := strconv.ParseInt(obj.GetAnnotations()[RevisionAnnotation], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return revision
+}
+
+// IsOwnedBy returns whether the object is controlled by an object with the given UID
+func IsOwnedBy(obj kmeta.Object, ownerUID string) bool {
+	for _, ownerReference := range obj.GetOwnerReferences() {
+		if string(ownerReference.UID) == ownerUID {
+			return true
+		}
+	}
+	return false
+}
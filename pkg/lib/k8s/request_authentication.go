@@ -0,0 +1,182 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var (
+	requestAuthenticationTypeMeta = kmeta.TypeMeta{
+		APIVersion: "v1beta1",
+		Kind:       "RequestAuthentication",
+	}
+
+	requestAuthenticationGVR = kschema.GroupVersionResource{
+		Group:    "security.istio.io",
+		Version:  "v1beta1",
+		Resource: "requestauthentications",
+	}
+
+	requestAuthenticationGVK = kschema.GroupVersionKind{
+		Group:   "security.istio.io",
+		Version: "v1beta1",
+		Kind:    "RequestAuthentication",
+	}
+)
+
+type RequestAuthenticationSpec struct {
+	Name        string
+	Namespace   string
+	Selector    map[string]string // labels of the workloads which the policy applies to
+	JWTRules    []JWTRule
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// Requests with a token from the rule's issuer are rejected if the token can't be validated (requests without a token aren't rejected)
+type JWTRule struct {
+	Issuer    string
+	JWKSURI   string
+	Audiences []string // any audience is accepted if empty
+}
+
+func RequestAuthentication(spec *RequestAuthenticationSpec) *kunstructured.Unstructured {
+	requestAuthenticationConfig := &kunstructured.Unstructured{}
+	requestAuthenticationConfig.SetGroupVersionKind(requestAuthenticationGVK)
+	requestAuthenticationConfig.SetName(spec.Name)
+	requestAuthenticationConfig.SetNamespace(spec.Namespace)
+	requestAuthenticationConfig.Object["metadata"] = map[string]interface{}{
+		"name":        spec.Name,
+		"namespace":   spec.Namespace,
+		"labels":      spec.Labels,
+		"annotations": spec.Annotations,
+	}
+
+	jwtRules := make([]map[string]interface{}, len(spec.JWTRules))
+	for i, rule := range spec.JWTRules {
+		jwtRules[i] = map[string]interface{}{
+			"issuer":               rule.Issuer,
+			"jwksUri":              rule.JWKSURI,
+			"forwardOriginalToken": true,
+		}
+		if len(rule.Audiences) > 0 {
+			jwtRules[i]["audiences"] = rule.Audiences
+		}
+	}
+
+	requestAuthenticationConfig.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": spec.Selector,
+		},
+		"jwtRules": jwtRules,
+	}
+
+	return requestAuthenticationConfig
+}
+
+func (c *Client) CreateRequestAuthentication(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	requestAuthentication, err := c.dynamicClient.
+		Resource(requestAuthenticationGVR).
+		Namespace(spec.GetNamespace()).
+		Create(spec, kmeta.CreateOptions{
+			TypeMeta: requestAuthenticationTypeMeta,
+		})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return requestAuthentication, nil
+}
+
+func (c *Client) updateRequestAuthentication(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	requestAuthentication, err := c.dynamicClient.
+		Resource(requestAuthenticationGVR).
+		Namespace(spec.GetNamespace()).
+		Update(spec, kmeta.UpdateOptions{
+			TypeMeta: requestAuthenticationTypeMeta,
+		})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return requestAuthentication, nil
+}
+
+func (c *Client) ApplyRequestAuthentication(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	existing, err := c.GetRequestAuthentication(spec.GetName(), spec.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateRequestAuthentication(spec)
+	}
+	spec.SetResourceVersion(existing.GetResourceVersion())
+	return c.updateRequestAuthentication(spec)
+}
+
+func (c *Client) GetRequestAuthentication(name, namespace string) (*kunstructured.Unstructured, error) {
+	requestAuthentication, err := c.dynamicClient.Resource(requestAuthenticationGVR).Namespace(namespace).Get(name, kmeta.GetOptions{
+		TypeMeta: requestAuthenticationTypeMeta,
+	})
+
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return requestAuthentication, nil
+}
+
+func (c *Client) DeleteRequestAuthentication(name, namespace string) (bool, error) {
+	err := c.dynamicClient.Resource(requestAuthenticationGVR).Namespace(namespace).Delete(name, &kmeta.DeleteOptions{
+		TypeMeta: requestAuthenticationTypeMeta,
+	})
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (c *Client) ListRequestAuthentications(namespace string, opts *kmeta.ListOptions) ([]kunstructured.Unstructured, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+
+	raList, err := c.dynamicClient.Resource(requestAuthenticationGVR).Namespace(namespace).List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range raList.Items {
+		raList.Items[i].SetGroupVersionKind(requestAuthenticationGVK)
+	}
+	return raList.Items, nil
+}
+
+func (c *Client) ListRequestAuthenticationsByLabels(namespace string, labels map[string]string) ([]kunstructured.Unstructured, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: LabelSelector(labels),
+	}
+	return c.ListRequestAuthentications(namespace, opts)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/retry"
+)
+
+// retryTransport retries requests to the API server which fail with a transient status code, with retry.Default.
+// Responses with a Retry-After header are returned as is, since client-go retries them itself
+type retryTransport struct {
+	config    retry.Config
+	transport http.RoundTripper
+}
+
+func withRetries(transport http.RoundTripper) http.RoundTripper {
+	return &retryTransport{config: retry.Default, transport: transport}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		response, err := t.transport.RoundTrip(req)
+		if err != nil || !isRetryableResponse(req.Method, response) || attempt >= t.config.MaxAttempts {
+			return response, err
+		}
+
+		// requests with a body can only be retried if the body can be read again
+		if req.Body != nil && req.GetBody == nil {
+			return response, err
+		}
+
+		response.Body.Close()
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(t.config.Delay(attempt)):
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// isRetryableResponse returns whether the request can be retried: requests which may modify resources are only retried if the API server didn't process them
+// (e.g. a create which timed out may have succeeded, in which case retrying it would fail because the resource already exists)
+func isRetryableResponse(method string, response *http.Response) bool {
+	if response.Header.Get("Retry-After") != "" {
+		return false
+	}
+
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return isIdempotentMethod(method)
+	}
+	return false
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
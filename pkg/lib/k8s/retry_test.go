@@ -0,0 +1,140 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/retry"
+)
+
+func TestRetryTransport(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		method           string
+		statusCodes      []int // the status codes of the server's responses, in order (the last one is repeated)
+		retryAfter       bool
+		expectedStatus   int
+		expectedAttempts int
+	}{
+		{
+			name:             "get is retried after a server error",
+			method:           http.MethodGet,
+			statusCodes:      []int{http.StatusInternalServerError, http.StatusGatewayTimeout, http.StatusOK},
+			expectedStatus:   http.StatusOK,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "get stops after the max attempts",
+			method:           http.MethodGet,
+			statusCodes:      []int{http.StatusBadGateway},
+			expectedStatus:   http.StatusBadGateway,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "post isn't retried after a timeout",
+			method:           http.MethodPost,
+			statusCodes:      []int{http.StatusGatewayTimeout, http.StatusCreated},
+			expectedStatus:   http.StatusGatewayTimeout,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "delete isn't retried after a server error",
+			method:           http.MethodDelete,
+			statusCodes:      []int{http.StatusInternalServerError, http.StatusOK},
+			expectedStatus:   http.StatusInternalServerError,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "post is retried when the server is unavailable",
+			method:           http.MethodPost,
+			statusCodes:      []int{http.StatusServiceUnavailable, http.StatusCreated},
+			expectedStatus:   http.StatusCreated,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "patch is retried when it's throttled",
+			method:           http.MethodPatch,
+			statusCodes:      []int{http.StatusTooManyRequests, http.StatusOK},
+			expectedStatus:   http.StatusOK,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "responses with retry-after are left to client-go",
+			method:           http.MethodGet,
+			statusCodes:      []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:       true,
+			expectedStatus:   http.StatusTooManyRequests,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "client errors aren't retried",
+			method:           http.MethodGet,
+			statusCodes:      []int{http.StatusNotFound, http.StatusOK},
+			expectedStatus:   http.StatusNotFound,
+			expectedAttempts: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var lock sync.Mutex
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				lock.Lock()
+				bodies = append(bodies, string(body))
+				attempt := len(bodies)
+				lock.Unlock()
+
+				statusCode := tc.statusCodes[len(tc.statusCodes)-1]
+				if attempt <= len(tc.statusCodes) {
+					statusCode = tc.statusCodes[attempt-1]
+				}
+				if tc.retryAfter {
+					w.Header().Set("Retry-After", "1")
+				}
+				w.WriteHeader(statusCode)
+			}))
+			defer server.Close()
+
+			transport := &retryTransport{
+				config:    retry.Config{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond},
+				transport: http.DefaultTransport,
+			}
+
+			req, err := http.NewRequest(tc.method, server.URL, bytes.NewReader([]byte("body")))
+			require.NoError(t, err)
+
+			response, err := transport.RoundTrip(req)
+			require.NoError(t, err)
+			response.Body.Close()
+
+			require.Equal(t, tc.expectedStatus, response.StatusCode)
+			require.Len(t, bodies, tc.expectedAttempts)
+			for _, body := range bodies {
+				require.Equal(t, "body", body) // the body is sent again with each retry
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	kcore "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var secretTypeMeta = kmeta.TypeMeta{
+	APIVersion: "v1",
+	Kind:       "Secret",
+}
+
+type SecretSpec struct {
+	Name        string
+	Namespace   string
+	Data        map[string]string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+func Secret(spec *SecretSpec) *kcore.Secret {
+	if spec.Namespace == "" {
+		spec.Namespace = "default"
+	}
+	secret := &kcore.Secret{
+		TypeMeta: secretTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Namespace:   spec.Namespace,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		StringData: spec.Data,
+		Type:       kcore.SecretTypeOpaque,
+	}
+	return secret
+}
+
+func (c *Client) CreateSecret(secret *kcore.Secret) (*kcore.Secret, error) {
+	secret.TypeMeta = secretTypeMeta
+	secret, err := c.secretClient.Create(secret)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return secret, nil
+}
+
+func (c *Client) updateSecret(secret *kcore.Secret) (*kcore.Secret, error) {
+	secret.TypeMeta = secretTypeMeta
+	secret, err := c.secretClient.Update(secret)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return secret, nil
+}
+
+func (c *Client) ApplySecret(secret *kcore.Secret) (*kcore.Secret, error) {
+	existing, err := c.GetSecret(secret.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateSecret(secret)
+	}
+	return c.updateSecret(secret)
+}
+
+func (c *Client) GetSecret(name string) (*kcore.Secret, error) {
+	secret, err := c.secretClient.Get(name, kmeta.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	secret.TypeMeta = secretTypeMeta
+	return secret, nil
+}
+
+func (c *Client) GetSecretData(name string) (map[string][]byte, error) {
+	secret, err := c.GetSecret(name)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return nil, nil
+	}
+	return secret.Data, nil
+}
+
+func (c *Client) DeleteSecret(name string) (bool, error) {
+	err := c.secretClient.Delete(name, deleteOpts)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (c *Client) SecretExists(name string) (bool, error) {
+	secret, err := c.GetSecret(name)
+	if err != nil {
+		return false, err
+	}
+	return secret != nil, nil
+}
+
+func (c *Client) ListSecrets(opts *kmeta.ListOptions) ([]kcore.Secret, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+	secretList, err := c.secretClient.List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range secretList.Items {
+		secretList.Items[i].TypeMeta = secretTypeMeta
+	}
+	return secretList.Items, nil
+}
+
+func (c *Client) ListSecretsByLabels(labels map[string]string) ([]kcore.Secret, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: LabelSelector(labels),
+	}
+	return c.ListSecrets(opts)
+}
+
+func (c *Client) ListSecretsByLabel(labelKey string, labelValue string) ([]kcore.Secret, error) {
+	return c.ListSecretsByLabels(map[string]string{labelKey: labelValue})
+}
+
+func SecretMap(secrets []kcore.Secret) map[string]kcore.Secret {
+	secretMap := map[string]kcore.Secret{}
+	for _, secret := range secrets {
+		secretMap[secret.Name] = secret
+	}
+	return secretMap
+}
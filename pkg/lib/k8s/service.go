@@ -38,6 +38,7 @@ type ServiceSpec struct {
 	Selector    map[string]string
 	Labels      map[string]string
 	Annotations map[string]string
+	ExtraPorts  []kcore.ServicePort // ports in addition to the "http" port (e.g. a serving container's grpc port)
 }
 
 func Service(spec *ServiceSpec) *kcore.Service {
@@ -66,6 +67,7 @@ func Service(spec *ServiceSpec) *kcore.Service {
 			},
 		},
 	}
+	service.Spec.Ports = append(service.Spec.Ports, spec.ExtraPorts...)
 	return service
 }
 
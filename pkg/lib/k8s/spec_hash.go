@@ -0,0 +1,125 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"encoding/json"
+	"sort"
+
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
+)
+
+// SpecHashAnnotation records the hash of an object's spec when it was applied, so that changes which were made
+// to the object by something else (i.e. drift) can be detected
+const SpecHashAnnotation = "cortex.dev/spec-hash"
+
+type containerFingerprint struct {
+	Name      string           `json:"name"`
+	Image     string           `json:"image"`
+	Command   []string         `json:"command"`
+	Args      []string         `json:"args"`
+	Env       []string         `json:"env"`
+	Resources map[string]int64 `json:"resources"` // in milli-units
+}
+
+// DeploymentSpecHash hashes the fields of the deployment's pod template which aren't defaulted by the API server
+// (the number of replicas is excluded, since it's managed by the autoscaler)
+func DeploymentSpecHash(deployment *kapps.Deployment) string {
+	var fingerprint struct {
+		Labels         map[string]string      `json:"labels"`
+		InitContainers []containerFingerprint `json:"init_containers"`
+		Containers     []containerFingerprint `json:"containers"`
+	}
+
+	fingerprint.Labels = deployment.Spec.Template.Labels
+	for _, container := range deployment.Spec.Template.Spec.InitContainers {
+		fingerprint.InitContainers = append(fingerprint.InitContainers, getContainerFingerprint(container))
+	}
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		fingerprint.Containers = append(fingerprint.Containers, getContainerFingerprint(container))
+	}
+
+	fingerprintBytes, _ := json.Marshal(fingerprint)
+	return hash.Bytes(fingerprintBytes)
+}
+
+func getContainerFingerprint(container kcore.Container) containerFingerprint {
+	fingerprint := containerFingerprint{
+		Name:      container.Name,
+		Image:     container.Image,
+		Resources: make(map[string]int64),
+	}
+
+	// empty lists are omitted by the API server
+	if len(container.Command) > 0 {
+		fingerprint.Command = container.Command
+	}
+	if len(container.Args) > 0 {
+		fingerprint.Args = container.Args
+	}
+
+	for _, envVar := range container.Env {
+		switch {
+		case envVar.ValueFrom == nil:
+			fingerprint.Env = append(fingerprint.Env, envVar.Name+"="+envVar.Value)
+		case envVar.ValueFrom.FieldRef != nil:
+			fingerprint.Env = append(fingerprint.Env, envVar.Name+"=field:"+envVar.ValueFrom.FieldRef.FieldPath)
+		case envVar.ValueFrom.SecretKeyRef != nil:
+			fingerprint.Env = append(fingerprint.Env, envVar.Name+"=secret:"+envVar.ValueFrom.SecretKeyRef.Name+"/"+envVar.ValueFrom.SecretKeyRef.Key)
+		default:
+			fingerprint.Env = append(fingerprint.Env, envVar.Name)
+		}
+	}
+	sort.Strings(fingerprint.Env)
+
+	for name, quantity := range container.Resources.Requests {
+		fingerprint.Resources["requests."+string(name)] = quantity.MilliValue()
+	}
+	for name, quantity := range container.Resources.Limits {
+		fingerprint.Resources["limits."+string(name)] = quantity.MilliValue()
+	}
+
+	return fingerprint
+}
+
+// VirtualServiceSpecHash hashes the virtual service's spec
+func VirtualServiceSpecHash(virtualService *kunstructured.Unstructured) string {
+	specBytes, _ := json.Marshal(virtualService.Object["spec"])
+	return hash.Bytes(specBytes)
+}
+
+// SetSpecHashAnnotation records the spec hash in the object's annotations
+func SetSpecHashAnnotation(obj kmeta.Object, specHash string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[SpecHashAnnotation] = specHash
+	obj.SetAnnotations(annotations)
+}
+
+// HasDrifted returns whether the object's current spec hash differs from the one which was recorded when it was applied
+// (objects which were applied without a spec hash are assumed not to have drifted)
+func HasDrifted(obj kmeta.Object, specHash string) bool {
+	appliedHash, ok := obj.GetAnnotations()[SpecHashAnnotation]
+	return ok && appliedHash != specHash
+}
@@ -17,34 +17,20 @@ limitations under the License.
 package k8s
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	kschema "k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
-	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	"github.com/cortexlabs/cortex/pkg/lib/urls"
 )
 
-var (
-	virtualServiceTypeMeta = kmeta.TypeMeta{
-		APIVersion: "v1alpha3",
-		Kind:       "VirtualService",
-	}
-
-	virtualServiceGVR = kschema.GroupVersionResource{
-		Group:    "networking.istio.io",
-		Version:  "v1alpha3",
-		Resource: "virtualservices",
-	}
-
-	virtualServiceGVK = kschema.GroupVersionKind{
-		Group:   "networking.istio.io",
-		Version: "v1alpha3",
-		Kind:    "VirtualService",
-	}
-)
+// virtualServiceGVK is used for the virtual services which are built by this package (the client converts them to the cluster's version of Istio's networking API)
+var virtualServiceGVK = IstioNetworkingV1Alpha3.VirtualServiceGVK()
 
 type VirtualServiceSpec struct {
 	Name        string
@@ -54,63 +40,179 @@ type VirtualServiceSpec struct {
 	ServicePort int32
 	Path        string
 	Rewrite     *string
+	Subpaths    []string // paths under Path which are routed to the same path on the service, e.g. "explain" routes <Path>/explain to /explain
+	CORSPolicy  *CORSPolicy
+	WebSocket   bool          // disables the route timeout so that upgraded connections stay open
+	Timeout     time.Duration // the timeout of requests, including their retries; not set if 0
+	Retries     *RetryPolicy
+	Canary      *CanaryRoute
 	Labels      map[string]string
 	Annotations map[string]string
 }
 
+// CanaryRoute splits traffic between the virtual service's service and a canary service
+type CanaryRoute struct {
+	ServiceName string
+	Weight      int32 // percentage of requests which are routed to the canary service
+}
+
+// RetryPolicy retries requests which fail to connect to the service (e.g. because a replica is restarting),
+// or which receive one of the status codes
+type RetryPolicy struct {
+	Attempts      int32
+	PerTryTimeout time.Duration // not set if 0
+	StatusCodes   []int32
+}
+
+// retry requests whose connections fail or are reset by the service
+var _retryOnConnectionFailures = []string{"connect-failure", "refused-stream", "reset"}
+
+type CORSPolicy struct {
+	AllowOrigins []string // "*" allows all origins
+	AllowMethods []string
+	AllowHeaders []string
+	MaxAge       time.Duration // not set if 0
+}
+
 func VirtualService(spec *VirtualServiceSpec) *kunstructured.Unstructured {
-	virtualServiceConfig := &kunstructured.Unstructured{}
-	virtualServiceConfig.SetGroupVersionKind(virtualServiceGVK)
-	virtualServiceConfig.SetName(spec.Name)
-	virtualServiceConfig.SetNamespace(spec.Namespace)
-	virtualServiceConfig.Object["metadata"] = map[string]interface{}{
-		"name":        spec.Name,
-		"namespace":   spec.Namespace,
-		"labels":      spec.Labels,
-		"annotations": spec.Annotations,
-	}
+	virtualService, _ := IstioVirtualServiceFromSpec(spec).ToUnstructured() // the typed virtual service is always convertible
+	return virtualService
+}
 
-	httpSpec := map[string]interface{}{
-		"match": []map[string]interface{}{
-			{
-				"uri": map[string]interface{}{
-					"exact": urls.CanonicalizeEndpoint(spec.Path),
-				},
-			},
+func IstioVirtualServiceFromSpec(spec *VirtualServiceSpec) *IstioVirtualService {
+	return &IstioVirtualService{
+		TypeMeta: kmeta.TypeMeta{
+			APIVersion: virtualServiceGVK.GroupVersion().String(),
+			Kind:       virtualServiceGVK.Kind,
+		},
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Namespace:   spec.Namespace,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
 		},
-		"route": []map[string]interface{}{
-			{
-				"destination": map[string]interface{}{
-					"host": spec.ServiceName,
-					"port": map[string]interface{}{
-						"number": spec.ServicePort,
-					},
-				},
-			},
+		Spec: IstioVirtualServiceSpec{
+			Hosts:    []string{"*"},
+			Gateways: spec.Gateways,
+			HTTP:     HTTPRoutes(spec),
 		},
 	}
+}
+
+// HTTPRoutes builds the virtual service's routes: the spec's route, followed by a route for each of its subpaths
+func HTTPRoutes(spec *VirtualServiceSpec) []IstioHTTPRoute {
+	routes := []IstioHTTPRoute{HTTPRoute(spec)}
+	for i := range spec.Subpaths {
+		subpathSpec := *spec
+		subpathSpec.Path = urls.Join(spec.Path, spec.Subpaths[i])
+		subpathSpec.Rewrite = &spec.Subpaths[i]
+		subpathSpec.Subpaths = nil
+		routes = append(routes, HTTPRoute(&subpathSpec))
+	}
+	return routes
+}
+
+// HTTPRoute builds the virtual service's route, which matches the spec's path exactly
+func HTTPRoute(spec *VirtualServiceSpec) IstioHTTPRoute {
+	route := IstioHTTPRoute{
+		Match: []IstioHTTPMatchRequest{
+			{URI: &IstioStringMatch{Exact: urls.CanonicalizeEndpoint(spec.Path)}},
+		},
+		Route: routeDestinations(spec),
+	}
 
 	if spec.Rewrite != nil && urls.CanonicalizeEndpoint(*spec.Rewrite) != urls.CanonicalizeEndpoint(spec.Path) {
-		httpSpec["rewrite"] = map[string]interface{}{
-			"uri": urls.CanonicalizeEndpoint(*spec.Rewrite),
+		route.Rewrite = &IstioHTTPRewrite{URI: urls.CanonicalizeEndpoint(*spec.Rewrite)}
+	}
+
+	if spec.CORSPolicy != nil {
+		route.CORSPolicy = istioCORSPolicy(spec.CORSPolicy)
+	}
+
+	if spec.WebSocket {
+		route.Timeout = "0s"
+	} else if spec.Timeout != 0 {
+		route.Timeout = istioDuration(spec.Timeout)
+	}
+
+	if spec.Retries != nil {
+		route.Retries = istioHTTPRetry(spec.Retries)
+	}
+
+	return route
+}
+
+func routeDestinations(spec *VirtualServiceSpec) []IstioHTTPRouteDestination {
+	destination := IstioHTTPRouteDestination{
+		Destination: IstioDestination{
+			Host: spec.ServiceName,
+			Port: IstioPortSelector{Number: spec.ServicePort},
+		},
+	}
+
+	if spec.Canary == nil {
+		return []IstioHTTPRouteDestination{destination}
+	}
+
+	destination.Weight = 100 - spec.Canary.Weight
+	canaryDestination := IstioHTTPRouteDestination{
+		Destination: IstioDestination{
+			Host: spec.Canary.ServiceName,
+			Port: IstioPortSelector{Number: spec.ServicePort},
+		},
+		Weight: spec.Canary.Weight,
+	}
+
+	return []IstioHTTPRouteDestination{destination, canaryDestination}
+}
+
+func istioHTTPRetry(retryPolicy *RetryPolicy) *IstioHTTPRetry {
+	retryOn := append([]string{}, _retryOnConnectionFailures...)
+	for _, statusCode := range retryPolicy.StatusCodes {
+		retryOn = append(retryOn, strconv.Itoa(int(statusCode)))
+	}
+
+	istioRetry := &IstioHTTPRetry{
+		Attempts: retryPolicy.Attempts,
+		RetryOn:  strings.Join(retryOn, ","),
+	}
+	if retryPolicy.PerTryTimeout != 0 {
+		istioRetry.PerTryTimeout = istioDuration(retryPolicy.PerTryTimeout)
+	}
+	return istioRetry
+}
+
+func istioCORSPolicy(corsPolicy *CORSPolicy) *IstioCORSPolicy {
+	allowOrigins := make([]IstioStringMatch, len(corsPolicy.AllowOrigins))
+	for i, origin := range corsPolicy.AllowOrigins {
+		if origin == "*" {
+			allowOrigins[i] = IstioStringMatch{Regex: ".*"}
+		} else {
+			allowOrigins[i] = IstioStringMatch{Exact: origin}
 		}
 	}
 
-	virtualServiceConfig.Object["spec"] = map[string]interface{}{
-		"hosts":    []string{"*"},
-		"gateways": spec.Gateways,
-		"http":     []map[string]interface{}{httpSpec},
+	istioCORSPolicy := &IstioCORSPolicy{
+		AllowOrigins: allowOrigins,
+		AllowMethods: corsPolicy.AllowMethods,
+		AllowHeaders: corsPolicy.AllowHeaders,
 	}
 
-	return virtualServiceConfig
+	if corsPolicy.MaxAge != 0 {
+		istioCORSPolicy.MaxAge = istioDuration(corsPolicy.MaxAge)
+	}
+
+	return istioCORSPolicy
 }
 
 func (c *Client) CreateVirtualService(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	istio := c.IstioNetworkingAPI()
+	spec.SetGroupVersionKind(istio.VirtualServiceGVK())
 	virtualService, err := c.dynamicClient.
-		Resource(virtualServiceGVR).
+		Resource(istio.VirtualServiceGVR()).
 		Namespace(spec.GetNamespace()).
 		Create(spec, kmeta.CreateOptions{
-			TypeMeta: virtualServiceTypeMeta,
+			TypeMeta: istioTypeMeta(istio.VirtualServiceGVK()),
 		})
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -119,11 +221,13 @@ func (c *Client) CreateVirtualService(spec *kunstructured.Unstructured) (*kunstr
 }
 
 func (c *Client) updateVirtualService(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	istio := c.IstioNetworkingAPI()
+	spec.SetGroupVersionKind(istio.VirtualServiceGVK())
 	virtualService, err := c.dynamicClient.
-		Resource(virtualServiceGVR).
+		Resource(istio.VirtualServiceGVR()).
 		Namespace(spec.GetNamespace()).
 		Update(spec, kmeta.UpdateOptions{
-			TypeMeta: virtualServiceTypeMeta,
+			TypeMeta: istioTypeMeta(istio.VirtualServiceGVK()),
 		})
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -132,6 +236,8 @@ func (c *Client) updateVirtualService(spec *kunstructured.Unstructured) (*kunstr
 }
 
 func (c *Client) ApplyVirtualService(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	SetSpecHashAnnotation(spec, VirtualServiceSpecHash(spec))
+
 	existing, err := c.GetVirtualService(spec.GetName(), spec.GetNamespace())
 	if err != nil {
 		return nil, err
@@ -144,8 +250,9 @@ func (c *Client) ApplyVirtualService(spec *kunstructured.Unstructured) (*kunstru
 }
 
 func (c *Client) GetVirtualService(name, namespace string) (*kunstructured.Unstructured, error) {
-	virtualService, err := c.dynamicClient.Resource(virtualServiceGVR).Namespace(namespace).Get(name, kmeta.GetOptions{
-		TypeMeta: virtualServiceTypeMeta,
+	istio := c.IstioNetworkingAPI()
+	virtualService, err := c.dynamicClient.Resource(istio.VirtualServiceGVR()).Namespace(namespace).Get(name, kmeta.GetOptions{
+		TypeMeta: istioTypeMeta(istio.VirtualServiceGVK()),
 	})
 
 	if kerrors.IsNotFound(err) {
@@ -166,8 +273,9 @@ func (c *Client) VirtualServiceExists(name, namespace string) (bool, error) {
 }
 
 func (c *Client) DeleteVirtualService(name, namespace string) (bool, error) {
-	err := c.dynamicClient.Resource(virtualServiceGVR).Namespace(namespace).Delete(name, &kmeta.DeleteOptions{
-		TypeMeta: virtualServiceTypeMeta,
+	istio := c.IstioNetworkingAPI()
+	err := c.dynamicClient.Resource(istio.VirtualServiceGVR()).Namespace(namespace).Delete(name, &kmeta.DeleteOptions{
+		TypeMeta: istioTypeMeta(istio.VirtualServiceGVK()),
 	})
 	if kerrors.IsNotFound(err) {
 		return false, nil
@@ -183,17 +291,21 @@ func (c *Client) ListVirtualServices(namespace string, opts *kmeta.ListOptions)
 		opts = &kmeta.ListOptions{}
 	}
 
-	vsList, err := c.dynamicClient.Resource(virtualServiceGVR).Namespace(namespace).List(*opts)
+	istio := c.IstioNetworkingAPI()
+	vsList, err := c.dynamicClient.Resource(istio.VirtualServiceGVR()).Namespace(namespace).List(*opts)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 	for i := range vsList.Items {
-		vsList.Items[i].SetGroupVersionKind(virtualServiceGVK)
+		vsList.Items[i].SetGroupVersionKind(istio.VirtualServiceGVK())
 	}
 	return vsList.Items, nil
 }
 
 func (c *Client) ListVirtualServicesByLabels(namespace string, labels map[string]string) ([]kunstructured.Unstructured, error) {
+	if c.informerCache != nil && c.informerCache.virtualServices != nil && namespace == c.Namespace {
+		return c.informerCache.listVirtualServices(labels)
+	}
 	opts := &kmeta.ListOptions{
 		LabelSelector: LabelSelector(labels),
 	}
@@ -203,94 +315,3 @@ func (c *Client) ListVirtualServicesByLabels(namespace string, labels map[string
 func (c *Client) ListVirtualServicesByLabel(namespace string, labelKey string, labelValue string) ([]kunstructured.Unstructured, error) {
 	return c.ListVirtualServicesByLabels(namespace, map[string]string{labelKey: labelValue})
 }
-
-func GetVirtualServiceGateways(virtualService *kunstructured.Unstructured) (strset.Set, error) {
-	spec, ok := virtualService.UnstructuredContent()["spec"].(map[string]interface{})
-	if !ok {
-		return nil, errors.New("virtual service spec is not a map[string]interface{}") // unexpected
-	}
-
-	gatewaysInterface, ok := spec["gateways"]
-	if !ok {
-		return strset.New(), nil
-	}
-	gateways, ok := gatewaysInterface.([]interface{})
-	if !ok {
-		return nil, errors.New("gateways is not a []interface{}") // unexpected
-	}
-
-	gatewayStrs := strset.New()
-
-	for _, gatewayInterface := range gateways {
-		gateway, ok := gatewayInterface.(string)
-		if !ok {
-			return nil, errors.New("gateway is not a string") // unexpected
-		}
-		gatewayStrs.Add(gateway)
-	}
-
-	return gatewayStrs, nil
-}
-
-func GetVirtualServiceEndpoints(virtualService *kunstructured.Unstructured) (strset.Set, error) {
-	spec, ok := virtualService.UnstructuredContent()["spec"].(map[string]interface{})
-	if !ok {
-		return nil, errors.New("virtual service spec is not a map[string]interface{}") // unexpected
-	}
-
-	httpConfigsInterface, ok := spec["http"]
-	if !ok {
-		return strset.New("/"), nil
-	}
-	httpConfigs, ok := httpConfigsInterface.([]interface{})
-	if !ok {
-		return nil, errors.New("http is not a []interface{}") // unexpected
-	}
-
-	endpoints := strset.New()
-
-	for _, httpConfigInterface := range httpConfigs {
-		httpConfig, ok := httpConfigInterface.(map[string]interface{})
-		if !ok {
-			return nil, errors.New("http item is not a map[string]interface{}") // unexpected
-		}
-
-		matchesInterface, ok := httpConfig["match"]
-		if !ok {
-			return strset.New("/"), nil
-		}
-		matches, ok := matchesInterface.([]interface{})
-		if !ok {
-			return nil, errors.New("match is not a []interface{}") // unexpected
-		}
-
-		for _, matchInterface := range matches {
-			match, ok := matchInterface.(map[string]interface{})
-			if !ok {
-				return nil, errors.New("match item is not a map[string]interface{}") // unexpected
-			}
-
-			uriInterface, ok := match["uri"]
-			if !ok {
-				return strset.New("/"), nil
-			}
-			uri, ok := uriInterface.(map[string]interface{})
-			if !ok {
-				return nil, errors.New("uri is not a map[string]interface{}") // unexpected
-			}
-
-			exactInferface, ok := uri["exact"]
-			if !ok {
-				return strset.New("/"), nil
-			}
-			exact, ok := exactInferface.(string)
-			if !ok {
-				return nil, errors.New("url is not a string") // unexpected
-			}
-
-			endpoints.Add(urls.CanonicalizeEndpoint(exact))
-		}
-	}
-
-	return endpoints, nil
-}
@@ -0,0 +1,135 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+)
+
+func TestHTTPRoute(t *testing.T) {
+	spec := &VirtualServiceSpec{
+		ServiceName: "api",
+		ServicePort: 8888,
+		Path:        "/deployment/api/",
+		Rewrite:     pointer.String("predict"),
+	}
+
+	route := HTTPRoute(spec)
+	require.Equal(t, "/deployment/api", route.Match[0].URI.Exact)
+	require.Equal(t, "/predict", route.Rewrite.URI)
+	require.Equal(t, []IstioHTTPRouteDestination{
+		{Destination: IstioDestination{Host: "api", Port: IstioPortSelector{Number: 8888}}},
+	}, route.Route)
+	require.Nil(t, route.CORSPolicy)
+	require.Empty(t, route.Timeout)
+	require.Nil(t, route.Retries)
+
+	spec.Rewrite = pointer.String("/deployment/api")
+	spec.Canary = &CanaryRoute{ServiceName: "api-canary", Weight: 20}
+	spec.CORSPolicy = &CORSPolicy{
+		AllowOrigins: []string{"*", "https://example.com"},
+		MaxAge:       time.Minute,
+	}
+	spec.WebSocket = true
+
+	route = HTTPRoute(spec)
+	require.Nil(t, route.Rewrite)
+	require.Equal(t, []IstioHTTPRouteDestination{
+		{Destination: IstioDestination{Host: "api", Port: IstioPortSelector{Number: 8888}}, Weight: 80},
+		{Destination: IstioDestination{Host: "api-canary", Port: IstioPortSelector{Number: 8888}}, Weight: 20},
+	}, route.Route)
+	require.Equal(t, []IstioStringMatch{{Regex: ".*"}, {Exact: "https://example.com"}}, route.CORSPolicy.AllowOrigins)
+	require.Equal(t, "60s", route.CORSPolicy.MaxAge)
+	require.Equal(t, "0s", route.Timeout)
+
+	spec.WebSocket = false
+	spec.Timeout = time.Minute
+	spec.Retries = &RetryPolicy{
+		Attempts:      3,
+		PerTryTimeout: 1500 * time.Millisecond,
+		StatusCodes:   []int32{502, 503},
+	}
+
+	route = HTTPRoute(spec)
+	require.Equal(t, "60s", route.Timeout)
+	require.Equal(t, &IstioHTTPRetry{
+		Attempts:      3,
+		PerTryTimeout: "1.5s",
+		RetryOn:       "connect-failure,refused-stream,reset,502,503",
+	}, route.Retries)
+}
+
+func TestHTTPRoutes(t *testing.T) {
+	spec := &VirtualServiceSpec{
+		ServiceName: "api",
+		ServicePort: 8888,
+		Path:        "/deployment/api/",
+		Rewrite:     pointer.String("predict"),
+		Timeout:     time.Minute,
+	}
+	require.Len(t, HTTPRoutes(spec), 1)
+
+	spec.Subpaths = []string{"explain"}
+	routes := HTTPRoutes(spec)
+	require.Len(t, routes, 2)
+	require.Equal(t, "/deployment/api", routes[0].Match[0].URI.Exact)
+	require.Equal(t, "/predict", routes[0].Rewrite.URI)
+	require.Equal(t, "/deployment/api/explain", routes[1].Match[0].URI.Exact)
+	require.Equal(t, "/explain", routes[1].Rewrite.URI)
+	require.Equal(t, "60s", routes[1].Timeout)
+	require.Equal(t, strset.New("/deployment/api", "/deployment/api/explain"), IstioVirtualServiceFromSpec(spec).Endpoints())
+}
+
+func TestVirtualServiceRoundTrip(t *testing.T) {
+	spec := &VirtualServiceSpec{
+		Name:        "api",
+		Namespace:   "default",
+		Gateways:    []string{"apis-gateway"},
+		ServiceName: "api",
+		ServicePort: 8888,
+		Path:        "/api",
+		Canary:      &CanaryRoute{ServiceName: "api-canary", Weight: 10},
+		Labels:      map[string]string{"apiName": "api"},
+	}
+
+	virtualService, err := ToIstioVirtualService(VirtualService(spec))
+	require.NoError(t, err)
+	require.Equal(t, IstioVirtualServiceFromSpec(spec), virtualService)
+	require.Equal(t, strset.New("apis-gateway"), virtualService.GatewaySet())
+	require.Equal(t, strset.New("/api"), virtualService.Endpoints())
+}
+
+func TestEndpoints(t *testing.T) {
+	virtualService := &IstioVirtualService{}
+	require.Equal(t, strset.New("/"), virtualService.Endpoints())
+
+	virtualService.Spec.HTTP = []IstioHTTPRoute{
+		{Match: []IstioHTTPMatchRequest{{URI: &IstioStringMatch{Exact: "/a/"}}, {URI: &IstioStringMatch{Exact: "b"}}}},
+	}
+	require.Equal(t, strset.New("/a", "/b"), virtualService.Endpoints())
+
+	virtualService.Spec.HTTP = append(virtualService.Spec.HTTP, IstioHTTPRoute{
+		Match: []IstioHTTPMatchRequest{{URI: &IstioStringMatch{Prefix: "/c"}}},
+	})
+	require.Equal(t, strset.New("/"), virtualService.Endpoints())
+}
@@ -35,3 +35,60 @@ func EmptyDirVolumeMount(volumeName string, mountPath string) kcore.VolumeMount
 		MountPath: mountPath,
 	}
 }
+
+func NFSVolume(volumeName string, server string, path string, readOnly bool) kcore.Volume {
+	return kcore.Volume{
+		Name: volumeName,
+		VolumeSource: kcore.VolumeSource{
+			NFS: &kcore.NFSVolumeSource{
+				Server:   server,
+				Path:     path,
+				ReadOnly: readOnly,
+			},
+		},
+	}
+}
+
+func CSIVolume(volumeName string, driver string, attributes map[string]string, readOnly bool) kcore.Volume {
+	return kcore.Volume{
+		Name: volumeName,
+		VolumeSource: kcore.VolumeSource{
+			CSI: &kcore.CSIVolumeSource{
+				Driver:           driver,
+				VolumeAttributes: attributes,
+				ReadOnly:         &readOnly,
+			},
+		},
+	}
+}
+
+func ConfigMapVolume(volumeName string, configMapName string) kcore.Volume {
+	return kcore.Volume{
+		Name: volumeName,
+		VolumeSource: kcore.VolumeSource{
+			ConfigMap: &kcore.ConfigMapVolumeSource{
+				LocalObjectReference: kcore.LocalObjectReference{
+					Name: configMapName,
+				},
+			},
+		},
+	}
+}
+
+func VolumeMount(volumeName string, mountPath string, readOnly bool) kcore.VolumeMount {
+	return kcore.VolumeMount{
+		Name:      volumeName,
+		MountPath: mountPath,
+		ReadOnly:  readOnly,
+	}
+}
+
+// SubPathVolumeMount mounts a file or directory within the volume
+func SubPathVolumeMount(volumeName string, mountPath string, subPath string, readOnly bool) kcore.VolumeMount {
+	return kcore.VolumeMount{
+		Name:      volumeName,
+		MountPath: mountPath,
+		SubPath:   subPath,
+		ReadOnly:  readOnly,
+	}
+}
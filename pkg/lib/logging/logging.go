@@ -0,0 +1,217 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+type Level int32
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+// Levels are the names of the log levels, in increasing order of severity
+var Levels = []string{
+	"debug",
+	"info",
+	"warn",
+	"error",
+}
+
+var _ = [1]int{}[int(ErrorLevel)-(len(Levels)-1)] // Ensure list length matches
+
+func (level Level) String() string {
+	return Levels[level]
+}
+
+func ParseLevel(str string) (Level, error) {
+	for i, level := range Levels {
+		if strings.ToLower(str) == level {
+			return Level(i), nil
+		}
+	}
+	return InfoLevel, ErrorInvalidLevel(str)
+}
+
+// Fields are added to each log entry as top-level JSON keys
+type Fields map[string]interface{}
+
+var (
+	_level            = int32(InfoLevel)
+	_out    io.Writer = os.Stdout
+	_outMux sync.Mutex
+)
+
+// SetLevel sets the minimum level of the entries which are logged (it's safe to call while logging)
+func SetLevel(level Level) {
+	atomic.StoreInt32(&_level, int32(level))
+}
+
+func GetLevel() Level {
+	return Level(atomic.LoadInt32(&_level))
+}
+
+// SetOutput sets where entries are written (stdout if out is nil)
+func SetOutput(out io.Writer) {
+	if out == nil {
+		out = os.Stdout
+	}
+	_outMux.Lock()
+	defer _outMux.Unlock()
+	_out = out
+}
+
+// Logger writes JSON log entries (one per line) which include its fields
+type Logger struct {
+	fields Fields
+}
+
+var _root = &Logger{}
+
+// With returns a logger whose entries include the fields (in addition to the logger's fields)
+func (logger *Logger) With(fields Fields) *Logger {
+	merged := make(Fields, len(logger.fields)+len(fields))
+	for key, value := range logger.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return &Logger{fields: merged}
+}
+
+func (logger *Logger) Debug(msg string) {
+	logger.log(DebugLevel, msg, nil)
+}
+
+func (logger *Logger) Debugf(format string, args ...interface{}) {
+	logger.log(DebugLevel, fmt.Sprintf(format, args...), nil)
+}
+
+func (logger *Logger) Info(msg string) {
+	logger.log(InfoLevel, msg, nil)
+}
+
+func (logger *Logger) Infof(format string, args ...interface{}) {
+	logger.log(InfoLevel, fmt.Sprintf(format, args...), nil)
+}
+
+func (logger *Logger) Warn(msg string) {
+	logger.log(WarnLevel, msg, nil)
+}
+
+func (logger *Logger) Warnf(format string, args ...interface{}) {
+	logger.log(WarnLevel, fmt.Sprintf(format, args...), nil)
+}
+
+// Error logs the error (wrapped with strs, like errors.PrintError), and its kind if it has one
+func (logger *Logger) Error(err error, strs ...string) {
+	wrappedErr := errors.Wrap(err, strs...)
+	logger.log(ErrorLevel, wrappedErr.Error(), wrappedErr)
+}
+
+func (logger *Logger) log(level Level, msg string, err error) {
+	if level < GetLevel() {
+		return
+	}
+
+	entry := make(map[string]interface{}, len(logger.fields)+4)
+	for key, value := range logger.fields {
+		entry[key] = value
+	}
+	entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = level.String()
+	entry["msg"] = msg
+	if err != nil {
+		if kind := errors.Kind(err); kind != "" && kind != "err_unknown" {
+			entry["error_kind"] = kind
+		}
+	}
+
+	entryBytes, marshalErr := json.Marshal(entry)
+	if marshalErr != nil {
+		entryBytes, _ = json.Marshal(map[string]interface{}{"level": level.String(), "msg": msg, "log_error": marshalErr.Error()})
+	}
+
+	_outMux.Lock()
+	defer _outMux.Unlock()
+	_out.Write(append(entryBytes, '\n'))
+}
+
+// With returns a logger whose entries include the fields
+func With(fields Fields) *Logger {
+	return _root.With(fields)
+}
+
+func Debug(msg string) {
+	_root.log(DebugLevel, msg, nil)
+}
+
+func Debugf(format string, args ...interface{}) {
+	_root.log(DebugLevel, fmt.Sprintf(format, args...), nil)
+}
+
+func Info(msg string) {
+	_root.log(InfoLevel, msg, nil)
+}
+
+func Infof(format string, args ...interface{}) {
+	_root.log(InfoLevel, fmt.Sprintf(format, args...), nil)
+}
+
+func Warn(msg string) {
+	_root.log(WarnLevel, msg, nil)
+}
+
+func Warnf(format string, args ...interface{}) {
+	_root.log(WarnLevel, fmt.Sprintf(format, args...), nil)
+}
+
+// PrintError logs the error (it's the structured replacement for errors.PrintError)
+func PrintError(err error, strs ...string) {
+	_root.Error(err, strs...)
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of the context which carries the logger
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger which the context carries (or a logger without fields if it doesn't carry one)
+func FromContext(ctx context.Context) *Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return logger
+	}
+	return _root
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+func captureEntries(t *testing.T, fn func()) []map[string]interface{} {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(nil)
+
+	fn()
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLoggerFields(t *testing.T) {
+	entries := captureEntries(t, func() {
+		logger := With(Fields{"request_id": "abc"}).With(Fields{"api": "iris"})
+		logger.Infof("deployed %s", "iris")
+		logger.Error(errors.New("failed"), "iris")
+	})
+
+	require.Len(t, entries, 2)
+	require.Equal(t, "info", entries[0]["level"])
+	require.Equal(t, "deployed iris", entries[0]["msg"])
+	require.Equal(t, "abc", entries[0]["request_id"])
+	require.Equal(t, "iris", entries[0]["api"])
+	require.NotEmpty(t, entries[0]["time"])
+
+	require.Equal(t, "error", entries[1]["level"])
+	require.Equal(t, "iris: failed", entries[1]["msg"])
+}
+
+func TestSetLevel(t *testing.T) {
+	defer SetLevel(InfoLevel)
+
+	entries := captureEntries(t, func() {
+		Debug("hidden")
+		SetLevel(DebugLevel)
+		Debug("shown")
+		SetLevel(ErrorLevel)
+		Warn("hidden")
+	})
+
+	require.Len(t, entries, 1)
+	require.Equal(t, "shown", entries[0]["msg"])
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("WARN")
+	require.NoError(t, err)
+	require.Equal(t, WarnLevel, level)
+
+	_, err = ParseLevel("verbose")
+	require.Error(t, err)
+	require.Equal(t, ErrInvalidLevel.String(), errors.Kind(err))
+}
+
+func TestContext(t *testing.T) {
+	logger := With(Fields{"request_id": "abc"})
+	require.Equal(t, logger, FromContext(NewContext(context.Background(), logger)))
+	require.Equal(t, _root, FromContext(context.Background()))
+}
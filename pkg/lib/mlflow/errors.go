@@ -0,0 +1,136 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mlflow
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+type ErrorKind int
+
+const (
+	ErrUnknown ErrorKind = iota
+	ErrInvalidModelURI
+	ErrRegistryUnreachable
+	ErrRegistryUnauthorized
+	ErrRegistryRequest
+	ErrNoModelVersionInStage
+	ErrMalformedMLmodel
+)
+
+var errorKinds = []string{
+	"err_unknown",
+	"err_invalid_model_uri",
+	"err_registry_unreachable",
+	"err_registry_unauthorized",
+	"err_registry_request",
+	"err_no_model_version_in_stage",
+	"err_malformed_mlmodel",
+}
+
+var _ = [1]int{}[int(ErrMalformedMLmodel)-(len(errorKinds)-1)] // Ensure list length matches
+
+func (t ErrorKind) String() string {
+	return errorKinds[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t ErrorKind) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *ErrorKind) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(errorKinds); i++ {
+		if enum == errorKinds[i] {
+			*t = ErrorKind(i)
+			return nil
+		}
+	}
+
+	*t = ErrUnknown
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *ErrorKind) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t ErrorKind) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+type Error struct {
+	Kind    ErrorKind
+	message string
+}
+
+func (e Error) Error() string {
+	return e.message
+}
+
+func ErrorInvalidModelURI(uri string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidModelURI,
+		message: fmt.Sprintf("%s is not a valid MLflow model (expected %s<model name>/<stage or version>, where the stage is %s, e.g. %smy-model/Production)", uri, ModelURIScheme, strings.Join(Stages, ", "), ModelURIScheme),
+	})
+}
+
+func ErrorRegistryUnreachable(trackingURI string, err error) error {
+	return errors.WithStack(Error{
+		Kind:    ErrRegistryUnreachable,
+		message: fmt.Sprintf("unable to reach the MLflow tracking server at %s: %s", trackingURI, err.Error()),
+	})
+}
+
+func ErrorRegistryUnauthorized(trackingURI string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrRegistryUnauthorized,
+		message: fmt.Sprintf("the MLflow tracking server at %s did not accept the cluster's credentials", trackingURI),
+	})
+}
+
+func ErrorRegistryRequest(status string, errorCode string, message string) error {
+	if errorCode != "" {
+		message = errorCode + ": " + message
+	}
+	return errors.WithStack(Error{
+		Kind:    ErrRegistryRequest,
+		message: fmt.Sprintf("the MLflow model registry responded with status %s (%s)", status, message),
+	})
+}
+
+func ErrorNoModelVersionInStage(modelName string, stage string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrNoModelVersionInStage,
+		message: fmt.Sprintf("none of the versions of the %s model are in the %s stage", modelName, stage),
+	})
+}
+
+func ErrorMalformedMLmodel(path string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrMalformedMLmodel,
+		message: fmt.Sprintf("%s: the MLmodel file does not define any flavors", path),
+	})
+}
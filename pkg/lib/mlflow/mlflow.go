@@ -0,0 +1,240 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mlflow
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/yaml"
+)
+
+// ModelURIScheme prefixes references to models in an MLflow model registry (e.g. mlflow://my-model/Production or mlflow://my-model/3)
+const ModelURIScheme = "mlflow://"
+
+// MLmodelFileName is the file in a model's directory which describes the model's flavors
+const MLmodelFileName = "MLmodel"
+
+// The stages which a version of a registered model can be in
+var Stages = []string{"None", "Staging", "Production", "Archived"}
+
+var _versionRegex = regexp.MustCompile(`^[1-9][0-9]*$`)
+
+// The keys in flavors' configurations which hold the path of the model's data (relative to the model's directory), in order of precedence
+var _flavorDataKeys = []string{"data", "pickled_model", "saved_model_dir"}
+
+var _httpClient = &http.Client{Timeout: 10 * time.Second}
+
+type ModelURI struct {
+	Name    string
+	Stage   string // set if the URI refers to the latest version in a stage
+	Version string // set if the URI refers to a specific version
+}
+
+func IsModelURI(str string) bool {
+	return strings.HasPrefix(str, ModelURIScheme)
+}
+
+func ParseModelURI(str string) (*ModelURI, error) {
+	if !IsModelURI(str) {
+		return nil, ErrorInvalidModelURI(str)
+	}
+
+	// registered model names may contain slashes, so the stage or version follows the last slash
+	path := strings.TrimPrefix(str, ModelURIScheme)
+	lastSlash := strings.LastIndex(path, "/")
+	if lastSlash <= 0 || lastSlash == len(path)-1 {
+		return nil, ErrorInvalidModelURI(str)
+	}
+	name, ref := path[:lastSlash], path[lastSlash+1:]
+
+	if _versionRegex.MatchString(ref) {
+		return &ModelURI{Name: name, Version: ref}, nil
+	}
+	for _, stage := range Stages {
+		if strings.EqualFold(ref, stage) {
+			return &ModelURI{Name: name, Stage: stage}, nil
+		}
+	}
+	return nil, ErrorInvalidModelURI(str)
+}
+
+func (uri *ModelURI) String() string {
+	if uri.Version != "" {
+		return ModelURIScheme + uri.Name + "/" + uri.Version
+	}
+	return ModelURIScheme + uri.Name + "/" + uri.Stage
+}
+
+type Client struct {
+	TrackingURI string // the base URL of the MLflow tracking server which hosts the model registry
+	Token       string // sent as a bearer token if it isn't empty
+}
+
+type ModelVersion struct {
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	CurrentStage string `json:"current_stage"`
+	Source       string `json:"source"` // the URI of the model's directory (e.g. s3://my-bucket/mlflow/0/<run id>/artifacts/model)
+	RunID        string `json:"run_id"`
+}
+
+// ModelVersion returns the version of the registered model which the URI refers to (for stages, the latest version in the stage)
+func (c *Client) ModelVersion(uri *ModelURI) (*ModelVersion, error) {
+	if uri.Version != "" {
+		var response struct {
+			ModelVersion *ModelVersion `json:"model_version"`
+		}
+		query := url.Values{"name": {uri.Name}, "version": {uri.Version}}
+		if err := c.request(http.MethodGet, "model-versions/get", query, nil, &response); err != nil {
+			return nil, err
+		}
+		if response.ModelVersion == nil {
+			return nil, ErrorRegistryRequest("200 OK", "", "the response did not include the model version")
+		}
+		return response.ModelVersion, nil
+	}
+
+	var response struct {
+		ModelVersions []*ModelVersion `json:"model_versions"`
+	}
+	body := map[string]interface{}{"name": uri.Name, "stages": []string{uri.Stage}}
+	if err := c.request(http.MethodPost, "registered-models/get-latest-versions", nil, body, &response); err != nil {
+		return nil, err
+	}
+	if len(response.ModelVersions) == 0 || response.ModelVersions[0] == nil {
+		return nil, ErrorNoModelVersionInStage(uri.Name, uri.Stage)
+	}
+	return response.ModelVersions[0], nil
+}
+
+// Ping checks that the tracking server can be reached, and that it accepts the client's credentials
+func (c *Client) Ping() error {
+	return c.request(http.MethodGet, "registered-models/list", url.Values{"max_results": {"1"}}, nil, nil)
+}
+
+type errorResponse struct {
+	ErrorCode string `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// request calls the endpoint of MLflow's REST API, and parses its response into out (if out isn't nil)
+func (c *Client) request(method string, endpoint string, query url.Values, body interface{}, out interface{}) error {
+	requestURL := strings.TrimSuffix(c.TrackingURI, "/") + "/api/2.0/mlflow/" + endpoint
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		if bodyBytes, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	request, err := http.NewRequest(method, requestURL, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return ErrorRegistryUnreachable(c.TrackingURI, err)
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	response, err := _httpClient.Do(request)
+	if err != nil {
+		return ErrorRegistryUnreachable(c.TrackingURI, err)
+	}
+	defer response.Body.Close()
+
+	responseBytes, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return ErrorRegistryUnreachable(c.TrackingURI, err)
+	}
+
+	if response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
+		return ErrorRegistryUnauthorized(c.TrackingURI)
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		var errResponse errorResponse
+		if json.Unmarshal(responseBytes, &errResponse) != nil || errResponse.Message == "" {
+			errResponse.Message = strings.TrimSpace(string(responseBytes))
+		}
+		return ErrorRegistryRequest(response.Status, errResponse.ErrorCode, errResponse.Message)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(responseBytes, out); err != nil {
+		return errors.Wrap(err, "unable to parse the response of the MLflow model registry")
+	}
+	return nil
+}
+
+type MLmodel struct {
+	ArtifactPath string                            `yaml:"artifact_path"`
+	Flavors      map[string]map[string]interface{} `yaml:"flavors"`
+}
+
+// ParseMLmodel parses the MLmodel file at the path
+func ParseMLmodel(path string, data []byte) (*MLmodel, error) {
+	var mlmodel MLmodel
+	if err := yaml.Unmarshal(data, &mlmodel); err != nil {
+		return nil, errors.Wrap(errors.WithStack(err), path)
+	}
+	if len(mlmodel.Flavors) == 0 {
+		return nil, ErrorMalformedMLmodel(path)
+	}
+	return &mlmodel, nil
+}
+
+// FlavorNames returns the names of the model's flavors, sorted
+func (mlmodel *MLmodel) FlavorNames() []string {
+	names := make([]string, 0, len(mlmodel.Flavors))
+	for name := range mlmodel.Flavors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FlavorDataPath returns the path of the flavor's model data, relative to the model's directory ("" if the data is the model's directory);
+// false is returned if the model doesn't have the flavor
+func (mlmodel *MLmodel) FlavorDataPath(flavor string) (string, bool) {
+	flavorConfig, ok := mlmodel.Flavors[flavor]
+	if !ok {
+		return "", false
+	}
+	for _, key := range _flavorDataKeys {
+		if dataPath, ok := flavorConfig[key].(string); ok && dataPath != "" {
+			return dataPath, true
+		}
+	}
+	return "", true
+}
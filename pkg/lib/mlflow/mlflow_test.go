@@ -0,0 +1,131 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mlflow
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseModelURI(t *testing.T) {
+	uri, err := ParseModelURI("mlflow://my-model/production")
+	require.NoError(t, err)
+	require.Equal(t, &ModelURI{Name: "my-model", Stage: "Production"}, uri)
+	require.Equal(t, "mlflow://my-model/Production", uri.String())
+
+	uri, err = ParseModelURI("mlflow://team/my-model/12")
+	require.NoError(t, err)
+	require.Equal(t, &ModelURI{Name: "team/my-model", Version: "12"}, uri)
+
+	for _, invalid := range []string{"s3://bucket/model", "mlflow://my-model", "mlflow://my-model/", "mlflow:///Production", "mlflow://my-model/Latest", "mlflow://my-model/0"} {
+		_, err := ParseModelURI(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestModelVersion(t *testing.T) {
+	var requests []string
+	var authorization string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		authorization = r.Header.Get("Authorization")
+		body, _ = ioutil.ReadAll(r.Body)
+
+		switch r.URL.Path {
+		case "/api/2.0/mlflow/registered-models/get-latest-versions":
+			w.Write([]byte(`{"model_versions": [{"name": "my-model", "version": "3", "current_stage": "Production", "source": "s3://bucket/artifacts/model"}]}`))
+		case "/api/2.0/mlflow/model-versions/get":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code": "RESOURCE_DOES_NOT_EXIST", "message": "Model Version (name=my-model, version=4) not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{TrackingURI: server.URL + "/", Token: "token"}
+
+	version, err := client.ModelVersion(&ModelURI{Name: "my-model", Stage: "Production"})
+	require.NoError(t, err)
+	require.Equal(t, "3", version.Version)
+	require.Equal(t, "s3://bucket/artifacts/model", version.Source)
+	require.Equal(t, "POST /api/2.0/mlflow/registered-models/get-latest-versions", requests[0])
+	require.JSONEq(t, `{"name": "my-model", "stages": ["Production"]}`, string(body))
+	require.Equal(t, "Bearer token", authorization)
+
+	_, err = client.ModelVersion(&ModelURI{Name: "my-model", Version: "4"})
+	require.Equal(t, ErrRegistryRequest, errors.Cause(err).(Error).Kind)
+	require.Contains(t, err.Error(), "RESOURCE_DOES_NOT_EXIST")
+	require.Equal(t, "GET /api/2.0/mlflow/model-versions/get?name=my-model&version=4", requests[1])
+
+	unauthorizedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer unauthorizedServer.Close()
+
+	err = (&Client{TrackingURI: unauthorizedServer.URL}).Ping()
+	require.Equal(t, ErrRegistryUnauthorized, errors.Cause(err).(Error).Kind)
+}
+
+func TestNoModelVersionInStage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	_, err := (&Client{TrackingURI: server.URL}).ModelVersion(&ModelURI{Name: "my-model", Stage: "Staging"})
+	require.Equal(t, ErrNoModelVersionInStage, errors.Cause(err).(Error).Kind)
+}
+
+func TestFlavorDataPath(t *testing.T) {
+	mlmodel, err := ParseMLmodel("MLmodel", []byte(`
+artifact_path: model
+flavors:
+  python_function:
+    loader_module: mlflow.sklearn
+  sklearn:
+    pickled_model: model.pkl
+    sklearn_version: 0.22.1
+  tensorflow:
+    saved_model_dir: tfmodel
+  custom: {}
+`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"custom", "python_function", "sklearn", "tensorflow"}, mlmodel.FlavorNames())
+
+	dataPath, ok := mlmodel.FlavorDataPath("sklearn")
+	require.True(t, ok)
+	require.Equal(t, "model.pkl", dataPath)
+
+	dataPath, ok = mlmodel.FlavorDataPath("tensorflow")
+	require.True(t, ok)
+	require.Equal(t, "tfmodel", dataPath)
+
+	dataPath, ok = mlmodel.FlavorDataPath("custom")
+	require.True(t, ok)
+	require.Equal(t, "", dataPath)
+
+	_, ok = mlmodel.FlavorDataPath("onnx")
+	require.False(t, ok)
+
+	_, err = ParseMLmodel("MLmodel", []byte("artifact_path: model\n"))
+	require.Error(t, err)
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Spec is an OpenAPI 2.0 (swagger) document
+type Spec struct {
+	Swagger             string                     `json:"swagger"`
+	Info                Info                       `json:"info"`
+	BasePath            string                     `json:"basePath,omitempty"`
+	Consumes            []string                   `json:"consumes,omitempty"`
+	Produces            []string                   `json:"produces,omitempty"`
+	Paths               map[string]PathItem        `json:"paths"`
+	Definitions         map[string]*Schema         `json:"definitions,omitempty"`
+	SecurityDefinitions map[string]*SecurityScheme `json:"securityDefinitions,omitempty"`
+	Security            []map[string][]string      `json:"security,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem maps lowercase HTTP methods to operations
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	OperationID string              `json:"operationId,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Consumes    []string            `json:"consumes,omitempty"`
+	Parameters  []*Parameter        `json:"parameters,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Type        string  `json:"type,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+type Response struct {
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name,omitempty"`
+	In          string `json:"in,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+func New(info Info) *Spec {
+	return &Spec{
+		Swagger:     "2.0",
+		Info:        info,
+		Paths:       map[string]PathItem{},
+		Definitions: map[string]*Schema{},
+	}
+}
+
+// AddOperation adds an operation to the spec for the given method (e.g. "GET") and path (e.g. "/apis/{apiName}")
+func (spec *Spec) AddOperation(method string, path string, operation *Operation) {
+	if spec.Paths[path] == nil {
+		spec.Paths[path] = PathItem{}
+	}
+	spec.Paths[path][strings.ToLower(method)] = operation
+}
+
+// SchemaFor returns the schema of the JSON encoding of v, adding definitions to the spec for named struct types
+func (spec *Spec) SchemaFor(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return spec.schemaForType(reflect.TypeOf(v))
+}
+
+func (spec *Spec) schemaForType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer", Format: "int64"}
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		return &Schema{} // the encoding can't be determined by reflection
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: spec.schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: spec.schemaForType(t.Elem())}
+	case reflect.Struct:
+		return spec.structSchema(t)
+	}
+
+	return &Schema{}
+}
+
+func (spec *Spec) structSchema(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return spec.structProperties(t)
+	}
+
+	name := definitionName(t)
+	ref := &Schema{Ref: "#/definitions/" + name}
+	if _, ok := spec.Definitions[name]; ok {
+		return ref
+	}
+
+	// Add a placeholder first in case the type is recursive
+	spec.Definitions[name] = &Schema{Type: "object"}
+	spec.Definitions[name] = spec.structProperties(t)
+	return ref
+}
+
+func (spec *Spec) structProperties(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	spec.addStructProperties(t, schema.Properties)
+	return schema
+}
+
+func (spec *Spec) addStructProperties(t reflect.Type, properties map[string]*Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+
+		// Embedded structs without a json name are flattened into the parent
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			for fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				spec.addStructProperties(fieldType, properties)
+				continue
+			}
+		}
+
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = spec.schemaForType(field.Type)
+	}
+}
+
+// definitionName qualifies the type's name with its package name (e.g. schema.DeployResponse) to avoid collisions
+func definitionName(t reflect.Type) string {
+	if pkg := path.Base(t.PkgPath()); pkg != "" && pkg != "." {
+		return pkg + "." + t.Name()
+	}
+	return t.Name()
+}
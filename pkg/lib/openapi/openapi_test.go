@@ -0,0 +1,87 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testKind int
+
+func (k testKind) MarshalText() ([]byte, error) {
+	return []byte("kind"), nil
+}
+
+type testBase struct {
+	ID string `json:"id"`
+}
+
+type testNode struct {
+	testBase
+	Name     string            `json:"name"`
+	Count    int32             `json:"count,omitempty"`
+	Ratio    *float64          `json:"ratio"`
+	Kind     testKind          `json:"kind"`
+	Created  time.Time         `json:"created"`
+	Timeout  time.Duration     `json:"timeout"`
+	Data     []byte            `json:"data"`
+	Labels   map[string]string `json:"labels"`
+	Children []*testNode       `json:"children"`
+	Any      interface{}       `json:"any"`
+	Ignored  string            `json:"-"`
+	private  string
+}
+
+func TestSchemaFor(t *testing.T) {
+	spec := New(Info{Title: "test", Version: "1"})
+
+	schema := spec.SchemaFor(&testNode{})
+	require.Equal(t, "#/definitions/openapi.testNode", schema.Ref)
+
+	definition := spec.Definitions["openapi.testNode"]
+	require.NotNil(t, definition)
+	require.Equal(t, "object", definition.Type)
+	require.Len(t, definition.Properties, 11)
+
+	require.Equal(t, &Schema{Type: "string"}, definition.Properties["id"])
+	require.Equal(t, &Schema{Type: "string"}, definition.Properties["name"])
+	require.Equal(t, &Schema{Type: "integer", Format: "int32"}, definition.Properties["count"])
+	require.Equal(t, &Schema{Type: "number", Format: "double"}, definition.Properties["ratio"])
+	require.Equal(t, &Schema{Type: "string"}, definition.Properties["kind"])
+	require.Equal(t, &Schema{Type: "string", Format: "date-time"}, definition.Properties["created"])
+	require.Equal(t, &Schema{Type: "integer", Format: "int64"}, definition.Properties["timeout"])
+	require.Equal(t, &Schema{Type: "string", Format: "byte"}, definition.Properties["data"])
+	require.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, definition.Properties["labels"])
+	require.Equal(t, &Schema{Type: "array", Items: &Schema{Ref: "#/definitions/openapi.testNode"}}, definition.Properties["children"])
+	require.Equal(t, &Schema{}, definition.Properties["any"])
+
+	require.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "string"}}, spec.SchemaFor([]string{}))
+	require.Equal(t, &Schema{}, spec.SchemaFor(nil))
+}
+
+func TestAddOperation(t *testing.T) {
+	spec := New(Info{Title: "test", Version: "1"})
+	spec.AddOperation("GET", "/apis", &Operation{Summary: "list"})
+	spec.AddOperation("POST", "/apis", &Operation{Summary: "create"})
+
+	require.Len(t, spec.Paths["/apis"], 2)
+	require.Equal(t, "list", spec.Paths["/apis"]["get"].Summary)
+	require.Equal(t, "create", spec.Paths["/apis"]["post"].Summary)
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package parallel
 
 import (
+	"sync"
+
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 )
 
@@ -48,6 +50,34 @@ func Run(fns ...func() error) []error {
 	return errors
 }
 
+// RunWithLimit is like Run, but runs at most limit functions at a time (all of them if limit <= 0)
+func RunWithLimit(limit int, fns ...func() error) []error {
+	if len(fns) == 0 {
+		return nil
+	}
+	if limit <= 0 || limit > len(fns) {
+		limit = len(fns)
+	}
+
+	errs := make([]error, len(fns))
+	semaphore := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i := range fns {
+		i := i
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = fns[i]()
+			<-semaphore
+		}()
+	}
+
+	wg.Wait()
+	return errs
+}
+
 func RunFirstErr(fns ...func() error) error {
 	errs := Run(fns...)
 	return errors.FirstError(errs...)
@@ -16,6 +16,16 @@ limitations under the License.
 
 package parallel
 
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
 //
 // These tests must be run and verified manually:
 // go test github.com/cortexlabs/cortex/pkg/lib/parallel -run TestRunInParallel -v
@@ -91,3 +101,39 @@ package parallel
 // 	}
 // 	require.Equal(t, expectedErrs, errs)
 // }
+
+func TestRunWithLimit(t *testing.T) {
+	var running, maxRunning int32
+	fns := make([]func() error, 10)
+	for i := range fns {
+		i := i
+		fns[i] = func() error {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				prevMax := atomic.LoadInt32(&maxRunning)
+				if current <= prevMax || atomic.CompareAndSwapInt32(&maxRunning, prevMax, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			if i%3 == 0 {
+				return errors.New("error " + strconv.Itoa(i))
+			}
+			return nil
+		}
+	}
+
+	errs := RunWithLimit(3, fns...)
+	require.Len(t, errs, 10)
+	require.LessOrEqual(t, maxRunning, int32(3))
+	for i, err := range errs {
+		if i%3 == 0 {
+			require.EqualError(t, err, "error "+strconv.Itoa(i))
+		} else {
+			require.NoError(t, err)
+		}
+	}
+
+	require.Nil(t, RunWithLimit(3))
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pip
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+type ErrorKind int
+
+const (
+	ErrUnknown ErrorKind = iota
+	ErrInvalidRequirement
+	ErrInvalidVersionSpecifier
+)
+
+var errorKinds = []string{
+	"err_unknown",
+	"err_invalid_requirement",
+	"err_invalid_version_specifier",
+}
+
+var _ = [1]int{}[int(ErrInvalidVersionSpecifier)-(len(errorKinds)-1)] // Ensure list length matches
+
+func (t ErrorKind) String() string {
+	return errorKinds[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t ErrorKind) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *ErrorKind) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(errorKinds); i++ {
+		if enum == errorKinds[i] {
+			*t = ErrorKind(i)
+			return nil
+		}
+	}
+
+	*t = ErrUnknown
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *ErrorKind) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t ErrorKind) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+type Error struct {
+	Kind    ErrorKind
+	message string
+}
+
+func (e Error) Error() string {
+	return e.message
+}
+
+func ErrorInvalidRequirement(requirement string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidRequirement,
+		message: fmt.Sprintf("%s is not a valid requirement (e.g. numpy, numpy==1.18.0, or numpy>=1.17,<1.19 are valid)", s.UserStr(requirement)),
+	})
+}
+
+func ErrorInvalidVersionSpecifier(specifier string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidVersionSpecifier,
+		message: fmt.Sprintf("%s is not a valid version specifier (valid operators are ==, !=, <=, >=, <, >, ~=, and ===)", s.UserStr(specifier)),
+	})
+}
@@ -0,0 +1,279 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pip
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var (
+	requirementRegex = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?)\s*(\[[A-Za-z0-9._,\s-]*\])?\s*(.*)$`)
+	specifierRegex   = regexp.MustCompile(`^(~=|===|==|!=|<=|>=|<|>)\s*([A-Za-z0-9.*+!_-]+)$`)
+	directRefRegex   = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*\s*(\[[^\]]*\])?\s*@`)
+	releaseRegex     = regexp.MustCompile(`^v?([0-9]+(\.[0-9]+)*)(\.\*)?$`)
+	nameNormalizer   = regexp.MustCompile(`[-_.]+`)
+)
+
+type Requirement struct {
+	Line       int // line number in the requirements file (1-indexed)
+	Name       string
+	Specifiers []Specifier
+}
+
+type Specifier struct {
+	Operator string
+	Version  string
+}
+
+func (spec Specifier) String() string {
+	return spec.Operator + spec.Version
+}
+
+func (req *Requirement) SpecifiersStr() string {
+	specStrs := make([]string, len(req.Specifiers))
+	for i, spec := range req.Specifiers {
+		specStrs[i] = spec.String()
+	}
+	return strings.Join(specStrs, ",")
+}
+
+// NormalizeName normalizes a package name per PEP 503 (e.g. Flask_API -> flask-api)
+func NormalizeName(name string) string {
+	return strings.ToLower(nameNormalizer.ReplaceAllString(name, "-"))
+}
+
+// ParseRequirements parses the contents of a requirements.txt file. Options (e.g. -r, --index-url),
+// editable installs, and URL / path requirements are not validated and are not included in the result
+func ParseRequirements(contents string) ([]*Requirement, error) {
+	var requirements []*Requirement
+
+	lines := strings.Split(contents, "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNum := i + 1
+		line := stripComment(lines[i])
+
+		// Join continued lines
+		for strings.HasSuffix(line, "\\") && i+1 < len(lines) {
+			i++
+			line = strings.TrimSuffix(line, "\\") + stripComment(lines[i])
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") || (isURLOrPath(line) && !directRefRegex.MatchString(line)) {
+			continue
+		}
+
+		req, err := parseRequirement(line, lineNum)
+		if err != nil {
+			return nil, errors.Wrap(err, "line "+strconv.Itoa(lineNum))
+		}
+		if req != nil {
+			requirements = append(requirements, req)
+		}
+	}
+
+	return requirements, nil
+}
+
+func parseRequirement(line string, lineNum int) (*Requirement, error) {
+	// Environment markers (e.g. `; python_version < "3.7"`) are not evaluated
+	if markerIndex := strings.Index(line, ";"); markerIndex >= 0 {
+		line = strings.TrimSpace(line[:markerIndex])
+	}
+
+	match := requirementRegex.FindStringSubmatch(line)
+	if match == nil {
+		return nil, ErrorInvalidRequirement(line)
+	}
+
+	req := &Requirement{
+		Line: lineNum,
+		Name: match[1],
+	}
+
+	specsStr := strings.TrimSpace(match[4])
+
+	// Direct references (e.g. `name @ https://...`)
+	if strings.HasPrefix(specsStr, "@") {
+		return req, nil
+	}
+
+	if strings.HasPrefix(specsStr, "(") && strings.HasSuffix(specsStr, ")") {
+		specsStr = strings.TrimSpace(specsStr[1 : len(specsStr)-1])
+	}
+	if specsStr == "" {
+		return req, nil
+	}
+
+	for _, specStr := range strings.Split(specsStr, ",") {
+		specStr = strings.TrimSpace(specStr)
+		specMatch := specifierRegex.FindStringSubmatch(specStr)
+		if specMatch == nil {
+			return nil, errors.Wrap(ErrorInvalidVersionSpecifier(specStr), req.Name)
+		}
+		spec := Specifier{Operator: specMatch[1], Version: specMatch[2]}
+		if err := validateSpecifier(spec); err != nil {
+			return nil, errors.Wrap(err, req.Name)
+		}
+		req.Specifiers = append(req.Specifiers, spec)
+	}
+
+	return req, nil
+}
+
+func validateSpecifier(spec Specifier) error {
+	if strings.Contains(spec.Version, "*") {
+		if (spec.Operator != "==" && spec.Operator != "!=") || !strings.HasSuffix(spec.Version, ".*") || strings.Count(spec.Version, "*") != 1 {
+			return ErrorInvalidVersionSpecifier(spec.String())
+		}
+	}
+	if spec.Operator == "~=" {
+		if release, ok := parseRelease(spec.Version); ok && len(release) < 2 {
+			return ErrorInvalidVersionSpecifier(spec.String())
+		}
+	}
+	return nil
+}
+
+// AllowsVersion returns whether the installed version satisfies all of the requirement's specifiers.
+// The second return value is false if this could not be determined (e.g. for pre-release versions)
+func (req *Requirement) AllowsVersion(version string) (bool, bool) {
+	installed, ok := parseRelease(version)
+	if !ok {
+		return false, false
+	}
+
+	for _, spec := range req.Specifiers {
+		if spec.Operator == "===" {
+			if spec.Version != version {
+				return false, true
+			}
+			continue
+		}
+
+		specRelease, ok := parseRelease(spec.Version)
+		if !ok {
+			return false, false
+		}
+
+		var allowed bool
+		switch spec.Operator {
+		case "==":
+			allowed = releaseMatches(installed, specRelease, spec.Version)
+		case "!=":
+			allowed = !releaseMatches(installed, specRelease, spec.Version)
+		case ">=":
+			allowed = compareReleases(installed, specRelease) >= 0
+		case "<=":
+			allowed = compareReleases(installed, specRelease) <= 0
+		case ">":
+			allowed = compareReleases(installed, specRelease) > 0
+		case "<":
+			allowed = compareReleases(installed, specRelease) < 0
+		case "~=":
+			allowed = compareReleases(installed, specRelease) >= 0 && hasReleasePrefix(installed, specRelease[:len(specRelease)-1])
+		}
+
+		if !allowed {
+			return false, true
+		}
+	}
+
+	return true, true
+}
+
+func parseRelease(version string) ([]int, bool) {
+	match := releaseRegex.FindStringSubmatch(version)
+	if match == nil {
+		return nil, false
+	}
+	segments := strings.Split(match[1], ".")
+	release := make([]int, len(segments))
+	for i, segment := range segments {
+		num, err := strconv.Atoi(segment)
+		if err != nil {
+			return nil, false
+		}
+		release[i] = num
+	}
+	return release, true
+}
+
+func releaseMatches(installed []int, specRelease []int, specVersion string) bool {
+	if strings.HasSuffix(specVersion, ".*") {
+		return hasReleasePrefix(installed, specRelease)
+	}
+	return compareReleases(installed, specRelease) == 0
+}
+
+func hasReleasePrefix(release []int, prefix []int) bool {
+	for i, num := range prefix {
+		if releaseSegment(release, i) != num {
+			return false
+		}
+	}
+	return true
+}
+
+// compareReleases returns -1, 0, or 1 (missing segments are treated as 0, e.g. 1.0 == 1.0.0)
+func compareReleases(a []int, b []int) int {
+	length := len(a)
+	if len(b) > length {
+		length = len(b)
+	}
+	for i := 0; i < length; i++ {
+		if releaseSegment(a, i) < releaseSegment(b, i) {
+			return -1
+		}
+		if releaseSegment(a, i) > releaseSegment(b, i) {
+			return 1
+		}
+	}
+	return 0
+}
+
+func releaseSegment(release []int, i int) int {
+	if i < len(release) {
+		return release[i]
+	}
+	return 0
+}
+
+func stripComment(line string) string {
+	if strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return ""
+	}
+	if commentIndex := strings.Index(line, " #"); commentIndex >= 0 {
+		return line[:commentIndex]
+	}
+	if commentIndex := strings.Index(line, "\t#"); commentIndex >= 0 {
+		return line[:commentIndex]
+	}
+	return line
+}
+
+func isURLOrPath(line string) bool {
+	return strings.Contains(line, "://") ||
+		strings.HasPrefix(line, "git+") ||
+		strings.HasPrefix(line, ".") ||
+		strings.HasPrefix(line, "/") ||
+		strings.HasPrefix(line, "~")
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRequirements(t *testing.T) {
+	requirements, err := ParseRequirements(`
+# comment
+numpy
+Flask_API==1.1  # inline comment
+pandas >= 0.25, < 1.0
+requests[security] (>=2.22)
+tensorflow==2.0.*; python_version >= "3.6"
+torch @ https://download.pytorch.org/whl/cpu/torch-1.3.1.whl
+scikit-learn~=0.22.0 \
+    ,!=0.22.1
+
+-r other-requirements.txt
+--extra-index-url https://pypi.example.com
+-e git+https://github.com/cortexlabs/example.git#egg=example
+git+https://github.com/cortexlabs/example.git#egg=example
+./local-package
+`)
+	require.NoError(t, err)
+	require.Len(t, requirements, 7)
+
+	require.Equal(t, "numpy", requirements[0].Name)
+	require.Empty(t, requirements[0].Specifiers)
+	require.Equal(t, 3, requirements[0].Line)
+
+	require.Equal(t, "Flask_API", requirements[1].Name)
+	require.Equal(t, "flask-api", NormalizeName(requirements[1].Name))
+	require.Equal(t, []Specifier{{"==", "1.1"}}, requirements[1].Specifiers)
+
+	require.Equal(t, []Specifier{{">=", "0.25"}, {"<", "1.0"}}, requirements[2].Specifiers)
+	require.Equal(t, ">=0.25,<1.0", requirements[2].SpecifiersStr())
+
+	require.Equal(t, "requests", requirements[3].Name)
+	require.Equal(t, []Specifier{{">=", "2.22"}}, requirements[3].Specifiers)
+
+	require.Equal(t, []Specifier{{"==", "2.0.*"}}, requirements[4].Specifiers)
+
+	require.Equal(t, "torch", requirements[5].Name)
+	require.Empty(t, requirements[5].Specifiers)
+
+	require.Equal(t, "scikit-learn", requirements[6].Name)
+	require.Equal(t, []Specifier{{"~=", "0.22.0"}, {"!=", "0.22.1"}}, requirements[6].Specifiers)
+	require.Equal(t, 9, requirements[6].Line)
+
+	_, err = ParseRequirements("numpy=1.18.0")
+	require.Error(t, err)
+
+	_, err = ParseRequirements("numpy==1.*.0")
+	require.Error(t, err)
+
+	_, err = ParseRequirements("numpy>=1.*")
+	require.Error(t, err)
+
+	_, err = ParseRequirements("numpy~=1")
+	require.Error(t, err)
+
+	_, err = ParseRequirements("numpy 1.18.0")
+	require.Error(t, err)
+
+	_, err = ParseRequirements("$numpy")
+	require.Error(t, err)
+}
+
+func TestAllowsVersion(t *testing.T) {
+	testAllowsVersion := func(requirement string, version string, expectedAllowed bool, expectedOK bool) {
+		t.Helper()
+		requirements, err := ParseRequirements(requirement)
+		require.NoError(t, err)
+		require.Len(t, requirements, 1)
+		allowed, ok := requirements[0].AllowsVersion(version)
+		require.Equal(t, expectedOK, ok, requirement)
+		if ok {
+			require.Equal(t, expectedAllowed, allowed, requirement)
+		}
+	}
+
+	testAllowsVersion("flask", "1.1.1", true, true)
+	testAllowsVersion("flask==1.1.1", "1.1.1", true, true)
+	testAllowsVersion("flask==1.1", "1.1.0", true, true)
+	testAllowsVersion("flask==1.1", "1.1.1", false, true)
+	testAllowsVersion("flask==1.*", "1.1.1", true, true)
+	testAllowsVersion("flask==1.0.*", "1.1.1", false, true)
+	testAllowsVersion("flask!=1.1.1", "1.1.1", false, true)
+	testAllowsVersion("flask!=1.0.*", "1.1.1", true, true)
+	testAllowsVersion("flask>=1.0,<2", "1.1.1", true, true)
+	testAllowsVersion("flask>1.1.1", "1.1.1", false, true)
+	testAllowsVersion("flask<=1.1.1", "1.1.1", true, true)
+	testAllowsVersion("flask<1.1", "1.1.1", false, true)
+	testAllowsVersion("flask~=1.1", "1.1.1", true, true)
+	testAllowsVersion("flask~=1.1", "2.0.0", false, true)
+	testAllowsVersion("flask~=1.0.1", "1.1.1", false, true)
+	testAllowsVersion("flask===1.1.1", "1.1.1", true, true)
+	testAllowsVersion("flask===1.1", "1.1.1", false, true)
+	testAllowsVersion("flask>=2.0.0rc1", "1.1.1", false, false)
+	testAllowsVersion("flask>=1.0", "1.1.1rc1", false, false)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protobuf
+
+// Fields of serialized protocol buffer messages are read without the messages' schemas, so that small fields
+// (e.g. the version of the framework which saved a model) can be read from large files without downloading them
+
+const (
+	WireTypeVarint          = 0
+	WireTypeFixed64         = 1
+	WireTypeLengthDelimited = 2
+	WireTypeFixed32         = 5
+
+	_maxVarintLen = 10
+	_maxHeaderLen = 2 * _maxVarintLen // a field's tag and length
+)
+
+type Field struct {
+	Number   int
+	WireType int
+	Varint   uint64 // the value of varint fields
+	Offset   int64  // the offset of the field's value (after its tag and length) in the message
+	Length   int64  // the length of the field's value (for length-delimited and fixed fields)
+}
+
+// ReadAtFn reads up to length bytes starting at offset (fewer bytes are returned if the data ends first)
+type ReadAtFn func(offset int64, length int64) ([]byte, error)
+
+// ScanFields calls fn with each of the top-level fields of the message (of the given size) until fn returns false,
+// reading only the fields' tags and lengths (and varint values)
+func ScanFields(size int64, readAt ReadAtFn, fn func(Field) (bool, error)) error {
+	var offset int64
+	for offset < size {
+		header, err := readAt(offset, _maxHeaderLen)
+		if err != nil {
+			return err
+		}
+
+		field, headerLen, ok := parseFieldHeader(header)
+		if !ok {
+			return ErrorMalformedMessage(offset)
+		}
+		field.Offset = offset + headerLen
+
+		next := field.Offset + field.Length
+		if next > size {
+			return ErrorMalformedMessage(offset)
+		}
+
+		keepScanning, err := fn(field)
+		if err != nil {
+			return err
+		}
+		if !keepScanning {
+			return nil
+		}
+		offset = next
+	}
+	return nil
+}
+
+// Fields parses the top-level fields of the message; data may be truncated, in which case the last field is
+// returned along with the bytes of its value which are present (and truncated is true)
+func Fields(data []byte) (fields []Field, truncated bool, err error) {
+	readAt := func(offset int64, length int64) ([]byte, error) {
+		end := offset + length
+		if end > int64(len(data)) {
+			end = int64(len(data))
+		}
+		return data[offset:end], nil
+	}
+
+	err = ScanFields(int64(len(data)), readAt, func(field Field) (bool, error) {
+		fields = append(fields, field)
+		return true, nil
+	})
+	if err == nil {
+		return fields, false, nil
+	}
+
+	// the last field may be a length-delimited field which extends past the end of data
+	var offset int64
+	if len(fields) > 0 {
+		lastField := fields[len(fields)-1]
+		offset = lastField.Offset + lastField.Length
+	}
+	field, headerLen, ok := parseFieldHeader(data[offset:])
+	if !ok || field.WireType != WireTypeLengthDelimited {
+		return nil, false, err
+	}
+	field.Offset = offset + headerLen
+	field.Length = int64(len(data)) - field.Offset
+	return append(fields, field), true, nil
+}
+
+// Bytes returns the value of a length-delimited field in the message
+func (field Field) Bytes(data []byte) []byte {
+	end := field.Offset + field.Length
+	if end > int64(len(data)) {
+		end = int64(len(data))
+	}
+	return data[field.Offset:end]
+}
+
+// FieldsByNumber returns the fields with the number
+func FieldsByNumber(fields []Field, number int) []Field {
+	var matches []Field
+	for _, field := range fields {
+		if field.Number == number {
+			matches = append(matches, field)
+		}
+	}
+	return matches
+}
+
+func parseFieldHeader(header []byte) (Field, int64, bool) {
+	tag, tagLen := parseVarint(header)
+	if tagLen == 0 || tag>>3 == 0 {
+		return Field{}, 0, false
+	}
+
+	field := Field{
+		Number:   int(tag >> 3),
+		WireType: int(tag & 7),
+	}
+
+	switch field.WireType {
+	case WireTypeVarint:
+		value, valueLen := parseVarint(header[tagLen:])
+		if valueLen == 0 {
+			return Field{}, 0, false
+		}
+		field.Varint = value
+		return field, int64(tagLen + valueLen), true
+	case WireTypeFixed64:
+		field.Length = 8
+		return field, int64(tagLen), true
+	case WireTypeFixed32:
+		field.Length = 4
+		return field, int64(tagLen), true
+	case WireTypeLengthDelimited:
+		length, lengthLen := parseVarint(header[tagLen:])
+		if lengthLen == 0 || length > 1<<62 {
+			return Field{}, 0, false
+		}
+		field.Length = int64(length)
+		return field, int64(tagLen + lengthLen), true
+	}
+
+	return Field{}, 0, false // groups are deprecated, and aren't used by the messages which are read
+}
+
+// parseVarint returns the varint at the start of data, and its length (0 if data doesn't start with a complete varint)
+func parseVarint(data []byte) (uint64, int) {
+	var value uint64
+	for i := 0; i < len(data) && i < _maxVarintLen; i++ {
+		value |= uint64(data[i]&0x7f) << (7 * uint(i))
+		if data[i] < 0x80 {
+			return value, i + 1
+		}
+	}
+	return 0, 0
+}
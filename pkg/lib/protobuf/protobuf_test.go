@@ -0,0 +1,110 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protobuf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func appendVarint(data []byte, value uint64) []byte {
+	for value >= 0x80 {
+		data = append(data, byte(value)|0x80)
+		value >>= 7
+	}
+	return append(data, byte(value))
+}
+
+func appendVarintField(data []byte, number int, value uint64) []byte {
+	data = appendVarint(data, uint64(number)<<3|WireTypeVarint)
+	return appendVarint(data, value)
+}
+
+func appendBytesField(data []byte, number int, value []byte) []byte {
+	data = appendVarint(data, uint64(number)<<3|WireTypeLengthDelimited)
+	data = appendVarint(data, uint64(len(value)))
+	return append(data, value...)
+}
+
+func TestFields(t *testing.T) {
+	inner := appendBytesField(nil, 5, []byte("1.15.0"))
+	data := appendVarintField(nil, 1, 300)
+	data = appendBytesField(data, 2, inner)
+	data = appendBytesField(data, 2, []byte("second"))
+
+	fields, truncated, err := Fields(data)
+	require.NoError(t, err)
+	require.False(t, truncated)
+	require.Len(t, fields, 3)
+	require.Equal(t, 1, fields[0].Number)
+	require.Equal(t, uint64(300), fields[0].Varint)
+	require.Len(t, FieldsByNumber(fields, 2), 2)
+
+	innerFields, _, err := Fields(fields[1].Bytes(data))
+	require.NoError(t, err)
+	require.Len(t, innerFields, 1)
+	require.Equal(t, "1.15.0", string(innerFields[0].Bytes(fields[1].Bytes(data))))
+	require.Equal(t, "second", string(fields[2].Bytes(data)))
+
+	fields, truncated, err = Fields(data[:len(data)-3])
+	require.NoError(t, err)
+	require.True(t, truncated)
+	require.Len(t, fields, 3)
+	require.Equal(t, "sec", string(fields[2].Bytes(data[:len(data)-3])))
+
+	_, _, err = Fields([]byte{0x08})
+	require.Error(t, err)
+}
+
+func TestScanFields(t *testing.T) {
+	data := appendVarintField(nil, 1, 6)
+	data = appendBytesField(data, 7, make([]byte, 1000))
+	data = appendBytesField(data, 8, appendVarintField(nil, 2, 11))
+
+	var bytesRead int64
+	readAt := func(offset int64, length int64) ([]byte, error) {
+		end := offset + length
+		if end > int64(len(data)) {
+			end = int64(len(data))
+		}
+		bytesRead += end - offset
+		return data[offset:end], nil
+	}
+
+	var numbers []int
+	err := ScanFields(int64(len(data)), readAt, func(field Field) (bool, error) {
+		numbers = append(numbers, field.Number)
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 7, 8}, numbers)
+	require.True(t, bytesRead < 100) // the large field's value isn't read
+
+	numbers = nil
+	err = ScanFields(int64(len(data)), readAt, func(field Field) (bool, error) {
+		numbers = append(numbers, field.Number)
+		return field.Number != 7, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 7}, numbers)
+
+	err = ScanFields(int64(len(data))-1, readAt, func(field Field) (bool, error) {
+		return true, nil
+	})
+	require.Error(t, err)
+}
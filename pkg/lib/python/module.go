@@ -0,0 +1,345 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package python
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+)
+
+var (
+	classRegex      = regexp.MustCompile(`^class\s+([A-Za-z_][A-Za-z0-9_]*)\s*(\(|:)`)
+	defRegex        = regexp.MustCompile(`^(async\s+)?def\s+([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+	importRegex     = regexp.MustCompile(`^import\s+(.+)$`)
+	fromImportRegex = regexp.MustCompile(`^from\s+\S+\s+import\s+(.+)$`)
+	assignmentRegex = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*(:[^=]*)?=[^=]`)
+)
+
+// Module is a shallow summary of a Python source file, which is sufficient to check class and method definitions without running the code
+type Module struct {
+	Classes           map[string]*Class // classes defined at the top level of the module
+	NestedClassNames  strset.Set        // classes defined within other blocks (e.g. in an if or try statement)
+	ImportedNames     strset.Set
+	AssignedNames     strset.Set // names assigned at the top level of the module
+	HasWildcardImport bool
+}
+
+type Class struct {
+	Name    string
+	Line    int // line number of the class statement (1-indexed)
+	Bases   []string
+	Methods map[string]*Function
+}
+
+type Function struct {
+	Name string
+	Line int      // line number of the def statement (1-indexed)
+	Args []string // positional argument names (excludes *args, **kwargs, and keyword-only arguments)
+}
+
+type logicalLine struct {
+	line   int
+	indent int
+	text   string
+}
+
+// ParseModule scans Python source code for top level class definitions and their methods.
+// Parsing is lenient: statements which aren't recognized are ignored.
+func ParseModule(src []byte) *Module {
+	module := &Module{
+		Classes:          map[string]*Class{},
+		NestedClassNames: strset.New(),
+		ImportedNames:    strset.New(),
+		AssignedNames:    strset.New(),
+	}
+
+	lines := logicalLines(string(src))
+
+	var currentClass *Class
+	classBodyIndent := -1
+
+	for _, line := range lines {
+		if line.indent == 0 {
+			currentClass = nil
+			classBodyIndent = -1
+		}
+
+		if match := classRegex.FindStringSubmatch(line.text); match != nil {
+			if line.indent > 0 {
+				module.NestedClassNames.Add(match[1])
+				continue
+			}
+			currentClass = &Class{
+				Name:    match[1],
+				Line:    line.line,
+				Methods: map[string]*Function{},
+			}
+			if match[2] == "(" {
+				for _, base := range splitArgs(enclosed(line.text, strings.Index(line.text, "("))) {
+					if base != "" && !strings.Contains(base, "=") {
+						currentClass.Bases = append(currentClass.Bases, base)
+					}
+				}
+			}
+			module.Classes[currentClass.Name] = currentClass
+			continue
+		}
+
+		module.addImports(line.text)
+
+		if line.indent == 0 {
+			if match := assignmentRegex.FindStringSubmatch(line.text); match != nil {
+				module.AssignedNames.Add(match[1])
+			}
+			continue
+		}
+
+		if currentClass == nil {
+			continue
+		}
+		if classBodyIndent == -1 {
+			classBodyIndent = line.indent
+		}
+		if line.indent != classBodyIndent {
+			continue
+		}
+
+		if match := defRegex.FindStringSubmatch(line.text); match != nil {
+			currentClass.Methods[match[2]] = &Function{
+				Name: match[2],
+				Line: line.line,
+				Args: argNames(enclosed(line.text, strings.Index(line.text, "("))),
+			}
+		}
+	}
+
+	return module
+}
+
+// MayDefine returns true if name could be defined in the module without a top level class statement (e.g. via an import)
+func (module *Module) MayDefine(name string) bool {
+	return module.HasWildcardImport ||
+		module.NestedClassNames.Has(name) ||
+		module.ImportedNames.Has(name) ||
+		module.AssignedNames.Has(name)
+}
+
+func (module *Module) addImports(text string) {
+	var names []string
+	if match := importRegex.FindStringSubmatch(text); match != nil {
+		for _, name := range splitArgs(match[1]) {
+			if asIndex := strings.Index(name, " as "); asIndex != -1 {
+				names = append(names, strings.TrimSpace(name[asIndex+4:]))
+			} else {
+				names = append(names, strings.Split(name, ".")[0])
+			}
+		}
+	} else if match := fromImportRegex.FindStringSubmatch(text); match != nil {
+		imported := strings.Trim(strings.TrimSpace(match[1]), "()")
+		for _, name := range splitArgs(imported) {
+			if name == "*" {
+				module.HasWildcardImport = true
+				continue
+			}
+			if asIndex := strings.Index(name, " as "); asIndex != -1 {
+				name = name[asIndex+4:]
+			}
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+
+	for _, name := range names {
+		if name != "" {
+			module.ImportedNames.Add(name)
+		}
+	}
+}
+
+// logicalLines joins physical lines which are continued (via brackets, backslashes, or multi-line strings),
+// and strips comments and the contents of string literals
+func logicalLines(src string) []logicalLine {
+	var lines []logicalLine
+	var sb strings.Builder
+
+	lineNum := 1
+	startLine := 1
+	indent := 0
+	atLineStart := true
+	depth := 0
+	var quote string
+
+	flush := func() {
+		text := strings.TrimSpace(sb.String())
+		if text != "" {
+			lines = append(lines, logicalLine{line: startLine, indent: indent, text: text})
+		}
+		sb.Reset()
+		depth = 0
+		atLineStart = true
+		indent = 0
+	}
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+
+		if quote != "" {
+			if c == '\\' {
+				if i+1 < len(src) && src[i+1] == '\n' {
+					lineNum++
+				}
+				i++
+				continue
+			}
+			if c == '\n' {
+				lineNum++
+			}
+			if strings.HasPrefix(src[i:], quote) {
+				i += len(quote) - 1
+				quote = ""
+				sb.WriteString(`""`)
+			}
+			continue
+		}
+
+		if atLineStart {
+			switch c {
+			case ' ':
+				indent++
+				continue
+			case '\t':
+				indent += 8 - indent%8
+				continue
+			case '\r', '\f':
+				continue
+			case '\n':
+				indent = 0
+				lineNum++
+				continue
+			case '#':
+				for i < len(src) && src[i] != '\n' {
+					i++
+				}
+				i--
+				continue
+			}
+			atLineStart = false
+			startLine = lineNum
+		}
+
+		switch c {
+		case '#':
+			for i+1 < len(src) && src[i+1] != '\n' {
+				i++
+			}
+		case '\'', '"':
+			quote = string(c)
+			if strings.HasPrefix(src[i:], strings.Repeat(quote, 3)) {
+				quote = strings.Repeat(quote, 3)
+			}
+			i += len(quote) - 1
+		case '\\':
+			if i+1 < len(src) && src[i+1] == '\n' {
+				i++
+				lineNum++
+				sb.WriteByte(' ')
+			}
+		case '(', '[', '{':
+			depth++
+			sb.WriteByte(c)
+		case ')', ']', '}':
+			if depth > 0 {
+				depth--
+			}
+			sb.WriteByte(c)
+		case '\n':
+			lineNum++
+			if depth > 0 {
+				sb.WriteByte(' ')
+			} else {
+				flush()
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	flush()
+
+	return lines
+}
+
+// enclosed returns the contents of the brackets which open at text[start]
+func enclosed(text string, start int) string {
+	if start < 0 || start >= len(text) {
+		return ""
+	}
+	depth := 0
+	for i := start; i < len(text); i++ {
+		switch text[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return text[start+1 : i]
+			}
+		}
+	}
+	return text[start+1:]
+}
+
+// splitArgs splits a comma-separated list on its top level commas
+func splitArgs(text string) []string {
+	var args []string
+	depth := 0
+	last := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(text[last:i]))
+				last = i + 1
+			}
+		}
+	}
+	if remaining := strings.TrimSpace(text[last:]); remaining != "" {
+		args = append(args, remaining)
+	}
+	return args
+}
+
+func argNames(params string) []string {
+	var names []string
+	for _, param := range splitArgs(params) {
+		if strings.HasPrefix(param, "*") {
+			break
+		}
+		if param == "/" {
+			continue
+		}
+		if end := strings.IndexAny(param, ":="); end != -1 {
+			param = param[:end]
+		}
+		names = append(names, strings.TrimSpace(param))
+	}
+	return names
+}
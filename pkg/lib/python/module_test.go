@@ -0,0 +1,105 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package python
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseModule(t *testing.T) {
+	module := ParseModule([]byte(`
+import os, numpy as np
+from sklearn.externals import joblib
+from .labels import (
+    LABELS,
+    Predictor as BasePredictor,
+)
+
+SCALE = 2
+
+
+class PythonPredictor(BasePredictor):
+    """
+    class PythonPredictor:
+        def predict(self, sample):
+    """
+
+    threshold = 0.5  # def ignored(self):
+
+    def __init__(self, config):
+        self.model = joblib.load(os.path.join(config["dir"], "model.pkl"))
+
+    @staticmethod
+    def labels():
+        return LABELS
+
+    def predict(
+        self,
+        payload: dict,
+        query_params={"a": ("b", "c")},
+        *args,
+        **kwargs
+    ) -> str:
+        def helper(x):
+            return x
+        return helper(payload)
+
+
+def predict(payload):
+    pass
+
+if os.environ.get("DEBUG"):
+    class DebugPredictor:
+        def predict(self, payload):
+            pass
+`))
+
+	require.Len(t, module.Classes, 1)
+
+	class := module.Classes["PythonPredictor"]
+	require.NotNil(t, class)
+	require.Equal(t, 12, class.Line)
+	require.Equal(t, []string{"BasePredictor"}, class.Bases)
+	require.Len(t, class.Methods, 3)
+
+	require.Equal(t, 20, class.Methods["__init__"].Line)
+	require.Equal(t, []string{"self", "config"}, class.Methods["__init__"].Args)
+	require.Equal(t, []string(nil), class.Methods["labels"].Args)
+	require.Equal(t, 27, class.Methods["predict"].Line)
+	require.Equal(t, []string{"self", "payload", "query_params"}, class.Methods["predict"].Args)
+
+	require.True(t, module.MayDefine("DebugPredictor"))
+	require.True(t, module.MayDefine("BasePredictor"))
+	require.True(t, module.MayDefine("LABELS"))
+	require.True(t, module.MayDefine("np"))
+	require.True(t, module.MayDefine("os"))
+	require.True(t, module.MayDefine("SCALE"))
+	require.False(t, module.MayDefine("numpy"))
+	require.False(t, module.MayDefine("PythonPredictor"))
+	require.False(t, module.MayDefine("helper"))
+
+	module = ParseModule([]byte("from predictors import *\nclass Predictor: pass\n"))
+	require.Len(t, module.Classes, 1)
+	require.Len(t, module.Classes["Predictor"].Methods, 0)
+	require.True(t, module.MayDefine("PythonPredictor"))
+
+	module = ParseModule([]byte("class PythonPredictor:\n\tdef __init__(self, config, /, extra=None):\n\t\tpass\n\n\tdef predict(self, payload, *, debug=False): pass\n"))
+	require.Equal(t, []string{"self", "config", "extra"}, module.Classes["PythonPredictor"].Methods["__init__"].Args)
+	require.Equal(t, []string{"self", "payload"}, module.Classes["PythonPredictor"].Methods["predict"].Args)
+}
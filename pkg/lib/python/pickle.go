@@ -0,0 +1,47 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package python
+
+import (
+	"bytes"
+)
+
+// The magic numbers of the compression formats which joblib can load pickles from
+var _joblibCompressionMagics = [][]byte{
+	{0x1f, 0x8b},                         // gzip
+	{0x78},                               // zlib
+	[]byte("BZh"),                        // bz2
+	{0xfd, 0x37, 0x7a, 0x58, 0x5a, 0x00}, // xz
+	{0x5d, 0x00, 0x00},                   // lzma
+	{0x04, 0x22, 0x4d, 0x18},             // lz4
+	[]byte("ZF"),                         // joblib's legacy zlib format
+}
+
+// IsPickle returns whether the header is the start of a pickle with protocol 2 or later (which starts with the PROTO opcode)
+func IsPickle(header []byte) bool {
+	return len(header) >= 2 && header[0] == 0x80 && header[1] >= 2 && header[1] <= 5
+}
+
+// IsJoblibCompressed returns whether the header is the start of a file in one of the compression formats which joblib.load() reads
+func IsJoblibCompressed(header []byte) bool {
+	for _, magic := range _joblibCompressionMagics {
+		if bytes.HasPrefix(header, magic) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Config describes how often and how long to wait before retrying a failed call
+type Config struct {
+	MaxAttempts  int           // the maximum number of times to call the function (including the first call)
+	InitialDelay time.Duration // the delay before the first retry (doubled for each subsequent retry)
+	MaxDelay     time.Duration // the maximum delay between attempts
+	Jitter       float64       // the fraction of each delay which is randomized (e.g. 0.5 waits between 50% and 100% of the delay)
+}
+
+// Default is used for calls to AWS and Kubernetes made by the operator
+var Default = Config{
+	MaxAttempts:  5,
+	InitialDelay: 200 * time.Millisecond,
+	MaxDelay:     5 * time.Second,
+	Jitter:       0.5,
+}
+
+// Delay returns how long to wait before the retry following the given attempt (attempts start at 1)
+func (config Config) Delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := config.InitialDelay
+	for i := 1; i < attempt && delay < config.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > config.MaxDelay {
+		delay = config.MaxDelay
+	}
+
+	if config.Jitter > 0 {
+		jitter := config.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+
+	return delay
+}
+
+// Do calls fn until it succeeds, it returns an error which isn't retryable, or config.MaxAttempts is reached (the last error is returned)
+func Do(config Config, isRetryable func(error) bool, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= config.MaxAttempts || !isRetryable(err) {
+			return err
+		}
+		time.Sleep(config.Delay(attempt))
+	}
+}
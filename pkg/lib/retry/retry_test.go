@@ -0,0 +1,78 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var _testConfig = Config{
+	MaxAttempts:  3,
+	InitialDelay: time.Millisecond,
+	MaxDelay:     4 * time.Millisecond,
+}
+
+func TestDelay(t *testing.T) {
+	require.Equal(t, time.Millisecond, _testConfig.Delay(1))
+	require.Equal(t, 2*time.Millisecond, _testConfig.Delay(2))
+	require.Equal(t, 4*time.Millisecond, _testConfig.Delay(3))
+	require.Equal(t, 4*time.Millisecond, _testConfig.Delay(10))
+
+	config := _testConfig
+	config.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := config.Delay(2)
+		require.True(t, delay >= time.Millisecond && delay <= 2*time.Millisecond)
+	}
+}
+
+func TestDo(t *testing.T) {
+	errRetryable := errors.New("retryable")
+	errFatal := errors.New("fatal")
+	isRetryable := func(err error) bool { return err == errRetryable }
+
+	calls := 0
+	err := Do(_testConfig, isRetryable, func() error {
+		calls++
+		if calls < 2 {
+			return errRetryable
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	calls = 0
+	err = Do(_testConfig, isRetryable, func() error {
+		calls++
+		return errRetryable
+	})
+	require.Equal(t, errRetryable, err)
+	require.Equal(t, 3, calls)
+
+	calls = 0
+	err = Do(_testConfig, isRetryable, func() error {
+		calls++
+		return errFatal
+	})
+	require.Equal(t, errFatal, err)
+	require.Equal(t, 1, calls)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sagemaker
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+type ErrorKind int
+
+const (
+	ErrUnknown ErrorKind = iota
+	ErrInvalidModelArtifact
+	ErrNoModelInArtifact
+	ErrMultipleModelsInArtifact
+)
+
+var errorKinds = []string{
+	"err_unknown",
+	"err_invalid_model_artifact",
+	"err_no_model_in_artifact",
+	"err_multiple_models_in_artifact",
+}
+
+var _ = [1]int{}[int(ErrMultipleModelsInArtifact)-(len(errorKinds)-1)] // Ensure list length matches
+
+func (t ErrorKind) String() string {
+	return errorKinds[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t ErrorKind) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *ErrorKind) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(errorKinds); i++ {
+		if enum == errorKinds[i] {
+			*t = ErrorKind(i)
+			return nil
+		}
+	}
+
+	*t = ErrUnknown
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *ErrorKind) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t ErrorKind) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+type Error struct {
+	Kind    ErrorKind
+	message string
+}
+
+func (e Error) Error() string {
+	return e.message
+}
+
+func ErrorInvalidModelArtifact(err error) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidModelArtifact,
+		message: fmt.Sprintf("unable to read the model artifact, which is expected to be a gzipped tar archive (e.g. the model.tar.gz of a SageMaker training job): %s", err.Error()),
+	})
+}
+
+func ErrorNoModelInArtifact() error {
+	return errors.WithStack(Error{
+		Kind:    ErrNoModelInArtifact,
+		message: "the model artifact doesn't contain a TensorFlow SavedModel (saved_model.pb), an ONNX model (.onnx), a pickled or joblib model (.joblib, .pkl, or .pickle), an XGBoost model (xgboost-model, .model, .bst, .xgb, or .json), or a LightGBM model (.txt, .model, or .lgb)",
+	})
+}
+
+func ErrorMultipleModelsInArtifact(paths []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrMultipleModelsInArtifact,
+		message: fmt.Sprintf("the model artifact contains multiple models (%s); it must contain only one", s.UserStrsAnd(paths)),
+	})
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sagemaker
+
+type Framework int
+
+const (
+	UnknownFramework Framework = iota
+	TensorFlowFramework
+	ONNXFramework
+	SKLearnFramework
+	XGBoostFramework
+	LightGBMFramework
+)
+
+// The names match the predictor types which serve each framework's models
+var frameworks = []string{
+	"unknown",
+	"tensorflow",
+	"onnx",
+	"sklearn",
+	"xgboost",
+	"lightgbm",
+}
+
+func FrameworkFromString(s string) Framework {
+	for i := 0; i < len(frameworks); i++ {
+		if s == frameworks[i] {
+			return Framework(i)
+		}
+	}
+	return UnknownFramework
+}
+
+func (t Framework) String() string {
+	return frameworks[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t Framework) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *Framework) UnmarshalText(text []byte) error {
+	*t = FrameworkFromString(string(text))
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *Framework) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t Framework) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sagemaker
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/python"
+)
+
+// ModelArtifactSuffix is the extension of the archives which SageMaker training jobs save their models in (e.g. s3://my-bucket/my-job/output/model.tar.gz)
+const ModelArtifactSuffix = ".tar.gz"
+
+// The number of bytes at the start of each file in the archive which are read to detect its format
+const _headerLen = 16
+
+// The name of the model which SageMaker's built-in XGBoost algorithm saves
+const _xgboostBuiltInModelName = "xgboost-model"
+
+func IsModelArtifact(path string) bool {
+	return strings.HasSuffix(path, ModelArtifactSuffix)
+}
+
+// File is a regular file in a model artifact
+type File struct {
+	Path   string
+	Header []byte // up to the first 16 bytes of the file
+}
+
+// Layout describes how a model artifact's files are arranged for the framework's predictor type
+type Layout struct {
+	Framework Framework         `json:"framework"`
+	ModelPath string            `json:"model_path"` // the path of the model (relative to the layout's root) which the predictor type loads ("" for the layout's root)
+	Files     map[string]string `json:"files"`      // the path of each of the model's files in the archive -> its path relative to the layout's root
+}
+
+// ListFiles reads the gzipped tar archive, and returns its regular files
+func ListFiles(r io.Reader) ([]File, error) {
+	var files []File
+	err := walkArchive(r, func(filePath string, reader io.Reader) error {
+		header := make([]byte, _headerLen)
+		n, err := io.ReadFull(reader, header)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return err
+		}
+		files = append(files, File{Path: filePath, Header: header[:n]})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Extract reads the gzipped tar archive, and calls fn with the path (relative to the layout's root) and contents of each of the layout's files
+func (layout *Layout) Extract(r io.Reader, fn func(path string, data []byte) error) error {
+	return walkArchive(r, func(filePath string, reader io.Reader) error {
+		layoutPath, ok := layout.Files[filePath]
+		if !ok {
+			return nil
+		}
+		var buf bytes.Buffer
+		if _, err := buf.ReadFrom(reader); err != nil {
+			return err
+		}
+		return fn(layoutPath, buf.Bytes())
+	})
+}
+
+// walkArchive calls fn with each regular file in the gzipped tar archive (paths are cleaned, and macOS metadata files are skipped)
+func walkArchive(r io.Reader, fn func(path string, reader io.Reader) error) error {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return ErrorInvalidModelArtifact(err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return ErrorInvalidModelArtifact(err)
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			continue
+		}
+
+		filePath := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if strings.HasPrefix(path.Base(filePath), "._") {
+			continue
+		}
+
+		if err := fn(filePath, tarReader); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+}
+
+// DetectLayout detects the framework of the artifact's model from its files, and arranges the model's files in the layout which the framework's predictor type expects:
+// a TensorFlow SavedModel is placed in a numbered version directory, an ONNX model is named model.onnx, and a model file keeps its name (with an extension which the predictor type supports)
+func DetectLayout(files []File) (*Layout, error) {
+	if layout, err := detectTensorFlowLayout(files); layout != nil || err != nil {
+		return layout, err
+	}
+
+	var candidates []*Layout
+	var candidatePaths []string
+	for _, file := range files {
+		framework, fileName := detectModelFile(file)
+		if framework == UnknownFramework {
+			continue
+		}
+		candidates = append(candidates, &Layout{
+			Framework: framework,
+			ModelPath: fileName,
+			Files:     map[string]string{file.Path: fileName},
+		})
+		candidatePaths = append(candidatePaths, file.Path)
+	}
+
+	if len(candidates) == 0 {
+		return nil, ErrorNoModelInArtifact()
+	}
+	if len(candidates) > 1 {
+		return nil, ErrorMultipleModelsInArtifact(candidatePaths)
+	}
+	return candidates[0], nil
+}
+
+// detectTensorFlowLayout returns nil if the artifact doesn't contain a SavedModel; if it contains multiple versions of a model (in numbered directories), the highest version is used
+func detectTensorFlowLayout(files []File) (*Layout, error) {
+	var exportDirs []string
+	for _, file := range files {
+		if path.Base(file.Path) == "saved_model.pb" {
+			exportDirs = append(exportDirs, path.Dir(file.Path))
+		}
+	}
+	if len(exportDirs) == 0 {
+		return nil, nil
+	}
+
+	exportDir := exportDirs[0]
+	if len(exportDirs) > 1 {
+		sort.Strings(exportDirs)
+		highestVersion := int64(-1)
+		for _, dir := range exportDirs {
+			version, err := strconv.ParseInt(path.Base(dir), 10, 64)
+			if err != nil || path.Dir(dir) != path.Dir(exportDirs[0]) {
+				return nil, ErrorMultipleModelsInArtifact(exportDirs)
+			}
+			if version > highestVersion {
+				highestVersion = version
+				exportDir = dir
+			}
+		}
+	}
+
+	// TensorFlow Serving loads the highest numbered directory in the model's directory
+	versionDir := path.Base(exportDir)
+	if _, err := strconv.ParseInt(versionDir, 10, 64); err != nil {
+		versionDir = "1"
+	}
+
+	layout := &Layout{
+		Framework: TensorFlowFramework,
+		Files:     map[string]string{},
+	}
+	for _, file := range files {
+		if exportDir == "." || strings.HasPrefix(file.Path, exportDir+"/") {
+			relativePath := file.Path
+			if exportDir != "." {
+				relativePath = strings.TrimPrefix(file.Path, exportDir+"/")
+			}
+			layout.Files[file.Path] = path.Join(versionDir, relativePath)
+		}
+	}
+	return layout, nil
+}
+
+// detectModelFile returns the framework which loads the file (UnknownFramework if it isn't a model), and the name which the file is given in the layout
+func detectModelFile(file File) (Framework, string) {
+	name := path.Base(file.Path)
+	ext := strings.ToLower(path.Ext(name))
+	header := file.Header
+
+	if ext == ".onnx" {
+		return ONNXFramework, "model.onnx"
+	}
+
+	isModelName := name == _xgboostBuiltInModelName
+	switch ext {
+	case ".joblib", ".pkl", ".pickle", ".model", ".bst", ".xgb", ".json", ".txt", ".lgb":
+		isModelName = true
+	}
+	if !isModelName {
+		return UnknownFramework, ""
+	}
+
+	// pickles (including the models of SageMaker's built-in XGBoost algorithm before version 1.0) are loaded with joblib
+	if python.IsPickle(header) || python.IsJoblibCompressed(header) {
+		if ext != ".joblib" && ext != ".pkl" && ext != ".pickle" {
+			return SKLearnFramework, name + ".pkl"
+		}
+		return SKLearnFramework, name
+	}
+
+	switch ext {
+	case ".joblib", ".pkl", ".pickle":
+		return UnknownFramework, ""
+	case ".txt", ".lgb":
+		if bytes.HasPrefix(header, []byte("tree")) {
+			return LightGBMFramework, name
+		}
+		return UnknownFramework, ""
+	case ".json":
+		if bytes.HasPrefix(bytes.TrimLeft(header, " \t\r\n"), []byte("{")) && name != "hyperparameters.json" {
+			return XGBoostFramework, name
+		}
+		return UnknownFramework, ""
+	}
+
+	if bytes.HasPrefix(header, []byte("tree")) {
+		return LightGBMFramework, name
+	}
+	if ext == "" {
+		return XGBoostFramework, name + ".bst"
+	}
+	return XGBoostFramework, name
+}
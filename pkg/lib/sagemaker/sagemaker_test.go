@@ -0,0 +1,150 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sagemaker
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/stretchr/testify/require"
+)
+
+var _pickle = []byte{0x80, 0x03, 'c', 's', 'k', 'l', 'e', 'a', 'r', 'n'}
+
+func artifact(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, data := range files {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}))
+		_, err := tarWriter.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+	return buf.Bytes()
+}
+
+func detect(t *testing.T, files map[string][]byte) (*Layout, error) {
+	listed, err := ListFiles(bytes.NewReader(artifact(t, files)))
+	require.NoError(t, err)
+	return DetectLayout(listed)
+}
+
+func TestDetectTensorFlowLayout(t *testing.T) {
+	layout, err := detect(t, map[string][]byte{
+		"export/Servo/1571/saved_model.pb":                       []byte("pb"),
+		"export/Servo/1571/variables/variables.index":            []byte("index"),
+		"export/Servo/1580/saved_model.pb":                       []byte("pb"),
+		"export/Servo/1580/variables/variables.data-00000-of-01": []byte("data"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, TensorFlowFramework, layout.Framework)
+	require.Equal(t, "", layout.ModelPath)
+	require.Equal(t, map[string]string{
+		"export/Servo/1580/saved_model.pb":                       "1580/saved_model.pb",
+		"export/Servo/1580/variables/variables.data-00000-of-01": "1580/variables/variables.data-00000-of-01",
+	}, layout.Files)
+
+	layout, err = detect(t, map[string][]byte{
+		"./saved_model.pb":              []byte("pb"),
+		"./variables/variables.index":   []byte("index"),
+		"./._saved_model.pb":            []byte("macos"),
+		"code/inference.py":             []byte("import json"),
+		"code/requirements.txt":         []byte("numpy"),
+		"./assets/vocab.txt":            []byte("a b c"),
+		"./variables/variables.data-01": []byte("data"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, TensorFlowFramework, layout.Framework)
+	require.Equal(t, "1/saved_model.pb", layout.Files["saved_model.pb"])
+	require.Equal(t, "1/code/inference.py", layout.Files["code/inference.py"])
+	require.Len(t, layout.Files, 6)
+
+	_, err = detect(t, map[string][]byte{
+		"a/saved_model.pb": []byte("pb"),
+		"b/saved_model.pb": []byte("pb"),
+	})
+	require.Equal(t, ErrMultipleModelsInArtifact, errors.Cause(err).(Error).Kind)
+}
+
+func TestDetectModelFileLayout(t *testing.T) {
+	layout, err := detect(t, map[string][]byte{
+		"model.onnx":           {0x08, 0x06},
+		"hyperparameters.json": []byte(`{"epochs": 3}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, &Layout{Framework: ONNXFramework, ModelPath: "model.onnx", Files: map[string]string{"model.onnx": "model.onnx"}}, layout)
+
+	layout, err = detect(t, map[string][]byte{"model.joblib": _pickle})
+	require.NoError(t, err)
+	require.Equal(t, SKLearnFramework, layout.Framework)
+	require.Equal(t, "model.joblib", layout.ModelPath)
+
+	// the built-in XGBoost algorithm saves a pickled booster before version 1.0
+	layout, err = detect(t, map[string][]byte{"xgboost-model": _pickle})
+	require.NoError(t, err)
+	require.Equal(t, SKLearnFramework, layout.Framework)
+	require.Equal(t, "xgboost-model.pkl", layout.ModelPath)
+
+	layout, err = detect(t, map[string][]byte{"xgboost-model": []byte("binf\x00\x00\x00\x3f")})
+	require.NoError(t, err)
+	require.Equal(t, XGBoostFramework, layout.Framework)
+	require.Equal(t, map[string]string{"xgboost-model": "xgboost-model.bst"}, layout.Files)
+
+	layout, err = detect(t, map[string][]byte{"model.json": []byte(`{"learner": {}}`)})
+	require.NoError(t, err)
+	require.Equal(t, XGBoostFramework, layout.Framework)
+
+	layout, err = detect(t, map[string][]byte{"model.txt": []byte("tree\nversion=v3"), "notes.txt": []byte("trained on 2019-12-01")})
+	require.NoError(t, err)
+	require.Equal(t, LightGBMFramework, layout.Framework)
+	require.Equal(t, "model.txt", layout.ModelPath)
+
+	_, err = detect(t, map[string][]byte{"model.joblib": _pickle, "model.onnx": {0x08}})
+	require.Equal(t, ErrMultipleModelsInArtifact, errors.Cause(err).(Error).Kind)
+
+	_, err = detect(t, map[string][]byte{"model.pth": []byte("torch")})
+	require.Equal(t, ErrNoModelInArtifact, errors.Cause(err).(Error).Kind)
+}
+
+func TestExtract(t *testing.T) {
+	data := artifact(t, map[string][]byte{
+		"1/saved_model.pb":          []byte("pb"),
+		"1/variables/variables.idx": []byte("index"),
+		"output/metrics.json":       []byte("{}"),
+	})
+
+	files, err := ListFiles(bytes.NewReader(data))
+	require.NoError(t, err)
+	layout, err := DetectLayout(files)
+	require.NoError(t, err)
+
+	extracted := map[string]string{}
+	err = layout.Extract(bytes.NewReader(data), func(path string, data []byte) error {
+		extracted[path] = string(data)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"1/saved_model.pb": "pb", "1/variables/variables.idx": "index"}, extracted)
+
+	_, err = ListFiles(bytes.NewReader([]byte("not an archive")))
+	require.Equal(t, ErrInvalidModelArtifact, errors.Cause(err).(Error).Kind)
+}
@@ -143,3 +143,46 @@ func StrsSentence(strs []string, lastJoinWord string) string {
 		return strings.Join(strs[:lastIndex], ", ") + ", " + lastJoinWord + " " + strs[lastIndex]
 	}
 }
+
+// LineDiff returns a line-by-line diff from before to after, where unchanged lines are prefixed with "  ",
+// removed lines are prefixed with "- ", and added lines are prefixed with "+ "
+func LineDiff(before string, after string) string {
+	beforeLines := strings.Split(before, "\n")
+	afterLines := strings.Split(after, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of beforeLines[i:] and afterLines[j:]
+	lcs := make([][]int, len(beforeLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(afterLines)+1)
+	}
+	for i := len(beforeLines) - 1; i >= 0; i-- {
+		for j := len(afterLines) - 1; j >= 0; j-- {
+			if beforeLines[i] == afterLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diffLines []string
+	i, j := 0, 0
+	for i < len(beforeLines) || j < len(afterLines) {
+		switch {
+		case i < len(beforeLines) && j < len(afterLines) && beforeLines[i] == afterLines[j]:
+			diffLines = append(diffLines, "  "+beforeLines[i])
+			i++
+			j++
+		case i < len(beforeLines) && (j == len(afterLines) || lcs[i+1][j] >= lcs[i][j+1]):
+			diffLines = append(diffLines, "- "+beforeLines[i])
+			i++
+		default:
+			diffLines = append(diffLines, "+ "+afterLines[j])
+			j++
+		}
+	}
+
+	return strings.Join(diffLines, "\n")
+}
@@ -54,3 +54,13 @@ func TestLongestCommonPrefix(t *testing.T) {
 	expected = ""
 	require.Equal(t, expected, LongestCommonPrefix(strs...))
 }
+
+func TestLineDiff(t *testing.T) {
+	require.Equal(t, "  a\n  b", LineDiff("a\nb", "a\nb"))
+
+	require.Equal(t, "  a\n- b\n+ c\n  d", LineDiff("a\nb\nd", "a\nc\nd"))
+
+	require.Equal(t, "  a\n+ b", LineDiff("a", "a\nb"))
+
+	require.Equal(t, "- a\n  b", LineDiff("a\nb", "b"))
+}
@@ -135,6 +135,24 @@ func DollarsMaxPrecision(val float64) string {
 	return "$" + Round(val, 100, 2)
 }
 
+var byteUnits = []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// ByteSize formats a number of bytes using binary units (e.g. 1.5 MiB)
+func ByteSize(bytes int64) string {
+	if bytes < 1024 && bytes > -1024 {
+		return Int64(bytes) + " B"
+	}
+	val := float64(bytes)
+	unit := ""
+	for _, unit = range byteUnits {
+		val /= 1024
+		if math.Abs(val) < 1024 {
+			break
+		}
+	}
+	return Round(val, 1, 0) + " " + unit
+}
+
 // This is similar to json.Marshal, but handles non-string keys (which we support). It should be valid YAML since we use it in templates
 func strIndent(val interface{}, indent string, currentIndent string, newlineChar string, quoteStr string) string {
 	if val == nil {
@@ -296,3 +296,12 @@ func TestRound(t *testing.T) {
 	require.Equal(t, Round(1, 2, 0), "1")
 	require.Equal(t, Round(20, 3, 0), "20")
 }
+
+func TestByteSize(t *testing.T) {
+	require.Equal(t, "0 B", ByteSize(0))
+	require.Equal(t, "1023 B", ByteSize(1023))
+	require.Equal(t, "1 KiB", ByteSize(1024))
+	require.Equal(t, "1.5 KiB", ByteSize(1536))
+	require.Equal(t, "50 MiB", ByteSize(50*1024*1024))
+	require.Equal(t, "1.2 GiB", ByteSize(1288490188))
+}
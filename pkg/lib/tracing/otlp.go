@@ -0,0 +1,142 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"sort"
+	"strconv"
+)
+
+// The JSON encoding of OTLP's ExportTraceServiceRequest (https://github.com/open-telemetry/opentelemetry-proto)
+
+const (
+	_otlpSpanKindInternal = 1
+	_otlpStatusCodeOK     = 1
+	_otlpStatusCodeError  = 2
+)
+
+type otlpTraceRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func otlpRequest(serviceName string, spans []*Span) *otlpTraceRequest {
+	otlpSpans := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		otlpSpans[i] = toOTLPSpan(span)
+	}
+
+	return &otlpTraceRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: otlpAttributes(map[string]string{"service.name": serviceName}),
+				},
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{Name: "cortex"},
+						Spans: otlpSpans,
+					},
+				},
+			},
+		},
+	}
+}
+
+func toOTLPSpan(span *Span) otlpSpan {
+	span.mux.Lock()
+	defer span.mux.Unlock()
+
+	otlpSpan := otlpSpan{
+		TraceID:           span.Context.TraceIDStr(),
+		SpanID:            span.Context.SpanIDStr(),
+		Name:              span.Name,
+		Kind:              _otlpSpanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+		Attributes:        otlpAttributes(span.Attributes),
+		Status:            otlpStatus{Code: _otlpStatusCodeOK},
+	}
+
+	if span.ParentID != nil {
+		otlpSpan.ParentSpanID = SpanContext{SpanID: *span.ParentID}.SpanIDStr()
+	}
+
+	if span.Err != nil {
+		otlpSpan.Status = otlpStatus{Code: _otlpStatusCodeError, Message: span.Err.Error()}
+	}
+
+	return otlpSpan
+}
+
+// otlpAttributes sorts the attributes by key, so that the exported spans are deterministic
+func otlpAttributes(attributes map[string]string) []otlpKeyValue {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	keyValues := make([]otlpKeyValue, len(keys))
+	for i, key := range keys {
+		keyValues[i] = otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: attributes[key]}}
+	}
+	return keyValues
+}
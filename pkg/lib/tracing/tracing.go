@@ -0,0 +1,267 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	mathrand "math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+)
+
+// TraceParentHeader is the W3C trace context header which spans are propagated with (https://www.w3.org/TR/trace-context)
+const TraceParentHeader = "traceparent"
+
+const _maxBufferedSpans = 2000
+
+var _httpClient = &http.Client{Timeout: 5 * time.Second}
+
+type Config struct {
+	Endpoint    string  // the base URL of an OTLP/HTTP collector (spans are POSTed to <endpoint>/v1/traces)
+	ServiceName string  // the service.name resource attribute of the exported spans
+	SampleRate  float64 // the fraction of root spans which are exported (spans with a parent follow the parent's decision)
+}
+
+var (
+	_config *Config
+	_spans  []*Span
+	_mux    sync.Mutex
+)
+
+// Init enables the export of sampled spans; until it's called, spans are created and propagated but never exported
+func Init(config Config) error {
+	if config.Endpoint == "" {
+		return errors.New("the tracing endpoint must be specified")
+	}
+	if config.SampleRate < 0 || config.SampleRate > 1 {
+		return errors.New(fmt.Sprintf("the tracing sample rate must be between 0 and 1 (got %v)", config.SampleRate))
+	}
+
+	_mux.Lock()
+	defer _mux.Unlock()
+	_config = &config
+	return nil
+}
+
+func IsEnabled() bool {
+	_mux.Lock()
+	defer _mux.Unlock()
+	return _config != nil
+}
+
+// SpanContext identifies a span, and is what's propagated to other services
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+func (sc SpanContext) TraceIDStr() string {
+	return hex.EncodeToString(sc.TraceID[:])
+}
+
+func (sc SpanContext) SpanIDStr() string {
+	return hex.EncodeToString(sc.SpanID[:])
+}
+
+// TraceParent formats the span context as a traceparent header
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceIDStr(), sc.SpanIDStr(), flags)
+}
+
+// ParseTraceParent returns nil if the traceparent header is empty or malformed
+func ParseTraceParent(header string) *SpanContext {
+	parts := strings.Split(strings.TrimSpace(strings.ToLower(header)), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return nil
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return nil
+	}
+
+	var sc SpanContext
+	if !decodeID(parts[1], sc.TraceID[:]) || !decodeID(parts[2], sc.SpanID[:]) {
+		return nil
+	}
+
+	if len(parts[3]) != 2 {
+		return nil
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return nil
+	}
+	sc.Sampled = flags&1 == 1
+
+	return &sc
+}
+
+// decodeID decodes the hex string into id, and returns false if it's the wrong length, not hex, or all zeros
+func decodeID(str string, id []byte) bool {
+	if len(str) != hex.EncodedLen(len(id)) {
+		return false
+	}
+	if _, err := hex.Decode(id, []byte(str)); err != nil {
+		return false
+	}
+	for _, b := range id {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+type Span struct {
+	Name       string
+	Context    SpanContext
+	ParentID   *[8]byte
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]string
+	Err        error
+	mux        sync.Mutex
+	ended      bool
+}
+
+// StartSpan starts a root span if parent is nil (which is sampled according to the configured sample rate), or a child of parent
+func StartSpan(name string, parent *SpanContext) *Span {
+	span := &Span{
+		Name:       name,
+		Start:      time.Now(),
+		Attributes: map[string]string{},
+	}
+
+	if parent != nil {
+		span.Context.TraceID = parent.TraceID
+		span.Context.Sampled = parent.Sampled
+		parentID := parent.SpanID
+		span.ParentID = &parentID
+	} else {
+		randomID(span.Context.TraceID[:])
+		span.Context.Sampled = shouldSample()
+	}
+	randomID(span.Context.SpanID[:])
+
+	return span
+}
+
+// StartChild starts a span which is a child of this span
+func (span *Span) StartChild(name string) *Span {
+	return StartSpan(name, &span.Context)
+}
+
+func (span *Span) SetAttribute(key string, value string) {
+	span.mux.Lock()
+	defer span.mux.Unlock()
+	span.Attributes[key] = value
+}
+
+// Finish ends the span (err is recorded as the span's status if it's not nil); spans are only finished once
+func (span *Span) Finish(err error) {
+	span.mux.Lock()
+	if span.ended {
+		span.mux.Unlock()
+		return
+	}
+	span.ended = true
+	span.End = time.Now()
+	span.Err = err
+	span.mux.Unlock()
+
+	if span.Context.Sampled {
+		bufferSpan(span)
+	}
+}
+
+// Inject sets the traceparent header to this span, so that the receiving service's spans are its children
+func (span *Span) Inject(header http.Header) {
+	header.Set(TraceParentHeader, span.Context.TraceParent())
+}
+
+func randomID(id []byte) {
+	if _, err := rand.Read(id); err != nil {
+		mathrand.Read(id)
+	}
+}
+
+func shouldSample() bool {
+	_mux.Lock()
+	defer _mux.Unlock()
+	if _config == nil {
+		return false
+	}
+	return mathrand.Float64() < _config.SampleRate
+}
+
+// bufferSpan holds the span until the next Flush (the oldest spans are dropped if the buffer is full)
+func bufferSpan(span *Span) {
+	_mux.Lock()
+	defer _mux.Unlock()
+	if _config == nil {
+		return
+	}
+	if len(_spans) >= _maxBufferedSpans {
+		_spans = _spans[1:]
+	}
+	_spans = append(_spans, span)
+}
+
+// Flush exports the finished spans to the collector (the spans are dropped if the export fails)
+func Flush() error {
+	_mux.Lock()
+	config := _config
+	spans := _spans
+	_spans = nil
+	_mux.Unlock()
+
+	if config == nil || len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(otlpRequest(config.ServiceName, spans))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	url := urls.Join(config.Endpoint, "v1", "traces")
+	response, err := _httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), fmt.Sprintf("unable to export %d spans", len(spans)))
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.New(fmt.Sprintf("unable to export %d spans: %s responded with status %s", len(spans), urls.TrimQueryParamsStr(url), response.Status))
+	}
+
+	return nil
+}
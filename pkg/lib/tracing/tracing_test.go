@@ -0,0 +1,121 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	sc := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NotNil(t, sc)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceIDStr())
+	require.Equal(t, "00f067aa0ba902b7", sc.SpanIDStr())
+	require.True(t, sc.Sampled)
+	require.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.TraceParent())
+
+	sc = ParseTraceParent("00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-00")
+	require.NotNil(t, sc)
+	require.False(t, sc.Sampled)
+
+	// future versions may append fields
+	require.NotNil(t, ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"))
+
+	require.Nil(t, ParseTraceParent(""))
+	require.Nil(t, ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"))
+	require.Nil(t, ParseTraceParent("ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	require.Nil(t, ParseTraceParent("00-00000000000000000000000000000000-00f067aa0ba902b7-01"))
+	require.Nil(t, ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"))
+	require.Nil(t, ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01"))
+	require.Nil(t, ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01"))
+	require.Nil(t, ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1"))
+}
+
+func TestStartSpan(t *testing.T) {
+	parent := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	span := StartSpan("deploy", parent)
+	require.Equal(t, parent.TraceID, span.Context.TraceID)
+	require.Equal(t, parent.SpanID, *span.ParentID)
+	require.NotEqual(t, parent.SpanID, span.Context.SpanID)
+	require.True(t, span.Context.Sampled)
+
+	child := span.StartChild("validate")
+	require.Equal(t, parent.TraceID, child.Context.TraceID)
+	require.Equal(t, span.Context.SpanID, *child.ParentID)
+
+	header := http.Header{}
+	child.Inject(header)
+	require.Equal(t, child.Context, *ParseTraceParent(header.Get(TraceParentHeader)))
+
+	root := StartSpan("deploy", nil)
+	require.Nil(t, root.ParentID)
+	require.NotEqual(t, parent.TraceID, root.Context.TraceID)
+}
+
+func TestFlush(t *testing.T) {
+	var requests []otlpTraceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/traces", r.URL.Path)
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		var request otlpTraceRequest
+		require.NoError(t, json.Unmarshal(body, &request))
+		requests = append(requests, request)
+	}))
+	defer server.Close()
+
+	require.Error(t, Init(Config{Endpoint: server.URL, SampleRate: 2}))
+	require.NoError(t, Init(Config{Endpoint: server.URL, ServiceName: "operator", SampleRate: 1}))
+	defer func() { _config = nil }()
+
+	span := StartSpan("deploy", nil)
+	span.SetAttribute("app", "iris")
+	child := span.StartChild("upload context")
+	child.Finish(errors.New("access denied"))
+	child.Finish(nil)
+	span.Finish(nil)
+
+	unsampled := StartSpan("deploy", &SpanContext{TraceID: span.Context.TraceID, SpanID: span.Context.SpanID})
+	unsampled.Finish(nil)
+
+	require.NoError(t, Flush())
+	require.NoError(t, Flush()) // nothing is buffered
+	require.Len(t, requests, 1)
+
+	resourceSpans := requests[0].ResourceSpans[0]
+	require.Equal(t, []otlpKeyValue{{Key: "service.name", Value: otlpAnyValue{StringValue: "operator"}}}, resourceSpans.Resource.Attributes)
+
+	spans := resourceSpans.ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+
+	require.Equal(t, "upload context", spans[0].Name)
+	require.Equal(t, span.Context.SpanIDStr(), spans[0].ParentSpanID)
+	require.Equal(t, otlpStatus{Code: _otlpStatusCodeError, Message: "access denied"}, spans[0].Status)
+
+	require.Equal(t, "deploy", spans[1].Name)
+	require.Equal(t, span.Context.TraceIDStr(), spans[1].TraceID)
+	require.Equal(t, "", spans[1].ParentSpanID)
+	require.Equal(t, otlpStatus{Code: _otlpStatusCodeOK}, spans[1].Status)
+	require.Equal(t, []otlpKeyValue{{Key: "app", Value: otlpAnyValue{StringValue: "iris"}}}, spans[1].Attributes)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package triton
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+type ErrorKind int
+
+const (
+	ErrUnknown ErrorKind = iota
+	ErrNoModelsInRepository
+	ErrMissingModelConfig
+	ErrInvalidModelConfig
+	ErrModelNameMismatch
+	ErrNoModelVersions
+)
+
+var errorKinds = []string{
+	"err_unknown",
+	"err_no_models_in_repository",
+	"err_missing_model_config",
+	"err_invalid_model_config",
+	"err_model_name_mismatch",
+	"err_no_model_versions",
+}
+
+var _ = [1]int{}[int(ErrNoModelVersions)-(len(errorKinds)-1)] // Ensure list length matches
+
+func (t ErrorKind) String() string {
+	return errorKinds[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t ErrorKind) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *ErrorKind) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(errorKinds); i++ {
+		if enum == errorKinds[i] {
+			*t = ErrorKind(i)
+			return nil
+		}
+	}
+
+	*t = ErrUnknown
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *ErrorKind) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t ErrorKind) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+type Error struct {
+	Kind    ErrorKind
+	message string
+}
+
+func (e Error) Error() string {
+	return e.message
+}
+
+func ErrorNoModelsInRepository() error {
+	return errors.WithStack(Error{
+		Kind:    ErrNoModelsInRepository,
+		message: "the model repository doesn't contain any models (each model must be in its own top-level directory, e.g. <repository>/<model name>/config.pbtxt)",
+	})
+}
+
+func ErrorMissingModelConfig(modelName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrMissingModelConfig,
+		message: fmt.Sprintf("model %s doesn't have a %s file in its directory", s.UserStr(modelName), ConfigFileName),
+	})
+}
+
+func ErrorInvalidModelConfig(modelName string, reason string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidModelConfig,
+		message: fmt.Sprintf("unable to parse the %s file of model %s: %s", ConfigFileName, s.UserStr(modelName), reason),
+	})
+}
+
+func ErrorModelNameMismatch(modelName string, configName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrModelNameMismatch,
+		message: fmt.Sprintf("the name in the %s file of model %s (%s) must match the name of the model's directory", ConfigFileName, s.UserStr(modelName), s.UserStr(configName)),
+	})
+}
+
+func ErrorNoModelVersions(modelName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrNoModelVersions,
+		message: fmt.Sprintf("model %s doesn't have any versions (each version must be in a numbered subdirectory of the model's directory, e.g. %s/1/)", s.UserStr(modelName), modelName),
+	})
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package triton
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConfigFileName is the name of the configuration file in each model's directory
+const ConfigFileName = "config.pbtxt"
+
+// EnsemblePlatform is the platform of models which pipe other models in the repository together (they don't have any model files)
+const EnsemblePlatform = "ensemble"
+
+// ModelConfig holds the top-level fields of a model's config.pbtxt which are used to validate the repository
+type ModelConfig struct {
+	Name     string
+	Platform string
+	Backend  string
+}
+
+// Model is a model in a Triton model repository
+type Model struct {
+	Name     string
+	Versions []int64
+	Config   *ModelConfig
+}
+
+// IsEnsemble returns whether the model pipes other models together
+func (model *Model) IsEnsemble() bool {
+	return model.Config != nil && model.Config.Platform == EnsemblePlatform
+}
+
+// ModelNames returns the names of the models
+func ModelNames(models []*Model) []string {
+	names := make([]string, len(models))
+	for i, model := range models {
+		names[i] = model.Name
+	}
+	return names
+}
+
+// ValidateRepository checks the layout of a model repository given the keys of its objects (relative to the repository), and returns its models sorted by name.
+// Each top-level directory is a model, which must have a config.pbtxt (read with readConfig) and at least one numbered version directory (unless it is an ensemble).
+func ValidateRepository(keys []string, readConfig func(modelName string) ([]byte, error)) ([]*Model, error) {
+	hasConfig := map[string]bool{}
+	versions := map[string]map[int64]bool{}
+
+	for _, key := range keys {
+		parts := strings.Split(strings.TrimPrefix(key, "/"), "/")
+		if len(parts) < 2 || parts[0] == "" || strings.HasPrefix(parts[0], ".") {
+			continue // files at the root of the repository and hidden directories aren't models
+		}
+
+		modelName := parts[0]
+		if _, ok := versions[modelName]; !ok {
+			versions[modelName] = map[int64]bool{}
+		}
+
+		if len(parts) == 2 && parts[1] == ConfigFileName {
+			hasConfig[modelName] = true
+			continue
+		}
+
+		// this includes empty "directory" objects (e.g. <model>/1/), which are how ensembles' version directories are usually uploaded
+		if len(parts) >= 3 {
+			if version, err := strconv.ParseInt(parts[1], 10, 64); err == nil && version > 0 {
+				versions[modelName][version] = true
+			}
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, ErrorNoModelsInRepository()
+	}
+
+	modelNames := make([]string, 0, len(versions))
+	for modelName := range versions {
+		modelNames = append(modelNames, modelName)
+	}
+	sort.Strings(modelNames)
+
+	models := make([]*Model, 0, len(modelNames))
+	for _, modelName := range modelNames {
+		if !hasConfig[modelName] {
+			return nil, ErrorMissingModelConfig(modelName)
+		}
+
+		configBytes, err := readConfig(modelName)
+		if err != nil {
+			return nil, err
+		}
+		config, err := ParseModelConfig(configBytes)
+		if err != nil {
+			return nil, ErrorInvalidModelConfig(modelName, err.Error())
+		}
+		if config.Name != "" && config.Name != modelName {
+			return nil, ErrorModelNameMismatch(modelName, config.Name)
+		}
+
+		model := &Model{
+			Name:   modelName,
+			Config: config,
+		}
+		for version := range versions[modelName] {
+			model.Versions = append(model.Versions, version)
+		}
+		sort.Slice(model.Versions, func(i, j int) bool { return model.Versions[i] < model.Versions[j] })
+
+		if len(model.Versions) == 0 && !model.IsEnsemble() {
+			return nil, ErrorNoModelVersions(modelName)
+		}
+
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+// ParseModelConfig reads the top-level name, platform, and backend fields of a config.pbtxt (protobuf text format); the rest of the config is validated by Triton when the model is loaded
+func ParseModelConfig(data []byte) (*ModelConfig, error) {
+	tokens, err := tokenizeProtoText(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	config := &ModelConfig{}
+	depth := 0
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch token.value {
+		case "{", "[", "<":
+			if !token.quoted {
+				depth++
+				continue
+			}
+		case "}", "]", ">":
+			if !token.quoted {
+				depth--
+				if depth < 0 {
+					return nil, fmt.Errorf("unexpected %s", token.value)
+				}
+				continue
+			}
+		}
+
+		if depth != 0 || token.quoted || i+2 >= len(tokens) || tokens[i+1].value != ":" || tokens[i+1].quoted {
+			continue
+		}
+
+		value := tokens[i+2]
+		switch token.value {
+		case "name":
+			config.Name = value.value
+		case "platform":
+			config.Platform = value.value
+		case "backend":
+			config.Backend = value.value
+		default:
+			continue
+		}
+		if !value.quoted {
+			return nil, fmt.Errorf("the value of %s must be a quoted string", token.value)
+		}
+		i += 2
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced brackets")
+	}
+
+	return config, nil
+}
+
+type protoTextToken struct {
+	value  string
+	quoted bool
+}
+
+// tokenizeProtoText splits protobuf text format into identifiers, values, punctuation, and quoted strings (without their quotes), skipping comments
+func tokenizeProtoText(text string) ([]protoTextToken, error) {
+	var tokens []protoTextToken
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n' || r == ',' || r == ';':
+			continue
+
+		case r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					sb.WriteRune(runes[i])
+					continue
+				}
+				if runes[i] == r {
+					closed = true
+					break
+				}
+				sb.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, protoTextToken{value: sb.String(), quoted: true})
+
+		case strings.ContainsRune(":{}[]<>", r):
+			tokens = append(tokens, protoTextToken{value: string(r)})
+
+		default:
+			start := i
+			for i+1 < len(runes) && !strings.ContainsRune(" \t\r\n,;#\"':{}[]<>", runes[i+1]) {
+				i++
+			}
+			tokens = append(tokens, protoTextToken{value: string(runes[start : i+1])})
+		}
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package triton
+
+import (
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func configReader(configs map[string]string) func(string) ([]byte, error) {
+	return func(modelName string) ([]byte, error) {
+		return []byte(configs[modelName]), nil
+	}
+}
+
+func TestParseModelConfig(t *testing.T) {
+	config, err := ParseModelConfig([]byte(`
+# the model's name must match its directory
+name: "resnet50"
+platform: 'tensorrt_plan'
+max_batch_size: 8
+input [
+  {
+    name: "input"
+    data_type: TYPE_FP32
+    dims: [ 3, 224, 224 ]
+  }
+]
+output [
+  {
+    name: "output"
+    data_type: TYPE_FP32
+    dims: [ 1000 ]
+    label_filename: "labels.txt"
+  }
+]
+instance_group [ { count: 2, kind: KIND_GPU } ]
+`))
+	require.NoError(t, err)
+	require.Equal(t, &ModelConfig{Name: "resnet50", Platform: "tensorrt_plan"}, config)
+
+	config, err = ParseModelConfig([]byte(`backend: "python" parameters { key: "name" value: { string_value: "other" } }`))
+	require.NoError(t, err)
+	require.Equal(t, &ModelConfig{Backend: "python"}, config)
+
+	config, err = ParseModelConfig([]byte(""))
+	require.NoError(t, err)
+	require.Equal(t, &ModelConfig{}, config)
+
+	_, err = ParseModelConfig([]byte(`name: "resnet50`))
+	require.Error(t, err)
+
+	_, err = ParseModelConfig([]byte(`input [ { name: "input" }`))
+	require.Error(t, err)
+
+	_, err = ParseModelConfig([]byte(`platform: onnxruntime_onnx`))
+	require.Error(t, err)
+}
+
+func TestValidateRepository(t *testing.T) {
+	models, err := ValidateRepository([]string{
+		"README.md",
+		"resnet50/config.pbtxt",
+		"resnet50/1/model.plan",
+		"resnet50/2/model.plan",
+		"resnet50/labels.txt",
+		"preprocess/config.pbtxt",
+		"preprocess/1/model.py",
+		"pipeline/config.pbtxt",
+		"pipeline/1/",
+		".hidden/config.pbtxt",
+	}, configReader(map[string]string{
+		"resnet50":   `name: "resnet50" platform: "tensorrt_plan"`,
+		"preprocess": `backend: "python"`,
+		"pipeline":   `name: "pipeline" platform: "ensemble"`,
+	}))
+	require.NoError(t, err)
+	require.Equal(t, []string{"pipeline", "preprocess", "resnet50"}, ModelNames(models))
+	require.Equal(t, []int64{1}, models[0].Versions)
+	require.True(t, models[0].IsEnsemble())
+	require.Equal(t, "python", models[1].Config.Backend)
+	require.Equal(t, []int64{1, 2}, models[2].Versions)
+
+	// ensembles don't need a version directory object
+	models, err = ValidateRepository([]string{
+		"pipeline/config.pbtxt",
+	}, configReader(map[string]string{
+		"pipeline": `platform: "ensemble"`,
+	}))
+	require.NoError(t, err)
+	require.Empty(t, models[0].Versions)
+
+	_, err = ValidateRepository([]string{"README.md"}, configReader(nil))
+	require.Equal(t, ErrNoModelsInRepository, errors.Cause(err).(Error).Kind)
+
+	_, err = ValidateRepository([]string{"resnet50/1/model.plan"}, configReader(nil))
+	require.Equal(t, ErrMissingModelConfig, errors.Cause(err).(Error).Kind)
+
+	_, err = ValidateRepository([]string{
+		"resnet50/config.pbtxt",
+		"resnet50/1/model.plan",
+	}, configReader(map[string]string{
+		"resnet50": `name: "resnet"`,
+	}))
+	require.Equal(t, ErrModelNameMismatch, errors.Cause(err).(Error).Kind)
+
+	_, err = ValidateRepository([]string{
+		"resnet50/config.pbtxt",
+		"resnet50/latest/model.plan",
+	}, configReader(map[string]string{
+		"resnet50": `platform: "tensorrt_plan"`,
+	}))
+	require.Equal(t, ErrNoModelVersions, errors.Cause(err).(Error).Kind)
+
+	_, err = ValidateRepository([]string{
+		"resnet50/config.pbtxt",
+		"resnet50/1/model.plan",
+	}, configReader(map[string]string{
+		"resnet50": `input [`,
+	}))
+	require.Equal(t, ErrInvalidModelConfig, errors.Cause(err).(Error).Kind)
+}
@@ -32,7 +32,7 @@ const (
 	ErrDNS1123
 	ErrEndpoint
 	ErrEndpointEmptyPath
-	ErrEndpointDoubleSlash
+	ErrEndpointRelativeSegment
 )
 
 var errorKinds = []string{
@@ -42,10 +42,10 @@ var errorKinds = []string{
 	"err_dns1123",
 	"err_endpoint",
 	"err_endpoint_empty_path",
-	"err_endpoint_double_slash",
+	"err_endpoint_relative_segment",
 }
 
-var _ = [1]int{}[int(ErrEndpointDoubleSlash)-(len(errorKinds)-1)] // Ensure list length matches
+var _ = [1]int{}[int(ErrEndpointRelativeSegment)-(len(errorKinds)-1)] // Ensure list length matches
 
 func (t ErrorKind) String() string {
 	return errorKinds[t]
@@ -125,9 +125,9 @@ func ErrorEndpointEmptyPath() error {
 	})
 }
 
-func ErrorEndpointDoubleSlash(provided string) error {
+func ErrorEndpointRelativeSegment(provided string) error {
 	return errors.WithStack(Error{
-		Kind:    ErrEndpointDoubleSlash,
-		message: fmt.Sprintf("%s cannot contain adjacent slashes", s.UserStr(provided)),
+		Kind:    ErrEndpointRelativeSegment,
+		message: fmt.Sprintf("%s cannot contain %s or %s path segments", s.UserStr(provided), s.UserStr("."), s.UserStr("..")),
 	})
 }
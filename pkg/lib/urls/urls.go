@@ -28,6 +28,7 @@ var (
 	dns1035Regex    = regexp.MustCompile(`^[a-z]([-a-z0-9]*[a-z0-9])?$`)
 	dns1123Regex    = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
 	endpointRegex   = regexp.MustCompile(`^[a-zA-Z0-9_\-\./]*$`)
+	slashesRegex    = regexp.MustCompile(`//+`)
 	_urlQParamRegex = regexp.MustCompile(`(https?://.*)\?[^:\s]*`)
 )
 
@@ -66,17 +67,20 @@ func CheckDNS1123(str string) error {
 	return nil
 }
 
+// ValidateEndpoint validates the endpoint and returns its canonical form (see CanonicalizeEndpoint)
 func ValidateEndpoint(str string) (string, error) {
 	if !endpointRegex.MatchString(str) {
 		return "", ErrorEndpoint(str)
 	}
 
-	if strings.Contains(str, "//") {
-		return "", ErrorEndpointDoubleSlash(str)
-	}
-
 	path := CanonicalizeEndpoint(str)
 
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "." || segment == ".." {
+			return "", ErrorEndpointRelativeSegment(str)
+		}
+	}
+
 	if path == "/" {
 		return "", ErrorEndpointEmptyPath()
 	}
@@ -84,13 +88,22 @@ func ValidateEndpoint(str string) (string, error) {
 	return path, nil
 }
 
+// CanonicalizeEndpoint lower cases the endpoint, collapses adjacent slashes, and ensures that it has a leading slash but no trailing slash (e.g. "My//API/" -> "/my/api")
 func CanonicalizeEndpoint(str string) string {
+	str = slashesRegex.ReplaceAllString(strings.ToLower(str), "/")
 	if str == "" || str == "/" {
 		return "/"
 	}
 	return strings.TrimSuffix(s.EnsurePrefix(str, "/"), "/")
 }
 
+// EndpointHasPrefix returns true if the endpoint is equal to prefix, or is nested under it (e.g. "/logs/read" has the prefix "/logs", but "/logsink" does not)
+func EndpointHasPrefix(endpoint string, prefix string) bool {
+	endpoint = CanonicalizeEndpoint(endpoint)
+	prefix = CanonicalizeEndpoint(prefix)
+	return prefix == "/" || endpoint == prefix || strings.HasPrefix(endpoint, prefix+"/")
+}
+
 func TrimQueryParamsURL(u url.URL) string {
 	u.RawQuery = ""
 	return u.String()
@@ -0,0 +1,177 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+)
+
+const (
+	DeployStartedEvent   = "deploy_started"
+	DeploySucceededEvent = "deploy_succeeded"
+	DeployFailedEvent    = "deploy_failed"
+	ScaledEvent          = "scaled"
+	CrashLoopingEvent    = "crash_looping"
+	HighErrorRateEvent   = "high_error_rate"
+	MaxReplicasEvent     = "max_replicas"
+	DataDriftEvent       = "data_drift"
+	IdleEvent            = "idle"
+
+	// Events which are only sent to the destinations of the APIs' alert rules
+	AlertFiredEvent    = "alert_fired"
+	AlertResolvedEvent = "alert_resolved"
+
+	URLKey    = "url"
+	SecretKey = "secret"
+	EventsKey = "events"
+
+	SignatureHeader = "X-Cortex-Signature"
+	EventHeader     = "X-Cortex-Event"
+)
+
+var Events = []string{DeployStartedEvent, DeploySucceededEvent, DeployFailedEvent, ScaledEvent, CrashLoopingEvent, HighErrorRateEvent, MaxReplicasEvent, DataDriftEvent, IdleEvent}
+
+var _httpClient = &http.Client{Timeout: 10 * time.Second}
+
+type Webhook struct {
+	URL    string   `json:"url" yaml:"url"`
+	Secret string   `json:"secret" yaml:"secret"` // used to sign payloads (optional)
+	Events []string `json:"events" yaml:"events"` // defaults to all events
+}
+
+type Payload struct {
+	Event      string                 `json:"event"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Cluster    string                 `json:"cluster"`
+	Deployment string                 `json:"deployment"`
+	API        string                 `json:"api"`
+	Message    string                 `json:"message"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+}
+
+var Validation = &cr.StructListValidation{
+	AllowExplicitNull: true,
+	StructValidation: &cr.StructValidation{
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "URL",
+				StringValidation: &cr.StringValidation{
+					Required:  true,
+					Validator: validateURL,
+				},
+			},
+			{
+				StructField: "Secret",
+				StringValidation: &cr.StringValidation{
+					AllowEmpty: true,
+				},
+			},
+			{
+				StructField: "Events",
+				StringListValidation: &cr.StringListValidation{
+					Default:      Events,
+					DisallowDups: true,
+					Validator:    validateEvents,
+				},
+			},
+		},
+	},
+}
+
+func validateURL(str string) (string, error) {
+	u, err := urls.Parse(str)
+	if err != nil {
+		return "", err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", urls.ErrorInvalidURL(str)
+	}
+	return str, nil
+}
+
+func validateEvents(events []string) ([]string, error) {
+	for _, event := range events {
+		if !slices.HasString(Events, event) {
+			return nil, cr.ErrorInvalidStr(event, Events...)
+		}
+	}
+	return events, nil
+}
+
+func (webhook *Webhook) Subscribes(event string) bool {
+	return slices.HasString(webhook.Events, event)
+}
+
+func (webhook *Webhook) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", URLKey, webhook.URL))
+	if webhook.Secret != "" {
+		sb.WriteString(fmt.Sprintf("%s: ********\n", SecretKey))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", EventsKey, strings.Join(webhook.Events, ", ")))
+	return sb.String()
+}
+
+// Signature returns the value of the signature header for a payload (the hex-encoded HMAC-SHA256 of the body, keyed with the webhook's secret)
+func Signature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send POSTs the payload to the URL, signing it if secret is not empty
+func Send(url string, secret string, payload *Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, payload.Event)
+	if secret != "" {
+		request.Header.Set(SignatureHeader, Signature(secret, body))
+	}
+
+	response, err := _httpClient.Do(request)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.New(fmt.Sprintf("webhook %s responded with status %s", urls.TrimQueryParamsStr(url), response.Status))
+	}
+
+	return nil
+}
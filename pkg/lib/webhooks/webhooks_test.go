@@ -0,0 +1,68 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignature(t *testing.T) {
+	// echo -n '{"event":"scaled"}' | openssl dgst -sha256 -hmac secret
+	require.Equal(t, "sha256=4afa1278229b3f2d1f3eded127464e04c562cf4557294a407031c1ba4b874fc1", Signature("secret", []byte(`{"event":"scaled"}`)))
+}
+
+func TestSend(t *testing.T) {
+	var receivedBody []byte
+	var receivedHeaders http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = ioutil.ReadAll(r.Body)
+		receivedHeaders = r.Header
+	}))
+	defer server.Close()
+
+	err := Send(server.URL, "secret", &Payload{Event: ScaledEvent, API: "my-api"})
+	require.NoError(t, err)
+	require.Equal(t, ScaledEvent, receivedHeaders.Get(EventHeader))
+	require.Equal(t, Signature("secret", receivedBody), receivedHeaders.Get(SignatureHeader))
+
+	err = Send(server.URL, "", &Payload{Event: ScaledEvent})
+	require.NoError(t, err)
+	require.Empty(t, receivedHeaders.Get(SignatureHeader))
+
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failingServer.Close()
+
+	require.Error(t, Send(failingServer.URL, "", &Payload{Event: ScaledEvent}))
+}
+
+func TestValidateURL(t *testing.T) {
+	_, err := validateURL("https://hooks.slack.com/services/abc")
+	require.NoError(t, err)
+
+	_, err = validateURL("hooks.slack.com/services/abc")
+	require.Error(t, err)
+
+	_, err = validateURL("ftp://example.com")
+	require.Error(t, err)
+}
@@ -301,6 +301,23 @@ func UnzipToFile(src string, destPath string) ([]string, error) {
 	return filenames, nil
 }
 
+// FileSizesInMem returns the uncompressed size of each file in the archive (without extracting it)
+func FileSizesInMem(zipBytes []byte) (map[string]int64, error) {
+	r, err := zip.NewReader(bytes.NewReader(zipBytes), int64(len(zipBytes)))
+	if err != nil {
+		return nil, errors.Wrap(err, errStrUnzip)
+	}
+
+	sizes := map[string]int64{}
+	for _, f := range r.File {
+		if !f.FileInfo().IsDir() {
+			path := strings.TrimPrefix(f.Name, "/")
+			sizes[path] = int64(f.UncompressedSize64)
+		}
+	}
+	return sizes, nil
+}
+
 func UnzipMemToMem(zipBytes []byte) (map[string][]byte, error) {
 	r, err := zip.NewReader(bytes.NewReader(zipBytes), int64(len(zipBytes)))
 	if err != nil {
@@ -382,4 +382,11 @@ func CheckZip(zipInput *Input, expected []string, shouldErr bool, t *testing.T)
 	contents, err = UnzipMemToMem(zipBytes)
 	require.NoError(t, err)
 	require.ElementsMatch(t, expected, maps.InterfaceMapKeysUnsafe(contents))
+
+	sizes, err := FileSizesInMem(zipBytes)
+	require.NoError(t, err)
+	require.ElementsMatch(t, expected, maps.InterfaceMapKeysUnsafe(sizes))
+	for path, size := range sizes {
+		require.Equal(t, int64(len(contents[path])), size)
+	}
 }
@@ -0,0 +1,149 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"time"
+
+	kadmissionregistration "k8s.io/api/admissionregistration/v1beta1"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/endpoints"
+)
+
+const (
+	admissionPortStr        = "8443"
+	cortexAPIValidationPath = "/validate/cortexapis"
+	cortexAPIWebhookName    = "cortexapis.cortex.dev"
+
+	managedResourceValidationPath = "/validate/managed-resources"
+	managedResourceWebhookName    = "managed-resources.cortex.dev"
+)
+
+// serveAdmissionWebhooks serves the CortexAPI and managed resource validating webhooks over TLS (with a self-signed certificate which is registered with the API server)
+func serveAdmissionWebhooks() error {
+	serviceHost := "operator." + consts.K8sNamespace + ".svc"
+
+	certPEM, keyPEM, err := selfSignedCertificate(serviceHost)
+	if err != nil {
+		return err
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = config.Kubernetes.ApplyValidatingWebhookConfiguration(k8s.ValidatingWebhookConfiguration(&k8s.ValidatingWebhookSpec{
+		Name:             cortexAPIWebhookName,
+		ServiceName:      "operator",
+		ServiceNamespace: consts.K8sNamespace,
+		ServicePath:      cortexAPIValidationPath,
+		CABundle:         certPEM,
+		APIGroups:        []string{"cortex.dev"},
+		APIVersions:      []string{"v1alpha1"},
+		Resources:        []string{"cortexapis"},
+	}))
+	if err != nil {
+		return err
+	}
+
+	// requests are allowed if the operator is unavailable, so that the cluster can't get stuck
+	err = config.Kubernetes.ApplyValidatingWebhookConfiguration(k8s.ValidatingWebhookConfiguration(&k8s.ValidatingWebhookSpec{
+		Name:             managedResourceWebhookName,
+		ServiceName:      "operator",
+		ServiceNamespace: consts.K8sNamespace,
+		ServicePath:      managedResourceValidationPath,
+		CABundle:         certPEM,
+		APIGroups:        []string{"apps", "autoscaling", "networking.istio.io"},
+		APIVersions:      []string{"*"},
+		Resources:        []string{"deployments", "horizontalpodautoscalers", "virtualservices"},
+		Operations:       []kadmissionregistration.OperationType{kadmissionregistration.Update, kadmissionregistration.Delete},
+		FailurePolicy:    kadmissionregistration.Ignore,
+		NamespaceLabels:  map[string]string{"cortex.dev/managed": "true"},
+	}))
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(cortexAPIValidationPath, endpoints.ValidateCortexAPI)
+	mux.HandleFunc(managedResourceValidationPath, endpoints.ValidateManagedResource)
+
+	server := &http.Server{
+		Addr:      ":" + admissionPortStr,
+		Handler:   panicMiddleware(mux),
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+	}
+
+	go func() {
+		logging.With(logging.Fields{"port": admissionPortStr}).Info("serving admission webhooks")
+		fatal(server.ListenAndServeTLS("", ""))
+	}()
+
+	return nil
+}
+
+// selfSignedCertificate returns a PEM-encoded certificate (which is its own CA) and private key for the host
+func selfSignedCertificate(host string) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: host},
+		DNSNames:              []string{host},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
@@ -17,6 +17,10 @@ limitations under the License.
 package context
 
 import (
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
 )
 
@@ -25,6 +29,29 @@ type APIs map[string]*API
 type API struct {
 	*userconfig.API
 	*ComputedResourceFields
+	Owner     string `json:"owner"`      // the ARN of the IAM principal which created the API (empty for APIs created before ownership was tracked)
+	Revision  int64  `json:"revision"`   // incremented each time the API's configuration is updated
+	GitCommit string `json:"git_commit"` // the commit of the GitOps repository from which the API was deployed (empty if it was deployed with the CLI)
+
+	ConfigMapFiles []int `json:"config_map_files"` // the indexes of the API's files which are stored in its config map (the rest are mounted from the project)
+
+	// Pause and LastResumed are set by `cortex pause` and `cortex resume`, and are kept when the API is redeployed
+	Pause       *APIPause  `json:"pause"`        // set while the API is paused
+	LastResumed *time.Time `json:"last_resumed"` // the API's idle window (if any) restarts when it's resumed
+}
+
+// APIPause records the replicas which the API had when it was paused, so that they can be restored when it's resumed
+type APIPause struct {
+	Replicas int32     `json:"replicas"`
+	Time     time.Time `json:"time"`
+}
+
+// ConfigID identifies the API's predictor config if it's reloaded by the API's replicas (otherwise the config is part of the API's ID)
+func (api *API) ConfigID() string {
+	if !api.Predictor.ConfigReload {
+		return ""
+	}
+	return hash.String(s.Obj(api.Predictor.Config))
 }
 
 func (apis APIs) OneByID(id string) *API {
@@ -59,6 +86,9 @@ func APIResourcesAndComputesMatch(ctx1 *Context, ctx2 *Context) bool {
 		if api1.Compute.ID() != api2.Compute.ID() {
 			return false
 		}
+		if api1.ConfigID() != api2.ConfigID() {
+			return false
+		}
 	}
 
 	return true
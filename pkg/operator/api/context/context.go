@@ -39,6 +39,7 @@ type Context struct {
 	APIs              APIs                          `json:"apis"`
 	ProjectID         string                        `json:"project_id"`
 	ProjectKey        string                        `json:"project_key"`
+	CondaEnvID        string                        `json:"conda_env_id"` // empty if the project doesn't define a conda environment
 }
 
 type Resource interface {
@@ -28,12 +28,14 @@ type DataStatus struct {
 // There is one APIStatus per API resource ID (including stale/removed models). There is always an APIStatus for APIs currently in the context.
 type APIStatus struct {
 	APISavedStatus
-	MinReplicas          int32 `json:"min_replicas"`
-	MaxReplicas          int32 `json:"max_replicas"`
-	InitReplicas         int32 `json:"init_replicas"`
-	TargetCPUUtilization int32 `json:"target_cpu_utilization"`
+	MinReplicas          int32  `json:"min_replicas"`
+	MaxReplicas          int32  `json:"max_replicas"`
+	InitReplicas         int32  `json:"init_replicas"`
+	TargetCPUUtilization int32  `json:"target_cpu_utilization"`
+	TargetGPUUtilization *int32 `json:"target_gpu_utilization"`
 	ReplicaCounts        `json:"replica_counts"`
 	PodStatuses          []k8s.PodStatus `json:"pod_statuses"`
+	GitCommit            string          `json:"git_commit"`
 	Code                 StatusCode      `json:"status_code"`
 }
 
@@ -121,6 +123,9 @@ const (
 	StatusUpdating
 	StatusStopping
 	StatusStopped
+	StatusCrashLooping
+	StatusStalled
+	StatusPaused
 )
 
 var statusCodes = []string{
@@ -143,9 +148,12 @@ var statusCodes = []string{
 	"status_updating",
 	"status_stopping",
 	"status_stopped",
+	"status_crash_looping",
+	"status_stalled",
+	"status_paused",
 }
 
-var _ = [1]int{}[int(StatusStopped)-(len(statusCodes)-1)] // Ensure list length matches
+var _ = [1]int{}[int(StatusPaused)-(len(statusCodes)-1)] // Ensure list length matches
 
 var statusCodeMessages = []string{
 	"unknown", // StatusUnknown
@@ -163,13 +171,16 @@ var statusCodeMessages = []string{
 	"ready",      // StatusSucceeded
 	"terminated", // StatusKilled
 
-	"live",     // StatusLive
-	"updating", // StatusUpdating
-	"stopping", // StatusStopping
-	"stopped",  // StatusStopped
+	"live",                  // StatusLive
+	"updating",              // StatusUpdating
+	"stopping",              // StatusStopping
+	"stopped",               // StatusStopped
+	"error (crash looping)", // StatusCrashLooping
+	"stalled",               // StatusStalled
+	"paused",                // StatusPaused
 }
 
-var _ = [1]int{}[int(StatusStopped)-(len(statusCodeMessages)-1)] // Ensure list length matches
+var _ = [1]int{}[int(StatusPaused)-(len(statusCodeMessages)-1)] // Ensure list length matches
 
 var statusSortBuckets = []int{
 	999, // StatusUnknown
@@ -191,9 +202,12 @@ var statusSortBuckets = []int{
 	0, // StatusUpdating
 	3, // StatusStopping
 	1, // StatusStopped
+	1, // StatusCrashLooping
+	1, // StatusStalled
+	1, // StatusPaused
 }
 
-var _ = [1]int{}[int(StatusStopped)-(len(statusSortBuckets)-1)] // Ensure list length matches
+var _ = [1]int{}[int(StatusPaused)-(len(statusSortBuckets)-1)] // Ensure list length matches
 
 func (code StatusCode) String() string {
 	if int(code) < 0 || int(code) >= len(statusCodes) {
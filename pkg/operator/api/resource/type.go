@@ -24,9 +24,11 @@ type Type int
 type Types []Type
 
 const (
-	UnknownType Type = iota // 0
-	AppType                 // 1
-	APIType                 // 2
+	UnknownType  Type = iota // 0
+	AppType                  // 1
+	APIType                  // 2
+	DefaultsType             // 3
+	PipelineType             // 4
 )
 
 var (
@@ -34,24 +36,32 @@ var (
 		"unknown",
 		"deployment",
 		"api",
+		"defaults",
+		"pipeline",
 	}
 
 	typePlurals = []string{
 		"unknown",
 		"deployments",
 		"apis",
+		"defaults",
+		"pipelines",
 	}
 
 	userFacing = []string{
 		"unknown",
 		"deployment",
 		"api",
+		"defaults",
+		"pipeline",
 	}
 
 	userFacingPlural = []string{
 		"unknowns",
 		"deployments",
 		"apis",
+		"defaults",
+		"pipelines",
 	}
 
 	VisibleTypes = Types{
@@ -0,0 +1,33 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+// NodeGroupCapacity is the compute which is schedulable by API replicas on a single node of a node group
+type NodeGroupCapacity struct {
+	Name         string `json:"name"`
+	InstanceType string `json:"instance_type"`
+	Arch         string `json:"arch"`
+	CPU          string `json:"cpu"`
+	Mem          string `json:"mem"`
+	GPU          int64  `json:"gpu"`
+	CPUReserve   string `json:"cpu_reserve"` // the CPU on the node which isn't available to API replicas
+	MemReserve   string `json:"mem_reserve"` // the memory on the node which isn't available to API replicas
+}
+
+type CapacityResponse struct {
+	NodeGroups []NodeGroupCapacity `json:"node_groups"`
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"time"
+)
+
+// APICost estimates what an API costs, based on the share of its instances' compute which its replicas request (with on-demand pricing)
+type APICost struct {
+	AppName   string  `json:"app_name"`
+	APIName   string  `json:"api_name"`
+	Replicas  int     `json:"replicas"`   // the number of the API's replicas which are scheduled on instances
+	Hourly    float64 `json:"hourly"`     // the current hourly cost of the API's replicas
+	AvgHourly float64 `json:"avg_hourly"` // the average hourly cost over the last day (or since the operator started)
+	Monthly   float64 `json:"monthly"`    // the average hourly cost over a month
+}
+
+// InstanceCost is the hourly cost of the cluster's worker instances of an instance type (including their volumes)
+type InstanceCost struct {
+	InstanceType string  `json:"instance_type"`
+	Count        int     `json:"count"`
+	Hourly       float64 `json:"hourly"`
+}
+
+type CostsResponse struct {
+	APIs        []APICost      `json:"apis"` // most expensive first
+	Instances   []InstanceCost `json:"instances"`
+	FixedHourly float64        `json:"fixed_hourly"` // the EKS cluster, the operator, the load balancers, and the NAT gateway
+	IdleHourly  float64        `json:"idle_hourly"`  // the cost of the worker instances' compute which isn't requested by APIs
+	Hourly      float64        `json:"hourly"`       // the current hourly cost of the cluster
+	Monthly     float64        `json:"monthly"`      // the current hourly cost of the cluster over a month
+	Time        time.Time      `json:"time"`         // when the costs were estimated
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"time"
+)
+
+type DeployStage string
+
+const (
+	DeployStageWaitingForCompute DeployStage = "waiting_for_compute"
+	DeployStagePullingImage      DeployStage = "pulling_image"
+	DeployStageStarting          DeployStage = "starting"
+	DeployStageUpdating          DeployStage = "updating"
+	DeployStageReady             DeployStage = "ready"
+	DeployStagePaused            DeployStage = "paused"
+	DeployStageFailed            DeployStage = "failed"
+	DeployStageDone              DeployStage = "done"
+)
+
+// DeployProgressEvent is sent by the deploy progress websocket whenever an API or one of its replicas reaches a new stage
+type DeployProgressEvent struct {
+	Time    time.Time   `json:"time"`
+	APIName string      `json:"api_name"`
+	Replica string      `json:"replica"` // the name of the replica's pod (empty for events about the API as a whole)
+	Stage   DeployStage `json:"stage"`
+	Message string      `json:"message"`
+	Failed  bool        `json:"failed"` // set on the done event if any of the APIs failed to deploy
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"time"
+)
+
+// ExecRequest is the command to run in an API replica (it isn't run in a shell; use e.g. ["sh", "-c", "ls /mnt/model | head"] for shell features)
+type ExecRequest struct {
+	Command []string `json:"command"`
+}
+
+// ExecResponse is the result of running a command in an API replica
+type ExecResponse struct {
+	APIName   string `json:"api_name"`
+	Replica   string `json:"replica"`
+	Container string `json:"container"`
+	ExitCode  int    `json:"exit_code"`
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr"`
+	Truncated bool   `json:"truncated"` // whether stdout or stderr exceeded the output limit
+	AuditID   string `json:"audit_id"`
+}
+
+// ExecAuditRecord is stored in the cluster's bucket before a command is run in an API replica, and updated with its result once it completes
+type ExecAuditRecord struct {
+	ID        string        `json:"id"`
+	Time      time.Time     `json:"time"`
+	Caller    string        `json:"caller"`
+	RequestID string        `json:"request_id"`
+	AppName   string        `json:"app_name"`
+	APIName   string        `json:"api_name"`
+	Replica   string        `json:"replica"`
+	Container string        `json:"container"`
+	Command   []string      `json:"command"`
+	Completed bool          `json:"completed"`
+	ExitCode  *int          `json:"exit_code"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration"`
+	Stdout    string        `json:"stdout"` // truncated to the first 64KiB
+	Stderr    string        `json:"stderr"` // truncated to the first 64KiB
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+// FederatedDeployResponse is the result of deploying a configuration to this cluster and to each of its federated clusters
+type FederatedDeployResponse struct {
+	Clusters []*FederatedDeployResult `json:"clusters"`
+}
+
+// FederatedDeployResult is the result of the deploy to one cluster (the response or the error is set, unless the cluster was skipped)
+type FederatedDeployResult struct {
+	Cluster  string          `json:"cluster"`
+	Skipped  bool            `json:"skipped"` // none of the configuration's apis select the cluster
+	Response *DeployResponse `json:"response,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// FederatedResourcesResponse lists a deployment's apis in this cluster and in each of its federated clusters
+type FederatedResourcesResponse struct {
+	Clusters []*FederatedResources `json:"clusters"`
+}
+
+// FederatedResources are the statuses of a deployment's apis in one cluster (the resources or the error is set)
+type FederatedResources struct {
+	Cluster   string                `json:"cluster"`
+	Resources *GetResourcesResponse `json:"resources,omitempty"`
+	Error     string                `json:"error,omitempty"`
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+)
+
+// LogLevels are the levels of log records, from the least to the most severe (Python's levels)
+var LogLevels = []string{"debug", "info", "warning", "error", "critical"}
+
+// the level of a text line, which is formatted by cortex's python logger as <time>:cortex:<LEVEL>:<message>
+var _textLogLevelRegex = regexp.MustCompile(`:(DEBUG|INFO|WARNING|ERROR|CRITICAL):`)
+
+// LogRecord is a line which one of an API's containers logged
+type LogRecord struct {
+	Timestamp time.Time              `json:"timestamp"`
+	Pod       string                 `json:"pod"`
+	Container string                 `json:"container"`
+	Level     string                 `json:"level,omitempty"` // one of LogLevels, or empty if the line doesn't have a level
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"` // the fields of JSON lines (see the API's logs.format)
+}
+
+type LogsResponse struct {
+	APIName   string      `json:"api_name"`
+	Records   []LogRecord `json:"records"`   // in chronological order
+	Truncated bool        `json:"truncated"` // whether more records matched the query than its limit (in which case the latest records are returned)
+}
+
+// LogLevelsAtOrAbove returns the levels which are at least as severe as the level (or nil if it isn't one of LogLevels)
+func LogLevelsAtOrAbove(level string) []string {
+	for i, logLevel := range LogLevels {
+		if logLevel == level {
+			return LogLevels[i:]
+		}
+	}
+	return nil
+}
+
+// ParseLogRecord parses a log event which was shipped by fluentd, whose stream is named <pod>_<container>
+// and whose message is a JSON object with a log field (which is an object if the line was parsed as JSON)
+func ParseLogRecord(timestamp time.Time, logStream string, message string) LogRecord {
+	record := LogRecord{
+		Timestamp: timestamp,
+		Pod:       logStream,
+	}
+	if i := strings.LastIndex(logStream, "_"); i != -1 {
+		record.Pod = logStream[:i]
+		record.Container = logStream[i+1:]
+	}
+
+	var event struct {
+		Log interface{} `json:"log"`
+	}
+	if err := json.Unmarshal([]byte(message), &event); err != nil || event.Log == nil {
+		record.Message = message
+		return record
+	}
+
+	switch log := event.Log.(type) {
+	case string:
+		record.Message = log
+		if match := _textLogLevelRegex.FindStringSubmatch(log); match != nil {
+			record.Level = strings.ToLower(match[1])
+		}
+	case map[string]interface{}:
+		record.Fields = log
+		for _, key := range []string{"message", "msg"} {
+			if msg, ok := log[key].(string); ok {
+				record.Message = msg
+				break
+			}
+		}
+		for _, key := range []string{"level", "levelname"} {
+			if level, ok := log[key].(string); ok {
+				record.Level = normalizeLogLevel(level)
+				break
+			}
+		}
+	default:
+		record.Message = message
+	}
+
+	return record
+}
+
+func normalizeLogLevel(level string) string {
+	level = strings.ToLower(level)
+	switch level {
+	case "warn":
+		return "warning"
+	case "fatal":
+		return "critical"
+	}
+	for _, logLevel := range LogLevels {
+		if logLevel == level {
+			return level
+		}
+	}
+	return ""
+}
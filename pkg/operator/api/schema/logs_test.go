@@ -0,0 +1,62 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogLevelsAtOrAbove(t *testing.T) {
+	require.Equal(t, LogLevels, LogLevelsAtOrAbove("debug"))
+	require.Equal(t, []string{"error", "critical"}, LogLevelsAtOrAbove("error"))
+	require.Equal(t, []string{"critical"}, LogLevelsAtOrAbove("critical"))
+	require.Nil(t, LogLevelsAtOrAbove("warn"))
+}
+
+func TestParseLogRecord(t *testing.T) {
+	timestamp := time.Date(2019, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	record := ParseLogRecord(timestamp, "iris-classifier-5d8f7-x2x9z_api", `{"log":"2019-10-17 12:00:00.000000:cortex:ERROR:failed to load the model"}`)
+	require.Equal(t, LogRecord{
+		Timestamp: timestamp,
+		Pod:       "iris-classifier-5d8f7-x2x9z",
+		Container: "api",
+		Level:     "error",
+		Message:   "2019-10-17 12:00:00.000000:cortex:ERROR:failed to load the model",
+	}, record)
+
+	record = ParseLogRecord(timestamp, "iris-classifier-5d8f7-x2x9z_api", `{"log":"serving on port 8888"}`)
+	require.Equal(t, "", record.Level)
+	require.Equal(t, "serving on port 8888", record.Message)
+
+	record = ParseLogRecord(timestamp, "iris-classifier-5d8f7-x2x9z_api", `{"log":{"level":"WARN","msg":"slow prediction","latency":1.5}}`)
+	require.Equal(t, "warning", record.Level)
+	require.Equal(t, "slow prediction", record.Message)
+	require.Equal(t, map[string]interface{}{"level": "WARN", "msg": "slow prediction", "latency": 1.5}, record.Fields)
+
+	record = ParseLogRecord(timestamp, "iris-classifier-5d8f7-x2x9z_api", `{"log":{"level":"verbose","message":"loaded"}}`)
+	require.Equal(t, "", record.Level)
+	require.Equal(t, "loaded", record.Message)
+
+	record = ParseLogRecord(timestamp, "stream", "not json")
+	require.Equal(t, "stream", record.Pod)
+	require.Equal(t, "", record.Container)
+	require.Equal(t, "not json", record.Message)
+}
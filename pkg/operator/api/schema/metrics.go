@@ -39,6 +39,20 @@ func (left RegressionStats) Merge(right RegressionStats) RegressionStats {
 	}
 }
 
+// FeedbackStats summarizes the feedback on tracked predictions; Avg is the accuracy of classification predictions,
+// or the mean absolute error of regression predictions
+type FeedbackStats struct {
+	Avg         *float64 `json:"avg"`
+	SampleCount int      `json:"sample_count"`
+}
+
+func (left FeedbackStats) Merge(right FeedbackStats) FeedbackStats {
+	return FeedbackStats{
+		Avg:         mergeAvg(left.Avg, left.SampleCount, right.Avg, right.SampleCount),
+		SampleCount: left.SampleCount + right.SampleCount,
+	}
+}
+
 type NetworkStats struct {
 	Latency *float64 `json:"latency"`
 	Code2XX int      `json:"code_2xx"`
@@ -61,6 +75,7 @@ type APIMetrics struct {
 	NetworkStats      *NetworkStats    `json:"network_stats"`
 	ClassDistribution map[string]int   `json:"class_distribution"`
 	RegressionStats   *RegressionStats `json:"regression_stats"`
+	FeedbackStats     *FeedbackStats   `json:"feedback_stats"`
 }
 
 func (left APIMetrics) Merge(right APIMetrics) APIMetrics {
@@ -98,10 +113,22 @@ func (left APIMetrics) Merge(right APIMetrics) APIMetrics {
 		mergedRegressionStats = right.RegressionStats
 	}
 
+	var mergedFeedbackStats *FeedbackStats
+	switch {
+	case left.FeedbackStats != nil && right.FeedbackStats != nil:
+		merged := (*left.FeedbackStats).Merge(*right.FeedbackStats)
+		mergedFeedbackStats = &merged
+	case left.FeedbackStats != nil:
+		mergedFeedbackStats = left.FeedbackStats
+	case right.FeedbackStats != nil:
+		mergedFeedbackStats = right.FeedbackStats
+	}
+
 	return APIMetrics{
 		NetworkStats:      mergedNetworkStats,
 		RegressionStats:   mergedRegressionStats,
 		ClassDistribution: mergedClassDistribution,
+		FeedbackStats:     mergedFeedbackStats,
 	}
 }
 
@@ -79,6 +79,29 @@ func TestRegressionStatsMerge(t *testing.T) {
 	require.Equal(t, merged, right.Merge(left))
 }
 
+func TestFeedbackStatsMerge(t *testing.T) {
+	require.Equal(t, FeedbackStats{}, FeedbackStats{}.Merge(FeedbackStats{}))
+
+	left := FeedbackStats{
+		Avg:         pointer.Float64(1),
+		SampleCount: 1,
+	}
+
+	right := FeedbackStats{
+		Avg:         pointer.Float64(0.5),
+		SampleCount: 3,
+	}
+
+	merged := FeedbackStats{
+		Avg:         pointer.Float64(0.625),
+		SampleCount: 4,
+	}
+
+	require.Equal(t, merged, left.Merge(right))
+	require.Equal(t, merged, right.Merge(left))
+	require.Equal(t, left, left.Merge(FeedbackStats{}))
+}
+
 func TestNetworkStatsMerge(t *testing.T) {
 	require.Equal(t, NetworkStats{}, NetworkStats{}.Merge(NetworkStats{}))
 
@@ -0,0 +1,32 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"time"
+)
+
+// ProfileResponse describes a profile of one of an API's replicas, which was uploaded to the cluster's bucket
+type ProfileResponse struct {
+	APIName   string        `json:"api_name"`
+	Replica   string        `json:"replica"`
+	Type      string        `json:"type"`
+	Duration  time.Duration `json:"duration"` // how long the replica was sampled for (0 for dumps)
+	S3Path    string        `json:"s3_path"`
+	URL       string        `json:"url"`        // a presigned URL which downloads the profile
+	ExpiresAt time.Time     `json:"expires_at"` // when the URL expires
+}
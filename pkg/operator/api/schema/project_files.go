@@ -0,0 +1,27 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+// ProjectFilesRequest lists the hashes of the contents of a project's files
+type ProjectFilesRequest struct {
+	Hashes []string `json:"hashes"`
+}
+
+// ProjectFilesResponse lists the hashes of the files which must be uploaded with the deploy (the operator has already stored the rest)
+type ProjectFilesResponse struct {
+	MissingHashes []string `json:"missing_hashes"`
+}
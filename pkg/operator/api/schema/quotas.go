@@ -0,0 +1,37 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+// QuotaCompute is an amount of compute (fields which aren't set are unlimited in a quota's limit)
+type QuotaCompute struct {
+	CPU      *string `json:"cpu,omitempty"`
+	Mem      *string `json:"mem,omitempty"`
+	GPU      *int64  `json:"gpu,omitempty"`
+	Replicas *int64  `json:"replicas,omitempty"`
+}
+
+// QuotaStatus is the compute which a quota's APIs request at their maximum replicas (paused APIs don't request any compute)
+type QuotaStatus struct {
+	Deployment string       `json:"deployment,omitempty"` // set if the quota applies to a deployment
+	Owner      string       `json:"owner,omitempty"`      // set if the quota applies to the APIs of an owner
+	Limit      QuotaCompute `json:"limit"`
+	Used       QuotaCompute `json:"used"`
+}
+
+type QuotasResponse struct {
+	Quotas []QuotaStatus `json:"quotas"`
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"time"
+)
+
+type ReplicaState string
+
+const (
+	ReplicaStateReady        ReplicaState = "ready"
+	ReplicaStatePending      ReplicaState = "pending"      // waiting to be scheduled or for its images to be pulled
+	ReplicaStateInitializing ReplicaState = "initializing" // running init containers, or running but not yet ready
+	ReplicaStateStale        ReplicaState = "stale"        // running a previous version of the API
+	ReplicaStateTerminating  ReplicaState = "terminating"
+	ReplicaStateFailed       ReplicaState = "failed"
+)
+
+// Failure reasons (otherwise, the reason reported by kubernetes is used)
+const (
+	ReplicaReasonImagePull = "image_pull"
+	ReplicaReasonOOMKilled = "oom_killed"
+	ReplicaReasonEvicted   = "evicted"
+	ReplicaReasonCrashLoop = "crash_loop"
+)
+
+type ReplicaStatus struct {
+	Name      string       `json:"name"`
+	State     ReplicaState `json:"state"`
+	Reason    string       `json:"reason"`    // why the replica failed (e.g. oom_killed)
+	Container string       `json:"container"` // the container which caused the failure
+	ExitCode  *int32       `json:"exit_code"` // the exit code of the container's last termination
+	Message   string       `json:"message"`
+	Restarts  int32        `json:"restarts"`
+	Node      string       `json:"node"`
+	StartTime *time.Time   `json:"start_time"`
+}
+
+type Event struct {
+	Time    time.Time `json:"time"`
+	Object  string    `json:"object"` // e.g. pod/my-api-5d8c7f9b4-x2k8j
+	Type    string    `json:"type"`   // Normal or Warning
+	Reason  string    `json:"reason"`
+	Message string    `json:"message"`
+	Count   int32     `json:"count"`
+}
+
+// MemoryRecommendation suggests how much memory to request for an API whose replicas have run out of memory
+type MemoryRecommendation struct {
+	Requested   *string `json:"requested"`  // nil if mem is not specified
+	PeakUsage   *string `json:"peak_usage"` // nil if no memory usage has been recorded
+	Recommended string  `json:"recommended"`
+	InstanceMem *string `json:"instance_mem"` // set if the recommendation exceeds the memory available on the cluster's instances
+	Message     string  `json:"message"`      // e.g. "increase mem from 2Gi to ~3Gi"
+}
+
+// CrashLoop describes the crash loop which stopped the rollout of an API
+type CrashLoop struct {
+	ResourceID string    `json:"resource_id"` // the resource ID of the API which was being rolled out
+	Replica    string    `json:"replica"`     // the replica with the most restarts
+	Container  string    `json:"container"`   // the replica's container with the most restarts
+	Restarts   int32     `json:"restarts"`    // the number of restarts of the API's replicas which triggered the circuit breaker
+	Time       time.Time `json:"time"`
+	RolledBack bool      `json:"rolled_back"` // whether the API was rolled back to its previous version (otherwise its rollout was paused)
+	Logs       string    `json:"logs"`        // the last lines which were logged by the container before it exited
+}
+
+// Reasons for which an API's rollout can stall
+const (
+	RolloutStallReasonImagePull         = "image_pull"         // an image could not be pulled
+	RolloutStallReasonUnschedulableGPU  = "unschedulable_gpu"  // no node has enough available GPUs
+	RolloutStallReasonInsufficientNodes = "insufficient_nodes" // no node has enough available compute (e.g. CPU or memory)
+	RolloutStallReasonNotReady          = "not_ready"          // replicas are running, but have not become ready
+	RolloutStallReasonUnknown           = "unknown"
+)
+
+// RolloutStall describes why an API's rollout exceeded its progress deadline
+type RolloutStall struct {
+	Reason  string    `json:"reason"`
+	Replica string    `json:"replica"` // the replica which is blocked ("" if no replica is blocked)
+	Message string    `json:"message"` // the message reported by kubernetes for the blocked replica
+	Time    time.Time `json:"time"`    // when the progress deadline was exceeded
+}
+
+// Drift describes changes which were made to an API's kubernetes resources outside of cortex
+type Drift struct {
+	Resources []string  `json:"resources"` // the resources which were modified or deleted (e.g. virtualservice/my-api)
+	Restored  bool      `json:"restored"`  // whether the resources were restored to the API's spec
+	Time      time.Time `json:"time"`      // when the drift was most recently detected
+}
+
+// Statuses of an API's data drift detection
+const (
+	DataDriftStatusCollectingBaseline  = "collecting_baseline"  // the baseline hasn't been captured yet
+	DataDriftStatusInsufficientSamples = "insufficient_samples" // fewer than min_samples predictions were tracked during the window
+	DataDriftStatusOK                  = "ok"
+	DataDriftStatusDrifted             = "drifted" // at least one field's PSI is above the threshold
+)
+
+// FieldDrift compares the distribution of a tracked field during the window with its baseline
+type FieldDrift struct {
+	Field   string  `json:"field"`   // "prediction", or the path of a feature in the request payloads
+	PSI     float64 `json:"psi"`     // the population stability index of the window relative to the baseline
+	Drifted bool    `json:"drifted"` // whether the PSI is above the threshold
+}
+
+// DataDrift is the most recent comparison of an API's tracked predictions (and features) with their baseline
+type DataDrift struct {
+	Status        string       `json:"status"`
+	Fields        []FieldDrift `json:"fields"` // the prediction followed by the features (only set if the status is ok or drifted)
+	Threshold     float64      `json:"threshold"`
+	BaselineCount int64        `json:"baseline_count"` // the number of predictions in the baseline
+	WindowCount   int64        `json:"window_count"`   // the number of predictions in the window
+	Time          time.Time    `json:"time"`           // when the comparison was made
+}
+
+// ClassFeedbackMetrics are the metrics of a class's predictions, relative to the ground truth which was received during the window
+type ClassFeedbackMetrics struct {
+	Class     string   `json:"class"`
+	Precision *float64 `json:"precision"` // the fraction of the predictions of the class which were correct (nil if the class wasn't predicted)
+	Recall    *float64 `json:"recall"`    // the fraction of the ground truths of the class which were predicted (nil if the class wasn't a ground truth)
+}
+
+// FeedbackMetrics are the rolling metrics of an API's tracked predictions which were given feedback during the window;
+// only the API's configured metrics are set (the precision and recall are the averages of the classes' metrics)
+type FeedbackMetrics struct {
+	Window    string                 `json:"window"` // e.g. 1h0m0s
+	Count     int64                  `json:"count"`  // the number of predictions which were given feedback
+	Accuracy  *float64               `json:"accuracy"`
+	Precision *float64               `json:"precision"`
+	Recall    *float64               `json:"recall"`
+	Classes   []ClassFeedbackMetrics `json:"classes"` // sorted by class (only set if precision or recall are configured)
+	MAE       *float64               `json:"mae"`
+	MSE       *float64               `json:"mse"`
+	Time      time.Time              `json:"time"` // when the metrics were computed
+}
+
+// Idle describes an API which hasn't received any requests during its idle window
+type Idle struct {
+	Window         string    `json:"window"` // e.g. 24h
+	Action         string    `json:"action"` // the action which is applied while the API is idle (none, scale_to_min, or pause)
+	Since          time.Time `json:"since"`  // when the API was first detected as idle
+	Recommendation string    `json:"recommendation"`
+}
+
+type APIStatusResponse struct {
+	APIName              string                `json:"api_name"`
+	Replicas             []ReplicaStatus       `json:"replicas"`
+	Events               []Event               `json:"events"`                // most recent first
+	MemoryRecommendation *MemoryRecommendation `json:"memory_recommendation"` // set if any of the API's replicas were killed for running out of memory
+	CrashLoop            *CrashLoop            `json:"crash_loop"`            // set if the API's rollout was stopped because its replicas were crash looping
+	RolloutStall         *RolloutStall         `json:"rollout_stall"`         // set if the API's rollout exceeded its progress deadline
+	Drift                *Drift                `json:"drift"`                 // set if the API's resources were modified outside of cortex since it was deployed
+	DataDrift            *DataDrift            `json:"data_drift"`            // set if the API's tracker detects data drift
+	FeedbackMetrics      *FeedbackMetrics      `json:"feedback_metrics"`      // set if the API computes metrics from its feedback
+	Cost                 *APICost              `json:"cost"`                  // nil until the API's cost has been estimated
+	Idle                 *Idle                 `json:"idle"`                  // set if the API hasn't received any requests during its idle window
+	DashboardURL         string                `json:"dashboard_url"`         // the URL of the API's CloudWatch dashboard (empty if it hasn't been created)
+}
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
 	"github.com/cortexlabs/cortex/pkg/operator/api/context"
 	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
 )
@@ -30,25 +31,55 @@ type InfoResponse struct {
 }
 
 type DeployResponse struct {
-	Message     string           `json:"message"`
-	Context     *context.Context `json:"context"`
-	APIsBaseURL string           `json:"apis_base_url"`
+	Message            string           `json:"message"`
+	Context            *context.Context `json:"context"`
+	APIsBaseURL        string           `json:"apis_base_url"`
+	PrivateAPIsBaseURL string           `json:"private_apis_base_url"`
+	Warnings           []string         `json:"warnings"`
 }
 
 type DeleteResponse struct {
 	Message string `json:"message"`
 }
 
+type PromoteResponse struct {
+	Message string `json:"message"`
+}
+
+type PauseResponse struct {
+	Message string `json:"message"`
+}
+
+type ResumeResponse struct {
+	Message string `json:"message"`
+}
+
+type TransferAPIOwnershipResponse struct {
+	Message string `json:"message"`
+}
+
+// APIDiffResponse lists the fields of the API's configuration which changed from revision From to revision To
+type APIDiffResponse struct {
+	APIName string           `json:"api_name"`
+	From    int64            `json:"from"`
+	To      int64            `json:"to"`
+	Diffs   []json.FieldDiff `json:"diffs"`
+}
+
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error  string `json:"error"`
+	Kind   string `json:"kind,omitempty"`
+	Status int    `json:"status"`
 }
 
 type GetResourcesResponse struct {
-	Context          *context.Context                    `json:"context"`
-	DataStatuses     map[string]*resource.DataStatus     `json:"data_statuses"`
-	APIStatuses      map[string]*resource.APIStatus      `json:"api_statuses"`
-	APIGroupStatuses map[string]*resource.APIGroupStatus `json:"api_name_statuses"`
-	APIsBaseURL      string                              `json:"apis_base_url"`
+	Context             *context.Context                    `json:"context"`
+	DataStatuses        map[string]*resource.DataStatus     `json:"data_statuses"`
+	APIStatuses         map[string]*resource.APIStatus      `json:"api_statuses"`
+	APIGroupStatuses    map[string]*resource.APIGroupStatus `json:"api_name_statuses"`
+	APIsBaseURL         string                              `json:"apis_base_url"`
+	PrivateAPIsBaseURL  string                              `json:"private_apis_base_url"`
+	ClusterLocalAPIURLs map[string]string                   `json:"cluster_local_api_urls"`
 }
 
 type Deployment struct {
@@ -70,3 +101,7 @@ type APISummary struct {
 	Message        string                      `json:"message"`
 	ModelSignature map[string]FeatureSignature `json:"model_signature"`
 }
+
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+)
+
+// Metrics which alert rules can be evaluated against
+const (
+	ErrorRateAlertMetric         = "error_rate"         // the fraction of requests which responded with a 5XX status code during the window
+	P99LatencyAlertMetric        = "p99_latency"        // the 99th percentile of the request latency during the window (in milliseconds)
+	ReplicaSaturationAlertMetric = "replica_saturation" // the API's replicas as a fraction of its max_replicas
+	DriftScoreAlertMetric        = "drift_score"        // the highest PSI of the API's tracked fields (requires tracker.drift)
+)
+
+var AlertMetrics = []string{ErrorRateAlertMetric, P99LatencyAlertMetric, ReplicaSaturationAlertMetric, DriftScoreAlertMetric}
+
+// metrics which are fractions (so their thresholds must be between 0 and 1)
+var _fractionAlertMetrics = strset.New(ErrorRateAlertMetric, ReplicaSaturationAlertMetric)
+
+type Alerts []*Alert
+
+// Alert fires when the metric is above the threshold (throughout the window, for metrics which aren't aggregated over it),
+// and is resolved once the metric is back at or below the threshold
+type Alert struct {
+	Name        string        `json:"name" yaml:"name"` // defaults to the metric
+	Metric      string        `json:"metric" yaml:"metric"`
+	Threshold   float64       `json:"threshold" yaml:"threshold"`
+	Window      time.Duration `json:"window" yaml:"window"`
+	Destination string        `json:"destination" yaml:"destination"` // the ARN of an SNS topic or the URL of a webhook
+}
+
+var alertsFieldValidation = &cr.StructFieldValidation{
+	StructField: "Alerts",
+	StructListValidation: &cr.StructListValidation{
+		AllowExplicitNull: true,
+		StructValidation: &cr.StructValidation{
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Name",
+					StringValidation: &cr.StringValidation{
+						AllowEmpty: true,
+					},
+				},
+				{
+					StructField: "Metric",
+					StringValidation: &cr.StringValidation{
+						Required:      true,
+						AllowedValues: AlertMetrics,
+					},
+				},
+				{
+					StructField: "Threshold",
+					Float64Validation: &cr.Float64Validation{
+						Required:             true,
+						GreaterThanOrEqualTo: pointer.Float64(0),
+					},
+				},
+				{
+					StructField: "Window",
+					DurationValidation: &cr.DurationValidation{
+						Default:              5 * time.Minute,
+						GreaterThanOrEqualTo: pointer.Duration(time.Minute),
+						LessThanOrEqualTo:    pointer.Duration(24 * time.Hour),
+					},
+				},
+				{
+					StructField: "Destination",
+					StringValidation: &cr.StringValidation{
+						Required:  true,
+						Validator: validateAlertDestination,
+					},
+				},
+			},
+		},
+	},
+}
+
+func validateAlertDestination(destination string) (string, error) {
+	if aws.IsSNSTopicARN(destination) {
+		return destination, nil
+	}
+	if u, err := urls.Parse(destination); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+		return destination, nil
+	}
+	return "", ErrorInvalidAlertDestination(destination)
+}
+
+// IsSNS returns whether the alert is published to an SNS topic (rather than sent to a webhook)
+func (alert *Alert) IsSNS() bool {
+	return aws.IsSNSTopicARN(alert.Destination)
+}
+
+// Validate defaults the alerts' names, checks that they're unique, and checks that each alert's metric applies to the API
+func (alerts Alerts) Validate(tracker *Tracker, cache *s3Cache) error {
+	names := strset.New()
+	for i, alert := range alerts {
+		if alert.Name == "" {
+			alert.Name = alert.Metric
+		}
+		if names.Has(alert.Name) {
+			return errors.Wrap(ErrorDuplicateAlertName(alert.Name), s.Index(i), NameKey)
+		}
+		names.Add(alert.Name)
+
+		if err := alert.Validate(tracker, cache); err != nil {
+			return errors.Wrap(err, s.Index(i))
+		}
+	}
+	return nil
+}
+
+// Validate checks that the alert's metric applies to the API and that the operator (which evaluates the alert) can publish to its SNS topic
+func (alert *Alert) Validate(tracker *Tracker, cache *s3Cache) error {
+	if _fractionAlertMetrics.Has(alert.Metric) && alert.Threshold > 1 {
+		return errors.Wrap(ErrorAlertThresholdNotFraction(alert.Metric, alert.Threshold), ThresholdKey)
+	}
+
+	if alert.Metric == DriftScoreAlertMetric && (tracker == nil || tracker.DataDrift == nil) {
+		return errors.Wrap(ErrorAlertMetricRequiresDataDrift(alert.Metric), MetricKey)
+	}
+
+	if alert.IsSNS() {
+		err := cache.check("sns:"+alert.Destination, func() error {
+			return aws.VerifySNSTopicPublishableByDefaultCredentials(alert.Destination)
+		})
+		if err != nil {
+			return errors.Wrap(err, DestinationKey)
+		}
+	}
+
+	return nil
+}
+
+func (alert *Alert) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", NameKey, alert.Name))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MetricKey, alert.Metric))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ThresholdKey, s.Float64(alert.Threshold)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", WindowKey, alert.Window.String()))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", DestinationKey, urls.TrimQueryParamsStr(alert.Destination)))
+	return sb.String()
+}
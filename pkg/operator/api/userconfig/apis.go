@@ -26,9 +26,13 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	"github.com/cortexlabs/cortex/pkg/lib/webhooks"
 	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
 	"github.com/cortexlabs/yaml"
 )
@@ -37,15 +41,32 @@ type APIs []*API
 
 type API struct {
 	ResourceFields
-	Endpoint  *string     `json:"endpoint" yaml:"endpoint"`
-	Predictor *Predictor  `json:"predictor" yaml:"predictor"`
-	Tracker   *Tracker    `json:"tracker" yaml:"tracker"`
-	Compute   *APICompute `json:"compute" yaml:"compute"`
+	Endpoint          *string             `json:"endpoint" yaml:"endpoint"`
+	Predictor         *Predictor          `json:"predictor" yaml:"predictor"`
+	Pipeline          *Pipeline           `json:"pipeline" yaml:"pipeline"` // only set for pipelines (whose predictor type is pipeline)
+	Tracker           *Tracker            `json:"tracker" yaml:"tracker"`
+	Compute           *APICompute         `json:"compute" yaml:"compute"`
+	Init              Containers          `json:"init" yaml:"init"`
+	Sidecars          Containers          `json:"sidecars" yaml:"sidecars"`
+	Volumes           Volumes             `json:"volumes" yaml:"volumes"`
+	Files             Files               `json:"files" yaml:"files"`
+	DependsOn         []string            `json:"depends_on" yaml:"depends_on"`
+	Networking        *Networking         `json:"networking" yaml:"networking"`
+	UpdateStrategy    *UpdateStrategy     `json:"update_strategy" yaml:"update_strategy"`
+	Experiment        *Experiment         `json:"experiment" yaml:"experiment"`
+	Webhooks          []*webhooks.Webhook `json:"webhooks" yaml:"webhooks"`
+	PredictionLogging *PredictionLogging  `json:"prediction_logging" yaml:"prediction_logging"`
+	Cache             *Cache              `json:"cache" yaml:"cache"`
+	Feedback          *Feedback           `json:"feedback" yaml:"feedback"`
+	Alerts            Alerts              `json:"alerts" yaml:"alerts"`
+	Logs              *Logs               `json:"logs" yaml:"logs"`
+	Clusters          []string            `json:"clusters" yaml:"clusters"` // the names of the clusters which the API is deployed to (all clusters if empty)
 }
 
 type Tracker struct {
-	Key       *string   `json:"key" yaml:"key"`
-	ModelType ModelType `json:"model_type" yaml:"model_type"`
+	Key       *string    `json:"key" yaml:"key"`
+	ModelType ModelType  `json:"model_type" yaml:"model_type"`
+	DataDrift *DataDrift `json:"drift" yaml:"drift"`
 }
 
 type Predictor struct {
@@ -54,8 +75,25 @@ type Predictor struct {
 	Model        *string                `json:"model" yaml:"model"`
 	PythonPath   *string                `json:"python_path" yaml:"python_path"`
 	Config       map[string]interface{} `json:"config" yaml:"config"`
+	ConfigReload bool                   `json:"config_reload" yaml:"config_reload"` // changes to Config are reloaded by the API's replicas, rather than rolling them
 	Env          map[string]string      `json:"env" yaml:"env"`
 	SignatureKey *string                `json:"signature_key" yaml:"signature_key"`
+	Batching     *Batching              `json:"batching" yaml:"batching"`
+	Explainer    *Explainer             `json:"explainer" yaml:"explainer"`
+
+	ProcessesPerReplica int32 `json:"processes_per_replica" yaml:"processes_per_replica"`
+	ThreadsPerProcess   int32 `json:"threads_per_process" yaml:"threads_per_process"`
+
+	TensorFlowServingVersion *string `json:"tensorflow_serving_version" yaml:"tensorflow_serving_version"`
+	ONNXRuntimeVersion       *string `json:"onnx_runtime_version" yaml:"onnx_runtime_version"`
+	PythonVersion            *string `json:"python_version" yaml:"python_version"`
+
+	// set when the model is resolved from the MLflow registry (Model is then the S3 path of the registered version's files)
+	MLflowModel        *string `json:"mlflow_model" yaml:"-"`
+	MLflowModelVersion string  `json:"mlflow_model_version" yaml:"-"`
+
+	// set when the model is imported from a SageMaker model artifact (Model is then the S3 path of the model in the cluster's bucket)
+	SageMakerModel *string `json:"sagemaker_model" yaml:"-"`
 }
 
 var predictorValidation = &cr.StructFieldValidation{
@@ -82,8 +120,10 @@ var predictorValidation = &cr.StructFieldValidation{
 			{
 				StructField: "Model",
 				StringPtrValidation: &cr.StringPtrValidation{
-					Validator: cr.S3PathValidator(),
+					Validator: validateModel,
 				},
+				RequiredIf: &cr.FieldCondition{Key: TypeKey, Values: []interface{}{PythonPredictorType}, Not: true},
+				AllowedIf:  &cr.FieldCondition{Key: TypeKey, Values: []interface{}{PythonPredictorType}, Not: true},
 			},
 			{
 				StructField: "PythonPath",
@@ -100,6 +140,13 @@ var predictorValidation = &cr.StructFieldValidation{
 					Default:        map[string]interface{}{},
 				},
 			},
+			{
+				StructField: "ConfigReload",
+				BoolValidation: &cr.BoolValidation{
+					Default: false,
+				},
+				AllowedIf: &cr.FieldCondition{Key: TypeKey, Values: []interface{}{PipelinePredictorType}, Not: true},
+			},
 			{
 				StructField: "Env",
 				StringMapValidation: &cr.StringMapValidation{
@@ -110,11 +157,56 @@ var predictorValidation = &cr.StructFieldValidation{
 			{
 				StructField:         "SignatureKey",
 				StringPtrValidation: &cr.StringPtrValidation{},
+				AllowedIf:           &cr.FieldCondition{Key: TypeKey, Values: []interface{}{TensorFlowPredictorType}},
+			},
+			{
+				StructField: "TensorFlowServingVersion",
+				StringPtrValidation: &cr.StringPtrValidation{
+					AllowedValues: TensorFlowServingVersions,
+				},
+				AllowedIf: &cr.FieldCondition{Key: TypeKey, Values: []interface{}{TensorFlowPredictorType}},
+			},
+			{
+				StructField: "ONNXRuntimeVersion",
+				StringPtrValidation: &cr.StringPtrValidation{
+					AllowedValues: ONNXRuntimeVersions,
+				},
+				AllowedIf: &cr.FieldCondition{Key: TypeKey, Values: []interface{}{ONNXPredictorType}},
+			},
+			{
+				StructField: "PythonVersion",
+				StringPtrValidation: &cr.StringPtrValidation{
+					AllowedValues: PythonVersions,
+				},
+			},
+			batchingFieldValidation,
+			explainerFieldValidation,
+			{
+				StructField: "ProcessesPerReplica",
+				Int32Validation: &cr.Int32Validation{
+					Default:           1,
+					GreaterThan:       pointer.Int32(0),
+					LessThanOrEqualTo: pointer.Int32(MaxProcessesPerReplica),
+				},
+			},
+			{
+				StructField: "ThreadsPerProcess",
+				Int32Validation: &cr.Int32Validation{
+					Default:           DefaultThreadsPerProcess,
+					GreaterThan:       pointer.Int32(0),
+					LessThanOrEqualTo: pointer.Int32(MaxThreadsPerProcess),
+				},
 			},
 		},
 	},
 }
 
+const (
+	MaxProcessesPerReplica   = 100
+	DefaultThreadsPerProcess = 4 // waitress's default
+	MaxThreadsPerProcess     = 1000
+)
+
 func ensurePythonPathSuffix(path string) (string, error) {
 	return s.EnsureSuffix(path, "/"), nil
 }
@@ -154,11 +246,30 @@ var apiValidation = &cr.StructValidation{
 							return ModelTypeFromString(str), nil
 						},
 					},
+					dataDriftFieldValidation,
 				},
 			},
 		},
 		predictorValidation,
 		apiComputeFieldValidation,
+		initFieldValidation,
+		sidecarsFieldValidation,
+		volumesFieldValidation,
+		filesFieldValidation,
+		dependsOnFieldValidation,
+		networkingFieldValidation,
+		updateStrategyFieldValidation,
+		experimentFieldValidation,
+		{
+			StructField:          "Webhooks",
+			StructListValidation: webhooks.Validation,
+		},
+		predictionLoggingFieldValidation,
+		cacheFieldValidation,
+		feedbackFieldValidation,
+		alertsFieldValidation,
+		logsFieldValidation,
+		clustersFieldValidation,
 		typeFieldValidation,
 	},
 }
@@ -245,8 +356,12 @@ func (api *API) UserConfigStr() string {
 	sb.WriteString(api.ResourceFields.UserConfigStr())
 	sb.WriteString(fmt.Sprintf("%s: %s\n", EndpointKey, *api.Endpoint))
 
-	sb.WriteString(fmt.Sprintf("%s:\n", PredictorKey))
-	sb.WriteString(s.Indent(api.Predictor.UserConfigStr(), "  "))
+	if api.Pipeline != nil {
+		sb.WriteString(api.Pipeline.UserConfigStr())
+	} else {
+		sb.WriteString(fmt.Sprintf("%s:\n", PredictorKey))
+		sb.WriteString(s.Indent(api.Predictor.UserConfigStr(), "  "))
+	}
 
 	if api.Compute != nil {
 		sb.WriteString(fmt.Sprintf("%s:\n", ComputeKey))
@@ -256,6 +371,66 @@ func (api *API) UserConfigStr() string {
 		sb.WriteString(fmt.Sprintf("%s:\n", TrackerKey))
 		sb.WriteString(s.Indent(api.Tracker.UserConfigStr(), "  "))
 	}
+	if len(api.Init) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", InitKey))
+		sb.WriteString(s.Indent(api.Init.UserConfigStr(), "  "))
+	}
+	if len(api.Sidecars) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", SidecarsKey))
+		sb.WriteString(s.Indent(api.Sidecars.UserConfigStr(), "  "))
+	}
+	if len(api.Volumes) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", VolumesKey))
+		sb.WriteString(s.Indent(api.Volumes.UserConfigStr(), "  "))
+	}
+	if len(api.Files) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", FilesKey))
+		sb.WriteString(s.Indent(api.Files.UserConfigStr(), "  "))
+	}
+	if len(api.DependsOn) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", DependsOnKey, s.ObjFlatNoQuotes(api.DependsOn)))
+	}
+	if len(api.Clusters) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ClustersKey, s.ObjFlatNoQuotes(api.Clusters)))
+	}
+	sb.WriteString(fmt.Sprintf("%s:\n", NetworkingKey))
+	sb.WriteString(s.Indent(api.Networking.UserConfigStr(), "  "))
+	sb.WriteString(fmt.Sprintf("%s:\n", UpdateStrategyKey))
+	sb.WriteString(s.Indent(api.UpdateStrategy.UserConfigStr(), "  "))
+	if api.Experiment != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", ExperimentKey))
+		sb.WriteString(s.Indent(api.Experiment.UserConfigStr(), "  "))
+	}
+	if len(api.Webhooks) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", WebhooksKey))
+		for _, webhook := range api.Webhooks {
+			webhookStr := s.Indent(webhook.UserConfigStr(), "    ")
+			sb.WriteString("  - " + strings.TrimPrefix(webhookStr, "    "))
+		}
+	}
+	if api.PredictionLogging != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", PredictionLoggingKey))
+		sb.WriteString(s.Indent(api.PredictionLogging.UserConfigStr(), "  "))
+	}
+	if api.Cache != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", CacheKey))
+		sb.WriteString(s.Indent(api.Cache.UserConfigStr(), "  "))
+	}
+	if api.Feedback != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", FeedbackKey))
+		sb.WriteString(s.Indent(api.Feedback.UserConfigStr(), "  "))
+	}
+	if len(api.Alerts) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", AlertsKey))
+		for _, alert := range api.Alerts {
+			alertStr := s.Indent(alert.UserConfigStr(), "    ")
+			sb.WriteString("  - " + strings.TrimPrefix(alertStr, "    "))
+		}
+	}
+	if api.Logs != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", LogsKey))
+		sb.WriteString(s.Indent(api.Logs.UserConfigStr(), "  "))
+	}
 	return sb.String()
 }
 
@@ -265,22 +440,51 @@ func (tracker *Tracker) UserConfigStr() string {
 	if tracker.Key != nil {
 		sb.WriteString(fmt.Sprintf("%s: %s\n", KeyKey, *tracker.Key))
 	}
+	if tracker.DataDrift != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", DriftKey))
+		sb.WriteString(s.Indent(tracker.DataDrift.UserConfigStr(), "  "))
+	}
 	return sb.String()
 }
 
+// The maximum number of APIs which are validated at a time (validating a TensorFlow or ONNX API makes requests to S3)
+const _apiValidationConcurrency = 10
+
 func (apis APIs) Validate(deploymentName string, projectFileMap map[string][]byte) error {
+	cache := newS3Cache()
+	fns := make([]func() error, len(apis))
+	for i := range apis {
+		api := apis[i]
+		fns[i] = func() error {
+			return api.Validate(deploymentName, projectFileMap, cache)
+		}
+	}
+
+	// the first API's error is returned (as if they were validated in order)
+	if err := errors.FirstError(parallel.RunWithLimit(_apiValidationConcurrency, fns...)...); err != nil {
+		return err
+	}
+
 	for _, api := range apis {
-		if err := api.Validate(deploymentName, projectFileMap); err != nil {
-			return err
+		if api.Pipeline != nil {
+			if err := api.Pipeline.Validate(apis, api.Compute.Arch); err != nil {
+				return errors.Wrap(err, Identify(api))
+			}
 		}
 	}
 
+	if err := apis.validateDependencies(); err != nil {
+		return err
+	}
+
 	endpoints := map[string]string{} // endpoint -> API name
 	for _, api := range apis {
-		if dupAPIName, ok := endpoints[*api.Endpoint]; ok {
-			return ErrorDuplicateEndpoints(*api.Endpoint, dupAPIName, api.Name)
+		for _, endpoint := range api.Endpoints() {
+			if dupAPIName, ok := endpoints[endpoint]; ok {
+				return ErrorDuplicateEndpoints(endpoint, dupAPIName, api.Name)
+			}
+			endpoints[endpoint] = api.Name
 		}
-		endpoints[*api.Endpoint] = api.Name
 	}
 
 	resources := make([]Resource, len(apis))
@@ -300,20 +504,46 @@ func (predictor *Predictor) UserConfigStr() string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("%s: %s\n", TypeKey, predictor.Type))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", PathKey, predictor.Path))
-	if predictor.Model != nil {
+	if predictor.MLflowModel != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s (version %s: %s)\n", ModelKey, *predictor.MLflowModel, predictor.MLflowModelVersion, *predictor.Model))
+	} else if predictor.SageMakerModel != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s (imported to %s)\n", ModelKey, *predictor.SageMakerModel, *predictor.Model))
+	} else if predictor.Model != nil {
 		sb.WriteString(fmt.Sprintf("%s: %s\n", ModelKey, *predictor.Model))
 	}
 	if predictor.SignatureKey != nil {
 		sb.WriteString(fmt.Sprintf("%s: %s\n", SignatureKeyKey, *predictor.SignatureKey))
 	}
+	if predictor.TensorFlowServingVersion != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", TensorFlowServingVersionKey, *predictor.TensorFlowServingVersion))
+	}
+	if predictor.ONNXRuntimeVersion != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ONNXRuntimeVersionKey, *predictor.ONNXRuntimeVersion))
+	}
+	if predictor.PythonVersion != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", PythonVersionKey, *predictor.PythonVersion))
+	}
 	if predictor.PythonPath != nil {
 		sb.WriteString(fmt.Sprintf("%s: %s\n", PythonPathKey, *predictor.PythonPath))
 	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ProcessesPerReplicaKey, s.Int32(predictor.ProcessesPerReplica)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ThreadsPerProcessKey, s.Int32(predictor.ThreadsPerProcess)))
+	if predictor.Batching != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", BatchingKey))
+		sb.WriteString(s.Indent(predictor.Batching.UserConfigStr(), "  "))
+	}
+	if predictor.Explainer != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", ExplainerKey))
+		sb.WriteString(s.Indent(predictor.Explainer.UserConfigStr(), "  "))
+	}
 	if len(predictor.Config) > 0 {
 		sb.WriteString(fmt.Sprintf("%s:\n", ConfigKey))
 		d, _ := yaml.Marshal(&predictor.Config)
 		sb.WriteString(s.Indent(string(d), "  "))
 	}
+	if predictor.ConfigReload {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ConfigReloadKey, s.Bool(predictor.ConfigReload)))
+	}
 	if len(predictor.Env) > 0 {
 		sb.WriteString(fmt.Sprintf("%s:\n", EnvKey))
 		d, _ := yaml.Marshal(&predictor.Env)
@@ -322,26 +552,69 @@ func (predictor *Predictor) UserConfigStr() string {
 	return sb.String()
 }
 
-func (predictor *Predictor) Validate(projectFileMap map[string][]byte) error {
-	switch predictor.Type {
-	case PythonPredictorType:
-		if err := predictor.PythonValidate(); err != nil {
-			return err
+// StartupConfig returns the entries of the predictor's config which are only read when the API's replicas start
+// (waitress_ options, and entries which reference secrets, since secrets are resolved into the replicas' environment)
+func (predictor *Predictor) StartupConfig() map[string]interface{} {
+	startupConfig := map[string]interface{}{}
+	for key, value := range predictor.Config {
+		if strings.HasPrefix(key, "waitress_") || len(secretReferences(map[string]interface{}{key: value})) > 0 {
+			startupConfig[key] = value
 		}
+	}
+	return startupConfig
+}
+
+func (predictor *Predictor) Validate(projectFileMap map[string][]byte, cache *s3Cache) error {
+	// mlflow:// models are resolved by the operator before the config is validated
+	if predictor.IsMLflowModel() {
+		return errors.Wrap(ErrorMLflowNotConfigured(), ModelKey)
+	}
+	if predictor.IsSageMakerModel() {
+		return errors.Wrap(ErrorSageMakerModelNotImported(*predictor.Model), ModelKey)
+	}
+
+	if predictor.PythonVersion != nil && !slices.HasString(SupportedPythonVersions(predictor.Type), *predictor.PythonVersion) {
+		return errors.Wrap(ErrorPythonVersionNotSupportedByPredictorType(*predictor.PythonVersion, predictor.Type), PythonVersionKey)
+	}
+
+	switch predictor.Type {
 	case TensorFlowPredictorType:
-		if err := predictor.TensorFlowValidate(); err != nil {
+		if err := predictor.TensorFlowValidate(cache); err != nil {
 			return err
 		}
 	case ONNXPredictorType:
-		if err := predictor.ONNXValidate(); err != nil {
+		if err := predictor.ONNXValidate(cache); err != nil {
+			return err
+		}
+	case SKLearnPredictorType, XGBoostPredictorType, LightGBMPredictorType:
+		if err := predictor.ModelFileValidate(cache); err != nil {
+			return err
+		}
+	case TritonPredictorType:
+		if err := predictor.TritonValidate(cache); err != nil {
 			return err
 		}
 	}
 
-	if _, ok := projectFileMap[predictor.Path]; !ok {
+	implBytes, ok := projectFileMap[predictor.Path]
+	if !ok {
 		return errors.Wrap(ErrorImplDoesNotExist(predictor.Path), PathKey)
 	}
 
+	if err := validatePredictorClass(predictor.Type, predictor.Path, implBytes); err != nil {
+		return errors.Wrap(err, PathKey)
+	}
+
+	if err := validateSecretReferences(predictor.Config); err != nil {
+		return errors.Wrap(err, ConfigKey)
+	}
+
+	if predictor.Explainer != nil {
+		if err := predictor.Explainer.Validate(predictor.Type, projectFileMap); err != nil {
+			return errors.Wrap(err, ExplainerKey)
+		}
+	}
+
 	if predictor.PythonPath != nil {
 		if err := ValidatePythonPath(*predictor.PythonPath, projectFileMap); err != nil {
 			return err
@@ -351,82 +624,294 @@ func (predictor *Predictor) Validate(projectFileMap map[string][]byte) error {
 	return nil
 }
 
-func (predictor *Predictor) TensorFlowValidate() error {
-	if predictor.Model == nil {
-		return ErrorFieldMustBeDefinedForPredictorType(ModelKey, TensorFlowPredictorType)
-	}
-
+// TensorFlowValidate checks that the model exists and is supported by the pinned version of TensorFlow Serving (the fields which are required or supported for the predictor type are checked when the config is parsed)
+func (predictor *Predictor) TensorFlowValidate(cache *s3Cache) error {
 	model := *predictor.Model
 
-	awsClient, err := aws.NewFromS3Path(model, false)
-	if err != nil {
-		return err
-	}
-	if strings.HasSuffix(model, ".zip") {
-		if ok, err := awsClient.IsS3PathFile(model); err != nil || !ok {
-			return errors.Wrap(ErrorExternalNotFound(model), ModelKey)
+	path, err := cache.modelPath("tensorflow:"+model, func() (string, error) {
+		awsClient, err := cache.client(model)
+		if err != nil {
+			return "", err
+		}
+		if strings.HasSuffix(model, ".zip") {
+			if ok, err := awsClient.IsS3PathFile(model); err != nil || !ok {
+				return "", errors.Wrap(ErrorExternalNotFound(model), ModelKey)
+			}
+			return model, nil
 		}
-	} else {
 		path, err := GetTFServingExportFromS3Path(model, awsClient)
 		if path == "" || err != nil {
-			return errors.Wrap(ErrorInvalidTensorFlowDir(model), ModelKey)
+			return "", errors.Wrap(ErrorInvalidTensorFlowDir(model), ModelKey)
+		}
+		return path, nil
+	}, tensorFlowModelETagPath)
+	if err != nil {
+		return err
+	}
+
+	predictor.Model = pointer.String(path)
+
+	// zipped models are only extracted by the API's downloader
+	if predictor.TensorFlowServingVersion != nil && !strings.HasSuffix(path, ".zip") {
+		servingVersion := *predictor.TensorFlowServingVersion
+		err := cache.check("tensorflow_serving_version:"+servingVersion+":"+path, func() error {
+			awsClient, err := cache.client(path)
+			if err != nil {
+				return err
+			}
+			return checkTensorFlowServingVersion(path, servingVersion, awsClient)
+		})
+		if err != nil {
+			return errors.Wrap(err, TensorFlowServingVersionKey)
 		}
-		predictor.Model = pointer.String(path)
 	}
 
 	return nil
 }
 
-func (predictor *Predictor) ONNXValidate() error {
-	if predictor.Model == nil {
-		return ErrorFieldMustBeDefinedForPredictorType(ModelKey, ONNXPredictorType)
+// tensorFlowModelETagPath returns the file which identifies the version of a validated TensorFlow model: zipped models are identified by their zip file,
+// but a newer version of an exported model can be uploaded under the model's prefix (which is only found by listing the prefix again), so export directories are validated on each deploy
+func tensorFlowModelETagPath(path string) string {
+	if strings.HasSuffix(path, ".zip") {
+		return path
 	}
+	return ""
+}
 
+// ONNXValidate checks that the model exists and is supported by the pinned version of ONNX Runtime (the fields which are required or supported for the predictor type are checked when the config is parsed)
+func (predictor *Predictor) ONNXValidate(cache *s3Cache) error {
 	model := *predictor.Model
 
-	awsClient, err := aws.NewFromS3Path(model, false)
+	_, err := cache.modelPath("onnx:"+model, func() (string, error) {
+		awsClient, err := cache.client(model)
+		if err != nil {
+			return "", err
+		}
+		if ok, err := awsClient.IsS3PathFile(model); err != nil || !ok {
+			return "", errors.Wrap(ErrorExternalNotFound(model), ModelKey)
+		}
+		return model, nil
+	}, func(path string) string {
+		return path
+	})
 	if err != nil {
 		return err
 	}
-	if ok, err := awsClient.IsS3PathFile(model); err != nil || !ok {
-		return errors.Wrap(ErrorExternalNotFound(model), ModelKey)
-	}
 
-	if predictor.SignatureKey != nil {
-		return ErrorFieldNotSupportedByPredictorType(SignatureKeyKey, ONNXPredictorType)
+	if predictor.ONNXRuntimeVersion != nil {
+		runtimeVersion := *predictor.ONNXRuntimeVersion
+		err := cache.check("onnx_runtime_version:"+runtimeVersion+":"+model, func() error {
+			awsClient, err := cache.client(model)
+			if err != nil {
+				return err
+			}
+			return checkONNXRuntimeVersion(model, runtimeVersion, awsClient)
+		})
+		if err != nil {
+			return errors.Wrap(err, ONNXRuntimeVersionKey)
+		}
 	}
 
 	return nil
 }
 
-func (predictor *Predictor) PythonValidate() error {
-	if predictor.SignatureKey != nil {
-		return ErrorFieldNotSupportedByPredictorType(SignatureKeyKey, PythonPredictorType)
+// Endpoint prefixes which are reserved for cortex (APIs can't be served at or under these paths)
+var ReservedEndpointPrefixes = []string{"/healthz", "/metrics", "/logs"}
+
+func (api *API) Validate(deploymentName string, projectFileMap map[string][]byte, cache *s3Cache) error {
+	if api.Endpoint == nil {
+		api.Endpoint = pointer.String(urls.CanonicalizeEndpoint("/" + deploymentName + "/" + api.Name))
 	}
 
-	if predictor.Model != nil {
-		return ErrorFieldNotSupportedByPredictorType(ModelKey, PythonPredictorType)
+	for _, reservedPrefix := range ReservedEndpointPrefixes {
+		if urls.EndpointHasPrefix(*api.Endpoint, reservedPrefix) {
+			return errors.Wrap(ErrorReservedEndpoint(*api.Endpoint, reservedPrefix), Identify(api), EndpointKey)
+		}
 	}
 
-	return nil
-}
+	if api.UpdateStrategy.Mode == BlueGreenUpdateMode {
+		if api.UpdateStrategy.PreviewEndpoint == nil {
+			api.UpdateStrategy.PreviewEndpoint = pointer.String(urls.CanonicalizeEndpoint(*api.Endpoint + "/preview"))
+		}
 
-func (api *API) Validate(deploymentName string, projectFileMap map[string][]byte) error {
-	if api.Endpoint == nil {
-		api.Endpoint = pointer.String("/" + deploymentName + "/" + api.Name)
+		previewEndpoint := *api.UpdateStrategy.PreviewEndpoint
+		if previewEndpoint == *api.Endpoint {
+			return errors.Wrap(ErrorPreviewEndpointMatchesEndpoint(previewEndpoint), Identify(api), UpdateStrategyKey, PreviewEndpointKey)
+		}
+		for _, reservedPrefix := range ReservedEndpointPrefixes {
+			if urls.EndpointHasPrefix(previewEndpoint, reservedPrefix) {
+				return errors.Wrap(ErrorReservedEndpoint(previewEndpoint, reservedPrefix), Identify(api), UpdateStrategyKey, PreviewEndpointKey)
+			}
+		}
 	}
 
-	if err := api.Predictor.Validate(projectFileMap); err != nil {
-		return errors.Wrap(err, Identify(api), PredictorKey)
+	// pipelines are validated once all of the APIs have been validated, since they depend on their steps' APIs
+	if api.Pipeline == nil {
+		if err := api.Predictor.Validate(projectFileMap, cache); err != nil {
+			return errors.Wrap(err, Identify(api), PredictorKey)
+		}
 	}
 
 	if err := api.Compute.Validate(); err != nil {
 		return errors.Wrap(err, Identify(api), ComputeKey)
 	}
 
+	// each process needs a cpu of its own (requests for fractions of a cpu are rounded up)
+	maxProcesses := (api.Compute.CPU.MilliValue() + 999) / 1000
+	if int64(api.Predictor.ProcessesPerReplica) > maxProcesses {
+		return errors.Wrap(ErrorProcessesExceedCPU(api.Predictor.ProcessesPerReplica, api.Compute.CPU.String()), Identify(api), PredictorKey, ProcessesPerReplicaKey)
+	}
+
+	if api.Compute.GPU > 0 && (IsModelFilePredictorType(api.Predictor.Type) || api.Pipeline != nil) {
+		return errors.Wrap(ErrorGPUNotSupportedByPredictorType(api.Predictor.Type), Identify(api), ComputeKey, GPUKey)
+	}
+
+	if api.Compute.Arch == ARM64Arch {
+		if !IsARM64PredictorType(api.Predictor.Type) {
+			return errors.Wrap(ErrorARM64NotSupported("the "+api.Predictor.Type.String()+" predictor type"), Identify(api), ComputeKey, ArchKey)
+		}
+		if api.Compute.GPU > 0 {
+			return errors.Wrap(ErrorARM64NotSupported("gpus"), Identify(api), ComputeKey, GPUKey)
+		}
+		if api.Predictor.PythonVersion != nil && *api.Predictor.PythonVersion != DefaultPythonVersion {
+			return errors.Wrap(ErrorARM64NotSupported("python "+*api.Predictor.PythonVersion), Identify(api), PredictorKey, PythonVersionKey)
+		}
+	}
+
+	if err := api.Init.Validate(); err != nil {
+		return errors.Wrap(err, Identify(api), InitKey)
+	}
+
+	if err := api.Sidecars.Validate(); err != nil {
+		return errors.Wrap(err, Identify(api), SidecarsKey)
+	}
+
+	if err := api.Volumes.Validate(); err != nil {
+		return errors.Wrap(err, Identify(api), VolumesKey)
+	}
+
+	if err := api.Files.Validate(projectFileMap, api.Volumes); err != nil {
+		return errors.Wrap(err, Identify(api), FilesKey)
+	}
+
+	if err := api.Networking.Validate(api.Predictor.Type); err != nil {
+		return errors.Wrap(err, Identify(api), NetworkingKey)
+	}
+
+	// blue/green and canary updates switch traffic between deployments with the API's load balancer routes
+	if api.IsClusterLocal() && api.UpdateStrategy != nil && (api.UpdateStrategy.Mode == BlueGreenUpdateMode || api.UpdateStrategy.Mode == CanaryUpdateMode) {
+		return errors.Wrap(ErrorNotSupportedByClusterLocal(ModeKey+": "+api.UpdateStrategy.Mode.String()), Identify(api), UpdateStrategyKey, ModeKey)
+	}
+
+	if api.Predictor.Batching != nil {
+		if err := api.Predictor.Batching.Validate(api.Predictor.Type, api.Networking); err != nil {
+			return errors.Wrap(err, Identify(api), PredictorKey, BatchingKey)
+		}
+	}
+
+	if api.Predictor.Explainer != nil {
+		if err := api.Predictor.Explainer.ValidateNetworking(api.Networking); err != nil {
+			return errors.Wrap(err, Identify(api), PredictorKey, ExplainerKey)
+		}
+	}
+
+	if api.Networking.LoadShedding != nil {
+		if err := api.Networking.LoadShedding.Validate(api.Predictor, api.Networking); err != nil {
+			return errors.Wrap(err, Identify(api), NetworkingKey, LoadSheddingKey)
+		}
+	}
+
+	// HPA scale-downs terminate pods regardless of their open connections
+	if api.Networking.Protocol == WebSocketProtocol && api.Compute.MinReplicas != api.Compute.MaxReplicas {
+		return errors.Wrap(ErrorWebSocketAutoscaling(api.Compute.MinReplicas, api.Compute.MaxReplicas), Identify(api), ComputeKey)
+	}
+	if api.Networking.Protocol == WebSocketProtocol {
+		for i, schedule := range api.Compute.Schedules {
+			if schedule.MinReplicas != schedule.MaxReplicas {
+				return errors.Wrap(ErrorWebSocketAutoscaling(schedule.MinReplicas, schedule.MaxReplicas), Identify(api), ComputeKey, SchedulesKey, s.Index(i))
+			}
+		}
+		if api.Compute.Idle != nil && api.Compute.Idle.Action != NoneIdleAction {
+			return errors.Wrap(ErrorWebSocketIdleAction(api.Compute.Idle.Action), Identify(api), ComputeKey, IdleKey)
+		}
+	}
+
+	if api.Experiment != nil {
+		if err := api.Experiment.Validate(api.Predictor.Type); err != nil {
+			return errors.Wrap(err, Identify(api), ExperimentKey)
+		}
+		if api.Predictor.ConfigReload {
+			return errors.Wrap(ErrorConfigReloadWithExperiment(), Identify(api), PredictorKey, ConfigReloadKey)
+		}
+	}
+
+	if api.PredictionLogging != nil {
+		if err := api.PredictionLogging.Validate(cache); err != nil {
+			return errors.Wrap(err, Identify(api), PredictionLoggingKey)
+		}
+	}
+
+	if api.Cache != nil {
+		if err := api.Cache.Validate(api.Networking); err != nil {
+			return errors.Wrap(err, Identify(api), CacheKey)
+		}
+	}
+
+	if api.Feedback != nil {
+		if err := api.Feedback.Validate(api.Tracker, api.Networking, cache); err != nil {
+			return errors.Wrap(err, Identify(api), FeedbackKey)
+		}
+	}
+
+	if err := api.Alerts.Validate(api.Tracker, cache); err != nil {
+		return errors.Wrap(err, Identify(api), AlertsKey)
+	}
+
+	initNames := strset.New(api.Init.Names()...)
+	for i, container := range api.Sidecars {
+		if initNames.Has(container.Name) {
+			return errors.Wrap(ErrorDuplicateContainerName(container.Name), Identify(api), SidecarsKey, s.Index(i), NameKey)
+		}
+	}
+
 	return nil
 }
 
+// Endpoints returns the endpoints which the API is served at (including its preview endpoint, if it has one);
+// cluster-local APIs aren't served by a load balancer, so they have none
+func (api *API) Endpoints() []string {
+	if api.IsClusterLocal() {
+		return nil
+	}
+	endpoints := []string{*api.Endpoint}
+	if api.UpdateStrategy != nil && api.UpdateStrategy.PreviewEndpoint != nil {
+		endpoints = append(endpoints, *api.UpdateStrategy.PreviewEndpoint)
+	}
+	for _, endpoint := range endpoints {
+		for _, subpath := range api.Subpaths() {
+			endpoints = append(endpoints, urls.Join(endpoint, subpath))
+		}
+	}
+	return endpoints
+}
+
+// Subpaths returns the paths under the API's endpoints which are also routed to the API (e.g. its explain endpoint)
+func (api *API) Subpaths() []string {
+	var subpaths []string
+	if api.Predictor != nil && api.Predictor.Explainer != nil {
+		subpaths = append(subpaths, ExplainSubpath)
+	}
+	if api.Feedback != nil {
+		subpaths = append(subpaths, FeedbackSubpath)
+	}
+	return subpaths
+}
+
+// IsClusterLocal returns whether the API is only reachable from within the cluster (at its Kubernetes service)
+func (api *API) IsClusterLocal() bool {
+	return api.Networking != nil && api.Networking.Expose == ClusterLocalExpose
+}
+
 func (api *API) GetResourceType() resource.Type {
 	return resource.APIType
 }
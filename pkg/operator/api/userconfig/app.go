@@ -21,7 +21,8 @@ import (
 )
 
 type App struct {
-	Name string `json:"name" yaml:"name"`
+	Name    string        `json:"name" yaml:"name"`
+	Project *ProjectFiles `json:"project" yaml:"project"`
 }
 
 var appValidation = &cr.StructValidation{
@@ -35,6 +36,7 @@ var appValidation = &cr.StructValidation{
 				DNS1123:                    true,
 			},
 		},
+		projectFilesFieldValidation,
 		typeFieldValidation,
 	},
 }
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+// Arch is the CPU architecture of the nodes which an API's replicas run on (the values match the nodes' kubernetes.io/arch labels)
+type Arch int
+
+const (
+	UnknownArch Arch = iota
+	AMD64Arch
+	ARM64Arch
+)
+
+var archs = []string{
+	"unknown",
+	"amd64",
+	"arm64",
+}
+
+// Predictor types whose images are built for arm64 (with the default python version)
+var _arm64PredictorTypes = map[PredictorType]bool{
+	PythonPredictorType:   true,
+	SKLearnPredictorType:  true,
+	XGBoostPredictorType:  true,
+	LightGBMPredictorType: true,
+	PipelinePredictorType: true,
+}
+
+func IsARM64PredictorType(predictorType PredictorType) bool {
+	return _arm64PredictorTypes[predictorType]
+}
+
+// ARM64PredictorTypes returns the predictor types which can be deployed on arm64 (pipelines can also be deployed on arm64)
+func ARM64PredictorTypes() []string {
+	var predictorTypes []string
+	for _, predictorType := range PredictorTypeStrings() {
+		if _arm64PredictorTypes[PredictorTypeFromString(predictorType)] {
+			predictorTypes = append(predictorTypes, predictorType)
+		}
+	}
+	return predictorTypes
+}
+
+func ArchFromString(s string) Arch {
+	for i := 0; i < len(archs); i++ {
+		if s == archs[i] {
+			return Arch(i)
+		}
+	}
+	return UnknownArch
+}
+
+func ArchStrings() []string {
+	return archs[1:]
+}
+
+func (t Arch) String() string {
+	return archs[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t Arch) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *Arch) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(archs); i++ {
+		if enum == archs[i] {
+			*t = Arch(i)
+			return nil
+		}
+	}
+
+	*t = UnknownArch
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *Arch) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t Arch) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/jwks"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/yaml"
+)
+
+// claim names are used in istio's request.auth.claims[<name>] conditions, so they can't contain brackets
+var _claimNameRegex = regexp.MustCompile(`^[^\[\]\s]+$`)
+
+type Auth struct {
+	JWT *JWTAuth `json:"jwt" yaml:"jwt"`
+}
+
+// JWTAuth requires requests to the API to have a bearer token which was signed by the issuer
+type JWTAuth struct {
+	Issuer         string            `json:"issuer" yaml:"issuer"`
+	JWKSURI        string            `json:"jwks_uri" yaml:"jwks_uri"`
+	Audiences      []string          `json:"audiences" yaml:"audiences"`
+	RequiredClaims map[string]string `json:"required_claims" yaml:"required_claims"` // claim name -> the value which the claim must have (or contain, if the claim is a list)
+}
+
+var authFieldValidation = &cr.StructFieldValidation{
+	StructField: "Auth",
+	StructValidation: &cr.StructValidation{
+		DefaultNil: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "JWT",
+				StructValidation: &cr.StructValidation{
+					Required: true,
+					StructFieldValidations: []*cr.StructFieldValidation{
+						{
+							StructField: "Issuer",
+							StringValidation: &cr.StringValidation{
+								Required: true,
+							},
+						},
+						{
+							StructField: "JWKSURI",
+							StringValidation: &cr.StringValidation{
+								Required:  true,
+								Validator: validateJWKSURI,
+							},
+						},
+						{
+							StructField: "Audiences",
+							StringListValidation: &cr.StringListValidation{
+								AllowEmpty:   true,
+								DisallowDups: true,
+							},
+						},
+						{
+							StructField: "RequiredClaims",
+							StringMapValidation: &cr.StringMapValidation{
+								Default:    map[string]string{},
+								AllowEmpty: true,
+								Validator:  validateRequiredClaims,
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func validateJWKSURI(uri string) (string, error) {
+	if err := jwks.ValidateURI(uri); err != nil {
+		return "", err
+	}
+	return uri, nil
+}
+
+func validateRequiredClaims(claims map[string]string) (map[string]string, error) {
+	for name := range claims {
+		if !_claimNameRegex.MatchString(name) {
+			return nil, ErrorInvalidClaimName(name)
+		}
+	}
+	return claims, nil
+}
+
+func (auth *Auth) UserConfigStr() string {
+	var sb strings.Builder
+	if auth.JWT != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", JWTKey))
+		sb.WriteString(s.Indent(auth.JWT.UserConfigStr(), "  "))
+	}
+	return sb.String()
+}
+
+func (jwt *JWTAuth) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", IssuerKey, jwt.Issuer))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", JWKSURIKey, jwt.JWKSURI))
+	if len(jwt.Audiences) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", AudiencesKey, s.ObjFlatNoQuotes(jwt.Audiences)))
+	}
+	if len(jwt.RequiredClaims) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", RequiredClaimsKey))
+		d, _ := yaml.Marshal(&jwt.RequiredClaims)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
+	return sb.String()
+}
+
+// JWTAuth returns the API's JWT authentication (nil if requests to the API don't require a token)
+func (api *API) JWTAuth() *JWTAuth {
+	if api.Networking == nil || api.Networking.Auth == nil {
+		return nil
+	}
+	return api.Networking.Auth.JWT
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+type Batching struct {
+	MaxBatchSize  int32         `json:"max_batch_size" yaml:"max_batch_size"`
+	BatchInterval time.Duration `json:"batch_interval" yaml:"batch_interval"`
+}
+
+var batchingFieldValidation = &cr.StructFieldValidation{
+	StructField: "Batching",
+	StructValidation: &cr.StructValidation{
+		DefaultNil: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "MaxBatchSize",
+				Int32Validation: &cr.Int32Validation{
+					Required:    true,
+					GreaterThan: pointer.Int32(0),
+				},
+			},
+			{
+				StructField: "BatchInterval",
+				DurationValidation: &cr.DurationValidation{
+					Required:    true,
+					GreaterThan: pointer.Duration(0),
+				},
+			},
+		},
+	},
+}
+
+// TF Serving batches requests itself, and the python predictor's requests are batched by the api (its predict() receives a list of payloads)
+func IsBatchingPredictorType(predictorType PredictorType) bool {
+	return predictorType == TensorFlowPredictorType || predictorType == PythonPredictorType
+}
+
+func (batching *Batching) Validate(predictorType PredictorType, networking *Networking) error {
+	if !IsBatchingPredictorType(predictorType) {
+		return ErrorBatchingNotSupportedByPredictorType(predictorType)
+	}
+
+	// streamed request bodies are read by the predictor itself, and websocket messages are predicted one at a time per connection
+	if networking.StreamRequests {
+		return ErrorBatchingNotSupportedByNetworking(s.UserStr(StreamRequestsKey))
+	}
+	if networking.Protocol == WebSocketProtocol {
+		return ErrorBatchingNotSupportedByNetworking(fmt.Sprintf("the %s protocol", WebSocketProtocol.String()))
+	}
+
+	return nil
+}
+
+func (batching *Batching) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxBatchSizeKey, s.Int32(batching.MaxBatchSize)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", BatchIntervalKey, batching.BatchInterval.String()))
+	return sb.String()
+}
+
+// TFServingBatchingParameters is the contents of TF Serving's --batching_parameters_file
+func (batching *Batching) TFServingBatchingParameters() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("max_batch_size { value: %d }\n", batching.MaxBatchSize))
+	sb.WriteString(fmt.Sprintf("batch_timeout_micros { value: %d }\n", batching.BatchInterval.Microseconds()))
+	return sb.String()
+}
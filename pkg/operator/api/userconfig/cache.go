@@ -0,0 +1,113 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+const (
+	CacheBodyVariable         = "body:hash" // ${body:hash} is replaced with the hash of the request body
+	CacheHeaderVariablePrefix = "header:"   // ${header:<name>} is replaced with the value of the request header
+)
+
+// cache key variables are of the form ${<source>:<name>}, so that they aren't confused with the config file's ${VAR} variables
+var _cacheKeyVariableRegex = regexp.MustCompile(`\$\{([^{}]*)\}`)
+
+// Cache configures the caching of an API's responses, which are stored by a redis sidecar in each of the API's pods
+type Cache struct {
+	TTL        time.Duration `json:"ttl" yaml:"ttl"`
+	MaxEntries int64         `json:"max_entries" yaml:"max_entries"`
+	Key        string        `json:"key" yaml:"key"` // requests whose keys are equal receive the same (cached) response
+}
+
+var cacheFieldValidation = &cr.StructFieldValidation{
+	StructField: "Cache",
+	StructValidation: &cr.StructValidation{
+		DefaultNil: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "TTL",
+				DurationValidation: &cr.DurationValidation{
+					Default:     time.Hour,
+					GreaterThan: pointer.Duration(0),
+				},
+			},
+			{
+				StructField: "MaxEntries",
+				Int64Validation: &cr.Int64Validation{
+					Default:     10000,
+					GreaterThan: pointer.Int64(0),
+				},
+			},
+			{
+				StructField: "Key",
+				StringValidation: &cr.StringValidation{
+					Default:   "${" + CacheBodyVariable + "}",
+					Validator: validateCacheKey,
+				},
+			},
+		},
+	},
+}
+
+func validateCacheKey(key string) (string, error) {
+	matches := _cacheKeyVariableRegex.FindAllStringSubmatch(key, -1)
+	if len(matches) == 0 {
+		return "", ErrorCacheKeyWithoutVariables(key)
+	}
+	for _, match := range matches {
+		variable := match[1]
+		if variable == CacheBodyVariable {
+			continue
+		}
+		if strings.HasPrefix(variable, CacheHeaderVariablePrefix) {
+			if _, err := validateHeaderName(strings.TrimPrefix(variable, CacheHeaderVariablePrefix)); err != nil {
+				return "", err
+			}
+			continue
+		}
+		return "", ErrorInvalidCacheKeyVariable(match[0])
+	}
+	return key, nil
+}
+
+func (cache *Cache) Validate(networking *Networking) error {
+	// the cache stores buffered http responses
+	if networking.StreamRequests {
+		return ErrorCacheNotSupportedByNetworking(s.UserStr(StreamRequestsKey))
+	}
+	if networking.Protocol == WebSocketProtocol {
+		return ErrorCacheNotSupportedByNetworking(fmt.Sprintf("the %s protocol", WebSocketProtocol.String()))
+	}
+	return nil
+}
+
+func (cache *Cache) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", TTLKey, cache.TTL.String()))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxEntriesKey, s.Int64(cache.MaxEntries)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", KeyKey, cache.Key))
+	return sb.String()
+}
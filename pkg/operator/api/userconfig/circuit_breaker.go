@@ -0,0 +1,121 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// CircuitBreaker limits the requests which are sent to the API's replicas, and ejects replicas which respond with consecutive 5xx errors;
+// it's enforced by the istio proxies which route requests to the API (e.g. the load balancer's gateway)
+type CircuitBreaker struct {
+	MaxConnections       *int32        `json:"max_connections" yaml:"max_connections"`
+	MaxPendingRequests   *int32        `json:"max_pending_requests" yaml:"max_pending_requests"`
+	MaxRequests          *int32        `json:"max_requests" yaml:"max_requests"`
+	Consecutive5xxErrors *int32        `json:"consecutive_5xx_errors" yaml:"consecutive_5xx_errors"` // replicas aren't ejected if nil
+	Interval             time.Duration `json:"interval" yaml:"interval"`
+	BaseEjectionTime     time.Duration `json:"base_ejection_time" yaml:"base_ejection_time"`
+	MaxEjectionPercent   int32         `json:"max_ejection_percent" yaml:"max_ejection_percent"`
+}
+
+var circuitBreakerFieldValidation = &cr.StructFieldValidation{
+	StructField: "CircuitBreaker",
+	StructValidation: &cr.StructValidation{
+		DefaultNil: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "MaxConnections",
+				Int32PtrValidation: &cr.Int32PtrValidation{
+					GreaterThan: pointer.Int32(0),
+				},
+			},
+			{
+				StructField: "MaxPendingRequests",
+				Int32PtrValidation: &cr.Int32PtrValidation{
+					GreaterThan: pointer.Int32(0),
+				},
+			},
+			{
+				StructField: "MaxRequests",
+				Int32PtrValidation: &cr.Int32PtrValidation{
+					GreaterThan: pointer.Int32(0),
+				},
+			},
+			{
+				StructField: "Consecutive5xxErrors",
+				Int32PtrValidation: &cr.Int32PtrValidation{
+					GreaterThan: pointer.Int32(0),
+				},
+			},
+			{
+				StructField: "Interval",
+				DurationValidation: &cr.DurationValidation{
+					Default:              10 * time.Second,
+					GreaterThanOrEqualTo: pointer.Duration(time.Second),
+				},
+			},
+			{
+				StructField: "BaseEjectionTime",
+				DurationValidation: &cr.DurationValidation{
+					Default:              30 * time.Second,
+					GreaterThanOrEqualTo: pointer.Duration(time.Second),
+				},
+			},
+			{
+				StructField: "MaxEjectionPercent",
+				Int32Validation: &cr.Int32Validation{
+					Default:           50,
+					GreaterThan:       pointer.Int32(0),
+					LessThanOrEqualTo: pointer.Int32(100),
+				},
+			},
+		},
+	},
+}
+
+func (circuitBreaker *CircuitBreaker) Validate() error {
+	if circuitBreaker.MaxConnections == nil && circuitBreaker.MaxPendingRequests == nil && circuitBreaker.MaxRequests == nil && circuitBreaker.Consecutive5xxErrors == nil {
+		return ErrorEmptyCircuitBreaker()
+	}
+	return nil
+}
+
+func (circuitBreaker *CircuitBreaker) UserConfigStr() string {
+	var sb strings.Builder
+	if circuitBreaker.MaxConnections != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxConnectionsKey, s.Int32(*circuitBreaker.MaxConnections)))
+	}
+	if circuitBreaker.MaxPendingRequests != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxPendingRequestsKey, s.Int32(*circuitBreaker.MaxPendingRequests)))
+	}
+	if circuitBreaker.MaxRequests != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxRequestsKey, s.Int32(*circuitBreaker.MaxRequests)))
+	}
+	if circuitBreaker.Consecutive5xxErrors != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", Consecutive5xxErrorsKey, s.Int32(*circuitBreaker.Consecutive5xxErrors)))
+		sb.WriteString(fmt.Sprintf("%s: %s\n", IntervalKey, circuitBreaker.Interval.String()))
+		sb.WriteString(fmt.Sprintf("%s: %s\n", BaseEjectionTimeKey, circuitBreaker.BaseEjectionTime.String()))
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxEjectionPercentKey, s.Int32(circuitBreaker.MaxEjectionPercent)))
+	}
+	return sb.String()
+}
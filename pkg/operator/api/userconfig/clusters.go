@@ -0,0 +1,49 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+)
+
+var clustersFieldValidation = &cr.StructFieldValidation{
+	StructField: "Clusters",
+	StringListValidation: &cr.StringListValidation{
+		AllowEmpty:   true,
+		DisallowDups: true,
+	},
+}
+
+// SelectsCluster returns whether the API is deployed to the cluster (by its cluster_name); APIs which don't list any clusters are deployed to every cluster
+func (api *API) SelectsCluster(clusterName string) bool {
+	return len(api.Clusters) == 0 || slices.HasString(api.Clusters, clusterName)
+}
+
+// SelectCluster returns the APIs which are deployed to the cluster, and the names of the APIs which aren't
+func (apis APIs) SelectCluster(clusterName string) (APIs, []string) {
+	var selected APIs
+	var excludedNames []string
+	for _, api := range apis {
+		if api.SelectsCluster(clusterName) {
+			selected = append(selected, api)
+		} else {
+			excludedNames = append(excludedNames, api.Name)
+		}
+	}
+	return selected, excludedNames
+}
@@ -27,10 +27,13 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
+	"time"
 
 	kresource "k8s.io/apimachinery/pkg/api/resource"
 
 	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/cron"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/hash"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
@@ -38,15 +41,36 @@ import (
 )
 
 type APICompute struct {
-	MinReplicas          int32         `json:"min_replicas" yaml:"min_replicas"`
-	MaxReplicas          int32         `json:"max_replicas" yaml:"max_replicas"`
-	InitReplicas         int32         `json:"init_replicas" yaml:"init_replicas"`
-	TargetCPUUtilization int32         `json:"target_cpu_utilization" yaml:"target_cpu_utilization"`
-	CPU                  k8s.Quantity  `json:"cpu" yaml:"cpu"`
-	Mem                  *k8s.Quantity `json:"mem" yaml:"mem"`
-	GPU                  int64         `json:"gpu" yaml:"gpu"`
+	MinReplicas          int32              `json:"min_replicas" yaml:"min_replicas"`
+	MaxReplicas          int32              `json:"max_replicas" yaml:"max_replicas"`
+	InitReplicas         int32              `json:"init_replicas" yaml:"init_replicas"`
+	TargetCPUUtilization int32              `json:"target_cpu_utilization" yaml:"target_cpu_utilization"`
+	TargetGPUUtilization *int32             `json:"target_gpu_utilization" yaml:"target_gpu_utilization"`
+	CPU                  k8s.Quantity       `json:"cpu" yaml:"cpu"`
+	Mem                  *k8s.Quantity      `json:"mem" yaml:"mem"`
+	GPU                  int64              `json:"gpu" yaml:"gpu"`
+	Schedules            []*ReplicaSchedule `json:"schedules" yaml:"schedules"`
+	Idle                 *Idle              `json:"idle" yaml:"idle"`
+	Priority             Priority           `json:"priority" yaml:"priority"`
+	Arch                 Arch               `json:"arch" yaml:"arch"`
 }
 
+// ReplicaSchedule overrides the API's min and max replicas for Duration each time Cron fires (cron expressions are evaluated in UTC)
+type ReplicaSchedule struct {
+	Cron        string        `json:"cron" yaml:"cron"`
+	Duration    time.Duration `json:"duration" yaml:"duration"`
+	MinReplicas int32         `json:"min_replicas" yaml:"min_replicas"`
+	MaxReplicas int32         `json:"max_replicas" yaml:"max_replicas"`
+}
+
+// Idle flags the API as idle once it hasn't received any requests for Window, and then applies Action until it receives a request
+type Idle struct {
+	Window time.Duration `json:"window" yaml:"window"`
+	Action IdleAction    `json:"action" yaml:"action"`
+}
+
+const _week = 7 * 24 * time.Hour
+
 var apiComputeFieldValidation = &cr.StructFieldValidation{
 	StructField: "Compute",
 	StructValidation: &cr.StructValidation{
@@ -79,6 +103,13 @@ var apiComputeFieldValidation = &cr.StructFieldValidation{
 					GreaterThan: pointer.Int32(0),
 				},
 			},
+			{
+				StructField: "TargetGPUUtilization",
+				Int32PtrValidation: &cr.Int32PtrValidation{
+					GreaterThan:       pointer.Int32(0),
+					LessThanOrEqualTo: pointer.Int32(100),
+				},
+			},
 			{
 				StructField: "CPU",
 				StringValidation: &cr.StringValidation{
@@ -92,7 +123,8 @@ var apiComputeFieldValidation = &cr.StructFieldValidation{
 			{
 				StructField: "Mem",
 				StringPtrValidation: &cr.StringPtrValidation{
-					Default: nil,
+					Default:     nil,
+					CastNumeric: true,
 				},
 				Parser: k8s.QuantityParser(&k8s.QuantityValidation{
 					GreaterThan: k8s.QuantityPtr(kresource.MustParse("0")),
@@ -105,6 +137,91 @@ var apiComputeFieldValidation = &cr.StructFieldValidation{
 					GreaterThanOrEqualTo: pointer.Int64(0),
 				},
 			},
+			{
+				StructField: "Schedules",
+				StructListValidation: &cr.StructListValidation{
+					AllowExplicitNull: true,
+					StructValidation: &cr.StructValidation{
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "Cron",
+								StringValidation: &cr.StringValidation{
+									Required:  true,
+									Validator: validateScheduleCron,
+								},
+							},
+							{
+								StructField: "Duration",
+								DurationValidation: &cr.DurationValidation{
+									Required:             true,
+									GreaterThanOrEqualTo: pointer.Duration(time.Minute),
+									LessThanOrEqualTo:    pointer.Duration(_week),
+								},
+							},
+							{
+								StructField: "MinReplicas",
+								Int32Validation: &cr.Int32Validation{
+									Required:    true,
+									GreaterThan: pointer.Int32(0),
+								},
+							},
+							{
+								StructField: "MaxReplicas",
+								Int32Validation: &cr.Int32Validation{
+									Required:    true,
+									GreaterThan: pointer.Int32(0),
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				StructField: "Idle",
+				StructValidation: &cr.StructValidation{
+					DefaultNil: true,
+					StructFieldValidations: []*cr.StructFieldValidation{
+						{
+							StructField: "Window",
+							DurationValidation: &cr.DurationValidation{
+								Default:              24 * time.Hour,
+								GreaterThanOrEqualTo: pointer.Duration(time.Hour),
+								LessThanOrEqualTo:    pointer.Duration(30 * 24 * time.Hour),
+							},
+						},
+						{
+							StructField: "Action",
+							StringValidation: &cr.StringValidation{
+								AllowedValues: IdleActionStrings(),
+								Default:       NoneIdleAction.String(),
+							},
+							Parser: func(str string) (interface{}, error) {
+								return IdleActionFromString(str), nil
+							},
+						},
+					},
+				},
+			},
+			{
+				StructField: "Priority",
+				StringValidation: &cr.StringValidation{
+					AllowedValues: PriorityStrings(),
+					Default:       MediumPriority.String(),
+				},
+				Parser: func(str string) (interface{}, error) {
+					return PriorityFromString(str), nil
+				},
+			},
+			{
+				StructField: "Arch",
+				StringValidation: &cr.StringValidation{
+					AllowedValues: ArchStrings(),
+					Default:       AMD64Arch.String(),
+				},
+				Parser: func(str string) (interface{}, error) {
+					return ArchFromString(str), nil
+				},
+			},
 		},
 	},
 }
@@ -115,7 +232,11 @@ func (ac *APICompute) UserConfigStr() string {
 	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxReplicasKey, s.Int32(ac.MaxReplicas)))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", InitReplicasKey, s.Int32(ac.InitReplicas)))
 	if ac.MinReplicas != ac.MaxReplicas {
-		sb.WriteString(fmt.Sprintf("%s: %s\n", TargetCPUUtilizationKey, s.Int32(ac.TargetCPUUtilization)))
+		if ac.TargetGPUUtilization != nil {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", TargetGPUUtilizationKey, s.Int32(*ac.TargetGPUUtilization)))
+		} else {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", TargetCPUUtilizationKey, s.Int32(ac.TargetCPUUtilization)))
+		}
 	}
 	sb.WriteString(fmt.Sprintf("%s: %s\n", CPUKey, ac.CPU.UserString))
 	if ac.GPU > 0 {
@@ -124,9 +245,51 @@ func (ac *APICompute) UserConfigStr() string {
 	if ac.Mem != nil {
 		sb.WriteString(fmt.Sprintf("%s: %s\n", MemKey, ac.Mem.UserString))
 	}
+	if len(ac.Schedules) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", SchedulesKey))
+		for _, schedule := range ac.Schedules {
+			scheduleStr := s.Indent(schedule.UserConfigStr(), "    ")
+			sb.WriteString("  - " + strings.TrimPrefix(scheduleStr, "    "))
+		}
+	}
+	if ac.Idle != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", IdleKey))
+		sb.WriteString(s.Indent(ac.Idle.UserConfigStr(), "  "))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", PriorityKey, ac.Priority.String()))
+	if ac.Arch != AMD64Arch {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ArchKey, ac.Arch.String()))
+	}
 	return sb.String()
 }
 
+func (schedule *ReplicaSchedule) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", CronKey, schedule.Cron))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", DurationKey, schedule.Duration.String()))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MinReplicasKey, s.Int32(schedule.MinReplicas)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxReplicasKey, s.Int32(schedule.MaxReplicas)))
+	return sb.String()
+}
+
+func (idle *Idle) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", WindowKey, idle.Window.String()))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ActionKey, idle.Action.String()))
+	return sb.String()
+}
+
+func validateScheduleCron(expr string) (string, error) {
+	schedule, err := cron.Parse(expr)
+	if err != nil {
+		return "", err
+	}
+	if !schedule.IsWeekly() {
+		return "", ErrorScheduleNotWeekly()
+	}
+	return expr, nil
+}
+
 func (ac *APICompute) Validate() error {
 	if ac.MinReplicas > ac.MaxReplicas {
 		return ErrorMinReplicasGreaterThanMax(ac.MinReplicas, ac.MaxReplicas)
@@ -140,18 +303,70 @@ func (ac *APICompute) Validate() error {
 		return ErrorInitReplicasLessThanMin(ac.InitReplicas, ac.MinReplicas)
 	}
 
+	if ac.TargetGPUUtilization != nil && ac.GPU == 0 {
+		return ErrorTargetGPUUtilizationWithoutGPU()
+	}
+
+	for i, schedule := range ac.Schedules {
+		if schedule.MinReplicas > schedule.MaxReplicas {
+			return errors.Wrap(ErrorMinReplicasGreaterThanMax(schedule.MinReplicas, schedule.MaxReplicas), SchedulesKey, s.Index(i))
+		}
+	}
+
+	if err := validateSchedulesOverlap(ac.Schedules); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// Warnings returns issues with the compute configuration which are valid, but likely unintended
+func (ac *APICompute) Warnings() []error {
+	var warnings []error
+
+	if ac.CPU.Cmp(kresource.MustParse("100")) >= 0 {
+		warnings = append(warnings, ErrorCPUWithoutMillicores(ac.CPU.UserString))
+	}
+
+	if ac.Mem != nil && ac.Mem.Cmp(kresource.MustParse("1Mi")) < 0 {
+		warnings = append(warnings, ErrorMemWithoutUnit(ac.Mem.UserString))
+	}
+
+	if ac.TargetCPUUtilization > 100 && ac.TargetGPUUtilization == nil && ac.MinReplicas < ac.MaxReplicas {
+		warnings = append(warnings, ErrorTargetCPUUtilizationAboveRequest(ac.TargetCPUUtilization))
+	}
+
+	return warnings
+}
+
 func (ac *APICompute) ID() string {
 	var buf bytes.Buffer
 	buf.WriteString(s.Int32(ac.MinReplicas))
 	buf.WriteString(s.Int32(ac.MaxReplicas))
 	buf.WriteString(s.Int32(ac.InitReplicas))
 	buf.WriteString(s.Int32(ac.TargetCPUUtilization))
+	if ac.TargetGPUUtilization != nil {
+		buf.WriteString(s.Int32(*ac.TargetGPUUtilization))
+	}
 	buf.WriteString(ac.CPU.ID())
 	buf.WriteString(k8s.QuantityPtrID(ac.Mem))
 	buf.WriteString(s.Int64(ac.GPU))
+	for _, schedule := range ac.Schedules {
+		buf.WriteString(schedule.Cron)
+		buf.WriteString(schedule.Duration.String())
+		buf.WriteString(s.Int32(schedule.MinReplicas))
+		buf.WriteString(s.Int32(schedule.MaxReplicas))
+	}
+	if ac.Idle != nil {
+		buf.WriteString(ac.Idle.Window.String())
+		buf.WriteString(ac.Idle.Action.String())
+	}
+	if ac.Priority != MediumPriority {
+		buf.WriteString(ac.Priority.String())
+	}
+	if ac.Arch != AMD64Arch {
+		buf.WriteString(ac.Arch.String())
+	}
 	return hash.Bytes(buf.Bytes())
 }
 
@@ -163,3 +378,64 @@ func (ac *APICompute) IDWithoutReplicas() string {
 	buf.WriteString(s.Int64(ac.GPU))
 	return hash.Bytes(buf.Bytes())
 }
+
+// validateSchedulesOverlap ensures that at most one schedule is active at any time
+// (the schedules only depend on the time of day and day of week, so it's sufficient to check a single week)
+func validateSchedulesOverlap(schedules []*ReplicaSchedule) error {
+	if len(schedules) < 2 {
+		return nil
+	}
+
+	weekMinutes := int(_week / time.Minute)
+	activeSchedules := make([]int, weekMinutes)                         // minute of the week -> index of the active schedule + 1 (0 if none)
+	weekStart := time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC) // a Sunday
+
+	for i, schedule := range schedules {
+		parsedCron, err := cron.Parse(schedule.Cron)
+		if err != nil {
+			return errors.Wrap(err, SchedulesKey, s.Index(i), CronKey)
+		}
+
+		// Sweep two weeks so that occurrences near the end of the week which wrap around are accounted for
+		durationMinutes := int(schedule.Duration / time.Minute)
+		lastFire := -1
+		for minute := 0; minute < 2*weekMinutes; minute++ {
+			if parsedCron.Matches(weekStart.Add(time.Duration(minute%weekMinutes) * time.Minute)) {
+				lastFire = minute
+			}
+			if lastFire == -1 || minute-lastFire >= durationMinutes {
+				continue
+			}
+
+			weekMinute := minute % weekMinutes
+			if other := activeSchedules[weekMinute] - 1; other != -1 && other != i {
+				return ErrorOverlappingSchedules(other, i)
+			}
+			activeSchedules[weekMinute] = i + 1
+		}
+	}
+
+	return nil
+}
+
+// ActiveReplicaSchedule returns the schedule which is in effect at t, or nil if there is none
+func (ac *APICompute) ActiveReplicaSchedule(t time.Time) *ReplicaSchedule {
+	for _, schedule := range ac.Schedules {
+		parsedCron, err := cron.Parse(schedule.Cron)
+		if err != nil {
+			continue
+		}
+		if _, ok := parsedCron.MostRecent(t.UTC(), schedule.Duration); ok {
+			return schedule
+		}
+	}
+	return nil
+}
+
+// ReplicaBounds returns the min and max replicas which are in effect at t
+func (ac *APICompute) ReplicaBounds(t time.Time) (int32, int32) {
+	if schedule := ac.ActiveReplicaSchedule(t); schedule != nil {
+		return schedule.MinReplicas, schedule.MaxReplicas
+	}
+	return ac.MinReplicas, ac.MaxReplicas
+}
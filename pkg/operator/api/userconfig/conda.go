@@ -0,0 +1,158 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/cast"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pip"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+const (
+	CondaPackagesFileName    = "conda-packages.txt"
+	CondaEnvironmentFileName = "environment.yml"
+)
+
+// Conda match specs, e.g. numpy, numpy=1.18, numpy>=1.17,<1.19, cudnn=7.6.5=cuda10.1_0, conda-forge::opencv, or numpy 1.18.*
+var condaSpecRegex = regexp.MustCompile(`^([A-Za-z0-9_.-]+::)?[A-Za-z0-9_][A-Za-z0-9_.-]*((\s*(==|=|>=|<=|>|<|!=|~=)|\s+)[A-Za-z0-9_.*+,|<>=!-]+(\s+[A-Za-z0-9_.*+-]+)?)?$`)
+
+var condaEnvironmentKeys = strset.New("name", "channels", "dependencies", "prefix", "variables")
+
+// CondaFileNames returns the names of the conda files which are present in the project
+func CondaFileNames(projectFileMap map[string][]byte) []string {
+	var fileNames []string
+	for _, fileName := range []string{CondaPackagesFileName, CondaEnvironmentFileName} {
+		if _, ok := projectFileMap[fileName]; ok {
+			fileNames = append(fileNames, fileName)
+		}
+	}
+	return fileNames
+}
+
+// ValidateConda checks the syntax of the project's conda-packages.txt and environment.yml (if present)
+func ValidateConda(projectFileMap map[string][]byte, apis APIs) error {
+	condaFileNames := CondaFileNames(projectFileMap)
+	if len(condaFileNames) == 0 {
+		return nil
+	}
+
+	for _, api := range apis {
+		if api.Predictor.Type != PythonPredictorType && api.Pipeline == nil {
+			return errors.Wrap(ErrorCondaNotSupportedByPredictorType(condaFileNames[0], api.Predictor.Type), Identify(api))
+		}
+		if api.Predictor.PythonVersion != nil {
+			return errors.Wrap(ErrorPythonVersionWithConda(condaFileNames[0]), Identify(api), PredictorKey, PythonVersionKey)
+		}
+		if api.Compute.Arch == ARM64Arch {
+			return errors.Wrap(ErrorARM64NotSupported(condaFileNames[0]), Identify(api), ComputeKey, ArchKey)
+		}
+	}
+
+	if condaPackagesBytes, ok := projectFileMap[CondaPackagesFileName]; ok {
+		if err := validateCondaPackages(string(condaPackagesBytes)); err != nil {
+			return errors.Wrap(err, CondaPackagesFileName)
+		}
+	}
+
+	if condaEnvironmentBytes, ok := projectFileMap[CondaEnvironmentFileName]; ok {
+		if err := validateCondaEnvironment(condaEnvironmentBytes); err != nil {
+			return errors.Wrap(err, CondaEnvironmentFileName)
+		}
+	}
+
+	return nil
+}
+
+func validateCondaPackages(contents string) error {
+	for i, line := range strings.Split(contents, "\n") {
+		if commentIndex := strings.Index(line, "#"); commentIndex >= 0 {
+			line = line[:commentIndex]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "@") { // "@EXPLICIT" and similar directives
+			continue
+		}
+		if !condaSpecRegex.MatchString(line) {
+			return errors.Wrap(ErrorInvalidCondaPackage(line), "line "+strconv.Itoa(i+1))
+		}
+	}
+	return nil
+}
+
+func validateCondaEnvironment(contents []byte) error {
+	envInterface, err := cr.ReadYAMLBytes(contents)
+	if err != nil {
+		return err
+	}
+
+	env, ok := cast.InterfaceToStrInterfaceMap(envInterface)
+	if !ok {
+		return ErrorMalformedCondaEnvironment()
+	}
+
+	for key := range env {
+		if !condaEnvironmentKeys.Has(key) {
+			return cr.ErrorUnsupportedKey(key)
+		}
+	}
+
+	if channelsInterface, ok := env["channels"]; ok && channelsInterface != nil {
+		if _, ok := cast.InterfaceToStrSlice(channelsInterface); !ok {
+			return errors.Wrap(cr.ErrorInvalidPrimitiveType(channelsInterface, cr.PrimTypeStringList), "channels")
+		}
+	}
+
+	dependenciesInterface, ok := env["dependencies"]
+	if !ok || dependenciesInterface == nil {
+		return nil
+	}
+	dependencies, ok := cast.InterfaceToInterfaceSlice(dependenciesInterface)
+	if !ok {
+		return errors.Wrap(cr.ErrorInvalidPrimitiveType(dependenciesInterface, cr.PrimTypeList), "dependencies")
+	}
+
+	for i, dependency := range dependencies {
+		if dependencyStr, ok := dependency.(string); ok {
+			if !condaSpecRegex.MatchString(strings.TrimSpace(dependencyStr)) {
+				return errors.Wrap(ErrorInvalidCondaPackage(dependencyStr), "dependencies", s.Index(i))
+			}
+			continue
+		}
+
+		// e.g. {pip: [requests, flask==1.1.1]}
+		dependencyMap, ok := cast.InterfaceToStrInterfaceMap(dependency)
+		if !ok || len(dependencyMap) != 1 {
+			return errors.Wrap(ErrorInvalidCondaPackage(s.ObjFlatNoQuotes(dependency)), "dependencies", s.Index(i))
+		}
+		pipPackages, ok := cast.InterfaceToStrSlice(dependencyMap["pip"])
+		if !ok {
+			return errors.Wrap(ErrorInvalidCondaPackage(s.ObjFlatNoQuotes(dependency)), "dependencies", s.Index(i))
+		}
+		if _, err := pip.ParseRequirements(strings.Join(pipPackages, "\n")); err != nil {
+			return errors.Wrap(err, "dependencies", s.Index(i), "pip")
+		}
+	}
+
+	return nil
+}
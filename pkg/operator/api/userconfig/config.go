@@ -17,11 +17,15 @@ limitations under the License.
 package userconfig
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/cortexlabs/cortex/pkg/lib/cast"
 	"github.com/cortexlabs/cortex/pkg/lib/configreader"
 	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	"github.com/cortexlabs/cortex/pkg/lib/zip"
 	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
 )
@@ -29,6 +33,9 @@ import (
 type Config struct {
 	App  *App `json:"app" yaml:"app"`
 	APIs APIs `json:"apis" yaml:"apis"`
+
+	// Issues which don't prevent the config from being deployed (e.g. ignored or deprecated keys, suspicious values)
+	Warnings []error `json:"-" yaml:"-"`
 }
 
 var typeFieldValidation = &cr.StructFieldValidation{
@@ -36,11 +43,12 @@ var typeFieldValidation = &cr.StructFieldValidation{
 	Nil: true,
 }
 
-func (config *Config) Validate(projectBytes []byte) error {
-	var err error
-	projectFileMap := make(map[string][]byte)
+func (config *Config) Validate(projectBytes []byte, projectLimits *ProjectLimits) error {
+	if err := ValidateProjectLimits(projectBytes, projectLimits); err != nil {
+		return err
+	}
 
-	projectFileMap, err = zip.UnzipMemToMem(projectBytes)
+	projectFileMap, err := zip.UnzipMemToMem(projectBytes)
 	if err != nil {
 		return err
 	}
@@ -49,26 +57,89 @@ func (config *Config) Validate(projectBytes []byte) error {
 		if err := config.APIs.Validate(config.App.Name, projectFileMap); err != nil {
 			return err
 		}
+		if err := ValidateRequirements(projectFileMap, config.APIs); err != nil {
+			return err
+		}
+		if err := ValidateConda(projectFileMap, config.APIs); err != nil {
+			return err
+		}
+		if err := ValidateExplainerRequirements(projectFileMap, config.APIs); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func New(filePath string, configBytes []byte) (*Config, error) {
-	var err error
+// ConfigDirName is the directory (alongside cortex.yaml) which may contain additional configuration files
+const ConfigDirName = "cortex.d"
 
-	configData, err := cr.ReadYAMLBytes(configBytes)
-	if err != nil {
-		return nil, errors.Wrap(err, filePath)
+type configResource struct {
+	filePath string
+	index    int
+	data     map[string]interface{}
+}
+
+func New(filePath string, configBytes []byte, variables map[string]string) (*Config, error) {
+	return NewFromFiles(map[string][]byte{filePath: configBytes}, variables)
+}
+
+// NewFromFiles merges the resources which are defined across multiple configuration files (keyed by file path),
+// after substituting variables (e.g. ${ENV_VAR}) in their values
+func NewFromFiles(configFiles map[string][]byte, variables map[string]string) (*Config, error) {
+	var resources []configResource
+	var defaults *configResource
+
+	filePaths := make([]string, 0, len(configFiles))
+	for filePath := range configFiles {
+		filePaths = append(filePaths, filePath)
 	}
+	// Read top level files (i.e. cortex.yaml) before files in subdirectories, so that duplicates are attributed to the latter
+	sort.Slice(filePaths, func(i, j int) bool {
+		iDepth, jDepth := strings.Count(filePaths[i], "/"), strings.Count(filePaths[j], "/")
+		if iDepth != jDepth {
+			return iDepth < jDepth
+		}
+		return filePaths[i] < filePaths[j]
+	})
 
-	configDataSlice, ok := cast.InterfaceToStrInterfaceMapSlice(configData)
-	if !ok {
-		return nil, errors.Wrap(ErrorMalformedConfig(), filePath)
+	for _, filePath := range filePaths {
+		configData, err := cr.ReadYAMLBytes(configFiles[filePath])
+		if err != nil {
+			return nil, errors.Wrap(err, filePath)
+		}
+
+		unresolved := strset.New()
+		configData = resolveVariables(configData, variables, unresolved)
+		if err := unresolvedVariablesErr(unresolved); err != nil {
+			return nil, errors.Wrap(err, filePath)
+		}
+
+		configDataSlice, ok := cast.InterfaceToStrInterfaceMapSlice(configData)
+		if !ok {
+			return nil, errors.Wrap(ErrorMalformedConfig(), filePath)
+		}
+
+		for i, data := range configDataSlice {
+			if kindStr, _ := data[KindKey].(string); resource.TypeFromKindString(kindStr) == resource.DefaultsType {
+				if defaults != nil {
+					return nil, errors.Wrap(ErrorDuplicateConfig(resource.DefaultsType), identify(filePath, resource.DefaultsType, "", i))
+				}
+				delete(data, KindKey)
+				if err := validateDefaults(data); err != nil {
+					return nil, errors.Wrap(err, identify(filePath, resource.DefaultsType, "", i))
+				}
+				defaults = &configResource{filePath: filePath, index: i, data: data}
+				continue
+			}
+			resources = append(resources, configResource{filePath: filePath, index: i, data: data})
+		}
 	}
 
 	config := &Config{}
-	for i, data := range configDataSlice {
+	for _, res := range resources {
+		filePath, i, data := res.filePath, res.index, res.data
+
 		kindInterface, ok := data[KindKey]
 		if !ok {
 			return nil, errors.Wrap(configreader.ErrorMustBeDefined(), identify(filePath, resource.UnknownType, "", i), KindKey)
@@ -79,21 +150,39 @@ func New(filePath string, configBytes []byte) (*Config, error) {
 		}
 
 		var errs []error
+		var warnings []error
 		resourceType := resource.TypeFromKindString(kindStr)
 		var newResource Resource
 		switch resourceType {
 		case resource.AppType:
 			if config.App != nil {
-				return nil, errors.Wrap(ErrorDuplicateConfig(resource.AppType), filePath)
+				name, _ := data[NameKey].(string)
+				return nil, errors.Wrap(ErrorDuplicateConfig(resource.AppType), identify(filePath, resource.AppType, name, i))
 			}
 			app := &App{}
-			errs = cr.Struct(app, data, appValidation)
+			errs, warnings = cr.StructWithWarnings(app, data, appValidation)
 			config.App = app
 		case resource.APIType:
+			if defaults != nil {
+				data = applyDefaults(data, defaults.data)
+			}
 			newResource = &API{}
-			errs = cr.Struct(newResource, data, apiValidation)
+			errs, warnings = cr.StructWithWarnings(newResource, data, apiValidation)
+			if !errors.HasErrors(errs) {
+				api := newResource.(*API)
+				config.APIs = append(config.APIs, api)
+				warnings = append(warnings, errors.WrapAll(api.Compute.Warnings(), ComputeKey)...)
+			}
+		case resource.PipelineType:
+			if defaults != nil {
+				data = applyDefaults(data, pipelineDefaults(defaults.data))
+			}
+			var api *API
+			api, errs, warnings = newPipelineAPI(data)
 			if !errors.HasErrors(errs) {
-				config.APIs = append(config.APIs, newResource.(*API))
+				newResource = api
+				config.APIs = append(config.APIs, api)
+				warnings = append(warnings, errors.WrapAll(api.Compute.Warnings(), ComputeKey)...)
 			}
 		default:
 			return nil, errors.Wrap(resource.ErrorUnknownKind(kindStr), identify(filePath, resource.UnknownType, "", i))
@@ -104,6 +193,11 @@ func New(filePath string, configBytes []byte) (*Config, error) {
 			return nil, errors.Wrap(errors.FirstError(errs...), identify(filePath, resourceType, name, i))
 		}
 
+		if len(warnings) > 0 {
+			name, _ := data[NameKey].(string)
+			config.Warnings = append(config.Warnings, errors.WrapAll(warnings, identify(filePath, resourceType, name, i))...)
+		}
+
 		if newResource != nil {
 			newResource.SetIndex(i)
 			newResource.SetFilePath(filePath)
@@ -117,13 +211,13 @@ func New(filePath string, configBytes []byte) (*Config, error) {
 	return config, nil
 }
 
-func ReadConfigFile(filePath string, relativePath string) (*Config, error) {
+func ReadConfigFile(filePath string, relativePath string, variables map[string]string) (*Config, error) {
 	configBytes, err := files.ReadFileBytesErrPath(filePath, relativePath)
 	if err != nil {
 		return nil, err
 	}
 
-	config, err := New(relativePath, configBytes)
+	config, err := New(relativePath, configBytes, variables)
 	if err != nil {
 		return nil, err
 	}
@@ -22,19 +22,35 @@ const (
 	NameKey    = "name"
 	KindKey    = "kind"
 
+	// App
+	ProjectKey = "project"
+	IncludeKey = "include"
+	ExcludeKey = "exclude"
+
 	// API
-	ModelKey        = "model"
-	TypeKey         = "type"
-	PathKey         = "path"
-	PredictorKey    = "predictor"
-	EndpointKey     = "endpoint"
-	SignatureKeyKey = "signature_key"
-	TrackerKey      = "tracker"
-	ModelTypeKey    = "model_type"
-	KeyKey          = "key"
-	ConfigKey       = "config"
-	PythonPathKey   = "python_path"
-	EnvKey          = "env"
+	ModelKey                    = "model"
+	TypeKey                     = "type"
+	PathKey                     = "path"
+	PredictorKey                = "predictor"
+	EndpointKey                 = "endpoint"
+	SignatureKeyKey             = "signature_key"
+	TensorFlowServingVersionKey = "tensorflow_serving_version"
+	ONNXRuntimeVersionKey       = "onnx_runtime_version"
+	PythonVersionKey            = "python_version"
+	TrackerKey                  = "tracker"
+	ModelTypeKey                = "model_type"
+	KeyKey                      = "key"
+	ConfigKey                   = "config"
+	PythonPathKey               = "python_path"
+	EnvKey                      = "env"
+	ConfigReloadKey             = "config_reload"
+	BatchingKey                 = "batching"
+	MaxBatchSizeKey             = "max_batch_size"
+	BatchIntervalKey            = "batch_interval"
+	ProcessesPerReplicaKey      = "processes_per_replica"
+	ThreadsPerProcessKey        = "threads_per_process"
+	ExplainerKey                = "explainer"
+	BackgroundKey               = "background"
 
 	// Compute
 	ComputeKey              = "compute"
@@ -42,7 +58,154 @@ const (
 	MaxReplicasKey          = "max_replicas"
 	InitReplicasKey         = "init_replicas"
 	TargetCPUUtilizationKey = "target_cpu_utilization"
+	TargetGPUUtilizationKey = "target_gpu_utilization"
 	CPUKey                  = "cpu"
 	GPUKey                  = "gpu"
 	MemKey                  = "mem"
+	SchedulesKey            = "schedules"
+	CronKey                 = "cron"
+	DurationKey             = "duration"
+	IdleKey                 = "idle"
+	ActionKey               = "action"
+	PriorityKey             = "priority"
+	ArchKey                 = "arch"
+
+	// Containers
+	InitKey     = "init"
+	SidecarsKey = "sidecars"
+	ImageKey    = "image"
+	CommandKey  = "command"
+	ArgsKey     = "args"
+
+	// Volumes
+	VolumesKey      = "volumes"
+	FileSystemIDKey = "file_system_id"
+	MountPathKey    = "mount_path"
+	SubPathKey      = "sub_path"
+	MountNameKey    = "mount_name"
+	ReadOnlyKey     = "read_only"
+
+	// Files
+	FilesKey = "files"
+
+	DependsOnKey = "depends_on"
+
+	ClustersKey = "clusters"
+
+	// Networking
+	NetworkingKey        = "networking"
+	CORSKey              = "cors"
+	AllowedOriginsKey    = "allowed_origins"
+	AllowedMethodsKey    = "allowed_methods"
+	AllowedHeadersKey    = "allowed_headers"
+	MaxAgeKey            = "max_age"
+	CompressionKey       = "compression"
+	GzipKey              = "gzip"
+	BrotliKey            = "brotli"
+	MinSizeKey           = "min_size"
+	MaxRequestSizeKey    = "max_request_size"
+	StreamRequestsKey    = "stream_requests"
+	ProtocolKey          = "protocol"
+	IdleTimeoutKey       = "idle_timeout"
+	RateLimitKey         = "rate_limit"
+	RequestsPerSecondKey = "requests_per_second"
+	BurstKey             = "burst"
+	ClientHeaderKey      = "client_header"
+	VisibilityKey        = "visibility"
+	IPAllowlistKey       = "ip_allowlist"
+	ExposeKey            = "expose"
+	AuthKey              = "auth"
+	JWTKey               = "jwt"
+	IssuerKey            = "issuer"
+	JWKSURIKey           = "jwks_uri"
+	AudiencesKey         = "audiences"
+	RequiredClaimsKey    = "required_claims"
+	ForwardHeadersKey    = "forward_headers"
+	RequiredKey          = "required"
+	PatternKey           = "pattern"
+
+	// Circuit breaker
+	CircuitBreakerKey       = "circuit_breaker"
+	MaxConnectionsKey       = "max_connections"
+	MaxPendingRequestsKey   = "max_pending_requests"
+	MaxRequestsKey          = "max_requests"
+	Consecutive5xxErrorsKey = "consecutive_5xx_errors"
+	IntervalKey             = "interval"
+	BaseEjectionTimeKey     = "base_ejection_time"
+	MaxEjectionPercentKey   = "max_ejection_percent"
+
+	// Timeouts and retries
+	TimeoutKey       = "timeout"
+	RetriesKey       = "retries"
+	AttemptsKey      = "attempts"
+	PerTryTimeoutKey = "per_try_timeout"
+	StatusCodesKey   = "status_codes"
+
+	// Load shedding
+	LoadSheddingKey   = "load_shedding"
+	MaxQueueDepthKey  = "max_queue_depth"
+	StatusCodeKey     = "status_code"
+	PriorityHeaderKey = "priority_header"
+
+	// Pipeline
+	StepsKey  = "steps"
+	APIKey    = "api"
+	InputsKey = "inputs"
+	OutputKey = "output"
+
+	// Update strategy
+	UpdateStrategyKey  = "update_strategy"
+	ModeKey            = "mode"
+	PreviewEndpointKey = "preview_endpoint"
+	CanaryKey          = "canary"
+	StepWeightKey      = "step_weight"
+	StepIntervalKey    = "step_interval"
+	MaxErrorRateKey    = "max_error_rate"
+	MaxLatencyKey      = "max_latency"
+	MinRequestsKey     = "min_requests"
+
+	RollbackOnCrashLoopKey = "rollback_on_crash_loop"
+	ProgressDeadlineKey    = "progress_deadline"
+
+	// Experiment
+	ExperimentKey = "experiment"
+	HeaderKey     = "header"
+	CookieKey     = "cookie"
+	VariantsKey   = "variants"
+	WeightKey     = "weight"
+
+	// Webhooks
+	WebhooksKey = "webhooks"
+
+	// Prediction logging
+	PredictionLoggingKey = "prediction_logging"
+	SampleRateKey        = "sample_rate"
+	DestinationKey       = "destination"
+	RedactKey            = "redact"
+
+	// Data drift
+	DriftKey          = "drift"
+	ThresholdKey      = "threshold"
+	WindowKey         = "window"
+	BaselineWindowKey = "baseline_window"
+	MinSamplesKey     = "min_samples"
+	FeaturesKey       = "features"
+
+	// Cache
+	CacheKey      = "cache"
+	TTLKey        = "ttl"
+	MaxEntriesKey = "max_entries"
+
+	// Feedback
+	FeedbackKey = "feedback"
+	MetricsKey  = "metrics"
+
+	// Alerts
+	AlertsKey = "alerts"
+	MetricKey = "metric"
+
+	// Logs
+	LogsKey          = "logs"
+	RetentionDaysKey = "retention_days"
+	FormatKey        = "format"
 )
@@ -0,0 +1,262 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/yaml"
+)
+
+// Container names which are used by cortex in every API pod
+var ReservedContainerNames = []string{"api", "serve", "downloader", "istio-proxy", "istio-init", "cache"}
+
+// Image names of service mesh proxies, which intercept the pod's traffic (and conflict with the istio proxy when the cluster requires mutual TLS)
+var _meshProxyImageNames = []string{"envoy", "istio", "linkerd", "consul", "kuma"}
+
+type Containers []*Container
+
+type Container struct {
+	Name    string            `json:"name" yaml:"name"`
+	Image   string            `json:"image" yaml:"image"`
+	Command []string          `json:"command" yaml:"command"`
+	Args    []string          `json:"args" yaml:"args"`
+	Env     map[string]string `json:"env" yaml:"env"`
+	Compute *ContainerCompute `json:"compute" yaml:"compute"`
+}
+
+type ContainerCompute struct {
+	CPU *k8s.Quantity `json:"cpu" yaml:"cpu"`
+	Mem *k8s.Quantity `json:"mem" yaml:"mem"`
+}
+
+var containerValidation = containerStructValidation(nil)
+
+// Sidecars request CPU by default, since the API's horizontal pod autoscaler can only compute the pod's CPU utilization if all of its containers request CPU
+var sidecarValidation = containerStructValidation(pointer.String("100m"))
+
+func containerStructValidation(defaultCPU *string) *cr.StructValidation {
+	return &cr.StructValidation{
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "Name",
+				StringValidation: &cr.StringValidation{
+					Required: true,
+					DNS1123:  true,
+				},
+			},
+			{
+				StructField: "Image",
+				StringValidation: &cr.StringValidation{
+					Required: true,
+				},
+			},
+			{
+				StructField: "Command",
+				StringListValidation: &cr.StringListValidation{
+					AllowEmpty: true,
+				},
+			},
+			{
+				StructField: "Args",
+				StringListValidation: &cr.StringListValidation{
+					AllowEmpty: true,
+				},
+			},
+			{
+				StructField: "Env",
+				StringMapValidation: &cr.StringMapValidation{
+					Default:    map[string]string{},
+					AllowEmpty: true,
+				},
+			},
+			{
+				StructField: "Compute",
+				StructValidation: &cr.StructValidation{
+					StructFieldValidations: []*cr.StructFieldValidation{
+						{
+							StructField: "CPU",
+							StringPtrValidation: &cr.StringPtrValidation{
+								Default:     defaultCPU,
+								CastNumeric: true,
+							},
+							Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+								GreaterThan: k8s.QuantityPtr(kresource.MustParse("0")),
+							}),
+						},
+						{
+							StructField: "Mem",
+							StringPtrValidation: &cr.StringPtrValidation{
+								Default: nil,
+							},
+							Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+								GreaterThan: k8s.QuantityPtr(kresource.MustParse("0")),
+							}),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var initFieldValidation = &cr.StructFieldValidation{
+	StructField: "Init",
+	StructListValidation: &cr.StructListValidation{
+		StructValidation:  containerValidation,
+		AllowExplicitNull: true,
+	},
+}
+
+var sidecarsFieldValidation = &cr.StructFieldValidation{
+	StructField: "Sidecars",
+	StructListValidation: &cr.StructListValidation{
+		StructValidation:  sidecarValidation,
+		AllowExplicitNull: true,
+	},
+}
+
+func (container *Container) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", NameKey, container.Name))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ImageKey, container.Image))
+	if len(container.Command) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", CommandKey, s.ObjFlatNoQuotes(container.Command)))
+	}
+	if len(container.Args) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ArgsKey, s.ObjFlatNoQuotes(container.Args)))
+	}
+	if len(container.Env) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", EnvKey))
+		d, _ := yaml.Marshal(&container.Env)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
+	if container.Compute != nil && (container.Compute.CPU != nil || container.Compute.Mem != nil) {
+		sb.WriteString(fmt.Sprintf("%s:\n", ComputeKey))
+		sb.WriteString(s.Indent(container.Compute.UserConfigStr(), "  "))
+	}
+	return sb.String()
+}
+
+func (cc *ContainerCompute) UserConfigStr() string {
+	var sb strings.Builder
+	if cc.CPU != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", CPUKey, cc.CPU.UserString))
+	}
+	if cc.Mem != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MemKey, cc.Mem.UserString))
+	}
+	return sb.String()
+}
+
+func (containers Containers) UserConfigStr() string {
+	var sb strings.Builder
+	for _, container := range containers {
+		containerStr := s.Indent(container.UserConfigStr(), "  ")
+		sb.WriteString("- " + strings.TrimPrefix(containerStr, "  "))
+	}
+	return sb.String()
+}
+
+func (containers Containers) Validate() error {
+	names := strset.New()
+	for i, container := range containers {
+		if err := validateContainerName(container.Name, names); err != nil {
+			return errors.Wrap(err, s.Index(i), NameKey)
+		}
+		names.Add(container.Name)
+	}
+	return nil
+}
+
+// MTLSWarnings returns warnings for the containers which are likely to conflict with the istio proxy
+// which handles mutual TLS (it's only injected into the API's pods when the cluster's mtls is enabled)
+func (containers Containers) MTLSWarnings() []error {
+	var warnings []error
+	for i, container := range containers {
+		if isMeshProxyImage(container.Image) {
+			warnings = append(warnings, errors.Wrap(ErrorSidecarMayBreakMTLS(container.Image), s.Index(i), ImageKey))
+		}
+	}
+	return warnings
+}
+
+// isMeshProxyImage returns whether the image's name (without its registry, organization, or tag) contains the name of a service mesh proxy
+func isMeshProxyImage(image string) bool {
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.IndexAny(name, ":@"); i != -1 {
+		name = name[:i]
+	}
+	for _, proxyName := range _meshProxyImageNames {
+		if strings.Contains(strings.ToLower(name), proxyName) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateContainerName(name string, existingNames strset.Set) error {
+	if slices.HasString(ReservedContainerNames, name) {
+		return ErrorReservedContainerName(name)
+	}
+	if existingNames.Has(name) {
+		return ErrorDuplicateContainerName(name)
+	}
+	return nil
+}
+
+// TotalCompute returns the sum of the containers' compute requests (nil if none of the containers request the resource)
+func (containers Containers) TotalCompute() (*k8s.Quantity, *k8s.Quantity) {
+	var totalCPU *k8s.Quantity
+	var totalMem *k8s.Quantity
+	for _, container := range containers {
+		if container.Compute == nil {
+			continue
+		}
+		if container.Compute.CPU != nil {
+			if totalCPU == nil {
+				totalCPU = &k8s.Quantity{}
+			}
+			totalCPU.Add(container.Compute.CPU.Quantity)
+		}
+		if container.Compute.Mem != nil {
+			if totalMem == nil {
+				totalMem = &k8s.Quantity{}
+			}
+			totalMem.Add(container.Compute.Mem.Quantity)
+		}
+	}
+	return totalCPU, totalMem
+}
+
+func (containers Containers) Names() []string {
+	names := make([]string, len(containers))
+	for i, container := range containers {
+		names[i] = container.Name
+	}
+	return names
+}
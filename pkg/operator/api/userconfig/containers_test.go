@@ -0,0 +1,81 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+func TestContainerComputeDefaults(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		validation  *cr.StructValidation
+		yamlStr     string
+		expectedCPU string // empty if the container shouldn't request CPU
+		expectedMem string // empty if the container shouldn't request memory
+	}{
+		{
+			name:        "sidecar without compute",
+			validation:  sidecarValidation,
+			yamlStr:     "name: exporter\nimage: exporter:latest",
+			expectedCPU: "100m",
+		},
+		{
+			name:        "sidecar with mem",
+			validation:  sidecarValidation,
+			yamlStr:     "name: exporter\nimage: exporter:latest\ncompute:\n  mem: 64Mi",
+			expectedCPU: "100m",
+			expectedMem: "64Mi",
+		},
+		{
+			name:        "sidecar with cpu",
+			validation:  sidecarValidation,
+			yamlStr:     "name: exporter\nimage: exporter:latest\ncompute:\n  cpu: 1",
+			expectedCPU: "1",
+		},
+		{
+			name:       "init container without compute",
+			validation: containerValidation,
+			yamlStr:    "name: migrate\nimage: migrate:latest",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			container := &Container{}
+			errs := cr.Struct(container, cr.MustReadYAMLStr(tc.yamlStr), tc.validation)
+			require.False(t, errors.HasErrors(errs), errors.FirstError(errs...))
+			require.NotNil(t, container.Compute)
+
+			if tc.expectedCPU == "" {
+				require.Nil(t, container.Compute.CPU)
+			} else {
+				require.NotNil(t, container.Compute.CPU)
+				require.Equal(t, tc.expectedCPU, container.Compute.CPU.String())
+			}
+			if tc.expectedMem == "" {
+				require.Nil(t, container.Compute.Mem)
+			} else {
+				require.NotNil(t, container.Compute.Mem)
+				require.Equal(t, tc.expectedMem, container.Compute.Mem.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// DataDrift compares the distributions of the tracked predictions (and features of the requests) with a baseline which is captured after the API is deployed
+type DataDrift struct {
+	Threshold      float64       `json:"threshold" yaml:"threshold"`             // the population stability index above which a distribution has drifted
+	Window         time.Duration `json:"window" yaml:"window"`                   // the rolling window which is compared with the baseline
+	BaselineWindow time.Duration `json:"baseline_window" yaml:"baseline_window"` // the baseline is the values which are received during this period after the API is deployed
+	MinSamples     int64         `json:"min_samples" yaml:"min_samples"`         // the minimum number of predictions in the baseline and in the window
+	Features       []string      `json:"features" yaml:"features"`               // dot-separated paths of fields in the request payloads
+}
+
+var dataDriftFieldValidation = &cr.StructFieldValidation{
+	StructField: "DataDrift",
+	StructValidation: &cr.StructValidation{
+		DefaultNil: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "Threshold",
+				Float64Validation: &cr.Float64Validation{
+					Default:     0.2,
+					GreaterThan: pointer.Float64(0),
+				},
+			},
+			{
+				StructField: "Window",
+				DurationValidation: &cr.DurationValidation{
+					Default:              time.Hour,
+					GreaterThanOrEqualTo: pointer.Duration(5 * time.Minute),
+					LessThanOrEqualTo:    pointer.Duration(_week),
+				},
+			},
+			{
+				StructField: "BaselineWindow",
+				DurationValidation: &cr.DurationValidation{
+					Default:              time.Hour,
+					GreaterThanOrEqualTo: pointer.Duration(5 * time.Minute),
+					LessThanOrEqualTo:    pointer.Duration(_week),
+				},
+			},
+			{
+				StructField: "MinSamples",
+				Int64Validation: &cr.Int64Validation{
+					Default:     100,
+					GreaterThan: pointer.Int64(0),
+				},
+			},
+			{
+				StructField: "Features",
+				StringListValidation: &cr.StringListValidation{
+					AllowEmpty: true,
+					Validator:  validateFieldPaths,
+				},
+			},
+		},
+	},
+}
+
+func (dataDrift *DataDrift) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ThresholdKey, s.Float64(dataDrift.Threshold)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", WindowKey, dataDrift.Window.String()))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", BaselineWindowKey, dataDrift.BaselineWindow.String()))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MinSamplesKey, s.Int64(dataDrift.MinSamples)))
+	if len(dataDrift.Features) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", FeaturesKey, s.ObjFlatNoQuotes(dataDrift.Features)))
+	}
+	return sb.String()
+}
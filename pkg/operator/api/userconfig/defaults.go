@@ -0,0 +1,97 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/cast"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+)
+
+// Keys which may be defined in a defaults resource (all API fields, except for those which identify the API)
+func defaultableAPIKeys() strset.Set {
+	keys := apiValidationKeys(apiValidation)
+	keys.Remove(KindKey, NameKey)
+	return keys
+}
+
+// pipelineDefaults returns the defaults which apply to pipelines (e.g. a default predictor doesn't)
+func pipelineDefaults(defaults map[string]interface{}) map[string]interface{} {
+	keys := apiValidationKeys(pipelineAPIValidation)
+	filtered := make(map[string]interface{}, len(defaults))
+	for key, val := range defaults {
+		if keys.Has(key) {
+			filtered[key] = val
+		}
+	}
+	return filtered
+}
+
+func apiValidationKeys(validation *cr.StructValidation) strset.Set {
+	keys := strset.New()
+	apiType := reflect.TypeOf(API{})
+	for _, fieldValidation := range validation.StructFieldValidations {
+		key := fieldValidation.Key
+		if key == "" {
+			field, _ := apiType.FieldByName(fieldValidation.StructField)
+			key = strings.Split(field.Tag.Get("json"), ",")[0]
+		}
+		keys.Add(key)
+	}
+	return keys
+}
+
+func validateDefaults(defaults map[string]interface{}) error {
+	allowedKeys := defaultableAPIKeys()
+	for key := range defaults {
+		if key == KindKey {
+			continue
+		}
+		if !allowedKeys.Has(key) {
+			return cr.ErrorUnsupportedKey(key)
+		}
+	}
+	return nil
+}
+
+// applyDefaults merges the defaults into an API's configuration; values which are defined in the API take precedence (maps are merged recursively)
+func applyDefaults(data map[string]interface{}, defaults map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(data)+len(defaults))
+	for key, defaultVal := range defaults {
+		merged[key] = defaultVal
+	}
+
+	for key, val := range data {
+		defaultVal, ok := merged[key]
+		if !ok {
+			merged[key] = val
+			continue
+		}
+		valMap, isMap := cast.InterfaceToStrInterfaceMap(val)
+		defaultValMap, isDefaultMap := cast.InterfaceToStrInterfaceMap(defaultVal)
+		if isMap && isDefaultMap && valMap != nil && defaultValMap != nil {
+			merged[key] = applyDefaults(valMap, defaultValMap)
+		} else {
+			merged[key] = val
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
+)
+
+var dependsOnFieldValidation = &cr.StructFieldValidation{
+	StructField: "DependsOn",
+	StringListValidation: &cr.StringListValidation{
+		AllowEmpty:   true,
+		DisallowDups: true,
+	},
+}
+
+// validateDependencies checks that the APIs which each API depends on are defined in the deployment, and that the dependencies don't form a cycle
+func (apis APIs) validateDependencies() error {
+	apiNames := strset.New()
+	for _, api := range apis {
+		apiNames.Add(api.Name)
+	}
+
+	for _, api := range apis {
+		for i, apiName := range api.DependsOn {
+			if !apiNames.Has(apiName) {
+				return errors.Wrap(ErrorUndefinedResource(apiName, resource.APIType), Identify(api), DependsOnKey, s.Index(i))
+			}
+		}
+	}
+
+	_, err := apis.DependencyStages()
+	return err
+}
+
+// DependencyStages groups the APIs in the order in which they can be rolled out: each stage's APIs only depend on the APIs in earlier stages
+func (apis APIs) DependencyStages() ([][]*API, error) {
+	done := strset.New()
+	remaining := apis
+
+	var stages [][]*API
+	for len(remaining) > 0 {
+		var stage []*API
+		var blocked []*API
+		for _, api := range remaining {
+			if done.Has(api.DependsOn...) {
+				stage = append(stage, api)
+			} else {
+				blocked = append(blocked, api)
+			}
+		}
+
+		if len(stage) == 0 {
+			blockedNames := make([]string, len(blocked))
+			for i, api := range blocked {
+				blockedNames[i] = api.Name
+			}
+			return nil, ErrorDependencyCycle(blockedNames)
+		}
+
+		for _, api := range stage {
+			done.Add(api.Name)
+		}
+		stages = append(stages, stage)
+		remaining = blocked
+	}
+
+	return stages, nil
+}
@@ -18,8 +18,12 @@ package userconfig
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/mlflow"
+	"github.com/cortexlabs/cortex/pkg/lib/sagemaker"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
@@ -47,9 +51,107 @@ const (
 	ErrExternalNotFound
 	ErrONNXDoesntSupportZip
 	ErrInvalidTensorFlowDir
-	ErrFieldMustBeDefinedForPredictorType
-	ErrFieldNotSupportedByPredictorType
 	ErrDuplicateEndpoints
+	ErrReservedContainerName
+	ErrDuplicateContainerName
+	ErrInvalidFileSystemID
+	ErrMountPathMustBeAbsolute
+	ErrReservedMountPath
+	ErrDuplicateMountPath
+	ErrInvalidSecretReference
+	ErrProjectTooManyFiles
+	ErrProjectFilesTooLarge
+	ErrProjectTooLarge
+	ErrRequirementConflictsWithRuntime
+	ErrCondaNotSupportedByPredictorType
+	ErrInvalidCondaPackage
+	ErrMalformedCondaEnvironment
+	ErrPredictorClassNotDefined
+	ErrPredictorFunctionNotDefined
+	ErrInvalidPredictorFunctionSignature
+	ErrUnresolvedVariables
+	ErrMemWithoutUnit
+	ErrCPUWithoutMillicores
+	ErrTargetCPUUtilizationAboveRequest
+	ErrReservedEndpoint
+	ErrInvalidCORSOrigin
+	ErrStreamRequestsNotSupportedByPredictorType
+	ErrMaxRequestSizeRequiresStreaming
+	ErrWebSocketNotSupportedByPredictorType
+	ErrWebSocketAutoscaling
+	ErrInvalidHeaderName
+	ErrInvalidIPBlock
+	ErrPreviewEndpointMatchesEndpoint
+	ErrExperimentNotSupportedByPredictorType
+	ErrDuplicateVariantName
+	ErrVariantWeightsSum
+	ErrTargetGPUUtilizationWithoutGPU
+	ErrScheduleNotWeekly
+	ErrOverlappingSchedules
+	ErrInvalidFieldPath
+	ErrS3DestinationNotWritable
+	ErrWebSocketIdleAction
+	ErrIncompatibleTensorFlowVersion
+	ErrIncompatibleONNXModel
+	ErrInvalidModelFileExtension
+	ErrInvalidModelFile
+	ErrGPUNotSupportedByPredictorType
+	ErrMLflowNotConfigured
+	ErrUnsupportedMLflowModelSource
+	ErrIncompatibleMLflowFlavor
+	ErrSageMakerFrameworkMismatch
+	ErrSageMakerModelNotImported
+	ErrReservedPipelineStepName
+	ErrDuplicatePipelineStepName
+	ErrPipelineStepIsPipeline
+	ErrWebSocketPipelineStep
+	ErrPredictorTypeNotColocatable
+	ErrUndefinedPipelineInput
+	ErrUndefinedPipelineStep
+	ErrPipelineCycle
+	ErrAmbiguousPipelineOutput
+	ErrUnusedPipelineStep
+	ErrBatchingNotSupportedByPredictorType
+	ErrBatchingNotSupportedByNetworking
+	ErrProcessesExceedCPU
+	ErrPythonVersionNotSupportedByPredictorType
+	ErrPythonVersionWithConda
+	ErrARM64NotSupported
+	ErrPipelineStepArchMismatch
+	ErrConfigReloadWithExperiment
+	ErrProjectFileNotFound
+	ErrInvalidProjectPattern
+	ErrDependencyCycle
+	ErrNotSupportedByClusterLocal
+	ErrSidecarMayBreakMTLS
+	ErrInvalidClaimName
+	ErrInvalidMetadataName
+	ErrInvalidHeaderPattern
+	ErrDuplicateForwardHeader
+	ErrDuplicateMetadataName
+	ErrCacheKeyWithoutVariables
+	ErrInvalidCacheKeyVariable
+	ErrCacheNotSupportedByNetworking
+	ErrCachedPipelineStepNotColocatable
+	ErrEmptyCircuitBreaker
+	ErrInvalidRetryStatusCode
+	ErrPerTryTimeoutExceedsTimeout
+	ErrLoadSheddingQueueTooDeep
+	ErrShedStatusCodeRetried
+	ErrShedStatusCodeEjectsReplicas
+	ErrExplainerNotPythonFile
+	ErrExplainerNotSupportedByPredictorType
+	ErrExplainerNotSupportedByNetworking
+	ErrExplainerPackageNotInstalled
+	ErrInvalidFeedbackDestination
+	ErrFeedbackNotSupportedByNetworking
+	ErrInvalidFeedbackMetric
+	ErrFeedbackMetricsWithoutTracker
+	ErrFeedbackMetricNotApplicable
+	ErrInvalidAlertDestination
+	ErrDuplicateAlertName
+	ErrAlertThresholdNotFraction
+	ErrAlertMetricRequiresDataDrift
 )
 
 var errorKinds = []string{
@@ -72,12 +174,110 @@ var errorKinds = []string{
 	"err_external_not_found",
 	"err_onnx_doesnt_support_zip",
 	"err_invalid_tensorflow_dir",
-	"err_field_must_be_defined_for_predictor_type",
-	"err_field_not_supported_by_predictor_type",
 	"err_duplicate_endpoints",
+	"err_reserved_container_name",
+	"err_duplicate_container_name",
+	"err_invalid_file_system_id",
+	"err_mount_path_must_be_absolute",
+	"err_reserved_mount_path",
+	"err_duplicate_mount_path",
+	"err_invalid_secret_reference",
+	"err_project_too_many_files",
+	"err_project_files_too_large",
+	"err_project_too_large",
+	"err_requirement_conflicts_with_runtime",
+	"err_conda_not_supported_by_predictor_type",
+	"err_invalid_conda_package",
+	"err_malformed_conda_environment",
+	"err_predictor_class_not_defined",
+	"err_predictor_function_not_defined",
+	"err_invalid_predictor_function_signature",
+	"err_unresolved_variables",
+	"err_mem_without_unit",
+	"err_cpu_without_millicores",
+	"err_target_cpu_utilization_above_request",
+	"err_reserved_endpoint",
+	"err_invalid_cors_origin",
+	"err_stream_requests_not_supported_by_predictor_type",
+	"err_max_request_size_requires_streaming",
+	"err_web_socket_not_supported_by_predictor_type",
+	"err_web_socket_autoscaling",
+	"err_invalid_header_name",
+	"err_invalid_ip_block",
+	"err_preview_endpoint_matches_endpoint",
+	"err_experiment_not_supported_by_predictor_type",
+	"err_duplicate_variant_name",
+	"err_variant_weights_sum",
+	"err_target_gpu_utilization_without_gpu",
+	"err_schedule_not_weekly",
+	"err_overlapping_schedules",
+	"err_invalid_field_path",
+	"err_s3_destination_not_writable",
+	"err_web_socket_idle_action",
+	"err_incompatible_tensorflow_version",
+	"err_incompatible_onnx_model",
+	"err_invalid_model_file_extension",
+	"err_invalid_model_file",
+	"err_gpu_not_supported_by_predictor_type",
+	"err_mlflow_not_configured",
+	"err_unsupported_mlflow_model_source",
+	"err_incompatible_mlflow_flavor",
+	"err_sagemaker_framework_mismatch",
+	"err_sagemaker_model_not_imported",
+	"err_reserved_pipeline_step_name",
+	"err_duplicate_pipeline_step_name",
+	"err_pipeline_step_is_pipeline",
+	"err_web_socket_pipeline_step",
+	"err_predictor_type_not_colocatable",
+	"err_undefined_pipeline_input",
+	"err_undefined_pipeline_step",
+	"err_pipeline_cycle",
+	"err_ambiguous_pipeline_output",
+	"err_unused_pipeline_step",
+	"err_batching_not_supported_by_predictor_type",
+	"err_batching_not_supported_by_networking",
+	"err_processes_exceed_cpu",
+	"err_python_version_not_supported_by_predictor_type",
+	"err_python_version_with_conda",
+	"err_arm64_not_supported",
+	"err_pipeline_step_arch_mismatch",
+	"err_config_reload_with_experiment",
+	"err_project_file_not_found",
+	"err_invalid_project_pattern",
+	"err_dependency_cycle",
+	"err_not_supported_by_cluster_local",
+	"err_sidecar_may_break_mtls",
+	"err_invalid_claim_name",
+	"err_invalid_metadata_name",
+	"err_invalid_header_pattern",
+	"err_duplicate_forward_header",
+	"err_duplicate_metadata_name",
+	"err_cache_key_without_variables",
+	"err_invalid_cache_key_variable",
+	"err_cache_not_supported_by_networking",
+	"err_cached_pipeline_step_not_colocatable",
+	"err_empty_circuit_breaker",
+	"err_invalid_retry_status_code",
+	"err_per_try_timeout_exceeds_timeout",
+	"err_load_shedding_queue_too_deep",
+	"err_shed_status_code_retried",
+	"err_shed_status_code_ejects_replicas",
+	"err_explainer_not_python_file",
+	"err_explainer_not_supported_by_predictor_type",
+	"err_explainer_not_supported_by_networking",
+	"err_explainer_package_not_installed",
+	"err_invalid_feedback_destination",
+	"err_feedback_not_supported_by_networking",
+	"err_invalid_feedback_metric",
+	"err_feedback_metrics_without_tracker",
+	"err_feedback_metric_not_applicable",
+	"err_invalid_alert_destination",
+	"err_duplicate_alert_name",
+	"err_alert_threshold_not_fraction",
+	"err_alert_metric_requires_data_drift",
 }
 
-var _ = [1]int{}[int(ErrDuplicateEndpoints)-(len(errorKinds)-1)] // Ensure list length matches
+var _ = [1]int{}[int(ErrAlertMetricRequiresDataDrift)-(len(errorKinds)-1)] // Ensure list length matches
 
 func (t ErrorKind) String() string {
 	return errorKinds[t]
@@ -185,6 +385,13 @@ func ErrorSpecifyAllOrNone(vals ...string) error {
 	})
 }
 
+func ErrorSpecifyOnlyOne(vals ...string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrSpecifyOnlyOne,
+		message: fmt.Sprintf("please specify exactly one of %s", s.UserStrsOr(vals)),
+	})
+}
+
 func ErrorSpecifyOneModelFormatFoundNone(vals ...string) error {
 	message := fmt.Sprintf("please specify a model format (%s)", s.UserStrsOr(vals))
 	return errors.WithStack(Error{
@@ -279,23 +486,720 @@ func ErrorInvalidTensorFlowDir(path string) error {
 	})
 }
 
-func ErrorFieldMustBeDefinedForPredictorType(fieldKey string, predictorType PredictorType) error {
+func ErrorDuplicateEndpoints(endpoint string, apiNames ...string) error {
 	return errors.WithStack(Error{
-		Kind:    ErrFieldMustBeDefinedForPredictorType,
-		message: fmt.Sprintf("%s field must be defined for the %s predictor type", fieldKey, predictorType.String()),
+		Kind:    ErrDuplicateEndpoints,
+		message: fmt.Sprintf("multiple APIs specify the same endpoint (endpoint %s is used by the %s APIs)", s.UserStr(endpoint), s.UserStrsAnd(apiNames)),
 	})
 }
 
-func ErrorFieldNotSupportedByPredictorType(fieldKey string, predictorType PredictorType) error {
+func ErrorReservedContainerName(name string) error {
 	return errors.WithStack(Error{
-		Kind:    ErrFieldNotSupportedByPredictorType,
-		message: fmt.Sprintf("%s is not a supported field for the %s predictor type", fieldKey, predictorType.String()),
+		Kind:    ErrReservedContainerName,
+		message: fmt.Sprintf("container name %s is reserved by cortex (reserved names: %s)", s.UserStr(name), s.UserStrsAnd(ReservedContainerNames)),
 	})
 }
 
-func ErrorDuplicateEndpoints(endpoint string, apiNames ...string) error {
+func ErrorDuplicateContainerName(name string) error {
 	return errors.WithStack(Error{
-		Kind:    ErrDuplicateEndpoints,
-		message: fmt.Sprintf("multiple APIs specify the same endpoint (endpoint %s is used by the %s APIs)", s.UserStr(endpoint), s.UserStrsAnd(apiNames)),
+		Kind:    ErrDuplicateContainerName,
+		message: fmt.Sprintf("container name %s must be unique within an API", s.UserStr(name)),
+	})
+}
+
+func ErrorInvalidFileSystemID(fileSystemID string, volumeType VolumeType) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidFileSystemID,
+		message: fmt.Sprintf("%s is not a valid %s file system ID", s.UserStr(fileSystemID), volumeType.String()),
+	})
+}
+
+func ErrorMountPathMustBeAbsolute(path string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrMountPathMustBeAbsolute,
+		message: fmt.Sprintf("%s must be an absolute path", s.UserStr(path)),
+	})
+}
+
+func ErrorReservedMountPath(path string, reservedPath string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrReservedMountPath,
+		message: fmt.Sprintf("%s conflicts with %s, which is reserved by cortex", s.UserStr(path), s.UserStr(reservedPath)),
+	})
+}
+
+func ErrorDuplicateMountPath(path string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDuplicateMountPath,
+		message: fmt.Sprintf("mount path %s is used by multiple volumes", s.UserStr(path)),
+	})
+}
+
+func ErrorInvalidSecretReference(provided string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidSecretReference,
+		message: fmt.Sprintf("%s is not a valid secret reference (valid formats are ${secret:<secret name or arn>} for AWS Secrets Manager and ${ssm:<parameter name or arn>} for AWS Systems Manager Parameter Store)", s.UserStr(provided)),
+	})
+}
+
+const projectIgnoreHint = "files can be excluded from the project by adding them to a .cortexignore file in your project's root directory"
+
+func ErrorProjectTooManyFiles(numFiles int, maxFiles int64) error {
+	return errors.WithStack(Error{
+		Kind:    ErrProjectTooManyFiles,
+		message: fmt.Sprintf("your project contains %d files, which exceeds the limit of %d files (%s)", numFiles, maxFiles, projectIgnoreHint),
+	})
+}
+
+func ErrorProjectFilesTooLarge(files []string, maxFileSize int64) error {
+	return errors.WithStack(Error{
+		Kind:    ErrProjectFilesTooLarge,
+		message: fmt.Sprintf("the following project files exceed the limit of %s per file: %s (%s; large files such as models should be stored in S3 instead)", s.ByteSize(maxFileSize), strings.Join(files, ", "), projectIgnoreHint),
+	})
+}
+
+func ErrorProjectTooLarge(size int64, maxSize int64, largestFiles []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrProjectTooLarge,
+		message: fmt.Sprintf("your project is %s, which exceeds the limit of %s; the largest files are: %s (%s)", s.ByteSize(size), s.ByteSize(maxSize), strings.Join(largestFiles, ", "), projectIgnoreHint),
+	})
+}
+
+func ErrorRequirementConflictsWithRuntime(requirement string, packageName string, installedVersion string, predictorType PredictorType) error {
+	return errors.WithStack(Error{
+		Kind:    ErrRequirementConflictsWithRuntime,
+		message: fmt.Sprintf("%s conflicts with %s==%s, which is required by the %s predictor's runtime (please remove the version constraint, or remove %s from %s)", s.UserStr(requirement), packageName, installedVersion, predictorType.String(), packageName, RequirementsFileName),
+	})
+}
+
+func ErrorCondaNotSupportedByPredictorType(fileName string, predictorType PredictorType) error {
+	return errors.WithStack(Error{
+		Kind:    ErrCondaNotSupportedByPredictorType,
+		message: fmt.Sprintf("%s is not supported by the %s predictor type (conda environments are only supported by the %s predictor type)", fileName, predictorType.String(), PythonPredictorType.String()),
+	})
+}
+
+func ErrorInvalidCondaPackage(provided string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidCondaPackage,
+		message: fmt.Sprintf("%s is not a valid conda package specification (e.g. numpy, numpy=1.18, or conda-forge::opencv are valid)", s.UserStr(provided)),
+	})
+}
+
+func ErrorMalformedCondaEnvironment() error {
+	return errors.WithStack(Error{
+		Kind:    ErrMalformedCondaEnvironment,
+		message: fmt.Sprintf("%s must be a map (with keys such as channels and dependencies)", CondaEnvironmentFileName),
+	})
+}
+
+func ErrorPredictorClassNotDefined(className string, implPath string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPredictorClassNotDefined,
+		message: fmt.Sprintf("%s: %s class is not defined", implPath, className),
+	})
+}
+
+func ErrorPredictorFunctionNotDefined(fnName string, className string, implPath string, line int) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPredictorFunctionNotDefined,
+		message: fmt.Sprintf("%s:%d: required function \"%s\" is not defined in the %s class", implPath, line, fnName, className),
+	})
+}
+
+func ErrorInvalidPredictorFunctionSignature(fnName string, className string, implPath string, line int, expectedArgs []string, actualArgs []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidPredictorFunctionSignature,
+		message: fmt.Sprintf("%s:%d: invalid signature for function \"%s\" in the %s class: expected arguments (%s) but found (%s)", implPath, line, fnName, className, strings.Join(expectedArgs, ", "), strings.Join(actualArgs, ", ")),
+	})
+}
+
+func ErrorUnresolvedVariables(names []string) error {
+	varStrs := make([]string, len(names))
+	for i, name := range names {
+		varStrs[i] = "${" + name + "}"
+	}
+	return errors.WithStack(Error{
+		Kind:    ErrUnresolvedVariables,
+		message: fmt.Sprintf("%s must be defined, either as environment variables or in a values file passed via --values (alternatively, a default can be provided, e.g. ${%s:-default})", s.StrsAnd(varStrs), names[0]),
+	})
+}
+
+func ErrorMemWithoutUnit(mem string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrMemWithoutUnit,
+		message: fmt.Sprintf("%s is interpreted as %s bytes; did you mean %sMi? (memory is specified in bytes unless a unit such as Mi or Gi is provided)", MemKey, mem, mem),
+	})
+}
+
+func ErrorCPUWithoutMillicores(cpu string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrCPUWithoutMillicores,
+		message: fmt.Sprintf("%s is interpreted as %s cores; did you mean %sm? (CPU is specified in cores unless the m suffix is used for millicores)", CPUKey, cpu, cpu),
+	})
+}
+
+func ErrorTargetCPUUtilizationAboveRequest(targetCPUUtilization int32) error {
+	return errors.WithStack(Error{
+		Kind:    ErrTargetCPUUtilizationAboveRequest,
+		message: fmt.Sprintf("%s is %d, so the API will only scale up once its replicas use more CPU than they request (%s is a percentage of the requested %s)", TargetCPUUtilizationKey, targetCPUUtilization, TargetCPUUtilizationKey, CPUKey),
+	})
+}
+
+func ErrorReservedEndpoint(endpoint string, reservedPrefix string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrReservedEndpoint,
+		message: fmt.Sprintf("%s conflicts with %s, which is reserved by cortex (%s are not allowed, including sub-paths)", endpoint, reservedPrefix, s.StrsAnd(ReservedEndpointPrefixes)),
+	})
+}
+
+func ErrorInvalidCORSOrigin(origin string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidCORSOrigin,
+		message: fmt.Sprintf("%s is not a valid origin (origins must be a scheme and host, e.g. https://example.com, or * to allow all origins)", s.UserStr(origin)),
+	})
+}
+
+func ErrorStreamRequestsNotSupportedByPredictorType(predictorType PredictorType) error {
+	return errors.WithStack(Error{
+		Kind:    ErrStreamRequestsNotSupportedByPredictorType,
+		message: fmt.Sprintf("streaming requests is not supported for the %s predictor type (only the %s predictor type can receive streamed requests)", predictorType.String(), PythonPredictorType.String()),
+	})
+}
+
+func ErrorMaxRequestSizeRequiresStreaming(maxRequestSize int64, maxBufferedRequestSize int64) error {
+	return errors.WithStack(Error{
+		Kind:    ErrMaxRequestSizeRequiresStreaming,
+		message: fmt.Sprintf("%s cannot exceed %s unless %s is enabled, since request bodies are read into memory (got %s)", MaxRequestSizeKey, s.ByteSize(maxBufferedRequestSize), StreamRequestsKey, s.ByteSize(maxRequestSize)),
+	})
+}
+
+func ErrorWebSocketNotSupportedByPredictorType(predictorType PredictorType) error {
+	return errors.WithStack(Error{
+		Kind:    ErrWebSocketNotSupportedByPredictorType,
+		message: fmt.Sprintf("the %s protocol is not supported for the %s predictor type (only the %s predictor type can serve %s connections)", WebSocketProtocol.String(), predictorType.String(), PythonPredictorType.String(), WebSocketProtocol.String()),
+	})
+}
+
+func ErrorWebSocketAutoscaling(minReplicas int32, maxReplicas int32) error {
+	return errors.WithStack(Error{
+		Kind:    ErrWebSocketAutoscaling,
+		message: fmt.Sprintf("%s and %s must be equal when %s is %s, since scaling down would close open connections (got %s: %d and %s: %d)", MinReplicasKey, MaxReplicasKey, ProtocolKey, WebSocketProtocol.String(), MinReplicasKey, minReplicas, MaxReplicasKey, maxReplicas),
+	})
+}
+
+func ErrorInvalidHeaderName(header string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidHeaderName,
+		message: fmt.Sprintf("%s is not a valid HTTP header name", s.UserStr(header)),
+	})
+}
+
+func ErrorInvalidIPBlock(ipBlock string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidIPBlock,
+		message: fmt.Sprintf("%s is not a valid IP address or CIDR block (e.g. 203.0.113.7 or 10.0.0.0/16)", s.UserStr(ipBlock)),
+	})
+}
+
+func ErrorPreviewEndpointMatchesEndpoint(endpoint string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPreviewEndpointMatchesEndpoint,
+		message: fmt.Sprintf("%s must be different from the api's %s (both are %s)", PreviewEndpointKey, EndpointKey, endpoint),
+	})
+}
+
+func ErrorExperimentNotSupportedByPredictorType(predictorType PredictorType) error {
+	return errors.WithStack(Error{
+		Kind:    ErrExperimentNotSupportedByPredictorType,
+		message: fmt.Sprintf("experiments are not supported for the %s predictor type (only the %s predictor type supports experiments)", predictorType.String(), PythonPredictorType.String()),
+	})
+}
+
+func ErrorDuplicateVariantName(name string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDuplicateVariantName,
+		message: fmt.Sprintf("variant name %s must be unique within an experiment", s.UserStr(name)),
+	})
+}
+
+func ErrorVariantWeightsSum(sum int32) error {
+	return errors.WithStack(Error{
+		Kind:    ErrVariantWeightsSum,
+		message: fmt.Sprintf("the %ss of the %s must add up to 100 (got %d)", WeightKey, VariantsKey, sum),
+	})
+}
+
+func ErrorTargetGPUUtilizationWithoutGPU() error {
+	return errors.WithStack(Error{
+		Kind:    ErrTargetGPUUtilizationWithoutGPU,
+		message: fmt.Sprintf("%s can only be specified if %s is greater than 0", TargetGPUUtilizationKey, GPUKey),
+	})
+}
+
+func ErrorScheduleNotWeekly() error {
+	return errors.WithStack(Error{
+		Kind:    ErrScheduleNotWeekly,
+		message: "the day of month and month fields must be * (schedules can only depend on the time of day and the day of week)",
+	})
+}
+
+func ErrorOverlappingSchedules(index1 int, index2 int) error {
+	return errors.WithStack(Error{
+		Kind:    ErrOverlappingSchedules,
+		message: fmt.Sprintf("%s %d and %d overlap (only one schedule can be active at a time)", SchedulesKey, index1, index2),
+	})
+}
+
+func ErrorInvalidFieldPath(field string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidFieldPath,
+		message: fmt.Sprintf("%s is not a valid field path (fields are separated by dots, e.g. user.email)", s.UserStr(field)),
+	})
+}
+
+func ErrorS3DestinationNotWritable(destination string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrS3DestinationNotWritable,
+		message: fmt.Sprintf("unable to write to %s; the cluster's AWS credentials need the s3:PutObject permission for the destination", destination),
+	})
+}
+
+func ErrorWebSocketIdleAction(action IdleAction) error {
+	return errors.WithStack(Error{
+		Kind:    ErrWebSocketIdleAction,
+		message: fmt.Sprintf("%s: %s is not supported when %s is %s, since requests over open connections aren't counted", ActionKey, action.String(), ProtocolKey, WebSocketProtocol.String()),
+	})
+}
+
+func ErrorIncompatibleTensorFlowVersion(modelVersion string, servingVersion string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrIncompatibleTensorFlowVersion,
+		message: fmt.Sprintf("the model was exported with TensorFlow %s, which is newer than TensorFlow Serving %s (%s must be at least the model's version of TensorFlow)", modelVersion, servingVersion, TensorFlowServingVersionKey),
+	})
+}
+
+func ErrorIncompatibleONNXModel(irVersion int64, opsetVersion int64, runtimeVersion string) error {
+	limits := _onnxRuntimeLimits[runtimeVersion]
+	return errors.WithStack(Error{
+		Kind:    ErrIncompatibleONNXModel,
+		message: fmt.Sprintf("the model uses ONNX IR version %d and opset version %d, but ONNX Runtime %s supports up to IR version %d and opset version %d", irVersion, opsetVersion, runtimeVersion, limits.maxIRVersion, limits.maxOpsetVersion),
+	})
+}
+
+func ErrorInvalidModelFileExtension(path string, predictorType PredictorType, extensions []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidModelFileExtension,
+		message: fmt.Sprintf("%s: models for the %s predictor type must have one of these extensions: %s", path, predictorType.String(), s.StrsOr(extensions)),
+	})
+}
+
+func ErrorInvalidModelFile(path string, predictorType PredictorType, reason string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidModelFile,
+		message: fmt.Sprintf("%s: unable to load the model with the %s predictor type: %s", path, predictorType.String(), reason),
+	})
+}
+
+func ErrorGPUNotSupportedByPredictorType(predictorType PredictorType) error {
+	return errors.WithStack(Error{
+		Kind:    ErrGPUNotSupportedByPredictorType,
+		message: fmt.Sprintf("gpus are not supported for the %s predictor type (models are served on cpus)", predictorType.String()),
+	})
+}
+
+func ErrorMLflowNotConfigured() error {
+	return errors.WithStack(Error{
+		Kind:    ErrMLflowNotConfigured,
+		message: fmt.Sprintf("%s models can't be deployed because the cluster isn't configured with an MLflow registry (set mlflow.tracking_uri in your cluster configuration file)", mlflow.ModelURIScheme),
+	})
+}
+
+func ErrorUnsupportedMLflowModelSource(modelName string, version string, source string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrUnsupportedMLflowModelSource,
+		message: fmt.Sprintf("version %s of %s is stored at %s, but only models which are stored in S3 can be deployed", version, s.UserStr(modelName), source),
+	})
+}
+
+func ErrorIncompatibleMLflowFlavor(modelName string, version string, predictorType PredictorType, flavors []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrIncompatibleMLflowFlavor,
+		message: fmt.Sprintf("version %s of %s can't be served by the %s predictor type, which requires the %s flavor (the model's flavors are %s)", version, s.UserStr(modelName), predictorType.String(), MLflowFlavors[predictorType], s.StrsAnd(flavors)),
+	})
+}
+
+func ErrorSageMakerFrameworkMismatch(artifactPath string, framework sagemaker.Framework, predictorType PredictorType) error {
+	message := fmt.Sprintf("%s contains a %s model, which can't be served by the %s predictor type (use the %s predictor type)", artifactPath, framework.String(), predictorType.String(), framework.String())
+	if framework == sagemaker.SKLearnFramework {
+		message = fmt.Sprintf("%s contains a pickled model, which can only be served by the %s predictor type (models which are saved with Booster.save_model() can be served by the %s and %s predictor types)", artifactPath, SKLearnPredictorType.String(), XGBoostPredictorType.String(), LightGBMPredictorType.String())
+	}
+	return errors.WithStack(Error{
+		Kind:    ErrSageMakerFrameworkMismatch,
+		message: message,
+	})
+}
+
+func ErrorSageMakerModelNotImported(artifactPath string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrSageMakerModelNotImported,
+		message: fmt.Sprintf("%s: SageMaker model artifacts can only be deployed by the operator", artifactPath),
+	})
+}
+
+func ErrorReservedPipelineStepName(name string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrReservedPipelineStepName,
+		message: fmt.Sprintf("%s is reserved (steps can take %s as an input to receive the pipeline's payload)", s.UserStr(name), s.UserStr(PipelinePayloadInput)),
+	})
+}
+
+func ErrorDuplicatePipelineStepName(name string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDuplicatePipelineStepName,
+		message: fmt.Sprintf("step name %s must be unique within a pipeline", s.UserStr(name)),
+	})
+}
+
+func ErrorPipelineStepIsPipeline(apiName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPipelineStepIsPipeline,
+		message: fmt.Sprintf("%s is a pipeline, but pipeline steps must be apis", s.UserStr(apiName)),
+	})
+}
+
+func ErrorWebSocketPipelineStep(apiName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrWebSocketPipelineStep,
+		message: fmt.Sprintf("api %s serves websocket connections, so it can't be a pipeline step", s.UserStr(apiName)),
+	})
+}
+
+func ErrorPredictorTypeNotColocatable(apiName string, predictorType PredictorType) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPredictorTypeNotColocatable,
+		message: fmt.Sprintf("api %s can't be a step of a %s pipeline, because the %s predictor type runs a separate serving container (use the %s mode instead)", s.UserStr(apiName), ColocatedPipelineMode.String(), predictorType.String(), ChainedPipelineMode.String()),
+	})
+}
+
+func ErrorUndefinedPipelineInput(input string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrUndefinedPipelineInput,
+		message: fmt.Sprintf("%s is not a step of the pipeline (inputs can be %s or the names of the pipeline's steps)", s.UserStr(input), s.UserStr(PipelinePayloadInput)),
+	})
+}
+
+func ErrorUndefinedPipelineStep(name string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrUndefinedPipelineStep,
+		message: fmt.Sprintf("%s is not a step of the pipeline", s.UserStr(name)),
+	})
+}
+
+func ErrorPipelineCycle(stepNames []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPipelineCycle,
+		message: fmt.Sprintf("the inputs of steps %s form a cycle (each step can only take the payload and the outputs of the steps which run before it)", s.UserStrsAnd(stepNames)),
+	})
+}
+
+func ErrorAmbiguousPipelineOutput(finalStepNames []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrAmbiguousPipelineOutput,
+		message: fmt.Sprintf("the outputs of steps %s aren't inputs to other steps, so the pipeline's output is ambiguous (specify %s)", s.UserStrsAnd(finalStepNames), OutputKey),
+	})
+}
+
+func ErrorUnusedPipelineStep(stepName string, output string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrUnusedPipelineStep,
+		message: fmt.Sprintf("step %s doesn't contribute to the pipeline's output (the output of step %s)", s.UserStr(stepName), s.UserStr(output)),
+	})
+}
+
+func ErrorBatchingNotSupportedByPredictorType(predictorType PredictorType) error {
+	return errors.WithStack(Error{
+		Kind:    ErrBatchingNotSupportedByPredictorType,
+		message: fmt.Sprintf("batching is not supported for the %s predictor type (only the %s and %s predictor types can batch requests)", predictorType.String(), TensorFlowPredictorType.String(), PythonPredictorType.String()),
+	})
+}
+
+func ErrorBatchingNotSupportedByNetworking(feature string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrBatchingNotSupportedByNetworking,
+		message: fmt.Sprintf("batching can't be used with %s (only buffered http requests can be batched)", feature),
+	})
+}
+
+func ErrorProcessesExceedCPU(processesPerReplica int32, cpu string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrProcessesExceedCPU,
+		message: fmt.Sprintf("%s (%d) cannot be greater than the number of cpus requested per replica, rounded up (%s.%s is %s)", ProcessesPerReplicaKey, processesPerReplica, ComputeKey, CPUKey, cpu),
+	})
+}
+
+func ErrorPythonVersionNotSupportedByPredictorType(pythonVersion string, predictorType PredictorType) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPythonVersionNotSupportedByPredictorType,
+		message: fmt.Sprintf("python %s is not supported for the %s predictor type (supported versions: %s)", pythonVersion, predictorType.String(), s.StrsAnd(SupportedPythonVersions(predictorType))),
+	})
+}
+
+func ErrorPythonVersionWithConda(condaFileName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPythonVersionWithConda,
+		message: fmt.Sprintf("%s can't be specified when the project has a %s file (add python to your conda packages instead, e.g. python=3.8)", PythonVersionKey, condaFileName),
+	})
+}
+
+func ErrorARM64NotSupported(feature string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrARM64NotSupported,
+		message: fmt.Sprintf("%s can't be used on %s (%s images are built for the %s predictor types with python %s, and don't support gpus or conda environments)", feature, ARM64Arch.String(), ARM64Arch.String(), s.StrsAnd(ARM64PredictorTypes()), DefaultPythonVersion),
+	})
+}
+
+func ErrorPipelineStepArchMismatch(stepAPIName string, stepArch Arch, pipelineArch Arch) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPipelineStepArchMismatch,
+		message: fmt.Sprintf("the containers of api %s are colocated in the pipeline's pods, so its %s.%s (%s) must match the pipeline's (%s)", stepAPIName, ComputeKey, ArchKey, stepArch.String(), pipelineArch.String()),
+	})
+}
+
+func ErrorConfigReloadWithExperiment() error {
+	return errors.WithStack(Error{
+		Kind:    ErrConfigReloadWithExperiment,
+		message: fmt.Sprintf("%s can't be used with %s, since each variant's predictor is initialized with its own config", ConfigReloadKey, ExperimentKey),
+	})
+}
+
+func ErrorProjectFileNotFound(path string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrProjectFileNotFound,
+		message: fmt.Sprintf("%s: file does not exist in the project", path),
+	})
+}
+
+func ErrorInvalidProjectPattern(pattern string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidProjectPattern,
+		message: fmt.Sprintf("%s is not a valid glob pattern (see https://golang.org/pkg/path/filepath/#Match for the syntax)", s.UserStr(pattern)),
+	})
+}
+
+func ErrorDependencyCycle(apiNames []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDependencyCycle,
+		message: fmt.Sprintf("the %s of apis %s form a cycle", DependsOnKey, s.UserStrsAnd(apiNames)),
+	})
+}
+
+func ErrorNotSupportedByClusterLocal(feature string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrNotSupportedByClusterLocal,
+		message: fmt.Sprintf("%s is not supported for apis with %s: %s, since they aren't served by a load balancer", feature, ExposeKey, ClusterLocalExpose.String()),
+	})
+}
+
+func ErrorSidecarMayBreakMTLS(image string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrSidecarMayBreakMTLS,
+		message: fmt.Sprintf("%s appears to be a service mesh proxy; the cluster requires mutual TLS, which is handled by the istio proxy in each of the API's pods, so a proxy which intercepts the pod's traffic may cause requests to the API (or from the API to other APIs) to fail", image),
+	})
+}
+
+func ErrorInvalidClaimName(name string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidClaimName,
+		message: fmt.Sprintf("%s is not a valid claim name (claim names can't contain brackets or whitespace)", s.UserStr(name)),
+	})
+}
+
+func ErrorInvalidMetadataName(name string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidMetadataName,
+		message: fmt.Sprintf("%s is not a valid metadata name (it must start with a letter or underscore, and only contain letters, numbers, and underscores)", s.UserStr(name)),
+	})
+}
+
+func ErrorInvalidHeaderPattern(pattern string, err error) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidHeaderPattern,
+		message: fmt.Sprintf("%s is not a valid regular expression: %s", s.UserStr(pattern), err.Error()),
+	})
+}
+
+func ErrorDuplicateForwardHeader(header string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDuplicateForwardHeader,
+		message: fmt.Sprintf("the %s header is forwarded more than once", header),
+	})
+}
+
+func ErrorDuplicateMetadataName(name string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDuplicateMetadataName,
+		message: fmt.Sprintf("multiple forwarded headers have the metadata name %s (set %s to a unique name)", s.UserStr(name), NameKey),
+	})
+}
+
+func ErrorCacheKeyWithoutVariables(key string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrCacheKeyWithoutVariables,
+		message: fmt.Sprintf("%s doesn't reference the request (so every request would receive the same response); use ${%s} for the hash of the request body, and/or ${%s<name>} for the value of a request header", s.UserStr(key), CacheBodyVariable, CacheHeaderVariablePrefix),
+	})
+}
+
+func ErrorInvalidCacheKeyVariable(variable string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidCacheKeyVariable,
+		message: fmt.Sprintf("%s is not a valid variable (valid variables are ${%s}, which is the hash of the request body, and ${%s<name>}, which is the value of a request header)", s.UserStr(variable), CacheBodyVariable, CacheHeaderVariablePrefix),
+	})
+}
+
+func ErrorCacheNotSupportedByNetworking(feature string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrCacheNotSupportedByNetworking,
+		message: fmt.Sprintf("responses can't be cached with %s (only buffered http requests are cached)", feature),
+	})
+}
+
+func ErrorCachedPipelineStepNotColocatable(apiName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrCachedPipelineStepNotColocatable,
+		message: fmt.Sprintf("api %s can't be a step of a %s pipeline, because its %s runs in a separate container (use the %s mode instead)", s.UserStr(apiName), ColocatedPipelineMode.String(), CacheKey, ChainedPipelineMode.String()),
+	})
+}
+
+func ErrorEmptyCircuitBreaker() error {
+	return errors.WithStack(Error{
+		Kind:    ErrEmptyCircuitBreaker,
+		message: fmt.Sprintf("at least one of %s, %s, %s, or %s must be specified", MaxConnectionsKey, MaxPendingRequestsKey, MaxRequestsKey, Consecutive5xxErrorsKey),
+	})
+}
+
+func ErrorInvalidRetryStatusCode(statusCode int32) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidRetryStatusCode,
+		message: fmt.Sprintf("%d is not a retriable status code (only 5xx status codes can be retried)", statusCode),
+	})
+}
+
+func ErrorPerTryTimeoutExceedsTimeout(perTryTimeout time.Duration, timeout time.Duration) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPerTryTimeoutExceedsTimeout,
+		message: fmt.Sprintf("%s (%s) must be less than %s (%s), since the timeout includes the request's retries", PerTryTimeoutKey, perTryTimeout.String(), TimeoutKey, timeout.String()),
+	})
+}
+
+func ErrorLoadSheddingQueueTooDeep(maxQueueDepth int32, concurrency int32, maxThreads int32) error {
+	return errors.WithStack(Error{
+		Kind:    ErrLoadSheddingQueueTooDeep,
+		message: fmt.Sprintf("%s (%d) plus the number of requests which each process handles at once (%d) cannot be greater than %d, since each queued request holds one of the server's threads", MaxQueueDepthKey, maxQueueDepth, concurrency, maxThreads),
+	})
+}
+
+func ErrorShedStatusCodeRetried(statusCode int32) error {
+	return errors.WithStack(Error{
+		Kind:    ErrShedStatusCodeRetried,
+		message: fmt.Sprintf("shed requests' status code (%d) is retried by %s.%s, which would add to the load on overloaded replicas; either set %s to 429 or remove %d from %s.%s", statusCode, NetworkingKey, RetriesKey, StatusCodeKey, statusCode, RetriesKey, StatusCodesKey),
+	})
+}
+
+func ErrorShedStatusCodeEjectsReplicas(statusCode int32) error {
+	return errors.WithStack(Error{
+		Kind:    ErrShedStatusCodeEjectsReplicas,
+		message: fmt.Sprintf("shed requests' status code (%d) counts towards %s.%s.%s, which would eject overloaded replicas and move their load to the others; set %s to 429 instead", statusCode, NetworkingKey, CircuitBreakerKey, Consecutive5xxErrorsKey, StatusCodeKey),
+	})
+}
+
+func ErrorExplainerNotPythonFile(path string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrExplainerNotPythonFile,
+		message: fmt.Sprintf("%s: the explainer's implementation must be a python file (.py)", path),
+	})
+}
+
+func ErrorExplainerNotSupportedByPredictorType(explainerType ExplainerType, predictorType PredictorType) error {
+	return errors.WithStack(Error{
+		Kind:    ErrExplainerNotSupportedByPredictorType,
+		message: fmt.Sprintf("the %s explainer is not supported by the %s predictor type (it explains pytorch models, which are served by the %s predictor type)", explainerType.String(), predictorType.String(), PythonPredictorType.String()),
+	})
+}
+
+func ErrorExplainerNotSupportedByNetworking(feature string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrExplainerNotSupportedByNetworking,
+		message: fmt.Sprintf("%s is not supported for APIs with %s (explanations are requested with json request bodies over http)", ExplainerKey, feature),
+	})
+}
+
+func ErrorExplainerPackageNotInstalled(packageName string, explainerType ExplainerType) error {
+	return errors.WithStack(Error{
+		Kind:    ErrExplainerPackageNotInstalled,
+		message: fmt.Sprintf("the %s explainer requires the %s package, which must be listed in your project's %s", explainerType.String(), packageName, RequirementsFileName),
+	})
+}
+
+func ErrorInvalidFeedbackDestination(destination string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidFeedbackDestination,
+		message: fmt.Sprintf("%s is not a valid feedback destination (it must be an S3 prefix, e.g. s3://my-bucket/feedback, or the ARN of a Kinesis Data Firehose delivery stream)", destination),
+	})
+}
+
+func ErrorFeedbackNotSupportedByNetworking(feature string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrFeedbackNotSupportedByNetworking,
+		message: fmt.Sprintf("%s is not supported for APIs with %s (prediction ids are returned in the response headers of http requests)", FeedbackKey, feature),
+	})
+}
+
+func ErrorInvalidFeedbackMetric(metric string, metrics []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidFeedbackMetric,
+		message: fmt.Sprintf("invalid metric \"%s\" (valid metrics are %s)", metric, s.StrsOr(metrics)),
+	})
+}
+
+func ErrorFeedbackMetricsWithoutTracker() error {
+	return errors.WithStack(Error{
+		Kind:    ErrFeedbackMetricsWithoutTracker,
+		message: fmt.Sprintf("%s can only be computed for APIs with a %s (the metrics compare the ground truth with the tracked predictions)", MetricsKey, TrackerKey),
+	})
+}
+
+func ErrorFeedbackMetricNotApplicable(metric string, modelType ModelType, metrics []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrFeedbackMetricNotApplicable,
+		message: fmt.Sprintf("the %s metric doesn't apply to %s predictions (the metrics of %s predictions are %s)", metric, modelType.String(), modelType.String(), s.StrsAnd(metrics)),
+	})
+}
+
+func ErrorInvalidAlertDestination(destination string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidAlertDestination,
+		message: fmt.Sprintf("%s is not a valid alert destination (it must be the ARN of an SNS topic or the http(s) URL of a webhook)", destination),
+	})
+}
+
+func ErrorDuplicateAlertName(name string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDuplicateAlertName,
+		message: fmt.Sprintf("alert name %s must be unique within an API (alert names default to their metric, so specify a %s for each alert which shares a metric)", s.UserStr(name), NameKey),
+	})
+}
+
+func ErrorAlertThresholdNotFraction(metric string, threshold float64) error {
+	return errors.WithStack(Error{
+		Kind:    ErrAlertThresholdNotFraction,
+		message: fmt.Sprintf("the %s metric is a fraction, so its threshold must be between 0 and 1 (got %s)", metric, s.Float64(threshold)),
+	})
+}
+
+func ErrorAlertMetricRequiresDataDrift(metric string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrAlertMetricRequiresDataDrift,
+		message: fmt.Sprintf("the %s metric can only be evaluated for APIs which track data drift (specify %s.%s)", metric, TrackerKey, DriftKey),
 	})
 }
@@ -0,0 +1,170 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/yaml"
+)
+
+// Requests are assigned to variants by a consistent hash of the header or cookie value,
+// so that requests which share a value (e.g. a user ID) are always served by the same variant
+type Experiment struct {
+	Header   *string  `json:"header" yaml:"header"`
+	Cookie   *string  `json:"cookie" yaml:"cookie"`
+	Variants Variants `json:"variants" yaml:"variants"`
+}
+
+type Variants []*Variant
+
+type Variant struct {
+	Name   string                 `json:"name" yaml:"name"`
+	Weight int32                  `json:"weight" yaml:"weight"`
+	Config map[string]interface{} `json:"config" yaml:"config"` // merged into the predictor's config
+}
+
+var experimentFieldValidation = &cr.StructFieldValidation{
+	StructField: "Experiment",
+	StructValidation: &cr.StructValidation{
+		DefaultNil: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "Header",
+				StringPtrValidation: &cr.StringPtrValidation{
+					Validator: validateHeaderName,
+				},
+			},
+			{
+				StructField:         "Cookie",
+				StringPtrValidation: &cr.StringPtrValidation{},
+			},
+			{
+				StructField: "Variants",
+				StructListValidation: &cr.StructListValidation{
+					Required: true,
+					StructValidation: &cr.StructValidation{
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "Name",
+								StringValidation: &cr.StringValidation{
+									Required: true,
+									DNS1035:  true,
+								},
+							},
+							{
+								StructField: "Weight",
+								Int32Validation: &cr.Int32Validation{
+									Required:             true,
+									GreaterThanOrEqualTo: pointer.Int32(0),
+									LessThanOrEqualTo:    pointer.Int32(100),
+								},
+							},
+							{
+								StructField: "Config",
+								InterfaceMapValidation: &cr.InterfaceMapValidation{
+									StringKeysOnly: true,
+									AllowEmpty:     true,
+									Default:        map[string]interface{}{},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func (experiment *Experiment) Validate(predictorType PredictorType) error {
+	if predictorType != PythonPredictorType {
+		return ErrorExperimentNotSupportedByPredictorType(predictorType)
+	}
+
+	if (experiment.Header == nil) == (experiment.Cookie == nil) {
+		return ErrorSpecifyOnlyOne(HeaderKey, CookieKey)
+	}
+
+	if err := experiment.Variants.Validate(); err != nil {
+		return errors.Wrap(err, VariantsKey)
+	}
+
+	return nil
+}
+
+func (variants Variants) Validate() error {
+	names := strset.New()
+	var weightsSum int32
+	for i, variant := range variants {
+		if names.Has(variant.Name) {
+			return errors.Wrap(ErrorDuplicateVariantName(variant.Name), s.Index(i), NameKey)
+		}
+		names.Add(variant.Name)
+
+		if err := validateSecretReferences(variant.Config); err != nil {
+			return errors.Wrap(err, s.Index(i), ConfigKey)
+		}
+
+		weightsSum += variant.Weight
+	}
+
+	if weightsSum != 100 {
+		return ErrorVariantWeightsSum(weightsSum)
+	}
+
+	return nil
+}
+
+func (experiment *Experiment) UserConfigStr() string {
+	var sb strings.Builder
+	if experiment.Header != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", HeaderKey, *experiment.Header))
+	}
+	if experiment.Cookie != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", CookieKey, *experiment.Cookie))
+	}
+	sb.WriteString(fmt.Sprintf("%s:\n", VariantsKey))
+	sb.WriteString(s.Indent(experiment.Variants.UserConfigStr(), "  "))
+	return sb.String()
+}
+
+func (variant *Variant) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", NameKey, variant.Name))
+	sb.WriteString(fmt.Sprintf("%s: %d\n", WeightKey, variant.Weight))
+	if len(variant.Config) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", ConfigKey))
+		d, _ := yaml.Marshal(&variant.Config)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
+	return sb.String()
+}
+
+func (variants Variants) UserConfigStr() string {
+	var sb strings.Builder
+	for _, variant := range variants {
+		variantStr := s.Indent(variant.UserConfigStr(), "  ")
+		sb.WriteString("- " + strings.TrimPrefix(variantStr, "  "))
+	}
+	return sb.String()
+}
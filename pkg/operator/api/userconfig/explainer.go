@@ -0,0 +1,195 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pip"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/yaml"
+)
+
+// ExplainSubpath is the path under the API's endpoint which serves explanations of its predictions
+const ExplainSubpath = "explain"
+
+// Explainer configures the explanations of the API's predictions, which are served at <endpoint>/explain
+type Explainer struct {
+	Type       ExplainerType          `json:"type" yaml:"type"`
+	Path       *string                `json:"path" yaml:"path"`             // the implementation of python explainers
+	Background *string                `json:"background" yaml:"background"` // a csv file in the project with sample inputs for shap and lime explainers
+	Config     map[string]interface{} `json:"config" yaml:"config"`
+}
+
+// Keep in sync with EXPLAINER_CLASS_VALIDATION in pkg/workloads/cortex/lib/context.py
+const explainerClassName = "Explainer"
+
+var explainerClassFunctions = []predictorFunction{
+	{name: "__init__", args: []string{"self", "predictor", "config"}},
+	{name: "explain", args: []string{"self", "payload"}},
+}
+
+// The packages which the explainer types use; they aren't installed in the predictor images, so they must be listed in the project's requirements.txt
+var explainerPackages = map[ExplainerType]string{
+	SHAPExplainerType:   "shap",
+	LIMEExplainerType:   "lime",
+	CaptumExplainerType: "captum",
+}
+
+var explainerFieldValidation = &cr.StructFieldValidation{
+	StructField: "Explainer",
+	StructValidation: &cr.StructValidation{
+		DefaultNil: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "Type",
+				StringValidation: &cr.StringValidation{
+					Required:      true,
+					AllowedValues: ExplainerTypeStrings(),
+				},
+				Parser: func(str string) (interface{}, error) {
+					return ExplainerTypeFromString(str), nil
+				},
+			},
+			{
+				StructField: "Path",
+				StringPtrValidation: &cr.StringPtrValidation{
+					Validator: ensurePythonFileSuffix,
+				},
+				RequiredIf: &cr.FieldCondition{Key: TypeKey, Values: []interface{}{PythonExplainerType}},
+				AllowedIf:  &cr.FieldCondition{Key: TypeKey, Values: []interface{}{PythonExplainerType}},
+			},
+			{
+				StructField:         "Background",
+				StringPtrValidation: &cr.StringPtrValidation{},
+				RequiredIf:          &cr.FieldCondition{Key: TypeKey, Values: []interface{}{SHAPExplainerType, LIMEExplainerType}},
+				AllowedIf:           &cr.FieldCondition{Key: TypeKey, Values: []interface{}{SHAPExplainerType, LIMEExplainerType}},
+			},
+			{
+				StructField: "Config",
+				InterfaceMapValidation: &cr.InterfaceMapValidation{
+					StringKeysOnly: true,
+					AllowEmpty:     true,
+					Default:        map[string]interface{}{},
+				},
+			},
+		},
+	},
+}
+
+func ensurePythonFileSuffix(path string) (string, error) {
+	if !strings.HasSuffix(path, ".py") {
+		return "", ErrorExplainerNotPythonFile(path)
+	}
+	return path, nil
+}
+
+// Validate checks that the explainer's implementation exists in the project, and that it can explain the predictor type's predictions
+// (captum explains pytorch models, which are served by python predictors)
+func (explainer *Explainer) Validate(predictorType PredictorType, projectFileMap map[string][]byte) error {
+	if explainer.Type == CaptumExplainerType && predictorType != PythonPredictorType {
+		return errors.Wrap(ErrorExplainerNotSupportedByPredictorType(explainer.Type, predictorType), TypeKey)
+	}
+
+	if explainer.Path != nil {
+		implBytes, ok := projectFileMap[*explainer.Path]
+		if !ok {
+			return errors.Wrap(ErrorImplDoesNotExist(*explainer.Path), PathKey)
+		}
+		if err := validatePythonClass(explainerClassName, explainerClassFunctions, *explainer.Path, implBytes); err != nil {
+			return errors.Wrap(err, PathKey)
+		}
+	}
+
+	if explainer.Background != nil {
+		if _, ok := projectFileMap[*explainer.Background]; !ok {
+			return errors.Wrap(ErrorProjectFileNotFound(*explainer.Background), BackgroundKey)
+		}
+	}
+
+	return nil
+}
+
+// ValidateNetworking checks that the API serves http requests with json bodies, which are what the explain endpoint accepts
+func (explainer *Explainer) ValidateNetworking(networking *Networking) error {
+	if networking.StreamRequests {
+		return ErrorExplainerNotSupportedByNetworking(s.UserStr(StreamRequestsKey))
+	}
+	if networking.Protocol == WebSocketProtocol {
+		return ErrorExplainerNotSupportedByNetworking(fmt.Sprintf("the %s protocol", WebSocketProtocol.String()))
+	}
+	return nil
+}
+
+// ValidateExplainerRequirements checks that the packages which the APIs' explainers use are listed in the project's requirements.txt
+// (projects with conda environments aren't checked, since the packages may be installed by conda instead)
+func ValidateExplainerRequirements(projectFileMap map[string][]byte, apis APIs) error {
+	if len(CondaFileNames(projectFileMap)) > 0 {
+		return nil
+	}
+
+	var requirements []*pip.Requirement
+	if requirementsBytes, ok := projectFileMap[RequirementsFileName]; ok {
+		var err error
+		requirements, err = pip.ParseRequirements(string(requirementsBytes))
+		if err != nil {
+			return errors.Wrap(err, RequirementsFileName)
+		}
+	}
+
+	for _, api := range apis {
+		if api.Predictor == nil || api.Predictor.Explainer == nil {
+			continue
+		}
+		packageName, ok := explainerPackages[api.Predictor.Explainer.Type]
+		if !ok || hasRequirement(requirements, packageName) {
+			continue
+		}
+		return errors.Wrap(ErrorExplainerPackageNotInstalled(packageName, api.Predictor.Explainer.Type), Identify(api), PredictorKey, ExplainerKey, TypeKey)
+	}
+
+	return nil
+}
+
+func hasRequirement(requirements []*pip.Requirement, packageName string) bool {
+	for _, req := range requirements {
+		if pip.NormalizeName(req.Name) == packageName {
+			return true
+		}
+	}
+	return false
+}
+
+func (explainer *Explainer) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", TypeKey, explainer.Type.String()))
+	if explainer.Path != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", PathKey, *explainer.Path))
+	}
+	if explainer.Background != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", BackgroundKey, *explainer.Background))
+	}
+	if len(explainer.Config) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", ConfigKey))
+		d, _ := yaml.Marshal(&explainer.Config)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
+	return sb.String()
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type ExplainerType int
+
+const (
+	UnknownExplainerType ExplainerType = iota
+	PythonExplainerType
+	SHAPExplainerType
+	LIMEExplainerType
+	CaptumExplainerType
+)
+
+var explainerTypes = []string{
+	"unknown",
+	"python",
+	"shap",
+	"lime",
+	"captum",
+}
+
+func ExplainerTypeFromString(s string) ExplainerType {
+	for i := 0; i < len(explainerTypes); i++ {
+		if s == explainerTypes[i] {
+			return ExplainerType(i)
+		}
+	}
+	return UnknownExplainerType
+}
+
+func ExplainerTypeStrings() []string {
+	return explainerTypes[1:]
+}
+
+func (t ExplainerType) String() string {
+	return explainerTypes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t ExplainerType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *ExplainerType) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(explainerTypes); i++ {
+		if enum == explainerTypes[i] {
+			*t = ExplainerType(i)
+			return nil
+		}
+	}
+
+	*t = UnknownExplainerType
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *ExplainerType) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t ExplainerType) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
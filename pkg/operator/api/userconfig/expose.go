@@ -0,0 +1,78 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type Expose int
+
+const (
+	UnknownExpose Expose = iota
+	LoadBalancerExpose
+	ClusterLocalExpose
+)
+
+var exposeModes = []string{
+	"unknown",
+	"load_balancer",
+	"cluster_local",
+}
+
+func ExposeFromString(s string) Expose {
+	for i := 0; i < len(exposeModes); i++ {
+		if s == exposeModes[i] {
+			return Expose(i)
+		}
+	}
+	return UnknownExpose
+}
+
+func ExposeStrings() []string {
+	return exposeModes[1:]
+}
+
+func (t Expose) String() string {
+	return exposeModes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t Expose) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *Expose) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(exposeModes); i++ {
+		if enum == exposeModes[i] {
+			*t = Expose(i)
+			return nil
+		}
+	}
+
+	*t = UnknownExpose
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *Expose) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t Expose) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// FeedbackSubpath is the path under the API's endpoint which records the ground truth of its predictions
+const FeedbackSubpath = "feedback"
+
+// Rolling metrics which are computed from the feedback on tracked predictions
+const (
+	AccuracyFeedbackMetric  = "accuracy"
+	PrecisionFeedbackMetric = "precision"
+	RecallFeedbackMetric    = "recall"
+	MAEFeedbackMetric       = "mae" // mean absolute error
+	MSEFeedbackMetric       = "mse" // mean squared error
+)
+
+// the metrics which apply to each model type (in the order in which they're displayed)
+var _feedbackMetrics = map[ModelType][]string{
+	ClassificationModelType: {AccuracyFeedbackMetric, PrecisionFeedbackMetric, RecallFeedbackMetric},
+	RegressionModelType:     {MAEFeedbackMetric, MSEFeedbackMetric},
+}
+
+// Feedback configures the recording of the ground truth of the API's predictions, which is posted to <endpoint>/feedback with the prediction's ID
+type Feedback struct {
+	Destination string        `json:"destination" yaml:"destination"` // an S3 prefix (e.g. s3://my-bucket/feedback) or the ARN of a Kinesis Data Firehose delivery stream
+	Metrics     []string      `json:"metrics" yaml:"metrics"`         // rolling metrics of the tracked predictions (defaults to all of the tracker's model type's metrics)
+	Window      time.Duration `json:"window" yaml:"window"`           // the rolling window of feedback which the metrics are computed from
+}
+
+var feedbackFieldValidation = &cr.StructFieldValidation{
+	StructField: "Feedback",
+	StructValidation: &cr.StructValidation{
+		DefaultNil: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "Destination",
+				StringValidation: &cr.StringValidation{
+					Required:  true,
+					Validator: validateFeedbackDestination,
+				},
+			},
+			{
+				StructField: "Metrics",
+				StringListValidation: &cr.StringListValidation{
+					AllowEmpty:   true,
+					DisallowDups: true,
+					Validator:    validateFeedbackMetrics,
+				},
+			},
+			{
+				StructField: "Window",
+				DurationValidation: &cr.DurationValidation{
+					Default:              time.Hour,
+					GreaterThanOrEqualTo: pointer.Duration(5 * time.Minute),
+					LessThanOrEqualTo:    pointer.Duration(_week),
+				},
+			},
+		},
+	},
+}
+
+func validateFeedbackDestination(destination string) (string, error) {
+	if !aws.IsValidS3Path(destination) && !aws.IsFirehoseStreamARN(destination) {
+		return "", ErrorInvalidFeedbackDestination(destination)
+	}
+	return destination, nil
+}
+
+func validateFeedbackMetrics(metrics []string) ([]string, error) {
+	allMetrics := append(_feedbackMetrics[ClassificationModelType], _feedbackMetrics[RegressionModelType]...)
+	for _, metric := range metrics {
+		if !slices.HasString(allMetrics, metric) {
+			return nil, ErrorInvalidFeedbackMetric(metric, allMetrics)
+		}
+	}
+	return metrics, nil
+}
+
+// FeedbackMetrics returns the metrics which apply to the model type
+func FeedbackMetrics(modelType ModelType) []string {
+	return _feedbackMetrics[modelType]
+}
+
+// IsFirehose returns whether the feedback is written to a Kinesis Data Firehose delivery stream (rather than to S3)
+func (feedback *Feedback) IsFirehose() bool {
+	return aws.IsFirehoseStreamARN(feedback.Destination)
+}
+
+// Validate checks that the metrics apply to the tracker's model type (defaulting them if they aren't set),
+// and that the cluster's AWS credentials (which the API's pods use) can write to the destination
+func (feedback *Feedback) Validate(tracker *Tracker, networking *Networking, cache *s3Cache) error {
+	if networking.Protocol == WebSocketProtocol {
+		return ErrorFeedbackNotSupportedByNetworking(fmt.Sprintf("the %s protocol", WebSocketProtocol.String()))
+	}
+
+	if tracker == nil {
+		if len(feedback.Metrics) > 0 {
+			return ErrorFeedbackMetricsWithoutTracker()
+		}
+	} else if len(feedback.Metrics) == 0 {
+		feedback.Metrics = FeedbackMetrics(tracker.ModelType)
+	} else {
+		for _, metric := range feedback.Metrics {
+			if !slices.HasString(FeedbackMetrics(tracker.ModelType), metric) {
+				return ErrorFeedbackMetricNotApplicable(metric, tracker.ModelType, FeedbackMetrics(tracker.ModelType))
+			}
+		}
+	}
+
+	if feedback.IsFirehose() {
+		return cache.check("firehose:"+feedback.Destination, func() error {
+			return aws.VerifyFirehoseStreamWritable(feedback.Destination)
+		})
+	}
+	return validateS3DestinationWritable(feedback.Destination, cache)
+}
+
+func (feedback *Feedback) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", DestinationKey, feedback.Destination))
+	if len(feedback.Metrics) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MetricsKey, s.ObjFlatNoQuotes(feedback.Metrics)))
+		sb.WriteString(fmt.Sprintf("%s: %s\n", WindowKey, feedback.Window.String()))
+	}
+	return sb.String()
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+type Files []*File
+
+// File is a file from the project which is mounted into the API's containers
+type File struct {
+	Path      string `json:"path" yaml:"path"`
+	MountPath string `json:"mount_path" yaml:"mount_path"`
+}
+
+var filesFieldValidation = &cr.StructFieldValidation{
+	StructField: "Files",
+	StructListValidation: &cr.StructListValidation{
+		AllowExplicitNull: true,
+		StructValidation: &cr.StructValidation{
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Path",
+					StringValidation: &cr.StringValidation{
+						Required: true,
+					},
+				},
+				{
+					StructField: "MountPath",
+					StringValidation: &cr.StringValidation{
+						Required:  true,
+						Validator: validateMountPath,
+					},
+				},
+			},
+		},
+	},
+}
+
+func (files Files) Validate(projectFileMap map[string][]byte, volumes Volumes) error {
+	mountPaths := strset.New()
+	for _, volume := range volumes {
+		mountPaths.Add(volume.MountPath)
+	}
+
+	for i, file := range files {
+		if _, ok := projectFileMap[file.Path]; !ok {
+			return errors.Wrap(ErrorProjectFileNotFound(file.Path), s.Index(i), PathKey)
+		}
+		if mountPaths.Has(file.MountPath) {
+			return errors.Wrap(ErrorDuplicateMountPath(file.MountPath), s.Index(i), MountPathKey)
+		}
+		mountPaths.Add(file.MountPath)
+	}
+	return nil
+}
+
+func (file *File) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", PathKey, file.Path))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MountPathKey, file.MountPath))
+	return sb.String()
+}
+
+func (files Files) UserConfigStr() string {
+	var sb strings.Builder
+	for _, file := range files {
+		fileStr := s.Indent(file.UserConfigStr(), "  ")
+		sb.WriteString("- " + strings.TrimPrefix(fileStr, "  "))
+	}
+	return sb.String()
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// metadata names are keys of the metadata dictionary which is passed to predict(), so they must be valid python identifiers
+var _metadataNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+type ForwardHeaders []*ForwardHeader
+
+// ForwardHeader is a request header which is validated and passed to the predictor in its metadata
+type ForwardHeader struct {
+	Header   string  `json:"header" yaml:"header"`
+	Name     string  `json:"name" yaml:"name"` // the header's key in the metadata
+	Required bool    `json:"required" yaml:"required"`
+	Pattern  *string `json:"pattern" yaml:"pattern"` // a regular expression which the whole value must match
+}
+
+var forwardHeadersFieldValidation = &cr.StructFieldValidation{
+	StructField: "ForwardHeaders",
+	StructListValidation: &cr.StructListValidation{
+		AllowExplicitNull: true,
+		StructValidation: &cr.StructValidation{
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Header",
+					StringValidation: &cr.StringValidation{
+						Required:  true,
+						Validator: validateHeaderName,
+					},
+				},
+				{
+					StructField: "Name",
+					StringValidation: &cr.StringValidation{
+						Default:    "",
+						AllowEmpty: true,
+						Validator:  validateMetadataName,
+					},
+				},
+				{
+					StructField:    "Required",
+					BoolValidation: &cr.BoolValidation{},
+				},
+				{
+					StructField: "Pattern",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Validator: validateHeaderPattern,
+					},
+				},
+			},
+		},
+	},
+}
+
+func validateMetadataName(name string) (string, error) {
+	if name != "" && !_metadataNameRegex.MatchString(name) {
+		return "", ErrorInvalidMetadataName(name)
+	}
+	return name, nil
+}
+
+func validateHeaderPattern(pattern string) (string, error) {
+	if _, err := regexp.Compile(pattern); err != nil {
+		return "", ErrorInvalidHeaderPattern(pattern, err)
+	}
+	return pattern, nil
+}
+
+// defaultMetadataName derives the metadata name from the header's (lowercase) name, e.g. x-user-id -> user_id
+func defaultMetadataName(header string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(header, "x-"), "-", "_")
+}
+
+// Validate sets the headers' default metadata names, and checks that the headers and names are unique
+func (forwardHeaders ForwardHeaders) Validate() error {
+	headers := strset.New()
+	names := strset.New()
+	for i, forwardHeader := range forwardHeaders {
+		if headers.Has(forwardHeader.Header) {
+			return errors.Wrap(ErrorDuplicateForwardHeader(forwardHeader.Header), s.Index(i), HeaderKey)
+		}
+		headers.Add(forwardHeader.Header)
+
+		if forwardHeader.Name == "" {
+			forwardHeader.Name = defaultMetadataName(forwardHeader.Header)
+			if _, err := validateMetadataName(forwardHeader.Name); err != nil {
+				return errors.Wrap(err, s.Index(i), NameKey)
+			}
+		}
+		if names.Has(forwardHeader.Name) {
+			return errors.Wrap(ErrorDuplicateMetadataName(forwardHeader.Name), s.Index(i), NameKey)
+		}
+		names.Add(forwardHeader.Name)
+	}
+	return nil
+}
+
+func (forwardHeader *ForwardHeader) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", HeaderKey, forwardHeader.Header))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", NameKey, forwardHeader.Name))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", RequiredKey, s.Bool(forwardHeader.Required)))
+	if forwardHeader.Pattern != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", PatternKey, *forwardHeader.Pattern))
+	}
+	return sb.String()
+}
+
+func (forwardHeaders ForwardHeaders) UserConfigStr() string {
+	var sb strings.Builder
+	for _, forwardHeader := range forwardHeaders {
+		forwardHeaderStr := s.Indent(forwardHeader.UserConfigStr(), "  ")
+		sb.WriteString("- " + strings.TrimPrefix(forwardHeaderStr, "  "))
+	}
+	return sb.String()
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type IdleAction int
+
+const (
+	UnknownIdleAction IdleAction = iota
+	NoneIdleAction
+	ScaleToMinIdleAction
+	PauseIdleAction
+)
+
+var idleActions = []string{
+	"unknown",
+	"none",
+	"scale_to_min",
+	"pause",
+}
+
+func IdleActionFromString(s string) IdleAction {
+	for i := 0; i < len(idleActions); i++ {
+		if s == idleActions[i] {
+			return IdleAction(i)
+		}
+	}
+	return UnknownIdleAction
+}
+
+func IdleActionStrings() []string {
+	return idleActions[1:]
+}
+
+func (t IdleAction) String() string {
+	return idleActions[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t IdleAction) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *IdleAction) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(idleActions); i++ {
+		if enum == idleActions[i] {
+			*t = IdleAction(i)
+			return nil
+		}
+	}
+
+	*t = UnknownIdleAction
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *IdleAction) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t IdleAction) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
+)
+
+// JSONSchema describes the contents of cortex.yaml (and of the files in cortex.d), for use by editors and CI
+func JSONSchema() map[string]interface{} {
+	appSchema := resourceJSONSchema(resource.AppType, cr.StructJSONSchema((*App)(nil), appValidation))
+	apiSchema := resourceJSONSchema(resource.APIType, cr.StructJSONSchema((*API)(nil), apiValidation))
+
+	// a pipeline's keys are those of an API without a predictor, along with the pipeline's own keys
+	pipelineSchema := resourceJSONSchema(resource.PipelineType, cr.StructJSONSchema((*API)(nil), pipelineAPIValidation))
+	pipelineFieldsSchema := cr.StructJSONSchema((*Pipeline)(nil), pipelineValidation)
+	for key, propertySchema := range pipelineFieldsSchema["properties"].(map[string]interface{}) {
+		pipelineSchema["properties"].(map[string]interface{})[key] = propertySchema
+	}
+	if required, ok := pipelineFieldsSchema["required"].([]string); ok {
+		pipelineSchema["required"] = append(pipelineSchema["required"].([]string), required...)
+	}
+
+	defaultsSchema := resourceJSONSchema(resource.DefaultsType, cr.StructJSONSchema((*API)(nil), apiValidation))
+	delete(defaultsSchema["properties"].(map[string]interface{}), NameKey)
+	defaultsSchema["required"] = []string{KindKey}
+
+	return map[string]interface{}{
+		"$schema":     cr.JSONSchemaDraft,
+		"title":       "cortex configuration",
+		"description": "a list of cortex resources",
+		"type":        "array",
+		"items": map[string]interface{}{
+			"oneOf": []interface{}{appSchema, apiSchema, pipelineSchema, defaultsSchema},
+		},
+	}
+}
+
+func resourceJSONSchema(resourceType resource.Type, schema map[string]interface{}) map[string]interface{} {
+	schema["properties"].(map[string]interface{})[KindKey] = map[string]interface{}{
+		"const": resourceType.String(),
+	}
+	required, _ := schema["required"].([]string)
+	if !slices.HasString(required, KindKey) {
+		schema["required"] = append([]string{KindKey}, required...)
+	}
+	return schema
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// LoadShedding configures the rejection of requests which arrive while each of the API's processes is busy and has a full queue;
+// it's applied by the API's server, so that overloaded replicas respond right away rather than letting every request time out
+type LoadShedding struct {
+	MaxQueueDepth  int32   `json:"max_queue_depth" yaml:"max_queue_depth"`
+	StatusCode     int32   `json:"status_code" yaml:"status_code"`
+	PriorityHeader *string `json:"priority_header" yaml:"priority_header"`
+}
+
+var loadSheddingFieldValidation = &cr.StructFieldValidation{
+	StructField: "LoadShedding",
+	AllowedIf:   &cr.FieldCondition{Key: ProtocolKey, Values: []interface{}{WebSocketProtocol}, Not: true},
+	StructValidation: &cr.StructValidation{
+		DefaultNil: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "MaxQueueDepth",
+				Int32Validation: &cr.Int32Validation{
+					Required:             true,
+					GreaterThanOrEqualTo: pointer.Int32(0),
+				},
+			},
+			{
+				StructField: "StatusCode",
+				Int32Validation: &cr.Int32Validation{
+					Default:       429,
+					AllowedValues: []int32{429, 503},
+				},
+			},
+			{
+				StructField: "PriorityHeader",
+				StringPtrValidation: &cr.StringPtrValidation{
+					Validator: validateHeaderName,
+				},
+			},
+		},
+	},
+}
+
+// loadSheddingConcurrency is the number of requests which each of the API's processes handles at once (the rest are queued);
+// batching APIs handle a full batch at once, and pipeline APIs are served with waitress's default number of threads
+func loadSheddingConcurrency(predictor *Predictor) int32 {
+	if predictor.Type == PipelinePredictorType {
+		return DefaultThreadsPerProcess
+	}
+	if predictor.Batching != nil && predictor.Batching.MaxBatchSize > predictor.ThreadsPerProcess {
+		return predictor.Batching.MaxBatchSize
+	}
+	return predictor.ThreadsPerProcess
+}
+
+// Validate checks that the queue fits in the process's threads (queued requests each hold one of the server's threads while they wait),
+// and that shed requests aren't retried or counted as failures by the load balancer, which would add to the API's load
+func (loadShedding *LoadShedding) Validate(predictor *Predictor, networking *Networking) error {
+	concurrency := loadSheddingConcurrency(predictor)
+	if concurrency+loadShedding.MaxQueueDepth > MaxThreadsPerProcess {
+		return errors.Wrap(ErrorLoadSheddingQueueTooDeep(loadShedding.MaxQueueDepth, concurrency, MaxThreadsPerProcess), MaxQueueDepthKey)
+	}
+
+	if loadShedding.StatusCode >= 500 {
+		if networking.Retries != nil && slices.HasInt32(networking.Retries.StatusCodes, loadShedding.StatusCode) {
+			return errors.Wrap(ErrorShedStatusCodeRetried(loadShedding.StatusCode), StatusCodeKey)
+		}
+		if networking.CircuitBreaker != nil && networking.CircuitBreaker.Consecutive5xxErrors != nil {
+			return errors.Wrap(ErrorShedStatusCodeEjectsReplicas(loadShedding.StatusCode), StatusCodeKey)
+		}
+	}
+
+	return nil
+}
+
+func (loadShedding *LoadShedding) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxQueueDepthKey, s.Int32(loadShedding.MaxQueueDepth)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", StatusCodeKey, s.Int32(loadShedding.StatusCode)))
+	if loadShedding.PriorityHeader != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", PriorityHeaderKey, *loadShedding.PriorityHeader))
+	}
+	return sb.String()
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// Formats of the lines which an API's containers log
+const (
+	TextLogFormat = "text" // each line is shipped as a string
+	JSONLogFormat = "json" // each line is parsed as a JSON object (lines which aren't JSON are shipped as strings)
+)
+
+var LogFormats = []string{TextLogFormat, JSONLogFormat}
+
+// Logs configures the API's CloudWatch log group, which is created (and deleted along with the API) by the operator
+type Logs struct {
+	RetentionDays *int64 `json:"retention_days" yaml:"retention_days"` // nil means that the logs never expire
+	Format        string `json:"format" yaml:"format"`
+}
+
+var logsFieldValidation = &cr.StructFieldValidation{
+	StructField: "Logs",
+	StructValidation: &cr.StructValidation{
+		DefaultNil: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "RetentionDays",
+				Int64PtrValidation: &cr.Int64PtrValidation{
+					AllowExplicitNull: true,
+					AllowedValues:     aws.LogGroupRetentionDays,
+				},
+			},
+			{
+				StructField: "Format",
+				StringValidation: &cr.StringValidation{
+					Default:       TextLogFormat,
+					AllowedValues: LogFormats,
+				},
+			},
+		},
+	},
+}
+
+// LogFormat returns the format of the API's logs (if logs isn't configured, they're shipped as text)
+func (api *API) LogFormat() string {
+	if api.Logs == nil {
+		return TextLogFormat
+	}
+	return api.Logs.Format
+}
+
+// LogRetentionDays returns the number of days for which the API's logs are kept (nil if they never expire)
+func (api *API) LogRetentionDays() *int64 {
+	if api.Logs == nil {
+		return nil
+	}
+	return api.Logs.RetentionDays
+}
+
+func (logs *Logs) UserConfigStr() string {
+	var sb strings.Builder
+	if logs.RetentionDays != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", RetentionDaysKey, s.Int64(*logs.RetentionDays)))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", FormatKey, logs.Format))
+	return sb.String()
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/mlflow"
+)
+
+// The MLflow model flavor which each predictor type loads from the registry (the python predictor type doesn't have a model)
+var MLflowFlavors = map[PredictorType]string{
+	TensorFlowPredictorType: "tensorflow",
+	ONNXPredictorType:       "onnx",
+	SKLearnPredictorType:    "sklearn",
+	XGBoostPredictorType:    "xgboost",
+	LightGBMPredictorType:   "lightgbm",
+}
+
+func validateModel(model string) (string, error) {
+	if mlflow.IsModelURI(model) {
+		uri, err := mlflow.ParseModelURI(model)
+		if err != nil {
+			return "", err
+		}
+		return uri.String(), nil
+	}
+	if !aws.IsValidS3Path(model) {
+		return "", aws.ErrorInvalidS3Path(model)
+	}
+	return model, nil
+}
+
+// IsMLflowModel returns whether the predictor's model is a model in the MLflow registry which hasn't been resolved yet
+func (predictor *Predictor) IsMLflowModel() bool {
+	return predictor.Model != nil && mlflow.IsModelURI(*predictor.Model)
+}
+
+// ResolveMLflowModels replaces each mlflow:// model with the S3 path of the registered version's files for the predictor type's flavor
+// (the original URI and the version are kept in the predictor); client is nil if the cluster isn't configured with an MLflow registry
+func (apis APIs) ResolveMLflowModels(client *mlflow.Client) error {
+	cache := newS3Cache()
+	versions := map[string]*mlflow.ModelVersion{} // model URI -> version (so that each URI is only looked up once per deploy)
+
+	for _, api := range apis {
+		if !api.Predictor.IsMLflowModel() {
+			continue
+		}
+		if client == nil {
+			return errors.Wrap(ErrorMLflowNotConfigured(), Identify(api), PredictorKey, ModelKey)
+		}
+
+		uriStr := *api.Predictor.Model
+		version, ok := versions[uriStr]
+		if !ok {
+			uri, err := mlflow.ParseModelURI(uriStr)
+			if err != nil {
+				return errors.Wrap(err, Identify(api), PredictorKey, ModelKey)
+			}
+			version, err = client.ModelVersion(uri)
+			if err != nil {
+				return errors.Wrap(err, Identify(api), PredictorKey, ModelKey)
+			}
+			versions[uriStr] = version
+		}
+
+		path, err := mlflowModelPath(version, api.Predictor.Type, cache)
+		if err != nil {
+			return errors.Wrap(err, Identify(api), PredictorKey, ModelKey)
+		}
+
+		api.Predictor.MLflowModel = &uriStr
+		api.Predictor.MLflowModelVersion = version.Version
+		api.Predictor.Model = &path
+	}
+
+	return nil
+}
+
+// mlflowModelPath returns the S3 path of the model version's data for the predictor type's flavor
+func mlflowModelPath(version *mlflow.ModelVersion, predictorType PredictorType, cache *s3Cache) (string, error) {
+	source := strings.TrimSuffix(version.Source, "/")
+	if !aws.IsValidS3Path(source) {
+		return "", ErrorUnsupportedMLflowModelSource(version.Name, version.Version, version.Source)
+	}
+
+	mlmodelPath := aws.S3PathJoin(source, mlflow.MLmodelFileName)
+	awsClient, err := cache.client(mlmodelPath)
+	if err != nil {
+		return "", err
+	}
+	mlmodelBytes, err := awsClient.ReadBytesFromS3Path(mlmodelPath)
+	if err != nil {
+		return "", errors.Wrap(err, mlmodelPath)
+	}
+	mlmodel, err := mlflow.ParseMLmodel(mlmodelPath, mlmodelBytes)
+	if err != nil {
+		return "", err
+	}
+
+	flavor := MLflowFlavors[predictorType]
+	dataPath, ok := mlmodel.FlavorDataPath(flavor)
+	if !ok {
+		return "", ErrorIncompatibleMLflowFlavor(version.Name, version.Version, predictorType, mlmodel.FlavorNames())
+	}
+	if dataPath == "" {
+		return source, nil
+	}
+	return aws.S3PathJoin(source, dataPath), nil
+}
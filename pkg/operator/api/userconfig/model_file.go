@@ -0,0 +1,113 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/python"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+)
+
+// The file extensions of the models which can be loaded by each of the predictor types which serve a model file with its framework
+var modelFileExtensions = map[PredictorType][]string{
+	SKLearnPredictorType:  {".joblib", ".pkl", ".pickle"},
+	XGBoostPredictorType:  {".model", ".bst", ".json", ".xgb"},
+	LightGBMPredictorType: {".txt", ".model", ".lgb"},
+}
+
+// The number of bytes at the start of a model file which are read to check that it can be loaded
+const _modelFileHeaderLen = 16
+
+// IsModelFilePredictorType returns whether the predictor type serves a model file with its framework (i.e. sklearn, xgboost, or lightgbm)
+func IsModelFilePredictorType(predictorType PredictorType) bool {
+	_, ok := modelFileExtensions[predictorType]
+	return ok
+}
+
+// ModelFileValidate checks that the model is a file with one of the predictor type's extensions, and that it starts with a header which the predictor type's framework can load
+func (predictor *Predictor) ModelFileValidate(cache *s3Cache) error {
+	model := *predictor.Model
+	extensions := modelFileExtensions[predictor.Type]
+
+	if !slices.HasString(extensions, strings.ToLower(filepath.Ext(model))) {
+		return errors.Wrap(ErrorInvalidModelFileExtension(model, predictor.Type, extensions), ModelKey)
+	}
+
+	_, err := cache.modelPath(predictor.Type.String()+":"+model, func() (string, error) {
+		awsClient, err := cache.client(model)
+		if err != nil {
+			return "", err
+		}
+		if ok, err := awsClient.IsS3PathFile(model); err != nil || !ok {
+			return "", errors.Wrap(ErrorExternalNotFound(model), ModelKey)
+		}
+		header, err := awsClient.ReadBytesRangeFromS3Path(model, 0, _modelFileHeaderLen)
+		if err != nil {
+			return "", errors.Wrap(err, ModelKey)
+		}
+		if err := probeModelFile(predictor.Type, model, header); err != nil {
+			return "", errors.Wrap(err, ModelKey)
+		}
+		return model, nil
+	}, func(path string) string {
+		return path
+	})
+	return err
+}
+
+// probeModelFile checks the header of the model file against the formats which the predictor type's framework loads
+func probeModelFile(predictorType PredictorType, path string, header []byte) error {
+	if len(header) == 0 {
+		return ErrorInvalidModelFile(path, predictorType, "the file is empty")
+	}
+
+	switch predictorType {
+	case SKLearnPredictorType:
+		if !python.IsPickle(header) && !python.IsJoblibCompressed(header) {
+			return ErrorInvalidModelFile(path, predictorType, "the file isn't a pickle or a compressed joblib file (save the model with joblib.dump() or pickle.dump())")
+		}
+
+	case XGBoostPredictorType:
+		isJSON := bytes.HasPrefix(bytes.TrimLeft(header, " \t\r\n"), []byte("{"))
+		if strings.ToLower(filepath.Ext(path)) == ".json" {
+			if !isJSON {
+				return ErrorInvalidModelFile(path, predictorType, "the file isn't a JSON model, which is expected for the .json extension")
+			}
+			return nil
+		}
+		if isJSON {
+			return ErrorInvalidModelFile(path, predictorType, "JSON models must have the .json extension")
+		}
+		if python.IsPickle(header) || python.IsJoblibCompressed(header) {
+			return ErrorInvalidModelFile(path, predictorType, "the file is a pickled model (save the model with Booster.save_model(), or use the sklearn predictor type)")
+		}
+
+	case LightGBMPredictorType:
+		if python.IsPickle(header) || python.IsJoblibCompressed(header) {
+			return ErrorInvalidModelFile(path, predictorType, "the file is a pickled model (save the model with Booster.save_model(), or use the sklearn predictor type)")
+		}
+		if !bytes.HasPrefix(header, []byte("tree")) {
+			return ErrorInvalidModelFile(path, predictorType, "the file isn't a LightGBM text model (save the model with Booster.save_model())")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"sync"
+	"time"
+)
+
+// How long a successful model validation is trusted without making any requests to S3
+const _modelValidationCacheTTL = 10 * time.Minute
+
+// _modelValidations holds successful model validations across deploys (failed validations are not cached, so that a fixed model is picked up on the next deploy)
+var _modelValidations = newModelValidationCache()
+
+type modelValidationCache struct {
+	sync.Mutex
+	entries map[string]modelValidationEntry // predictor type and model -> entry
+}
+
+type modelValidationEntry struct {
+	path        string    // the validated model path (e.g. the TensorFlow export directory)
+	etagPath    string    // the S3 file whose ETag identifies this version of the model
+	etag        string    // the ETag of etagPath when the model was validated
+	validatedAt time.Time // when the model was validated (or its ETag was last confirmed)
+}
+
+func newModelValidationCache() *modelValidationCache {
+	return &modelValidationCache{
+		entries: make(map[string]modelValidationEntry),
+	}
+}
+
+func (cache *modelValidationCache) get(key string) (modelValidationEntry, bool) {
+	cache.Lock()
+	defer cache.Unlock()
+	entry, ok := cache.entries[key]
+	return entry, ok
+}
+
+func (cache *modelValidationCache) set(key string, entry modelValidationEntry) {
+	cache.Lock()
+	defer cache.Unlock()
+	cache.entries[key] = entry
+}
+
+func (cache *modelValidationCache) delete(key string) {
+	cache.Lock()
+	defer cache.Unlock()
+	delete(cache.entries, key)
+}
+
+// validatedModelPath returns the model path from a previous validation if it can be reused:
+// within the TTL the entry is used as is, and after the TTL it is used if a HEAD request shows that the model's ETag hasn't changed
+func (cache *s3Cache) validatedModelPath(key string) (string, bool) {
+	entry, ok := _modelValidations.get(key)
+	if !ok {
+		return "", false
+	}
+
+	if time.Since(entry.validatedAt) < _modelValidationCacheTTL {
+		return entry.path, true
+	}
+
+	if etag, err := cache.etag(entry.etagPath); err != nil || etag == "" || etag != entry.etag {
+		_modelValidations.delete(key)
+		return "", false
+	}
+
+	entry.validatedAt = time.Now()
+	_modelValidations.set(key, entry)
+	return entry.path, true
+}
+
+// saveModelValidation records a successful model validation (if the ETag can't be retrieved, the validation is not cached)
+func (cache *s3Cache) saveModelValidation(key string, path string, etagPath string) {
+	etag, err := cache.etag(etagPath)
+	if err != nil || etag == "" {
+		return
+	}
+
+	_modelValidations.set(key, modelValidationEntry{
+		path:        path,
+		etagPath:    etagPath,
+		etag:        etag,
+		validatedAt: time.Now(),
+	})
+}
+
+func (cache *s3Cache) etag(s3Path string) (string, error) {
+	awsClient, err := cache.client(s3Path)
+	if err != nil {
+		return "", err
+	}
+	return awsClient.GetS3PathETag(s3Path)
+}
@@ -0,0 +1,440 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+var headerNameRegex = regexp.MustCompile(`^[a-zA-Z0-9!#$%&'*+.^_|~-]+$`)
+
+var CORSMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// Request bodies which aren't streamed are read into memory by the API server
+const MaxBufferedRequestSize = 100 * 1024 * 1024
+
+// Websocket connections which haven't sent or received a message within this duration are closed (unless idle_timeout is set)
+const DefaultWebSocketIdleTimeout = time.Hour
+
+type Networking struct {
+	CORS           *CORS           `json:"cors" yaml:"cors"`
+	Compression    *Compression    `json:"compression" yaml:"compression"`
+	MaxRequestSize int64           `json:"max_request_size" yaml:"max_request_size"`
+	StreamRequests bool            `json:"stream_requests" yaml:"stream_requests"`
+	Protocol       Protocol        `json:"protocol" yaml:"protocol"`
+	IdleTimeout    time.Duration   `json:"idle_timeout" yaml:"idle_timeout"`
+	RateLimit      *RateLimit      `json:"rate_limit" yaml:"rate_limit"`
+	Visibility     Visibility      `json:"visibility" yaml:"visibility"`
+	IPAllowlist    []string        `json:"ip_allowlist" yaml:"ip_allowlist"`
+	Expose         Expose          `json:"expose" yaml:"expose"`
+	Auth           *Auth           `json:"auth" yaml:"auth"`
+	ForwardHeaders ForwardHeaders  `json:"forward_headers" yaml:"forward_headers"`
+	CircuitBreaker *CircuitBreaker `json:"circuit_breaker" yaml:"circuit_breaker"`
+	Timeout        time.Duration   `json:"timeout" yaml:"timeout"` // requests don't time out if 0
+	Retries        *Retries        `json:"retries" yaml:"retries"`
+	LoadShedding   *LoadShedding   `json:"load_shedding" yaml:"load_shedding"`
+}
+
+type CORS struct {
+	AllowedOrigins []string      `json:"allowed_origins" yaml:"allowed_origins"`
+	AllowedMethods []string      `json:"allowed_methods" yaml:"allowed_methods"`
+	AllowedHeaders []string      `json:"allowed_headers" yaml:"allowed_headers"`
+	MaxAge         time.Duration `json:"max_age" yaml:"max_age"`
+}
+
+type RateLimit struct {
+	RequestsPerSecond float64 `json:"requests_per_second" yaml:"requests_per_second"`
+	Burst             *int32  `json:"burst" yaml:"burst"`
+	ClientHeader      *string `json:"client_header" yaml:"client_header"`
+}
+
+type Compression struct {
+	Gzip    bool  `json:"gzip" yaml:"gzip"`
+	Brotli  bool  `json:"brotli" yaml:"brotli"`
+	MinSize int64 `json:"min_size" yaml:"min_size"`
+}
+
+var networkingFieldValidation = &cr.StructFieldValidation{
+	StructField: "Networking",
+	StructValidation: &cr.StructValidation{
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "CORS",
+				StructValidation: &cr.StructValidation{
+					DefaultNil: true,
+					StructFieldValidations: []*cr.StructFieldValidation{
+						{
+							StructField: "AllowedOrigins",
+							StringListValidation: &cr.StringListValidation{
+								Required:     true,
+								DisallowDups: true,
+								Validator:    validateCORSOrigins,
+							},
+						},
+						{
+							StructField: "AllowedMethods",
+							StringListValidation: &cr.StringListValidation{
+								Default:      []string{"GET", "POST"},
+								DisallowDups: true,
+								Validator:    validateCORSMethods,
+							},
+						},
+						{
+							StructField: "AllowedHeaders",
+							StringListValidation: &cr.StringListValidation{
+								Default:      []string{"*"},
+								DisallowDups: true,
+							},
+						},
+						{
+							StructField: "MaxAge",
+							DurationValidation: &cr.DurationValidation{
+								GreaterThanOrEqualTo: pointer.Duration(0),
+							},
+						},
+					},
+				},
+			},
+			{
+				StructField: "Compression",
+				AllowedIf:   &cr.FieldCondition{Key: ProtocolKey, Values: []interface{}{WebSocketProtocol}, Not: true},
+				StructValidation: &cr.StructValidation{
+					StructFieldValidations: []*cr.StructFieldValidation{
+						{
+							StructField:    "Gzip",
+							BoolValidation: &cr.BoolValidation{},
+						},
+						{
+							StructField:    "Brotli",
+							BoolValidation: &cr.BoolValidation{},
+						},
+						{
+							StructField: "MinSize",
+							ByteQuantityValidation: &cr.ByteQuantityValidation{
+								Default:              1024,
+								GreaterThanOrEqualTo: pointer.Int64(0),
+							},
+						},
+					},
+				},
+			},
+			{
+				StructField: "MaxRequestSize",
+				ByteQuantityValidation: &cr.ByteQuantityValidation{
+					Default:     MaxBufferedRequestSize,
+					GreaterThan: pointer.Int64(0),
+				},
+			},
+			{
+				StructField:    "StreamRequests",
+				AllowedIf:      &cr.FieldCondition{Key: ProtocolKey, Values: []interface{}{WebSocketProtocol}, Not: true},
+				BoolValidation: &cr.BoolValidation{},
+			},
+			{
+				StructField: "Protocol",
+				StringValidation: &cr.StringValidation{
+					AllowedValues: ProtocolStrings(),
+					Default:       HTTPProtocol.String(),
+				},
+				Parser: func(str string) (interface{}, error) {
+					return ProtocolFromString(str), nil
+				},
+			},
+			{
+				StructField: "IdleTimeout",
+				AllowedIf:   &cr.FieldCondition{Key: ProtocolKey, Values: []interface{}{WebSocketProtocol}},
+				DurationValidation: &cr.DurationValidation{
+					Default:     DefaultWebSocketIdleTimeout,
+					GreaterThan: pointer.Duration(0),
+				},
+			},
+			{
+				StructField: "RateLimit",
+				AllowedIf:   &cr.FieldCondition{Key: ProtocolKey, Values: []interface{}{WebSocketProtocol}, Not: true},
+				StructValidation: &cr.StructValidation{
+					DefaultNil: true,
+					StructFieldValidations: []*cr.StructFieldValidation{
+						{
+							StructField: "RequestsPerSecond",
+							Float64Validation: &cr.Float64Validation{
+								Required:    true,
+								GreaterThan: pointer.Float64(0),
+							},
+						},
+						{
+							StructField: "Burst",
+							Int32PtrValidation: &cr.Int32PtrValidation{
+								GreaterThan: pointer.Int32(0),
+							},
+						},
+						{
+							StructField: "ClientHeader",
+							StringPtrValidation: &cr.StringPtrValidation{
+								Validator: validateHeaderName,
+							},
+						},
+					},
+				},
+			},
+			{
+				StructField: "Visibility",
+				StringValidation: &cr.StringValidation{
+					AllowedValues: VisibilityStrings(),
+					Default:       PublicVisibility.String(),
+				},
+				Parser: func(str string) (interface{}, error) {
+					return VisibilityFromString(str), nil
+				},
+			},
+			{
+				StructField: "IPAllowlist",
+				StringListValidation: &cr.StringListValidation{
+					AllowEmpty:   true,
+					DisallowDups: true,
+					Validator:    validateIPAllowlist,
+				},
+			},
+			{
+				StructField: "Expose",
+				StringValidation: &cr.StringValidation{
+					AllowedValues: ExposeStrings(),
+					Default:       LoadBalancerExpose.String(),
+				},
+				Parser: func(str string) (interface{}, error) {
+					return ExposeFromString(str), nil
+				},
+			},
+			authFieldValidation,
+			forwardHeadersFieldValidation,
+			circuitBreakerFieldValidation,
+			{
+				StructField: "Timeout",
+				AllowedIf:   &cr.FieldCondition{Key: ProtocolKey, Values: []interface{}{WebSocketProtocol}, Not: true},
+				DurationValidation: &cr.DurationValidation{
+					GreaterThanOrEqualTo: pointer.Duration(0),
+				},
+			},
+			retriesFieldValidation,
+			loadSheddingFieldValidation,
+		},
+	},
+}
+
+func validateHeaderName(header string) (string, error) {
+	if !headerNameRegex.MatchString(header) {
+		return "", ErrorInvalidHeaderName(header)
+	}
+	return strings.ToLower(header), nil
+}
+
+// IP addresses are converted to single-address CIDR blocks
+func validateIPAllowlist(ipBlocks []string) ([]string, error) {
+	cidrs := make([]string, len(ipBlocks))
+	for i, ipBlock := range ipBlocks {
+		if ip := net.ParseIP(ipBlock); ip != nil {
+			if ip.To4() != nil {
+				cidrs[i] = ip.String() + "/32"
+			} else {
+				cidrs[i] = ip.String() + "/128"
+			}
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(ipBlock)
+		if err != nil {
+			return nil, ErrorInvalidIPBlock(ipBlock)
+		}
+		cidrs[i] = ipNet.String()
+	}
+	return cidrs, nil
+}
+
+func validateCORSOrigins(origins []string) ([]string, error) {
+	for _, origin := range origins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			return nil, ErrorInvalidCORSOrigin(origin)
+		}
+	}
+	return origins, nil
+}
+
+func validateCORSMethods(methods []string) ([]string, error) {
+	upperMethods := make([]string, len(methods))
+	for i, method := range methods {
+		upperMethods[i] = strings.ToUpper(method)
+		if !slices.HasString(CORSMethods, upperMethods[i]) {
+			return nil, cr.ErrorInvalidStr(method, CORSMethods...)
+		}
+	}
+	return upperMethods, nil
+}
+
+func (networking *Networking) Validate(predictorType PredictorType) error {
+	if networking.StreamRequests && predictorType != PythonPredictorType {
+		return errors.Wrap(ErrorStreamRequestsNotSupportedByPredictorType(predictorType), StreamRequestsKey)
+	}
+
+	if networking.Protocol == WebSocketProtocol && predictorType != PythonPredictorType {
+		return errors.Wrap(ErrorWebSocketNotSupportedByPredictorType(predictorType), ProtocolKey)
+	}
+
+	if !networking.StreamRequests && networking.MaxRequestSize > MaxBufferedRequestSize {
+		return errors.Wrap(ErrorMaxRequestSizeRequiresStreaming(networking.MaxRequestSize, MaxBufferedRequestSize), MaxRequestSizeKey)
+	}
+
+	// cluster-local APIs aren't routed through a load balancer, which is where these are applied
+	if networking.Expose == ClusterLocalExpose {
+		if networking.CORS != nil {
+			return errors.Wrap(ErrorNotSupportedByClusterLocal(CORSKey), CORSKey)
+		}
+		if networking.Visibility == PrivateVisibility {
+			return errors.Wrap(ErrorNotSupportedByClusterLocal(VisibilityKey+": "+PrivateVisibility.String()), VisibilityKey)
+		}
+		if len(networking.IPAllowlist) > 0 {
+			return errors.Wrap(ErrorNotSupportedByClusterLocal(IPAllowlistKey), IPAllowlistKey)
+		}
+		if networking.Timeout != 0 {
+			return errors.Wrap(ErrorNotSupportedByClusterLocal(TimeoutKey), TimeoutKey)
+		}
+		if networking.Retries != nil {
+			return errors.Wrap(ErrorNotSupportedByClusterLocal(RetriesKey), RetriesKey)
+		}
+	}
+
+	if err := networking.ForwardHeaders.Validate(); err != nil {
+		return errors.Wrap(err, ForwardHeadersKey)
+	}
+
+	if networking.CircuitBreaker != nil {
+		if err := networking.CircuitBreaker.Validate(); err != nil {
+			return errors.Wrap(err, CircuitBreakerKey)
+		}
+	}
+
+	if networking.Retries != nil {
+		if err := networking.Retries.Validate(networking.Timeout); err != nil {
+			return errors.Wrap(err, RetriesKey, PerTryTimeoutKey)
+		}
+	}
+
+	// by default, clients may send one second's worth of requests at once
+	if networking.RateLimit != nil && networking.RateLimit.Burst == nil {
+		networking.RateLimit.Burst = pointer.Int32(int32(math.Ceil(networking.RateLimit.RequestsPerSecond)))
+	}
+
+	return nil
+}
+
+func (networking *Networking) UserConfigStr() string {
+	var sb strings.Builder
+	if networking.CORS != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", CORSKey))
+		sb.WriteString(s.Indent(networking.CORS.UserConfigStr(), "  "))
+	}
+	if networking.Compression != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", CompressionKey))
+		sb.WriteString(s.Indent(networking.Compression.UserConfigStr(), "  "))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxRequestSizeKey, s.Int64(networking.MaxRequestSize)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", StreamRequestsKey, s.Bool(networking.StreamRequests)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ProtocolKey, networking.Protocol.String()))
+	if networking.Protocol == WebSocketProtocol {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", IdleTimeoutKey, networking.IdleTimeout.String()))
+	}
+	if networking.RateLimit != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", RateLimitKey))
+		sb.WriteString(s.Indent(networking.RateLimit.UserConfigStr(), "  "))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", VisibilityKey, networking.Visibility.String()))
+	if networking.Expose == ClusterLocalExpose {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ExposeKey, networking.Expose.String()))
+	}
+	if len(networking.IPAllowlist) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", IPAllowlistKey, s.ObjFlatNoQuotes(networking.IPAllowlist)))
+	}
+	if networking.Auth != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", AuthKey))
+		sb.WriteString(s.Indent(networking.Auth.UserConfigStr(), "  "))
+	}
+	if len(networking.ForwardHeaders) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", ForwardHeadersKey))
+		sb.WriteString(s.Indent(networking.ForwardHeaders.UserConfigStr(), "  "))
+	}
+	if networking.CircuitBreaker != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", CircuitBreakerKey))
+		sb.WriteString(s.Indent(networking.CircuitBreaker.UserConfigStr(), "  "))
+	}
+	if networking.Timeout != 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", TimeoutKey, networking.Timeout.String()))
+	}
+	if networking.Retries != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", RetriesKey))
+		sb.WriteString(s.Indent(networking.Retries.UserConfigStr(), "  "))
+	}
+	if networking.LoadShedding != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", LoadSheddingKey))
+		sb.WriteString(s.Indent(networking.LoadShedding.UserConfigStr(), "  "))
+	}
+	return sb.String()
+}
+
+func (rateLimit *RateLimit) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", RequestsPerSecondKey, s.Float64(rateLimit.RequestsPerSecond)))
+	if rateLimit.Burst != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", BurstKey, s.Int32(*rateLimit.Burst)))
+	}
+	if rateLimit.ClientHeader != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ClientHeaderKey, *rateLimit.ClientHeader))
+	}
+	return sb.String()
+}
+
+func (compression *Compression) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", GzipKey, s.Bool(compression.Gzip)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", BrotliKey, s.Bool(compression.Brotli)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MinSizeKey, s.Int64(compression.MinSize)))
+	return sb.String()
+}
+
+func (cors *CORS) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", AllowedOriginsKey, s.ObjFlatNoQuotes(cors.AllowedOrigins)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", AllowedMethodsKey, s.ObjFlatNoQuotes(cors.AllowedMethods)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", AllowedHeadersKey, s.ObjFlatNoQuotes(cors.AllowedHeaders)))
+	if cors.MaxAge != 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxAgeKey, cors.MaxAge.String()))
+	}
+	return sb.String()
+}
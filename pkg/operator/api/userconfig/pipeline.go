@@ -0,0 +1,337 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
+)
+
+// PipelinePayloadInput is the input which refers to the payload of the request to the pipeline
+const PipelinePayloadInput = "payload"
+
+// The keys of a pipeline resource which configure the pipeline (its other keys configure it as an API)
+var pipelineKeys = []string{ModeKey, StepsKey, OutputKey}
+
+type Pipeline struct {
+	Mode   PipelineMode    `json:"mode" yaml:"mode"`
+	Steps  []*PipelineStep `json:"steps" yaml:"steps"`
+	Output *string         `json:"output" yaml:"output"`
+}
+
+type PipelineStep struct {
+	Name   string   `json:"name" yaml:"name"`
+	API    string   `json:"api" yaml:"api"`
+	Inputs []string `json:"inputs" yaml:"inputs"`
+}
+
+var pipelineValidation = &cr.StructValidation{
+	StructFieldValidations: []*cr.StructFieldValidation{
+		{
+			StructField: "Mode",
+			StringValidation: &cr.StringValidation{
+				AllowedValues: PipelineModeStrings(),
+				Default:       ChainedPipelineMode.String(),
+			},
+			Parser: func(str string) (interface{}, error) {
+				return PipelineModeFromString(str), nil
+			},
+		},
+		{
+			StructField: "Steps",
+			StructListValidation: &cr.StructListValidation{
+				Required: true,
+				StructValidation: &cr.StructValidation{
+					StructFieldValidations: []*cr.StructFieldValidation{
+						{
+							StructField: "Name",
+							StringValidation: &cr.StringValidation{
+								Required: true,
+								DNS1035:  true,
+							},
+						},
+						{
+							StructField: "API",
+							StringValidation: &cr.StringValidation{
+								Required: true,
+							},
+						},
+						{
+							StructField: "Inputs",
+							StringListValidation: &cr.StringListValidation{
+								DisallowDups: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			StructField:         "Output",
+			StringPtrValidation: &cr.StringPtrValidation{},
+		},
+	},
+}
+
+// A pipeline is configured as an API, except that it doesn't have a predictor (or the containers and volumes which a predictor would use);
+// its responses aren't cached, since its steps' APIs can cache theirs
+var pipelineAPIValidation = &cr.StructValidation{
+	StructFieldValidations: pipelineAPIFieldValidations(),
+}
+
+func pipelineAPIFieldValidations() []*cr.StructFieldValidation {
+	excludedFields := strset.New("Predictor", "Init", "Sidecars", "Volumes", "Experiment", "Cache")
+	var fieldValidations []*cr.StructFieldValidation
+	for _, fieldValidation := range apiValidation.StructFieldValidations {
+		if !excludedFields.Has(fieldValidation.StructField) {
+			fieldValidations = append(fieldValidations, fieldValidation)
+		}
+	}
+	return fieldValidations
+}
+
+// newPipelineAPI parses a pipeline resource into an API which runs the pipeline
+func newPipelineAPI(data map[string]interface{}) (*API, []error, []error) {
+	pipelineData := map[string]interface{}{}
+	apiData := map[string]interface{}{}
+	for key, val := range data {
+		if slices.HasString(pipelineKeys, key) {
+			pipelineData[key] = val
+		} else {
+			apiData[key] = val
+		}
+	}
+
+	api := &API{}
+	errs, warnings := cr.StructWithWarnings(api, apiData, pipelineAPIValidation)
+	if errors.HasErrors(errs) {
+		return nil, errs, warnings
+	}
+
+	pipeline := &Pipeline{}
+	if errs := cr.Struct(pipeline, pipelineData, pipelineValidation); errors.HasErrors(errs) {
+		return nil, errs, warnings
+	}
+
+	api.Pipeline = pipeline
+	api.Predictor = &Predictor{
+		Type:   PipelinePredictorType,
+		Config: map[string]interface{}{},
+		Env:    map[string]string{},
+	}
+	return api, nil, warnings
+}
+
+// Predictor types which serve from a single container (so that their containers can be added to a pipeline's pods)
+var colocatedPredictorTypes = map[PredictorType]bool{
+	PythonPredictorType:   true,
+	ONNXPredictorType:     true,
+	SKLearnPredictorType:  true,
+	XGBoostPredictorType:  true,
+	LightGBMPredictorType: true,
+}
+
+// Validate checks that the steps' APIs are defined, and that their inputs form a DAG which leads to the pipeline's output
+// (steps which don't specify their inputs take the output of the previous step, or the payload if they're the first step);
+// arch is the pipeline's architecture, which colocated steps must match
+func (pipeline *Pipeline) Validate(apis APIs, arch Arch) error {
+	if len(pipeline.Steps) == 0 {
+		return errors.Wrap(cr.ErrorCannotBeEmpty(), StepsKey)
+	}
+
+	apisByName := make(map[string]*API, len(apis))
+	for _, api := range apis {
+		apisByName[api.Name] = api
+	}
+
+	stepNames := strset.New()
+	for i, step := range pipeline.Steps {
+		if step.Name == PipelinePayloadInput {
+			return errors.Wrap(ErrorReservedPipelineStepName(step.Name), StepsKey, s.Index(i), NameKey)
+		}
+		if stepNames.Has(step.Name) {
+			return errors.Wrap(ErrorDuplicatePipelineStepName(step.Name), StepsKey, s.Index(i), NameKey)
+		}
+		stepNames.Add(step.Name)
+
+		stepAPI, ok := apisByName[step.API]
+		if !ok {
+			return errors.Wrap(ErrorUndefinedResource(step.API, resource.APIType), StepsKey, s.Index(i), APIKey)
+		}
+		if stepAPI.Pipeline != nil {
+			return errors.Wrap(ErrorPipelineStepIsPipeline(step.API), StepsKey, s.Index(i), APIKey)
+		}
+		if stepAPI.Networking.Protocol == WebSocketProtocol {
+			return errors.Wrap(ErrorWebSocketPipelineStep(step.API), StepsKey, s.Index(i), APIKey)
+		}
+		if pipeline.Mode == ColocatedPipelineMode && !colocatedPredictorTypes[stepAPI.Predictor.Type] {
+			return errors.Wrap(ErrorPredictorTypeNotColocatable(step.API, stepAPI.Predictor.Type), StepsKey, s.Index(i), APIKey)
+		}
+		if pipeline.Mode == ColocatedPipelineMode && stepAPI.Cache != nil {
+			return errors.Wrap(ErrorCachedPipelineStepNotColocatable(step.API), StepsKey, s.Index(i), APIKey)
+		}
+		if pipeline.Mode == ColocatedPipelineMode && stepAPI.Compute.Arch != arch {
+			return errors.Wrap(ErrorPipelineStepArchMismatch(step.API, stepAPI.Compute.Arch, arch), StepsKey, s.Index(i), APIKey)
+		}
+
+		if step.Inputs == nil {
+			if i == 0 {
+				step.Inputs = []string{PipelinePayloadInput}
+			} else {
+				step.Inputs = []string{pipeline.Steps[i-1].Name}
+			}
+		}
+	}
+
+	consumed := strset.New()
+	for i, step := range pipeline.Steps {
+		if len(step.Inputs) == 0 {
+			return errors.Wrap(cr.ErrorCannotBeEmpty(), StepsKey, s.Index(i), InputsKey)
+		}
+		for _, input := range step.Inputs {
+			if input != PipelinePayloadInput && !stepNames.Has(input) {
+				return errors.Wrap(ErrorUndefinedPipelineInput(input), StepsKey, s.Index(i), InputsKey)
+			}
+		}
+		consumed.Add(step.Inputs...)
+	}
+
+	if _, err := pipeline.Stages(); err != nil {
+		return errors.Wrap(err, StepsKey)
+	}
+
+	if pipeline.Output == nil {
+		var finalSteps []string
+		for _, step := range pipeline.Steps {
+			if !consumed.Has(step.Name) {
+				finalSteps = append(finalSteps, step.Name)
+			}
+		}
+		if len(finalSteps) != 1 {
+			return ErrorAmbiguousPipelineOutput(finalSteps)
+		}
+		pipeline.Output = &finalSteps[0]
+	} else if !stepNames.Has(*pipeline.Output) {
+		return errors.Wrap(ErrorUndefinedPipelineStep(*pipeline.Output), OutputKey)
+	}
+
+	usedSteps := pipeline.dependencies(*pipeline.Output)
+	for i, step := range pipeline.Steps {
+		if !usedSteps.Has(step.Name) {
+			return errors.Wrap(ErrorUnusedPipelineStep(step.Name, *pipeline.Output), StepsKey, s.Index(i))
+		}
+	}
+
+	return nil
+}
+
+// Stages groups the steps in the order in which they run: each stage's steps only take the payload and the outputs of earlier stages as inputs
+func (pipeline *Pipeline) Stages() ([][]*PipelineStep, error) {
+	done := strset.New(PipelinePayloadInput)
+	remaining := pipeline.Steps
+
+	var stages [][]*PipelineStep
+	for len(remaining) > 0 {
+		var stage []*PipelineStep
+		var blocked []*PipelineStep
+		for _, step := range remaining {
+			if done.Has(step.Inputs...) {
+				stage = append(stage, step)
+			} else {
+				blocked = append(blocked, step)
+			}
+		}
+
+		if len(stage) == 0 {
+			blockedNames := make([]string, len(blocked))
+			for i, step := range blocked {
+				blockedNames[i] = step.Name
+			}
+			return nil, ErrorPipelineCycle(blockedNames)
+		}
+
+		for _, step := range stage {
+			done.Add(step.Name)
+		}
+		stages = append(stages, stage)
+		remaining = blocked
+	}
+
+	return stages, nil
+}
+
+// dependencies returns the step and the steps whose outputs it depends on
+func (pipeline *Pipeline) dependencies(stepName string) strset.Set {
+	steps := make(map[string]*PipelineStep, len(pipeline.Steps))
+	for _, step := range pipeline.Steps {
+		steps[step.Name] = step
+	}
+
+	dependencies := strset.New()
+	toVisit := []string{stepName}
+	for len(toVisit) > 0 {
+		name := toVisit[len(toVisit)-1]
+		toVisit = toVisit[:len(toVisit)-1]
+		if dependencies.Has(name) {
+			continue
+		}
+		dependencies.Add(name)
+		for _, input := range steps[name].Inputs {
+			if input != PipelinePayloadInput {
+				toVisit = append(toVisit, input)
+			}
+		}
+	}
+	return dependencies
+}
+
+// StepAPINames returns the names of the APIs which the pipeline's steps call (without duplicates, in the order of the steps)
+func (pipeline *Pipeline) StepAPINames() []string {
+	var apiNames []string
+	seen := strset.New()
+	for _, step := range pipeline.Steps {
+		if !seen.Has(step.API) {
+			apiNames = append(apiNames, step.API)
+			seen.Add(step.API)
+		}
+	}
+	return apiNames
+}
+
+func (pipeline *Pipeline) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ModeKey, pipeline.Mode.String()))
+	sb.WriteString(fmt.Sprintf("%s:\n", StepsKey))
+	for _, step := range pipeline.Steps {
+		sb.WriteString(fmt.Sprintf("  - %s: %s\n", NameKey, step.Name))
+		sb.WriteString(fmt.Sprintf("    %s: %s\n", APIKey, step.API))
+		if len(step.Inputs) > 0 {
+			sb.WriteString(fmt.Sprintf("    %s: %s\n", InputsKey, s.ObjFlatNoQuotes(step.Inputs)))
+		}
+	}
+	if pipeline.Output != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", OutputKey, *pipeline.Output))
+	}
+	return sb.String()
+}
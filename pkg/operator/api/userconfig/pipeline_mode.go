@@ -0,0 +1,78 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type PipelineMode int
+
+const (
+	UnknownPipelineMode PipelineMode = iota
+	ChainedPipelineMode
+	ColocatedPipelineMode
+)
+
+var pipelineModes = []string{
+	"unknown",
+	"chained",
+	"colocated",
+}
+
+func PipelineModeFromString(s string) PipelineMode {
+	for i := 0; i < len(pipelineModes); i++ {
+		if s == pipelineModes[i] {
+			return PipelineMode(i)
+		}
+	}
+	return UnknownPipelineMode
+}
+
+func PipelineModeStrings() []string {
+	return pipelineModes[1:]
+}
+
+func (t PipelineMode) String() string {
+	return pipelineModes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t PipelineMode) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *PipelineMode) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(pipelineModes); i++ {
+		if enum == pipelineModes[i] {
+			*t = PipelineMode(i)
+			return nil
+		}
+	}
+
+	*t = UnknownPipelineMode
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *PipelineMode) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t PipelineMode) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
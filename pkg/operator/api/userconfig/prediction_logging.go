@@ -0,0 +1,113 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// PredictionLogging persists a sample of the API's requests and responses to S3 (as JSON lines files under the destination prefix)
+type PredictionLogging struct {
+	SampleRate  float64  `json:"sample_rate" yaml:"sample_rate"`
+	Destination string   `json:"destination" yaml:"destination"` // an S3 prefix (e.g. s3://my-bucket/predictions)
+	Redact      []string `json:"redact" yaml:"redact"`           // dot-separated paths of fields in the payloads and predictions which are replaced before they're logged
+}
+
+var predictionLoggingFieldValidation = &cr.StructFieldValidation{
+	StructField: "PredictionLogging",
+	StructValidation: &cr.StructValidation{
+		DefaultNil: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "SampleRate",
+				Float64Validation: &cr.Float64Validation{
+					Default:           1,
+					GreaterThan:       pointer.Float64(0),
+					LessThanOrEqualTo: pointer.Float64(1),
+				},
+			},
+			{
+				StructField: "Destination",
+				StringValidation: &cr.StringValidation{
+					Required:  true,
+					Validator: cr.S3PathValidator(),
+				},
+			},
+			{
+				StructField: "Redact",
+				StringListValidation: &cr.StringListValidation{
+					AllowEmpty: true,
+					Validator:  validateFieldPaths,
+				},
+			},
+		},
+	},
+}
+
+// validateFieldPaths checks paths to fields in request payloads or predictions (fields are separated by dots)
+func validateFieldPaths(fields []string) ([]string, error) {
+	for _, field := range fields {
+		for _, part := range strings.Split(field, ".") {
+			if part == "" {
+				return nil, ErrorInvalidFieldPath(field)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// _writeCheckKey is written under destination prefixes to check that the cluster's credentials can write to them
+const _writeCheckKey = ".cortex_write_check"
+
+// Validate checks that the cluster's AWS credentials (which the API's pods use) can write to the destination
+func (predictionLogging *PredictionLogging) Validate(cache *s3Cache) error {
+	return validateS3DestinationWritable(predictionLogging.Destination, cache)
+}
+
+func validateS3DestinationWritable(destination string, cache *s3Cache) error {
+	return cache.check("write:"+destination, func() error {
+		awsClient, err := cache.client(destination)
+		if err != nil {
+			return err
+		}
+		_, prefix, err := aws.SplitS3Path(destination)
+		if err != nil {
+			return err
+		}
+		if err := awsClient.UploadStringToS3("", path.Join(prefix, _writeCheckKey)); err != nil {
+			return ErrorS3DestinationNotWritable(destination)
+		}
+		return nil
+	})
+}
+
+func (predictionLogging *PredictionLogging) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", SampleRateKey, s.Float64(predictionLogging.SampleRate)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", DestinationKey, predictionLogging.Destination))
+	if len(predictionLogging.Redact) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", RedactKey, s.ObjFlatNoQuotes(predictionLogging.Redact)))
+	}
+	return sb.String()
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/python"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+)
+
+type predictorFunction struct {
+	name string
+	args []string
+}
+
+// Keep in sync with the class validations in pkg/workloads/cortex/lib/context.py
+var predictorClassNames = map[PredictorType]string{
+	PythonPredictorType:     "PythonPredictor",
+	TensorFlowPredictorType: "TensorFlowPredictor",
+	ONNXPredictorType:       "ONNXPredictor",
+	SKLearnPredictorType:    "SKLearnPredictor",
+	XGBoostPredictorType:    "XGBoostPredictor",
+	LightGBMPredictorType:   "LightGBMPredictor",
+	TritonPredictorType:     "TritonPredictor",
+}
+
+var predictorClassFunctions = map[PredictorType][]predictorFunction{
+	PythonPredictorType: {
+		{name: "__init__", args: []string{"self", "config"}},
+		{name: "predict", args: []string{"self", "payload"}},
+	},
+	TensorFlowPredictorType: {
+		{name: "__init__", args: []string{"self", "tensorflow_client", "config"}},
+		{name: "predict", args: []string{"self", "payload"}},
+	},
+	ONNXPredictorType: {
+		{name: "__init__", args: []string{"self", "onnx_client", "config"}},
+		{name: "predict", args: []string{"self", "payload"}},
+	},
+	SKLearnPredictorType: {
+		{name: "__init__", args: []string{"self", "model", "config"}},
+		{name: "predict", args: []string{"self", "payload"}},
+	},
+	XGBoostPredictorType: {
+		{name: "__init__", args: []string{"self", "model", "config"}},
+		{name: "predict", args: []string{"self", "payload"}},
+	},
+	LightGBMPredictorType: {
+		{name: "__init__", args: []string{"self", "model", "config"}},
+		{name: "predict", args: []string{"self", "payload"}},
+	},
+	TritonPredictorType: {
+		{name: "__init__", args: []string{"self", "triton_client", "config"}},
+		{name: "predict", args: []string{"self", "payload"}},
+	},
+}
+
+// validatePredictorClass checks that the implementation file defines the predictor class with the expected function signatures.
+// Definitions which can't be resolved statically (e.g. imported or inherited from another module) are left to be checked at runtime.
+func validatePredictorClass(predictorType PredictorType, implPath string, implBytes []byte) error {
+	return validatePythonClass(predictorClassNames[predictorType], predictorClassFunctions[predictorType], implPath, implBytes)
+}
+
+func validatePythonClass(className string, functions []predictorFunction, implPath string, implBytes []byte) error {
+	if !strings.HasSuffix(implPath, ".py") {
+		return nil
+	}
+
+	module := python.ParseModule(implBytes)
+
+	class, ok := module.Classes[className]
+	if !ok {
+		if module.MayDefine(className) {
+			return nil
+		}
+		return ErrorPredictorClassNotDefined(className, implPath)
+	}
+
+	for _, expected := range functions {
+		fn, ok := class.Methods[expected.name]
+		if !ok {
+			if len(class.Bases) > 0 {
+				continue
+			}
+			return ErrorPredictorFunctionNotDefined(expected.name, className, implPath, class.Line)
+		}
+		if !slices.StrSlicesEqual(fn.Args, expected.args) {
+			return ErrorInvalidPredictorFunctionSignature(expected.name, className, implPath, fn.Line, expected.args, fn.Args)
+		}
+	}
+
+	return nil
+}
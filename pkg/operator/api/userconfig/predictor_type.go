@@ -23,6 +23,11 @@ const (
 	PythonPredictorType
 	TensorFlowPredictorType
 	ONNXPredictorType
+	SKLearnPredictorType
+	XGBoostPredictorType
+	LightGBMPredictorType
+	TritonPredictorType
+	PipelinePredictorType
 )
 
 var predictorTypes = []string{
@@ -30,6 +35,11 @@ var predictorTypes = []string{
 	"python",
 	"tensorflow",
 	"onnx",
+	"sklearn",
+	"xgboost",
+	"lightgbm",
+	"triton",
+	"pipeline",
 }
 
 func PredictorTypeFromString(s string) PredictorType {
@@ -41,8 +51,9 @@ func PredictorTypeFromString(s string) PredictorType {
 	return UnknownPredictorType
 }
 
+// PredictorTypeStrings returns the predictor types which can be configured (pipeline APIs are declared with their own kind)
 func PredictorTypeStrings() []string {
-	return predictorTypes[1:]
+	return predictorTypes[1:PipelinePredictorType]
 }
 
 func (t PredictorType) String() string {
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+// Priority determines which APIs' replicas are preempted when the cluster is full (replicas of higher priority APIs may preempt replicas of lower priority APIs)
+type Priority int
+
+const (
+	UnknownPriority Priority = iota
+	LowPriority
+	MediumPriority
+	HighPriority
+)
+
+var priorities = []string{
+	"unknown",
+	"low",
+	"medium",
+	"high",
+}
+
+func PriorityFromString(s string) Priority {
+	for i := 0; i < len(priorities); i++ {
+		if s == priorities[i] {
+			return Priority(i)
+		}
+	}
+	return UnknownPriority
+}
+
+func PriorityStrings() []string {
+	return priorities[1:]
+}
+
+func (t Priority) String() string {
+	return priorities[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t Priority) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *Priority) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(priorities); i++ {
+		if enum == priorities[i] {
+			*t = Priority(i)
+			return nil
+		}
+	}
+
+	*t = UnknownPriority
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *Priority) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t Priority) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"sort"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
+)
+
+const numLargestProjectFilesToShow = 5
+
+const (
+	// ProjectManifestFileName is the deploy request's form file which maps each of the project's paths to the hash of its contents
+	ProjectManifestFileName = "project.json"
+	// ProjectFilesDirName prefixes the deploy request's form files which contain the project files that the operator hasn't stored yet (each is named by its hash)
+	ProjectFilesDirName = "project_files"
+)
+
+// ProjectFiles selects which of the files in the project directory are deployed (in addition to .cortexignore)
+type ProjectFiles struct {
+	Include []string `json:"include" yaml:"include"`
+	Exclude []string `json:"exclude" yaml:"exclude"`
+}
+
+var projectFilesFieldValidation = &cr.StructFieldValidation{
+	StructField: "Project",
+	StructValidation: &cr.StructValidation{
+		DefaultNil:        true,
+		AllowExplicitNull: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "Include",
+				StringListValidation: &cr.StringListValidation{
+					AllowEmpty: true,
+					Validator:  validateProjectPatterns,
+				},
+			},
+			{
+				StructField: "Exclude",
+				StringListValidation: &cr.StringListValidation{
+					AllowEmpty: true,
+					Validator:  validateProjectPatterns,
+				},
+			},
+		},
+	},
+}
+
+func validateProjectPatterns(patterns []string) ([]string, error) {
+	for _, pattern := range patterns {
+		if err := files.ValidatePattern(pattern); err != nil {
+			return nil, ErrorInvalidProjectPattern(pattern)
+		}
+	}
+	return patterns, nil
+}
+
+// Includes returns whether the file at path (relative to the project root) is deployed
+func (projectFiles *ProjectFiles) Includes(path string) (bool, error) {
+	if projectFiles == nil {
+		return true, nil
+	}
+
+	excluded, err := files.PathMatchesPatterns(path, projectFiles.Exclude)
+	if err != nil || excluded {
+		return false, err
+	}
+
+	if len(projectFiles.Include) == 0 {
+		return true, nil
+	}
+	return files.PathMatchesPatterns(path, projectFiles.Include)
+}
+
+// Filter removes the files which aren't included from the zipped project (the project is returned as is if all of its files are included)
+func (projectFiles *ProjectFiles) Filter(projectBytes []byte) ([]byte, error) {
+	if projectFiles == nil || (len(projectFiles.Include) == 0 && len(projectFiles.Exclude) == 0) {
+		return projectBytes, nil
+	}
+
+	projectFileMap, err := zip.UnzipMemToMem(projectBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for path := range projectFileMap {
+		included, err := projectFiles.Includes(path)
+		if err != nil {
+			return nil, err
+		}
+		if included {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == len(projectFileMap) {
+		return projectBytes, nil
+	}
+	sort.Strings(paths) // the project's ID is the hash of the zip
+
+	zipInput := &zip.Input{Bytes: make([]zip.BytesInput, len(paths))}
+	for i, path := range paths {
+		zipInput.Bytes[i] = zip.BytesInput{Content: projectFileMap[path], Dest: path}
+	}
+	return zip.ToMem(zipInput)
+}
+
+// ProjectLimits constrains the (uncompressed) contents of the project directory
+type ProjectLimits struct {
+	MaxSize     int64 // bytes
+	MaxFileSize int64 // bytes
+	MaxFiles    int64
+}
+
+// ValidateProjectLimits checks the project against the limits without extracting it
+func ValidateProjectLimits(projectBytes []byte, limits *ProjectLimits) error {
+	if limits == nil {
+		return nil
+	}
+
+	fileSizes, err := zip.FileSizesInMem(projectBytes)
+	if err != nil {
+		return err
+	}
+
+	if int64(len(fileSizes)) > limits.MaxFiles {
+		return ErrorProjectTooManyFiles(len(fileSizes), limits.MaxFiles)
+	}
+
+	paths := make([]string, 0, len(fileSizes))
+	var totalSize int64
+	for path, size := range fileSizes {
+		paths = append(paths, path)
+		totalSize += size
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if fileSizes[paths[i]] == fileSizes[paths[j]] {
+			return paths[i] < paths[j]
+		}
+		return fileSizes[paths[i]] > fileSizes[paths[j]]
+	})
+
+	var oversizedFiles []string
+	for _, path := range paths {
+		if fileSizes[path] <= limits.MaxFileSize {
+			break
+		}
+		oversizedFiles = append(oversizedFiles, projectFileStr(path, fileSizes[path]))
+	}
+	if len(oversizedFiles) > 0 {
+		return ErrorProjectFilesTooLarge(oversizedFiles, limits.MaxFileSize)
+	}
+
+	if totalSize > limits.MaxSize {
+		var largestFiles []string
+		for i := 0; i < len(paths) && i < numLargestProjectFilesToShow; i++ {
+			largestFiles = append(largestFiles, projectFileStr(paths[i], fileSizes[paths[i]]))
+		}
+		return ErrorProjectTooLarge(totalSize, limits.MaxSize, largestFiles)
+	}
+
+	return nil
+}
+
+func projectFileStr(path string, size int64) string {
+	return fmt.Sprintf("%s (%s)", path, s.ByteSize(size))
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type Protocol int
+
+const (
+	UnknownProtocol Protocol = iota
+	HTTPProtocol
+	WebSocketProtocol
+)
+
+var protocols = []string{
+	"unknown",
+	"http",
+	"websocket",
+}
+
+func ProtocolFromString(s string) Protocol {
+	for i := 0; i < len(protocols); i++ {
+		if s == protocols[i] {
+			return Protocol(i)
+		}
+	}
+	return UnknownProtocol
+}
+
+func ProtocolStrings() []string {
+	return protocols[1:]
+}
+
+func (t Protocol) String() string {
+	return protocols[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t Protocol) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *Protocol) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(protocols); i++ {
+		if enum == protocols[i] {
+			*t = Protocol(i)
+			return nil
+		}
+	}
+
+	*t = UnknownProtocol
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *Protocol) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t Protocol) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
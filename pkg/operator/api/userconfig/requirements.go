@@ -0,0 +1,114 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pip"
+)
+
+const RequirementsFileName = "requirements.txt"
+
+// Packages which the serving runtime depends on, and the versions which are installed in each predictor type's image
+// (see pkg/workloads/cortex/*/requirements.txt and images/*/Dockerfile)
+var runtimePackages = map[PredictorType]map[string]string{
+	PythonPredictorType: {
+		"dill":      "0.3.1.1",
+		"flask":     "1.1.1",
+		"flask-api": "1.1",
+		"msgpack":   "0.6.2",
+		"waitress":  "1.4.2",
+	},
+	TensorFlowPredictorType: {
+		"dill":                   "0.3.1.1",
+		"flask":                  "1.1.1",
+		"flask-api":              "1.1",
+		"msgpack":                "0.6.2",
+		"tensorflow":             "2.0.0",
+		"tensorflow-serving-api": "2.0.0",
+		"waitress":               "1.4.2",
+	},
+	ONNXPredictorType: {
+		"dill":        "0.3.1.1",
+		"flask":       "1.1.1",
+		"flask-api":   "1.1",
+		"msgpack":     "0.6.2",
+		"onnxruntime": "1.1.0",
+		"waitress":    "1.4.2",
+	},
+	SKLearnPredictorType: {
+		"dill":         "0.3.1.1",
+		"flask":        "1.1.1",
+		"flask-api":    "1.1",
+		"joblib":       "0.14.1",
+		"msgpack":      "0.6.2",
+		"scikit-learn": "0.22.1",
+		"waitress":     "1.4.2",
+	},
+	XGBoostPredictorType: {
+		"dill":      "0.3.1.1",
+		"flask":     "1.1.1",
+		"flask-api": "1.1",
+		"msgpack":   "0.6.2",
+		"waitress":  "1.4.2",
+		"xgboost":   "0.90",
+	},
+	LightGBMPredictorType: {
+		"dill":      "0.3.1.1",
+		"flask":     "1.1.1",
+		"flask-api": "1.1",
+		"lightgbm":  "2.3.1",
+		"msgpack":   "0.6.2",
+		"waitress":  "1.4.2",
+	},
+	TritonPredictorType: {
+		"dill":      "0.3.1.1",
+		"flask":     "1.1.1",
+		"flask-api": "1.1",
+		"msgpack":   "0.6.2",
+		"requests":  "2.22.0",
+		"waitress":  "1.4.2",
+	},
+}
+
+// ValidateRequirements checks that the project's requirements.txt (if present) is valid, and that it doesn't conflict with the runtime's packages
+func ValidateRequirements(projectFileMap map[string][]byte, apis APIs) error {
+	requirementsBytes, ok := projectFileMap[RequirementsFileName]
+	if !ok {
+		return nil
+	}
+
+	requirements, err := pip.ParseRequirements(string(requirementsBytes))
+	if err != nil {
+		return errors.Wrap(err, RequirementsFileName)
+	}
+
+	for _, api := range apis {
+		packages := runtimePackages[api.Predictor.Type]
+		for _, req := range requirements {
+			installedVersion, ok := packages[pip.NormalizeName(req.Name)]
+			if !ok {
+				continue
+			}
+			if allowed, ok := req.AllowsVersion(installedVersion); ok && !allowed {
+				return errors.Wrap(ErrorRequirementConflictsWithRuntime(req.Name+req.SpecifiersStr(), req.Name, installedVersion, api.Predictor.Type), RequirementsFileName)
+			}
+		}
+	}
+
+	return nil
+}
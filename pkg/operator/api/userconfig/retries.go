@@ -0,0 +1,97 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+const MaxRetryAttempts = 10
+
+// Retries configures the retries of requests which fail to connect to the API's replicas (e.g. because a replica is restarting),
+// or which receive one of the status codes; requests are retried by the istio gateway
+type Retries struct {
+	Attempts      int32         `json:"attempts" yaml:"attempts"`
+	PerTryTimeout time.Duration `json:"per_try_timeout" yaml:"per_try_timeout"` // each try may take up to the request's timeout if 0
+	StatusCodes   []int32       `json:"status_codes" yaml:"status_codes"`
+}
+
+var retriesFieldValidation = &cr.StructFieldValidation{
+	StructField: "Retries",
+	AllowedIf:   &cr.FieldCondition{Key: ProtocolKey, Values: []interface{}{WebSocketProtocol}, Not: true},
+	StructValidation: &cr.StructValidation{
+		DefaultNil: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "Attempts",
+				Int32Validation: &cr.Int32Validation{
+					Required:          true,
+					GreaterThan:       pointer.Int32(0),
+					LessThanOrEqualTo: pointer.Int32(MaxRetryAttempts),
+				},
+			},
+			{
+				StructField: "PerTryTimeout",
+				DurationValidation: &cr.DurationValidation{
+					GreaterThanOrEqualTo: pointer.Duration(0),
+				},
+			},
+			{
+				StructField: "StatusCodes",
+				Int32ListValidation: &cr.Int32ListValidation{
+					Default:    []int32{503},
+					AllowEmpty: true,
+					Validator:  validateRetryStatusCodes,
+				},
+			},
+		},
+	},
+}
+
+// only server errors are retried (other status codes are caused by the request itself)
+func validateRetryStatusCodes(statusCodes []int32) ([]int32, error) {
+	for _, statusCode := range statusCodes {
+		if statusCode < 500 || statusCode > 599 {
+			return nil, ErrorInvalidRetryStatusCode(statusCode)
+		}
+	}
+	return statusCodes, nil
+}
+
+// Validate checks that each try can finish within the request's timeout (a timeout of 0 means that requests don't time out)
+func (retries *Retries) Validate(timeout time.Duration) error {
+	if timeout != 0 && retries.PerTryTimeout >= timeout {
+		return ErrorPerTryTimeoutExceedsTimeout(retries.PerTryTimeout, timeout)
+	}
+	return nil
+}
+
+func (retries *Retries) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", AttemptsKey, s.Int32(retries.Attempts)))
+	if retries.PerTryTimeout != 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", PerTryTimeoutKey, retries.PerTryTimeout.String()))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", StatusCodesKey, s.ObjFlatNoQuotes(retries.StatusCodes)))
+	return sb.String()
+}
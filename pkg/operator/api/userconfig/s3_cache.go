@@ -0,0 +1,93 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"sync"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+)
+
+// s3Cache shares S3 lookups between the APIs which are validated in the same deploy (it's safe for concurrent use)
+// Kubernetes lookups aren't cached here: validating a deploy measures the cluster's node groups once (in workloads.ValidateDeploy), and reads deployments and pods from the operator's informer caches
+type s3Cache struct {
+	sync.Mutex
+	modelPaths map[string]cachedModelValidation // predictor type and model -> result
+	checks     map[string]error                 // check name -> result
+}
+
+type cachedModelValidation struct {
+	path string
+	err  error
+}
+
+func newS3Cache() *s3Cache {
+	return &s3Cache{
+		modelPaths: make(map[string]cachedModelValidation),
+		checks:     make(map[string]error),
+	}
+}
+
+// client returns a client for the S3 path's bucket (in the bucket's region); clients are pooled across deploys by the aws package
+func (cache *s3Cache) client(s3Path string) (*aws.Client, error) {
+	return aws.NewFromS3Path(s3Path, false)
+}
+
+// check returns the result of fn for the key, calling it only if it hasn't already been called for the key in this deploy
+func (cache *s3Cache) check(key string, fn func() error) error {
+	cache.Lock()
+	err, ok := cache.checks[key]
+	cache.Unlock()
+	if ok {
+		return err
+	}
+
+	err = fn()
+
+	cache.Lock()
+	cache.checks[key] = err
+	cache.Unlock()
+	return err
+}
+
+// modelPath returns the result of validate for the key, calling it only if it hasn't already been called for the key in this deploy
+// and there isn't a reusable validation from a previous deploy (etagPath returns the S3 file which identifies the version of a validated model,
+// or an empty string if the model can change without that file changing, in which case the validation is only shared within the deploy)
+func (cache *s3Cache) modelPath(key string, validate func() (string, error), etagPath func(path string) string) (string, error) {
+	cache.Lock()
+	cached, ok := cache.modelPaths[key]
+	cache.Unlock()
+	if ok {
+		return cached.path, cached.err
+	}
+
+	path, ok := cache.validatedModelPath(key)
+	var err error
+	if !ok {
+		path, err = validate()
+		if err == nil {
+			if modelETagPath := etagPath(path); modelETagPath != "" {
+				cache.saveModelValidation(key, path, modelETagPath)
+			}
+		}
+	}
+
+	cache.Lock()
+	cache.modelPaths[key] = cachedModelValidation{path: path, err: err}
+	cache.Unlock()
+	return path, err
+}
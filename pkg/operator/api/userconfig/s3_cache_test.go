@@ -0,0 +1,79 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Exports of TensorFlow models are looked up again on each deploy, so that a newer version which is uploaded under the model's prefix is served
+func TestTensorFlowExportIsRevalidatedAcrossDeploys(t *testing.T) {
+	model := "s3://cortex-test/models/iris"
+	key := "tensorflow:" + model
+	defer _modelValidations.delete(key)
+
+	export := model + "/1"
+	validations := 0
+	validate := func() (string, error) {
+		validations++
+		return export, nil
+	}
+
+	cache := newS3Cache()
+	path, err := cache.modelPath(key, validate, tensorFlowModelETagPath)
+	require.NoError(t, err)
+	require.Equal(t, model+"/1", path)
+
+	// the validation is shared by the deploy's APIs
+	path, err = cache.modelPath(key, validate, tensorFlowModelETagPath)
+	require.NoError(t, err)
+	require.Equal(t, model+"/1", path)
+	require.Equal(t, 1, validations)
+
+	export = model + "/2"
+
+	path, err = newS3Cache().modelPath(key, validate, tensorFlowModelETagPath)
+	require.NoError(t, err)
+	require.Equal(t, model+"/2", path)
+	require.Equal(t, 2, validations)
+}
+
+func TestZippedTensorFlowModelIsReusedAcrossDeploys(t *testing.T) {
+	model := "s3://cortex-test/models/iris.zip"
+	key := "tensorflow:" + model
+	defer _modelValidations.delete(key)
+
+	require.Equal(t, model, tensorFlowModelETagPath(model))
+
+	// a validation from a previous deploy which is within the TTL is reused without any requests to S3
+	_modelValidations.set(key, modelValidationEntry{
+		path:        model,
+		etagPath:    model,
+		etag:        "etag",
+		validatedAt: time.Now(),
+	})
+
+	path, err := newS3Cache().modelPath(key, func() (string, error) {
+		require.Fail(t, "the model was validated again")
+		return "", nil
+	}, tensorFlowModelETagPath)
+	require.NoError(t, err)
+	require.Equal(t, model, path)
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"path/filepath"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	"github.com/cortexlabs/cortex/pkg/lib/sagemaker"
+)
+
+// The file in an imported model's directory which records its layout (it's uploaded after the model's files, so the import is complete if it exists)
+const _sageMakerLayoutFileName = "layout.json"
+
+// IsSageMakerModel returns whether the predictor's model is a SageMaker model artifact which hasn't been imported yet
+func (predictor *Predictor) IsSageMakerModel() bool {
+	return predictor.Model != nil && sagemaker.IsModelArtifact(*predictor.Model)
+}
+
+// ImportSageMakerModels replaces each SageMaker model artifact (model.tar.gz) with the S3 path of its model, in the layout which the predictor type expects
+// (the model's files are extracted to the cluster's bucket once per version of the artifact, and the artifact's path is kept in the predictor)
+func (apis APIs) ImportSageMakerModels(clusterAWSClient *aws.Client) error {
+	cache := newS3Cache()
+	importedPaths := map[string]string{} // artifact path and predictor type -> the imported model's path
+
+	for _, api := range apis {
+		if !api.Predictor.IsSageMakerModel() {
+			continue
+		}
+
+		artifactPath := *api.Predictor.Model
+		key := api.Predictor.Type.String() + ":" + artifactPath
+		modelPath, ok := importedPaths[key]
+		if !ok {
+			var err error
+			modelPath, err = importSageMakerModel(artifactPath, api.Predictor.Type, cache, clusterAWSClient)
+			if err != nil {
+				return errors.Wrap(err, Identify(api), PredictorKey, ModelKey)
+			}
+			importedPaths[key] = modelPath
+		}
+
+		api.Predictor.SageMakerModel = &artifactPath
+		api.Predictor.Model = &modelPath
+	}
+
+	return nil
+}
+
+func importSageMakerModel(artifactPath string, predictorType PredictorType, cache *s3Cache, clusterAWSClient *aws.Client) (string, error) {
+	etag, err := cache.etag(artifactPath)
+	if err != nil {
+		return "", err
+	}
+	if etag == "" {
+		return "", ErrorExternalNotFound(artifactPath)
+	}
+
+	importDir := filepath.Join(consts.SageMakerModelsDir, hash.String(artifactPath+etag))
+	layoutKey := filepath.Join(importDir, _sageMakerLayoutFileName)
+
+	layout := &sagemaker.Layout{}
+	if imported, err := clusterAWSClient.IsS3File(layoutKey); err != nil {
+		return "", err
+	} else if imported {
+		if err := clusterAWSClient.ReadJSONFromS3(layout, layoutKey); err != nil {
+			return "", err
+		}
+	} else {
+		layout, err = extractSageMakerModel(artifactPath, predictorType, cache, clusterAWSClient, importDir)
+		if err != nil {
+			return "", errors.Wrap(err, artifactPath)
+		}
+	}
+
+	if layout.Framework.String() != predictorType.String() {
+		return "", ErrorSageMakerFrameworkMismatch(artifactPath, layout.Framework, predictorType)
+	}
+
+	return clusterAWSClient.S3Path(filepath.Join(importDir, layout.ModelPath)), nil
+}
+
+// extractSageMakerModel detects the layout of the artifact's model, and uploads the model's files to the import directory if it can be served by the predictor type
+func extractSageMakerModel(artifactPath string, predictorType PredictorType, cache *s3Cache, clusterAWSClient *aws.Client, importDir string) (*sagemaker.Layout, error) {
+	artifactAWSClient, err := cache.client(artifactPath)
+	if err != nil {
+		return nil, err
+	}
+
+	artifact, err := artifactAWSClient.OpenS3Path(artifactPath)
+	if err != nil {
+		return nil, err
+	}
+	files, err := sagemaker.ListFiles(artifact)
+	artifact.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	layout, err := sagemaker.DetectLayout(files)
+	if err != nil {
+		return nil, err
+	}
+	if layout.Framework.String() != predictorType.String() {
+		return layout, nil
+	}
+
+	// the artifact is read again so that only one of the model's files is held in memory at a time
+	artifact, err = artifactAWSClient.OpenS3Path(artifactPath)
+	if err != nil {
+		return nil, err
+	}
+	defer artifact.Close()
+	err = layout.Extract(artifact, func(path string, data []byte) error {
+		return clusterAWSClient.UploadBytesToS3(data, filepath.Join(importDir, path))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := clusterAWSClient.UploadJSONToS3(layout, filepath.Join(importDir, _sageMakerLayoutFileName)); err != nil {
+		return nil, err
+	}
+	return layout, nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+const (
+	SecretsManagerSecretSource = "secret"
+	SSMParameterSecretSource   = "ssm"
+)
+
+var (
+	secretReferenceRegex       = regexp.MustCompile(`^\$\{(secret|ssm):([^{}\s]+)\}$`)
+	secretReferencePrefixRegex = regexp.MustCompile(`^\$\{(secret|ssm):`)
+)
+
+// SecretReference is a predictor config value of the form ${secret:<name or arn>} or ${ssm:<name or arn>}
+type SecretReference struct {
+	Reference string // the full reference, as written in the config
+	Source    string // SecretsManagerSecretSource or SSMParameterSecretSource
+	ID        string // the secret or parameter name / ARN
+}
+
+// ParseSecretReference returns the reference if str is of the form ${secret:<name or arn>} or ${ssm:<name or arn>}
+func ParseSecretReference(str string) (*SecretReference, bool) {
+	match := secretReferenceRegex.FindStringSubmatch(str)
+	if match == nil {
+		return nil, false
+	}
+	return &SecretReference{
+		Reference: str,
+		Source:    match[1],
+		ID:        match[2],
+	}, true
+}
+
+// SecretReferences returns the unique secret references in the predictor's config (sorted by reference)
+func (predictor *Predictor) SecretReferences() []*SecretReference {
+	return secretReferences(predictor.Config)
+}
+
+// SecretReferences returns the unique secret references in the API's predictor and experiment variant configs (sorted by reference)
+func (api *API) SecretReferences() []*SecretReference {
+	configs := []map[string]interface{}{api.Predictor.Config}
+	if api.Experiment != nil {
+		for _, variant := range api.Experiment.Variants {
+			configs = append(configs, variant.Config)
+		}
+	}
+	return secretReferences(configs...)
+}
+
+func secretReferences(configs ...map[string]interface{}) []*SecretReference {
+	refs := map[string]*SecretReference{}
+	for _, config := range configs {
+		walkConfigStrings(config, func(str string, _ []string) {
+			if ref, ok := ParseSecretReference(str); ok {
+				refs[ref.Reference] = ref
+			}
+		})
+	}
+
+	refList := make([]*SecretReference, 0, len(refs))
+	for _, ref := range refs {
+		refList = append(refList, ref)
+	}
+	sort.Slice(refList, func(i, j int) bool {
+		return refList[i].Reference < refList[j].Reference
+	})
+	return refList
+}
+
+func validateSecretReferences(config map[string]interface{}) error {
+	var err error
+	walkConfigStrings(config, func(str string, keys []string) {
+		if err != nil || !secretReferencePrefixRegex.MatchString(str) {
+			return
+		}
+		if _, ok := ParseSecretReference(str); !ok {
+			err = errors.Wrap(ErrorInvalidSecretReference(str), keys...)
+		}
+	})
+	return err
+}
+
+// walkConfigStrings calls fn with every string value in the config (recursing into nested maps and lists)
+func walkConfigStrings(val interface{}, fn func(str string, keys []string), keys ...string) {
+	switch typedVal := val.(type) {
+	case string:
+		fn(typedVal, keys)
+	case map[string]interface{}:
+		for key, subVal := range typedVal {
+			walkConfigStrings(subVal, fn, append(keys[:len(keys):len(keys)], key)...)
+		}
+	case map[interface{}]interface{}:
+		for key, subVal := range typedVal {
+			walkConfigStrings(subVal, fn, append(keys[:len(keys):len(keys)], s.ObjFlatNoQuotes(key))...)
+		}
+	case []interface{}:
+		for i, subVal := range typedVal {
+			walkConfigStrings(subVal, fn, append(keys[:len(keys):len(keys)], s.Index(i))...)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/protobuf"
+)
+
+// The versions of TensorFlow Serving which APIs can pin (a tf-serve image is built for each of them)
+var TensorFlowServingVersions = []string{"1.14.0", "1.15.0", "2.0.0"}
+
+// The versions of ONNX Runtime which APIs can pin (an onnx-serve image is built for each of them)
+var ONNXRuntimeVersions = []string{"0.5.0", "1.0.0", "1.1.0"}
+
+// The versions of Python which APIs can select (the images which run the predictor are built with each of the versions which their frameworks support)
+var PythonVersions = []string{"3.6", "3.7", "3.8"}
+
+const (
+	DefaultTensorFlowServingVersion = "2.0.0" // the version which is served by the cluster's tf-serve images
+	DefaultONNXRuntimeVersion       = "1.1.0" // the version which is served by the cluster's onnx-serve images
+	DefaultPythonVersion            = "3.6"   // the version which is installed in the cluster's images
+)
+
+// SupportedPythonVersions returns the versions of Python which the predictor type's framework can be installed with
+func SupportedPythonVersions(predictorType PredictorType) []string {
+	switch predictorType {
+	case TensorFlowPredictorType:
+		return []string{"3.6"} // the tf-api image is based on TensorFlow's image
+	case ONNXPredictorType:
+		return []string{"3.6", "3.7"} // the supported versions of ONNX Runtime aren't published for python 3.8
+	}
+	return PythonVersions
+}
+
+type onnxRuntimeLimits struct {
+	maxIRVersion    int64
+	maxOpsetVersion int64 // of the default (ai.onnx) operator set
+}
+
+// The newest ONNX IR and opset versions which are supported by each version of ONNX Runtime
+var _onnxRuntimeLimits = map[string]onnxRuntimeLimits{
+	"0.5.0": {maxIRVersion: 5, maxOpsetVersion: 10},
+	"1.0.0": {maxIRVersion: 6, maxOpsetVersion: 11},
+	"1.1.0": {maxIRVersion: 6, maxOpsetVersion: 11},
+}
+
+// Field numbers in TensorFlow's SavedModel, MetaGraphDef, and MetaInfoDef messages
+const (
+	_savedModelMetaGraphsField         = 2
+	_metaGraphMetaInfoDefField         = 1
+	_metaInfoDefTensorFlowVersionField = 5
+)
+
+// Field numbers in ONNX's ModelProto and OperatorSetIdProto messages
+const (
+	_onnxModelIRVersionField   = 1
+	_onnxModelOpsetImportField = 8
+	_onnxOpsetDomainField      = 1
+	_onnxOpsetVersionField     = 2
+)
+
+// checkTensorFlowServingVersion checks that the model in the export directory wasn't exported with a newer version of TensorFlow than the version of TensorFlow Serving
+// (if the model doesn't record the version of TensorFlow which exported it, the check is skipped)
+func checkTensorFlowServingVersion(exportPath string, servingVersion string, awsClient *aws.Client) error {
+	modelVersion, err := savedModelTensorFlowVersion(aws.S3PathJoin(exportPath, "saved_model.pb"), awsClient)
+	if err != nil {
+		return err
+	}
+	if modelVersion == "" {
+		return nil
+	}
+
+	if compareMajorMinor(modelVersion, servingVersion) > 0 {
+		return ErrorIncompatibleTensorFlowVersion(modelVersion, servingVersion)
+	}
+	return nil
+}
+
+// savedModelTensorFlowVersion returns the version of TensorFlow which is recorded in the first meta graph of the SavedModel ("" if it isn't recorded);
+// only the headers of the fields which precede it are read from S3
+func savedModelTensorFlowVersion(s3Path string, awsClient *aws.Client) (string, error) {
+	size, err := awsClient.GetS3PathSize(s3Path)
+	if err != nil {
+		return "", err
+	}
+	readAt := func(offset int64, length int64) ([]byte, error) {
+		return awsClient.ReadBytesRangeFromS3Path(s3Path, offset, length)
+	}
+
+	metaGraph, err := findField(0, size, readAt, _savedModelMetaGraphsField)
+	if metaGraph == nil || err != nil {
+		return "", err
+	}
+	metaInfoDef, err := findField(metaGraph.Offset, metaGraph.Length, readAt, _metaGraphMetaInfoDefField)
+	if metaInfoDef == nil || err != nil {
+		return "", err
+	}
+	tensorFlowVersion, err := findField(metaInfoDef.Offset, metaInfoDef.Length, readAt, _metaInfoDefTensorFlowVersionField)
+	if tensorFlowVersion == nil || err != nil {
+		return "", err
+	}
+
+	version, err := readAt(tensorFlowVersion.Offset, tensorFlowVersion.Length)
+	if err != nil {
+		return "", err
+	}
+	return string(version), nil
+}
+
+// checkONNXRuntimeVersion checks that the model's IR and opset versions are supported by the version of ONNX Runtime
+func checkONNXRuntimeVersion(s3Path string, runtimeVersion string, awsClient *aws.Client) error {
+	size, err := awsClient.GetS3PathSize(s3Path)
+	if err != nil {
+		return err
+	}
+	readAt := func(offset int64, length int64) ([]byte, error) {
+		return awsClient.ReadBytesRangeFromS3Path(s3Path, offset, length)
+	}
+
+	var irVersion, opsetVersion int64
+	var opsetImports []protobuf.Field
+	err = protobuf.ScanFields(size, readAt, func(field protobuf.Field) (bool, error) {
+		switch {
+		case field.Number == _onnxModelIRVersionField && field.WireType == protobuf.WireTypeVarint:
+			irVersion = int64(field.Varint)
+		case field.Number == _onnxModelOpsetImportField && field.WireType == protobuf.WireTypeLengthDelimited:
+			opsetImports = append(opsetImports, field)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return ignoreMalformedMessage(err)
+	}
+
+	for _, opsetImport := range opsetImports {
+		data, err := readAt(opsetImport.Offset, opsetImport.Length)
+		if err != nil {
+			return err
+		}
+		fields, _, err := protobuf.Fields(data)
+		if err != nil {
+			return ignoreMalformedMessage(err)
+		}
+
+		var domain string
+		var version int64
+		for _, field := range fields {
+			switch {
+			case field.Number == _onnxOpsetDomainField && field.WireType == protobuf.WireTypeLengthDelimited:
+				domain = string(field.Bytes(data))
+			case field.Number == _onnxOpsetVersionField && field.WireType == protobuf.WireTypeVarint:
+				version = int64(field.Varint)
+			}
+		}
+		if (domain == "" || domain == "ai.onnx") && version > opsetVersion {
+			opsetVersion = version
+		}
+	}
+
+	limits := _onnxRuntimeLimits[runtimeVersion]
+	if irVersion > limits.maxIRVersion || opsetVersion > limits.maxOpsetVersion {
+		return ErrorIncompatibleONNXModel(irVersion, opsetVersion, runtimeVersion)
+	}
+	return nil
+}
+
+// findField returns the first field with the number in the message at the offset (nil if there isn't one, or if the message is malformed)
+func findField(offset int64, size int64, readAt protobuf.ReadAtFn, number int) (*protobuf.Field, error) {
+	var match *protobuf.Field
+	err := protobuf.ScanFields(size, func(fieldOffset int64, length int64) ([]byte, error) {
+		return readAt(offset+fieldOffset, length)
+	}, func(field protobuf.Field) (bool, error) {
+		if field.Number != number {
+			return true, nil
+		}
+		field.Offset += offset
+		match = &field
+		return false, nil
+	})
+	if err != nil {
+		return nil, ignoreMalformedMessage(err)
+	}
+	return match, nil
+}
+
+// ignoreMalformedMessage returns nil for errors from parsing models, so that models which can't be parsed are left for the serving runtime to report on
+func ignoreMalformedMessage(err error) error {
+	if _, ok := errors.Cause(err).(protobuf.Error); ok {
+		return nil
+	}
+	return err
+}
+
+// compareMajorMinor compares the major and minor components of two versions (e.g. "1.15.0" and "1.15.2-rc0" are equal)
+func compareMajorMinor(v1 string, v2 string) int {
+	r1, r2 := majorMinor(v1), majorMinor(v2)
+	for i := range r1 {
+		if r1[i] != r2[i] {
+			if r1[i] < r2[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func majorMinor(version string) [2]int {
+	var release [2]int
+	parts := strings.SplitN(version, ".", 3)
+	for i := 0; i < len(parts) && i < 2; i++ {
+		digits := parts[i]
+		if end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); end != -1 {
+			digits = digits[:end]
+		}
+		release[i], _ = strconv.Atoi(digits)
+	}
+	return release
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/triton"
+)
+
+// TritonValidate checks that the model is a Triton model repository: each of its top-level directories is a model with a config.pbtxt and numbered version directories (all of the models are served by each replica)
+func (predictor *Predictor) TritonValidate(cache *s3Cache) error {
+	model := strings.TrimSuffix(*predictor.Model, "/")
+
+	err := cache.check("triton:"+model, func() error {
+		awsClient, err := cache.client(model)
+		if err != nil {
+			return err
+		}
+
+		keys, err := awsClient.ListS3PathDir(model)
+		if err != nil {
+			return errors.Wrap(err, ModelKey)
+		}
+		if len(keys) == 0 {
+			return errors.Wrap(ErrorExternalNotFound(model), ModelKey)
+		}
+
+		_, err = triton.ValidateRepository(keys, func(modelName string) ([]byte, error) {
+			return awsClient.ReadBytesFromS3Path(aws.S3PathJoin(model, modelName, triton.ConfigFileName))
+		})
+		if err != nil {
+			return errors.Wrap(err, ModelKey)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	predictor.Model = pointer.String(model)
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type UpdateMode int
+
+const (
+	UnknownUpdateMode UpdateMode = iota
+	RollingUpdateMode
+	BlueGreenUpdateMode
+	CanaryUpdateMode
+)
+
+var updateModes = []string{
+	"unknown",
+	"rolling",
+	"blue_green",
+	"canary",
+}
+
+func UpdateModeFromString(s string) UpdateMode {
+	for i := 0; i < len(updateModes); i++ {
+		if s == updateModes[i] {
+			return UpdateMode(i)
+		}
+	}
+	return UnknownUpdateMode
+}
+
+func UpdateModeStrings() []string {
+	return updateModes[1:]
+}
+
+func (t UpdateMode) String() string {
+	return updateModes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t UpdateMode) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *UpdateMode) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(updateModes); i++ {
+		if enum == updateModes[i] {
+			*t = UpdateMode(i)
+			return nil
+		}
+	}
+
+	*t = UnknownUpdateMode
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *UpdateMode) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t UpdateMode) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
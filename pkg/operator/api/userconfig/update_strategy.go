@@ -0,0 +1,154 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+)
+
+type UpdateStrategy struct {
+	Mode                UpdateMode    `json:"mode" yaml:"mode"`
+	PreviewEndpoint     *string       `json:"preview_endpoint" yaml:"preview_endpoint"`
+	Canary              *Canary       `json:"canary" yaml:"canary"`
+	RollbackOnCrashLoop bool          `json:"rollback_on_crash_loop" yaml:"rollback_on_crash_loop"`
+	ProgressDeadline    time.Duration `json:"progress_deadline" yaml:"progress_deadline"`
+}
+
+type Canary struct {
+	StepWeight   int32         `json:"step_weight" yaml:"step_weight"`
+	StepInterval time.Duration `json:"step_interval" yaml:"step_interval"`
+	MaxErrorRate float64       `json:"max_error_rate" yaml:"max_error_rate"`
+	MaxLatency   time.Duration `json:"max_latency" yaml:"max_latency"` // not checked if 0
+	MinRequests  int32         `json:"min_requests" yaml:"min_requests"`
+}
+
+var updateStrategyFieldValidation = &cr.StructFieldValidation{
+	StructField: "UpdateStrategy",
+	StructValidation: &cr.StructValidation{
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "Mode",
+				StringValidation: &cr.StringValidation{
+					AllowedValues: UpdateModeStrings(),
+					Default:       RollingUpdateMode.String(),
+				},
+				Parser: func(str string) (interface{}, error) {
+					return UpdateModeFromString(str), nil
+				},
+			},
+			{
+				StructField: "PreviewEndpoint",
+				StringPtrValidation: &cr.StringPtrValidation{
+					Validator: urls.ValidateEndpoint,
+				},
+				AllowedIf: &cr.FieldCondition{Key: ModeKey, Values: []interface{}{BlueGreenUpdateMode}},
+			},
+			{
+				StructField: "Canary",
+				AllowedIf:   &cr.FieldCondition{Key: ModeKey, Values: []interface{}{CanaryUpdateMode}},
+				StructValidation: &cr.StructValidation{
+					StructFieldValidations: []*cr.StructFieldValidation{
+						{
+							StructField: "StepWeight",
+							Int32Validation: &cr.Int32Validation{
+								Default:           10,
+								GreaterThan:       pointer.Int32(0),
+								LessThanOrEqualTo: pointer.Int32(100),
+							},
+						},
+						{
+							StructField: "StepInterval",
+							DurationValidation: &cr.DurationValidation{
+								Default:              5 * time.Minute,
+								GreaterThanOrEqualTo: pointer.Duration(time.Minute),
+							},
+						},
+						{
+							StructField: "MaxErrorRate",
+							Float64Validation: &cr.Float64Validation{
+								Default:              0.01,
+								GreaterThanOrEqualTo: pointer.Float64(0),
+								LessThanOrEqualTo:    pointer.Float64(1),
+							},
+						},
+						{
+							StructField: "MaxLatency",
+							DurationValidation: &cr.DurationValidation{
+								GreaterThanOrEqualTo: pointer.Duration(0),
+							},
+						},
+						{
+							StructField: "MinRequests",
+							Int32Validation: &cr.Int32Validation{
+								Default:              1,
+								GreaterThanOrEqualTo: pointer.Int32(0),
+							},
+						},
+					},
+				},
+			},
+			{
+				StructField:    "RollbackOnCrashLoop",
+				BoolValidation: &cr.BoolValidation{},
+				AllowedIf:      &cr.FieldCondition{Key: ModeKey, Values: []interface{}{RollingUpdateMode}},
+			},
+			{
+				StructField: "ProgressDeadline",
+				DurationValidation: &cr.DurationValidation{
+					Default:              10 * time.Minute,
+					GreaterThanOrEqualTo: pointer.Duration(time.Minute),
+				},
+			},
+		},
+	},
+}
+
+func (updateStrategy *UpdateStrategy) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ModeKey, updateStrategy.Mode.String()))
+	if updateStrategy.PreviewEndpoint != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", PreviewEndpointKey, *updateStrategy.PreviewEndpoint))
+	}
+	if updateStrategy.Mode == CanaryUpdateMode && updateStrategy.Canary != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", CanaryKey))
+		sb.WriteString(s.Indent(updateStrategy.Canary.UserConfigStr(), "  "))
+	}
+	if updateStrategy.Mode == RollingUpdateMode {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", RollbackOnCrashLoopKey, s.Bool(updateStrategy.RollbackOnCrashLoop)))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ProgressDeadlineKey, updateStrategy.ProgressDeadline.String()))
+	return sb.String()
+}
+
+func (canary *Canary) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %d\n", StepWeightKey, canary.StepWeight))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", StepIntervalKey, canary.StepInterval.String()))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxErrorRateKey, s.Float64(canary.MaxErrorRate)))
+	if canary.MaxLatency > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxLatencyKey, canary.MaxLatency.String()))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %d\n", MinRequestsKey, canary.MinRequests))
+	return sb.String()
+}
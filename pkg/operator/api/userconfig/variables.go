@@ -0,0 +1,152 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/cortexlabs/cortex/pkg/lib/cast"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/yaml"
+)
+
+// Matches ${VAR} and ${VAR:-default}; $${VAR} is an escaped (literal) ${VAR}
+var variableRegex = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^{}]*))?\}`)
+
+// ReferencedVariables returns the names of the variables which are referenced in a configuration file
+func ReferencedVariables(configBytes []byte) []string {
+	names := strset.New()
+	for _, match := range variableRegex.FindAllStringSubmatch(string(configBytes), -1) {
+		if match[0][1] != '$' {
+			names.Add(match[1])
+		}
+	}
+	nameList := names.Slice()
+	sort.Strings(nameList)
+	return nameList
+}
+
+// ReadValuesFile reads a YAML map of variable names to (scalar) values
+func ReadValuesFile(filePath string) (map[string]string, error) {
+	valuesBytes, err := files.ReadFileBytes(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	valuesData, err := cr.ReadYAMLBytes(valuesBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, filePath)
+	}
+
+	valuesMap, ok := cast.InterfaceToStrInterfaceMap(valuesData)
+	if !ok {
+		return nil, errors.Wrap(cr.ErrorInvalidPrimitiveType(valuesData, cr.PrimTypeMap), filePath)
+	}
+
+	values := make(map[string]string, len(valuesMap))
+	for name, val := range valuesMap {
+		switch val.(type) {
+		case string:
+			values[name] = val.(string)
+		case int, int64, float64, bool:
+			values[name] = s.ObjFlatNoQuotes(val)
+		default:
+			return nil, errors.Wrap(cr.ErrorInvalidPrimitiveType(val, cr.PrimTypeScalars...), filePath, name)
+		}
+	}
+
+	return values, nil
+}
+
+// resolveVariables substitutes variables in all string values of the parsed configuration.
+// A string which consists of a single variable reference takes on the YAML type of the variable's value (e.g. replicas: ${REPLICAS}).
+func resolveVariables(val interface{}, variables map[string]string, unresolved strset.Set) interface{} {
+	switch typedVal := val.(type) {
+	case string:
+		return resolveVariablesInStr(typedVal, variables, unresolved)
+	case []interface{}:
+		resolved := make([]interface{}, len(typedVal))
+		for i, elem := range typedVal {
+			resolved[i] = resolveVariables(elem, variables, unresolved)
+		}
+		return resolved
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(typedVal))
+		for key, elem := range typedVal {
+			resolved[key] = resolveVariables(elem, variables, unresolved)
+		}
+		return resolved
+	case map[interface{}]interface{}:
+		resolved := make(map[interface{}]interface{}, len(typedVal))
+		for key, elem := range typedVal {
+			resolved[key] = resolveVariables(elem, variables, unresolved)
+		}
+		return resolved
+	}
+	return val
+}
+
+func resolveVariablesInStr(str string, variables map[string]string, unresolved strset.Set) interface{} {
+	matches := variableRegex.FindAllStringSubmatch(str, -1)
+	if len(matches) == 0 {
+		return str
+	}
+
+	resolve := func(match []string) string {
+		if match[0][1] == '$' {
+			return match[0][1:]
+		}
+		if value, ok := variables[match[1]]; ok {
+			return value
+		}
+		if match[2] != "" {
+			return match[3]
+		}
+		unresolved.Add(match[1])
+		return match[0]
+	}
+
+	if len(matches) == 1 && matches[0][0] == str && str[1] != '$' {
+		resolved := resolve(matches[0])
+		var parsed interface{}
+		if err := yaml.Unmarshal([]byte(resolved), &parsed); err == nil {
+			switch parsed.(type) {
+			case int, int64, float64, bool:
+				return parsed
+			}
+		}
+		return resolved
+	}
+
+	return variableRegex.ReplaceAllStringFunc(str, func(matchStr string) string {
+		return resolve(variableRegex.FindStringSubmatch(matchStr))
+	})
+}
+
+func unresolvedVariablesErr(unresolved strset.Set) error {
+	if len(unresolved) == 0 {
+		return nil
+	}
+	names := unresolved.Slice()
+	sort.Strings(names)
+	return ErrorUnresolvedVariables(names)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type Visibility int
+
+const (
+	UnknownVisibility Visibility = iota
+	PublicVisibility
+	PrivateVisibility
+)
+
+var visibilities = []string{
+	"unknown",
+	"public",
+	"private",
+}
+
+func VisibilityFromString(s string) Visibility {
+	for i := 0; i < len(visibilities); i++ {
+		if s == visibilities[i] {
+			return Visibility(i)
+		}
+	}
+	return UnknownVisibility
+}
+
+func VisibilityStrings() []string {
+	return visibilities[1:]
+}
+
+func (t Visibility) String() string {
+	return visibilities[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t Visibility) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *Visibility) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(visibilities); i++ {
+		if enum == visibilities[i] {
+			*t = Visibility(i)
+			return nil
+		}
+	}
+
+	*t = UnknownVisibility
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *Visibility) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t Visibility) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
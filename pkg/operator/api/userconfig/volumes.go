@@ -0,0 +1,188 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+var (
+	efsFileSystemIDRegex = regexp.MustCompile(`^fs-([0-9a-f]{8}|[0-9a-f]{17})$`)
+	fsxFileSystemIDRegex = regexp.MustCompile(`^fs-[0-9a-f]{17}$`)
+)
+
+type Volumes []*Volume
+
+type Volume struct {
+	Type         VolumeType `json:"type" yaml:"type"`
+	FileSystemID string     `json:"file_system_id" yaml:"file_system_id"`
+	MountPath    string     `json:"mount_path" yaml:"mount_path"`
+	SubPath      string     `json:"sub_path" yaml:"sub_path"`
+	MountName    *string    `json:"mount_name" yaml:"mount_name"`
+	ReadOnly     bool       `json:"read_only" yaml:"read_only"`
+}
+
+var volumesFieldValidation = &cr.StructFieldValidation{
+	StructField: "Volumes",
+	StructListValidation: &cr.StructListValidation{
+		AllowExplicitNull: true,
+		StructValidation: &cr.StructValidation{
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Type",
+					StringValidation: &cr.StringValidation{
+						Required:      true,
+						AllowedValues: VolumeTypeStrings(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return VolumeTypeFromString(str), nil
+					},
+				},
+				{
+					StructField: "FileSystemID",
+					StringValidation: &cr.StringValidation{
+						Required: true,
+					},
+				},
+				{
+					StructField: "MountPath",
+					StringValidation: &cr.StringValidation{
+						Required:  true,
+						Validator: validateMountPath,
+					},
+				},
+				{
+					StructField: "SubPath",
+					StringValidation: &cr.StringValidation{
+						Default:   "/",
+						Validator: ensureAbsolutePath,
+					},
+				},
+				{
+					StructField:         "MountName",
+					StringPtrValidation: &cr.StringPtrValidation{},
+					RequiredIf:          &cr.FieldCondition{Key: TypeKey, Values: []interface{}{FSxVolumeType}},
+					AllowedIf:           &cr.FieldCondition{Key: TypeKey, Values: []interface{}{FSxVolumeType}},
+				},
+				{
+					StructField: "ReadOnly",
+					BoolValidation: &cr.BoolValidation{
+						Default: true,
+					},
+				},
+			},
+		},
+	},
+}
+
+// Paths used by cortex inside of every API container
+func reservedMountPaths() []string {
+	return []string{
+		consts.EmptyDirMountPath,
+		filepath.Join(consts.EmptyDirMountPath, "project"),
+		filepath.Join(consts.EmptyDirMountPath, "model"),
+		consts.ContextCacheDir,
+	}
+}
+
+func validateMountPath(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return "", ErrorMountPathMustBeAbsolute(path)
+	}
+	path = filepath.Clean(path)
+
+	for _, reservedPath := range reservedMountPaths() {
+		// Mounting over (or above) a cortex path would hide it
+		if path == reservedPath || isSubPath(reservedPath, path) {
+			return "", ErrorReservedMountPath(path, reservedPath)
+		}
+		// Nesting within the shared empty dir is allowed, but not within cortex's subdirectories
+		if reservedPath != consts.EmptyDirMountPath && isSubPath(path, reservedPath) {
+			return "", ErrorReservedMountPath(path, reservedPath)
+		}
+	}
+
+	return path, nil
+}
+
+func isSubPath(path string, parent string) bool {
+	return parent == "/" || strings.HasPrefix(path, parent+"/")
+}
+
+func ensureAbsolutePath(path string) (string, error) {
+	return filepath.Clean(s.EnsurePrefix(path, "/")), nil
+}
+
+func (volume *Volume) Validate() error {
+	switch volume.Type {
+	case EFSVolumeType:
+		if !efsFileSystemIDRegex.MatchString(volume.FileSystemID) {
+			return errors.Wrap(ErrorInvalidFileSystemID(volume.FileSystemID, volume.Type), FileSystemIDKey)
+		}
+	case FSxVolumeType:
+		if !fsxFileSystemIDRegex.MatchString(volume.FileSystemID) {
+			return errors.Wrap(ErrorInvalidFileSystemID(volume.FileSystemID, volume.Type), FileSystemIDKey)
+		}
+	}
+
+	return nil
+}
+
+func (volumes Volumes) Validate() error {
+	mountPaths := strset.New()
+	for i, volume := range volumes {
+		if err := volume.Validate(); err != nil {
+			return errors.Wrap(err, s.Index(i))
+		}
+		if mountPaths.Has(volume.MountPath) {
+			return errors.Wrap(ErrorDuplicateMountPath(volume.MountPath), s.Index(i), MountPathKey)
+		}
+		mountPaths.Add(volume.MountPath)
+	}
+	return nil
+}
+
+func (volume *Volume) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", TypeKey, volume.Type.String()))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", FileSystemIDKey, volume.FileSystemID))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MountPathKey, volume.MountPath))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", SubPathKey, volume.SubPath))
+	if volume.MountName != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MountNameKey, *volume.MountName))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ReadOnlyKey, s.Bool(volume.ReadOnly)))
+	return sb.String()
+}
+
+func (volumes Volumes) UserConfigStr() string {
+	var sb strings.Builder
+	for _, volume := range volumes {
+		volumeStr := s.Indent(volume.UserConfigStr(), "  ")
+		sb.WriteString("- " + strings.TrimPrefix(volumeStr, "  "))
+	}
+	return sb.String()
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/endpoints"
+)
+
+const (
+	roleViewer   = "viewer"
+	roleDeployer = "deployer"
+)
+
+// requests which don't modify the cluster only require viewer access
+func requiredRole(r *http.Request) string {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return roleViewer
+	}
+	return roleDeployer
+}
+
+// authenticate returns the caller's identity, responding with an error if the request is not authenticated
+// "CortexSigV4 <url>" headers contain a presigned STS GetCallerIdentity request, so that the caller's secret access key isn't sent to the operator (the request is only valid if it was presigned for this cluster)
+// "CortexAWS <access key id>|<secret access key>" headers are accepted from older CLIs
+func authenticate(w http.ResponseWriter, r *http.Request) *aws.CallerIdentity {
+	authHeader := r.Header.Get("Authorization")
+
+	var identity *aws.CallerIdentity
+	var validCreds bool
+	var err error
+
+	switch {
+	case strings.HasPrefix(authHeader, "CortexSigV4 "):
+		identity, validCreds, err = aws.VerifyCallerIdentityRequest(strings.TrimPrefix(authHeader, "CortexSigV4 "), config.Cluster.ClusterName)
+	case strings.HasPrefix(authHeader, "CortexAWS "):
+		parts := strings.Split(strings.TrimPrefix(authHeader, "CortexAWS "), "|")
+		if len(parts) != 2 {
+			endpoints.RespondError(w, endpoints.ErrorAuthHeaderMalformed())
+			return nil
+		}
+		identity, validCreds, err = aws.GetCallerIdentity(parts[0], parts[1], *config.Cluster.Region)
+	default:
+		endpoints.RespondError(w, endpoints.ErrorAuthHeaderMissing())
+		return nil
+	}
+
+	if err != nil {
+		endpoints.RespondError(w, endpoints.ErrorAuthAPIError())
+		return nil
+	}
+	if !validCreds {
+		endpoints.RespondErrorCode(w, http.StatusForbidden, endpoints.ErrorAuthInvalid())
+		return nil
+	}
+	if identity.Account != config.AWS.AccountID {
+		endpoints.RespondErrorCode(w, http.StatusForbidden, endpoints.ErrorAuthOtherAccount())
+		return nil
+	}
+
+	return identity
+}
+
+// authorize responds with an error if the caller is not allowed to make the request
+func authorize(w http.ResponseWriter, r *http.Request, identity *aws.CallerIdentity) bool {
+	deployers := config.Cluster.OperatorDeployers
+	viewers := config.Cluster.OperatorViewers
+	admins := config.Cluster.OperatorAdmins
+
+	// every principal in the account is a deployer unless access has been configured
+	if len(deployers) == 0 && len(viewers) == 0 && len(admins) == 0 {
+		return true
+	}
+
+	role := requiredRole(r)
+
+	if aws.PrincipalMatches(admins, identity.ARN) || aws.PrincipalMatches(deployers, identity.ARN) {
+		return true
+	}
+	if role == roleViewer && aws.PrincipalMatches(viewers, identity.ARN) {
+		return true
+	}
+
+	endpoints.RespondErrorCode(w, http.StatusForbidden, endpoints.ErrorAuthPrincipalNotAllowed(identity.ARN, role))
+	return false
+}
@@ -17,7 +17,6 @@ limitations under the License.
 package config
 
 import (
-	"fmt"
 	"os"
 	"strings"
 
@@ -26,10 +25,11 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
 	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
-	"github.com/cortexlabs/cortex/pkg/lib/exit"
 	"github.com/cortexlabs/cortex/pkg/lib/hash"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/lib/tracing"
 )
 
 var (
@@ -37,6 +37,12 @@ var (
 	AWS             *aws.Client
 	Kubernetes      *k8s.Client
 	IstioKubernetes *k8s.Client
+
+	// IngressKubernetes is set if the cluster uses the ingress backend (its namespace is the ingress controller's)
+	IngressKubernetes *k8s.Client
+
+	// GrafanaKubernetes is set if the cluster provisions Grafana dashboards (its namespace is the one which Grafana's sidecar watches)
+	GrafanaKubernetes *k8s.Client
 )
 
 func Init() error {
@@ -57,11 +63,16 @@ func Init() error {
 		return errors.FirstError(errs...)
 	}
 
+	// the level can be changed at runtime via the operator's /logging/level endpoint
+	if level, err := logging.ParseLevel(Cluster.OperatorLogLevel); err == nil {
+		logging.SetLevel(level)
+	}
+
 	Cluster.ID = hash.String(*Cluster.Bucket + *Cluster.Region + Cluster.LogGroup)
 
 	AWS, err = aws.New(*Cluster.Region, *Cluster.Bucket, true)
 	if err != nil {
-		exit.Error(err)
+		return err
 	}
 
 	err = telemetry.Init(telemetry.Config{
@@ -72,7 +83,18 @@ func Init() error {
 		BlockDuplicateErrors: true,
 	})
 	if err != nil {
-		fmt.Println(err.Error())
+		logging.PrintError(err, "telemetry")
+	}
+
+	if Cluster.Tracing != nil {
+		err = tracing.Init(tracing.Config{
+			Endpoint:    Cluster.Tracing.Endpoint,
+			ServiceName: "operator",
+			SampleRate:  Cluster.Tracing.SampleRate,
+		})
+		if err != nil {
+			logging.PrintError(err, "tracing")
+		}
 	}
 
 	Cluster.InstanceMetadata = aws.InstanceMetadatas[*Cluster.Region][*Cluster.InstanceType]
@@ -81,9 +103,27 @@ func Init() error {
 		return err
 	}
 
+	// deployments, pods, and virtual services are listed from the informers' caches (the operator runs until it exits, so the informers are never stopped)
+	if err := Kubernetes.StartInformers(make(chan struct{})); err != nil {
+		return err
+	}
+
 	if IstioKubernetes, err = k8s.New("istio-system", Cluster.OperatorInCluster); err != nil {
 		return err
 	}
 
+	if Cluster.IngressBackend == clusterconfig.KubernetesIngressBackend {
+		ingressNamespace, _, _ := clusterconfig.SplitNamespacedName(Cluster.IngressService)
+		if IngressKubernetes, err = k8s.New(ingressNamespace, Cluster.OperatorInCluster); err != nil {
+			return err
+		}
+	}
+
+	if Cluster.Grafana != nil {
+		if GrafanaKubernetes, err = k8s.New(Cluster.Grafana.Namespace, Cluster.OperatorInCluster); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,55 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package context
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+)
+
+// k8s objects are limited to 1MiB, and a config map's binary data is base64 encoded
+const _maxConfigMapFilesBytes = 700 * 1024
+
+// assignConfigMapFiles chooses which of each API's files are stored in its config map (in order, until the config map is full);
+// the rest are mounted from the project, which the API's replicas download from S3
+func assignConfigMapFiles(apis context.APIs, projectBytes []byte) error {
+	var projectFileMap map[string][]byte
+	for _, api := range apis {
+		if len(api.Files) == 0 {
+			continue
+		}
+
+		if projectFileMap == nil {
+			var err error
+			projectFileMap, err = zip.UnzipMemToMem(projectBytes)
+			if err != nil {
+				return err
+			}
+		}
+
+		var totalBytes int
+		for i, file := range api.Files {
+			fileBytes := len(projectFileMap[file.Path])
+			if totalBytes+fileBytes > _maxConfigMapFilesBytes {
+				continue
+			}
+			totalBytes += fileBytes
+			api.ConfigMapFiles = append(api.ConfigMapFiles, i)
+		}
+	}
+	return nil
+}
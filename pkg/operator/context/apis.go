@@ -35,7 +35,38 @@ func getAPIs(config *userconfig.Config, deploymentVersion string, projectID stri
 		buf.WriteString(*apiConfig.Endpoint)
 		buf.WriteString(s.Obj(apiConfig.Tracker))
 		buf.WriteString(deploymentVersion)
-		buf.WriteString(s.Obj(apiConfig.Predictor))
+		predictor := apiConfig.Predictor
+		if predictor.ConfigReload {
+			// the rest of the config is reloaded by the API's replicas, so changing it doesn't roll them
+			predictorCopy := *predictor
+			predictorCopy.Config = predictor.StartupConfig()
+			predictor = &predictorCopy
+		}
+		buf.WriteString(s.Obj(predictor))
+		buf.WriteString(s.Obj(apiConfig.Init))
+		buf.WriteString(s.Obj(apiConfig.Sidecars))
+		buf.WriteString(s.Obj(apiConfig.Volumes))
+		buf.WriteString(s.Obj(apiConfig.Networking))
+		buf.WriteString(s.Obj(apiConfig.UpdateStrategy))
+		buf.WriteString(s.Obj(apiConfig.Experiment))
+		if apiConfig.Pipeline != nil {
+			buf.WriteString(s.Obj(apiConfig.Pipeline))
+		}
+		if apiConfig.PredictionLogging != nil {
+			buf.WriteString(s.Obj(apiConfig.PredictionLogging)) // only included when it's configured, so that other APIs' IDs don't change
+		}
+		if apiConfig.Feedback != nil {
+			buf.WriteString(s.Obj(apiConfig.Feedback))
+		}
+		if apiConfig.Logs != nil {
+			buf.WriteString(apiConfig.Logs.Format) // the retention is applied to the API's log group by the operator, so changing it doesn't roll the API's replicas
+		}
+		if len(apiConfig.Files) > 0 {
+			buf.WriteString(s.Obj(apiConfig.Files))
+		}
+		if len(apiConfig.DependsOn) > 0 {
+			buf.WriteString(s.Obj(apiConfig.DependsOn))
+		}
 		buf.WriteString(projectID)
 
 		id := hash.Bytes(buf.Bytes())
@@ -49,5 +80,19 @@ func getAPIs(config *userconfig.Config, deploymentVersion string, projectID stri
 			API: apiConfig,
 		}
 	}
+
+	// colocated pipelines run their steps' containers, so they are redeployed when their steps' APIs change
+	for _, api := range apis {
+		if api.Pipeline == nil || api.Pipeline.Mode != userconfig.ColocatedPipelineMode {
+			continue
+		}
+		var buf bytes.Buffer
+		buf.WriteString(api.ID)
+		for _, stepAPIName := range api.Pipeline.StepAPINames() {
+			buf.WriteString(apis[stepAPIName].ID)
+		}
+		api.ID = hash.Bytes(buf.Bytes())
+	}
+
 	return apis, nil
 }
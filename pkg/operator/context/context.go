@@ -17,6 +17,7 @@ limitations under the License.
 package context
 
 import (
+	"bytes"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -24,6 +25,8 @@ import (
 
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
 	"github.com/cortexlabs/cortex/pkg/operator/api/context"
 	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
@@ -69,12 +72,21 @@ func New(
 	}
 	ctx.APIs = apis
 
+	if err := assignConfigMapFiles(ctx.APIs, projectBytes); err != nil {
+		return nil, err
+	}
+
 	ctx.ProjectID = projectID
 	ctx.ProjectKey = filepath.Join(consts.ProjectsDir, ctx.ProjectID+".zip")
 	if err = config.AWS.UploadBytesToS3(projectBytes, ctx.ProjectKey); err != nil {
 		return nil, err
 	}
 
+	ctx.CondaEnvID, err = condaEnvID(projectBytes)
+	if err != nil {
+		return nil, err
+	}
+
 	err = ctx.Validate()
 	if err != nil {
 		return nil, err
@@ -85,6 +97,24 @@ func New(
 	return ctx, nil
 }
 
+// condaEnvID identifies the conda environment which is defined by the project's conda files (empty if there are none)
+func condaEnvID(projectBytes []byte) (string, error) {
+	projectFileMap, err := zip.UnzipMemToMem(projectBytes)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	for _, fileName := range userconfig.CondaFileNames(projectFileMap) {
+		buf.WriteString(fileName)
+		buf.Write(projectFileMap[fileName])
+	}
+	if buf.Len() == 0 {
+		return "", nil
+	}
+	return hash.Bytes(buf.Bytes()), nil
+}
+
 func ctxKey(ctxID string, appName string) string {
 	return filepath.Join(
 		consts.AppsDir,
@@ -146,6 +176,38 @@ func LatestWorkloadIDKey(resourceID string, appName string) string {
 	)
 }
 
+// APIRevisionKey is the key of the configuration which the API was deployed with at the revision
+func APIRevisionKey(apiName string, revision int64, appName string) string {
+	return filepath.Join(
+		consts.AppsDir,
+		appName,
+		consts.APIRevisionsDir,
+		apiName,
+		s.Int64(revision)+".json",
+	)
+}
+
+// ProfileKey is the key of a profile of one of the API's replicas
+func ProfileKey(apiName string, fileName string, appName string) string {
+	return filepath.Join(
+		consts.AppsDir,
+		appName,
+		consts.ProfilesDir,
+		apiName,
+		fileName,
+	)
+}
+
+// ExecAuditKey is the key of the audit record of a command which was run in an API replica (records are grouped by day, since they aren't specific to a deployment)
+func ExecAuditKey(auditID string, t time.Time) string {
+	return filepath.Join(
+		consts.AuditDir,
+		"exec",
+		t.UTC().Format("2006-01-02"),
+		t.UTC().Format("20060102T150405Z")+"_"+auditID+".json",
+	)
+}
+
 func BaseWorkloadKey(workloadID string, appName string) string {
 	return filepath.Join(
 		consts.AppsDir,
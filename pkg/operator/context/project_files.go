@@ -0,0 +1,161 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package context
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// Project files are stored in S3 by the hashes of their contents, so the CLI only uploads the files which the operator hasn't stored yet
+
+const _projectFileRequestsLimit = 16
+
+// the hashes of the files which are known to be in the store
+var storedProjectFiles = struct {
+	s strset.Set
+	sync.RWMutex
+}{s: strset.New()}
+
+func projectFileKey(fileHash string) string {
+	return filepath.Join(consts.ProjectFilesDir, fileHash)
+}
+
+func isProjectFileStored(fileHash string) bool {
+	storedProjectFiles.RLock()
+	defer storedProjectFiles.RUnlock()
+	return storedProjectFiles.s.Has(fileHash)
+}
+
+func cacheStoredProjectFile(fileHash string) {
+	storedProjectFiles.Lock()
+	defer storedProjectFiles.Unlock()
+	storedProjectFiles.s.Add(fileHash)
+}
+
+// MissingProjectFiles returns the hashes which aren't in the project file store (sorted)
+func MissingProjectFiles(fileHashes []string) ([]string, error) {
+	uncheckedHashes := strset.New()
+	for _, fileHash := range fileHashes {
+		if !isProjectFileStored(fileHash) {
+			uncheckedHashes.Add(fileHash)
+		}
+	}
+
+	missingHashes := strset.New()
+	var missingHashesMutex sync.Mutex
+
+	fns := make([]func() error, 0, len(uncheckedHashes))
+	for fileHash := range uncheckedHashes {
+		fileHash := fileHash
+		fns = append(fns, func() error {
+			isStored, err := config.AWS.IsS3File(projectFileKey(fileHash))
+			if err != nil {
+				return err
+			}
+			if isStored {
+				cacheStoredProjectFile(fileHash)
+				return nil
+			}
+			missingHashesMutex.Lock()
+			missingHashes.Add(fileHash)
+			missingHashesMutex.Unlock()
+			return nil
+		})
+	}
+	if err := errors.FirstError(parallel.RunWithLimit(_projectFileRequestsLimit, fns...)...); err != nil {
+		return nil, err
+	}
+
+	missing := missingHashes.Slice()
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// StoreProjectFiles uploads files (keyed by the hashes of their contents) to the project file store
+func StoreProjectFiles(projectFiles map[string][]byte) error {
+	fns := make([]func() error, 0, len(projectFiles))
+	for fileHash, fileBytes := range projectFiles {
+		fileHash, fileBytes := fileHash, fileBytes
+		if isProjectFileStored(fileHash) {
+			continue
+		}
+		fns = append(fns, func() error {
+			if err := config.AWS.UploadBytesToS3(fileBytes, projectFileKey(fileHash)); err != nil {
+				return err
+			}
+			cacheStoredProjectFile(fileHash)
+			return nil
+		})
+	}
+	return errors.FirstError(parallel.RunWithLimit(_projectFileRequestsLimit, fns...)...)
+}
+
+// AssembleProject zips the files in the manifest (path -> hash of the file's contents); files which aren't in newFiles (keyed by hash) are read from the project file store
+func AssembleProject(manifest map[string]string, newFiles map[string][]byte) ([]byte, error) {
+	paths := make([]string, 0, len(manifest))
+	for path := range manifest {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths) // the project's ID is the hash of the zip, so the files are always added in the same order
+
+	storedFiles := make(map[string][]byte)
+	var storedFilesMutex sync.Mutex
+
+	fns := []func() error{}
+	for _, fileHash := range manifest {
+		fileHash := fileHash
+		if _, ok := newFiles[fileHash]; ok {
+			continue
+		}
+		if _, ok := storedFiles[fileHash]; ok {
+			continue
+		}
+		storedFiles[fileHash] = nil
+		fns = append(fns, func() error {
+			fileBytes, err := config.AWS.ReadBytesFromS3(projectFileKey(fileHash))
+			if err != nil {
+				return err
+			}
+			storedFilesMutex.Lock()
+			storedFiles[fileHash] = fileBytes
+			storedFilesMutex.Unlock()
+			return nil
+		})
+	}
+	if err := errors.FirstError(parallel.RunWithLimit(_projectFileRequestsLimit, fns...)...); err != nil {
+		return nil, err
+	}
+
+	zipInput := &zip.Input{Bytes: make([]zip.BytesInput, len(paths))}
+	for i, path := range paths {
+		fileBytes, ok := newFiles[manifest[path]]
+		if !ok {
+			fileBytes = storedFiles[manifest[path]]
+		}
+		zipInput.Bytes[i] = zip.BytesInput{Content: fileBytes, Dest: path}
+	}
+	return zip.ToMem(zipInput)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	ocontext "github.com/cortexlabs/cortex/pkg/operator/context"
+	"github.com/cortexlabs/cortex/pkg/operator/workloads"
+)
+
+func GetAPIDiff(w http.ResponseWriter, r *http.Request) {
+	apiName, err := getRequiredPathParam("apiName", r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	appName, err := getRequiredQueryParam("appName", r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	ctx := workloads.CurrentContext(appName)
+	if ctx == nil {
+		RespondError(w, ErrorAppNotDeployed(appName))
+		return
+	}
+
+	api := ctx.APIs[apiName]
+	if api == nil {
+		RespondError(w, ErrorAPINotDeployed(apiName, appName))
+		return
+	}
+
+	// the route's query params have already been validated as integers
+	to := api.Revision
+	if toStr := getOptionalQParam("to", r); toStr != "" {
+		to, _ = s.ParseInt64(toStr)
+	}
+	from := to - 1
+	if fromStr := getOptionalQParam("from", r); fromStr != "" {
+		from, _ = s.ParseInt64(fromStr)
+	}
+
+	fromSpec, err := readAPIRevision(apiName, from, appName)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+	toSpec, err := readAPIRevision(apiName, to, appName)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	diffs, err := json.Diff(fromSpec, toSpec)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	Respond(w, schema.APIDiffResponse{
+		APIName: apiName,
+		From:    from,
+		To:      to,
+		Diffs:   apiSpecDiffs(diffs),
+	})
+}
+
+func readAPIRevision(apiName string, revision int64, appName string) (*userconfig.API, error) {
+	key := ocontext.APIRevisionKey(apiName, revision, appName)
+	isFile, err := config.AWS.IsS3File(key)
+	if err != nil {
+		return nil, err
+	}
+	if !isFile {
+		return nil, ErrorAPIRevisionNotFound(apiName, revision)
+	}
+
+	var api userconfig.API
+	if err := config.AWS.ReadJSONFromS3(&api, key); err != nil {
+		return nil, err
+	}
+	return &api, nil
+}
+
+// apiSpecDiffs excludes the fields which describe where the API is defined in the configuration files rather than how it's deployed
+func apiSpecDiffs(diffs []json.FieldDiff) []json.FieldDiff {
+	filtered := []json.FieldDiff{}
+	for _, diff := range diffs {
+		if diff.Path == "index" || diff.Path == "file_path" {
+			continue
+		}
+		filtered = append(filtered, diff)
+	}
+	return filtered
+}
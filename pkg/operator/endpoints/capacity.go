@@ -0,0 +1,49 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/workloads"
+)
+
+func GetCapacity(w http.ResponseWriter, r *http.Request) {
+	nodeGroups, err := workloads.GetNodeGroupCapacities()
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	response := schema.CapacityResponse{
+		NodeGroups: make([]schema.NodeGroupCapacity, len(nodeGroups)),
+	}
+	for i, nodeGroup := range nodeGroups {
+		response.NodeGroups[i] = schema.NodeGroupCapacity{
+			Name:         nodeGroup.Name,
+			InstanceType: nodeGroup.InstanceType,
+			Arch:         nodeGroup.Arch,
+			CPU:          nodeGroup.CPU.String(),
+			Mem:          nodeGroup.Mem.String(),
+			GPU:          nodeGroup.GPU,
+			CPUReserve:   nodeGroup.Reserve.CPU.String(),
+			MemReserve:   nodeGroup.Reserve.Mem.String(),
+		}
+	}
+	Respond(w, response)
+}
@@ -0,0 +1,293 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/cortexlabs/yaml"
+	kadmission "k8s.io/api/admission/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/workloads"
+)
+
+const (
+	_cortexAPISyncInterval = 10 * time.Second
+
+	// CortexAPI spec fields which aren't API fields
+	_cortexAPIDeploymentKey = "deployment"
+	_cortexAPIProjectKey    = "project"
+
+	_cortexAPIDeployedState = "deployed"
+	_cortexAPIErrorState    = "error"
+)
+
+// The hash of the CortexAPI specs which were most recently deployed for each deployment
+var _syncedCortexAPIs = make(map[string]string)
+
+// RunCortexAPIController periodically deploys the APIs which are defined by CortexAPI custom resources (grouped by their deployment),
+// and deletes deployments whose CortexAPIs have all been deleted
+func RunCortexAPIController() {
+	for {
+		if err := syncCortexAPIs(); err != nil {
+			err = errors.Wrap(err, "cortexapi controller")
+			telemetry.Error(err)
+			logging.PrintError(err)
+		}
+		time.Sleep(_cortexAPISyncInterval)
+	}
+}
+
+func syncCortexAPIs() error {
+	cortexAPIs, err := config.Kubernetes.ListCortexAPIs(nil)
+	if err != nil {
+		if kerrors.IsNotFound(errors.Cause(err)) {
+			return nil // the CRD isn't installed
+		}
+		return err
+	}
+
+	cortexAPIsByApp := make(map[string][]kunstructured.Unstructured)
+	for _, cortexAPI := range cortexAPIs {
+		appName, _, _ := kunstructured.NestedString(cortexAPI.Object, "spec", _cortexAPIDeploymentKey)
+		cortexAPIsByApp[appName] = append(cortexAPIsByApp[appName], cortexAPI)
+	}
+
+	for appName, appCortexAPIs := range cortexAPIsByApp {
+		if err := syncCortexAPIDeployment(appName, appCortexAPIs); err != nil {
+			err = errors.Wrap(err, "cortexapi controller")
+			telemetry.Error(err)
+			logging.With(logging.Fields{"app": appName}).Error(err)
+		}
+	}
+
+	for _, ctx := range workloads.CurrentContexts() {
+		if _, ok := cortexAPIsByApp[ctx.App.Name]; ok || !isManagedByCortexAPIs(ctx) {
+			continue
+		}
+		unlock := lockApp(ctx.App.Name)
+		workloads.DeleteApp(ctx.App.Name, false)
+		unlock()
+		delete(_syncedCortexAPIs, ctx.App.Name)
+		logging.With(logging.Fields{"app": ctx.App.Name}).Info("deleted deployment (its CortexAPIs were deleted)")
+	}
+
+	return nil
+}
+
+// isManagedByCortexAPIs returns true if every API in the deployment was created from a CortexAPI
+func isManagedByCortexAPIs(ctx *context.Context) bool {
+	if len(ctx.APIs) == 0 {
+		return false
+	}
+	for _, api := range ctx.APIs {
+		if api.Owner != _cortexAPIPrincipal {
+			return false
+		}
+	}
+	return true
+}
+
+func syncCortexAPIDeployment(appName string, cortexAPIs []kunstructured.Unstructured) error {
+	sort.Slice(cortexAPIs, func(i, j int) bool {
+		return cortexAPIs[i].GetName() < cortexAPIs[j].GetName()
+	})
+
+	specs := make([]interface{}, len(cortexAPIs))
+	for i, cortexAPI := range cortexAPIs {
+		specs[i] = []interface{}{cortexAPI.GetName(), cortexAPI.Object["spec"]}
+	}
+	specsHash := hash.Any(specs)
+	if _syncedCortexAPIs[appName] == specsHash {
+		return nil
+	}
+	// failed deploys are not retried until a CortexAPI changes (or the operator restarts)
+	_syncedCortexAPIs[appName] = specsHash
+
+	response, err := deployCortexAPIs(appName, cortexAPIs)
+	if err != nil {
+		return updateCortexAPIStatuses(cortexAPIs, nil, err)
+	}
+
+	ctx := response.Context
+	if ctx == nil {
+		ctx = workloads.CurrentContext(appName)
+	}
+	return updateCortexAPIStatuses(cortexAPIs, ctx, nil)
+}
+
+func deployCortexAPIs(appName string, cortexAPIs []kunstructured.Unstructured) (*schema.DeployResponse, error) {
+	configFiles, projectPath, err := cortexAPIConfigFiles(appName, cortexAPIs)
+	if err != nil {
+		return nil, err
+	}
+
+	var projectBytes []byte
+	if projectPath != "" {
+		projectBytes, err = config.AWS.ReadBytesFromS3Path(projectPath)
+	} else {
+		projectBytes, err = zip.ToMem(&zip.Input{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response, _, err := deploy(&deployRequest{
+		ConfigFiles:  configFiles,
+		ProjectBytes: projectBytes,
+		Variables:    map[string]string{},
+		CallerARN:    _cortexAPIPrincipal,
+		Force:        true, // the CortexAPIs are the source of truth
+		Prune:        true,
+		Log:          newRequestLogger(logging.Fields{"source": "cortexapi controller"}),
+	})
+	return response, err
+}
+
+// cortexAPIConfigFiles converts CortexAPIs to configuration files (one per CortexAPI, along with cortex.yaml for the deployment), and returns the S3 path of their project
+func cortexAPIConfigFiles(appName string, cortexAPIs []kunstructured.Unstructured) (map[string][]byte, string, error) {
+	appConfigBytes, err := yaml.Marshal([]map[string]interface{}{
+		{
+			userconfig.KindKey: resource.AppType.String(),
+			userconfig.NameKey: appName,
+		},
+	})
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+	configFiles := map[string][]byte{
+		"cortex.yaml": appConfigBytes,
+	}
+
+	var projectPath string
+	for _, cortexAPI := range cortexAPIs {
+		spec, _, _ := kunstructured.NestedMap(cortexAPI.Object, "spec")
+		if spec == nil {
+			spec = make(map[string]interface{})
+		}
+
+		if project, _ := spec[_cortexAPIProjectKey].(string); project != "" {
+			if projectPath != "" && projectPath != project {
+				return nil, "", ErrorConflictingCortexAPIProjects(appName, projectPath, project)
+			}
+			projectPath = project
+		}
+
+		delete(spec, _cortexAPIDeploymentKey)
+		delete(spec, _cortexAPIProjectKey)
+		spec[userconfig.KindKey] = resource.APIType.String()
+		spec[userconfig.NameKey] = cortexAPI.GetName()
+
+		apiConfigBytes, err := yaml.Marshal([]map[string]interface{}{spec})
+		if err != nil {
+			return nil, "", errors.WithStack(err)
+		}
+		configFiles["CortexAPI/"+cortexAPI.GetName()] = apiConfigBytes
+	}
+
+	return configFiles, projectPath, nil
+}
+
+func updateCortexAPIStatuses(cortexAPIs []kunstructured.Unstructured, ctx *context.Context, deployErr error) error {
+	for i := range cortexAPIs {
+		cortexAPI := &cortexAPIs[i]
+		status := map[string]interface{}{
+			"observedGeneration": cortexAPI.GetGeneration(),
+			"updatedAt":          time.Now().UTC().Format(time.RFC3339),
+		}
+
+		if deployErr != nil {
+			status["state"] = _cortexAPIErrorState
+			status["message"] = deployErr.Error()
+		} else if ctx != nil && ctx.APIs[cortexAPI.GetName()] != nil {
+			api := ctx.APIs[cortexAPI.GetName()]
+			status["state"] = _cortexAPIDeployedState
+			status["revision"] = api.Revision
+			status["endpoint"] = *api.Endpoint
+		}
+
+		if err := config.Kubernetes.UpdateCortexAPIStatus(cortexAPI, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateCortexAPI is the handler for the CortexAPI validating admission webhook; it validates the API's configuration
+// (checks which depend on the API's project files are performed when the API is deployed, and are reported in its status)
+func ValidateCortexAPI(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		RespondError(w, errors.WithStack(err))
+		return
+	}
+
+	var review kadmission.AdmissionReview
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		RespondError(w, ErrorInvalidAdmissionReview())
+		return
+	}
+
+	review.Response = &kadmission.AdmissionResponse{
+		UID:     review.Request.UID,
+		Allowed: true,
+	}
+
+	if err := validateCortexAPI(review.Request.Object.Raw); err != nil {
+		review.Response.Allowed = false
+		review.Response.Result = &kmeta.Status{
+			Status:  kmeta.StatusFailure,
+			Message: err.Error(),
+		}
+	}
+	review.Request = nil
+
+	Respond(w, review)
+}
+
+func validateCortexAPI(objectBytes []byte) error {
+	var cortexAPI kunstructured.Unstructured
+	if err := cortexAPI.UnmarshalJSON(objectBytes); err != nil {
+		return errors.WithStack(err)
+	}
+
+	appName, _, _ := kunstructured.NestedString(cortexAPI.Object, "spec", _cortexAPIDeploymentKey)
+	configFiles, _, err := cortexAPIConfigFiles(appName, []kunstructured.Unstructured{cortexAPI})
+	if err != nil {
+		return err
+	}
+
+	_, err = userconfig.NewFromFiles(configFiles, map[string]string{})
+	return err
+}
@@ -32,6 +32,18 @@ func Delete(w http.ResponseWriter, r *http.Request) {
 
 	keepCache := getOptionalBoolQParam("keepCache", false, r)
 
+	unlock := lockApp(appName)
+	defer unlock()
+
+	if ctx := workloads.CurrentContext(appName); ctx != nil {
+		for apiName, api := range ctx.APIs {
+			if !canModifyAPI(api, callerARN(r)) {
+				RespondErrorCode(w, http.StatusForbidden, ErrorAPINotOwned(apiName, api.Owner))
+				return
+			}
+		}
+	}
+
 	wasDeployed := workloads.DeleteApp(appName, keepCache)
 
 	if !wasDeployed {
@@ -17,14 +17,22 @@ limitations under the License.
 package endpoints
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/tracing"
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
 	"github.com/cortexlabs/cortex/pkg/operator/api/context"
 	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
 	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
@@ -34,47 +42,237 @@ import (
 	"github.com/cortexlabs/cortex/pkg/operator/workloads"
 )
 
+type deployRequest struct {
+	ConfigFiles       map[string][]byte
+	UserConfig        *userconfig.Config // used instead of ConfigFiles if it's set (e.g. when a deployment's configuration is redeployed)
+	ProjectBytes      []byte
+	NewProjectFiles   map[string][]byte // files (keyed by the hashes of their contents) which are added to the project file store once the deploy is validated
+	Variables         map[string]string
+	ExpectedRevisions map[string]int64 // API name -> the revision which the caller last deployed
+	CallerARN         string
+	GitCommit         string
+	Force             bool
+	Prune             bool // delete the deployment's APIs which aren't defined in the configuration (true unless the prune param is false, in which case the deploy is rejected if there are any)
+	DryRun            bool // validate the deploy and describe its changes, without applying them
+	IgnoreCache       bool
+	CheckRequirements bool
+	FreezeOverride    string          // the token which allows the deploy while the cluster's deploy freeze is active
+	Log               *logging.Logger // includes the ID of the request (or sync) which triggered the deploy
+	TraceParent       string          // the deploy's spans are children of this span (a traceparent header), or a new trace if it's empty
+}
+
 func Deploy(w http.ResponseWriter, r *http.Request) {
-	ignoreCache := getOptionalBoolQParam("ignoreCache", false, r)
-	force := getOptionalBoolQParam("force", false, r)
+	req, err := readDeployRequest(w, r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
 
-	configBytes, err := files.ReadReqFile(r, "cortex.yaml")
+	response, errCode, err := deploy(req)
 	if err != nil {
-		RespondError(w, errors.WithStack(err))
+		RespondErrorCode(w, errCode, err)
 		return
 	}
 
+	Respond(w, response)
+}
+
+// readDeployRequest reads the deploy's configuration, project, and options from the request's multipart form and query params
+func readDeployRequest(w http.ResponseWriter, r *http.Request) (*deployRequest, error) {
+	configBytes, err := files.ReadReqFile(r, "cortex.yaml")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
 	if len(configBytes) == 0 {
-		RespondError(w, ErrorFormFileMustBeProvided("cortex.yaml"))
-		return
+		return nil, ErrorFormFileMustBeProvided("cortex.yaml")
 	}
 
 	projectBytes, err := files.ReadReqFile(r, "project.zip")
 
-	userconf, err := userconfig.New("cortex.yaml", configBytes)
+	// the CLI uploads a manifest of the project's files (and the contents of the files which the operator hasn't stored) rather than a zip
+	var newProjectFiles map[string][]byte
+	if len(projectBytes) == 0 {
+		manifest, newFiles, err := readProjectFiles(r)
+		if err != nil {
+			return nil, err
+		}
+		if manifest != nil {
+			projectBytes, err = assembleProject(manifest, newFiles)
+			if err != nil {
+				return nil, err
+			}
+			newProjectFiles = newFiles
+		}
+	}
+
+	configFiles, err := files.ReadReqFilesWithPrefix(r, userconfig.ConfigDirName+"/")
 	if err != nil {
-		RespondError(w, err)
-		return
+		return nil, err
 	}
+	configFiles["cortex.yaml"] = configBytes
 
-	err = userconf.Validate(projectBytes)
+	variables := map[string]string{}
+	variablesBytes, err := files.ReadReqFile(r, "variables.json")
 	if err != nil {
-		RespondError(w, err)
-		return
+		return nil, err
+	}
+	if len(variablesBytes) > 0 {
+		if err := json.Unmarshal(variablesBytes, &variables); err != nil {
+			return nil, err
+		}
 	}
 
-	ctx, err := ocontext.New(userconf, projectBytes, ignoreCache)
+	freezeOverrideBytes, err := files.ReadReqFile(r, "freeze_override")
 	if err != nil {
-		RespondError(w, err)
-		return
+		return nil, err
+	}
+
+	var expectedRevisions map[string]int64
+	revisionsBytes, err := files.ReadReqFile(r, "revisions.json")
+	if err != nil {
+		return nil, err
+	}
+	if len(revisionsBytes) > 0 {
+		if err := json.Unmarshal(revisionsBytes, &expectedRevisions); err != nil {
+			return nil, err
+		}
+	}
+
+	return &deployRequest{
+		ConfigFiles:       configFiles,
+		ProjectBytes:      projectBytes,
+		NewProjectFiles:   newProjectFiles,
+		Variables:         variables,
+		ExpectedRevisions: expectedRevisions,
+		CallerARN:         callerARN(r),
+		Force:             getOptionalBoolQParam("force", false, r),
+		Prune:             getOptionalBoolQParam("prune", true, r),
+		DryRun:            getOptionalBoolQParam("dryRun", false, r),
+		IgnoreCache:       getOptionalBoolQParam("ignoreCache", false, r),
+		CheckRequirements: getOptionalBoolQParam("checkRequirements", false, r),
+		FreezeOverride:    string(freezeOverrideBytes),
+		Log:               RequestLogger(w),
+		TraceParent:       r.Header.Get(tracing.TraceParentHeader),
+	}, nil
+}
+
+// deploy validates and runs the deployment, and returns the response (or the error and its HTTP status code)
+func deploy(req *deployRequest) (*schema.DeployResponse, int, error) {
+	span := tracing.StartSpan("deploy", tracing.ParseTraceParent(req.TraceParent))
+	response, errCode, err := tracedDeploy(req, span)
+	span.Finish(err)
+	return response, errCode, err
+}
+
+// tracedDeploy records the validation, S3, and kubernetes steps of the deploy as children of span
+func tracedDeploy(req *deployRequest, span *tracing.Span) (*schema.DeployResponse, int, error) {
+	userconf := req.UserConfig
+	if userconf == nil {
+		step := span.StartChild("parse config")
+		var err error
+		userconf, err = userconfig.NewFromFiles(req.ConfigFiles, req.Variables)
+		step.Finish(err)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+
+	// the CLI only uploads the files which are included, but projects from other sources (e.g. GitOps) aren't filtered yet
+	projectBytes, err := userconf.App.Project.Filter(req.ProjectBytes)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(err, userconfig.ProjectKey)
+	}
+	req.ProjectBytes = projectBytes
+
+	warnings := make([]string, len(userconf.Warnings))
+	for i, warning := range userconf.Warnings {
+		warnings[i] = warning.Error()
+	}
+
+	// the APIs which only select other clusters are deployed by those clusters' operators (e.g. from a federated deploy)
+	var excludedAPIs []string
+	userconf.APIs, excludedAPIs = userconf.APIs.SelectCluster(config.Cluster.ClusterName)
+	if len(excludedAPIs) > 0 {
+		warnings = append(warnings, fmt.Sprintf("the %s api(s) are not deployed to this cluster (%s), since their %s don't include it", s.StrsAnd(excludedAPIs), config.Cluster.ClusterName, userconfig.ClustersKey))
+	}
+	if config.Cluster.MTLS {
+		for _, api := range userconf.APIs {
+			for _, warning := range api.Sidecars.MTLSWarnings() {
+				warnings = append(warnings, errors.Wrap(warning, userconfig.Identify(api), userconfig.SidecarsKey).Error())
+			}
+		}
+	}
+
+	step := span.StartChild("resolve mlflow models")
+	err = resolveMLflowModels(userconf.APIs)
+	step.Finish(err)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	step = span.StartChild("fetch jwks")
+	err = validateJWKSURIs(userconf.APIs)
+	step.Finish(err)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	// the models' files are extracted to the cluster's bucket if they haven't been imported already
+	step = span.StartChild("import sagemaker models")
+	err = userconf.APIs.ImportSageMakerModels(config.AWS)
+	step.Finish(err)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	// this includes checking that the APIs' models exist in S3
+	step = span.StartChild("validate config")
+	err = userconf.Validate(req.ProjectBytes, &userconfig.ProjectLimits{
+		MaxSize:     config.Cluster.MaxProjectSize * 1024 * 1024,
+		MaxFileSize: config.Cluster.MaxProjectFileSize * 1024 * 1024,
+		MaxFiles:    config.Cluster.MaxProjectFiles,
+	})
+	step.Finish(err)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	step = span.StartChild("store project files")
+	err = ocontext.StoreProjectFiles(req.NewProjectFiles)
+	step.Finish(err)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	// this uploads the project to S3
+	step = span.StartChild("build context")
+	ctx, err := ocontext.New(userconf, req.ProjectBytes, req.IgnoreCache)
+	step.Finish(err)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	span.SetAttribute("app", ctx.App.Name)
+
+	log := req.Log
+	if log == nil {
+		log = newRequestLogger(nil)
+	}
+	log = log.With(logging.Fields{"app": ctx.App.Name, "trace_id": span.Context.TraceIDStr()})
+	log.With(logging.Fields{"apis": userconf.APIs.Names(), "caller": req.CallerARN, "force": req.Force, "prune": req.Prune, "dry_run": req.DryRun}).Info("deploying")
+
+	for _, api := range ctx.APIs {
+		api.GitCommit = req.GitCommit
 	}
 
 	err = workloads.PopulateWorkloadIDs(ctx)
 	if err != nil {
-		RespondError(w, err)
-		return
+		return nil, http.StatusBadRequest, err
 	}
 
+	unlock := lockApp(ctx.App.Name)
+	defer unlock()
+
 	existingCtx := workloads.CurrentContext(ctx.App.Name)
 
 	fullCtxMatch := false
@@ -82,16 +280,56 @@ func Deploy(w http.ResponseWriter, r *http.Request) {
 		fullCtxMatch = true
 	}
 
+	step = span.StartChild("validate deploy")
 	err = workloads.ValidateDeploy(ctx)
+	step.Finish(err)
 	if err != nil {
-		RespondError(w, err)
-		return
+		return nil, http.StatusBadRequest, err
+	}
+
+	err = assignAPIOwners(ctx, existingCtx, req.CallerARN)
+	if err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	err = checkAPIPriorities(ctx, existingCtx, req.CallerARN)
+	if err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	if !req.Force {
+		err = checkAPIRevisions(ctx, existingCtx, req.ExpectedRevisions)
+		if err != nil {
+			return nil, http.StatusConflict, err
+		}
+	}
+	assignAPIRevisions(ctx, existingCtx)
+	keepAPIPauses(ctx, existingCtx)
+
+	// the APIs' owners and pauses determine which quotas they count towards
+	err = workloads.ValidateQuotas(ctx)
+	if err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	if req.DryRun {
+		return &schema.DeployResponse{Message: dryRunMessage(existingCtx, ctx, req.Prune), Warnings: warnings}, 0, nil
+	}
+
+	if !req.Prune {
+		if _, _, deletedAPIs := apiDiff(existingCtx, ctx); len(deletedAPIs) > 0 {
+			return nil, http.StatusConflict, ErrorPruneRequired(ctx.App.Name, deletedAPIs)
+		}
+	}
+
+	err = checkDeployFreeze(req.FreezeOverride)
+	if err != nil {
+		return nil, http.StatusForbidden, err
 	}
 
 	deploymentStatus, err := workloads.GetDeploymentStatus(ctx.App.Name)
 	if err != nil {
-		RespondError(w, err)
-		return
+		return nil, http.StatusBadRequest, err
 	}
 
 	isUpdating := deploymentStatus == resource.UpdatingDeploymentStatus
@@ -99,66 +337,95 @@ func Deploy(w http.ResponseWriter, r *http.Request) {
 	if isUpdating {
 		if fullCtxMatch {
 			msg := deployResponseMessage(ResDeploymentUpToDateUpdating(ctx.App.Name), ctx, nil)
-			Respond(w, schema.DeployResponse{Message: msg})
-			return
+			return &schema.DeployResponse{Message: msg, Warnings: warnings}, 0, nil
 		}
-		if !force {
+		if !req.Force {
 			msg := deployResponseMessage(ResDifferentDeploymentUpdating(ctx.App.Name), ctx, nil)
-			Respond(w, schema.DeployResponse{Message: msg})
-			return
+			return &schema.DeployResponse{Message: msg, Warnings: warnings}, 0, nil
+		}
+	}
+
+	if req.CheckRequirements {
+		projectFileMap, err := zip.UnzipMemToMem(req.ProjectBytes)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		if requirementsBytes, ok := projectFileMap[userconfig.RequirementsFileName]; ok {
+			err = workloads.CheckRequirements(ctx, requirementsBytes)
+			if err != nil {
+				return nil, http.StatusBadRequest, err
+			}
 		}
 	}
 
+	step = span.StartChild("upload context")
 	err = config.AWS.UploadMsgpackToS3(ctx, ctx.Key)
+	step.Finish(err)
 	if err != nil {
-		RespondError(w, err, ctx.App.Name, "upload context")
-		return
+		return nil, http.StatusBadRequest, errors.Wrap(err, ctx.App.Name, "upload context")
+	}
+
+	err = storeAPIRevisions(ctx, existingCtx)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(err, ctx.App.Name, "store api revisions")
 	}
 
+	step = span.StartChild("apply kubernetes resources")
 	err = workloads.Run(ctx)
+	step.Finish(err)
 	if err != nil {
-		RespondError(w, err)
-		return
+		return nil, http.StatusBadRequest, err
 	}
+	log.Info("started deployment's workflow")
 
 	apisBaseURL, err := workloads.APIsBaseURL()
 	if err != nil {
-		RespondError(w, err)
-		return
+		return nil, http.StatusBadRequest, err
+	}
+
+	privateAPIsBaseURL, err := workloads.PrivateAPIsBaseURL(ctx)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
 	}
 
 	var updatingAPIs []string
 	var baseMessage string
-	if !isUpdating && !ignoreCache && existingCtx != nil && fullCtxMatch {
+	if !isUpdating && !req.IgnoreCache && existingCtx != nil && fullCtxMatch {
 		baseMessage = ResDeploymentUpToDate(ctx.App.Name)
 	} else {
 		baseMessage, updatingAPIs = apiDiffMessage(existingCtx, ctx, apisBaseURL)
 	}
+	for _, apiName := range updatingAPIs {
+		log.With(logging.Fields{"api": apiName}).Info("updating api")
+	}
 
-	Respond(w, schema.DeployResponse{
-		Context:     ctx,
-		APIsBaseURL: apisBaseURL,
-		Message:     deployResponseMessage(baseMessage, ctx, updatingAPIs),
-	})
+	return &schema.DeployResponse{
+		Context:            ctx,
+		APIsBaseURL:        apisBaseURL,
+		PrivateAPIsBaseURL: privateAPIsBaseURL,
+		Message:            deployResponseMessage(baseMessage, ctx, updatingAPIs),
+		Warnings:           warnings,
+	}, 0, nil
 }
 
-func apiDiffMessage(previousCtx *context.Context, currentCtx *context.Context, apisBaseURL string) (string, []string) {
-	var newAPIs []context.API
-	var updatedAPIs []context.API
-	var deletedAPIs []context.API
+// apiDiff returns the names of the APIs which deploying currentCtx creates, updates, and deletes
+func apiDiff(previousCtx *context.Context, currentCtx *context.Context) ([]string, []string, []string) {
+	var newAPIs []string
+	var updatedAPIs []string
+	var deletedAPIs []string
 
 	if previousCtx == nil {
 		for _, api := range currentCtx.APIs {
-			newAPIs = append(newAPIs, *api)
+			newAPIs = append(newAPIs, api.Name)
 		}
 	} else {
 		for _, api := range currentCtx.APIs {
 			if prevAPI, ok := previousCtx.APIs[api.Name]; ok {
-				if api.ID != prevAPI.ID || api.Compute.ID() != prevAPI.Compute.ID() {
-					updatedAPIs = append(updatedAPIs, *api)
+				if api.ID != prevAPI.ID || api.Compute.ID() != prevAPI.Compute.ID() || api.ConfigID() != prevAPI.ConfigID() {
+					updatedAPIs = append(updatedAPIs, api.Name)
 				}
 			} else {
-				newAPIs = append(newAPIs, *api)
+				newAPIs = append(newAPIs, api.Name)
 			}
 		}
 
@@ -166,27 +433,57 @@ func apiDiffMessage(previousCtx *context.Context, currentCtx *context.Context, a
 			if _, ok := currentCtx.APIs[api.Name]; ok {
 				continue
 			}
-			deletedAPIs = append(deletedAPIs, *api)
+			deletedAPIs = append(deletedAPIs, api.Name)
 		}
 	}
 
+	sort.Strings(newAPIs)
+	sort.Strings(updatedAPIs)
+	sort.Strings(deletedAPIs)
+	return newAPIs, updatedAPIs, deletedAPIs
+}
+
+func apiDiffMessage(previousCtx *context.Context, currentCtx *context.Context, apisBaseURL string) (string, []string) {
+	newAPIs, updatedAPIs, deletedAPIs := apiDiff(previousCtx, currentCtx)
+
 	var updatingAPIs []string
 	var strs []string
-	for _, api := range newAPIs {
-		strs = append(strs, ResCreatingAPI(api.Name))
-		updatingAPIs = append(updatingAPIs, api.Name)
+	for _, apiName := range newAPIs {
+		strs = append(strs, ResCreatingAPI(apiName))
+		updatingAPIs = append(updatingAPIs, apiName)
 	}
-	for _, api := range updatedAPIs {
-		strs = append(strs, ResUpdatingAPI(api.Name))
-		updatingAPIs = append(updatingAPIs, api.Name)
+	for _, apiName := range updatedAPIs {
+		strs = append(strs, ResUpdatingAPI(apiName))
+		updatingAPIs = append(updatingAPIs, apiName)
 	}
-	for _, api := range deletedAPIs {
-		strs = append(strs, ResDeletingAPI(api.Name))
+	for _, apiName := range deletedAPIs {
+		strs = append(strs, ResDeletingAPI(apiName))
 	}
 
 	return strings.Join(strs, "\n"), updatingAPIs
 }
 
+// dryRunMessage describes the changes which deploying currentCtx would make
+func dryRunMessage(previousCtx *context.Context, currentCtx *context.Context, prune bool) string {
+	newAPIs, updatedAPIs, deletedAPIs := apiDiff(previousCtx, currentCtx)
+
+	strs := []string{ResDryRun(currentCtx.App.Name)}
+	for _, apiName := range newAPIs {
+		strs = append(strs, ResDryRunCreateAPI(apiName))
+	}
+	for _, apiName := range updatedAPIs {
+		strs = append(strs, ResDryRunUpdateAPI(apiName))
+	}
+	for _, apiName := range deletedAPIs {
+		strs = append(strs, ResDryRunDeleteAPI(apiName, prune))
+	}
+	if len(strs) == 1 {
+		strs = append(strs, ResDeploymentUpToDate(currentCtx.App.Name))
+	}
+
+	return strings.Join(strs, "\n")
+}
+
 func deployResponseMessage(baseMessage string, ctx *context.Context, updatingAPIs []string) string {
 	apiName := "<api_name>"
 
@@ -208,3 +505,29 @@ func deployResponseMessage(baseMessage string, ctx *context.Context, updatingAPI
 		NumSpaces: pointer.Int(2),
 	})
 }
+
+// checkDeployFreeze returns an error if one of the cluster's deploy freeze windows is active, unless overrideToken is the freeze's override token
+func checkDeployFreeze(overrideToken string) error {
+	freeze := config.Cluster.DeployFreeze
+	if freeze == nil {
+		return nil
+	}
+
+	window, end, ok := freeze.ActiveWindow(time.Now())
+	if !ok {
+		return nil
+	}
+
+	if overrideToken == "" || freeze.OverrideToken == nil {
+		return ErrorDeployFrozen(window.String(), end)
+	}
+
+	token, err := workloads.ResolveSecretValue(*freeze.OverrideToken)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(overrideToken), []byte(token)) != 1 {
+		return ErrorInvalidFreezeOverride()
+	}
+	return nil
+}
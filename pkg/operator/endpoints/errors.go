@@ -18,7 +18,10 @@ package endpoints
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 )
@@ -40,6 +43,31 @@ const (
 	ErrAnyQueryParamRequired
 	ErrAnyPathParamRequired
 	ErrPending
+	ErrInvalidQueryParam
+	ErrRouteNotFound
+	ErrMethodNotAllowed
+	ErrAuthPrincipalNotAllowed
+	ErrAPINotOwned
+	ErrInvalidPrincipal
+	ErrDeployConflict
+	ErrConflictingCortexAPIProjects
+	ErrInvalidAdmissionReview
+	ErrManagedResourceModification
+	ErrCostsNotEstimated
+	ErrPriorityNotAllowed
+	ErrPruneRequired
+	ErrInvalidProjectFileHash
+	ErrInvalidProjectFilePath
+	ErrProjectFilesMissing
+	ErrDeployFrozen
+	ErrInvalidFreezeOverride
+	ErrAPIRevisionNotFound
+	ErrDebugExecDisabled
+	ErrDebugExecNotAllowed
+	ErrExecCommandRequired
+	ErrNoFederatedClusters
+	ErrUnknownCluster
+	ErrSetLogLevelNotAllowed
 )
 
 var (
@@ -58,10 +86,35 @@ var (
 		"err_any_query_param_required",
 		"err_any_path_param_required",
 		"err_pending",
+		"err_invalid_query_param",
+		"err_route_not_found",
+		"err_method_not_allowed",
+		"err_auth_principal_not_allowed",
+		"err_api_not_owned",
+		"err_invalid_principal",
+		"err_deploy_conflict",
+		"err_conflicting_cortex_api_projects",
+		"err_invalid_admission_review",
+		"err_managed_resource_modification",
+		"err_costs_not_estimated",
+		"err_priority_not_allowed",
+		"err_prune_required",
+		"err_invalid_project_file_hash",
+		"err_invalid_project_file_path",
+		"err_project_files_missing",
+		"err_deploy_frozen",
+		"err_invalid_freeze_override",
+		"err_api_revision_not_found",
+		"err_debug_exec_disabled",
+		"err_debug_exec_not_allowed",
+		"err_exec_command_required",
+		"err_no_federated_clusters",
+		"err_unknown_cluster",
+		"err_set_log_level_not_allowed",
 	}
 )
 
-var _ = [1]int{}[int(ErrPending)-(len(errorKinds)-1)] // Ensure list length matches
+var _ = [1]int{}[int(ErrSetLogLevelNotAllowed)-(len(errorKinds)-1)] // Ensure list length matches
 
 func (t ErrorKind) String() string {
 	return errorKinds[t]
@@ -141,6 +194,55 @@ func ErrorAuthOtherAccount() error {
 	})
 }
 
+func ErrorAuthPrincipalNotAllowed(principalARN string, role string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrAuthPrincipalNotAllowed,
+		message: fmt.Sprintf("%s is not allowed to perform this request (%s access is required); add it to operator_%ss in your cluster configuration and run `cortex cluster update`", principalARN, role, role),
+	})
+}
+
+func ErrorAPINotOwned(apiName string, owner string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrAPINotOwned,
+		message: fmt.Sprintf("%s api is owned by %s; only its owner or an operator admin can update or delete it", s.UserStr(apiName), owner),
+	})
+}
+
+func ErrorInvalidPrincipal(principal string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidPrincipal,
+		message: fmt.Sprintf("%s is not the ARN of an IAM user or role (e.g. arn:aws:iam::123456789012:user/alice)", s.UserStr(principal)),
+	})
+}
+
+func ErrorDeployConflict(conflicts []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDeployConflict,
+		message: fmt.Sprintf("the deployment has been changed since your last deploy:\n\n%s\n\nrun `cortex get` to review the current deployment, or `cortex deploy --force` to overwrite it", strings.Join(conflicts, "\n\n")),
+	})
+}
+
+func ErrorConflictingCortexAPIProjects(appName string, project1 string, project2 string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrConflictingCortexAPIProjects,
+		message: fmt.Sprintf("the CortexAPIs in the %s deployment must have the same project (got %s and %s)", appName, s.UserStr(project1), s.UserStr(project2)),
+	})
+}
+
+func ErrorInvalidAdmissionReview() error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidAdmissionReview,
+		message: "the request does not contain an admission review",
+	})
+}
+
+func ErrorManagedResourceModification(kind string, name string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrManagedResourceModification,
+		message: fmt.Sprintf("%s %s is managed by cortex and cannot be modified or deleted directly; use `cortex deploy` or `cortex delete` instead", strings.ToLower(kind), s.UserStr(name)),
+	})
+}
+
 func ErrorAppNotDeployed(appName string) error {
 	return errors.WithStack(Error{
 		Kind: ErrAppNotDeployed,
@@ -196,3 +298,129 @@ func ErrorPending() error {
 		message: "pending",
 	})
 }
+
+func ErrorInvalidQueryParam(param string, value string, paramType string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidQueryParam,
+		message: fmt.Sprintf("query param %s must be a %s (got %s)", param, paramType, s.UserStr(value)),
+	})
+}
+
+func ErrorRouteNotFound(path string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrRouteNotFound,
+		message: fmt.Sprintf("%s is not a valid route (see /swagger.json for the operator's routes)", path),
+	})
+}
+
+func ErrorMethodNotAllowed(method string, path string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrMethodNotAllowed,
+		message: fmt.Sprintf("method %s is not allowed for %s", method, path),
+	})
+}
+
+func ErrorCostsNotEstimated() error {
+	return errors.WithStack(Error{
+		Kind:    ErrCostsNotEstimated,
+		message: "the cluster's costs have not been estimated yet, please try again in a minute",
+	})
+}
+
+func ErrorPriorityNotAllowed(apiName string, priority string, appName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPriorityNotAllowed,
+		message: fmt.Sprintf("%s api: you are not allowed to deploy apis with %s priority to the %s deployment (see %s in the cluster configuration)", apiName, s.UserStr(priority), appName, clusterconfig.PriorityPermissionsKey),
+	})
+}
+
+func ErrorPruneRequired(appName string, apiNames []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPruneRequired,
+		message: fmt.Sprintf("%s deployment: the %s api(s) are not defined in your configuration, and weren't deleted since --prune=false; run `cortex deploy` to delete them, or `cortex deploy --dry-run` to review the changes first", appName, s.StrsAnd(apiNames)),
+	})
+}
+
+func ErrorInvalidProjectFileHash(fileHash string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidProjectFileHash,
+		message: fmt.Sprintf("%s is not the hash of an uploaded project file's contents", s.UserStr(fileHash)),
+	})
+}
+
+func ErrorInvalidProjectFilePath(path string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidProjectFilePath,
+		message: fmt.Sprintf("%s is not a valid project file path (it must be relative to the project's root directory)", s.UserStr(path)),
+	})
+}
+
+func ErrorProjectFilesMissing(paths []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrProjectFilesMissing,
+		message: fmt.Sprintf("the contents of the following project files were not uploaded: %s; please try deploying again", strings.Join(paths, ", ")),
+	})
+}
+
+func ErrorDeployFrozen(window string, end time.Time) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDeployFrozen,
+		message: fmt.Sprintf("deploys are frozen until %s (deploy freeze window %s); run `cortex deploy --freeze-override <token>` to deploy anyway", end.Format(time.RFC3339), s.UserStr(window)),
+	})
+}
+
+func ErrorInvalidFreezeOverride() error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidFreezeOverride,
+		message: fmt.Sprintf("the freeze override token is not valid (see %s.%s in the cluster configuration)", clusterconfig.DeployFreezeKey, clusterconfig.OverrideTokenKey),
+	})
+}
+
+func ErrorAPIRevisionNotFound(apiName string, revision int64) error {
+	return errors.WithStack(Error{
+		Kind:    ErrAPIRevisionNotFound,
+		message: fmt.Sprintf("%s api: the configuration of revision %d was not found (configurations are only stored for revisions which were deployed with this version of cortex)", apiName, revision),
+	})
+}
+
+func ErrorDebugExecDisabled() error {
+	return errors.WithStack(Error{
+		Kind:    ErrDebugExecDisabled,
+		message: fmt.Sprintf("running commands in api replicas is disabled; set %s to true in your cluster configuration and run `cortex cluster update` to enable it", clusterconfig.DebugExecKey),
+	})
+}
+
+func ErrorDebugExecNotAllowed(principalARN string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDebugExecNotAllowed,
+		message: fmt.Sprintf("%s is not allowed to run commands in api replicas (only %s may)", principalARN, clusterconfig.OperatorAdminsKey),
+	})
+}
+
+func ErrorExecCommandRequired() error {
+	return errors.WithStack(Error{
+		Kind:    ErrExecCommandRequired,
+		message: "a command to run is required",
+	})
+}
+
+func ErrorNoFederatedClusters() error {
+	return errors.WithStack(Error{
+		Kind:    ErrNoFederatedClusters,
+		message: fmt.Sprintf("the cluster doesn't have any federated clusters; add them to %s in your cluster configuration and run `cortex cluster update`", clusterconfig.FederatedClustersKey),
+	})
+}
+
+func ErrorUnknownCluster(clusterName string, clusterNames []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrUnknownCluster,
+		message: fmt.Sprintf("%s is not this cluster or one of its federated clusters (%s)", s.UserStr(clusterName), s.StrsAnd(clusterNames)),
+	})
+}
+
+func ErrorSetLogLevelNotAllowed(principalARN string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrSetLogLevelNotAllowed,
+		message: fmt.Sprintf("%s is not allowed to change the operator's log level (only %s may)", principalARN, clusterconfig.OperatorAdminsKey),
+	})
+}
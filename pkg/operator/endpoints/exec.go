@@ -0,0 +1,114 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/workloads"
+)
+
+// ExecReplica runs a command in one of an API's replicas; only operator admins may do so, and only if debug_exec is enabled.
+// Each command is logged (without its output) along with its audit record's ID, and the audit record itself is stored by workloads.ExecReplica
+func ExecReplica(w http.ResponseWriter, r *http.Request) {
+	caller := callerARN(r)
+
+	if !config.Cluster.DebugExec {
+		RespondErrorCode(w, http.StatusForbidden, ErrorDebugExecDisabled())
+		return
+	}
+	// debug_exec can't be enabled without operator admins, but they're checked here too in case the cluster config was edited directly
+	if len(config.Cluster.OperatorAdmins) == 0 || !aws.PrincipalMatches(config.Cluster.OperatorAdmins, caller) {
+		RequestLogger(w).With(logging.Fields{"caller": caller}).Warn("exec denied")
+		RespondErrorCode(w, http.StatusForbidden, ErrorDebugExecNotAllowed(caller))
+		return
+	}
+
+	apiName, err := getRequiredPathParam("apiName", r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	appName, err := getRequiredQueryParam("appName", r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		RespondError(w, errors.WithStack(err))
+		return
+	}
+
+	var request schema.ExecRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		RespondError(w, err)
+		return
+	}
+	if len(request.Command) == 0 {
+		RespondError(w, ErrorExecCommandRequired())
+		return
+	}
+
+	ctx := workloads.CurrentContext(appName)
+	if ctx == nil {
+		RespondError(w, ErrorAppNotDeployed(appName))
+		return
+	}
+	if ctx.APIs[apiName] == nil {
+		RespondError(w, ErrorAPINotDeployed(apiName, appName))
+		return
+	}
+
+	log := RequestLogger(w).With(logging.Fields{
+		"caller":  caller,
+		"app":     appName,
+		"api":     apiName,
+		"command": request.Command,
+	})
+
+	response, err := workloads.ExecReplica(ctx, apiName, workloads.ExecOptions{
+		Replica:   getOptionalQParam("replica", r),
+		Container: getOptionalQParam("container", r),
+		Command:   request.Command,
+		Caller:    caller,
+		RequestID: w.Header().Get(RequestIDHeader),
+	})
+	if err != nil {
+		log.Warn("exec failed") // the error is logged with the request's ID when it's responded with
+		RespondError(w, err)
+		return
+	}
+
+	log.With(logging.Fields{
+		"audit_id":  response.AuditID,
+		"replica":   response.Replica,
+		"container": response.Container,
+		"exit_code": response.ExitCode,
+	}).Warn("exec")
+
+	Respond(w, response)
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/federation"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/workloads"
+)
+
+// FederatedDeploy deploys the configuration to this cluster and to each of its federated clusters which any of the configuration's APIs select.
+// Each cluster's operator deploys the APIs which select it (see deploy), so the whole configuration is sent to each cluster
+func FederatedDeploy(w http.ResponseWriter, r *http.Request) {
+	if len(config.Cluster.FederatedClusters) == 0 {
+		RespondError(w, ErrorNoFederatedClusters())
+		return
+	}
+
+	req, err := readDeployRequest(w, r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	userconf, err := userconfig.NewFromFiles(req.ConfigFiles, req.Variables)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	clusterNames := federationClusterNames()
+	for _, api := range userconf.APIs {
+		for i, clusterName := range api.Clusters {
+			if !slices.HasString(clusterNames, clusterName) {
+				RespondError(w, errors.Wrap(ErrorUnknownCluster(clusterName, clusterNames), userconfig.Identify(api), userconfig.ClustersKey, s.Index(i)))
+				return
+			}
+		}
+	}
+
+	clients, err := federatedClients()
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	// the other clusters' operators assemble the project from the zip, and check the revisions of their own APIs
+	forwardedFiles := map[string][]byte{"project.zip": req.ProjectBytes}
+	for fileName, fileBytes := range req.ConfigFiles {
+		forwardedFiles[fileName] = fileBytes
+	}
+	if len(req.Variables) > 0 {
+		variablesBytes, err := json.Marshal(req.Variables)
+		if err != nil {
+			RespondError(w, err)
+			return
+		}
+		forwardedFiles["variables.json"] = variablesBytes
+	}
+	if req.FreezeOverride != "" {
+		forwardedFiles["freeze_override"] = []byte(req.FreezeOverride)
+	}
+	query := url.Values{
+		"force":             {s.Bool(req.Force)},
+		"prune":             {s.Bool(req.Prune)},
+		"dryRun":            {s.Bool(req.DryRun)},
+		"ignoreCache":       {s.Bool(req.IgnoreCache)},
+		"checkRequirements": {s.Bool(req.CheckRequirements)},
+	}
+
+	results := make([]*schema.FederatedDeployResult, len(clusterNames))
+	var fns []func() error
+	for i, clusterName := range clusterNames {
+		result := &schema.FederatedDeployResult{Cluster: clusterName}
+		results[i] = result
+
+		if !apisSelectCluster(userconf.APIs, clusterName) {
+			result.Skipped = true
+			continue
+		}
+
+		if clusterName == config.Cluster.ClusterName {
+			fns = append(fns, func() error {
+				response, _, err := deploy(req)
+				result.Response = response
+				return err
+			})
+			continue
+		}
+
+		client := clients[clusterName]
+		fns = append(fns, func() error {
+			var response schema.DeployResponse
+			if err := client.PostMultipart(APIVersionPrefix+"/deploy", query, forwardedFiles, &response); err != nil {
+				return err
+			}
+			result.Response = &response
+			return nil
+		})
+	}
+
+	var deployedClusters []string
+	for _, result := range results {
+		if !result.Skipped {
+			deployedClusters = append(deployedClusters, result.Cluster)
+		}
+	}
+	RequestLogger(w).With(logging.Fields{"caller": req.CallerARN, "clusters": deployedClusters, "dry_run": req.DryRun}).Info("federated deploy")
+
+	errs := parallel.Run(fns...)
+	i := 0
+	for _, result := range results {
+		if result.Skipped {
+			continue
+		}
+		if errs[i] != nil {
+			result.Response = nil
+			result.Error = errs[i].Error()
+		}
+		i++
+	}
+
+	Respond(w, schema.FederatedDeployResponse{Clusters: results})
+}
+
+// GetFederatedResources gets the statuses of a deployment's APIs in this cluster and in each of its federated clusters
+func GetFederatedResources(w http.ResponseWriter, r *http.Request) {
+	if len(config.Cluster.FederatedClusters) == 0 {
+		RespondError(w, ErrorNoFederatedClusters())
+		return
+	}
+
+	appName, err := getRequiredQueryParam("appName", r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	clients, err := federatedClients()
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	clusterNames := federationClusterNames()
+	results := make([]*schema.FederatedResources, len(clusterNames))
+	fns := make([]func() error, len(clusterNames))
+	for i, clusterName := range clusterNames {
+		result := &schema.FederatedResources{Cluster: clusterName}
+		results[i] = result
+
+		if clusterName == config.Cluster.ClusterName {
+			fns[i] = func() error {
+				response, err := getResourcesResponse(appName)
+				result.Resources = response
+				return err
+			}
+			continue
+		}
+
+		client := clients[clusterName]
+		fns[i] = func() error {
+			var response schema.GetResourcesResponse
+			if err := client.Get(APIVersionPrefix+"/apis", url.Values{"appName": {appName}}, &response); err != nil {
+				return err
+			}
+			result.Resources = &response
+			return nil
+		}
+	}
+
+	for i, err := range parallel.Run(fns...) {
+		if err != nil {
+			results[i].Resources = nil
+			results[i].Error = err.Error()
+		}
+	}
+
+	Respond(w, schema.FederatedResourcesResponse{Clusters: results})
+}
+
+// federationClusterNames returns the name of this cluster, followed by the names of its federated clusters
+func federationClusterNames() []string {
+	return append([]string{config.Cluster.ClusterName}, clusterconfig.FederatedClusterNames(config.Cluster.FederatedClusters)...)
+}
+
+// federatedClients returns a client for each of the federated clusters' operators (keyed by cluster name)
+func federatedClients() (map[string]*federation.Client, error) {
+	clients := make(map[string]*federation.Client, len(config.Cluster.FederatedClusters))
+	for _, cluster := range config.Cluster.FederatedClusters {
+		secretAccessKey, err := workloads.ResolveSecretValue(cluster.AWSSecretAccessKey)
+		if err != nil {
+			return nil, errors.Wrap(err, clusterconfig.FederatedClustersKey, cluster.Name, clusterconfig.AWSSecretAccessKeyKey)
+		}
+		clients[cluster.Name] = &federation.Client{
+			ClusterName:        cluster.Name,
+			OperatorEndpoint:   cluster.OperatorEndpoint,
+			AWSAccessKeyID:     cluster.AWSAccessKeyID,
+			AWSSecretAccessKey: secretAccessKey,
+			CortexVersion:      consts.CortexVersion,
+		}
+	}
+	return clients, nil
+}
+
+func apisSelectCluster(apis userconfig.APIs, clusterName string) bool {
+	for _, api := range apis {
+		if api.SelectsCluster(clusterName) {
+			return true
+		}
+	}
+	return false
+}
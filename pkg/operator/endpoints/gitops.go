@@ -0,0 +1,171 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/git"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/workloads"
+)
+
+const _gitOpsDir = "/tmp/gitops"
+
+// The deployment which was most recently synced from the GitOps repository
+var _gitOpsAppName string
+
+// RunGitOps periodically deploys the cortex.yaml (and project files) in the cluster's GitOps repository, so that the deployed APIs match the repository's branch
+func RunGitOps() {
+	for {
+		if err := syncGitOps(); err != nil {
+			err = errors.Wrap(err, "gitops")
+			telemetry.Error(err)
+			logging.PrintError(err)
+		}
+		time.Sleep(config.Cluster.GitOps.Interval)
+	}
+}
+
+func syncGitOps() error {
+	gitOps := config.Cluster.GitOps
+
+	repo := &git.Repo{
+		URL:    gitOps.Repository,
+		Branch: gitOps.Branch,
+	}
+	if gitOps.SSHKey != nil {
+		sshKey, err := workloads.ResolveSecretValue(*gitOps.SSHKey)
+		if err != nil {
+			return err
+		}
+		repo.SSHKey = sshKey
+	}
+
+	commit, err := git.Sync(repo, _gitOpsDir)
+	if err != nil {
+		return err
+	}
+
+	// the repository hasn't changed, and its APIs haven't been replaced by another deploy
+	if _gitOpsAppName != "" && isDeployedFromCommit(_gitOpsAppName, commit) {
+		return nil
+	}
+
+	projectRoot := filepath.Join(_gitOpsDir, gitOps.Path)
+
+	configFiles, err := readProjectConfigFiles(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	// the deployment's include and exclude patterns are applied when it's deployed
+	ignoreFns, err := files.ProjectIgnoreFns(projectRoot, nil, nil)
+	if err != nil {
+		return err
+	}
+	projectPaths, err := files.ListDirRecursive(projectRoot, false, ignoreFns...)
+	if err != nil {
+		return err
+	}
+	projectBytes, err := zip.ToMem(&zip.Input{
+		FileLists: []zip.FileListInput{
+			{
+				Sources:      projectPaths,
+				RemovePrefix: projectRoot,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	log := newRequestLogger(logging.Fields{"source": "gitops", "commit": commit})
+	response, _, err := deploy(&deployRequest{
+		ConfigFiles:  configFiles,
+		ProjectBytes: projectBytes,
+		Variables:    map[string]string{},
+		CallerARN:    _gitOpsPrincipal,
+		GitCommit:    commit,
+		Force:        true, // the repository is the source of truth
+		Prune:        true,
+		Log:          log,
+	})
+	if err != nil {
+		return errors.Wrap(err, "commit "+commit)
+	}
+
+	if response.Context != nil {
+		_gitOpsAppName = response.Context.App.Name
+		log.With(logging.Fields{"app": _gitOpsAppName}).Info("deployed from commit")
+	}
+
+	return nil
+}
+
+// isDeployedFromCommit returns true if every API in the deployment was deployed from the commit
+func isDeployedFromCommit(appName string, commit string) bool {
+	ctx := workloads.CurrentContext(appName)
+	if ctx == nil {
+		return false
+	}
+	for _, api := range ctx.APIs {
+		if api.GitCommit != commit {
+			return false
+		}
+	}
+	return true
+}
+
+// readProjectConfigFiles reads cortex.yaml and any YAML files in the cortex.d directory (keyed by their paths relative to the project root)
+func readProjectConfigFiles(projectRoot string) (map[string][]byte, error) {
+	configBytes, err := files.ReadFileBytesErrPath(filepath.Join(projectRoot, "cortex.yaml"), "cortex.yaml")
+	if err != nil {
+		return nil, err
+	}
+	configFiles := map[string][]byte{
+		"cortex.yaml": configBytes,
+	}
+
+	configDir := filepath.Join(projectRoot, userconfig.ConfigDirName)
+	if !files.IsDir(configDir) {
+		return configFiles, nil
+	}
+
+	configPaths, err := files.ListDirRecursive(configDir, false, files.IgnoreNonYAML)
+	if err != nil {
+		return nil, err
+	}
+	for _, configPath := range configPaths {
+		relativePath := strings.TrimPrefix(configPath, projectRoot+"/")
+		configBytes, err := files.ReadFileBytesErrPath(configPath, relativePath)
+		if err != nil {
+			return nil, err
+		}
+		configFiles[relativePath] = configBytes
+	}
+
+	return configFiles, nil
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/jwks"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+)
+
+// validateJWKSURIs checks that the key sets of the APIs which require a JWT can be fetched
+// (the istio proxies fetch them to validate the requests' tokens, so requests would be rejected if they couldn't)
+func validateJWKSURIs(apis userconfig.APIs) error {
+	fetched := map[string]bool{}
+	for _, api := range apis {
+		jwt := api.JWTAuth()
+		if jwt == nil || fetched[jwt.JWKSURI] {
+			continue
+		}
+		if _, err := jwks.Fetch(jwt.JWKSURI); err != nil {
+			return errors.Wrap(err, userconfig.Identify(api), userconfig.NetworkingKey, userconfig.AuthKey, userconfig.JWTKey, userconfig.JWKSURIKey)
+		}
+		fetched[jwt.JWKSURI] = true
+	}
+	return nil
+}
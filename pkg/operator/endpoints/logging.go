@@ -0,0 +1,130 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is set on each response (a request's ID is taken from the header if the caller sets it)
+const RequestIDHeader = "X-Request-ID"
+
+// loggedResponseWriter carries the request's logger, so that the errors which are responded with are logged with the request's fields
+type loggedResponseWriter struct {
+	http.ResponseWriter
+	log    *logging.Logger
+	status int
+}
+
+func (w *loggedResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack satisfies http.Hijacker, which is needed to upgrade websocket requests
+func (w *loggedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer does not support hijacking")
+	}
+	w.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// LogRequests assigns an ID to each request, adds a logger with the request's ID to the request's context (see RequestLogger),
+// and logs each request once it has been handled (GET requests are logged at the debug level)
+func LogRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		log := logging.With(logging.Fields{"request_id": requestID})
+		loggedWriter := &loggedResponseWriter{ResponseWriter: w, log: log, status: http.StatusOK}
+		start := time.Now()
+
+		next.ServeHTTP(loggedWriter, r.WithContext(logging.NewContext(r.Context(), log)))
+
+		requestLog := log.With(logging.Fields{
+			"method":      r.Method,
+			"path":        r.URL.Path,
+			"status":      loggedWriter.status,
+			"duration_ms": time.Since(start).Milliseconds(),
+		})
+		if appName := r.URL.Query().Get("appName"); appName != "" {
+			requestLog = requestLog.With(logging.Fields{"app": appName})
+		}
+		if r.Method == http.MethodGet {
+			requestLog.Debug("handled request")
+		} else {
+			requestLog.Info("handled request")
+		}
+	})
+}
+
+// RequestLogger returns the logger of the request which is being responded to (or a logger without fields if the response writer isn't from LogRequests)
+func RequestLogger(w http.ResponseWriter) *logging.Logger {
+	if loggedWriter, ok := w.(*loggedResponseWriter); ok {
+		return loggedWriter.log
+	}
+	return logging.With(nil)
+}
+
+func GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	Respond(w, schema.LogLevelResponse{Level: logging.GetLevel().String()})
+}
+
+// SetLogLevel changes the operator's log level until it restarts (the level which it starts with is the cluster's operator_log_level); only operator admins may change it
+func SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	caller := callerARN(r)
+
+	// the log level applies to the whole operator, so only operator admins may change it (regardless of the caller's role)
+	if len(config.Cluster.OperatorAdmins) == 0 || !aws.PrincipalMatches(config.Cluster.OperatorAdmins, caller) {
+		RequestLogger(w).With(logging.Fields{"caller": caller}).Warn("set log level denied")
+		RespondErrorCode(w, http.StatusForbidden, ErrorSetLogLevelNotAllowed(caller))
+		return
+	}
+
+	level, err := logging.ParseLevel(getOptionalQParam("level", r))
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	previousLevel := logging.GetLevel()
+	logging.SetLevel(level)
+	RequestLogger(w).With(logging.Fields{"previous_level": previousLevel.String(), "caller": caller}).Warnf("set log level to %s", level.String())
+
+	Respond(w, schema.LogLevelResponse{Level: level.String()})
+}
+
+// newRequestLogger returns a logger with a new request ID, for work which isn't triggered by a request to the operator (e.g. GitOps syncs)
+func newRequestLogger(fields logging.Fields) *logging.Logger {
+	return logging.With(logging.Fields{"request_id": uuid.New().String()}).With(fields)
+}
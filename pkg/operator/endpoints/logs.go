@@ -18,12 +18,16 @@ package endpoints
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
 	"github.com/cortexlabs/cortex/pkg/operator/workloads"
 )
 
@@ -133,3 +137,66 @@ func readLogs(w http.ResponseWriter, r *http.Request, podLabels map[string]strin
 
 	workloads.ReadLogs(appName, podLabels, socket)
 }
+
+func QueryLogs(w http.ResponseWriter, r *http.Request) {
+	apiName, err := getRequiredPathParam("apiName", r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	appName, err := getRequiredQueryParam("appName", r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	ctx := workloads.CurrentContext(appName)
+	if ctx == nil {
+		RespondError(w, ErrorAppNotDeployed(appName))
+		return
+	}
+
+	if ctx.APIs[apiName] == nil {
+		RespondError(w, ErrorAPINotDeployed(apiName, appName))
+		return
+	}
+
+	query := workloads.LogsQuery{
+		Since: workloads.DefaultLogsQuerySince,
+		Level: strings.ToLower(getOptionalQParam("level", r)),
+		Pod:   getOptionalQParam("pod", r),
+		Text:  getOptionalQParam("q", r),
+		Limit: workloads.DefaultLogsQueryLimit,
+	}
+
+	if sinceStr := getOptionalQParam("since", r); sinceStr != "" {
+		since, err := time.ParseDuration(sinceStr)
+		if err != nil || since <= 0 {
+			RespondError(w, ErrorInvalidQueryParam("since", sinceStr, "positive duration"))
+			return
+		}
+		query.Since = since
+	}
+
+	if query.Level != "" && !slices.HasString(schema.LogLevels, query.Level) {
+		RespondError(w, ErrorInvalidQueryParam("level", query.Level, "log level ("+s.StrsOr(schema.LogLevels)+")"))
+		return
+	}
+
+	// the route's query params have already been validated as integers
+	if limitStr := getOptionalQParam("limit", r); limitStr != "" {
+		query.Limit, _ = s.ParseInt64(limitStr)
+		if query.Limit <= 0 || query.Limit > workloads.MaxLogsQueryLimit {
+			RespondError(w, ErrorInvalidQueryParam("limit", limitStr, "integer between 1 and "+s.Int64(workloads.MaxLogsQueryLimit)))
+			return
+		}
+	}
+
+	response, err := workloads.QueryLogs(ctx, apiName, query)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+	Respond(w, response)
+}
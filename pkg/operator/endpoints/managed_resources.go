@@ -0,0 +1,131 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	kadmission "k8s.io/api/admission/v1beta1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/workloads"
+)
+
+var _operatorServiceAccount = "system:serviceaccount:" + consts.K8sNamespace + ":operator"
+
+// ValidateManagedResource is the handler for the validating admission webhook which prevents the Kubernetes resources
+// that the operator manages for APIs (deployments, HPAs, and virtual services) from being updated or deleted by anyone but the operator
+func ValidateManagedResource(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		RespondError(w, errors.WithStack(err))
+		return
+	}
+
+	var review kadmission.AdmissionReview
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		RespondError(w, ErrorInvalidAdmissionReview())
+		return
+	}
+
+	review.Response = &kadmission.AdmissionResponse{
+		UID:     review.Request.UID,
+		Allowed: true,
+	}
+
+	if err := validateManagedResource(review.Request); err != nil {
+		review.Response.Allowed = false
+		review.Response.Result = &kmeta.Status{
+			Status:  kmeta.StatusFailure,
+			Message: err.Error(),
+		}
+	}
+	review.Request = nil
+
+	Respond(w, review)
+}
+
+func validateManagedResource(request *kadmission.AdmissionRequest) error {
+	if request.Namespace != consts.K8sNamespace || request.SubResource != "" {
+		return nil // e.g. status updates, and scaling by the HPA
+	}
+	if isKubernetesComponent(request.UserInfo.Username) {
+		return nil
+	}
+
+	labels, err := managedResourceLabels(request)
+	if err != nil {
+		return nil // don't block requests for objects which can't be read
+	}
+	if !workloads.IsAPIResource(labels) {
+		return nil
+	}
+
+	return ErrorManagedResourceModification(request.Kind.Kind, request.Name)
+}
+
+// isKubernetesComponent returns whether the user is the operator, or a Kubernetes controller (e.g. the garbage collector, or the namespace controller)
+func isKubernetesComponent(username string) bool {
+	if username == _operatorServiceAccount {
+		return true
+	}
+	if strings.HasPrefix(username, "system:serviceaccount:kube-system:") {
+		return true
+	}
+	return username == "system:kube-controller-manager"
+}
+
+// managedResourceLabels returns the labels of the existing object (the old object isn't included in delete requests, so it's retrieved)
+func managedResourceLabels(request *kadmission.AdmissionRequest) (map[string]string, error) {
+	if len(request.OldObject.Raw) > 0 {
+		var obj kunstructured.Unstructured
+		if err := obj.UnmarshalJSON(request.OldObject.Raw); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return obj.GetLabels(), nil
+	}
+
+	switch request.Kind.Kind {
+	case "Deployment":
+		deployment, err := config.Kubernetes.GetDeployment(request.Name)
+		if err != nil || deployment == nil {
+			return nil, err
+		}
+		return deployment.Labels, nil
+	case "HorizontalPodAutoscaler":
+		hpa, err := config.Kubernetes.GetHPA(request.Name)
+		if err != nil || hpa == nil {
+			return nil, err
+		}
+		return hpa.Labels, nil
+	case "VirtualService":
+		virtualService, err := config.Kubernetes.GetVirtualService(request.Name, request.Namespace)
+		if err != nil || virtualService == nil {
+			return nil, err
+		}
+		return virtualService.GetLabels(), nil
+	}
+
+	return nil, nil
+}
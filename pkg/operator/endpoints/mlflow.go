@@ -0,0 +1,221 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/lib/mlflow"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/workloads"
+)
+
+// mlflowClient returns a client for the cluster's MLflow registry, or nil if the cluster isn't configured with one
+func mlflowClient() (*mlflow.Client, error) {
+	mlflowConfig := config.Cluster.MLflow
+	if mlflowConfig == nil {
+		return nil, nil
+	}
+
+	client := &mlflow.Client{TrackingURI: mlflowConfig.TrackingURI}
+	if mlflowConfig.Token != nil {
+		token, err := workloads.ResolveSecretValue(*mlflowConfig.Token)
+		if err != nil {
+			return nil, err
+		}
+		client.Token = token
+	}
+	return client, nil
+}
+
+// resolveMLflowModels replaces the APIs' mlflow:// models with the S3 paths of the registered versions
+func resolveMLflowModels(apis userconfig.APIs) error {
+	hasMLflowModel := false
+	for _, api := range apis {
+		if api.Predictor.IsMLflowModel() {
+			hasMLflowModel = true
+			break
+		}
+	}
+	if !hasMLflowModel {
+		return nil
+	}
+
+	client, err := mlflowClient()
+	if err != nil {
+		return err
+	}
+	return apis.ResolveMLflowModels(client)
+}
+
+// PingMLflow checks that the cluster's MLflow registry can be reached with its configured credentials
+func PingMLflow() error {
+	client, err := mlflowClient()
+	if err != nil || client == nil {
+		return err
+	}
+	return client.Ping()
+}
+
+// RunMLflowWatcher periodically redeploys the deployments which have an API that serves a stage of a registered model, if a different version has been transitioned to the stage
+func RunMLflowWatcher() {
+	for {
+		time.Sleep(config.Cluster.MLflow.WatchInterval)
+		if err := syncMLflowModels(); err != nil {
+			err = errors.Wrap(err, "mlflow")
+			telemetry.Error(err)
+			logging.PrintError(err)
+		}
+	}
+}
+
+func syncMLflowModels() error {
+	client, err := mlflowClient()
+	if err != nil {
+		return err
+	}
+
+	stageVersions := map[string]string{} // model URI -> the latest version in the stage (shared between deployments)
+	var errs []error
+
+	for _, ctx := range workloads.CurrentContexts() {
+		changedAPIs := map[string]string{} // API name -> the stage's new version
+		for _, api := range ctx.APIs {
+			if api.Predictor.MLflowModel == nil {
+				continue
+			}
+			uri, err := mlflow.ParseModelURI(*api.Predictor.MLflowModel)
+			if err != nil || uri.Stage == "" {
+				continue // pinned versions don't change
+			}
+
+			version, ok := stageVersions[uri.String()]
+			if !ok {
+				modelVersion, err := client.ModelVersion(uri)
+				if err != nil {
+					errs = append(errs, errors.Wrap(err, ctx.App.Name, api.Name))
+					continue
+				}
+				version = modelVersion.Version
+				stageVersions[uri.String()] = version
+			}
+
+			if version != api.Predictor.MLflowModelVersion {
+				changedAPIs[api.Name] = version
+			}
+		}
+
+		if len(changedAPIs) == 0 {
+			continue
+		}
+
+		if err := redeployMLflowModels(ctx, changedAPIs); err != nil {
+			errs = append(errs, errors.Wrap(err, ctx.App.Name))
+		}
+	}
+
+	return errors.FirstError(errs...)
+}
+
+// redeployMLflowModels deploys the deployment's current configuration and project again, resolving its mlflow:// models to the registry's current versions
+func redeployMLflowModels(ctx *context.Context, changedAPIs map[string]string) error {
+	userconf, err := deployedUserConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	projectBytes, err := config.AWS.ReadBytesFromS3(ctx.ProjectKey)
+	if err != nil {
+		return err
+	}
+
+	// the commit is only kept if every API was deployed from it, so that the GitOps sync isn't triggered
+	gitCommit := ""
+	for _, api := range ctx.APIs {
+		gitCommit = api.GitCommit
+		break
+	}
+	for _, api := range ctx.APIs {
+		if api.GitCommit != gitCommit {
+			gitCommit = ""
+			break
+		}
+	}
+
+	log := newRequestLogger(logging.Fields{"source": "mlflow", "app": ctx.App.Name})
+	for apiName, version := range changedAPIs {
+		log.With(logging.Fields{"api": apiName, "model": *ctx.APIs[apiName].Predictor.MLflowModel, "version": version}).Info("model version changed")
+	}
+
+	_, _, err = deploy(&deployRequest{
+		UserConfig:   userconf,
+		ProjectBytes: projectBytes,
+		CallerARN:    _mlflowPrincipal,
+		GitCommit:    gitCommit,
+		Force:        true,
+		Prune:        true,
+		Log:          log,
+	})
+	return err
+}
+
+// deployedUserConfig copies the configuration of the deployment's APIs (as they were validated when they were deployed), with their mlflow:// and SageMaker models as they were configured
+func deployedUserConfig(ctx *context.Context) (*userconfig.Config, error) {
+	apis := make(userconfig.APIs, 0, len(ctx.APIs))
+	for _, api := range ctx.APIs {
+		apiBytes, err := json.Marshal(api.API)
+		if err != nil {
+			return nil, err
+		}
+		var apiConfig userconfig.API
+		if err := json.Unmarshal(apiBytes, &apiConfig); err != nil {
+			return nil, err
+		}
+
+		if apiConfig.Predictor.MLflowModel != nil {
+			apiConfig.Predictor.Model = apiConfig.Predictor.MLflowModel
+			apiConfig.Predictor.MLflowModel = nil
+			apiConfig.Predictor.MLflowModelVersion = ""
+		}
+		if apiConfig.Predictor.SageMakerModel != nil {
+			apiConfig.Predictor.Model = apiConfig.Predictor.SageMakerModel
+			apiConfig.Predictor.SageMakerModel = nil
+		}
+
+		apis = append(apis, &apiConfig)
+	}
+
+	// the APIs are validated in the order in which they were configured
+	sort.Slice(apis, func(i, j int) bool {
+		if apis[i].FilePath != apis[j].FilePath {
+			return apis[i].FilePath < apis[j].FilePath
+		}
+		return apis[i].Index < apis[j].Index
+	})
+
+	return &userconfig.Config{
+		App:  ctx.App.App,
+		APIs: apis,
+	}, nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	gocontext "context"
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/workloads"
+)
+
+type callerIdentityKey struct{}
+
+// The principal which deploys APIs from the GitOps repository (it may modify any API, and other callers may only modify the APIs which it owns if they are operator admins)
+const _gitOpsPrincipal = "cortex:gitops"
+
+// The principal which deploys APIs from CortexAPI custom resources (with the same privileges as the GitOps principal)
+const _cortexAPIPrincipal = "cortex:crd"
+
+// The principal which redeploys APIs when a different version is transitioned to their MLflow model's stage (with the same privileges as the GitOps principal)
+const _mlflowPrincipal = "cortex:mlflow"
+
+// isOperatorPrincipal returns whether the principal is one which the operator deploys APIs as
+func isOperatorPrincipal(principalARN string) bool {
+	return principalARN == _gitOpsPrincipal || principalARN == _cortexAPIPrincipal || principalARN == _mlflowPrincipal
+}
+
+// WithCallerIdentity attaches the authenticated caller's identity to the request
+func WithCallerIdentity(r *http.Request, identity *aws.CallerIdentity) *http.Request {
+	return r.WithContext(gocontext.WithValue(r.Context(), callerIdentityKey{}, identity))
+}
+
+// Returns the normalized ARN of the authenticated caller, or an empty string if the request was not authenticated
+func callerARN(r *http.Request) string {
+	identity, ok := r.Context().Value(callerIdentityKey{}).(*aws.CallerIdentity)
+	if !ok || identity == nil {
+		return ""
+	}
+	return aws.NormalizePrincipalARN(identity.ARN)
+}
+
+// Ownership is only enforced once operator admins have been configured, so that every API can always be managed by someone
+func canModifyAPI(api *context.API, principalARN string) bool {
+	if len(config.Cluster.OperatorAdmins) == 0 || api.Owner == "" || isOperatorPrincipal(principalARN) {
+		return true
+	}
+	if aws.PrincipalMatches(config.Cluster.OperatorAdmins, principalARN) {
+		return true
+	}
+	return aws.PrincipalMatches([]string{api.Owner}, principalARN)
+}
+
+// assignAPIOwners sets the owner of each API in ctx, and returns an error if the caller is updating or deleting an API which it doesn't own
+func assignAPIOwners(ctx *context.Context, existingCtx *context.Context, principalARN string) error {
+	for apiName, api := range ctx.APIs {
+		var prevAPI *context.API
+		if existingCtx != nil {
+			prevAPI = existingCtx.APIs[apiName]
+		}
+
+		if prevAPI == nil || prevAPI.Owner == "" {
+			api.Owner = principalARN
+			continue
+		}
+
+		api.Owner = prevAPI.Owner
+
+		if prevAPI.ID == api.ID && prevAPI.Compute.ID() == api.Compute.ID() {
+			continue
+		}
+		if !canModifyAPI(prevAPI, principalARN) {
+			return ErrorAPINotOwned(apiName, prevAPI.Owner)
+		}
+	}
+
+	if existingCtx != nil {
+		for apiName, prevAPI := range existingCtx.APIs {
+			if _, ok := ctx.APIs[apiName]; ok {
+				continue
+			}
+			if !canModifyAPI(prevAPI, principalARN) {
+				return ErrorAPINotOwned(apiName, prevAPI.Owner)
+			}
+		}
+	}
+
+	return nil
+}
+
+func TransferAPIOwnership(w http.ResponseWriter, r *http.Request) {
+	apiName, err := getRequiredPathParam("apiName", r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	appName, err := getRequiredQueryParam("appName", r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	newOwner, err := getRequiredQueryParam("owner", r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+	if !aws.IsPrincipalARN(newOwner) {
+		RespondError(w, ErrorInvalidPrincipal(newOwner))
+		return
+	}
+	newOwner = aws.NormalizePrincipalARN(newOwner)
+
+	unlock := lockApp(appName)
+	defer unlock()
+
+	ctx := workloads.CurrentContext(appName)
+	if ctx == nil {
+		RespondError(w, ErrorAppNotDeployed(appName))
+		return
+	}
+
+	api := ctx.APIs[apiName]
+	if api == nil {
+		RespondError(w, ErrorAPINotDeployed(apiName, appName))
+		return
+	}
+
+	if !canModifyAPI(api, callerARN(r)) {
+		RespondErrorCode(w, http.StatusForbidden, ErrorAPINotOwned(apiName, api.Owner))
+		return
+	}
+
+	if err := workloads.SetAPIOwner(ctx, apiName, newOwner); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	Respond(w, schema.TransferAPIOwnershipResponse{Message: ResTransferredAPI(apiName, newOwner)})
+}
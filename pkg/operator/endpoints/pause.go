@@ -0,0 +1,108 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/workloads"
+)
+
+func Pause(w http.ResponseWriter, r *http.Request) {
+	ctx, apiName, unlock, ok := lockModifiableAPI(w, r)
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	if err := workloads.PauseAPI(ctx, apiName); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	Respond(w, schema.PauseResponse{Message: ResPausedAPI(apiName)})
+}
+
+func Resume(w http.ResponseWriter, r *http.Request) {
+	ctx, apiName, unlock, ok := lockModifiableAPI(w, r)
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	if err := workloads.ResumeAPI(ctx, apiName); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	Respond(w, schema.ResumeResponse{Message: ResResumedAPI(apiName)})
+}
+
+// lockModifiableAPI locks the app of the API in the request's path, and checks that the caller can modify the API
+// (if it can't, an error response is written, the app is unlocked, and ok is false)
+func lockModifiableAPI(w http.ResponseWriter, r *http.Request) (ctx *context.Context, apiName string, unlock func(), ok bool) {
+	apiName, err := getRequiredPathParam("apiName", r)
+	if err != nil {
+		RespondError(w, err)
+		return nil, "", nil, false
+	}
+
+	appName, err := getRequiredQueryParam("appName", r)
+	if err != nil {
+		RespondError(w, err)
+		return nil, "", nil, false
+	}
+
+	unlock = lockApp(appName)
+
+	ctx = workloads.CurrentContext(appName)
+	if ctx == nil {
+		unlock()
+		RespondError(w, ErrorAppNotDeployed(appName))
+		return nil, "", nil, false
+	}
+
+	api := ctx.APIs[apiName]
+	if api == nil {
+		unlock()
+		RespondError(w, ErrorAPINotDeployed(apiName, appName))
+		return nil, "", nil, false
+	}
+
+	if !canModifyAPI(api, callerARN(r)) {
+		unlock()
+		RespondErrorCode(w, http.StatusForbidden, ErrorAPINotOwned(apiName, api.Owner))
+		return nil, "", nil, false
+	}
+
+	return ctx, apiName, unlock, true
+}
+
+// keepAPIPauses carries over whether each API is paused (and when it was last resumed) from the existing context, so that deploys don't resume paused APIs
+func keepAPIPauses(ctx *context.Context, existingCtx *context.Context) {
+	if existingCtx == nil {
+		return
+	}
+	for apiName, api := range ctx.APIs {
+		if prevAPI := existingCtx.APIs[apiName]; prevAPI != nil {
+			api.Pause = prevAPI.Pause
+			api.LastResumed = prevAPI.LastResumed
+		}
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// checkAPIPriorities returns an error if the caller isn't allowed to deploy an API in ctx with its priority (APIs whose priority isn't changing aren't checked)
+func checkAPIPriorities(ctx *context.Context, existingCtx *context.Context, principalARN string) error {
+	for apiName, api := range ctx.APIs {
+		if existingCtx != nil {
+			if prevAPI := existingCtx.APIs[apiName]; prevAPI != nil && prevAPI.Compute.Priority == api.Compute.Priority {
+				continue
+			}
+		}
+
+		priority := api.Compute.Priority.String()
+		if !canUsePriority(priority, ctx.App.Name, principalARN) {
+			return ErrorPriorityNotAllowed(apiName, priority, ctx.App.Name)
+		}
+	}
+	return nil
+}
+
+// Priorities which aren't listed in the cluster's priority permissions are unrestricted; the operator's principals (GitOps, CortexAPI, and MLflow) may use any priority in the allowed deployments
+func canUsePriority(priority string, appName string, principalARN string) bool {
+	restricted := false
+	for _, permission := range config.Cluster.PriorityPermissions {
+		if permission.Priority != priority {
+			continue
+		}
+		restricted = true
+
+		principalAllowed := len(permission.Principals) == 0 || isOperatorPrincipal(principalARN) || aws.PrincipalMatches(permission.Principals, principalARN)
+		deploymentAllowed := len(permission.Deployments) == 0 || slices.HasString(permission.Deployments, appName)
+		if principalAllowed && deploymentAllowed {
+			return true
+		}
+	}
+	return !restricted
+}
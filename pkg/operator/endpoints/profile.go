@@ -0,0 +1,83 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/workloads"
+)
+
+func ProfileReplica(w http.ResponseWriter, r *http.Request) {
+	apiName, err := getRequiredPathParam("apiName", r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	appName, err := getRequiredQueryParam("appName", r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	ctx := workloads.CurrentContext(appName)
+	if ctx == nil {
+		RespondError(w, ErrorAppNotDeployed(appName))
+		return
+	}
+
+	api := ctx.APIs[apiName]
+	if api == nil {
+		RespondError(w, ErrorAPINotDeployed(apiName, appName))
+		return
+	}
+
+	// the app isn't locked while its replica is profiled, since profiles can take minutes
+	if !canModifyAPI(api, callerARN(r)) {
+		RespondErrorCode(w, http.StatusForbidden, ErrorAPINotOwned(apiName, api.Owner))
+		return
+	}
+
+	profileType := workloads.CPUProfileType
+	if typeStr := getOptionalQParam("type", r); typeStr != "" {
+		if !slices.HasString(workloads.ProfileTypes, typeStr) {
+			RespondError(w, ErrorInvalidQueryParam("type", typeStr, "profile type ("+s.StrsOr(workloads.ProfileTypes)+")"))
+			return
+		}
+		profileType = typeStr
+	}
+
+	duration := workloads.DefaultProfileDuration
+	if durationStr := getOptionalQParam("duration", r); durationStr != "" {
+		duration, err = time.ParseDuration(durationStr)
+		if err != nil || duration < time.Second || duration > workloads.MaxProfileDuration || duration%time.Second != 0 {
+			RespondError(w, ErrorInvalidQueryParam("duration", durationStr, "whole number of seconds between 1s and "+workloads.MaxProfileDuration.String()))
+			return
+		}
+	}
+
+	response, err := workloads.ProfileReplica(ctx, apiName, getOptionalQParam("replica", r), profileType, duration)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+	Respond(w, response)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	ocontext "github.com/cortexlabs/cortex/pkg/operator/context"
+)
+
+// the hashes are hex-encoded sha256 digests, trimmed to 63 characters by hash.Bytes
+var _projectFileHashRegex = regexp.MustCompile(`^[0-9a-f]{63}$`)
+
+func GetMissingProjectFiles(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		RespondError(w, errors.WithStack(err))
+		return
+	}
+
+	var request schema.ProjectFilesRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	for _, fileHash := range request.Hashes {
+		if !_projectFileHashRegex.MatchString(fileHash) {
+			RespondError(w, ErrorInvalidProjectFileHash(fileHash))
+			return
+		}
+	}
+
+	missingHashes, err := ocontext.MissingProjectFiles(request.Hashes)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	Respond(w, schema.ProjectFilesResponse{MissingHashes: missingHashes})
+}
+
+// readProjectFiles reads the request's project manifest (path -> hash of the file's contents), along with the files which it includes that aren't in the project file store yet (keyed by hash).
+// The manifest is nil if the request doesn't include one (e.g. if the project was uploaded as a zip)
+func readProjectFiles(r *http.Request) (map[string]string, map[string][]byte, error) {
+	manifestBytes, err := files.ReadReqFile(r, userconfig.ProjectManifestFileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(manifestBytes) == 0 {
+		return nil, nil, nil
+	}
+
+	var manifest map[string]string
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, nil, err
+	}
+
+	uploadedFiles, err := files.ReadReqFilesWithPrefix(r, userconfig.ProjectFilesDirName+"/")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newFiles := make(map[string][]byte, len(uploadedFiles))
+	for fileName, fileBytes := range uploadedFiles {
+		fileHash := strings.TrimPrefix(fileName, userconfig.ProjectFilesDirName+"/")
+		if hash.Bytes(fileBytes) != fileHash {
+			return nil, nil, ErrorInvalidProjectFileHash(fileHash)
+		}
+		newFiles[fileHash] = fileBytes
+	}
+
+	for path, fileHash := range manifest {
+		if filepath.IsAbs(path) || filepath.Clean(path) != path || path == ".." || strings.HasPrefix(path, "../") {
+			return nil, nil, ErrorInvalidProjectFilePath(path)
+		}
+		if !_projectFileHashRegex.MatchString(fileHash) {
+			return nil, nil, ErrorInvalidProjectFileHash(fileHash)
+		}
+	}
+
+	return manifest, newFiles, nil
+}
+
+// assembleProject zips the project which is described by the manifest, after checking that the files which weren't uploaded are in the project file store
+func assembleProject(manifest map[string]string, newFiles map[string][]byte) ([]byte, error) {
+	var storedHashes []string
+	for _, fileHash := range manifest {
+		if _, ok := newFiles[fileHash]; !ok {
+			storedHashes = append(storedHashes, fileHash)
+		}
+	}
+
+	missingHashes, err := ocontext.MissingProjectFiles(storedHashes)
+	if err != nil {
+		return nil, err
+	}
+	if len(missingHashes) > 0 {
+		isMissing := make(map[string]bool, len(missingHashes))
+		for _, fileHash := range missingHashes {
+			isMissing[fileHash] = true
+		}
+		var missingPaths []string
+		for path, fileHash := range manifest {
+			if isMissing[fileHash] {
+				missingPaths = append(missingPaths, path)
+			}
+		}
+		sort.Strings(missingPaths)
+		return nil, ErrorProjectFilesMissing(missingPaths)
+	}
+
+	return ocontext.AssembleProject(manifest, newFiles)
+}
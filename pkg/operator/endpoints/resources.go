@@ -30,37 +30,48 @@ func GetResources(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	response, err := getResourcesResponse(appName)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	Respond(w, response)
+}
+
+func getResourcesResponse(appName string) (*schema.GetResourcesResponse, error) {
 	ctx := workloads.CurrentContext(appName)
 	if ctx == nil {
-		RespondError(w, ErrorAppNotDeployed(appName))
-		return
+		return nil, ErrorAppNotDeployed(appName)
 	}
 
 	dataStatuses, err := workloads.GetCurrentDataStatuses(ctx)
 	if err != nil {
-		RespondError(w, err)
-		return
+		return nil, err
 	}
 
 	apiStatuses, apiGroupStatuses, err := workloads.GetCurrentAPIAndGroupStatuses(dataStatuses, ctx)
 	if err != nil {
-		RespondError(w, err)
-		return
+		return nil, err
 	}
 
 	apisBaseURL, err := workloads.APIsBaseURL()
 	if err != nil {
-		RespondError(w, err)
-		return
+		return nil, err
 	}
 
-	response := schema.GetResourcesResponse{
-		Context:          ctx,
-		DataStatuses:     dataStatuses,
-		APIStatuses:      apiStatuses,
-		APIGroupStatuses: apiGroupStatuses,
-		APIsBaseURL:      apisBaseURL,
+	privateAPIsBaseURL, err := workloads.PrivateAPIsBaseURL(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	Respond(w, response)
+	return &schema.GetResourcesResponse{
+		Context:             ctx,
+		DataStatuses:        dataStatuses,
+		APIStatuses:         apiStatuses,
+		APIGroupStatuses:    apiGroupStatuses,
+		APIsBaseURL:         apisBaseURL,
+		PrivateAPIsBaseURL:  privateAPIsBaseURL,
+		ClusterLocalAPIURLs: workloads.ClusterLocalAPIURLs(ctx),
+	}, nil
 }
@@ -0,0 +1,132 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	ocontext "github.com/cortexlabs/cortex/pkg/operator/context"
+)
+
+var _appLocks = struct {
+	sync.Mutex
+	m map[string]*sync.Mutex
+}{m: make(map[string]*sync.Mutex)}
+
+// lockApp serializes changes to an app, so that API revisions can't change between being checked and being updated
+func lockApp(appName string) func() {
+	_appLocks.Lock()
+	appLock, ok := _appLocks.m[appName]
+	if !ok {
+		appLock = &sync.Mutex{}
+		_appLocks.m[appName] = appLock
+	}
+	_appLocks.Unlock()
+
+	appLock.Lock()
+	return appLock.Unlock
+}
+
+// assignAPIRevisions sets the revision of each API in ctx, incrementing the revisions of APIs which have changed
+func assignAPIRevisions(ctx *context.Context, existingCtx *context.Context) {
+	for apiName, api := range ctx.APIs {
+		var prevAPI *context.API
+		if existingCtx != nil {
+			prevAPI = existingCtx.APIs[apiName]
+		}
+
+		switch {
+		case prevAPI == nil:
+			api.Revision = 1
+		case prevAPI.ID == api.ID && prevAPI.Compute.ID() == api.Compute.ID() && prevAPI.ConfigID() == api.ConfigID():
+			api.Revision = prevAPI.Revision
+		default:
+			api.Revision = prevAPI.Revision + 1
+		}
+	}
+}
+
+// storeAPIRevisions stores the configuration of each API whose revision is new, so that it can be compared with the API's other revisions
+func storeAPIRevisions(ctx *context.Context, existingCtx *context.Context) error {
+	for apiName, api := range ctx.APIs {
+		if existingCtx != nil {
+			if prevAPI := existingCtx.APIs[apiName]; prevAPI != nil && prevAPI.Revision == api.Revision {
+				continue
+			}
+		}
+		if err := config.AWS.UploadJSONToS3(api.API, ocontext.APIRevisionKey(apiName, api.Revision, ctx.App.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAPIRevisions returns an error if any of the APIs' current revisions differ from the revisions which the client last deployed
+// (expectedRevisions maps API name to revision, where 0 indicates that the API was not deployed; APIs which are not in expectedRevisions are not checked)
+func checkAPIRevisions(ctx *context.Context, existingCtx *context.Context, expectedRevisions map[string]int64) error {
+	var conflicts []string
+
+	apiNames := make([]string, 0, len(expectedRevisions))
+	for apiName := range expectedRevisions {
+		apiNames = append(apiNames, apiName)
+	}
+	sort.Strings(apiNames)
+
+	for _, apiName := range apiNames {
+		expectedRevision := expectedRevisions[apiName]
+
+		var currentAPI *context.API
+		if existingCtx != nil {
+			currentAPI = existingCtx.APIs[apiName]
+		}
+
+		var currentRevision int64
+		if currentAPI != nil {
+			currentRevision = currentAPI.Revision
+		}
+
+		if currentRevision == expectedRevision {
+			continue
+		}
+
+		if currentAPI == nil {
+			conflicts = append(conflicts, fmt.Sprintf("%s has been deleted", apiName))
+			continue
+		}
+
+		conflict := fmt.Sprintf("%s has been updated to revision %d (your last deploy was revision %d)", apiName, currentRevision, expectedRevision)
+		if expectedRevision == 0 {
+			conflict = fmt.Sprintf("%s has been created", apiName)
+		}
+
+		if api := ctx.APIs[apiName]; api != nil {
+			conflict += "; the differences between it and your configuration are:\n" + s.LineDiff(strings.TrimSpace(currentAPI.UserConfigStr()), strings.TrimSpace(api.UserConfigStr()))
+		}
+		conflicts = append(conflicts, conflict)
+	}
+
+	if len(conflicts) > 0 {
+		return ErrorDeployConflict(conflicts)
+	}
+	return nil
+}
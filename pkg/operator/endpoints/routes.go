@@ -0,0 +1,402 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"strings"
+
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+)
+
+// APIVersionPrefix is prepended to the path of each route
+const APIVersionPrefix = "/v1"
+
+type QueryParamType string
+
+const (
+	StringQueryParam QueryParamType = "string"
+	BoolQueryParam   QueryParamType = "boolean"
+	IntQueryParam    QueryParamType = "integer"
+)
+
+type QueryParam struct {
+	Name        string
+	Type        QueryParamType
+	Required    bool
+	Description string
+}
+
+type Route struct {
+	Method      string
+	Path        string // relative to APIVersionPrefix; path params are written as {paramName}
+	LegacyPath  string // unversioned path which older CLIs use (optional)
+	Handler     http.HandlerFunc
+	Summary     string
+	Tag         string
+	QueryParams []QueryParam
+	FormFiles   []string    // multipart form files which the request may contain
+	Response    interface{} // a value of the response's type, used to document it
+	WebSocket   bool
+}
+
+var appNameQueryParam = QueryParam{Name: "appName", Type: StringQueryParam, Required: true, Description: "name of the deployment"}
+
+var Routes = []*Route{
+	{
+		Method:     http.MethodGet,
+		Path:       "/info",
+		LegacyPath: "/info",
+		Handler:    Info,
+		Summary:    "get information about the cluster",
+		Tag:        "cluster",
+		Response:   schema.InfoResponse{},
+	},
+	{
+		Method:     http.MethodGet,
+		Path:       "/schema",
+		LegacyPath: "/schema",
+		Handler:    GetSchema,
+		Summary:    "get the JSON schema of cortex.yaml",
+		Tag:        "cluster",
+		Response:   map[string]interface{}{},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/logging/level",
+		Handler:  GetLogLevel,
+		Summary:  "get the operator's log level",
+		Tag:      "cluster",
+		Response: schema.LogLevelResponse{},
+	},
+	{
+		Method:  http.MethodPost,
+		Path:    "/logging/level",
+		Handler: SetLogLevel,
+		Summary: "change the operator's log level (until the operator restarts); requires an operator admin",
+		Tag:     "cluster",
+		QueryParams: []QueryParam{
+			{Name: "level", Type: StringQueryParam, Required: true, Description: "debug, info, warn, or error"},
+		},
+		Response: schema.LogLevelResponse{},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/costs",
+		Handler:  GetCosts,
+		Summary:  "get the estimated costs of the cluster and of each API",
+		Tag:      "cluster",
+		Response: schema.CostsResponse{},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/cluster/capacity",
+		Handler:  GetCapacity,
+		Summary:  "get the compute which is schedulable by API replicas on a node of each node group",
+		Tag:      "cluster",
+		Response: schema.CapacityResponse{},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/cluster/quotas",
+		Handler:  GetQuotas,
+		Summary:  "get the compute which the APIs of each of the cluster's quotas request at their maximum replicas",
+		Tag:      "cluster",
+		Response: schema.QuotasResponse{},
+	},
+	{
+		Method:     http.MethodPost,
+		Path:       "/deploy",
+		LegacyPath: "/deploy",
+		Handler:    Deploy,
+		Summary:    "create or update a deployment",
+		Tag:        "deployments",
+		QueryParams: []QueryParam{
+			{Name: "ignoreCache", Type: BoolQueryParam, Description: "redeploy all APIs, even if they are up to date"},
+			{Name: "force", Type: BoolQueryParam, Description: "override an in-progress deployment, and skip the revisions.json check"},
+			{Name: "prune", Type: BoolQueryParam, Description: "delete the deployment's APIs which aren't defined in the configuration; if false, the deploy is rejected if there are any (default: true)"},
+			{Name: "dryRun", Type: BoolQueryParam, Description: "validate the deployment and describe the changes which it would make, without applying them"},
+			{Name: "checkRequirements", Type: BoolQueryParam, Description: "check that the project's python requirements can be installed before deploying"},
+		},
+		FormFiles: []string{"cortex.yaml", "project.zip", userconfig.ProjectManifestFileName, userconfig.ProjectFilesDirName + "/*", "variables.json", "revisions.json", userconfig.ConfigDirName + "/*"},
+		Response:  schema.DeployResponse{},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/projects/missing",
+		Handler:  GetMissingProjectFiles,
+		Summary:  "get which of the project files (identified by the hashes of their contents) must be uploaded with the next deploy",
+		Tag:      "deployments",
+		Response: schema.ProjectFilesResponse{},
+	},
+	{
+		Method:  http.MethodPost,
+		Path:    "/federation/deploy",
+		Handler: FederatedDeploy,
+		Summary: "create or update a deployment in this cluster and in each of its federated clusters which the deployment's APIs select",
+		Tag:     "federation",
+		QueryParams: []QueryParam{
+			{Name: "ignoreCache", Type: BoolQueryParam, Description: "redeploy all APIs, even if they are up to date"},
+			{Name: "force", Type: BoolQueryParam, Description: "override in-progress deployments"},
+			{Name: "prune", Type: BoolQueryParam, Description: "delete the deployment's APIs which aren't defined in the configuration (or which no longer select a cluster) (default: true)"},
+			{Name: "dryRun", Type: BoolQueryParam, Description: "validate the deployment and describe the changes which it would make in each cluster, without applying them"},
+			{Name: "checkRequirements", Type: BoolQueryParam, Description: "check that the project's python requirements can be installed before deploying"},
+		},
+		FormFiles: []string{"cortex.yaml", "project.zip", userconfig.ProjectManifestFileName, userconfig.ProjectFilesDirName + "/*", "variables.json", userconfig.ConfigDirName + "/*"},
+		Response:  schema.FederatedDeployResponse{},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/federation/apis",
+		Handler:     GetFederatedResources,
+		Summary:     "get the statuses of a deployment's APIs in this cluster and in each of its federated clusters",
+		Tag:         "federation",
+		QueryParams: []QueryParam{appNameQueryParam},
+		Response:    schema.FederatedResourcesResponse{},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/deploy/progress",
+		Handler:     StreamDeployProgress,
+		Summary:     "stream the progress of the deployment's rollout (websocket; each message is a JSON-encoded DeployProgressEvent)",
+		Tag:         "deployments",
+		QueryParams: []QueryParam{appNameQueryParam},
+		WebSocket:   true,
+	},
+	{
+		Method:      http.MethodPost,
+		Path:        "/delete",
+		LegacyPath:  "/delete",
+		Handler:     Delete,
+		Summary:     "delete a deployment",
+		Tag:         "deployments",
+		QueryParams: []QueryParam{appNameQueryParam, {Name: "keepCache", Type: BoolQueryParam, Description: "keep cached data in the bucket"}},
+		Response:    schema.DeleteResponse{},
+	},
+	{
+		Method:      http.MethodPost,
+		Path:        "/promote",
+		LegacyPath:  "/promote",
+		Handler:     Promote,
+		Summary:     "promote an API's blue-green update",
+		Tag:         "apis",
+		QueryParams: []QueryParam{appNameQueryParam, {Name: "apiName", Type: StringQueryParam, Required: true, Description: "name of the API"}},
+		Response:    schema.PromoteResponse{},
+	},
+	{
+		Method:     http.MethodGet,
+		Path:       "/deployments",
+		LegacyPath: "/deployments",
+		Handler:    GetDeployments,
+		Summary:    "list deployments",
+		Tag:        "deployments",
+		Response:   schema.GetDeploymentsResponse{},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/apis",
+		LegacyPath:  "/resources",
+		Handler:     GetResources,
+		Summary:     "get the statuses of a deployment's APIs",
+		Tag:         "apis",
+		QueryParams: []QueryParam{appNameQueryParam},
+		Response:    schema.GetResourcesResponse{},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/apis/{apiName}/status",
+		LegacyPath:  "/apis/{apiName}/status",
+		Handler:     GetAPIStatus,
+		Summary:     "get the detailed status of an API's replicas",
+		Tag:         "apis",
+		QueryParams: []QueryParam{appNameQueryParam},
+		Response:    schema.APIStatusResponse{},
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/apis/{apiName}/diff",
+		Handler: GetAPIDiff,
+		Summary: "get the fields of an API's configuration which changed between two of its revisions",
+		Tag:     "apis",
+		QueryParams: []QueryParam{
+			appNameQueryParam,
+			{Name: "from", Type: IntQueryParam, Description: "the earlier revision (default: the revision before to)"},
+			{Name: "to", Type: IntQueryParam, Description: "the later revision (default: the API's current revision)"},
+		},
+		Response: schema.APIDiffResponse{},
+	},
+	{
+		Method:  http.MethodPost,
+		Path:    "/apis/{apiName}/owner",
+		Handler: TransferAPIOwnership,
+		Summary: "transfer ownership of an API to another IAM user or role",
+		Tag:     "apis",
+		QueryParams: []QueryParam{
+			appNameQueryParam,
+			{Name: "owner", Type: StringQueryParam, Required: true, Description: "ARN of the IAM user or role which will own the API"},
+		},
+		Response: schema.TransferAPIOwnershipResponse{},
+	},
+	{
+		Method:      http.MethodPost,
+		Path:        "/apis/{apiName}/pause",
+		Handler:     Pause,
+		Summary:     "scale an API to zero replicas, keeping its configuration and endpoint",
+		Tag:         "apis",
+		QueryParams: []QueryParam{appNameQueryParam},
+		Response:    schema.PauseResponse{},
+	},
+	{
+		Method:      http.MethodPost,
+		Path:        "/apis/{apiName}/resume",
+		Handler:     Resume,
+		Summary:     "restore the replicas which a paused API had when it was paused",
+		Tag:         "apis",
+		QueryParams: []QueryParam{appNameQueryParam},
+		Response:    schema.ResumeResponse{},
+	},
+	{
+		Method:  http.MethodPost,
+		Path:    "/apis/{apiName}/profile",
+		Handler: ProfileReplica,
+		Summary: "profile one of an API's replicas with py-spy, and get a URL which downloads the profile",
+		Tag:     "apis",
+		QueryParams: []QueryParam{
+			appNameQueryParam,
+			{Name: "replica", Type: StringQueryParam, Description: "name of the replica (pod) to profile (default: one of the API's running replicas)"},
+			{Name: "type", Type: StringQueryParam, Description: "cpu (a flamegraph of where the replica's python processes spend their time) or dump (the current stack of each of their threads) (default: cpu)"},
+			{Name: "duration", Type: StringQueryParam, Description: "how long to sample cpu profiles for, as a whole number of seconds (default: 30s, max: 5m)"},
+		},
+		Response: schema.ProfileResponse{},
+	},
+	{
+		Method:  http.MethodPost,
+		Path:    "/apis/{apiName}/exec",
+		Handler: ExecReplica,
+		Summary: "run a command (the request body's schema.ExecRequest) in one of an API's replicas; requires debug_exec and an operator admin, and is audit logged",
+		Tag:     "apis",
+		QueryParams: []QueryParam{
+			appNameQueryParam,
+			{Name: "replica", Type: StringQueryParam, Description: "name of the replica (pod) to run the command in (default: one of the API's running replicas)"},
+			{Name: "container", Type: StringQueryParam, Description: "api or serve (default: api)"},
+		},
+		Response: schema.ExecResponse{},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/metrics",
+		LegacyPath:  "/metrics",
+		Handler:     GetMetrics,
+		Summary:     "get an API's metrics",
+		Tag:         "apis",
+		QueryParams: []QueryParam{appNameQueryParam, {Name: "apiName", Type: StringQueryParam, Required: true, Description: "name of the API"}},
+		Response:    schema.APIMetrics{},
+	},
+	{
+		Method:     http.MethodGet,
+		Path:       "/logs",
+		LegacyPath: "/logs/read",
+		Handler:    ReadLogs,
+		Summary:    "stream logs (websocket)",
+		Tag:        "logs",
+		QueryParams: []QueryParam{
+			appNameQueryParam,
+			{Name: "resourceName", Type: StringQueryParam, Description: "name of the resource"},
+			{Name: "resourceType", Type: StringQueryParam, Description: "type of the resource"},
+			{Name: "resourceID", Type: StringQueryParam, Description: "ID of the resource"},
+			{Name: "workloadID", Type: StringQueryParam, Description: "ID of the workload"},
+		},
+		WebSocket: true,
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/logs/{apiName}",
+		Handler: QueryLogs,
+		Summary: "query an API's historical logs with CloudWatch Logs Insights",
+		Tag:     "logs",
+		QueryParams: []QueryParam{
+			appNameQueryParam,
+			{Name: "since", Type: StringQueryParam, Description: "how far back to query, as a duration (default: 1h)"},
+			{Name: "level", Type: StringQueryParam, Description: "the least severe level of the records: debug, info, warning, error, or critical"},
+			{Name: "pod", Type: StringQueryParam, Description: "the prefix of the names of the records' pods"},
+			{Name: "q", Type: StringQueryParam, Description: "text which the records contain (case sensitive)"},
+			{Name: "limit", Type: IntQueryParam, Description: "the maximum number of records to return, which are the latest records that match (default: 100, max: 9999)"},
+		},
+		Response: schema.LogsResponse{},
+	},
+}
+
+// PathParams returns the names of the route's path params
+func (route *Route) PathParams() []string {
+	var params []string
+	for _, part := range strings.Split(route.Path, "/") {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			params = append(params, part[1:len(part)-1])
+		}
+	}
+	return params
+}
+
+// ValidatedHandler wraps the route's handler to reject requests with missing or malformed query params
+func (route *Route) ValidatedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := route.validateRequest(r); err != nil {
+			RespondError(w, err)
+			return
+		}
+		route.Handler(w, r)
+	})
+}
+
+func (route *Route) validateRequest(r *http.Request) error {
+	query := r.URL.Query()
+
+	for _, param := range route.QueryParams {
+		value := query.Get(param.Name)
+		if value == "" {
+			if param.Required {
+				return ErrorQueryParamRequired(param.Name)
+			}
+			continue
+		}
+
+		switch param.Type {
+		case BoolQueryParam:
+			if _, ok := s.ParseBool(value); !ok {
+				return ErrorInvalidQueryParam(param.Name, value, string(BoolQueryParam))
+			}
+		case IntQueryParam:
+			if _, ok := s.ParseInt64(value); !ok {
+				return ErrorInvalidQueryParam(param.Name, value, string(IntQueryParam))
+			}
+		}
+	}
+
+	return nil
+}
+
+// NotFound responds with an error envelope for requests which don't match any route
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	RespondErrorCode(w, http.StatusNotFound, ErrorRouteNotFound(r.URL.Path))
+}
+
+// MethodNotAllowed responds with an error envelope for requests which match a route's path but not its method
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	RespondErrorCode(w, http.StatusMethodNotAllowed, ErrorMethodNotAllowed(r.Method, r.URL.Path))
+}
@@ -22,6 +22,7 @@ import (
 	"net/http"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
@@ -56,7 +57,43 @@ func ResDeletingAPI(apiName string) string {
 	return fmt.Sprintf("deleting %s api", apiName)
 }
 
+func ResDryRun(appName string) string {
+	return fmt.Sprintf("dry run: the %s deployment was not changed", appName)
+}
+
+func ResDryRunCreateAPI(apiName string) string {
+	return fmt.Sprintf("%s api would be created", apiName)
+}
+
+func ResDryRunUpdateAPI(apiName string) string {
+	return fmt.Sprintf("%s api would be updated", apiName)
+}
+
+func ResDryRunDeleteAPI(apiName string, prune bool) string {
+	if !prune {
+		return fmt.Sprintf("%s api would not be deleted, and the deploy would be rejected (deploy without --prune=false to delete it)", apiName)
+	}
+	return fmt.Sprintf("%s api would be deleted", apiName)
+}
+
+func ResPromotedAPI(apiName string) string {
+	return fmt.Sprintf("promoted %s api's update", apiName)
+}
+
+func ResPausedAPI(apiName string) string {
+	return fmt.Sprintf("paused %s api", apiName)
+}
+
+func ResResumedAPI(apiName string) string {
+	return fmt.Sprintf("resumed %s api", apiName)
+}
+
+func ResTransferredAPI(apiName string, owner string) string {
+	return fmt.Sprintf("%s is now owned by %s", apiName, owner)
+}
+
 func Respond(w http.ResponseWriter, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
@@ -67,11 +104,14 @@ func RespondError(w http.ResponseWriter, err error, strs ...string) {
 
 func RespondErrorCode(w http.ResponseWriter, code int, err error, strs ...string) {
 	err = errors.Wrap(err, strs...)
-	errors.PrintError(err)
+	RequestLogger(w).With(logging.Fields{"status": code}).Error(err)
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
 	response := schema.ErrorResponse{
-		Error: err.Error(),
+		Error:  err.Error(),
+		Kind:   errors.Kind(err),
+		Status: code,
 	}
 	json.NewEncoder(w).Encode(response)
 }
@@ -0,0 +1,127 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/openapi"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+)
+
+var (
+	_openAPISpec     *openapi.Spec
+	_openAPISpecOnce sync.Once
+)
+
+func GetOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	_openAPISpecOnce.Do(func() {
+		_openAPISpec = buildOpenAPISpec(Routes)
+	})
+	Respond(w, _openAPISpec)
+}
+
+func buildOpenAPISpec(routes []*Route) *openapi.Spec {
+	spec := openapi.New(openapi.Info{
+		Title:       "cortex operator",
+		Description: "requests must include the CortexAPIVersion header (except for /info), and an Authorization header in the form of \"CortexAWS <access key id>|<secret access key>\"",
+		Version:     consts.CortexVersion,
+	})
+	spec.BasePath = APIVersionPrefix
+	spec.Produces = []string{"application/json"}
+	spec.SecurityDefinitions = map[string]*openapi.SecurityScheme{
+		"CortexAWS": {
+			Type:        "apiKey",
+			Name:        "Authorization",
+			In:          "header",
+			Description: "CortexAWS <access key id>|<secret access key>",
+		},
+	}
+	spec.Security = []map[string][]string{{"CortexAWS": {}}}
+
+	errorSchema := spec.SchemaFor(schema.ErrorResponse{})
+
+	for _, route := range routes {
+		operation := &openapi.Operation{
+			Summary:     route.Summary,
+			OperationID: operationID(route),
+			Responses: map[string]openapi.Response{
+				"default": {Description: "error", Schema: errorSchema},
+			},
+		}
+		if route.Tag != "" {
+			operation.Tags = []string{route.Tag}
+		}
+
+		for _, param := range route.PathParams() {
+			operation.Parameters = append(operation.Parameters, &openapi.Parameter{
+				Name:     param,
+				In:       "path",
+				Required: true,
+				Type:     "string",
+			})
+		}
+
+		for _, param := range route.QueryParams {
+			operation.Parameters = append(operation.Parameters, &openapi.Parameter{
+				Name:        param.Name,
+				In:          "query",
+				Description: param.Description,
+				Required:    param.Required,
+				Type:        string(param.Type),
+			})
+		}
+
+		if len(route.FormFiles) > 0 {
+			operation.Consumes = []string{"multipart/form-data"}
+			for _, fileName := range route.FormFiles {
+				operation.Parameters = append(operation.Parameters, &openapi.Parameter{
+					Name: fileName,
+					In:   "formData",
+					Type: "file",
+				})
+			}
+		}
+
+		if route.WebSocket {
+			operation.Description = "this endpoint must be requested with a websocket upgrade; messages are streamed as text frames"
+			operation.Responses["101"] = openapi.Response{Description: "switching protocols"}
+		} else {
+			operation.Responses["200"] = openapi.Response{Description: "success", Schema: spec.SchemaFor(route.Response)}
+		}
+
+		spec.AddOperation(route.Method, route.Path, operation)
+	}
+
+	return spec
+}
+
+// e.g. GET /apis/{apiName}/status -> getApisApiNameStatus
+func operationID(route *Route) string {
+	id := strings.ToLower(route.Method)
+	for _, part := range strings.Split(route.Path, "/") {
+		part = strings.Trim(part, "{}")
+		if part == "" {
+			continue
+		}
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
@@ -17,13 +17,11 @@ limitations under the License.
 package main
 
 import (
-	"log"
 	"net/http"
-	"strings"
 
 	"github.com/cortexlabs/cortex/pkg/consts"
-	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
@@ -38,31 +36,66 @@ var _cachedClientIDs = strset.New()
 
 func main() {
 	if err := config.Init(); err != nil {
-		exit.Error(err)
+		fatal(err)
 	}
 
 	telemetry.Event("operator.init")
 
 	if err := workloads.Init(); err != nil {
-		exit.Error(err)
+		fatal(err)
+	}
+
+	if config.Cluster.GitOps != nil {
+		go endpoints.RunGitOps()
+	}
+
+	if config.Cluster.MLflow != nil {
+		// APIs with mlflow:// models can't be deployed until the registry is reachable, but the operator's other endpoints are still served
+		if err := endpoints.PingMLflow(); err != nil {
+			logging.PrintError(err, "unable to connect to the mlflow registry")
+		}
+		if config.Cluster.MLflow.Watch {
+			go endpoints.RunMLflowWatcher()
+		}
+	}
+
+	go endpoints.RunCortexAPIController()
+	if config.Cluster.OperatorInCluster {
+		if err := serveAdmissionWebhooks(); err != nil {
+			logging.PrintError(err, "unable to serve admission webhooks")
+		}
 	}
 
 	router := mux.NewRouter()
+	router.Use(endpoints.LogRequests)
 	router.Use(panicMiddleware)
 	router.Use(clientIDMiddleware)
 	router.Use(apiVersionCheckMiddleware)
 	router.Use(authMiddleware)
 
-	router.HandleFunc("/info", endpoints.Info).Methods("GET")
-	router.HandleFunc("/deploy", endpoints.Deploy).Methods("POST")
-	router.HandleFunc("/delete", endpoints.Delete).Methods("POST")
-	router.HandleFunc("/deployments", endpoints.GetDeployments).Methods("GET")
-	router.HandleFunc("/metrics", endpoints.GetMetrics).Methods("GET")
-	router.HandleFunc("/resources", endpoints.GetResources).Methods("GET")
-	router.HandleFunc("/logs/read", endpoints.ReadLogs)
+	router.NotFoundHandler = http.HandlerFunc(endpoints.NotFound)
+	router.MethodNotAllowedHandler = http.HandlerFunc(endpoints.MethodNotAllowed)
 
-	log.Print("Running on port " + operatorPortStr)
-	log.Fatal(http.ListenAndServe(":"+operatorPortStr, router))
+	router.HandleFunc("/swagger.json", endpoints.GetOpenAPISpec).Methods("GET")
+
+	v1Router := router.PathPrefix(endpoints.APIVersionPrefix).Subrouter()
+	for _, route := range endpoints.Routes {
+		v1Router.Handle(route.Path, route.ValidatedHandler()).Methods(route.Method)
+
+		// routes without the version prefix are kept for older CLIs
+		if route.LegacyPath != "" {
+			router.Handle(route.LegacyPath, route.ValidatedHandler()).Methods(route.Method)
+		}
+	}
+
+	logging.With(logging.Fields{"port": operatorPortStr}).Info("running")
+	fatal(http.ListenAndServe(":"+operatorPortStr, router))
+}
+
+// fatal logs the error and exits
+func fatal(err error) {
+	logging.PrintError(err)
+	exit.ErrorNoPrint(err)
 }
 
 func panicMiddleware(next http.Handler) http.Handler {
@@ -86,41 +119,27 @@ func clientIDMiddleware(next http.Handler) http.Handler {
 
 func authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-
-		if !strings.HasPrefix(authHeader, "CortexAWS") {
-			endpoints.RespondError(w, endpoints.ErrorAuthHeaderMissing())
+		if r.URL.Path == "/swagger.json" {
+			next.ServeHTTP(w, r)
 			return
 		}
 
-		parts := strings.Split(authHeader[10:], "|")
-		if len(parts) != 2 {
-			endpoints.RespondError(w, endpoints.ErrorAuthHeaderMalformed())
+		identity := authenticate(w, r)
+		if identity == nil {
 			return
 		}
 
-		accessKeyID, secretAccessKey := parts[0], parts[1]
-		userAccountID, validCreds, err := aws.AccountID(accessKeyID, secretAccessKey, *config.Cluster.Region)
-		if err != nil {
-			endpoints.RespondError(w, endpoints.ErrorAuthAPIError())
-			return
-		}
-		if !validCreds {
-			endpoints.RespondErrorCode(w, http.StatusForbidden, endpoints.ErrorAuthInvalid())
-			return
-		}
-		if userAccountID != config.AWS.AccountID {
-			endpoints.RespondErrorCode(w, http.StatusForbidden, endpoints.ErrorAuthOtherAccount())
+		if !authorize(w, r, identity) {
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, endpoints.WithCallerIdentity(r, identity))
 	})
 }
 
 func apiVersionCheckMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/info" {
+		if r.URL.Path == "/info" || r.URL.Path == endpoints.APIVersionPrefix+"/info" || r.URL.Path == "/swagger.json" {
 			next.ServeHTTP(w, r)
 			return
 		}
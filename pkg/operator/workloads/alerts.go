@@ -0,0 +1,294 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/webhooks"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	_errorRateAlertInterval  = 1 * time.Minute
+	_errorRateAlertWindow    = 5 * time.Minute
+	_errorRateAlertThreshold = 0.05
+	_errorRateAlertMinCount  = 10
+	_snsSubjectMaxLength     = 100
+	_alertRulesInterval      = 1 * time.Minute
+)
+
+// Events which are published to the cluster's SNS topic
+var _alertEvents = strset.New(webhooks.DeployFailedEvent, webhooks.CrashLoopingEvent, webhooks.HighErrorRateEvent, webhooks.MaxReplicasEvent, webhooks.DataDriftEvent)
+
+var _lastErrorRateAlertCron time.Time
+
+// APIs whose 5XX rate is above the threshold (resource ID)
+var _highErrorRateAPIs = strset.New()
+
+var _lastAlertRulesCron time.Time
+
+// When each alert rule's metric rose above its threshold (alert rule key -> time)
+var _alertRuleBreaches = make(map[string]time.Time)
+
+// Alert rules which have fired and haven't been resolved (alert rule key)
+var _firingAlertRules = strset.New()
+
+func publishAlert(topicARN string, payload *webhooks.Payload) {
+	message, err := json.Marshal(payload)
+	if err != nil {
+		logging.With(webhookLogFields(payload)).Error(err, "alert")
+		return
+	}
+
+	subject := s.TruncateEllipses(fmt.Sprintf("[cortex %s] %s", payload.Cluster, payload.Message), _snsSubjectMaxLength)
+
+	err = config.AWS.PublishSNS(topicARN, subject, string(message), map[string]string{
+		"event":      payload.Event,
+		"deployment": payload.Deployment,
+		"api":        payload.API,
+	})
+	if err != nil {
+		logging.With(webhookLogFields(payload)).Error(err, "alert")
+	}
+}
+
+// updateErrorRateAlerts emits an event when an API's 5XX rate has been above the threshold throughout the alert window
+func updateErrorRateAlerts() error {
+	now := time.Now()
+	highErrorRateAPIs := strset.New()
+
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			networkStats, err := apiNetworkStats(ctx, api, now.Add(-_errorRateAlertWindow), now)
+			if err != nil {
+				return err
+			}
+			if networkStats.Total < _errorRateAlertMinCount {
+				continue
+			}
+
+			errorRate := float64(networkStats.Code5XX) / float64(networkStats.Total)
+			if errorRate <= _errorRateAlertThreshold {
+				continue
+			}
+
+			highErrorRateAPIs.Add(api.ID)
+			if _highErrorRateAPIs.Has(api.ID) {
+				continue
+			}
+
+			emitEvent(ctx, api, webhooks.HighErrorRateEvent, fmt.Sprintf("%s has a 5XX rate of %s over the last %s", api.Name, s.Float64(errorRate), _errorRateAlertWindow.String()), map[string]interface{}{
+				"error_rate": errorRate,
+				"code_5xx":   networkStats.Code5XX,
+				"total":      networkStats.Total,
+			})
+		}
+	}
+
+	_highErrorRateAPIs = highErrorRateAPIs
+	return nil
+}
+
+func alertRuleKey(api *context.API, alert *userconfig.Alert) string {
+	return api.ID + "/" + alert.Name
+}
+
+// updateAlertRules evaluates each of the APIs' alert rules, firing the rules whose metric has risen above their threshold
+// and resolving the fired rules whose metric has dropped back to or below it
+func updateAlertRules() {
+	now := time.Now()
+	alertRuleBreaches := make(map[string]time.Time)
+	firingAlertRules := strset.New()
+
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			for _, alert := range api.Alerts {
+				key := alertRuleKey(api, alert)
+
+				value, ok, err := alertMetricValue(ctx, api, alert, now)
+				if err != nil {
+					apiCronLog(ctx, api).Error(err, "alert "+alert.Name)
+				}
+				if err != nil || !ok {
+					// the metric is unknown (e.g. the API hasn't received enough requests during the window), so the rule's state is kept
+					if breachStart, ok := _alertRuleBreaches[key]; ok {
+						alertRuleBreaches[key] = breachStart
+					}
+					if _firingAlertRules.Has(key) {
+						firingAlertRules.Add(key)
+					}
+					continue
+				}
+
+				if value <= alert.Threshold {
+					if _firingAlertRules.Has(key) {
+						sendAlert(ctx, api, alert, webhooks.AlertResolvedEvent, value)
+					}
+					continue
+				}
+
+				breachStart, ok := _alertRuleBreaches[key]
+				if !ok {
+					breachStart = now
+				}
+				alertRuleBreaches[key] = breachStart
+
+				if _firingAlertRules.Has(key) {
+					firingAlertRules.Add(key)
+					continue
+				}
+
+				// metrics which are aggregated over the window fire immediately, and the others fire once they've been above the threshold throughout the window
+				if isWindowedAlertMetric(alert.Metric) || now.Sub(breachStart) >= alert.Window {
+					sendAlert(ctx, api, alert, webhooks.AlertFiredEvent, value)
+					firingAlertRules.Add(key)
+				}
+			}
+		}
+	}
+
+	_alertRuleBreaches = alertRuleBreaches
+	_firingAlertRules = firingAlertRules
+}
+
+func isWindowedAlertMetric(metric string) bool {
+	return metric == userconfig.ErrorRateAlertMetric || metric == userconfig.P99LatencyAlertMetric
+}
+
+// alertMetricValue returns the current value of the alert's metric, or false if it's unknown
+func alertMetricValue(ctx *context.Context, api *context.API, alert *userconfig.Alert, now time.Time) (float64, bool, error) {
+	switch alert.Metric {
+	case userconfig.ErrorRateAlertMetric:
+		networkStats, err := apiNetworkStats(ctx, api, now.Add(-alert.Window), now)
+		if err != nil {
+			return 0, false, err
+		}
+		if networkStats.Total < _errorRateAlertMinCount {
+			return 0, false, nil
+		}
+		return float64(networkStats.Code5XX) / float64(networkStats.Total), true, nil
+
+	case userconfig.P99LatencyAlertMetric:
+		return apiP99Latency(ctx, api, now.Add(-alert.Window), now)
+
+	case userconfig.ReplicaSaturationAlertMetric:
+		_, maxReplicas := apiReplicaBounds(api)
+		if maxReplicas == 0 {
+			return 0, false, nil
+		}
+		deploymentName, err := apiDeploymentName(ctx, api)
+		if err != nil {
+			return 0, false, err
+		}
+		deployment, err := config.Kubernetes.GetDeployment(deploymentName)
+		if err != nil {
+			return 0, false, err
+		}
+		if deployment == nil || deployment.Spec.Replicas == nil {
+			return 0, false, nil
+		}
+		return float64(*deployment.Spec.Replicas) / float64(maxReplicas), true, nil
+
+	case userconfig.DriftScoreAlertMetric:
+		dataDrift := getAPIDataDrift(api)
+		if dataDrift == nil || len(dataDrift.Fields) == 0 {
+			return 0, false, nil
+		}
+		driftScore := dataDrift.Fields[0].PSI
+		for _, field := range dataDrift.Fields[1:] {
+			driftScore = math.Max(driftScore, field.PSI)
+		}
+		return driftScore, true, nil
+	}
+
+	return 0, false, nil
+}
+
+// apiP99Latency returns the 99th percentile of the API's request latency (in milliseconds) between startTime and endTime, or false if it received no requests
+func apiP99Latency(ctx *context.Context, api *context.API, startTime time.Time, endTime time.Time) (float64, bool, error) {
+	startTime = startTime.Truncate(time.Minute)
+	output, err := config.AWS.CloudWatchMetrics.GetMetricData(&cloudwatch.GetMetricDataInput{
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		MetricDataQueries: []*cloudwatch.MetricDataQuery{
+			{
+				Id: aws.String("p99_latency"),
+				MetricStat: &cloudwatch.MetricStat{
+					Metric: &cloudwatch.Metric{
+						Namespace:  aws.String(config.Cluster.LogGroup),
+						MetricName: aws.String("Latency"),
+						Dimensions: getAPIDimensionsHistogram(ctx.App.Name, api),
+					},
+					Stat:   aws.String("p99"),
+					Period: aws.Int64(int64(endTime.Sub(startTime).Round(time.Minute) / time.Second)),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	for _, result := range output.MetricDataResults {
+		if len(result.Values) > 0 && result.Values[0] != nil {
+			return *result.Values[0], true, nil // the most recent datapoint
+		}
+	}
+	return 0, false, nil
+}
+
+// sendAlert publishes the alert rule's event to its SNS topic or sends it to its webhook (in the background)
+func sendAlert(ctx *context.Context, api *context.API, alert *userconfig.Alert, event string, value float64) {
+	message := fmt.Sprintf("%s's %s alert fired: its %s is %s, above the threshold of %s", api.Name, alert.Name, alert.Metric, s.Float64(value), s.Float64(alert.Threshold))
+	if event == webhooks.AlertResolvedEvent {
+		message = fmt.Sprintf("%s's %s alert resolved: its %s is %s, within the threshold of %s", api.Name, alert.Name, alert.Metric, s.Float64(value), s.Float64(alert.Threshold))
+	}
+
+	payload := &webhooks.Payload{
+		Event:      event,
+		Timestamp:  time.Now(),
+		Cluster:    config.Cluster.ClusterName,
+		Deployment: ctx.App.Name,
+		API:        api.Name,
+		Message:    message,
+		Details: map[string]interface{}{
+			"alert":     alert.Name,
+			"metric":    alert.Metric,
+			"value":     value,
+			"threshold": alert.Threshold,
+			"window":    alert.Window.String(),
+		},
+	}
+
+	if alert.IsSNS() {
+		go publishAlert(alert.Destination, payload)
+	} else {
+		go sendWebhook(&webhooks.Webhook{URL: alert.Destination}, payload)
+	}
+}
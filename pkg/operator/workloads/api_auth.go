@@ -0,0 +1,76 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// applyAPIAuth configures the istio proxies in the API's pods to only accept requests with a valid token (if the API requires a JWT);
+// the token is validated by a request authentication, and required (along with the API's required claims) by an authorization policy
+func applyAPIAuth(ctx *context.Context, api *context.API) error {
+	name := internalAPIName(api.Name, ctx.App.Name)
+
+	jwt := api.JWTAuth()
+	if jwt == nil {
+		if _, err := config.Kubernetes.DeleteRequestAuthentication(name, consts.K8sNamespace); err != nil {
+			return err
+		}
+		_, err := config.Kubernetes.DeleteAuthorizationPolicy(name, consts.K8sNamespace)
+		return err
+	}
+
+	labels := map[string]string{
+		"appName":      ctx.App.Name,
+		"workloadType": workloadTypeAPI,
+		"apiName":      api.Name,
+	}
+
+	_, err := config.Kubernetes.ApplyRequestAuthentication(k8s.RequestAuthentication(&k8s.RequestAuthenticationSpec{
+		Name:      name,
+		Namespace: consts.K8sNamespace,
+		Selector:  labels,
+		JWTRules: []k8s.JWTRule{
+			{
+				Issuer:    jwt.Issuer,
+				JWKSURI:   jwt.JWKSURI,
+				Audiences: jwt.Audiences,
+			},
+		},
+		Labels: labels,
+	}))
+	if err != nil {
+		return err
+	}
+
+	_, err = config.Kubernetes.ApplyAuthorizationPolicy(k8s.AuthorizationPolicy(&k8s.AuthorizationPolicySpec{
+		Name:      name,
+		Namespace: consts.K8sNamespace,
+		Selector:  labels,
+		Rules: []k8s.AuthorizationRule{
+			{
+				RequestPrincipals: []string{jwt.Issuer + "/*"},
+				Claims:            jwt.RequiredClaims,
+			},
+		},
+		Labels: labels,
+	}))
+	return err
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"strings"
+
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+)
+
+// areAPIDependenciesSucceeded returns whether the APIs which the API depends on have been rolled out (so that the API is only started once they're ready)
+func areAPIDependenciesSucceeded(ctx *context.Context, api *context.API) (bool, error) {
+	for _, apiName := range api.DependsOn {
+		dependencyWorkload := &APIWorkload{
+			singleBaseWorkload(ctx.APIs[apiName], ctx.App.Name, workloadTypeAPI),
+		}
+		isSucceeded, err := dependencyWorkload.IsSucceeded(ctx)
+		if err != nil {
+			return false, err
+		}
+		if !isSucceeded {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// addAPIDependencyEnvVars sets the URLs of the APIs which the API depends on in its api container's environment (e.g. CORTEX_API_TEXT_ENCODER_URL)
+func addAPIDependencyEnvVars(ctx *context.Context, api *context.API, deployment *kapps.Deployment) error {
+	if len(api.DependsOn) == 0 {
+		return nil
+	}
+
+	envVars := make([]kcore.EnvVar, 0, len(api.DependsOn))
+	for _, apiName := range api.DependsOn {
+		url, err := apiURL(ctx, ctx.APIs[apiName])
+		if err != nil {
+			return err
+		}
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  apiDependencyEnvVarName(apiName),
+			Value: url,
+		})
+	}
+
+	containers := deployment.Spec.Template.Spec.Containers
+	for i := range containers {
+		if containers[i].Name == apiContainerName {
+			containers[i].Env = append(containers[i].Env, envVars...)
+		}
+	}
+	return nil
+}
+
+func apiDependencyEnvVarName(apiName string) string {
+	return "CORTEX_API_" + strings.ToUpper(strings.ReplaceAll(apiName, "-", "_")) + "_URL"
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"path"
+
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const apiFilesVolumeName = "api-files"
+
+// updateAPIFileConfigMaps stores the files which fit in each API's config map (see API.ConfigMapFiles);
+// the API's other files are mounted from the project, which is downloaded into the empty dir
+func updateAPIFileConfigMaps(ctx *context.Context) error {
+	var projectFileMap map[string][]byte
+	for _, api := range ctx.APIs {
+		configMapName := apiFilesConfigMapName(api.Name, ctx.App.Name)
+
+		if len(api.ConfigMapFiles) == 0 {
+			config.Kubernetes.DeleteConfigMap(configMapName)
+			continue
+		}
+
+		if projectFileMap == nil {
+			projectBytes, err := config.AWS.ReadBytesFromS3(ctx.ProjectKey)
+			if err != nil {
+				return errors.Wrap(err, ctx.App.Name, "read project")
+			}
+			projectFileMap, err = zip.UnzipMemToMem(projectBytes)
+			if err != nil {
+				return errors.Wrap(err, ctx.App.Name, "read project")
+			}
+		}
+
+		binaryData := make(map[string][]byte, len(api.ConfigMapFiles))
+		for _, i := range api.ConfigMapFiles {
+			binaryData[apiFileKey(i)] = projectFileMap[api.Files[i].Path]
+		}
+
+		configMap := k8s.ConfigMap(&k8s.ConfigMapSpec{
+			Name:      configMapName,
+			Namespace: consts.K8sNamespace,
+			Labels: map[string]string{
+				"appName":      ctx.App.Name,
+				"workloadType": workloadTypeAPI,
+				"apiName":      api.Name,
+			},
+		})
+		configMap.BinaryData = binaryData
+
+		if _, err := config.Kubernetes.ApplyConfigMap(configMap); err != nil {
+			return errors.Wrap(err, userconfig.Identify(api), userconfig.FilesKey)
+		}
+	}
+
+	return nil
+}
+
+func apiFileVolumes(api *context.API, appName string) []kcore.Volume {
+	if len(api.ConfigMapFiles) == 0 {
+		return nil
+	}
+	return []kcore.Volume{
+		k8s.ConfigMapVolume(apiFilesVolumeName, apiFilesConfigMapName(api.Name, appName)),
+	}
+}
+
+func apiFileVolumeMounts(api *context.API) []kcore.VolumeMount {
+	inConfigMap := make(map[int]bool, len(api.ConfigMapFiles))
+	for _, i := range api.ConfigMapFiles {
+		inConfigMap[i] = true
+	}
+
+	volumeMounts := make([]kcore.VolumeMount, 0, len(api.Files))
+	for i, file := range api.Files {
+		if inConfigMap[i] {
+			volumeMounts = append(volumeMounts, k8s.SubPathVolumeMount(apiFilesVolumeName, file.MountPath, apiFileKey(i), true))
+		} else {
+			volumeMounts = append(volumeMounts, k8s.SubPathVolumeMount(consts.EmptyDirVolumeName, file.MountPath, path.Join("project", file.Path), true))
+		}
+	}
+	return volumeMounts
+}
+
+func apiFileKey(index int) string {
+	return "file-" + s.Int(index)
+}
+
+func apiFilesConfigMapName(apiName string, appName string) string {
+	return internalAPIName(apiName, appName) + "-files"
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// the deploying principal's ARN isn't a valid label value, so it is stored as an annotation
+const apiOwnerAnnotation = "apiOwner"
+
+func apiDeploymentAnnotations(api *context.API) map[string]string {
+	if api.Owner == "" {
+		return nil
+	}
+	return map[string]string{
+		apiOwnerAnnotation: api.Owner,
+	}
+}
+
+// SetAPIOwner records a new owner for a deployed API (the API's pods are not restarted)
+func SetAPIOwner(ctx *context.Context, apiName string, owner string) error {
+	updatedAPI := *ctx.APIs[apiName]
+	updatedAPI.Owner = owner
+	if _, err := setCurrentContextAPI(ctx, &updatedAPI); err != nil {
+		return err
+	}
+
+	deployments, err := config.Kubernetes.ListDeploymentsByLabels(map[string]string{
+		"appName":      ctx.App.Name,
+		"workloadType": workloadTypeAPI,
+		"apiName":      apiName,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range deployments {
+		deployment := &deployments[i]
+		if deployment.Annotations == nil {
+			deployment.Annotations = map[string]string{}
+		}
+		deployment.Annotations[apiOwnerAnnotation] = owner
+		if _, err := config.Kubernetes.ApplyDeployment(deployment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"sort"
+	"time"
+
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	_apiEventsMaxAge = 1 * time.Hour
+	_apiEventsLimit  = 50
+)
+
+var imagePullReasons = strset.New("ErrImagePull", "ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull")
+
+// GetAPIReplicas returns the state of each of the API's replicas, and the recent k8s events for its replicas and deployments
+func GetAPIReplicas(ctx *context.Context, apiName string) (*schema.APIStatusResponse, error) {
+	api := ctx.APIs[apiName]
+
+	pods, err := config.Kubernetes.ListPodsByLabels(map[string]string{
+		"appName":      ctx.App.Name,
+		"workloadType": workloadTypeAPI,
+		"apiName":      api.Name,
+		"userFacing":   "true",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The API's deployments and HPAs share the same names
+	objectNames := strset.New(
+		internalAPIName(api.Name, ctx.App.Name),
+		blueDeploymentName(api, ctx.App.Name),
+		greenDeploymentName(api, ctx.App.Name),
+	)
+
+	var memoryRecommendation *schema.MemoryRecommendation
+	replicas := make([]schema.ReplicaStatus, len(pods))
+	for i := range pods {
+		replicas[i] = replicaStatus(&pods[i], api)
+		if memoryRecommendation == nil && isAPIPodOOMKilled(&pods[i]) {
+			memoryRecommendation = apiMemoryRecommendation(api)
+		}
+		objectNames.Add(pods[i].Name)
+		for _, ownerReference := range pods[i].OwnerReferences {
+			objectNames.Add(ownerReference.Name) // replica sets
+		}
+	}
+
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i].Name < replicas[j].Name
+	})
+
+	events, err := recentEvents(objectNames)
+	if err != nil {
+		return nil, err
+	}
+
+	crashLoop, err := getAPICrashLoop(ctx, api)
+	if err != nil {
+		return nil, err
+	}
+
+	rolloutStall, err := getAPIRolloutStall(ctx, api, pods)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.APIStatusResponse{
+		APIName:              api.Name,
+		Replicas:             replicas,
+		Events:               events,
+		MemoryRecommendation: memoryRecommendation,
+		CrashLoop:            crashLoop,
+		RolloutStall:         rolloutStall,
+		Drift:                getAPIDrift(api),
+		DataDrift:            getAPIDataDrift(api),
+		FeedbackMetrics:      getAPIFeedbackMetrics(api),
+		Cost:                 getAPICost(ctx, api),
+		Idle:                 getAPIIdle(api),
+		DashboardURL:         getAPIDashboardURL(ctx, api),
+	}, nil
+}
+
+func replicaStatus(pod *kcore.Pod, api *context.API) schema.ReplicaStatus {
+	replica := schema.ReplicaStatus{
+		Name: pod.Name,
+		Node: pod.Spec.NodeName,
+	}
+	if pod.Status.StartTime != nil {
+		replica.StartTime = &pod.Status.StartTime.Time
+	}
+
+	containerStatuses := append(pod.Status.InitContainerStatuses[:len(pod.Status.InitContainerStatuses):len(pod.Status.InitContainerStatuses)], pod.Status.ContainerStatuses...)
+	for _, containerStatus := range containerStatuses {
+		replica.Restarts += containerStatus.RestartCount
+	}
+
+	if pod.Status.Reason == k8s.ReasonEvicted {
+		replica.State = schema.ReplicaStateFailed
+		replica.Reason = schema.ReplicaReasonEvicted
+		replica.Message = pod.Status.Message
+		return replica
+	}
+
+	for _, containerStatus := range containerStatuses {
+		if failed := classifyContainerFailure(&replica, containerStatus); failed {
+			replica.State = schema.ReplicaStateFailed
+			return replica
+		}
+	}
+
+	if pod.Status.Phase == kcore.PodFailed {
+		replica.State = schema.ReplicaStateFailed
+		replica.Reason = pod.Status.Reason
+		replica.Message = pod.Status.Message
+		return replica
+	}
+
+	switch {
+	case pod.DeletionTimestamp != nil:
+		replica.State = schema.ReplicaStateTerminating
+	case pod.Labels["resourceID"] != api.ID || APIPodComputeID(pod.Spec.Containers) != api.Compute.IDWithoutReplicas():
+		replica.State = schema.ReplicaStateStale
+	case k8s.IsPodReady(pod):
+		replica.State = schema.ReplicaStateReady
+	case pod.Status.Phase == kcore.PodPending && k8s.GetPodStatus(pod) != k8s.PodStatusInitializing:
+		replica.State = schema.ReplicaStatePending
+	default:
+		replica.State = schema.ReplicaStateInitializing
+	}
+
+	return replica
+}
+
+// classifyContainerFailure sets the replica's failure reason if the container has failed (and returns whether it has)
+func classifyContainerFailure(replica *schema.ReplicaStatus, containerStatus kcore.ContainerStatus) bool {
+	lastTermination := containerStatus.LastTerminationState.Terminated
+	if containerStatus.State.Terminated != nil {
+		lastTermination = containerStatus.State.Terminated
+	}
+	if lastTermination != nil {
+		replica.ExitCode = &lastTermination.ExitCode
+	}
+
+	if waiting := containerStatus.State.Waiting; waiting != nil {
+		switch {
+		case imagePullReasons.Has(waiting.Reason):
+			replica.Reason = schema.ReplicaReasonImagePull
+		case waiting.Reason == "CrashLoopBackOff":
+			replica.Reason = schema.ReplicaReasonCrashLoop
+			if lastTermination != nil && lastTermination.Reason == "OOMKilled" {
+				replica.Reason = schema.ReplicaReasonOOMKilled
+			}
+		default:
+			replica.ExitCode = nil
+			return false
+		}
+		replica.Container = containerStatus.Name
+		replica.Message = waiting.Message
+		if lastTermination != nil && lastTermination.Message != "" {
+			replica.Message = lastTermination.Message
+		}
+		return true
+	}
+
+	if terminated := containerStatus.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+		replica.Container = containerStatus.Name
+		replica.Reason = terminated.Reason
+		if terminated.Reason == "OOMKilled" {
+			replica.Reason = schema.ReplicaReasonOOMKilled
+		}
+		replica.Message = terminated.Message
+		return true
+	}
+
+	replica.ExitCode = nil
+	return false
+}
+
+func recentEvents(objectNames strset.Set) ([]schema.Event, error) {
+	k8sEvents, err := config.Kubernetes.ListEvents(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []schema.Event{}
+	for i := range k8sEvents {
+		k8sEvent := &k8sEvents[i]
+		eventTime := k8s.GetEventTime(k8sEvent)
+		if !objectNames.Has(k8sEvent.InvolvedObject.Name) || time.Since(eventTime) > _apiEventsMaxAge {
+			continue
+		}
+		events = append(events, schema.Event{
+			Time:    eventTime,
+			Object:  k8sEvent.InvolvedObject.Kind + "/" + k8sEvent.InvolvedObject.Name,
+			Type:    k8sEvent.Type,
+			Reason:  k8sEvent.Reason,
+			Message: k8sEvent.Message,
+			Count:   k8sEvent.Count,
+		})
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Time.After(events[j].Time)
+	})
+	if len(events) > _apiEventsLimit {
+		events = events[:_apiEventsLimit]
+	}
+
+	return events, nil
+}
@@ -107,10 +107,11 @@ func getCurrentAPIStatuses(
 				},
 			}
 		}
-		apiStatuses[resourceID].MinReplicas = api.Compute.MinReplicas
-		apiStatuses[resourceID].MaxReplicas = api.Compute.MaxReplicas
+		apiStatuses[resourceID].MinReplicas, apiStatuses[resourceID].MaxReplicas = apiReplicaBounds(api)
 		apiStatuses[resourceID].InitReplicas = api.Compute.InitReplicas
 		apiStatuses[resourceID].TargetCPUUtilization = api.Compute.TargetCPUUtilization
+		apiStatuses[resourceID].TargetGPUUtilization = api.Compute.TargetGPUUtilization
+		apiStatuses[resourceID].GitCommit = api.GitCommit
 		currentAPIResourceIDs.Add(resourceID)
 	}
 
@@ -118,8 +119,13 @@ func getCurrentAPIStatuses(
 		apiStatus.ReplicaCounts = replicaCountsMap[resourceID]
 		apiStatus.PodStatuses = podStatusMap[resourceID]
 		apiStatus.Code = apiStatusCode(apiStatus)
+		if api := ctx.APIs.OneByID(resourceID); api != nil && api.WorkloadID == apiStatus.WorkloadID && apiStatus.Code == resource.StatusStopped && isAPIPaused(api) {
+			apiStatus.Code = resource.StatusPaused
+		}
 	}
 
+	setCrashLoopingAPIStatusCodes(apiStatuses, deployments)
+
 	for _, apiStatus := range apiStatuses {
 		if currentAPIResourceIDs.Has(apiStatus.ResourceID) {
 			updateAPIStatusCodeByParents(apiStatus, dataStatuses, ctx)
@@ -128,6 +134,8 @@ func getCurrentAPIStatuses(
 
 	setInsufficientComputeAPIStatusCodes(apiStatuses, ctx)
 
+	setStalledAPIStatusCodes(apiStatuses, deployments)
+
 	return apiStatuses, nil
 }
 
@@ -361,6 +369,9 @@ func apiGroupStatusCode(apiStatuses []*resource.APIStatus, groupedReplicaCounts
 		if groupedReplicaCounts.Available() > 0 {
 			return resource.StatusStopping
 		}
+		if ctxAPI != nil && isAPIPaused(ctxAPI) {
+			return resource.StatusPaused
+		}
 		return resource.StatusStopped
 	}
 
@@ -402,15 +413,17 @@ func getRequestedReplicas(api *context.API, k8sRequested int32, hpa *kautoscalin
 		k8sRequested = hpa.Spec.MaxReplicas
 	}
 
+	minReplicas, maxReplicas := apiReplicaBounds(api)
+
 	requestedReplicas := api.Compute.InitReplicas
 	if k8sRequested > 0 {
 		requestedReplicas = k8sRequested
 	}
-	if requestedReplicas < api.Compute.MinReplicas {
-		requestedReplicas = api.Compute.MinReplicas
+	if requestedReplicas < minReplicas {
+		requestedReplicas = minReplicas
 	}
-	if requestedReplicas > api.Compute.MaxReplicas {
-		requestedReplicas = api.Compute.MaxReplicas
+	if requestedReplicas > maxReplicas {
+		requestedReplicas = maxReplicas
 	}
 	return requestedReplicas
 }
@@ -21,9 +21,12 @@ import (
 	"fmt"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 
 	kapps "k8s.io/api/apps/v1"
 	kcore "k8s.io/api/core/v1"
@@ -33,7 +36,9 @@ import (
 
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/maps"
 	"github.com/cortexlabs/cortex/pkg/operator/api/context"
 	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
@@ -41,11 +46,14 @@ import (
 
 const (
 	apiContainerName            = "api"
-	tfServingContainerName      = "serve"
+	servingContainerName        = "serve" // the sidecar which serves the model (for the tensorflow and triton predictor types)
 	downloaderInitContainerName = "downloader"
 
-	defaultPortInt32, defaultPortStr     = int32(8888), "8888"
-	tfServingPortInt32, tfServingPortStr = int32(9000), "9000"
+	defaultPortInt32, defaultPortStr             = int32(8888), "8888"
+	tfServingPortInt32, tfServingPortStr         = int32(9000), "9000"
+	tritonHTTPPortInt32, tritonHTTPPortStr       = int32(8000), "8000"
+	tritonGRPCPortInt32, tritonGRPCPortStr       = int32(8001), "8001"
+	tritonMetricsPortInt32, tritonMetricsPortStr = int32(8002), "8002"
 )
 
 type APIWorkload struct {
@@ -80,7 +88,10 @@ func extractAPIWorkloads(ctx *context.Context) []Workload {
 func (aw *APIWorkload) Start(ctx *context.Context) error {
 	api := ctx.APIs.OneByID(aw.GetSingleResourceID())
 
-	k8sDeloymentName := internalAPIName(api.Name, ctx.App.Name)
+	k8sDeloymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return err
+	}
 	k8sDeloyment, err := config.Kubernetes.GetDeployment(k8sDeloymentName)
 	if err != nil {
 		return err
@@ -92,24 +103,26 @@ func (aw *APIWorkload) Start(ctx *context.Context) error {
 
 	desiredReplicas := getRequestedReplicasFromDeployment(api, k8sDeloyment, hpa)
 
-	var deploymentSpec *kapps.Deployment
-	switch api.Predictor.Type {
-	case userconfig.TensorFlowPredictorType:
-		deploymentSpec = tfAPISpec(ctx, api, aw.WorkloadID, desiredReplicas)
-	case userconfig.ONNXPredictorType:
-		deploymentSpec = onnxAPISpec(ctx, api, aw.WorkloadID, desiredReplicas)
-	case userconfig.PythonPredictorType:
-		deploymentSpec = pythonAPISpec(ctx, api, aw.WorkloadID, desiredReplicas)
-	default:
-		return errors.New(api.Name, "unknown model format encountered") // unexpected
+	deploymentSpec, err := apiDeploymentSpec(ctx, api, aw.WorkloadID, k8sDeloymentName, desiredReplicas)
+	if err != nil {
+		return err
 	}
 
-	_, err = config.Kubernetes.ApplyService(serviceSpec(ctx, api))
+	_, err = config.Kubernetes.ApplyService(serviceSpec(ctx, api, k8sDeloymentName))
 	if err != nil {
 		return err
 	}
 
-	_, err = config.Kubernetes.ApplyVirtualService(virtualServiceSpec(ctx, api))
+	if api.IsClusterLocal() {
+		err = applyClusterLocalAPI(ctx, api, k8sDeloymentName)
+	} else {
+		err = routes().applyRoutes(ctx, api, k8sDeloymentName)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = applyAPIAuth(ctx, api)
 	if err != nil {
 		return err
 	}
@@ -132,9 +145,44 @@ func (aw *APIWorkload) Start(ctx *context.Context) error {
 	return nil
 }
 
+// apiDeploymentSpec returns the API's deployment, annotated with its spec hash (so that drift can be detected)
+func apiDeploymentSpec(ctx *context.Context, api *context.API, workloadID string, deploymentName string, desiredReplicas int32) (*kapps.Deployment, error) {
+	var deploymentSpec *kapps.Deployment
+	switch api.Predictor.Type {
+	case userconfig.TensorFlowPredictorType:
+		deploymentSpec = tfAPISpec(ctx, api, workloadID, deploymentName, desiredReplicas)
+	case userconfig.ONNXPredictorType:
+		deploymentSpec = onnxAPISpec(ctx, api, workloadID, deploymentName, desiredReplicas)
+	case userconfig.PythonPredictorType:
+		deploymentSpec = pythonAPISpec(ctx, api, workloadID, deploymentName, desiredReplicas)
+	case userconfig.SKLearnPredictorType, userconfig.XGBoostPredictorType, userconfig.LightGBMPredictorType:
+		deploymentSpec = modelFileAPISpec(ctx, api, frameworkServingImage(api.Predictor.Type), workloadID, deploymentName, desiredReplicas)
+	case userconfig.TritonPredictorType:
+		deploymentSpec = tritonAPISpec(ctx, api, workloadID, deploymentName, desiredReplicas)
+	case userconfig.PipelinePredictorType:
+		var err error
+		deploymentSpec, err = pipelineAPISpec(ctx, api, workloadID, deploymentName, desiredReplicas)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New(api.Name, "unknown model format encountered") // unexpected
+	}
+
+	if err := addAPIDependencyEnvVars(ctx, api, deploymentSpec); err != nil {
+		return nil, err
+	}
+
+	k8s.SetSpecHashAnnotation(deploymentSpec, k8s.DeploymentSpecHash(deploymentSpec))
+	return deploymentSpec, nil
+}
+
 func (aw *APIWorkload) IsSucceeded(ctx *context.Context) (bool, error) {
 	api := ctx.APIs.OneByID(aw.GetSingleResourceID())
-	k8sDeloymentName := internalAPIName(api.Name, ctx.App.Name)
+	k8sDeloymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return false, err
+	}
 
 	k8sDeployment, err := config.Kubernetes.GetDeployment(k8sDeloymentName)
 	if err != nil {
@@ -152,7 +200,7 @@ func (aw *APIWorkload) IsSucceeded(ctx *context.Context) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if updatedReplicas < api.Compute.MinReplicas {
+	if minReplicas, _ := apiReplicaBounds(api); updatedReplicas < minReplicas {
 		return false, nil
 	}
 
@@ -161,7 +209,10 @@ func (aw *APIWorkload) IsSucceeded(ctx *context.Context) (bool, error) {
 
 func (aw *APIWorkload) IsRunning(ctx *context.Context) (bool, error) {
 	api := ctx.APIs.OneByID(aw.GetSingleResourceID())
-	k8sDeloymentName := internalAPIName(api.Name, ctx.App.Name)
+	k8sDeloymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return false, err
+	}
 
 	k8sDeployment, err := config.Kubernetes.GetDeployment(k8sDeloymentName)
 	if err != nil {
@@ -179,7 +230,7 @@ func (aw *APIWorkload) IsRunning(ctx *context.Context) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if updatedReplicas < api.Compute.MinReplicas {
+	if minReplicas, _ := apiReplicaBounds(api); updatedReplicas < minReplicas {
 		return true, nil
 	}
 
@@ -188,7 +239,10 @@ func (aw *APIWorkload) IsRunning(ctx *context.Context) (bool, error) {
 
 func (aw *APIWorkload) IsStarted(ctx *context.Context) (bool, error) {
 	api := ctx.APIs.OneByID(aw.GetSingleResourceID())
-	k8sDeloymentName := internalAPIName(api.Name, ctx.App.Name)
+	k8sDeloymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return false, err
+	}
 
 	k8sDeployment, err := config.Kubernetes.GetDeployment(k8sDeloymentName)
 	if err != nil {
@@ -206,12 +260,32 @@ func (aw *APIWorkload) IsStarted(ctx *context.Context) (bool, error) {
 }
 
 func (aw *APIWorkload) CanRun(ctx *context.Context) (bool, error) {
-	return areAllDataDependenciesSucceeded(ctx, aw.GetResourceIDs())
+	canRun, err := areAllDataDependenciesSucceeded(ctx, aw.GetResourceIDs())
+	if err != nil || !canRun {
+		return false, err
+	}
+	return areAPIDependenciesSucceeded(ctx, ctx.APIs.OneByID(aw.GetSingleResourceID()))
 }
 
 func (aw *APIWorkload) IsFailed(ctx *context.Context) (bool, error) {
 	api := ctx.APIs.OneByID(aw.GetSingleResourceID())
 
+	isRolledBack, err := isCanaryRolledBack(ctx, api)
+	if err != nil {
+		return false, err
+	}
+	if isRolledBack {
+		return true, nil
+	}
+
+	crashLoop, err := getAPICrashLoop(ctx, api)
+	if err != nil {
+		return false, err
+	}
+	if crashLoop != nil {
+		return true, nil
+	}
+
 	pods, err := config.Kubernetes.ListPodsByLabels(map[string]string{
 		"appName":      ctx.App.Name,
 		"workloadType": workloadTypeAPI,
@@ -235,8 +309,14 @@ func (aw *APIWorkload) IsFailed(ctx *context.Context) (bool, error) {
 }
 
 type downloadContainerConfig struct {
-	DownloadArgs []downloadContainerArg `json:"download_args"`
-	LastLog      string                 `json:"last_log"` // string to log at the conclusion of the downloader (if "" nothing will be logged)
+	DownloadArgs []downloadContainerArg  `json:"download_args"`
+	Files        []downloadContainerFile `json:"files"`    // files to write once everything has been downloaded
+	LastLog      string                  `json:"last_log"` // string to log at the conclusion of the downloader (if "" nothing will be logged)
+}
+
+type downloadContainerFile struct {
+	Path     string `json:"path"`
+	Contents string `json:"contents"`
 }
 
 type downloadContainerArg struct {
@@ -251,10 +331,13 @@ type downloadContainerArg struct {
 
 const downloaderLastLog = "pulling the %s serving image"
 
+var tfServingBatchingParametersPath = path.Join(consts.EmptyDirMountPath, "batching_parameters.txt")
+
 func tfAPISpec(
 	ctx *context.Context,
 	api *context.API,
 	workloadID string,
+	deploymentName string,
 	desiredReplicas int32,
 ) *kapps.Deployment {
 	apiResourceList := kcore.ResourceList{}
@@ -277,6 +360,7 @@ func tfAPISpec(
 		tfServingResourceList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
 		tfServingLimitsList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
 	}
+	servingImage = versionedServingImage(servingImage, api.Predictor.TensorFlowServingVersion, userconfig.DefaultTensorFlowServingVersion)
 
 	tensorflowModel := *ctx.APIs[api.Name].Predictor.Model
 
@@ -301,6 +385,22 @@ func tfAPISpec(
 		},
 	}
 
+	tfServingArgs := []string{
+		"--port=" + tfServingPortStr,
+		"--model_base_path=" + path.Join(consts.EmptyDirMountPath, "model"),
+	}
+
+	if api.Predictor.Batching != nil {
+		downloadConfig.Files = append(downloadConfig.Files, downloadContainerFile{
+			Path:     tfServingBatchingParametersPath,
+			Contents: api.Predictor.Batching.TFServingBatchingParameters(),
+		})
+		tfServingArgs = append(tfServingArgs,
+			"--enable_batching=true",
+			"--batching_parameters_file="+tfServingBatchingParametersPath,
+		)
+	}
+
 	envVars := []kcore.EnvVar{}
 
 	for name, val := range api.Predictor.Env {
@@ -320,6 +420,7 @@ func tfAPISpec(
 			},
 		},
 	)
+	envVars = append(envVars, tracingEnvVars()...)
 
 	if api.Predictor.PythonPath != nil {
 		envVars = append(envVars, kcore.EnvVar{
@@ -331,39 +432,43 @@ func tfAPISpec(
 	downloadArgsBytes, _ := json.Marshal(downloadConfig)
 	downloadArgsStr := base64.URLEncoding.EncodeToString(downloadArgsBytes)
 	return k8s.Deployment(&k8s.DeploymentSpec{
-		Name:     internalAPIName(api.Name, ctx.App.Name),
-		Replicas: desiredReplicas,
+		Name:             deploymentName,
+		Replicas:         desiredReplicas,
+		ProgressDeadline: apiProgressDeadline(api),
+		Annotations:      apiDeploymentAnnotations(api),
 		Labels: map[string]string{
-			"appName":      ctx.App.Name,
-			"workloadType": workloadTypeAPI,
-			"apiName":      api.Name,
-			"resourceID":   ctx.APIs[api.Name].ID,
-			"workloadID":   workloadID,
+			"appName":       ctx.App.Name,
+			"workloadType":  workloadTypeAPI,
+			"apiName":       api.Name,
+			"apiDeployment": deploymentName,
+			"resourceID":    ctx.APIs[api.Name].ID,
+			"workloadID":    workloadID,
 		},
 		Selector: map[string]string{
-			"appName":      ctx.App.Name,
-			"workloadType": workloadTypeAPI,
-			"apiName":      api.Name,
+			"appName":       ctx.App.Name,
+			"workloadType":  workloadTypeAPI,
+			"apiName":       api.Name,
+			"apiDeployment": deploymentName,
 		},
 		PodSpec: k8s.PodSpec{
 			Labels: map[string]string{
-				"appName":      ctx.App.Name,
-				"workloadType": workloadTypeAPI,
-				"apiName":      api.Name,
-				"resourceID":   ctx.APIs[api.Name].ID,
-				"workloadID":   workloadID,
-				"userFacing":   "true",
-				"logGroupName": ctx.LogGroupName(api.Name),
-			},
-			Annotations: map[string]string{
-				"traffic.sidecar.istio.io/excludeOutboundIPRanges": "0.0.0.0/0",
+				"appName":       ctx.App.Name,
+				"workloadType":  workloadTypeAPI,
+				"apiName":       api.Name,
+				"apiDeployment": deploymentName,
+				"resourceID":    ctx.APIs[api.Name].ID,
+				"workloadID":    workloadID,
+				"userFacing":    "true",
+				"logGroupName":  ctx.LogGroupName(api.Name),
+				"logFormat":     api.LogFormat(),
 			},
+			Annotations: apiPodAnnotations(api),
 			K8sPodSpec: kcore.PodSpec{
 				RestartPolicy: "Always",
-				InitContainers: []kcore.Container{
+				InitContainers: append([]kcore.Container{
 					{
 						Name:            downloaderInitContainerName,
-						Image:           config.Cluster.ImageDownloader,
+						Image:           archImage(config.Cluster.ImageDownloader, api.Compute.Arch),
 						ImagePullPolicy: "Always",
 						Args: []string{
 							"--download=" + downloadArgsStr,
@@ -371,8 +476,8 @@ func tfAPISpec(
 						EnvFrom:      baseEnvVars(),
 						VolumeMounts: defaultVolumeMounts(),
 					},
-				},
-				Containers: []kcore.Container{
+				}, userInitContainers(api)...),
+				Containers: append([]kcore.Container{
 					{
 						Name:            apiContainerName,
 						Image:           config.Cluster.ImageTFAPI,
@@ -386,10 +491,13 @@ func tfAPISpec(
 							"--model-dir=" + path.Join(consts.EmptyDirMountPath, "model"),
 							"--cache-dir=" + consts.ContextCacheDir,
 							"--project-dir=" + path.Join(consts.EmptyDirMountPath, "project"),
+							"--processes-per-replica=" + s.Int32(api.Predictor.ProcessesPerReplica),
+							"--threads-per-process=" + s.Int32(api.Predictor.ThreadsPerProcess),
 						},
-						Env:          envVars,
-						EnvFrom:      baseEnvVars(),
-						VolumeMounts: defaultVolumeMounts(),
+						Env:             append(append(envVars, predictorSecretsEnvVars(api, ctx.App.Name)...), predictorConfigEnvVars(api)...),
+						EnvFrom:         baseEnvVars(),
+						VolumeMounts:    apiVolumeMounts(api),
+						SecurityContext: apiContainerSecurityContext(),
 						ReadinessProbe: &kcore.Probe{
 							InitialDelaySeconds: 5,
 							TimeoutSeconds:      5,
@@ -412,16 +520,13 @@ func tfAPISpec(
 						},
 					},
 					{
-						Name:            tfServingContainerName,
+						Name:            servingContainerName,
 						Image:           servingImage,
 						ImagePullPolicy: kcore.PullAlways,
-						Args: []string{
-							"--port=" + tfServingPortStr,
-							"--model_base_path=" + path.Join(consts.EmptyDirMountPath, "model"),
-						},
-						Env:          envVars,
-						EnvFrom:      baseEnvVars(),
-						VolumeMounts: defaultVolumeMounts(),
+						Args:            tfServingArgs,
+						Env:             envVars,
+						EnvFrom:         baseEnvVars(),
+						VolumeMounts:    apiVolumeMounts(api),
 						ReadinessProbe: &kcore.Probe{
 							InitialDelaySeconds: 5,
 							TimeoutSeconds:      5,
@@ -446,23 +551,256 @@ func tfAPISpec(
 							},
 						},
 					},
+				}, apiSidecarContainers(api)...),
+				NodeSelector:       apiNodeSelector(api),
+				Tolerations:        tolerations,
+				Volumes:            apiVolumes(api, ctx.App.Name),
+				ServiceAccountName: "default",
+				PriorityClassName:  apiPriorityClassName(api),
+			},
+		},
+		Namespace: consts.K8sNamespace,
+	})
+}
+
+// tritonAPISpec is like tfAPISpec, but the sidecar is Triton Inference Server, which serves every model in the model repository over HTTP and gRPC
+func tritonAPISpec(
+	ctx *context.Context,
+	api *context.API,
+	workloadID string,
+	deploymentName string,
+	desiredReplicas int32,
+) *kapps.Deployment {
+	apiResourceList := kcore.ResourceList{}
+	tritonResourceList := kcore.ResourceList{}
+	tritonLimitsList := kcore.ResourceList{}
+
+	q1, q2 := api.Compute.CPU.SplitInTwo()
+	apiResourceList[kcore.ResourceCPU] = *q1
+	tritonResourceList[kcore.ResourceCPU] = *q2
+
+	if api.Compute.Mem != nil {
+		q1, q2 := api.Compute.Mem.SplitInTwo()
+		apiResourceList[kcore.ResourceMemory] = *q1
+		tritonResourceList[kcore.ResourceMemory] = *q2
+	}
+
+	// triton APIs without gpus are rejected when the cluster doesn't have a cpu-only build of Triton (see validateTritonCompute)
+	servingImage := config.Cluster.ImageTritonServe
+	if api.Compute.GPU > 0 {
+		tritonResourceList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
+		tritonLimitsList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
+	} else if config.Cluster.ImageTritonServeCPU != nil {
+		servingImage = *config.Cluster.ImageTritonServeCPU
+	}
+
+	downloadConfig := downloadContainerConfig{
+		LastLog: fmt.Sprintf(downloaderLastLog, "triton"),
+		DownloadArgs: []downloadContainerArg{
+			{
+				From:             config.AWS.S3Path(ctx.ProjectKey),
+				To:               path.Join(consts.EmptyDirMountPath, "project"),
+				Unzip:            true,
+				ItemName:         "the project code",
+				HideFromLog:      true,
+				HideUnzippingLog: true,
+			},
+			{
+				From:     *ctx.APIs[api.Name].Predictor.Model,
+				To:       path.Join(consts.EmptyDirMountPath, "model"),
+				ItemName: "the model repository",
+			},
+		},
+	}
+
+	envVars := []kcore.EnvVar{}
+
+	for name, val := range api.Predictor.Env {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  name,
+			Value: val,
+		})
+	}
+
+	envVars = append(envVars,
+		kcore.EnvVar{
+			Name: "HOST_IP",
+			ValueFrom: &kcore.EnvVarSource{
+				FieldRef: &kcore.ObjectFieldSelector{
+					FieldPath: "status.hostIP",
 				},
-				NodeSelector: map[string]string{
-					"workload": "true",
-				},
+			},
+		},
+	)
+	envVars = append(envVars, tracingEnvVars()...)
+
+	if api.Predictor.PythonPath != nil {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "PYTHON_PATH",
+			Value: path.Join(consts.EmptyDirMountPath, "project", *api.Predictor.PythonPath),
+		})
+	}
+
+	downloadArgsBytes, _ := json.Marshal(downloadConfig)
+	downloadArgsStr := base64.URLEncoding.EncodeToString(downloadArgsBytes)
+	return k8s.Deployment(&k8s.DeploymentSpec{
+		Name:             deploymentName,
+		Replicas:         desiredReplicas,
+		ProgressDeadline: apiProgressDeadline(api),
+		Annotations:      apiDeploymentAnnotations(api),
+		Labels: map[string]string{
+			"appName":       ctx.App.Name,
+			"workloadType":  workloadTypeAPI,
+			"apiName":       api.Name,
+			"apiDeployment": deploymentName,
+			"resourceID":    ctx.APIs[api.Name].ID,
+			"workloadID":    workloadID,
+		},
+		Selector: map[string]string{
+			"appName":       ctx.App.Name,
+			"workloadType":  workloadTypeAPI,
+			"apiName":       api.Name,
+			"apiDeployment": deploymentName,
+		},
+		PodSpec: k8s.PodSpec{
+			Labels: map[string]string{
+				"appName":       ctx.App.Name,
+				"workloadType":  workloadTypeAPI,
+				"apiName":       api.Name,
+				"apiDeployment": deploymentName,
+				"resourceID":    ctx.APIs[api.Name].ID,
+				"workloadID":    workloadID,
+				"userFacing":    "true",
+				"logGroupName":  ctx.LogGroupName(api.Name),
+				"logFormat":     api.LogFormat(),
+			},
+			Annotations: apiPodAnnotations(api),
+			K8sPodSpec: kcore.PodSpec{
+				RestartPolicy: "Always",
+				InitContainers: append([]kcore.Container{
+					{
+						Name:            downloaderInitContainerName,
+						Image:           archImage(config.Cluster.ImageDownloader, api.Compute.Arch),
+						ImagePullPolicy: "Always",
+						Args: []string{
+							"--download=" + downloadArgsStr,
+						},
+						EnvFrom:      baseEnvVars(),
+						VolumeMounts: defaultVolumeMounts(),
+					},
+				}, userInitContainers(api)...),
+				Containers: append([]kcore.Container{
+					{
+						Name:            apiContainerName,
+						Image:           pythonVersionedImage(config.Cluster.ImageTritonAPI, api.Predictor.PythonVersion),
+						ImagePullPolicy: kcore.PullAlways,
+						Args: []string{
+							"--workload-id=" + workloadID,
+							"--port=" + defaultPortStr,
+							"--triton-http-port=" + tritonHTTPPortStr,
+							"--context=" + config.AWS.S3Path(ctx.Key),
+							"--api=" + ctx.APIs[api.Name].ID,
+							"--model-dir=" + path.Join(consts.EmptyDirMountPath, "model"),
+							"--cache-dir=" + consts.ContextCacheDir,
+							"--project-dir=" + path.Join(consts.EmptyDirMountPath, "project"),
+							"--processes-per-replica=" + s.Int32(api.Predictor.ProcessesPerReplica),
+							"--threads-per-process=" + s.Int32(api.Predictor.ThreadsPerProcess),
+						},
+						Env:             append(append(envVars, predictorSecretsEnvVars(api, ctx.App.Name)...), predictorConfigEnvVars(api)...),
+						EnvFrom:         baseEnvVars(),
+						VolumeMounts:    apiVolumeMounts(api),
+						SecurityContext: apiContainerSecurityContext(),
+						ReadinessProbe: &kcore.Probe{
+							InitialDelaySeconds: 5,
+							TimeoutSeconds:      5,
+							PeriodSeconds:       5,
+							SuccessThreshold:    1,
+							FailureThreshold:    2,
+							Handler: kcore.Handler{
+								Exec: &kcore.ExecAction{
+									Command: []string{"/bin/bash", "-c", "/bin/ps aux | grep \"api.py\" && test -f /health_check.txt"},
+								},
+							},
+						},
+						Resources: kcore.ResourceRequirements{
+							Requests: apiResourceList,
+						},
+						Ports: []kcore.ContainerPort{
+							{
+								ContainerPort: defaultPortInt32,
+							},
+						},
+					},
+					{
+						Name:            servingContainerName,
+						Image:           servingImage,
+						ImagePullPolicy: kcore.PullAlways,
+						Args: []string{
+							"--model-repository=" + path.Join(consts.EmptyDirMountPath, "model"),
+							"--http-port=" + tritonHTTPPortStr,
+							"--grpc-port=" + tritonGRPCPortStr,
+							"--metrics-port=" + tritonMetricsPortStr,
+						},
+						Env:          envVars,
+						EnvFrom:      baseEnvVars(),
+						VolumeMounts: apiVolumeMounts(api),
+						ReadinessProbe: &kcore.Probe{
+							InitialDelaySeconds: 5,
+							TimeoutSeconds:      5,
+							PeriodSeconds:       5,
+							SuccessThreshold:    1,
+							FailureThreshold:    2,
+							Handler: kcore.Handler{
+								// Triton is ready once all of the models in the repository have loaded
+								HTTPGet: &kcore.HTTPGetAction{
+									Path: "/v2/health/ready",
+									Port: intstr.IntOrString{
+										IntVal: tritonHTTPPortInt32,
+									},
+								},
+							},
+						},
+						Resources: kcore.ResourceRequirements{
+							Requests: tritonResourceList,
+							Limits:   tritonLimitsList,
+						},
+						Ports: []kcore.ContainerPort{
+							{
+								Name:          "http-triton",
+								ContainerPort: tritonHTTPPortInt32,
+							},
+							{
+								Name:          "grpc-triton",
+								ContainerPort: tritonGRPCPortInt32,
+							},
+							{
+								Name:          "metrics-triton",
+								ContainerPort: tritonMetricsPortInt32,
+							},
+						},
+					},
+				}, apiSidecarContainers(api)...),
+				NodeSelector:       apiNodeSelector(api),
 				Tolerations:        tolerations,
-				Volumes:            defaultVolumes(),
+				Volumes:            apiVolumes(api, ctx.App.Name),
 				ServiceAccountName: "default",
+				PriorityClassName:  apiPriorityClassName(api),
 			},
 		},
 		Namespace: consts.K8sNamespace,
 	})
 }
 
+// condaEnvCacheKey is the S3 key of the archived conda environment, which is shared by all APIs built from the same conda files and image
+func condaEnvCacheKey(condaEnvID string, image string) string {
+	return path.Join(consts.CondaEnvsDir, hash.String(image+condaEnvID)+".tar.gz")
+}
+
 func pythonAPISpec(
 	ctx *context.Context,
 	api *context.API,
 	workloadID string,
+	deploymentName string,
 	desiredReplicas int32,
 ) *kapps.Deployment {
 	servingImage := config.Cluster.ImagePythonServe
@@ -479,6 +817,16 @@ func pythonAPISpec(
 		resourceList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
 		resourceLimitsList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
 	}
+	servingImage = pythonVersionedImage(servingImage, api.Predictor.PythonVersion)
+	servingImage = archImage(servingImage, api.Compute.Arch)
+
+	// python_version can't be set for projects with conda files (their environment installs python), and conda environments aren't supported on arm64
+	if ctx.CondaEnvID != "" {
+		servingImage = config.Cluster.ImagePythonServeConda
+		if api.Compute.GPU > 0 {
+			servingImage = config.Cluster.ImagePythonServeCondaGPU
+		}
+	}
 
 	downloadConfig := downloadContainerConfig{
 		LastLog: fmt.Sprintf(downloaderLastLog, "python"),
@@ -516,6 +864,7 @@ func pythonAPISpec(
 			},
 		},
 	)
+	envVars = append(envVars, tracingEnvVars()...)
 
 	if api.Predictor.PythonPath != nil {
 		envVars = append(envVars, kcore.EnvVar{
@@ -524,40 +873,51 @@ func pythonAPISpec(
 		})
 	}
 
+	if ctx.CondaEnvID != "" {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "CORTEX_CONDA_ENV_CACHE",
+			Value: config.AWS.S3Path(condaEnvCacheKey(ctx.CondaEnvID, servingImage)),
+		})
+	}
+
 	return k8s.Deployment(&k8s.DeploymentSpec{
-		Name:     internalAPIName(api.Name, ctx.App.Name),
-		Replicas: desiredReplicas,
+		Name:             deploymentName,
+		Replicas:         desiredReplicas,
+		ProgressDeadline: apiProgressDeadline(api),
+		Annotations:      apiDeploymentAnnotations(api),
 		Labels: map[string]string{
-			"appName":      ctx.App.Name,
-			"workloadType": workloadTypeAPI,
-			"apiName":      api.Name,
-			"resourceID":   ctx.APIs[api.Name].ID,
-			"workloadID":   workloadID,
+			"appName":       ctx.App.Name,
+			"workloadType":  workloadTypeAPI,
+			"apiName":       api.Name,
+			"apiDeployment": deploymentName,
+			"resourceID":    ctx.APIs[api.Name].ID,
+			"workloadID":    workloadID,
 		},
 		Selector: map[string]string{
-			"appName":      ctx.App.Name,
-			"workloadType": workloadTypeAPI,
-			"apiName":      api.Name,
+			"appName":       ctx.App.Name,
+			"workloadType":  workloadTypeAPI,
+			"apiName":       api.Name,
+			"apiDeployment": deploymentName,
 		},
 		PodSpec: k8s.PodSpec{
 			Labels: map[string]string{
-				"appName":      ctx.App.Name,
-				"workloadType": workloadTypeAPI,
-				"apiName":      api.Name,
-				"resourceID":   ctx.APIs[api.Name].ID,
-				"workloadID":   workloadID,
-				"userFacing":   "true",
-				"logGroupName": ctx.LogGroupName(api.Name),
-			},
-			Annotations: map[string]string{
-				"traffic.sidecar.istio.io/excludeOutboundIPRanges": "0.0.0.0/0",
+				"appName":       ctx.App.Name,
+				"workloadType":  workloadTypeAPI,
+				"apiName":       api.Name,
+				"apiDeployment": deploymentName,
+				"resourceID":    ctx.APIs[api.Name].ID,
+				"workloadID":    workloadID,
+				"userFacing":    "true",
+				"logGroupName":  ctx.LogGroupName(api.Name),
+				"logFormat":     api.LogFormat(),
 			},
+			Annotations: apiPodAnnotations(api),
 			K8sPodSpec: kcore.PodSpec{
 				RestartPolicy: "Always",
-				InitContainers: []kcore.Container{
+				InitContainers: append([]kcore.Container{
 					{
 						Name:            downloaderInitContainerName,
-						Image:           config.Cluster.ImageDownloader,
+						Image:           archImage(config.Cluster.ImageDownloader, api.Compute.Arch),
 						ImagePullPolicy: "Always",
 						Args: []string{
 							"--download=" + downloadArgsStr,
@@ -565,8 +925,8 @@ func pythonAPISpec(
 						EnvFrom:      baseEnvVars(),
 						VolumeMounts: defaultVolumeMounts(),
 					},
-				},
-				Containers: []kcore.Container{
+				}, userInitContainers(api)...),
+				Containers: append([]kcore.Container{
 					{
 						Name:            apiContainerName,
 						Image:           servingImage,
@@ -578,10 +938,13 @@ func pythonAPISpec(
 							"--api=" + ctx.APIs[api.Name].ID,
 							"--cache-dir=" + consts.ContextCacheDir,
 							"--project-dir=" + path.Join(consts.EmptyDirMountPath, "project"),
+							"--processes-per-replica=" + s.Int32(api.Predictor.ProcessesPerReplica),
+							"--threads-per-process=" + s.Int32(api.Predictor.ThreadsPerProcess),
 						},
-						Env:          envVars,
-						EnvFrom:      baseEnvVars(),
-						VolumeMounts: defaultVolumeMounts(),
+						Env:             append(append(envVars, predictorSecretsEnvVars(api, ctx.App.Name)...), predictorConfigEnvVars(api)...),
+						EnvFrom:         baseEnvVars(),
+						VolumeMounts:    apiVolumeMounts(api),
+						SecurityContext: apiContainerSecurityContext(),
 						ReadinessProbe: &kcore.Probe{
 							InitialDelaySeconds: 5,
 							TimeoutSeconds:      5,
@@ -604,13 +967,12 @@ func pythonAPISpec(
 							},
 						},
 					},
-				},
-				NodeSelector: map[string]string{
-					"workload": "true",
-				},
+				}, apiSidecarContainers(api)...),
+				NodeSelector:       apiNodeSelector(api),
 				Tolerations:        tolerations,
-				Volumes:            defaultVolumes(),
+				Volumes:            apiVolumes(api, ctx.App.Name),
 				ServiceAccountName: "default",
+				PriorityClassName:  apiPriorityClassName(api),
 			},
 		},
 		Namespace: consts.K8sNamespace,
@@ -621,9 +983,43 @@ func onnxAPISpec(
 	ctx *context.Context,
 	api *context.API,
 	workloadID string,
+	deploymentName string,
 	desiredReplicas int32,
 ) *kapps.Deployment {
 	servingImage := config.Cluster.ImageONNXServe
+	if api.Compute.GPU > 0 {
+		servingImage = config.Cluster.ImageONNXServeGPU
+	}
+	servingImage = versionedServingImage(servingImage, api.Predictor.ONNXRuntimeVersion, userconfig.DefaultONNXRuntimeVersion)
+
+	return modelFileAPISpec(ctx, api, servingImage, workloadID, deploymentName, desiredReplicas)
+}
+
+// frameworkServingImage returns the image which serves the predictor types that load a model file with their framework (their APIs can't use gpus)
+func frameworkServingImage(predictorType userconfig.PredictorType) string {
+	switch predictorType {
+	case userconfig.SKLearnPredictorType:
+		return config.Cluster.ImageSKLearnServe
+	case userconfig.XGBoostPredictorType:
+		return config.Cluster.ImageXGBoostServe
+	case userconfig.LightGBMPredictorType:
+		return config.Cluster.ImageLightGBMServe
+	}
+	return ""
+}
+
+// modelFileAPISpec is the deployment for predictor types which are served by a single container that loads the model file (onnx, sklearn, xgboost, and lightgbm)
+func modelFileAPISpec(
+	ctx *context.Context,
+	api *context.API,
+	servingImage string,
+	workloadID string,
+	deploymentName string,
+	desiredReplicas int32,
+) *kapps.Deployment {
+	servingImage = pythonVersionedImage(servingImage, api.Predictor.PythonVersion)
+	servingImage = archImage(servingImage, api.Compute.Arch)
+
 	resourceList := kcore.ResourceList{}
 	resourceLimitsList := kcore.ResourceList{}
 	resourceList[kcore.ResourceCPU] = api.Compute.CPU.Quantity
@@ -633,13 +1029,12 @@ func onnxAPISpec(
 	}
 
 	if api.Compute.GPU > 0 {
-		servingImage = config.Cluster.ImageONNXServeGPU
 		resourceList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
 		resourceLimitsList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
 	}
 
 	downloadConfig := downloadContainerConfig{
-		LastLog: fmt.Sprintf(downloaderLastLog, "onnx"),
+		LastLog: fmt.Sprintf(downloaderLastLog, api.Predictor.Type.String()),
 		DownloadArgs: []downloadContainerArg{
 			{
 				From:             config.AWS.S3Path(ctx.ProjectKey),
@@ -676,6 +1071,7 @@ func onnxAPISpec(
 			},
 		},
 	)
+	envVars = append(envVars, tracingEnvVars()...)
 
 	if api.Predictor.PythonPath != nil {
 		envVars = append(envVars, kcore.EnvVar{
@@ -687,38 +1083,42 @@ func onnxAPISpec(
 	downloadArgsBytes, _ := json.Marshal(downloadConfig)
 	downloadArgsStr := base64.URLEncoding.EncodeToString(downloadArgsBytes)
 	return k8s.Deployment(&k8s.DeploymentSpec{
-		Name:     internalAPIName(api.Name, ctx.App.Name),
-		Replicas: desiredReplicas,
+		Name:             deploymentName,
+		Replicas:         desiredReplicas,
+		ProgressDeadline: apiProgressDeadline(api),
+		Annotations:      apiDeploymentAnnotations(api),
 		Labels: map[string]string{
-			"appName":      ctx.App.Name,
-			"workloadType": workloadTypeAPI,
-			"apiName":      api.Name,
-			"resourceID":   ctx.APIs[api.Name].ID,
-			"workloadID":   workloadID,
+			"appName":       ctx.App.Name,
+			"workloadType":  workloadTypeAPI,
+			"apiName":       api.Name,
+			"apiDeployment": deploymentName,
+			"resourceID":    ctx.APIs[api.Name].ID,
+			"workloadID":    workloadID,
 		},
 		Selector: map[string]string{
-			"appName":      ctx.App.Name,
-			"workloadType": workloadTypeAPI,
-			"apiName":      api.Name,
+			"appName":       ctx.App.Name,
+			"workloadType":  workloadTypeAPI,
+			"apiName":       api.Name,
+			"apiDeployment": deploymentName,
 		},
 		PodSpec: k8s.PodSpec{
 			Labels: map[string]string{
-				"appName":      ctx.App.Name,
-				"workloadType": workloadTypeAPI,
-				"apiName":      api.Name,
-				"resourceID":   ctx.APIs[api.Name].ID,
-				"workloadID":   workloadID,
-				"userFacing":   "true",
-				"logGroupName": ctx.LogGroupName(api.Name),
-			},
-			Annotations: map[string]string{
-				"traffic.sidecar.istio.io/excludeOutboundIPRanges": "0.0.0.0/0",
+				"appName":       ctx.App.Name,
+				"workloadType":  workloadTypeAPI,
+				"apiName":       api.Name,
+				"apiDeployment": deploymentName,
+				"resourceID":    ctx.APIs[api.Name].ID,
+				"workloadID":    workloadID,
+				"userFacing":    "true",
+				"logGroupName":  ctx.LogGroupName(api.Name),
+				"logFormat":     api.LogFormat(),
 			},
+			Annotations: apiPodAnnotations(api),
 			K8sPodSpec: kcore.PodSpec{
-				InitContainers: []kcore.Container{
+				InitContainers: append([]kcore.Container{
 					{
 						Name:            downloaderInitContainerName,
-						Image:           config.Cluster.ImageDownloader,
+						Image:           archImage(config.Cluster.ImageDownloader, api.Compute.Arch),
 						ImagePullPolicy: "Always",
 						Args: []string{
 							"--download=" + downloadArgsStr,
@@ -726,8 +1126,8 @@ func onnxAPISpec(
 						EnvFrom:      baseEnvVars(),
 						VolumeMounts: defaultVolumeMounts(),
 					},
-				},
-				Containers: []kcore.Container{
+				}, userInitContainers(api)...),
+				Containers: append([]kcore.Container{
 					{
 						Name:            apiContainerName,
 						Image:           servingImage,
@@ -740,10 +1140,13 @@ func onnxAPISpec(
 							"--model-dir=" + path.Join(consts.EmptyDirMountPath, "model"),
 							"--cache-dir=" + consts.ContextCacheDir,
 							"--project-dir=" + path.Join(consts.EmptyDirMountPath, "project"),
+							"--processes-per-replica=" + s.Int32(api.Predictor.ProcessesPerReplica),
+							"--threads-per-process=" + s.Int32(api.Predictor.ThreadsPerProcess),
 						},
-						Env:          envVars,
-						EnvFrom:      baseEnvVars(),
-						VolumeMounts: defaultVolumeMounts(),
+						Env:             append(append(envVars, predictorSecretsEnvVars(api, ctx.App.Name)...), predictorConfigEnvVars(api)...),
+						EnvFrom:         baseEnvVars(),
+						VolumeMounts:    apiVolumeMounts(api),
+						SecurityContext: apiContainerSecurityContext(),
 						ReadinessProbe: &kcore.Probe{
 							InitialDelaySeconds: 5,
 							TimeoutSeconds:      5,
@@ -766,28 +1169,112 @@ func onnxAPISpec(
 							},
 						},
 					},
-				},
-				NodeSelector: map[string]string{
-					"workload": "true",
-				},
+				}, apiSidecarContainers(api)...),
+				NodeSelector:       apiNodeSelector(api),
 				Tolerations:        tolerations,
-				Volumes:            defaultVolumes(),
+				Volumes:            apiVolumes(api, ctx.App.Name),
 				ServiceAccountName: "default",
+				PriorityClassName:  apiPriorityClassName(api),
 			},
 		},
 		Namespace: consts.K8sNamespace,
 	})
 }
 
-func virtualServiceSpec(ctx *context.Context, api *context.API) *kunstructured.Unstructured {
-	return k8s.VirtualService(&k8s.VirtualServiceSpec{
-		Name:        internalAPIName(api.Name, ctx.App.Name),
+// The virtual service routes the API's endpoint to the service of the given deployment
+func virtualServiceSpec(ctx *context.Context, api *context.API, deploymentName string) *kunstructured.Unstructured {
+	return k8s.VirtualService(apiVirtualServiceSpec(ctx, api, internalAPIName(api.Name, ctx.App.Name), *api.Endpoint, deploymentName))
+}
+
+func apiVirtualServiceSpec(ctx *context.Context, api *context.API, name string, path string, deploymentName string) *k8s.VirtualServiceSpec {
+	var corsPolicy *k8s.CORSPolicy
+	if api.Networking != nil && api.Networking.CORS != nil {
+		corsPolicy = &k8s.CORSPolicy{
+			AllowOrigins: api.Networking.CORS.AllowedOrigins,
+			AllowMethods: api.Networking.CORS.AllowedMethods,
+			AllowHeaders: api.Networking.CORS.AllowedHeaders,
+			MaxAge:       api.Networking.CORS.MaxAge,
+		}
+	}
+
+	var timeout time.Duration
+	var retryPolicy *k8s.RetryPolicy
+	if api.Networking != nil {
+		timeout = api.Networking.Timeout
+		if api.Networking.Retries != nil {
+			retryPolicy = &k8s.RetryPolicy{
+				Attempts:      api.Networking.Retries.Attempts,
+				PerTryTimeout: api.Networking.Retries.PerTryTimeout,
+				StatusCodes:   api.Networking.Retries.StatusCodes,
+			}
+		}
+	}
+
+	return &k8s.VirtualServiceSpec{
+		Name:        name,
 		Namespace:   consts.K8sNamespace,
-		Gateways:    []string{"apis-gateway"},
-		ServiceName: internalAPIName(api.Name, ctx.App.Name),
+		Gateways:    []string{apiGateway(api)},
+		ServiceName: deploymentName,
 		ServicePort: defaultPortInt32,
-		Path:        *api.Endpoint,
+		Path:        path,
 		Rewrite:     pointer.String("predict"),
+		Subpaths:    api.Subpaths(),
+		CORSPolicy:  corsPolicy,
+		WebSocket:   isWebSocketAPI(api),
+		Timeout:     timeout,
+		Retries:     retryPolicy,
+		Labels: map[string]string{
+			"appName":       ctx.App.Name,
+			"workloadType":  workloadTypeAPI,
+			"apiName":       api.Name,
+			"apiDeployment": deploymentName,
+			"ipAllowlist":   s.Bool(hasIPAllowlist(api)),
+		},
+	}
+}
+
+func isWebSocketAPI(api *context.API) bool {
+	return api.Networking != nil && api.Networking.Protocol == userconfig.WebSocketProtocol
+}
+
+// hasCircuitBreaker returns whether the API's requests are limited by the istio proxies which route them (with its destination rule)
+func hasCircuitBreaker(api *context.API) bool {
+	return api.Networking != nil && api.Networking.CircuitBreaker != nil
+}
+
+func destinationRuleSpec(ctx *context.Context, api *context.API, deploymentName string) *kunstructured.Unstructured {
+	var idleTimeout time.Duration
+	if isWebSocketAPI(api) {
+		idleTimeout = api.Networking.IdleTimeout
+	}
+
+	var connectionLimits *k8s.ConnectionLimits
+	var outlierDetection *k8s.OutlierDetection
+	if hasCircuitBreaker(api) {
+		circuitBreaker := api.Networking.CircuitBreaker
+		connectionLimits = &k8s.ConnectionLimits{
+			MaxConnections:     circuitBreaker.MaxConnections,
+			MaxPendingRequests: circuitBreaker.MaxPendingRequests,
+			MaxRequests:        circuitBreaker.MaxRequests,
+		}
+		if circuitBreaker.Consecutive5xxErrors != nil {
+			outlierDetection = &k8s.OutlierDetection{
+				Consecutive5xxErrors: *circuitBreaker.Consecutive5xxErrors,
+				Interval:             circuitBreaker.Interval,
+				BaseEjectionTime:     circuitBreaker.BaseEjectionTime,
+				MaxEjectionPercent:   circuitBreaker.MaxEjectionPercent,
+			}
+		}
+	}
+
+	return k8s.DestinationRule(&k8s.DestinationRuleSpec{
+		Name:             deploymentName,
+		Namespace:        consts.K8sNamespace,
+		ServiceName:      deploymentName,
+		IdleTimeout:      idleTimeout,
+		MTLS:             config.Cluster.MTLS,
+		ConnectionLimits: connectionLimits,
+		OutlierDetection: outlierDetection,
 		Labels: map[string]string{
 			"appName":      ctx.App.Name,
 			"workloadType": workloadTypeAPI,
@@ -796,25 +1283,139 @@ func virtualServiceSpec(ctx *context.Context, api *context.API) *kunstructured.U
 	})
 }
 
-func serviceSpec(ctx *context.Context, api *context.API) *kcore.Service {
+func serviceSpec(ctx *context.Context, api *context.API, deploymentName string) *kcore.Service {
+	var extraPorts []kcore.ServicePort
+	if api.Predictor.Type == userconfig.TritonPredictorType {
+		// Triton's own endpoints can be reached from within the cluster (the ports' names tell istio their protocols)
+		extraPorts = []kcore.ServicePort{
+			{
+				Protocol:   kcore.ProtocolTCP,
+				Name:       "http-triton",
+				Port:       tritonHTTPPortInt32,
+				TargetPort: intstr.IntOrString{IntVal: tritonHTTPPortInt32},
+			},
+			{
+				Protocol:   kcore.ProtocolTCP,
+				Name:       "grpc-triton",
+				Port:       tritonGRPCPortInt32,
+				TargetPort: intstr.IntOrString{IntVal: tritonGRPCPortInt32},
+			},
+		}
+	}
+
 	return k8s.Service(&k8s.ServiceSpec{
-		Name:       internalAPIName(api.Name, ctx.App.Name),
+		Name:       deploymentName,
 		Port:       defaultPortInt32,
 		TargetPort: defaultPortInt32,
+		ExtraPorts: extraPorts,
 		Labels: map[string]string{
-			"appName":      ctx.App.Name,
-			"workloadType": workloadTypeAPI,
-			"apiName":      api.Name,
+			"appName":       ctx.App.Name,
+			"workloadType":  workloadTypeAPI,
+			"apiName":       api.Name,
+			"apiDeployment": deploymentName,
 		},
 		Selector: map[string]string{
-			"appName":      ctx.App.Name,
-			"workloadType": workloadTypeAPI,
-			"apiName":      api.Name,
+			"appName":       ctx.App.Name,
+			"workloadType":  workloadTypeAPI,
+			"apiName":       api.Name,
+			"apiDeployment": deploymentName,
 		},
 		Namespace: consts.K8sNamespace,
 	})
 }
 
+// versionedServingImage returns the image for the pinned version of the serving runtime, which is tagged with the image's tag and the version
+// (e.g. cortexlabs/tf-serve:master-1.15.0); the image itself is used if the version isn't pinned or is the default version
+func versionedServingImage(image string, version *string, defaultVersion string) string {
+	if version == nil || *version == defaultVersion {
+		return image
+	}
+	if strings.LastIndex(image, ":") > strings.LastIndex(image, "/") {
+		return image + "-" + *version
+	}
+	return image + ":" + *version
+}
+
+// pythonVersionedImage returns the variant of the image which is built with the version of Python (e.g. cortexlabs/python-serve:master-py3.8)
+func pythonVersionedImage(image string, pythonVersion *string) string {
+	if pythonVersion == nil || *pythonVersion == userconfig.DefaultPythonVersion {
+		return image
+	}
+	return versionedServingImage(image, pointer.String("py"+*pythonVersion), userconfig.DefaultPythonVersion)
+}
+
+// archImage returns the variant of the image which is built for the architecture (e.g. cortexlabs/python-serve:master-arm64)
+func archImage(image string, arch userconfig.Arch) string {
+	if arch != userconfig.ARM64Arch {
+		return image
+	}
+	return versionedServingImage(image, pointer.String(arch.String()), userconfig.AMD64Arch.String())
+}
+
+// apiNodeSelector schedules the API's pods on the worker nodes of the API's architecture
+func apiNodeSelector(api *context.API) map[string]string {
+	return map[string]string{
+		"workload":           "true",
+		"kubernetes.io/arch": api.Compute.Arch.String(),
+	}
+}
+
+// User-defined init containers run after the downloader, so the project code is available
+func userInitContainers(api *context.API) []kcore.Container {
+	containers := make([]kcore.Container, len(api.Init))
+	for i, container := range api.Init {
+		containers[i] = userContainer(api, container)
+	}
+	return containers
+}
+
+func userSidecarContainers(api *context.API) []kcore.Container {
+	containers := make([]kcore.Container, len(api.Sidecars))
+	for i, container := range api.Sidecars {
+		containers[i] = userContainer(api, container)
+	}
+	return containers
+}
+
+func userContainer(api *context.API, container *userconfig.Container) kcore.Container {
+	// sorted so that the container's spec doesn't change between deploys
+	envVars := []kcore.EnvVar{}
+	for _, name := range maps.StrMapSortedKeys(container.Env) {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  name,
+			Value: container.Env[name],
+		})
+	}
+
+	return kcore.Container{
+		Name:            container.Name,
+		Image:           container.Image,
+		ImagePullPolicy: kcore.PullIfNotPresent,
+		Command:         container.Command,
+		Args:            container.Args,
+		Env:             envVars,
+		EnvFrom:         baseEnvVars(),
+		VolumeMounts:    apiVolumeMounts(api),
+		Resources: kcore.ResourceRequirements{
+			Requests: userContainerResourceList(container.Compute),
+		},
+	}
+}
+
+func userContainerResourceList(compute *userconfig.ContainerCompute) kcore.ResourceList {
+	resourceList := kcore.ResourceList{}
+	if compute == nil {
+		return resourceList
+	}
+	if compute.CPU != nil {
+		resourceList[kcore.ResourceCPU] = compute.CPU.Quantity
+	}
+	if compute.Mem != nil {
+		resourceList[kcore.ResourceMemory] = compute.Mem.Quantity
+	}
+	return resourceList
+}
+
 func doesAPIComputeNeedsUpdating(api *context.API, k8sDeployment *kapps.Deployment) bool {
 	requestedReplicas := getRequestedReplicasFromDeployment(api, k8sDeployment, nil)
 	if k8sDeployment.Spec.Replicas == nil || *k8sDeployment.Spec.Replicas != requestedReplicas {
@@ -835,45 +1436,17 @@ func doesAPIComputeNeedsUpdating(api *context.API, k8sDeployment *kapps.Deployme
 	return false
 }
 
+// deleteOldAPIs deletes the resources of the app's APIs which are no longer in its context (resources which can't be deleted are retried by the orphan GC cron)
 func deleteOldAPIs(ctx *context.Context) {
-	virtualServices, _ := config.Kubernetes.ListVirtualServicesByLabels(consts.K8sNamespace, map[string]string{
-		"appName":      ctx.App.Name,
-		"workloadType": workloadTypeAPI,
-	})
-	for _, virtualService := range virtualServices {
-		if _, ok := ctx.APIs[virtualService.GetLabels()["apiName"]]; !ok {
-			config.Kubernetes.DeleteVirtualService(virtualService.GetName(), consts.K8sNamespace)
-		}
-	}
-
-	services, _ := config.Kubernetes.ListServicesByLabels(map[string]string{
-		"appName":      ctx.App.Name,
-		"workloadType": workloadTypeAPI,
-	})
-	for _, service := range services {
-		if _, ok := ctx.APIs[service.Labels["apiName"]]; !ok {
-			config.Kubernetes.DeleteService(service.Name)
-		}
-	}
-
-	deployments, _ := config.Kubernetes.ListDeploymentsByLabels(map[string]string{
-		"appName":      ctx.App.Name,
-		"workloadType": workloadTypeAPI,
-	})
-	for _, deployment := range deployments {
-		if _, ok := ctx.APIs[deployment.Labels["apiName"]]; !ok {
-			config.Kubernetes.DeleteDeployment(deployment.Name)
-		}
-	}
-
-	hpas, _ := config.Kubernetes.ListHPAsByLabels(map[string]string{
+	err := deleteResources(map[string]string{
 		"appName":      ctx.App.Name,
 		"workloadType": workloadTypeAPI,
+	}, func(resourceLabels map[string]string) bool {
+		_, ok := ctx.APIs[resourceLabels["apiName"]]
+		return ok
 	})
-	for _, hpa := range hpas {
-		if _, ok := ctx.APIs[hpa.Labels["apiName"]]; !ok {
-			config.Kubernetes.DeleteHPA(hpa.Name)
-		}
+	if err != nil {
+		logging.With(logging.Fields{"app": ctx.App.Name}).Error(err, "delete old apis")
 	}
 }
 
@@ -895,8 +1468,12 @@ func apiDeploymentMap(appName string) (map[string]*kapps.Deployment, error) {
 }
 
 // Avoid pointer in loop issues
+// If an API has more than one deployment (i.e. a blue/green update is in progress), the newest one is used
 func addToDeploymentMap(deployments map[string]*kapps.Deployment, deployment kapps.Deployment) {
 	apiName := deployment.Labels["apiName"]
+	if existing, ok := deployments[apiName]; ok && deployment.CreationTimestamp.Before(&existing.CreationTimestamp) {
+		return
+	}
 	deployments[apiName] = &deployment
 }
 
@@ -905,7 +1482,22 @@ func internalAPIName(apiName string, appName string) string {
 }
 
 func APIsBaseURL() (string, error) {
-	service, err := config.IstioKubernetes.GetService("apis-ingressgateway")
+	return routes().baseURL(false)
+}
+
+// PrivateAPIsBaseURL is the URL of the internal load balancer, which is only reachable from within the cluster's VPC
+// (it is empty if none of the deployment's APIs are private)
+func PrivateAPIsBaseURL(ctx *context.Context) (string, error) {
+	for _, api := range ctx.APIs {
+		if apiGateway(api) == apisInternalGateway {
+			return routes().baseURL(true)
+		}
+	}
+	return "", nil
+}
+
+func loadBalancerURL(client *k8s.Client, serviceName string) (string, error) {
+	service, err := client.GetService(serviceName)
 	if err != nil {
 		return "", err
 	}
@@ -915,7 +1507,17 @@ func APIsBaseURL() (string, error) {
 	if len(service.Status.LoadBalancer.Ingress) == 0 {
 		return "", ErrorLoadBalancerInitializing()
 	}
-	return "http://" + service.Status.LoadBalancer.Ingress[0].Hostname, nil
+	if hostname := service.Status.LoadBalancer.Ingress[0].Hostname; hostname != "" {
+		return "http://" + hostname, nil
+	}
+	return "http://" + service.Status.LoadBalancer.Ingress[0].IP, nil
+}
+
+func apiProgressDeadline(api *context.API) *int32 {
+	if api.UpdateStrategy == nil {
+		return nil
+	}
+	return pointer.Int32(int32(api.UpdateStrategy.ProgressDeadline.Seconds()))
 }
 
 func APIPodComputeID(containers []kcore.Container) string {
@@ -937,7 +1539,7 @@ func APIPodCompute(containers []kcore.Container) (*k8s.Quantity, *k8s.Quantity,
 	var totalGPU int64
 
 	for _, container := range containers {
-		if container.Name != apiContainerName && container.Name != tfServingContainerName {
+		if container.Name != apiContainerName && container.Name != servingContainerName {
 			continue
 		}
 
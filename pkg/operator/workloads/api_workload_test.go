@@ -0,0 +1,155 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+func testQuantity(str string) k8s.Quantity {
+	return k8s.Quantity{Quantity: kresource.MustParse(str), UserString: str}
+}
+
+// testPythonAPI returns a python API with the given sidecars, in a context which can be used to build its workloads
+func testPythonAPI(sidecars ...*userconfig.Container) (*context.Context, *context.API) {
+	config.Cluster = &clusterconfig.InternalConfig{}
+	config.AWS = &aws.Client{Bucket: "cortex-test"}
+
+	api := &context.API{
+		API: &userconfig.API{
+			ResourceFields: userconfig.ResourceFields{Name: "iris"},
+			Predictor: &userconfig.Predictor{
+				Type:                userconfig.PythonPredictorType,
+				Path:                "predictor.py",
+				ProcessesPerReplica: 1,
+				ThreadsPerProcess:   1,
+			},
+			Compute: &userconfig.APICompute{
+				MinReplicas: 1,
+				MaxReplicas: 5,
+				CPU:         testQuantity("200m"),
+			},
+			Sidecars: sidecars,
+		},
+		ComputedResourceFields: &context.ComputedResourceFields{
+			ResourceFields: &context.ResourceFields{ID: "api-id"},
+		},
+	}
+
+	ctx := &context.Context{
+		ClusterConfig: config.Cluster,
+		App:           &context.App{App: &userconfig.App{Name: "test"}},
+		APIs:          context.APIs{api.Name: api},
+	}
+
+	return ctx, api
+}
+
+// The API's horizontal pod autoscaler scales on CPU utilization, which requires each of the pod's containers to request CPU
+func TestAPIPodContainersRequestCPU(t *testing.T) {
+	cpu := testQuantity("100m")
+	mem := testQuantity("64Mi")
+	ctx, api := testPythonAPI(
+		&userconfig.Container{
+			Name:  "exporter",
+			Image: "exporter:latest",
+			Compute: &userconfig.ContainerCompute{
+				CPU: &cpu,
+				Mem: &mem,
+			},
+		},
+	)
+
+	deployment := pythonAPISpec(ctx, api, "workload-id", "iris", 1)
+	containers := deployment.Spec.Template.Spec.Containers
+	require.Len(t, containers, 2)
+	require.Equal(t, "exporter", containers[1].Name)
+
+	for _, container := range containers {
+		cpu, ok := container.Resources.Requests[kcore.ResourceCPU]
+		require.True(t, ok, "container %s doesn't request cpu", container.Name)
+		require.False(t, cpu.IsZero(), "container %s doesn't request cpu", container.Name)
+	}
+
+	require.Equal(t, kresource.MustParse("64Mi"), containers[1].Resources.Requests[kcore.ResourceMemory])
+}
+
+func TestUserContainerEnvIsSorted(t *testing.T) {
+	_, api := testPythonAPI()
+	container := &userconfig.Container{
+		Name:  "exporter",
+		Image: "exporter:latest",
+		Env: map[string]string{
+			"PORT":      "9100",
+			"LOG_LEVEL": "info",
+			"ENDPOINT":  "localhost:8888",
+			"TIMEOUT":   "5s",
+		},
+	}
+
+	expected := []kcore.EnvVar{
+		{Name: "ENDPOINT", Value: "localhost:8888"},
+		{Name: "LOG_LEVEL", Value: "info"},
+		{Name: "PORT", Value: "9100"},
+		{Name: "TIMEOUT", Value: "5s"},
+	}
+	for i := 0; i < 10; i++ {
+		require.Equal(t, expected, userContainer(api, container).Env)
+	}
+}
+
+func TestAPIPodRequestsIncludeCache(t *testing.T) {
+	ctx, api := testPythonAPI()
+	cpu, mem, _ := apiPodRequests(ctx, api)
+	require.Equal(t, "200m", cpu.String())
+	require.Nil(t, mem)
+
+	apiMem := testQuantity("1Gi")
+	api.Compute.Mem = &apiMem
+	api.Cache = &userconfig.Cache{MaxEntries: 1000}
+
+	cpu, mem, _ = apiPodRequests(ctx, api)
+	require.Equal(t, "300m", cpu.String())
+	require.NotNil(t, mem)
+	require.Equal(t, "1088Mi", mem.String())
+
+	// the requests match the containers of the API's pods
+	var podCPU, podMem kresource.Quantity
+	for _, container := range pythonAPISpec(ctx, api, "workload-id", "iris", 1).Spec.Template.Spec.Containers {
+		podCPU.Add(*container.Resources.Requests.Cpu())
+		podMem.Add(*container.Resources.Requests.Memory())
+	}
+	require.Zero(t, cpu.Cmp(podCPU))
+	require.Zero(t, mem.Cmp(podMem))
+
+	// the API's requests and the cache's requests aren't modified
+	require.Equal(t, "200m", api.Compute.CPU.Quantity.String())
+	require.Equal(t, "1Gi", api.Compute.Mem.Quantity.String())
+	require.Equal(t, "100m", cacheCPURequest.String())
+	require.Equal(t, "64Mi", cacheMemRequest.String())
+}
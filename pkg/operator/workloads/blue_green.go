@@ -0,0 +1,221 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// APIs with the blue_green or canary update modes alternate between two deployments (each with its own service and HPA),
+// and the API's virtual service records which one its endpoint routes to in the "apiDeployment" label
+
+func isBlueGreen(api *context.API) bool {
+	return api.UpdateStrategy != nil && api.UpdateStrategy.Mode == userconfig.BlueGreenUpdateMode
+}
+
+func hasDeploymentSlots(api *context.API) bool {
+	return isBlueGreen(api) || isCanary(api)
+}
+
+func blueDeploymentName(api *context.API, appName string) string {
+	return internalAPIName(api.Name, appName) + "-blue"
+}
+
+func greenDeploymentName(api *context.API, appName string) string {
+	return internalAPIName(api.Name, appName) + "-green"
+}
+
+func previewVirtualServiceName(api *context.API, appName string) string {
+	return internalAPIName(api.Name, appName) + "-preview"
+}
+
+// activeDeploymentName returns the name of the deployment which the API's endpoint routes to ("" if there isn't one)
+func activeDeploymentName(ctx *context.Context, api *context.API) (string, error) {
+	virtualService, err := config.Kubernetes.GetVirtualService(internalAPIName(api.Name, ctx.App.Name), consts.K8sNamespace)
+	if err != nil {
+		return "", err
+	}
+	if virtualService == nil {
+		return "", nil
+	}
+
+	deploymentName := virtualService.GetLabels()["apiDeployment"]
+	if deploymentName == "" {
+		return "", nil
+	}
+
+	deployment, err := config.Kubernetes.GetDeployment(deploymentName)
+	if err != nil {
+		return "", err
+	}
+	if deployment == nil {
+		return "", nil
+	}
+
+	return deploymentName, nil
+}
+
+// apiDeploymentName returns the name of the deployment which runs (or will run) the API's current spec
+func apiDeploymentName(ctx *context.Context, api *context.API) (string, error) {
+	if !hasDeploymentSlots(api) {
+		return internalAPIName(api.Name, ctx.App.Name), nil
+	}
+
+	activeName, err := activeDeploymentName(ctx, api)
+	if err != nil {
+		return "", err
+	}
+	if activeName == "" {
+		return blueDeploymentName(api, ctx.App.Name), nil
+	}
+
+	activeDeployment, err := config.Kubernetes.GetDeployment(activeName)
+	if err != nil {
+		return "", err
+	}
+	if activeDeployment != nil && activeDeployment.Labels["resourceID"] == api.ID {
+		return activeName, nil
+	}
+
+	if activeName == blueDeploymentName(api, ctx.App.Name) {
+		return greenDeploymentName(api, ctx.App.Name), nil
+	}
+	return blueDeploymentName(api, ctx.App.Name), nil
+}
+
+// applyVirtualServices routes the API's endpoint to the given deployment, unless the API is being updated with
+// the blue_green update mode (in which case only the API's preview endpoint is routed to the new deployment)
+// or the canary update mode (in which case the new deployment's share of the traffic is managed by the canary analysis)
+func applyVirtualServices(ctx *context.Context, api *context.API, deploymentName string) error {
+	if hasDeploymentSlots(api) {
+		activeName, err := activeDeploymentName(ctx, api)
+		if err != nil {
+			return err
+		}
+
+		if activeName != "" && activeName != deploymentName {
+			if isCanary(api) {
+				if _, err := config.Kubernetes.DeleteVirtualService(previewVirtualServiceName(api, ctx.App.Name), consts.K8sNamespace); err != nil {
+					return err
+				}
+				return applyCanaryVirtualService(ctx, api, activeName, deploymentName)
+			}
+			_, err := config.Kubernetes.ApplyVirtualService(previewVirtualServiceSpec(ctx, api, deploymentName))
+			return err
+		}
+	}
+
+	if _, err := config.Kubernetes.ApplyVirtualService(virtualServiceSpec(ctx, api, deploymentName)); err != nil {
+		return err
+	}
+
+	if _, err := config.Kubernetes.DeleteVirtualService(previewVirtualServiceName(api, ctx.App.Name), consts.K8sNamespace); err != nil {
+		return err
+	}
+
+	// The API's endpoint no longer routes to any of its other deployments
+	return deleteOtherAPIDeployments(ctx, api, deploymentName)
+}
+
+func deleteOtherAPIDeployments(ctx *context.Context, api *context.API, deploymentName string) error {
+	for _, otherName := range []string{internalAPIName(api.Name, ctx.App.Name), blueDeploymentName(api, ctx.App.Name), greenDeploymentName(api, ctx.App.Name)} {
+		if otherName == deploymentName {
+			continue
+		}
+		if err := deleteAPIDeployment(otherName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func previewVirtualServiceSpec(ctx *context.Context, api *context.API, deploymentName string) *kunstructured.Unstructured {
+	return k8s.VirtualService(apiVirtualServiceSpec(ctx, api, previewVirtualServiceName(api, ctx.App.Name), *api.UpdateStrategy.PreviewEndpoint, deploymentName))
+}
+
+// deleteAPIDeployment deletes a deployment and the resources which route to or scale it
+func deleteAPIDeployment(deploymentName string) error {
+	if _, err := config.Kubernetes.DeleteHPA(deploymentName); err != nil {
+		return err
+	}
+	if _, err := config.Kubernetes.DeleteDestinationRule(deploymentName, consts.K8sNamespace); err != nil {
+		return err
+	}
+	if _, err := config.Kubernetes.DeleteService(deploymentName); err != nil {
+		return err
+	}
+	if _, err := config.Kubernetes.DeleteDeployment(deploymentName); err != nil {
+		return err
+	}
+	return nil
+}
+
+// PromoteAPI routes a blue_green or canary API's endpoint to the deployment which runs its current spec (once it's ready),
+// and deletes the deployment which the endpoint previously routed to
+func PromoteAPI(ctx *context.Context, apiName string) error {
+	api := ctx.APIs[apiName]
+
+	if !hasDeploymentSlots(api) {
+		return ErrorAPINotBlueGreen(apiName)
+	}
+
+	activeName, err := activeDeploymentName(ctx, api)
+	if err != nil {
+		return err
+	}
+	deploymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return err
+	}
+	if deploymentName == activeName {
+		return ErrorNoAPIUpdateToPromote(apiName)
+	}
+
+	updatedReplicas, err := numUpdatedReadyReplicas(ctx, api)
+	if err != nil {
+		return err
+	}
+	if updatedReplicas < api.Compute.MinReplicas {
+		return ErrorAPIUpdateNotReady(apiName, updatedReplicas, api.Compute.MinReplicas)
+	}
+
+	return promoteDeployment(ctx, api, activeName, deploymentName)
+}
+
+func promoteDeployment(ctx *context.Context, api *context.API, activeName string, deploymentName string) error {
+	if _, err := config.Kubernetes.ApplyVirtualService(virtualServiceSpec(ctx, api, deploymentName)); err != nil {
+		return err
+	}
+
+	if _, err := config.Kubernetes.DeleteVirtualService(previewVirtualServiceName(api, ctx.App.Name), consts.K8sNamespace); err != nil {
+		return err
+	}
+
+	if activeName != "" {
+		if err := deleteAPIDeployment(activeName); err != nil {
+			return err
+		}
+	}
+
+	return updateGatewayAuthorizationPolicies()
+}
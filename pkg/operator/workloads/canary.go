@@ -0,0 +1,224 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// The state of a canary analysis is stored in the annotations of the API's virtual service
+const (
+	canaryIDAnnotation         = "canaryID"         // the resource ID of the API which is being analyzed
+	canaryWeightAnnotation     = "canaryWeight"     // the percentage of requests currently routed to the canary
+	canaryStepStartAnnotation  = "canaryStepStart"  // when the current weight was applied (not set until the canary is ready)
+	canaryRolledBackAnnotation = "canaryRolledBack" // the resource ID of the last API which was rolled back
+)
+
+func isCanary(api *context.API) bool {
+	return api.UpdateStrategy != nil && api.UpdateStrategy.Mode == userconfig.CanaryUpdateMode
+}
+
+func canaryVirtualServiceSpec(ctx *context.Context, api *context.API, activeName string, canaryName string, weight int32, stepStart *time.Time) *kunstructured.Unstructured {
+	spec := apiVirtualServiceSpec(ctx, api, internalAPIName(api.Name, ctx.App.Name), *api.Endpoint, activeName)
+	spec.Canary = &k8s.CanaryRoute{
+		ServiceName: canaryName,
+		Weight:      weight,
+	}
+	spec.Labels["canaryDeployment"] = canaryName
+	spec.Annotations = map[string]string{
+		canaryIDAnnotation:     api.ID,
+		canaryWeightAnnotation: s.Int32(weight),
+	}
+	if stepStart != nil {
+		spec.Annotations[canaryStepStartAnnotation] = stepStart.Format(time.RFC3339)
+	}
+	return k8s.VirtualService(spec)
+}
+
+// applyCanaryVirtualService starts the canary analysis of the given deployment (if it hasn't already started);
+// no requests are routed to the canary until it is ready
+func applyCanaryVirtualService(ctx *context.Context, api *context.API, activeName string, canaryName string) error {
+	virtualService, err := config.Kubernetes.GetVirtualService(internalAPIName(api.Name, ctx.App.Name), consts.K8sNamespace)
+	if err != nil {
+		return err
+	}
+	if virtualService != nil && virtualService.GetAnnotations()[canaryIDAnnotation] == api.ID {
+		return nil
+	}
+
+	_, err = config.Kubernetes.ApplyVirtualService(canaryVirtualServiceSpec(ctx, api, activeName, canaryName, 0, nil))
+	return err
+}
+
+func isCanaryRolledBack(ctx *context.Context, api *context.API) (bool, error) {
+	if !isCanary(api) {
+		return false, nil
+	}
+
+	virtualService, err := config.Kubernetes.GetVirtualService(internalAPIName(api.Name, ctx.App.Name), consts.K8sNamespace)
+	if err != nil {
+		return false, err
+	}
+
+	return virtualService != nil && virtualService.GetAnnotations()[canaryRolledBackAnnotation] == api.ID, nil
+}
+
+func updateCanaries() {
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			if !isCanary(api) {
+				continue
+			}
+			if err := updateCanary(ctx, api); err != nil {
+				telemetry.Error(errors.Wrap(err, ctx.App.Name, api.Name))
+				apiCronLog(ctx, api).Error(err)
+			}
+		}
+	}
+}
+
+// updateCanary shifts more traffic to the API's canary once it has met its thresholds for a full step,
+// and rolls it back if it breaches them
+func updateCanary(ctx *context.Context, api *context.API) error {
+	virtualService, err := config.Kubernetes.GetVirtualService(internalAPIName(api.Name, ctx.App.Name), consts.K8sNamespace)
+	if err != nil {
+		return err
+	}
+	if virtualService == nil || virtualService.GetAnnotations()[canaryIDAnnotation] != api.ID {
+		return nil
+	}
+
+	canaryConfig := api.UpdateStrategy.Canary
+	activeName := virtualService.GetLabels()["apiDeployment"]
+	canaryName := virtualService.GetLabels()["canaryDeployment"]
+	annotations := virtualService.GetAnnotations()
+
+	readyReplicas, err := numUpdatedReadyReplicas(ctx, api)
+	if err != nil {
+		return err
+	}
+	if readyReplicas < api.Compute.MinReplicas {
+		return nil
+	}
+
+	now := time.Now()
+
+	if annotations[canaryStepStartAnnotation] == "" {
+		_, err := config.Kubernetes.ApplyVirtualService(canaryVirtualServiceSpec(ctx, api, activeName, canaryName, canaryConfig.StepWeight, &now))
+		return err
+	}
+
+	stepStart, err := time.Parse(time.RFC3339, annotations[canaryStepStartAnnotation])
+	if err != nil {
+		return err
+	}
+	if now.Sub(stepStart) < canaryConfig.StepInterval {
+		return nil
+	}
+
+	networkStats, err := apiNetworkStats(ctx, api, stepStart, now)
+	if err != nil {
+		return err
+	}
+
+	if networkStats.Total < int(canaryConfig.MinRequests) {
+		return nil
+	}
+
+	if reason := canaryThresholdBreach(canaryConfig, networkStats); reason != "" {
+		apiCronLog(ctx, api).With(logging.Fields{"reason": reason}).Warn("rolling back canary")
+		return rollbackCanary(ctx, api, activeName, canaryName)
+	}
+
+	weight, ok := s.ParseInt32(annotations[canaryWeightAnnotation])
+	if !ok {
+		weight = 0
+	}
+	weight += canaryConfig.StepWeight
+
+	if weight >= 100 {
+		return promoteDeployment(ctx, api, activeName, canaryName)
+	}
+
+	_, err = config.Kubernetes.ApplyVirtualService(canaryVirtualServiceSpec(ctx, api, activeName, canaryName, weight, &now))
+	return err
+}
+
+// canaryThresholdBreach returns a description of the threshold which the canary breached ("" if none were breached)
+func canaryThresholdBreach(canaryConfig *userconfig.Canary, networkStats *schema.NetworkStats) string {
+	if networkStats.Total > 0 {
+		errorRate := float64(networkStats.Code5XX) / float64(networkStats.Total)
+		if errorRate > canaryConfig.MaxErrorRate {
+			return fmt.Sprintf("error rate of %s exceeded %s of %s", s.Float64(errorRate), userconfig.MaxErrorRateKey, s.Float64(canaryConfig.MaxErrorRate))
+		}
+	}
+
+	if canaryConfig.MaxLatency > 0 && networkStats.Latency != nil {
+		latency := time.Duration(*networkStats.Latency * float64(time.Millisecond))
+		if latency > canaryConfig.MaxLatency {
+			return fmt.Sprintf("average latency of %s exceeded %s of %s", latency.Round(time.Millisecond).String(), userconfig.MaxLatencyKey, canaryConfig.MaxLatency.String())
+		}
+	}
+
+	return ""
+}
+
+// rollbackCanary routes all of the API's traffic back to the active deployment and deletes the canary;
+// the rollback is recorded so that the canary isn't redeployed until the API is updated again
+func rollbackCanary(ctx *context.Context, api *context.API, activeName string, canaryName string) error {
+	spec := apiVirtualServiceSpec(ctx, api, internalAPIName(api.Name, ctx.App.Name), *api.Endpoint, activeName)
+	spec.Annotations = map[string]string{
+		canaryRolledBackAnnotation: api.ID,
+	}
+
+	if _, err := config.Kubernetes.ApplyVirtualService(k8s.VirtualService(spec)); err != nil {
+		return err
+	}
+
+	return deleteAPIDeployment(canaryName)
+}
+
+func apiNetworkStats(ctx *context.Context, api *context.API, startTime time.Time, endTime time.Time) (*schema.NetworkStats, error) {
+	startTime = startTime.Truncate(time.Minute)
+	metricsDataQuery := cloudwatch.GetMetricDataInput{
+		EndTime:           &endTime,
+		StartTime:         &startTime,
+		MetricDataQueries: getNetworkStatsDef(ctx.App.Name, api, 60),
+	}
+
+	output, err := config.AWS.CloudWatchMetrics.GetMetricData(&metricsDataQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	return extractNetworkMetrics(output.MetricDataResults)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+
+	awslib "github.com/cortexlabs/cortex/pkg/lib/aws"
+)
+
+// CapacityCalculator computes the compute which is available to API replicas on the nodes of a node group.
+// Its methods don't modify the calculator or their arguments (quantities share their underlying values when they are copied, so they are deep copied before any arithmetic)
+type CapacityCalculator struct {
+	InstanceType string                  // the cluster's configured instance type
+	InstanceMem  kresource.Quantity      // the memory of the cluster's configured instance type
+	MemCapacity  kresource.Quantity      // the memory capacity which was measured on nodes of the cluster's configured instance type
+	Reserves     map[string]*NodeReserve // instance type -> the reserve which was discovered on running nodes
+	CPUReserve   *kresource.Quantity     // overrides the reserves' CPU if set
+	MemReserve   *kresource.Quantity     // overrides the reserves' memory if set
+}
+
+func (calc *CapacityCalculator) NodeGroupCapacity(name string, instanceMetadata *awslib.InstanceMetadata) *NodeGroupCapacity {
+	reserve := calc.Reserve(instanceMetadata.Type, instanceMetadata.GPU)
+
+	cpu := instanceMetadata.CPU.DeepCopy()
+	cpu.Sub(reserve.CPU)
+	mem := calc.Mem(instanceMetadata)
+	mem.Sub(reserve.Mem)
+
+	return &NodeGroupCapacity{
+		Name:         name,
+		InstanceType: instanceMetadata.Type,
+		Arch:         awslib.InstanceArch(instanceMetadata.Type),
+		CPU:          cpu,
+		Mem:          mem,
+		GPU:          instanceMetadata.GPU,
+		Reserve:      reserve,
+	}
+}
+
+// Mem returns the memory capacity of a node of the instance type; the memory capacity of a node is less than its instance type's memory,
+// so instance types other than the configured one are scaled by the ratio which was measured for the configured instance type
+func (calc *CapacityCalculator) Mem(instanceMetadata *awslib.InstanceMetadata) kresource.Quantity {
+	if instanceMetadata.Type == calc.InstanceType {
+		return calc.MemCapacity.DeepCopy()
+	}
+	if calc.InstanceMem.Value() <= 0 || calc.MemCapacity.Cmp(calc.InstanceMem) >= 0 {
+		return instanceMetadata.Memory.DeepCopy()
+	}
+	memRatio := float64(calc.MemCapacity.Value()) / float64(calc.InstanceMem.Value())
+	return *kresource.NewQuantity(int64(float64(instanceMetadata.Memory.Value())*memRatio), kresource.BinarySI)
+}
+
+// Reserve returns the reserve of a node of the instance type; the cluster's cpu_reserve and mem_reserve take precedence over the discovered reserves,
+// and the default reserves are used for instance types which don't have any running nodes
+func (calc *CapacityCalculator) Reserve(instanceType string, gpu int64) NodeReserve {
+	var reserve NodeReserve
+	if discovered, ok := calc.Reserves[instanceType]; ok {
+		reserve = NodeReserve{CPU: discovered.CPU.DeepCopy(), Mem: discovered.Mem.DeepCopy()}
+	} else {
+		reserve = NodeReserve{CPU: cortexCPUReserve.DeepCopy(), Mem: cortexMemReserve.DeepCopy()}
+		if gpu > 0 {
+			// Reserve resources for nvidia device plugin daemonset
+			reserve.add(nvidiaCPUReserve, nvidiaMemReserve)
+		}
+	}
+
+	if calc.CPUReserve != nil {
+		reserve.CPU = calc.CPUReserve.DeepCopy()
+	}
+	if calc.MemReserve != nil {
+		reserve.Mem = calc.MemReserve.DeepCopy()
+	}
+
+	return reserve
+}
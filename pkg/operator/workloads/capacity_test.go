@@ -0,0 +1,284 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	awslib "github.com/cortexlabs/cortex/pkg/lib/aws"
+)
+
+func testInstanceMetadata(instanceType string, cpu string, mem string, gpu int64) *awslib.InstanceMetadata {
+	return &awslib.InstanceMetadata{
+		Type:   instanceType,
+		CPU:    kresource.MustParse(cpu),
+		Memory: kresource.MustParse(mem),
+		GPU:    gpu,
+	}
+}
+
+func testNode(name string, instanceType string, capacityCPU string, capacityMem string, allocatableCPU string, allocatableMem string) kcore.Node {
+	return kcore.Node{
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"node.kubernetes.io/instance-type": instanceType},
+		},
+		Status: kcore.NodeStatus{
+			Capacity: kcore.ResourceList{
+				kcore.ResourceCPU:    kresource.MustParse(capacityCPU),
+				kcore.ResourceMemory: kresource.MustParse(capacityMem),
+			},
+			Allocatable: kcore.ResourceList{
+				kcore.ResourceCPU:    kresource.MustParse(allocatableCPU),
+				kcore.ResourceMemory: kresource.MustParse(allocatableMem),
+			},
+		},
+	}
+}
+
+func testPod(nodeName string, daemonSet bool, phase kcore.PodPhase, cpu string, mem string) kcore.Pod {
+	pod := kcore.Pod{
+		Spec: kcore.PodSpec{
+			NodeName: nodeName,
+			Containers: []kcore.Container{
+				{
+					Resources: kcore.ResourceRequirements{
+						Requests: kcore.ResourceList{
+							kcore.ResourceCPU:    kresource.MustParse(cpu),
+							kcore.ResourceMemory: kresource.MustParse(mem),
+						},
+					},
+				},
+			},
+		},
+		Status: kcore.PodStatus{Phase: phase},
+	}
+	if daemonSet {
+		pod.OwnerReferences = []kmeta.OwnerReference{{Kind: "DaemonSet"}}
+	}
+	return pod
+}
+
+func requireQuantity(t *testing.T, expected string, actual kresource.Quantity, name string) {
+	t.Helper()
+	expectedQuantity := kresource.MustParse(expected)
+	require.Zero(t, expectedQuantity.Cmp(actual), "%s: expected %s, got %s", name, expected, actual.String())
+}
+
+func TestNodeReserves(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		nodes    []kcore.Node
+		pods     []kcore.Pod
+		expected map[string][2]string // instance type -> cpu and mem reserve
+	}{
+		{
+			name:     "no nodes",
+			expected: map[string][2]string{},
+		},
+		{
+			name: "capacity minus allocatable plus daemon set requests and buffer",
+			nodes: []kcore.Node{
+				testNode("node-1", "m5.large", "2", "8Gi", "1800m", "7Gi"),
+			},
+			pods: []kcore.Pod{
+				testPod("node-1", true, kcore.PodRunning, "200m", "200Mi"),
+				testPod("node-1", true, kcore.PodRunning, "100m", "100Mi"),
+				testPod("node-1", false, kcore.PodRunning, "1", "1Gi"),  // api replicas aren't reserved
+				testPod("node-1", true, kcore.PodSucceeded, "1", "1Gi"), // finished pods don't request compute
+				testPod("node-2", true, kcore.PodRunning, "1", "1Gi"),   // other nodes' daemon sets aren't included
+				testPod("", true, kcore.PodPending, "1", "1Gi"),         // unscheduled pods aren't included
+			},
+			expected: map[string][2]string{
+				"m5.large": {"600m", "1424Mi"}, // (200m + 200m + 100m + 100m buffer), (1Gi + 200Mi + 100Mi + 100Mi buffer)
+			},
+		},
+		{
+			name: "largest reserve of each instance type",
+			nodes: []kcore.Node{
+				testNode("node-1", "m5.large", "2", "8Gi", "1800m", "7Gi"),
+				testNode("node-2", "m5.large", "2", "8Gi", "1500m", "7500Mi"),
+				testNode("node-3", "g4dn.xlarge", "4", "16Gi", "3900m", "15Gi"),
+			},
+			pods: []kcore.Pod{
+				testPod("node-1", true, kcore.PodRunning, "200m", "500Mi"),
+			},
+			expected: map[string][2]string{
+				"m5.large":    {"600m", "1624Mi"}, // cpu from node-2 (500m + 100m), mem from node-1 (1Gi + 500Mi + 100Mi)
+				"g4dn.xlarge": {"200m", "1124Mi"},
+			},
+		},
+		{
+			name: "nodes without an instance type",
+			nodes: []kcore.Node{
+				{
+					ObjectMeta: kmeta.ObjectMeta{Name: "node-1"},
+				},
+			},
+			expected: map[string][2]string{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reserves := nodeReserves(tc.nodes, tc.pods)
+			require.Len(t, reserves, len(tc.expected))
+			for instanceType, expected := range tc.expected {
+				require.Contains(t, reserves, instanceType)
+				requireQuantity(t, expected[0], reserves[instanceType].CPU, instanceType+" cpu")
+				requireQuantity(t, expected[1], reserves[instanceType].Mem, instanceType+" mem")
+			}
+		})
+	}
+}
+
+func TestNodeGroupCapacity(t *testing.T) {
+	cpuReserve := kresource.MustParse("1")
+	memReserve := kresource.MustParse("2Gi")
+
+	for _, tc := range []struct {
+		name             string
+		calculator       *CapacityCalculator
+		instanceMetadata *awslib.InstanceMetadata
+		expectedCPU      string
+		expectedMem      string
+		expectedReserve  [2]string
+	}{
+		{
+			name: "discovered reserve of the configured instance type",
+			calculator: &CapacityCalculator{
+				InstanceType: "m5.large",
+				InstanceMem:  kresource.MustParse("8Gi"),
+				MemCapacity:  kresource.MustParse("7800Mi"),
+				Reserves: map[string]*NodeReserve{
+					"m5.large": {CPU: kresource.MustParse("600m"), Mem: kresource.MustParse("1000Mi")},
+				},
+			},
+			instanceMetadata: testInstanceMetadata("m5.large", "2", "8Gi", 0),
+			expectedCPU:      "1400m",
+			expectedMem:      "6800Mi",
+			expectedReserve:  [2]string{"600m", "1000Mi"},
+		},
+		{
+			name: "no nodes",
+			calculator: &CapacityCalculator{
+				InstanceType: "m5.large",
+				InstanceMem:  kresource.MustParse("8Gi"),
+				MemCapacity:  kresource.MustParse("8Gi"),
+			},
+			instanceMetadata: testInstanceMetadata("m5.large", "2", "8Gi", 0),
+			expectedCPU:      "1200m",
+			expectedMem:      "6992Mi",
+			expectedReserve:  [2]string{"800m", "1200Mi"},
+		},
+		{
+			name: "gpu instance type without nodes",
+			calculator: &CapacityCalculator{
+				InstanceType: "m5.large",
+				InstanceMem:  kresource.MustParse("8Gi"),
+				MemCapacity:  kresource.MustParse("7Gi"),
+				Reserves: map[string]*NodeReserve{
+					"m5.large": {CPU: kresource.MustParse("600m"), Mem: kresource.MustParse("1000Mi")},
+				},
+			},
+			instanceMetadata: testInstanceMetadata("g4dn.xlarge", "4", "16Gi", 1),
+			expectedCPU:      "3100m",   // 4 - (800m + 100m for the nvidia device plugin)
+			expectedMem:      "13036Mi", // 16Gi * 7/8 - (1200Mi + 100Mi)
+			expectedReserve:  [2]string{"900m", "1300Mi"},
+		},
+		{
+			name: "configured reserves take precedence",
+			calculator: &CapacityCalculator{
+				InstanceType: "m5.large",
+				InstanceMem:  kresource.MustParse("8Gi"),
+				MemCapacity:  kresource.MustParse("8Gi"),
+				Reserves: map[string]*NodeReserve{
+					"g4dn.xlarge": {CPU: kresource.MustParse("600m"), Mem: kresource.MustParse("1000Mi")},
+				},
+				CPUReserve: &cpuReserve,
+				MemReserve: &memReserve,
+			},
+			instanceMetadata: testInstanceMetadata("g4dn.xlarge", "4", "16Gi", 1),
+			expectedCPU:      "3",
+			expectedMem:      "14Gi",
+			expectedReserve:  [2]string{"1", "2Gi"},
+		},
+		{
+			name: "memory capacity hasn't been measured",
+			calculator: &CapacityCalculator{
+				InstanceType: "m5.large",
+			},
+			instanceMetadata: testInstanceMetadata("m5.xlarge", "4", "16Gi", 0),
+			expectedCPU:      "3200m",
+			expectedMem:      "15184Mi",
+			expectedReserve:  [2]string{"800m", "1200Mi"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			capacity := tc.calculator.NodeGroupCapacity("ng", tc.instanceMetadata)
+			require.Equal(t, "ng", capacity.Name)
+			require.Equal(t, tc.instanceMetadata.Type, capacity.InstanceType)
+			require.Equal(t, tc.instanceMetadata.GPU, capacity.GPU)
+			requireQuantity(t, tc.expectedCPU, capacity.CPU, "cpu")
+			requireQuantity(t, tc.expectedMem, capacity.Mem, "mem")
+			requireQuantity(t, tc.expectedReserve[0], capacity.Reserve.CPU, "cpu reserve")
+			requireQuantity(t, tc.expectedReserve[1], capacity.Reserve.Mem, "mem reserve")
+		})
+	}
+}
+
+// Quantities share their underlying values when they're copied, so computing (and modifying) capacities mustn't change the calculator, the instance metadata, or the default reserves
+func TestNodeGroupCapacityDoesntMutate(t *testing.T) {
+	calculator := &CapacityCalculator{
+		InstanceType: "m5.large",
+		InstanceMem:  kresource.MustParse("8Gi"),
+		MemCapacity:  kresource.MustParse("7800Mi"),
+		Reserves: map[string]*NodeReserve{
+			"m5.large": {CPU: kresource.MustParse("600m"), Mem: kresource.MustParse("1000Mi")},
+		},
+	}
+	configured := testInstanceMetadata("m5.large", "2", "8Gi", 0)
+	gpu := testInstanceMetadata("g4dn.xlarge", "4", "16Gi", 1)
+
+	for i := 0; i < 3; i++ {
+		for _, instanceMetadata := range []*awslib.InstanceMetadata{configured, gpu} {
+			capacity := calculator.NodeGroupCapacity("ng", instanceMetadata)
+			capacity.CPU.Sub(kresource.MustParse("100m"))
+			capacity.Mem.Sub(kresource.MustParse("100Mi"))
+			capacity.Reserve.add(kresource.MustParse("100m"), kresource.MustParse("100Mi"))
+		}
+	}
+
+	requireQuantity(t, "8Gi", calculator.InstanceMem, "instance mem")
+	requireQuantity(t, "7800Mi", calculator.MemCapacity, "mem capacity")
+	requireQuantity(t, "600m", calculator.Reserves["m5.large"].CPU, "discovered cpu reserve")
+	requireQuantity(t, "1000Mi", calculator.Reserves["m5.large"].Mem, "discovered mem reserve")
+	requireQuantity(t, "2", configured.CPU, "instance cpu")
+	requireQuantity(t, "8Gi", configured.Memory, "instance mem")
+	requireQuantity(t, "4", gpu.CPU, "gpu instance cpu")
+	requireQuantity(t, "16Gi", gpu.Memory, "gpu instance mem")
+	requireQuantity(t, "800m", cortexCPUReserve, "default cpu reserve")
+	requireQuantity(t, "1200Mi", cortexMemReserve, "default mem reserve")
+	requireQuantity(t, "100m", nvidiaCPUReserve, "nvidia cpu reserve")
+	requireQuantity(t, "100Mi", nvidiaMemReserve, "nvidia mem reserve")
+
+	capacity := calculator.NodeGroupCapacity("ng", gpu)
+	requireQuantity(t, "3100m", capacity.CPU, "cpu")
+}
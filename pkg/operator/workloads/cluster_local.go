@@ -0,0 +1,63 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// applyClusterLocalAPI deletes the API's load balancer routes (it's only reachable at its Kubernetes service);
+// cluster-local APIs can't use blue/green or canary updates, so the service always has the API's internal name
+func applyClusterLocalAPI(ctx *context.Context, api *context.API, deploymentName string) error {
+	labels := map[string]string{
+		"appName":      ctx.App.Name,
+		"workloadType": workloadTypeAPI,
+		"apiName":      api.Name,
+	}
+	if err := routes().deleteRoutes(labels, func(map[string]string) bool { return true }); err != nil {
+		return err
+	}
+
+	// the API's clients (i.e. the other APIs) use mutual TLS to connect to its service, and their proxies enforce its circuit breaker
+	if config.Cluster.MTLS || hasCircuitBreaker(api) {
+		if _, err := config.Kubernetes.ApplyDestinationRule(destinationRuleSpec(ctx, api, deploymentName)); err != nil {
+			return err
+		}
+	}
+
+	return deleteOtherAPIDeployments(ctx, api, deploymentName)
+}
+
+// ClusterLocalAPIURL is the URL of a cluster-local API's service
+func ClusterLocalAPIURL(appName string, apiName string) string {
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local:%s/predict", internalAPIName(apiName, appName), consts.K8sNamespace, defaultPortStr)
+}
+
+// ClusterLocalAPIURLs returns the URLs of the deployment's cluster-local APIs (by API name)
+func ClusterLocalAPIURLs(ctx *context.Context) map[string]string {
+	urls := map[string]string{}
+	for _, api := range ctx.APIs {
+		if api.IsClusterLocal() {
+			urls[api.Name] = ClusterLocalAPIURL(ctx.App.Name, api.Name)
+		}
+	}
+	return urls
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	kcore "k8s.io/api/core/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	_costInterval      = 5 * time.Minute
+	_costHistoryWindow = 24 * time.Hour
+)
+
+var _lastCostCron time.Time
+
+type costSample struct {
+	time   time.Time
+	hourly float64
+}
+
+// The costs which were estimated by the most recent cron, and the hourly cost of each API over the history window
+// (app name + "/" + API name -> samples, oldest first; the history is kept across updates of the API)
+var _costs = struct {
+	sync.RWMutex
+	estimate  *schema.CostsResponse
+	histories map[string][]costSample
+}{histories: make(map[string][]costSample)}
+
+func GetCosts() *schema.CostsResponse {
+	_costs.RLock()
+	defer _costs.RUnlock()
+	return _costs.estimate
+}
+
+func getAPICost(ctx *context.Context, api *context.API) *schema.APICost {
+	estimate := GetCosts()
+	if estimate == nil {
+		return nil
+	}
+	for i := range estimate.APIs {
+		if estimate.APIs[i].AppName == ctx.App.Name && estimate.APIs[i].APIName == api.Name {
+			return &estimate.APIs[i]
+		}
+	}
+	return nil
+}
+
+// updateCosts estimates the cost of the cluster's instances, and attributes the cost of each instance to the API replicas which are scheduled on it
+// in proportion to the share of the instance's compute which they request
+func updateCosts(apiPods []kcore.Pod) error {
+	nodes, err := config.Kubernetes.ListNodes(&kmeta.ListOptions{
+		LabelSelector: k8s.LabelSelector(map[string]string{"workload": "true"}),
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	estimate := &schema.CostsResponse{
+		FixedHourly: clusterconfig.FixedHourlyPrice(*config.Cluster.Region),
+		Time:        now,
+	}
+
+	nodeInstanceTypes := make(map[string]string) // node name -> instance type
+	instanceCounts := make(map[string]int)
+	for _, node := range nodes {
+		instanceType := node.Labels["beta.kubernetes.io/instance-type"]
+		if _, ok := awsInstanceMetadata(instanceType); !ok {
+			instanceType = config.Cluster.InstanceMetadata.Type
+		}
+		nodeInstanceTypes[node.Name] = instanceType
+		instanceCounts[instanceType]++
+	}
+
+	var workersHourly float64
+	for instanceType, count := range instanceCounts {
+		hourly := float64(count) * config.Cluster.WorkerHourlyPrice(instanceType)
+		estimate.Instances = append(estimate.Instances, schema.InstanceCost{InstanceType: instanceType, Count: count, Hourly: hourly})
+		workersHourly += hourly
+	}
+	sort.Slice(estimate.Instances, func(i, j int) bool {
+		return estimate.Instances[i].InstanceType < estimate.Instances[j].InstanceType
+	})
+
+	apiHourly := make(map[string]float64) // app name + "/" + API name -> hourly cost
+	apiReplicas := make(map[string]int)
+	for i := range apiPods {
+		pod := &apiPods[i]
+		instanceType, ok := nodeInstanceTypes[pod.Spec.NodeName]
+		if !ok || pod.Status.Phase == kcore.PodSucceeded || pod.Status.Phase == kcore.PodFailed {
+			continue // the pod isn't using a worker instance's compute
+		}
+		key := pod.Labels["appName"] + "/" + pod.Labels["apiName"]
+		apiHourly[key] += podComputeShare(pod, instanceType) * config.Cluster.WorkerHourlyPrice(instanceType)
+		apiReplicas[key]++
+	}
+
+	_costs.Lock()
+	defer _costs.Unlock()
+
+	histories := make(map[string][]costSample)
+	var requestedHourly float64
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			key := ctx.App.Name + "/" + api.Name
+
+			history := append(trimCostHistory(_costs.histories[key], now), costSample{time: now, hourly: apiHourly[key]})
+			histories[key] = history
+
+			var avgHourly float64
+			for _, sample := range history {
+				avgHourly += sample.hourly / float64(len(history))
+			}
+
+			estimate.APIs = append(estimate.APIs, schema.APICost{
+				AppName:   ctx.App.Name,
+				APIName:   api.Name,
+				Replicas:  apiReplicas[key],
+				Hourly:    apiHourly[key],
+				AvgHourly: avgHourly,
+				Monthly:   avgHourly * clusterconfig.HoursPerMonth,
+			})
+			requestedHourly += apiHourly[key]
+		}
+	}
+	sort.SliceStable(estimate.APIs, func(i, j int) bool {
+		return estimate.APIs[i].Hourly > estimate.APIs[j].Hourly
+	})
+
+	estimate.IdleHourly = math.Max(workersHourly-requestedHourly, 0)
+	estimate.Hourly = estimate.FixedHourly + workersHourly
+	estimate.Monthly = estimate.Hourly * clusterconfig.HoursPerMonth
+
+	_costs.estimate = estimate
+	_costs.histories = histories // the histories of deleted APIs are dropped
+	return nil
+}
+
+func trimCostHistory(history []costSample, now time.Time) []costSample {
+	for i, sample := range history {
+		if now.Sub(sample.time) < _costHistoryWindow {
+			return history[i:]
+		}
+	}
+	return nil
+}
+
+// podComputeShare returns the largest share of the instance's CPU, memory, or GPUs which the pod's containers request (at most 1)
+func podComputeShare(pod *kcore.Pod, instanceType string) float64 {
+	instanceMetadata, ok := awsInstanceMetadata(instanceType)
+	if !ok {
+		return 0
+	}
+
+	var cpu, mem, gpu int64
+	for _, container := range pod.Spec.Containers {
+		cpu += container.Resources.Requests.Cpu().MilliValue()
+		mem += container.Resources.Requests.Memory().Value()
+		gpuRequest := container.Resources.Requests["nvidia.com/gpu"]
+		gpu += gpuRequest.Value()
+	}
+
+	var share float64
+	if instanceCPU := instanceMetadata.CPU.MilliValue(); instanceCPU > 0 {
+		share = math.Max(share, float64(cpu)/float64(instanceCPU))
+	}
+	if instanceMem := instanceMetadata.Memory.Value(); instanceMem > 0 {
+		share = math.Max(share, float64(mem)/float64(instanceMem))
+	}
+	if instanceMetadata.GPU > 0 {
+		share = math.Max(share, float64(gpu)/float64(instanceMetadata.GPU))
+	}
+	return math.Min(share, 1)
+}
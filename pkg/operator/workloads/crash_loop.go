@@ -0,0 +1,295 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/lib/webhooks"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// The crash loop which stopped an API's rollout is stored (as JSON) in an annotation of the API's deployment
+const crashLoopAnnotation = "crashLoop"
+
+const (
+	_crashLoopWindow           = 10 * time.Minute
+	_crashLoopRestartThreshold = 3 // the number of restarts of an API's updated replicas within the window which trips the circuit breaker
+	_crashLoopLogLines         = 20
+	_crashLoopMaxLogsLength    = 4096
+)
+
+// Only accessed by the cron
+var apiRestarts = struct {
+	podRestartCounts map[string]int32       // pod name -> number of container restarts
+	restartTimes     map[string][]time.Time // resourceID -> when the API's replicas restarted (within the window)
+}{
+	podRestartCounts: make(map[string]int32),
+	restartTimes:     make(map[string][]time.Time),
+}
+
+func podRestartCount(pod *kcore.Pod) int32 {
+	var restarts int32
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		restarts += containerStatus.RestartCount
+	}
+	return restarts
+}
+
+func recordAPIRestarts(apiPods []kcore.Pod) {
+	now := time.Now()
+
+	podRestartCounts := make(map[string]int32, len(apiPods))
+	for i := range apiPods {
+		pod := &apiPods[i]
+		restarts := podRestartCount(pod)
+		podRestartCounts[pod.Name] = restarts
+
+		prevRestarts, ok := apiRestarts.podRestartCounts[pod.Name]
+		if !ok && now.Sub(pod.CreationTimestamp.Time) > _crashLoopWindow {
+			prevRestarts = restarts // the pod's restarts may have happened before the window (e.g. if the operator restarted)
+		}
+
+		resourceID := pod.Labels["resourceID"]
+		for i := prevRestarts; i < restarts; i++ {
+			apiRestarts.restartTimes[resourceID] = append(apiRestarts.restartTimes[resourceID], now)
+		}
+	}
+	apiRestarts.podRestartCounts = podRestartCounts
+
+	for resourceID, restartTimes := range apiRestarts.restartTimes {
+		var recentRestartTimes []time.Time
+		for _, restartTime := range restartTimes {
+			if now.Sub(restartTime) <= _crashLoopWindow {
+				recentRestartTimes = append(recentRestartTimes, restartTime)
+			}
+		}
+		if len(recentRestartTimes) == 0 {
+			delete(apiRestarts.restartTimes, resourceID)
+		} else {
+			apiRestarts.restartTimes[resourceID] = recentRestartTimes
+		}
+	}
+}
+
+func updateCrashLoops(apiPods []kcore.Pod) {
+	recordAPIRestarts(apiPods)
+
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			if len(apiRestarts.restartTimes[api.ID]) < _crashLoopRestartThreshold {
+				continue
+			}
+			if err := stopCrashLoopingRollout(ctx, api, apiPods); err != nil {
+				telemetry.Error(errors.Wrap(err, ctx.App.Name, api.Name))
+				apiCronLog(ctx, api).Error(err)
+			}
+		}
+	}
+}
+
+// stopCrashLoopingRollout pauses the rollout of the API's deployment (or rolls it back to its previous version, if configured),
+// and records the crash loop so that the API is not redeployed until it is updated
+func stopCrashLoopingRollout(ctx *context.Context, api *context.API, apiPods []kcore.Pod) error {
+	deploymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return err
+	}
+	deployment, err := config.Kubernetes.GetDeployment(deploymentName)
+	if err != nil {
+		return err
+	}
+	if deployment == nil || deployment.Labels["resourceID"] != api.ID || deployment.DeletionTimestamp != nil || getCrashLoop(deployment) != nil {
+		return nil
+	}
+
+	// The API's rollout has already completed
+	updatedReplicas, err := numUpdatedReadyReplicas(ctx, api)
+	if err != nil {
+		return err
+	}
+	if updatedReplicas >= api.Compute.MinReplicas {
+		return nil
+	}
+
+	crashLoop := crashLoopingReplica(ctx, api, apiPods)
+	crashLoop.Restarts = int32(len(apiRestarts.restartTimes[api.ID]))
+
+	if api.UpdateStrategy != nil && api.UpdateStrategy.RollbackOnCrashLoop {
+		crashLoop.RolledBack, err = rollbackDeployment(deployment)
+		if err != nil {
+			return err
+		}
+	}
+	if !crashLoop.RolledBack {
+		deployment.Spec.Paused = true
+	}
+
+	crashLoopBytes, err := json.Marshal(crashLoop)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if deployment.Annotations == nil {
+		deployment.Annotations = make(map[string]string)
+	}
+	deployment.Annotations[crashLoopAnnotation] = string(crashLoopBytes)
+
+	if _, err := config.Kubernetes.ApplyDeployment(deployment); err != nil {
+		return err
+	}
+
+	telemetry.Event("operator.api.crash_loop", map[string]interface{}{
+		"restarts":   crashLoop.Restarts,
+		"rolledBack": crashLoop.RolledBack,
+	})
+
+	emitEvent(ctx, api, webhooks.CrashLoopingEvent, fmt.Sprintf("%s is crash looping (%d restarts)", api.Name, crashLoop.Restarts), map[string]interface{}{
+		"restarts":    crashLoop.Restarts,
+		"rolled_back": crashLoop.RolledBack,
+		"replica":     crashLoop.Replica,
+		"container":   crashLoop.Container,
+		"logs":        crashLoop.Logs,
+	})
+
+	return nil
+}
+
+// crashLoopingReplica finds the API's container with the most restarts, and the last lines it logged before it exited
+func crashLoopingReplica(ctx *context.Context, api *context.API, apiPods []kcore.Pod) schema.CrashLoop {
+	crashLoop := schema.CrashLoop{
+		ResourceID: api.ID,
+		Time:       time.Now(),
+	}
+
+	var maxRestarts int32
+	for _, pod := range apiPods {
+		if pod.Labels["appName"] != ctx.App.Name || pod.Labels["resourceID"] != api.ID {
+			continue
+		}
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			if containerStatus.RestartCount > maxRestarts {
+				maxRestarts = containerStatus.RestartCount
+				crashLoop.Replica = pod.Name
+				crashLoop.Container = containerStatus.Name
+			}
+		}
+	}
+
+	if crashLoop.Replica != "" {
+		// the logs of the previous run may not be available, in which case they are omitted
+		logs, err := config.Kubernetes.GetPodContainerLogs(crashLoop.Replica, crashLoop.Container, true, _crashLoopLogLines)
+		if err == nil {
+			if len(logs) > _crashLoopMaxLogsLength {
+				logs = logs[len(logs)-_crashLoopMaxLogsLength:]
+			}
+			crashLoop.Logs = logs
+		}
+	}
+
+	return crashLoop
+}
+
+// rollbackDeployment sets the deployment's pod template to that of its most recent revision which ran a different version of the API
+// (and returns false if there is no such revision)
+func rollbackDeployment(deployment *kapps.Deployment) (bool, error) {
+	replicaSets, err := config.Kubernetes.ListReplicaSetsByLabels(deployment.Spec.Selector.MatchLabels)
+	if err != nil {
+		return false, err
+	}
+
+	currentRevision := k8s.Revision(deployment)
+	var previous *kapps.ReplicaSet
+	for i := range replicaSets {
+		replicaSet := &replicaSets[i]
+		if !k8s.IsOwnedBy(replicaSet, string(deployment.UID)) || replicaSet.Spec.Template.Labels["resourceID"] == deployment.Labels["resourceID"] {
+			continue
+		}
+		revision := k8s.Revision(replicaSet)
+		if revision >= currentRevision {
+			continue
+		}
+		if previous == nil || revision > k8s.Revision(previous) {
+			previous = replicaSet
+		}
+	}
+
+	if previous == nil {
+		return false, nil
+	}
+
+	template := previous.Spec.Template.DeepCopy()
+	delete(template.Labels, k8s.PodTemplateHashKey)
+	deployment.Spec.Template = *template
+	k8s.SetSpecHashAnnotation(deployment, k8s.DeploymentSpecHash(deployment))
+	return true, nil
+}
+
+func getCrashLoop(deployment *kapps.Deployment) *schema.CrashLoop {
+	if deployment == nil {
+		return nil
+	}
+	crashLoopStr, ok := deployment.Annotations[crashLoopAnnotation]
+	if !ok {
+		return nil
+	}
+	var crashLoop schema.CrashLoop
+	if err := json.Unmarshal([]byte(crashLoopStr), &crashLoop); err != nil {
+		return nil
+	}
+	return &crashLoop
+}
+
+// getAPICrashLoop returns the crash loop which stopped the rollout of the API (nil if its rollout wasn't stopped)
+func getAPICrashLoop(ctx *context.Context, api *context.API) (*schema.CrashLoop, error) {
+	deploymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return nil, err
+	}
+	deployment, err := config.Kubernetes.GetDeployment(deploymentName)
+	if err != nil {
+		return nil, err
+	}
+
+	crashLoop := getCrashLoop(deployment)
+	if crashLoop == nil || crashLoop.ResourceID != api.ID {
+		return nil, nil
+	}
+	return crashLoop, nil
+}
+
+func setCrashLoopingAPIStatusCodes(apiStatuses map[string]*resource.APIStatus, deployments map[string]*kapps.Deployment) {
+	for _, deployment := range deployments {
+		crashLoop := getCrashLoop(deployment)
+		if crashLoop == nil {
+			continue
+		}
+		if apiStatus, ok := apiStatuses[crashLoop.ResourceID]; ok {
+			apiStatus.Code = resource.StatusCrashLooping
+		}
+	}
+}
@@ -20,12 +20,12 @@ import (
 	"time"
 
 	kcore "k8s.io/api/core/v1"
-	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
-	"github.com/cortexlabs/cortex/pkg/lib/k8s"
-	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/lib/tracing"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
 )
 
@@ -36,6 +36,12 @@ const (
 
 var _lastTelemetryCron time.Time
 
+// _cronLog includes the ID of the current cron run (runs are sequential, so it's only replaced between runs)
+var (
+	_cronLog      = logging.With(nil)
+	_cronRunCount int64
+)
+
 var cronChannel = make(chan struct{}, 1)
 
 func cronRunner() {
@@ -58,49 +64,164 @@ func runCronNow() {
 }
 
 func runCron() {
+	_cronRunCount++
+	_cronLog = logging.With(logging.Fields{"cron_run_id": _cronRunCount})
+	start := time.Now()
+	defer func() {
+		_cronLog.With(logging.Fields{"duration_ms": time.Since(start).Milliseconds()}).Debug("completed cron run")
+	}()
+
 	defer reportAndRecover("cron failed")
 
+	// Idleness is checked first so that the replica bounds of idle APIs are up to date (e.g. after the operator restarts)
+	if time.Since(_lastIdleCron) >= _idleInterval {
+		_lastIdleCron = time.Now()
+		updateIdleAPIs()
+	}
+
+	applyReplicaSchedules()
+
 	if err := UpdateWorkflows(); err != nil {
 		telemetry.Error(err)
-		errors.PrintError(err)
+		_cronLog.Error(err)
 	}
 
-	apiPods, err := config.Kubernetes.ListPodsByLabels(map[string]string{
+	apiPods, apiPodsErr := config.Kubernetes.ListPodsByLabels(map[string]string{
 		"workloadType": workloadTypeAPI,
 		"userFacing":   "true",
 	})
 
-	if err != nil {
-		telemetry.Error(err)
-		errors.PrintError(err)
+	if apiPodsErr != nil {
+		telemetry.Error(apiPodsErr)
+		_cronLog.Error(apiPodsErr)
 	}
 
 	if err := updateAPISavedStatuses(apiPods); err != nil {
 		telemetry.Error(err)
-		errors.PrintError(err)
+		_cronLog.Error(err)
 	}
 
-	failedPods, err := config.Kubernetes.ListPods(&kmeta.ListOptions{
-		FieldSelector: "status.phase=Failed",
-	})
+	updateCanaries()
+
+	if time.Since(_lastOrphanGCCron) >= _orphanGCInterval {
+		_lastOrphanGCCron = time.Now()
+		if err := retryPendingTeardowns(); err != nil {
+			telemetry.Error(err)
+			_cronLog.Error(err)
+		}
+	}
+
+	if time.Since(_lastDriftCron) >= _driftInterval {
+		_lastDriftCron = time.Now()
+		reconcileAPIDrift()
+	}
+
+	if err := tracing.Flush(); err != nil {
+		_cronLog.Error(err)
+	}
+
+	if err := updateWebhookEvents(); err != nil {
+		telemetry.Error(err)
+		_cronLog.Error(err)
+	}
+
+	if time.Since(_lastErrorRateAlertCron) >= _errorRateAlertInterval {
+		_lastErrorRateAlertCron = time.Now()
+		if err := updateErrorRateAlerts(); err != nil {
+			telemetry.Error(err)
+			_cronLog.Error(err)
+		}
+	}
+
+	if time.Since(_lastDataDriftCron) >= _dataDriftInterval {
+		_lastDataDriftCron = time.Now()
+		updateDataDrifts()
+	}
+
+	if time.Since(_lastFeedbackMetricsCron) >= _feedbackMetricsInterval {
+		_lastFeedbackMetricsCron = time.Now()
+		updateFeedbackMetrics()
+	}
+
+	if time.Since(_lastGrafanaCron) >= _grafanaInterval {
+		_lastGrafanaCron = time.Now()
+		if err := reconcileGrafana(); err != nil {
+			telemetry.Error(err)
+			_cronLog.Error(err)
+		}
+	}
+
+	if time.Since(_lastAlertRulesCron) >= _alertRulesInterval {
+		_lastAlertRulesCron = time.Now()
+		updateAlertRules()
+	}
+
+	if time.Since(_lastLogGroupsCron) >= _logGroupsInterval {
+		_lastLogGroupsCron = time.Now()
+		if err := reconcileLogGroups(); err != nil {
+			telemetry.Error(err)
+			_cronLog.Error(err)
+		}
+	}
+
+	// These track the API pods over time, so they are skipped if the pods couldn't be listed
+	if apiPodsErr == nil {
+		if time.Since(_lastMemoryUsageCron) >= _memoryUsageInterval {
+			_lastMemoryUsageCron = time.Now()
+			if err := updateAPIPeakMemory(apiPods); err != nil {
+				telemetry.Error(err)
+				_cronLog.Error(err)
+			}
+		}
+
+		reportOOMKills(apiPods)
+
+		if time.Since(_lastCostCron) >= _costInterval {
+			_lastCostCron = time.Now()
+			if err := updateCosts(apiPods); err != nil {
+				telemetry.Error(err)
+				_cronLog.Error(err)
+			}
+		}
+
+		updateCrashLoops(apiPods)
+
+		if time.Since(_lastDashboardsCron) >= _dashboardsInterval {
+			_lastDashboardsCron = time.Now()
+			if err := updateDashboards(apiPods); err != nil {
+				telemetry.Error(err)
+				_cronLog.Error(err)
+			}
+		}
+
+		if time.Since(_lastGPUAutoscaleCron) >= _gpuAutoscaleInterval {
+			_lastGPUAutoscaleCron = time.Now()
+			if err := autoscaleGPUAPIs(apiPods); err != nil {
+				telemetry.Error(err)
+				_cronLog.Error(err)
+			}
+		}
+	}
+
+	failedPods, err := config.Kubernetes.ListPodsByPhase(kcore.PodFailed)
 
 	if err != nil {
 		telemetry.Error(err)
-		errors.PrintError(err)
+		_cronLog.Error(err)
 	}
 
-	deleteEvictedPods(failedPods)
+	deleteFailedPods(failedPods)
 
 	if err := updateDataWorkloadErrors(failedPods); err != nil {
 		telemetry.Error(err)
-		errors.PrintError(err)
+		_cronLog.Error(err)
 	}
 
 	if time.Since(_lastTelemetryCron) >= _telemetryInterval {
 		_lastTelemetryCron = time.Now()
 		if err := telemetryCron(); err != nil {
 			telemetry.Error(err)
-			errors.PrintError(err)
+			_cronLog.Error(err)
 		}
 	}
 }
@@ -129,12 +250,16 @@ func telemetryCron() error {
 	}
 
 	properties := map[string]interface{}{
-		"instanceTypes": instanceTypeCounts,
-		"instanceCount": totalInstances,
+		"instanceTypes":           instanceTypeCounts,
+		"instanceCount":           totalInstances,
+		"failedPodsDeleted":       failedPodDeletion.deleted,
+		"failedPodDeletionErrors": failedPodDeletion.errors,
 	}
 
 	telemetry.Event("operator.cron", properties)
 
+	resetFailedPodDeletionCounts()
+
 	return nil
 }
 
@@ -142,38 +267,13 @@ func reportAndRecover(strs ...string) error {
 	if errInterface := recover(); errInterface != nil {
 		err := errors.CastRecoverError(errInterface, strs...)
 		telemetry.Error(err)
-		errors.PrintError(err)
+		_cronLog.Error(err)
 		return err
 	}
 	return nil
 }
 
-func deleteEvictedPods(failedPods []kcore.Pod) {
-	evictedPods := []kcore.Pod{}
-	for _, pod := range failedPods {
-		if pod.Status.Reason == k8s.ReasonEvicted {
-			evictedPods = append(evictedPods, pod)
-		}
-	}
-
-	if len(evictedPods) > 0 {
-		savedEvictedPods := map[string]kcore.Pod{}
-		currentWorkloadIDs := strset.New()
-		for _, ctx := range CurrentContexts() {
-			currentWorkloadIDs.Merge(ctx.ComputedResourceWorkloadIDs())
-		}
-
-		for _, pod := range evictedPods {
-			if currentWorkloadIDs.Has(pod.Labels["workloadID"]) {
-				if _, ok := savedEvictedPods[pod.Labels["resourceID"]]; !ok {
-					savedEvictedPods[pod.Labels["resourceID"]] = pod
-					continue
-				}
-			}
-			_, err := config.Kubernetes.DeletePod(pod.Name)
-			if err != nil {
-				errors.PrintError(err)
-			}
-		}
-	}
+// apiCronLog returns the current cron run's logger for the API
+func apiCronLog(ctx *context.Context, api *context.API) *logging.Logger {
+	return _cronLog.With(logging.Fields{"app": ctx.App.Name, "api": api.Name})
 }
@@ -17,11 +17,11 @@ limitations under the License.
 package workloads
 
 import (
-	"fmt"
 	"sync"
 
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	"github.com/cortexlabs/cortex/pkg/operator/api/context"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
 	ocontext "github.com/cortexlabs/cortex/pkg/operator/context"
@@ -65,6 +65,33 @@ func setCurrentContext(ctx *context.Context) error {
 	return nil
 }
 
+// setCurrentContextAPI replaces one of the APIs in the app's current context (e.g. to record its owner), and returns the updated context
+func setCurrentContextAPI(ctx *context.Context, updatedAPI *context.API) (*context.Context, error) {
+	updatedCtx := contextWithAPI(ctx, updatedAPI)
+
+	if err := config.AWS.UploadMsgpackToS3(updatedCtx, updatedCtx.Key); err != nil {
+		return nil, err
+	}
+
+	if err := setCurrentContext(updatedCtx); err != nil {
+		return nil, err
+	}
+
+	return updatedCtx, nil
+}
+
+// contextWithAPI returns a copy of the context in which one of its APIs is replaced
+// (the context is copied rather than modified, since it may be in use by other requests)
+func contextWithAPI(ctx *context.Context, updatedAPI *context.API) *context.Context {
+	updatedCtx := *ctx
+	updatedCtx.APIs = make(context.APIs, len(ctx.APIs))
+	for name, api := range ctx.APIs {
+		updatedCtx.APIs[name] = api
+	}
+	updatedCtx.APIs[updatedAPI.Name] = updatedAPI
+	return &updatedCtx
+}
+
 func deleteCurrentContext(appName string) error {
 	currentCtxs.Lock()
 	defer currentCtxs.Unlock()
@@ -111,7 +138,7 @@ func reloadCurrentContexts() error {
 	for appName, ctxID := range configMap.Data {
 		ctx, err := ocontext.DownloadContext(ctxID, appName)
 		if err != nil {
-			fmt.Printf("Deleting stale workflow: %s\n", appName)
+			logging.With(logging.Fields{"app": appName}).Warn("deleting stale workflow")
 			DeleteApp(appName, true)
 		} else if ctx != nil {
 			currentCtxs.m[appName] = ctx
@@ -0,0 +1,409 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+
+	awslib "github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	_dashboardsInterval          = 1 * time.Minute
+	_dashboardPeriod             = 60 // seconds
+	_dashboardWidgetWidth        = 12 // dashboards are 24 units wide, so widgets are laid out in two columns
+	_dashboardWidgetHeight       = 6
+	_putMetricDataMaxBatchLength = 20
+)
+
+var _lastDashboardsCron time.Time
+
+// The body of each API dashboard as of when it was last put (dashboard name -> body)
+var _dashboardBodies = struct {
+	sync.Mutex
+	m map[string]string
+}{m: make(map[string]string)}
+
+type dashboard struct {
+	Widgets []dashboardWidget `json:"widgets"`
+}
+
+type dashboardWidget struct {
+	Type       string                    `json:"type"`
+	X          int                       `json:"x"`
+	Y          int                       `json:"y"`
+	Width      int                       `json:"width"`
+	Height     int                       `json:"height"`
+	Properties dashboardWidgetProperties `json:"properties"`
+}
+
+type dashboardWidgetProperties struct {
+	Title   string          `json:"title"`
+	Region  string          `json:"region"`
+	View    string          `json:"view"`
+	Period  int             `json:"period"`
+	Metrics [][]interface{} `json:"metrics"` // each metric is [namespace, metric name, dimension name, dimension value, ..., rendering properties]
+}
+
+// dashboardNamePrefix is the prefix of the names of the cluster's dashboards
+func dashboardNamePrefix() string {
+	return config.Cluster.ClusterName + "_"
+}
+
+// isAPIDashboardName returns whether the dashboard is one of the cluster's API dashboards (deployment and API names can't contain underscores,
+// whereas cluster names can, so this excludes the dashboards of other clusters whose names start with this cluster's name)
+func isAPIDashboardName(name string) bool {
+	return strings.HasPrefix(name, dashboardNamePrefix()) && strings.Count(strings.TrimPrefix(name, dashboardNamePrefix()), "_") == 1
+}
+
+func apiDashboardName(appName string, apiName string) string {
+	return dashboardNamePrefix() + appName + "_" + apiName
+}
+
+// getAPIDashboardURL returns the URL of the API's CloudWatch dashboard, or "" if it hasn't been created
+func getAPIDashboardURL(ctx *context.Context, api *context.API) string {
+	name := apiDashboardName(ctx.App.Name, api.Name)
+
+	_dashboardBodies.Lock()
+	defer _dashboardBodies.Unlock()
+	if _, ok := _dashboardBodies.m[name]; !ok {
+		return ""
+	}
+	return awslib.DashboardURL(config.AWS.Region, name)
+}
+
+// updateDashboards publishes the APIs' replica and utilization metrics, and reconciles the APIs' dashboards
+func updateDashboards(apiPods []kcore.Pod) error {
+	if config.Cluster.APIDashboards {
+		if err := publishAPIReplicaMetrics(apiPods); err != nil {
+			return err
+		}
+	}
+	return reconcileDashboards()
+}
+
+// reconcileDashboards puts each API's dashboard if it has changed, and deletes the dashboards of APIs which have been deleted
+func reconcileDashboards() error {
+	dashboardBodies := make(map[string]string)
+	if config.Cluster.APIDashboards {
+		for _, ctx := range CurrentContexts() {
+			for _, api := range ctx.APIs {
+				body, err := apiDashboardBody(ctx, api)
+				if err != nil {
+					return err
+				}
+				dashboardBodies[apiDashboardName(ctx.App.Name, api.Name)] = body
+			}
+		}
+	}
+
+	_dashboardBodies.Lock()
+	defer _dashboardBodies.Unlock()
+
+	var putErr error
+	for name, body := range dashboardBodies {
+		if _dashboardBodies.m[name] == body {
+			continue
+		}
+		if err := config.AWS.PutDashboard(name, body); err != nil {
+			putErr = err
+			continue
+		}
+		_dashboardBodies.m[name] = body
+	}
+
+	existingNames, err := config.AWS.ListDashboards(dashboardNamePrefix())
+	if err != nil {
+		return err
+	}
+	var staleNames []string
+	for _, name := range existingNames {
+		if _, ok := dashboardBodies[name]; !ok && isAPIDashboardName(name) {
+			staleNames = append(staleNames, name)
+		}
+	}
+	if err := config.AWS.DeleteDashboards(staleNames); err != nil {
+		return err
+	}
+	for name := range _dashboardBodies.m {
+		if _, ok := dashboardBodies[name]; !ok {
+			delete(_dashboardBodies.m, name)
+		}
+	}
+
+	return putErr
+}
+
+func apiDashboardBody(ctx *context.Context, api *context.API) (string, error) {
+	widgets := []dashboardWidgetProperties{
+		{
+			Title: "requests",
+			Metrics: [][]interface{}{
+				dashboardMetric("Latency", getAPIDimensionsHistogram(ctx.App.Name, api), "SampleCount", "requests"),
+				dashboardMetric("StatusCode", statusCodeDimensions(ctx.App.Name, api, "2XX"), "Sum", "2XX"),
+				dashboardMetric("StatusCode", statusCodeDimensions(ctx.App.Name, api, "4XX"), "Sum", "4XX"),
+				dashboardMetric("StatusCode", statusCodeDimensions(ctx.App.Name, api, "5XX"), "Sum", "5XX"),
+			},
+		},
+		{
+			Title: "latency (ms)",
+			Metrics: [][]interface{}{
+				dashboardMetric("Latency", getAPIDimensionsHistogram(ctx.App.Name, api), "Average", "average"),
+				dashboardMetric("Latency", getAPIDimensionsHistogram(ctx.App.Name, api), "p99", "p99"),
+			},
+		},
+		{
+			Title: "replicas",
+			Metrics: [][]interface{}{
+				dashboardMetric("RequestedReplicas", getAPIDimensions(ctx.App.Name, api), "Maximum", "requested"),
+				dashboardMetric("ReadyReplicas", getAPIDimensions(ctx.App.Name, api), "Minimum", "ready"),
+			},
+		},
+	}
+
+	utilizationWidget := dashboardWidgetProperties{
+		Title: "utilization (%)",
+		Metrics: [][]interface{}{
+			dashboardMetric("CPUUtilization", getAPIDimensions(ctx.App.Name, api), "Average", "cpu"),
+		},
+	}
+	if api.Compute.GPU > 0 {
+		utilizationWidget.Metrics = append(utilizationWidget.Metrics, dashboardMetric("GPUUtilization", getAPIDimensions(ctx.App.Name, api), "Average", "gpu"))
+	}
+	widgets = append(widgets, utilizationWidget)
+
+	if api.Tracker != nil {
+		predictionsWidget := dashboardWidgetProperties{Title: "predictions"}
+		if api.Tracker.ModelType == userconfig.ClassificationModelType {
+			predictionsWidget.Metrics = [][]interface{}{{classesSearchExpression(ctx.App.Name, api)}}
+		} else {
+			for _, stat := range []string{"Average", "Minimum", "Maximum"} {
+				predictionsWidget.Metrics = append(predictionsWidget.Metrics, dashboardMetric("Prediction", getAPIDimensionsHistogram(ctx.App.Name, api), stat, stat))
+			}
+		}
+		widgets = append(widgets, predictionsWidget)
+	}
+
+	if api.Feedback != nil && len(api.Feedback.Metrics) > 0 {
+		feedbackWidget := dashboardWidgetProperties{Title: "feedback metrics"}
+		for _, metric := range api.Feedback.Metrics {
+			feedbackWidget.Metrics = append(feedbackWidget.Metrics, dashboardMetric(_feedbackMetricNames[metric], getAPIDimensions(ctx.App.Name, api), "Average", metric))
+		}
+		widgets = append(widgets, feedbackWidget)
+	}
+
+	var body dashboard
+	for i, properties := range widgets {
+		properties.Region = config.AWS.Region
+		properties.View = "timeSeries"
+		properties.Period = _dashboardPeriod
+		body.Widgets = append(body.Widgets, dashboardWidget{
+			Type:       "metric",
+			X:          (i % 2) * _dashboardWidgetWidth,
+			Y:          (i / 2) * _dashboardWidgetHeight,
+			Width:      _dashboardWidgetWidth,
+			Height:     _dashboardWidgetHeight,
+			Properties: properties,
+		})
+	}
+
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	return string(bodyBytes), nil
+}
+
+func dashboardMetric(metricName string, dimensions []*cloudwatch.Dimension, stat string, label string) []interface{} {
+	metric := []interface{}{config.Cluster.LogGroup, metricName}
+	for _, dimension := range dimensions {
+		metric = append(metric, *dimension.Name, *dimension.Value)
+	}
+	return append(metric, map[string]interface{}{"stat": stat, "label": label})
+}
+
+func statusCodeDimensions(appName string, api *context.API, code string) []*cloudwatch.Dimension {
+	return append(getAPIDimensionsCounter(appName, api), &cloudwatch.Dimension{
+		Name:  aws.String("Code"),
+		Value: aws.String(code),
+	})
+}
+
+// classesSearchExpression graphs the count of each predicted class (whose metrics have a Class dimension, in addition to the API's counter dimensions)
+func classesSearchExpression(appName string, api *context.API) map[string]interface{} {
+	schema := fmt.Sprintf("{\"%s\",AppName,APIName,APIID,metric_type,Class}", config.Cluster.LogGroup)
+	query := fmt.Sprintf("MetricName=\"Prediction\" AppName=\"%s\" APIName=\"%s\" APIID=\"%s\" metric_type=\"counter\"", appName, api.Name, api.ID)
+	return map[string]interface{}{
+		"expression": fmt.Sprintf("SEARCH('%s %s', 'Sum', %d)", schema, query, _dashboardPeriod),
+		"id":         "classes",
+		"label":      "",
+	}
+}
+
+// publishAPIReplicaMetrics publishes the metrics which the APIs' replicas don't publish themselves:
+// the requested and ready replica counts of each API, and its ready replicas' average CPU and GPU utilization
+func publishAPIReplicaMetrics(apiPods []kcore.Pod) error {
+	deployments, err := config.Kubernetes.ListDeploymentsByLabels(map[string]string{
+		"workloadType": workloadTypeAPI,
+	})
+	if err != nil {
+		return err
+	}
+	requestedReplicas := make(map[string]int32) // resource ID -> replicas
+	for _, deployment := range deployments {
+		if deployment.Spec.Replicas != nil && deployment.DeletionTimestamp == nil {
+			requestedReplicas[deployment.Labels["resourceID"]] += *deployment.Spec.Replicas
+		}
+	}
+
+	readyPods := make(map[string][]*kcore.Pod) // resource ID -> pods
+	for i := range apiPods {
+		if k8s.IsPodReady(&apiPods[i]) {
+			resourceID := apiPods[i].Labels["resourceID"]
+			readyPods[resourceID] = append(readyPods[resourceID], &apiPods[i])
+		}
+	}
+
+	// utilization metrics are skipped if they're unavailable (e.g. if metrics-server isn't running)
+	cpuUsage, err := config.Kubernetes.ListPodsCPUUsage(map[string]string{
+		"workloadType": workloadTypeAPI,
+		"userFacing":   "true",
+	})
+	if err != nil {
+		_cronLog.Error(err, "api cpu usage")
+	}
+
+	var gpuUtilization map[string]float64
+	if hasGPUAPIs() {
+		gpuUtilization, err = config.Kubernetes.ListPodsGPUUtilization(map[string]string{
+			"app": _dcgmExporterAppLabelValue,
+		})
+		if err != nil {
+			_cronLog.Error(err, "api gpu utilization")
+		}
+	}
+
+	now := time.Now()
+	var metricData []*cloudwatch.MetricDatum
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			addMetric := func(metricName string, value float64, unit string) {
+				metricData = append(metricData, &cloudwatch.MetricDatum{
+					MetricName: aws.String(metricName),
+					Dimensions: getAPIDimensions(ctx.App.Name, api),
+					Value:      aws.Float64(value),
+					Unit:       aws.String(unit),
+					Timestamp:  aws.Time(now),
+				})
+			}
+
+			addMetric("RequestedReplicas", float64(requestedReplicas[api.ID]), cloudwatch.StandardUnitCount)
+			addMetric("ReadyReplicas", float64(len(readyPods[api.ID])), cloudwatch.StandardUnitCount)
+			if utilization, ok := podsCPUUtilization(readyPods[api.ID], cpuUsage); ok {
+				addMetric("CPUUtilization", utilization, cloudwatch.StandardUnitPercent)
+			}
+			if api.Compute.GPU > 0 {
+				if utilization, ok := podsGPUUtilization(readyPods[api.ID], gpuUtilization); ok {
+					addMetric("GPUUtilization", utilization, cloudwatch.StandardUnitPercent)
+				}
+			}
+		}
+	}
+
+	for start := 0; start < len(metricData); start += _putMetricDataMaxBatchLength {
+		end := start + _putMetricDataMaxBatchLength
+		if end > len(metricData) {
+			end = len(metricData)
+		}
+		_, err := config.AWS.CloudWatchMetrics.PutMetricData(&cloudwatch.PutMetricDataInput{
+			Namespace:  aws.String(config.Cluster.LogGroup),
+			MetricData: metricData[start:end],
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+func hasGPUAPIs() bool {
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			if api.Compute.GPU > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// podsCPUUtilization returns the pods' total CPU usage as a percentage of their total CPU requests (which is how the horizontal pod autoscaler measures utilization)
+func podsCPUUtilization(pods []*kcore.Pod, cpuUsage map[string]map[string]kresource.Quantity) (float64, bool) {
+	var usageMillis, requestMillis int64
+	for _, pod := range pods {
+		containersUsage, ok := cpuUsage[pod.Name]
+		if !ok {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			request, hasRequest := container.Resources.Requests[kcore.ResourceCPU]
+			usage, hasUsage := containersUsage[container.Name]
+			if !hasRequest || !hasUsage {
+				continue
+			}
+			usageMillis += usage.MilliValue()
+			requestMillis += request.MilliValue()
+		}
+	}
+
+	if requestMillis == 0 {
+		return 0, false
+	}
+	return float64(usageMillis) / float64(requestMillis) * 100, true
+}
+
+// podsGPUUtilization returns the pods' average GPU utilization (as a percentage)
+func podsGPUUtilization(pods []*kcore.Pod, gpuUtilization map[string]float64) (float64, bool) {
+	var utilizationSum float64
+	var count int
+	for _, pod := range pods {
+		if utilization, ok := gpuUtilization[pod.Name]; ok {
+			utilizationSum += utilization
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, false
+	}
+	return utilizationSum / float64(count), true
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/distribution"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/webhooks"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	_dataDriftInterval = 5 * time.Minute
+	_maxSampleObjects  = 1000 // the maximum number of sample objects (of data drift or feedback) which are listed at a time
+)
+
+var _lastDataDriftCron time.Time
+
+// The most recent data drift comparison of each API which tracks data drift (resource ID)
+var _apiDataDrifts = make(map[string]*schema.DataDrift)
+var _apiDataDriftsMutex = &sync.Mutex{}
+
+// dataDriftSample is written periodically by each of the API's replicas (under <metadata root>/<api ID>/drift/samples/<unix time>-<suffix>.json);
+// the baseline has the same format
+type dataDriftSample struct {
+	Start      int64                                 `json:"start"` // unix time
+	End        int64                                 `json:"end"`   // unix time
+	Prediction *distribution.Distribution            `json:"prediction"`
+	Features   map[string]*distribution.Distribution `json:"features"`
+}
+
+func getAPIDataDrift(api *context.API) *schema.DataDrift {
+	_apiDataDriftsMutex.Lock()
+	defer _apiDataDriftsMutex.Unlock()
+	return _apiDataDrifts[api.ID]
+}
+
+// updateDataDrifts compares each tracked API's recent predictions with its baseline, and emits an event when an API starts drifting
+func updateDataDrifts() {
+	now := time.Now()
+	currentResourceIDs := strset.New()
+
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			if api.Tracker == nil || api.Tracker.DataDrift == nil {
+				continue
+			}
+			currentResourceIDs.Add(api.ID)
+
+			dataDrift, err := compareDataDrift(ctx, api, now)
+			if err != nil {
+				apiCronLog(ctx, api).Error(err, "data drift")
+				continue
+			}
+
+			previous := getAPIDataDrift(api)
+			_apiDataDriftsMutex.Lock()
+			_apiDataDrifts[api.ID] = dataDrift
+			_apiDataDriftsMutex.Unlock()
+
+			if dataDrift.Status != schema.DataDriftStatusDrifted || (previous != nil && previous.Status == schema.DataDriftStatusDrifted) {
+				continue
+			}
+
+			var driftedFields []string
+			psis := map[string]float64{}
+			for _, field := range dataDrift.Fields {
+				if field.Drifted {
+					driftedFields = append(driftedFields, field.Field)
+					psis[field.Field] = field.PSI
+				}
+			}
+
+			apiCronLog(ctx, api).With(logging.Fields{"fields": driftedFields}).Warn("detected data drift")
+			emitEvent(ctx, api, webhooks.DataDriftEvent, fmt.Sprintf("%s's %s drifted from the baseline over the last %s", api.Name, s.StrsAnd(driftedFields), api.Tracker.DataDrift.Window.String()), map[string]interface{}{
+				"psi":            psis,
+				"threshold":      dataDrift.Threshold,
+				"baseline_count": dataDrift.BaselineCount,
+				"window_count":   dataDrift.WindowCount,
+			})
+		}
+	}
+
+	_apiDataDriftsMutex.Lock()
+	for resourceID := range _apiDataDrifts {
+		if !currentResourceIDs.Has(resourceID) {
+			delete(_apiDataDrifts, resourceID)
+		}
+	}
+	_apiDataDriftsMutex.Unlock()
+}
+
+// compareDataDrift compares the samples which were written during the window with the API's baseline (which is captured first if necessary),
+// and deletes the samples which are no longer needed
+func compareDataDrift(ctx *context.Context, api *context.API, now time.Time) (*schema.DataDrift, error) {
+	driftConfig := api.Tracker.DataDrift
+	dataDrift := &schema.DataDrift{
+		Threshold: driftConfig.Threshold,
+		Time:      now,
+	}
+
+	prefix := filepath.Join(ctx.MetadataRoot, api.ID, "drift")
+	samplesPrefix := filepath.Join(prefix, "samples") + "/"
+	baselineKey := filepath.Join(prefix, "baseline.json")
+
+	baseline, err := getDataDriftBaseline(baselineKey)
+	if err != nil {
+		return nil, err
+	}
+	if baseline == nil {
+		baseline, err = captureDataDriftBaseline(ctx, api, samplesPrefix, now)
+		if err != nil {
+			return nil, err
+		}
+		if baseline == nil {
+			dataDrift.Status = schema.DataDriftStatusCollectingBaseline
+			return dataDrift, nil
+		}
+		if err := config.AWS.UploadJSONToS3(baseline, baselineKey); err != nil {
+			return nil, err
+		}
+	}
+	dataDrift.BaselineCount = baseline.Prediction.Count()
+
+	// the window doesn't overlap the baseline
+	windowStart := now.Add(-driftConfig.Window).Unix()
+	if windowStart < baseline.End {
+		windowStart = baseline.End
+	}
+
+	if err := deleteSamples(samplesPrefix, windowStart); err != nil {
+		return nil, err
+	}
+
+	// keys start with the unix time at which they were written, so only the keys in the window are listed
+	objects, err := config.AWS.ListPrefixAfter(samplesPrefix, samplesPrefix+strconv.FormatInt(windowStart, 10), _maxSampleObjects)
+	if err != nil {
+		return nil, err
+	}
+	var samples []*dataDriftSample
+	for _, object := range objects {
+		if timestamp, ok := sampleObjectTime(object); !ok || timestamp <= windowStart {
+			continue
+		}
+		sample, err := readDataDriftSample(*object.Key)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+
+	window := mergeDataDriftSamples(samples)
+	dataDrift.WindowCount = window.Prediction.Count()
+	if dataDrift.WindowCount < driftConfig.MinSamples {
+		dataDrift.Status = schema.DataDriftStatusInsufficientSamples
+		return dataDrift, nil
+	}
+
+	dataDrift.Status = schema.DataDriftStatusOK
+	addField := func(field string, baselineDist *distribution.Distribution, windowDist *distribution.Distribution) {
+		psi := distribution.PSI(baselineDist, windowDist)
+		drifted := psi > driftConfig.Threshold
+		if drifted {
+			dataDrift.Status = schema.DataDriftStatusDrifted
+		}
+		dataDrift.Fields = append(dataDrift.Fields, schema.FieldDrift{Field: field, PSI: psi, Drifted: drifted})
+	}
+
+	addField("prediction", baseline.Prediction, window.Prediction)
+	for _, feature := range driftConfig.Features {
+		if baseline.Features[feature].Count() == 0 {
+			continue // the feature wasn't present in the baseline's requests
+		}
+		addField(feature, baseline.Features[feature], window.Features[feature])
+	}
+
+	return dataDrift, nil
+}
+
+func getDataDriftBaseline(key string) (*dataDriftSample, error) {
+	var baseline dataDriftSample
+	if err := config.AWS.ReadJSONFromS3(&baseline, key); err != nil {
+		if aws.IsNoSuchKeyErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &baseline, nil
+}
+
+// captureDataDriftBaseline merges the samples which were written during the baseline window after the API was deployed
+// (samples from after the baseline window are included until there are min_samples predictions);
+// nil is returned if the baseline window hasn't elapsed or there aren't enough predictions yet
+func captureDataDriftBaseline(ctx *context.Context, api *context.API, samplesPrefix string, now time.Time) (*dataDriftSample, error) {
+	driftConfig := api.Tracker.DataDrift
+
+	apiSavedStatus, err := getAPISavedStatus(api.ID, api.WorkloadID, ctx.App.Name)
+	if err != nil {
+		return nil, err
+	}
+	if apiSavedStatus == nil || apiSavedStatus.Start == nil {
+		return nil, nil
+	}
+	baselineEnd := apiSavedStatus.Start.Add(driftConfig.BaselineWindow)
+	if now.Before(baselineEnd) {
+		return nil, nil
+	}
+
+	// objects are listed in lexicographic order, so the oldest samples are first
+	objects, err := config.AWS.ListPrefix(samplesPrefix, _maxSampleObjects)
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []*dataDriftSample
+	var count int64
+	for _, object := range objects {
+		timestamp, ok := sampleObjectTime(object)
+		if !ok {
+			continue
+		}
+		if timestamp > baselineEnd.Unix() && count >= driftConfig.MinSamples {
+			break
+		}
+		sample, err := readDataDriftSample(*object.Key)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+		count += sample.Prediction.Count()
+	}
+
+	if count < driftConfig.MinSamples {
+		return nil, nil
+	}
+	return mergeDataDriftSamples(samples), nil
+}
+
+func readDataDriftSample(key string) (*dataDriftSample, error) {
+	var sample dataDriftSample
+	if err := config.AWS.ReadJSONFromS3(&sample, key); err != nil {
+		return nil, err
+	}
+	return &sample, nil
+}
+
+// sampleObjectTime parses the unix time at the start of a sample's file name (samples are written by the API's replicas as <unix time>-<suffix>.json)
+func sampleObjectTime(object *s3.Object) (int64, bool) {
+	if object.Key == nil {
+		return 0, false
+	}
+	timestampStr := strings.SplitN(filepath.Base(*object.Key), "-", 2)[0]
+	timestamp, err := strconv.ParseInt(timestampStr, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return timestamp, true
+}
+
+func mergeDataDriftSamples(samples []*dataDriftSample) *dataDriftSample {
+	merged := &dataDriftSample{Features: map[string]*distribution.Distribution{}}
+
+	predictions := make([]*distribution.Distribution, len(samples))
+	features := map[string][]*distribution.Distribution{}
+	for i, sample := range samples {
+		if merged.Start == 0 || sample.Start < merged.Start {
+			merged.Start = sample.Start
+		}
+		if sample.End > merged.End {
+			merged.End = sample.End
+		}
+		predictions[i] = sample.Prediction
+		for feature, dist := range sample.Features {
+			features[feature] = append(features[feature], dist)
+		}
+	}
+
+	merged.Prediction = distribution.Merge(predictions...)
+	for feature, dists := range features {
+		merged.Features[feature] = distribution.Merge(dists...)
+	}
+	return merged
+}
+
+// deleteSamples deletes the samples which were written at or before the cutoff (unix time)
+func deleteSamples(samplesPrefix string, cutoff int64) error {
+	objects, err := config.AWS.ListPrefix(samplesPrefix, _maxSampleObjects)
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	for _, object := range objects {
+		if timestamp, ok := sampleObjectTime(object); ok && timestamp <= cutoff {
+			keys = append(keys, *object.Key)
+		}
+	}
+	return config.AWS.DeleteS3Keys(keys...)
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gorilla/websocket"
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const _deployProgressPollPeriod = 2 * time.Second
+
+type deployProgress struct {
+	stage   schema.DeployStage
+	message string
+}
+
+// StreamDeployProgress sends an event over the socket each time one of the deployment's APIs (or one of their replicas) reaches a new stage,
+// and closes the socket once every API is ready, paused, or has failed (or the deployment is updated again)
+func StreamDeployProgress(appName string, socket *websocket.Conn) {
+	cancel := make(chan struct{})
+	go func() {
+		pumpStdin(socket)
+		close(cancel)
+	}()
+
+	ctx := CurrentContext(appName)
+	if ctx == nil {
+		writeDeployProgressEvent(socket, &schema.DeployProgressEvent{Stage: schema.DeployStageDone, Message: "deployment " + appName + " not found", Failed: true})
+		closeSocket(socket)
+		return
+	}
+	ctxID := ctx.ID
+
+	sent := make(map[string]deployProgress) // api name (and replica name) -> the progress which was last sent
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-cancel:
+			return
+		case <-timer.C:
+			ctx = CurrentContext(appName)
+			if ctx == nil || ctx.ID != ctxID {
+				writeDeployProgressEvent(socket, &schema.DeployProgressEvent{Stage: schema.DeployStageDone, Message: "the deployment was updated or deleted by another request", Failed: true})
+				closeSocket(socket)
+				return
+			}
+
+			apiProgress, replicaProgress, err := getDeployProgress(ctx)
+			if err != nil {
+				writeDeployProgressEvent(socket, &schema.DeployProgressEvent{Stage: schema.DeployStageDone, Message: err.Error(), Failed: true})
+				closeSocket(socket)
+				return
+			}
+
+			done := true
+			failed := false
+			apiNames := make([]string, 0, len(ctx.APIs))
+			for apiName := range ctx.APIs {
+				apiNames = append(apiNames, apiName)
+			}
+			sort.Strings(apiNames)
+
+			for _, apiName := range apiNames {
+				for _, replica := range sortedKeys(replicaProgress[apiName]) {
+					progress := replicaProgress[apiName][replica]
+					if sent[apiName+"/"+replica] != progress {
+						sent[apiName+"/"+replica] = progress
+						writeDeployProgressEvent(socket, &schema.DeployProgressEvent{APIName: apiName, Replica: replica, Stage: progress.stage, Message: progress.message})
+					}
+				}
+
+				progress := apiProgress[apiName]
+				if sent[apiName] != progress {
+					sent[apiName] = progress
+					writeDeployProgressEvent(socket, &schema.DeployProgressEvent{APIName: apiName, Stage: progress.stage, Message: progress.message})
+				}
+
+				switch progress.stage {
+				case schema.DeployStageReady, schema.DeployStagePaused:
+				case schema.DeployStageFailed:
+					failed = true
+				default:
+					done = false
+				}
+			}
+
+			if done {
+				message := "all apis are ready"
+				if failed {
+					message = "some apis failed to deploy"
+				}
+				writeDeployProgressEvent(socket, &schema.DeployProgressEvent{Stage: schema.DeployStageDone, Message: message, Failed: failed})
+				closeSocket(socket)
+				return
+			}
+
+			timer.Reset(_deployProgressPollPeriod)
+		}
+	}
+}
+
+// getDeployProgress returns the progress of each of the context's APIs (api name -> progress),
+// and of each of their up-to-date replicas (api name -> replica name -> progress)
+func getDeployProgress(ctx *context.Context) (map[string]deployProgress, map[string]map[string]deployProgress, error) {
+	dataStatuses, err := GetCurrentDataStatuses(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	apiStatuses, _, err := GetCurrentAPIAndGroupStatuses(dataStatuses, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pods, err := config.Kubernetes.ListPodsByLabels(map[string]string{
+		"workloadType": workloadTypeAPI,
+		"appName":      ctx.App.Name,
+		"userFacing":   "true",
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	replicaProgress := make(map[string]map[string]deployProgress)
+	for i := range pods {
+		pod := &pods[i]
+		api := ctx.APIs[pod.Labels["apiName"]]
+		if api == nil || pod.Labels["resourceID"] != api.ID || APIPodComputeID(pod.Spec.Containers) != api.Compute.IDWithoutReplicas() {
+			continue // the replica is being replaced
+		}
+		if replicaProgress[api.Name] == nil {
+			replicaProgress[api.Name] = make(map[string]deployProgress)
+		}
+		replicaProgress[api.Name][pod.Name] = podDeployProgress(pod)
+	}
+
+	apiProgress := make(map[string]deployProgress, len(ctx.APIs))
+	for _, api := range ctx.APIs {
+		apiStatus := apiStatuses[api.ID]
+		if apiStatus == nil {
+			apiProgress[api.Name] = deployProgress{stage: schema.DeployStageUpdating, message: resource.StatusPending.Message()}
+			continue
+		}
+
+		switch {
+		case apiStatus.Code == resource.StatusLive:
+			apiProgress[api.Name] = deployProgress{stage: schema.DeployStageReady, message: fmt.Sprintf("%d/%d replicas ready", apiStatus.ReadyUpdatedCompute, apiStatus.K8sRequested)}
+		case apiStatus.Code == resource.StatusPaused:
+			apiProgress[api.Name] = deployProgress{stage: schema.DeployStagePaused, message: apiStatus.Code.Message()}
+		case isFailedRolloutStatus(apiStatus.Code):
+			apiProgress[api.Name] = deployProgress{stage: schema.DeployStageFailed, message: apiStatus.Code.Message()}
+		default:
+			apiProgress[api.Name] = deployProgress{stage: schema.DeployStageUpdating, message: fmt.Sprintf("%s (%d/%d replicas ready)", apiStatus.Code.Message(), apiStatus.ReadyUpdatedCompute, apiStatus.K8sRequested)}
+		}
+	}
+
+	return apiProgress, replicaProgress, nil
+}
+
+func podDeployProgress(pod *kcore.Pod) deployProgress {
+	podStatus := k8s.GetPodStatus(pod)
+
+	switch podStatus {
+	case k8s.PodStatusFailed, k8s.PodStatusKilled, k8s.PodStatusKilledOOM:
+		return deployProgress{stage: schema.DeployStageFailed, message: string(podStatus)}
+	case k8s.PodStatusInitializing:
+		return deployProgress{stage: schema.DeployStageStarting, message: "initializing"}
+	case k8s.PodStatusRunning:
+		if k8s.IsPodReady(pod) {
+			return deployProgress{stage: schema.DeployStageReady, message: "ready"}
+		}
+		return deployProgress{stage: schema.DeployStageStarting, message: "waiting for the api to become ready"}
+	case k8s.PodStatusPending:
+		if pod.Spec.NodeName == "" {
+			return deployProgress{stage: schema.DeployStageWaitingForCompute, message: "waiting for a node with enough compute"}
+		}
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			if containerStatus.State.Waiting != nil && containerStatus.ImageID == "" {
+				return deployProgress{stage: schema.DeployStagePullingImage, message: "pulling " + containerStatus.Image}
+			}
+		}
+		return deployProgress{stage: schema.DeployStageStarting, message: "creating containers"}
+	}
+
+	return deployProgress{stage: schema.DeployStageStarting, message: string(podStatus)}
+}
+
+// isFailedRolloutStatus returns whether an API with the status won't become live without being redeployed
+func isFailedRolloutStatus(code resource.StatusCode) bool {
+	switch code {
+	case resource.StatusError, resource.StatusKilled, resource.StatusKilledOOM, resource.StatusCrashLooping, resource.StatusStalled:
+		return true
+	}
+	return false
+}
+
+func writeDeployProgressEvent(socket *websocket.Conn, event *schema.DeployProgressEvent) {
+	event.Time = time.Now()
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		return // unexpected
+	}
+	socket.SetWriteDeadline(time.Now().Add(socketWriteDeadlineWait))
+	socket.WriteMessage(websocket.TextMessage, eventBytes)
+}
+
+func sortedKeys(progress map[string]deployProgress) []string {
+	keys := make([]string, 0, len(progress))
+	for key := range progress {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
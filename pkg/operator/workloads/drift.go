@@ -0,0 +1,146 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"sync"
+	"time"
+
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const _driftInterval = 1 * time.Minute
+
+var _lastDriftCron time.Time
+
+// The most recently detected drift of each API (resource ID); it's reported until the API is re-deployed
+var _apiDrifts = make(map[string]*schema.Drift)
+var _apiDriftsMutex = &sync.Mutex{}
+
+func getAPIDrift(api *context.API) *schema.Drift {
+	_apiDriftsMutex.Lock()
+	defer _apiDriftsMutex.Unlock()
+	return _apiDrifts[api.ID]
+}
+
+// reconcileAPIDrift restores the deployments and virtual services of APIs which were modified or deleted outside of cortex
+// (missing deployments are re-created by the API's workflow)
+func reconcileAPIDrift() {
+	currentResourceIDs := strset.New()
+
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			currentResourceIDs.Add(api.ID)
+
+			drift, err := reconcileAPI(ctx, api)
+			if err != nil {
+				apiCronLog(ctx, api).Error(err, "drift")
+			}
+			if drift == nil {
+				continue
+			}
+
+			apiCronLog(ctx, api).With(logging.Fields{"resources": drift.Resources, "restored": drift.Restored}).Warn("detected drift")
+			_apiDriftsMutex.Lock()
+			_apiDrifts[api.ID] = drift
+			_apiDriftsMutex.Unlock()
+		}
+	}
+
+	_apiDriftsMutex.Lock()
+	for resourceID := range _apiDrifts {
+		if !currentResourceIDs.Has(resourceID) {
+			delete(_apiDrifts, resourceID)
+		}
+	}
+	_apiDriftsMutex.Unlock()
+}
+
+// reconcileAPI re-applies the API's deployment and virtual service if they differ from the ones which the operator applied
+// (nil is returned if they don't); the API is skipped while it's being rolled out, or if its rollout was stopped
+func reconcileAPI(ctx *context.Context, api *context.API) (*schema.Drift, error) {
+	deploymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return nil, err
+	}
+	deployment, err := config.Kubernetes.GetDeployment(deploymentName)
+	if err != nil {
+		return nil, err
+	}
+	if deployment == nil || deployment.Labels["resourceID"] != api.ID || deployment.DeletionTimestamp != nil || getCrashLoop(deployment) != nil {
+		return nil, nil
+	}
+
+	// Routes are only reconciled for the istio ingress backend
+	var virtualService *kunstructured.Unstructured
+	virtualServiceName := internalAPIName(api.Name, ctx.App.Name)
+	if isIstioBackend() {
+		virtualService, err = config.Kubernetes.GetVirtualService(virtualServiceName, consts.K8sNamespace)
+		if err != nil {
+			return nil, err
+		}
+		if virtualService != nil && virtualService.GetLabels()["apiDeployment"] != deploymentName {
+			return nil, nil // the API's endpoint is routed by its blue_green or canary update
+		}
+	}
+
+	drift := &schema.Drift{
+		Restored: true,
+		Time:     time.Now(),
+	}
+
+	if k8s.HasDrifted(deployment, k8s.DeploymentSpecHash(deployment)) {
+		drift.Resources = append(drift.Resources, "deployment/"+deploymentName)
+
+		deploymentSpec, err := apiDeploymentSpec(ctx, api, api.WorkloadID, deploymentName, *deployment.Spec.Replicas)
+		if err != nil {
+			drift.Restored = false
+			return drift, err
+		}
+		for key, value := range deployment.Annotations {
+			if _, ok := deploymentSpec.Annotations[key]; !ok {
+				deploymentSpec.Annotations[key] = value // e.g. the API's owner
+			}
+		}
+		if _, err := config.Kubernetes.ApplyDeployment(deploymentSpec); err != nil {
+			drift.Restored = false
+			return drift, err
+		}
+	}
+
+	if isIstioBackend() && (virtualService == nil || k8s.HasDrifted(virtualService, k8s.VirtualServiceSpecHash(virtualService))) {
+		drift.Resources = append(drift.Resources, "virtualservice/"+virtualServiceName)
+
+		if _, err := config.Kubernetes.ApplyVirtualService(virtualServiceSpec(ctx, api, deploymentName)); err != nil {
+			drift.Restored = false
+			return drift, err
+		}
+	}
+
+	if len(drift.Resources) == 0 {
+		return nil, nil
+	}
+	return drift, nil
+}
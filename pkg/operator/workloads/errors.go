@@ -18,9 +18,12 @@ package workloads
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
 )
 
 type ErrorKind int
@@ -34,6 +37,21 @@ const (
 	ErrAPIInitializing
 	ErrNoAvailableNodeComputeLimit
 	ErrDuplicateEndpointOtherDeployment
+	ErrRequirementsCheckFailed
+	ErrRequirementsCheckTimeout
+	ErrAPINotBlueGreen
+	ErrNoAPIUpdateToPromote
+	ErrAPIUpdateNotReady
+	ErrNoNodeGroupComputeLimit
+	ErrUnsupportedByIngressBackend
+	ErrAPIAlreadyPaused
+	ErrAPINotPaused
+	ErrQuotaExceeded
+	ErrTritonGPURequired
+	ErrNoNodeGroupForArch
+	ErrReplicaNotFound
+	ErrNoRunningReplicas
+	ErrContainerNotFound
 )
 
 var errorKinds = []string{
@@ -45,9 +63,24 @@ var errorKinds = []string{
 	"err_api_initializing",
 	"err_no_available_node_compute_limit",
 	"err_duplicate_endpoint_other_deployment",
+	"err_requirements_check_failed",
+	"err_requirements_check_timeout",
+	"err_api_not_blue_green",
+	"err_no_api_update_to_promote",
+	"err_api_update_not_ready",
+	"err_no_node_group_compute_limit",
+	"err_unsupported_by_ingress_backend",
+	"err_api_already_paused",
+	"err_api_not_paused",
+	"err_quota_exceeded",
+	"err_triton_gpu_required",
+	"err_no_node_group_for_arch",
+	"err_replica_not_found",
+	"err_no_running_replicas",
+	"err_container_not_found",
 }
 
-var _ = [1]int{}[int(ErrDuplicateEndpointOtherDeployment)-(len(errorKinds)-1)] // Ensure list length matches
+var _ = [1]int{}[int(ErrContainerNotFound)-(len(errorKinds)-1)] // Ensure list length matches
 
 func (t ErrorKind) String() string {
 	return errorKinds[t]
@@ -144,3 +177,112 @@ func ErrorDuplicateEndpointOtherDeployment(appName string, apiName string) error
 		message: fmt.Sprintf("endpoint is already in use by an API named %s in the %s deployment", s.UserStr(apiName), s.UserStr(appName)),
 	})
 }
+
+func ErrorRequirementsCheckFailed(predictorType string, logs string) error {
+	message := fmt.Sprintf("unable to resolve the packages in the %s predictor's environment", predictorType)
+	if logs != "" {
+		message += ":\n" + logs
+	}
+	return errors.WithStack(Error{
+		Kind:    ErrRequirementsCheckFailed,
+		message: message,
+	})
+}
+
+func ErrorRequirementsCheckTimeout(predictorType string, timeout time.Duration) error {
+	return errors.WithStack(Error{
+		Kind:    ErrRequirementsCheckTimeout,
+		message: fmt.Sprintf("resolving the packages in the %s predictor's environment did not finish within %s", predictorType, timeout.String()),
+	})
+}
+
+func ErrorAPINotBlueGreen(apiName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrAPINotBlueGreen,
+		message: fmt.Sprintf("%s api does not use the %s or %s update mode", apiName, userconfig.BlueGreenUpdateMode.String(), userconfig.CanaryUpdateMode.String()),
+	})
+}
+
+func ErrorNoAPIUpdateToPromote(apiName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrNoAPIUpdateToPromote,
+		message: fmt.Sprintf("%s api is already serving its latest update", apiName),
+	})
+}
+
+func ErrorAPIUpdateNotReady(apiName string, readyReplicas int32, minReplicas int32) error {
+	return errors.WithStack(Error{
+		Kind:    ErrAPIUpdateNotReady,
+		message: fmt.Sprintf("%s api's update is not ready to be promoted (%d of %d replicas are ready)", apiName, readyReplicas, minReplicas),
+	})
+}
+
+func ErrorNoNodeGroupComputeLimit(reqStr string, nodeGroupsStr string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrNoNodeGroupComputeLimit,
+		message: fmt.Sprintf("none of the cluster's node groups can satisfy the requested compute (%s); available compute per node: %s", reqStr, nodeGroupsStr),
+	})
+}
+
+func ErrorUnsupportedByIngressBackend(ingressBackend string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrUnsupportedByIngressBackend,
+		message: fmt.Sprintf("is not supported when the cluster's ingress_backend is %s (it requires the istio ingress backend)", ingressBackend),
+	})
+}
+
+func ErrorAPIAlreadyPaused(apiName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrAPIAlreadyPaused,
+		message: fmt.Sprintf("%s api is already paused (run `cortex resume %s` to resume it)", apiName, apiName),
+	})
+}
+
+func ErrorAPINotPaused(apiName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrAPINotPaused,
+		message: fmt.Sprintf("%s api is not paused", apiName),
+	})
+}
+
+func ErrorQuotaExceeded(quotaStr string, resource string, requested string, limit string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrQuotaExceeded,
+		message: fmt.Sprintf("the %s quota would be exceeded: the apis would request up to %s %s at their maximum replicas, but the quota is %s %s", quotaStr, requested, resource, limit, resource),
+	})
+}
+
+func ErrorTritonGPURequired() error {
+	return errors.WithStack(Error{
+		Kind:    ErrTritonGPURequired,
+		message: fmt.Sprintf("the %s predictor type requires at least 1 gpu, since the cluster doesn't have a cpu-only build of Triton (specify %s in your cluster configuration to serve %s apis without gpus)", userconfig.TritonPredictorType.String(), clusterconfig.ImageTritonServeCPUKey, userconfig.TritonPredictorType.String()),
+	})
+}
+
+func ErrorNoNodeGroupForArch(arch string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrNoNodeGroupForArch,
+		message: fmt.Sprintf("the cluster doesn't have a node group of %s instances (specify %s in your cluster configuration to add a node group of graviton instances)", arch, clusterconfig.ARMInstanceTypeKey),
+	})
+}
+
+func ErrorReplicaNotFound(replica string, apiName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrReplicaNotFound,
+		message: fmt.Sprintf("%s api doesn't have a replica named %s (run `cortex get %s` to list its replicas)", apiName, replica, apiName),
+	})
+}
+
+func ErrorNoRunningReplicas(apiName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrNoRunningReplicas,
+		message: fmt.Sprintf("%s api doesn't have any running replicas", apiName),
+	})
+}
+
+func ErrorContainerNotFound(container string, replica string, containers []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrContainerNotFound,
+		message: fmt.Sprintf("replica %s doesn't have a container named %s (commands can only be run in %s)", replica, container, s.StrsOr(containers)),
+	})
+}
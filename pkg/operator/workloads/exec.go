@@ -0,0 +1,137 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	ocontext "github.com/cortexlabs/cortex/pkg/operator/context"
+)
+
+const (
+	// commands are killed once they've run for this long (the operator's load balancer closes idle connections after 10 minutes)
+	ExecTimeout = time.Minute
+
+	// stdout and stderr are each limited to this many bytes in the response
+	MaxExecOutputBytes = 1 << 20
+
+	// stdout and stderr are each limited to this many bytes in the audit record
+	_auditedExecOutputBytes = 64 << 10
+)
+
+// the containers which commands may be run in (their images are based on ubuntu, which includes coreutils' timeout)
+var _execContainerNames = []string{apiContainerName, servingContainerName}
+
+// ExecOptions describes a command to run in an API replica, and who requested it
+type ExecOptions struct {
+	Replica   string // if empty, one of the API's running replicas is used
+	Container string // if empty, the api container is used
+	Command   []string
+	Caller    string
+	RequestID string
+}
+
+// ExecReplica runs a single command in the api (or serve) container of one of the API's replicas (with ExecTimeout) and returns its output.
+// An audit record is stored in the cluster's bucket before the command is run (the command isn't run if the record can't be stored),
+// and it's updated with the command's exit code and output once the command completes
+func ExecReplica(ctx *context.Context, apiName string, opts ExecOptions) (*schema.ExecResponse, error) {
+	pod, err := replicaPod(ctx, apiName, opts.Replica)
+	if err != nil {
+		return nil, err
+	}
+
+	container := opts.Container
+	if container == "" {
+		container = apiContainerName
+	}
+	var containerNames []string
+	for _, c := range pod.Spec.Containers {
+		if slices.HasString(_execContainerNames, c.Name) {
+			containerNames = append(containerNames, c.Name)
+		}
+	}
+	if !slices.HasString(containerNames, container) {
+		return nil, ErrorContainerNotFound(container, pod.Name, containerNames)
+	}
+
+	start := time.Now().UTC()
+	record := &schema.ExecAuditRecord{
+		ID:        uuid.New().String(),
+		Time:      start,
+		Caller:    opts.Caller,
+		RequestID: opts.RequestID,
+		AppName:   ctx.App.Name,
+		APIName:   apiName,
+		Replica:   pod.Name,
+		Container: container,
+		Command:   opts.Command,
+	}
+	auditKey := ocontext.ExecAuditKey(record.ID, start)
+
+	if err := config.AWS.UploadJSONToS3(record, auditKey); err != nil {
+		return nil, errors.Wrap(err, "audit record")
+	}
+
+	command := append([]string{"timeout", "-s", "KILL", strconv.Itoa(int(ExecTimeout.Seconds()))}, opts.Command...)
+	result, execErr := config.Kubernetes.RunPodContainerCommand(pod.Name, container, command, MaxExecOutputBytes)
+
+	record.Completed = true
+	record.Duration = time.Since(start)
+	if execErr != nil {
+		record.Error = execErr.Error()
+	} else {
+		record.ExitCode = &result.ExitCode
+		record.Stdout = truncateOutput(result.Stdout, _auditedExecOutputBytes)
+		record.Stderr = truncateOutput(result.Stderr, _auditedExecOutputBytes)
+	}
+
+	// the command has already run, so its output is still returned if the record can't be updated
+	if err := config.AWS.UploadJSONToS3(record, auditKey); err != nil {
+		telemetry.Error(errors.Wrap(err, ctx.App.Name, apiName, "audit record", record.ID))
+	}
+
+	if execErr != nil {
+		return nil, execErr
+	}
+
+	return &schema.ExecResponse{
+		APIName:   apiName,
+		Replica:   pod.Name,
+		Container: container,
+		ExitCode:  result.ExitCode,
+		Stdout:    string(result.Stdout),
+		Stderr:    string(result.Stderr),
+		Truncated: result.Truncated,
+		AuditID:   record.ID,
+	}, nil
+}
+
+func truncateOutput(output []byte, maxBytes int) string {
+	if len(output) > maxBytes {
+		return string(output[:maxBytes])
+	}
+	return string(output)
+}
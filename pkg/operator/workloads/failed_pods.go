@@ -0,0 +1,112 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"time"
+
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	_maxFailedPodDeletionsPerCron = 20 // the remaining pods are deleted on subsequent cron runs
+	_maxFailedPodDeletionBackoff  = 5 * time.Minute
+)
+
+// Only accessed by the cron
+var failedPodDeletion = struct {
+	backoff     time.Duration
+	nextAttempt time.Time
+	deleted     map[string]int // reason -> number of pods deleted since the last telemetry report
+	errors      int            // number of failed deletions since the last telemetry report
+}{deleted: make(map[string]int)}
+
+func resetFailedPodDeletionCounts() {
+	failedPodDeletion.deleted = make(map[string]int)
+	failedPodDeletion.errors = 0
+}
+
+// deleteFailedPods deletes evicted pods (and pods which failed for any of the reasons in the cluster config),
+// keeping one pod for each current resource so that its status can be reported
+func deleteFailedPods(failedPods []kcore.Pod) {
+	if time.Now().Before(failedPodDeletion.nextAttempt) {
+		return
+	}
+
+	deletableReasons := strset.New(config.Cluster.DeleteFailedPodReasons...)
+	deletableReasons.Add(k8s.ReasonEvicted)
+
+	deletablePods := []kcore.Pod{}
+	for _, pod := range failedPods {
+		if deletableReasons.Has(pod.Status.Reason) && pod.DeletionTimestamp == nil {
+			deletablePods = append(deletablePods, pod)
+		}
+	}
+
+	if len(deletablePods) == 0 {
+		return
+	}
+
+	currentWorkloadIDs := strset.New()
+	for _, ctx := range CurrentContexts() {
+		currentWorkloadIDs.Merge(ctx.ComputedResourceWorkloadIDs())
+	}
+
+	savedResourceIDs := strset.New()
+	numDeleted := 0
+	for _, pod := range deletablePods {
+		if currentWorkloadIDs.Has(pod.Labels["workloadID"]) && !savedResourceIDs.Has(pod.Labels["resourceID"]) {
+			savedResourceIDs.Add(pod.Labels["resourceID"])
+			continue
+		}
+
+		if numDeleted == _maxFailedPodDeletionsPerCron {
+			break
+		}
+
+		if _, err := config.Kubernetes.DeletePod(pod.Name); err != nil {
+			failedPodDeletion.errors++
+			backOffFailedPodDeletion()
+			telemetry.Error(err)
+			_cronLog.With(logging.Fields{"pod": pod.Name}).Error(err)
+			return
+		}
+
+		failedPodDeletion.deleted[pod.Status.Reason]++
+		numDeleted++
+	}
+
+	failedPodDeletion.backoff = 0
+}
+
+// backOffFailedPodDeletion doubles the time to wait before deleting failed pods again (starting at the cron interval)
+func backOffFailedPodDeletion() {
+	failedPodDeletion.backoff *= 2
+	if failedPodDeletion.backoff == 0 {
+		failedPodDeletion.backoff = _cronInterval
+	}
+	if failedPodDeletion.backoff > _maxFailedPodDeletionBackoff {
+		failedPodDeletion.backoff = _maxFailedPodDeletionBackoff
+	}
+	failedPodDeletion.nextAttempt = time.Now().Add(failedPodDeletion.backoff)
+}
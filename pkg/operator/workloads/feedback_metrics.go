@@ -0,0 +1,277 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const _feedbackMetricsInterval = 1 * time.Minute
+
+var _lastFeedbackMetricsCron time.Time
+
+// The CloudWatch metric name of each feedback metric
+var _feedbackMetricNames = map[string]string{
+	userconfig.AccuracyFeedbackMetric:  "RollingAccuracy",
+	userconfig.PrecisionFeedbackMetric: "RollingPrecision",
+	userconfig.RecallFeedbackMetric:    "RollingRecall",
+	userconfig.MAEFeedbackMetric:       "RollingMAE",
+	userconfig.MSEFeedbackMetric:       "RollingMSE",
+}
+
+// The most recent feedback metrics of each API which computes them (resource ID)
+var _apiFeedbackMetrics = make(map[string]*schema.FeedbackMetrics)
+var _apiFeedbackMetricsMutex = &sync.Mutex{}
+
+// feedbackSample is written periodically by each of the API's replicas (under <metadata root>/<api ID>/feedback/samples/<unix time>-<suffix>.json),
+// and summarizes the feedback which the replica received on tracked predictions
+type feedbackSample struct {
+	Start         int64                       `json:"start"` // unix time
+	End           int64                       `json:"end"`   // unix time
+	Count         int64                       `json:"count"`
+	Confusion     map[string]map[string]int64 `json:"confusion"`      // predicted class -> ground truth class -> count (classification)
+	AbsoluteError float64                     `json:"absolute_error"` // the sum of the absolute errors (regression)
+	SquaredError  float64                     `json:"squared_error"`  // the sum of the squared errors (regression)
+}
+
+func getAPIFeedbackMetrics(api *context.API) *schema.FeedbackMetrics {
+	_apiFeedbackMetricsMutex.Lock()
+	defer _apiFeedbackMetricsMutex.Unlock()
+	return _apiFeedbackMetrics[api.ID]
+}
+
+// updateFeedbackMetrics computes the rolling metrics of each API's feedback, and publishes them to CloudWatch
+func updateFeedbackMetrics() {
+	now := time.Now()
+	currentResourceIDs := strset.New()
+
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			if api.Feedback == nil || api.Tracker == nil || len(api.Feedback.Metrics) == 0 {
+				continue
+			}
+			currentResourceIDs.Add(api.ID)
+
+			feedbackMetrics, err := computeFeedbackMetrics(ctx, api, now)
+			if err != nil {
+				apiCronLog(ctx, api).Error(err, "feedback metrics")
+				continue
+			}
+
+			_apiFeedbackMetricsMutex.Lock()
+			_apiFeedbackMetrics[api.ID] = feedbackMetrics
+			_apiFeedbackMetricsMutex.Unlock()
+
+			if err := publishFeedbackMetrics(ctx, api, feedbackMetrics); err != nil {
+				apiCronLog(ctx, api).Error(err, "feedback metrics")
+			}
+		}
+	}
+
+	_apiFeedbackMetricsMutex.Lock()
+	for resourceID := range _apiFeedbackMetrics {
+		if !currentResourceIDs.Has(resourceID) {
+			delete(_apiFeedbackMetrics, resourceID)
+		}
+	}
+	_apiFeedbackMetricsMutex.Unlock()
+}
+
+// computeFeedbackMetrics merges the samples which were written during the window, and deletes the samples which are no longer needed
+func computeFeedbackMetrics(ctx *context.Context, api *context.API, now time.Time) (*schema.FeedbackMetrics, error) {
+	samplesPrefix := filepath.Join(ctx.MetadataRoot, api.ID, "feedback", "samples") + "/"
+	windowStart := now.Add(-api.Feedback.Window).Unix()
+
+	if err := deleteSamples(samplesPrefix, windowStart); err != nil {
+		return nil, err
+	}
+
+	// keys start with the unix time at which they were written, so only the keys in the window are listed
+	objects, err := config.AWS.ListPrefixAfter(samplesPrefix, samplesPrefix+strconv.FormatInt(windowStart, 10), _maxSampleObjects)
+	if err != nil {
+		return nil, err
+	}
+
+	window := &feedbackSample{Confusion: map[string]map[string]int64{}}
+	for _, object := range objects {
+		if timestamp, ok := sampleObjectTime(object); !ok || timestamp <= windowStart {
+			continue
+		}
+		var sample feedbackSample
+		if err := config.AWS.ReadJSONFromS3(&sample, *object.Key); err != nil {
+			return nil, err
+		}
+		window.merge(&sample)
+	}
+
+	feedbackMetrics := window.metrics(api.Feedback.Metrics)
+	feedbackMetrics.Window = api.Feedback.Window.String()
+	feedbackMetrics.Time = now
+	return feedbackMetrics, nil
+}
+
+func (sample *feedbackSample) merge(other *feedbackSample) {
+	sample.Count += other.Count
+	sample.AbsoluteError += other.AbsoluteError
+	sample.SquaredError += other.SquaredError
+	for predicted, counts := range other.Confusion {
+		if sample.Confusion[predicted] == nil {
+			sample.Confusion[predicted] = map[string]int64{}
+		}
+		for actual, count := range counts {
+			sample.Confusion[predicted][actual] += count
+		}
+	}
+}
+
+// metrics computes the configured metrics of the sample (precision and recall are the unweighted averages of the classes' metrics)
+func (sample *feedbackSample) metrics(metrics []string) *schema.FeedbackMetrics {
+	feedbackMetrics := &schema.FeedbackMetrics{Count: sample.Count}
+	if sample.Count == 0 {
+		return feedbackMetrics
+	}
+	count := float64(sample.Count)
+
+	if slices.HasString(metrics, userconfig.MAEFeedbackMetric) {
+		feedbackMetrics.MAE = pointer.Float64(sample.AbsoluteError / count)
+	}
+	if slices.HasString(metrics, userconfig.MSEFeedbackMetric) {
+		feedbackMetrics.MSE = pointer.Float64(sample.SquaredError / count)
+	}
+
+	classes := strset.New()
+	predictedCounts := map[string]int64{}
+	actualCounts := map[string]int64{}
+	var correct int64
+	for predicted, counts := range sample.Confusion {
+		classes.Add(predicted)
+		for actual, count := range counts {
+			classes.Add(actual)
+			predictedCounts[predicted] += count
+			actualCounts[actual] += count
+			if predicted == actual {
+				correct += count
+			}
+		}
+	}
+
+	if slices.HasString(metrics, userconfig.AccuracyFeedbackMetric) {
+		feedbackMetrics.Accuracy = pointer.Float64(float64(correct) / count)
+	}
+
+	withPrecision := slices.HasString(metrics, userconfig.PrecisionFeedbackMetric)
+	withRecall := slices.HasString(metrics, userconfig.RecallFeedbackMetric)
+	if !withPrecision && !withRecall {
+		return feedbackMetrics
+	}
+
+	classList := classes.Slice()
+	sort.Strings(classList)
+
+	var precisions []float64
+	var recalls []float64
+	for _, class := range classList {
+		classMetrics := schema.ClassFeedbackMetrics{Class: class}
+		truePositives := float64(sample.Confusion[class][class])
+		if withPrecision && predictedCounts[class] > 0 {
+			classMetrics.Precision = pointer.Float64(truePositives / float64(predictedCounts[class]))
+			precisions = append(precisions, *classMetrics.Precision)
+		}
+		if withRecall && actualCounts[class] > 0 {
+			classMetrics.Recall = pointer.Float64(truePositives / float64(actualCounts[class]))
+			recalls = append(recalls, *classMetrics.Recall)
+		}
+		feedbackMetrics.Classes = append(feedbackMetrics.Classes, classMetrics)
+	}
+
+	if withPrecision {
+		feedbackMetrics.Precision = averageFloat64s(precisions)
+	}
+	if withRecall {
+		feedbackMetrics.Recall = averageFloat64s(recalls)
+	}
+	return feedbackMetrics
+}
+
+func averageFloat64s(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return pointer.Float64(sum / float64(len(values)))
+}
+
+// publishFeedbackMetrics publishes the metrics alongside the API's request metrics (as Rolling<metric>, e.g. RollingAccuracy)
+func publishFeedbackMetrics(ctx *context.Context, api *context.API, feedbackMetrics *schema.FeedbackMetrics) error {
+	values := map[string]*float64{
+		userconfig.AccuracyFeedbackMetric:  feedbackMetrics.Accuracy,
+		userconfig.PrecisionFeedbackMetric: feedbackMetrics.Precision,
+		userconfig.RecallFeedbackMetric:    feedbackMetrics.Recall,
+		userconfig.MAEFeedbackMetric:       feedbackMetrics.MAE,
+		userconfig.MSEFeedbackMetric:       feedbackMetrics.MSE,
+	}
+
+	metrics := make([]string, 0, len(values))
+	for metric := range values {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+
+	var metricData []*cloudwatch.MetricDatum
+	for _, metric := range metrics {
+		if values[metric] == nil {
+			continue
+		}
+		metricData = append(metricData, &cloudwatch.MetricDatum{
+			MetricName: aws.String(_feedbackMetricNames[metric]),
+			Dimensions: getAPIDimensions(ctx.App.Name, api),
+			Value:      values[metric],
+			Timestamp:  aws.Time(feedbackMetrics.Time),
+		})
+	}
+	if len(metricData) == 0 {
+		return nil
+	}
+
+	_, err := config.AWS.CloudWatchMetrics.PutMetricData(&cloudwatch.PutMetricDataInput{
+		Namespace:  aws.String(config.Cluster.LogGroup),
+		MetricData: metricData,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
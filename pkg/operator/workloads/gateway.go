@@ -0,0 +1,144 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"sort"
+
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	apisGateway         = "apis-gateway"
+	apisInternalGateway = "apis-internal-gateway"
+
+	// authorization policies apply to the gateways' pods, which run in the istio namespace
+	istioNamespace = "istio-system"
+)
+
+// gateway -> labels of the gateway's pods
+var gatewaySelectors = map[string]map[string]string{
+	apisGateway:         {"istio": "apis-ingressgateway"},
+	apisInternalGateway: {"istio": "apis-internal-ingressgateway"},
+}
+
+func apiGateway(api *context.API) string {
+	if api.Networking != nil && api.Networking.Visibility == userconfig.PrivateVisibility {
+		return apisInternalGateway
+	}
+	return apisGateway
+}
+
+func hasIPAllowlist(api *context.API) bool {
+	return api.Networking != nil && len(api.Networking.IPAllowlist) > 0
+}
+
+func authorizationPolicySpec(ctx *context.Context, api *context.API) *kunstructured.Unstructured {
+	return k8s.AuthorizationPolicy(&k8s.AuthorizationPolicySpec{
+		Name:      internalAPIName(api.Name, ctx.App.Name),
+		Namespace: istioNamespace,
+		Selector:  gatewaySelectors[apiGateway(api)],
+		Rules: []k8s.AuthorizationRule{
+			{
+				Paths:    api.Endpoints(),
+				IPBlocks: api.Networking.IPAllowlist,
+			},
+		},
+		Labels: map[string]string{
+			"appName":      ctx.App.Name,
+			"workloadType": workloadTypeAPI,
+			"apiName":      api.Name,
+		},
+	})
+}
+
+func applyAuthorizationPolicy(ctx *context.Context, api *context.API) error {
+	if hasIPAllowlist(api) {
+		if _, err := config.IstioKubernetes.ApplyAuthorizationPolicy(authorizationPolicySpec(ctx, api)); err != nil {
+			return err
+		}
+	} else {
+		if _, err := config.IstioKubernetes.DeleteAuthorizationPolicy(internalAPIName(api.Name, ctx.App.Name), istioNamespace); err != nil {
+			return err
+		}
+	}
+
+	return updateGatewayAuthorizationPolicies()
+}
+
+// Istio denies requests to a gateway which don't match any of its authorization policies (once it has at least one),
+// so when an API on the gateway has an IP allowlist, the endpoints of the gateway's other APIs are allowed from all sources
+func updateGatewayAuthorizationPolicies() error {
+	virtualServices, err := config.Kubernetes.ListIstioVirtualServicesByLabel(consts.K8sNamespace, "workloadType", workloadTypeAPI)
+	if err != nil {
+		return err
+	}
+
+	for gateway, selector := range gatewaySelectors {
+		hasRestrictedAPIs := false
+		unrestrictedEndpoints := strset.New()
+
+		for _, virtualService := range virtualServices {
+			if !virtualService.GatewaySet().Has(gateway) {
+				continue
+			}
+
+			if virtualService.Labels["ipAllowlist"] == "true" {
+				hasRestrictedAPIs = true
+				continue
+			}
+
+			unrestrictedEndpoints.Merge(virtualService.Endpoints())
+		}
+
+		policyName := gateway + "-unrestricted"
+
+		if !hasRestrictedAPIs {
+			if _, err := config.IstioKubernetes.DeleteAuthorizationPolicy(policyName, istioNamespace); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var rules []k8s.AuthorizationRule
+		if len(unrestrictedEndpoints) > 0 {
+			paths := unrestrictedEndpoints.Slice()
+			sort.Strings(paths)
+			rules = append(rules, k8s.AuthorizationRule{Paths: paths})
+		}
+
+		_, err := config.IstioKubernetes.ApplyAuthorizationPolicy(k8s.AuthorizationPolicy(&k8s.AuthorizationPolicySpec{
+			Name:      policyName,
+			Namespace: istioNamespace,
+			Selector:  selector,
+			Rules:     rules,
+		}))
+		if err != nil {
+			return errors.Wrap(err, gateway)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"math"
+	"time"
+
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// These mirror the defaults of the horizontal pod autoscaler, which scales APIs based on CPU utilization
+const (
+	_gpuAutoscaleInterval      = 15 * time.Second
+	_gpuScaleDownStabilization = 5 * time.Minute
+	_gpuUtilizationTolerance   = 0.1
+	_dcgmExporterAppLabelValue = "dcgm-exporter"
+)
+
+var _lastGPUAutoscaleCron time.Time
+
+type replicaRecommendation struct {
+	replicas  int32
+	timestamp time.Time
+}
+
+// resourceID -> recent replica recommendations (only accessed by the cron)
+var gpuReplicaRecommendations = make(map[string][]replicaRecommendation)
+
+func usesGPUAutoscaling(api *context.API) bool {
+	if api.Compute.TargetGPUUtilization == nil {
+		return false
+	}
+	minReplicas, maxReplicas := apiReplicaBounds(api)
+	return minReplicas != maxReplicas
+}
+
+// autoscaleGPUAPIs scales each API which specifies target_gpu_utilization based on its replicas' average GPU utilization
+func autoscaleGPUAPIs(apiPods []kcore.Pod) error {
+	var gpuAPIs []*context.API
+	var gpuCtxs []*context.Context
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			if usesGPUAutoscaling(api) {
+				gpuAPIs = append(gpuAPIs, api)
+				gpuCtxs = append(gpuCtxs, ctx)
+			}
+		}
+	}
+
+	currentResourceIDs := make(map[string]bool, len(gpuAPIs))
+	for _, api := range gpuAPIs {
+		currentResourceIDs[api.ID] = true
+	}
+	for resourceID := range gpuReplicaRecommendations {
+		if !currentResourceIDs[resourceID] {
+			delete(gpuReplicaRecommendations, resourceID)
+		}
+	}
+
+	if len(gpuAPIs) == 0 {
+		return nil
+	}
+
+	podsUtilization, err := config.Kubernetes.ListPodsGPUUtilization(map[string]string{
+		"app": _dcgmExporterAppLabelValue,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, api := range gpuAPIs {
+		if err := autoscaleGPUAPI(gpuCtxs[i], api, apiPods, podsUtilization); err != nil {
+			telemetry.Error(errors.Wrap(err, gpuCtxs[i].App.Name, api.Name))
+			apiCronLog(gpuCtxs[i], api).Error(err)
+		}
+	}
+
+	return nil
+}
+
+func autoscaleGPUAPI(ctx *context.Context, api *context.API, apiPods []kcore.Pod, podsUtilization map[string]float64) error {
+	k8sDeploymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return err
+	}
+
+	k8sDeployment, err := config.Kubernetes.GetDeployment(k8sDeploymentName)
+	if err != nil {
+		return err
+	}
+	if !isGPUAutoscalable(api, k8sDeployment) {
+		return nil
+	}
+
+	var readyPods int32
+	var utilizationSum float64
+	for _, pod := range apiPods {
+		if pod.Labels["resourceID"] != api.ID || pod.Labels["apiDeployment"] != k8sDeploymentName || !k8s.IsPodReady(&pod) {
+			continue
+		}
+		utilization, ok := podsUtilization[pod.Name]
+		if !ok {
+			continue
+		}
+		readyPods++
+		utilizationSum += utilization
+	}
+
+	if readyPods == 0 {
+		return nil
+	}
+
+	currentReplicas := *k8sDeployment.Spec.Replicas
+	recommendation := gpuReplicaRecommendation(api, currentReplicas, readyPods, utilizationSum)
+
+	now := time.Now()
+	recommendations := []replicaRecommendation{{replicas: recommendation, timestamp: now}}
+	for _, prev := range gpuReplicaRecommendations[api.ID] {
+		if now.Sub(prev.timestamp) < _gpuScaleDownStabilization {
+			recommendations = append(recommendations, prev)
+		}
+	}
+	gpuReplicaRecommendations[api.ID] = recommendations
+
+	// Scale up immediately, but only scale down to the highest replica count which was recommended during the stabilization window
+	desiredReplicas := recommendation
+	if desiredReplicas < currentReplicas {
+		for _, prev := range recommendations {
+			if prev.replicas > desiredReplicas {
+				desiredReplicas = prev.replicas
+			}
+		}
+		if desiredReplicas > currentReplicas {
+			desiredReplicas = currentReplicas
+		}
+	}
+
+	if desiredReplicas == currentReplicas {
+		return nil
+	}
+
+	k8sDeployment.Spec.Replicas = &desiredReplicas
+	if _, err := config.Kubernetes.ApplyDeployment(k8sDeployment); err != nil {
+		return err
+	}
+
+	telemetry.Event("operator.api.gpu_autoscale", map[string]interface{}{
+		"previousReplicas": currentReplicas,
+		"replicas":         desiredReplicas,
+	})
+
+	return nil
+}
+
+// isGPUAutoscalable is true once the API's deployment is up to date and fully rolled out
+func isGPUAutoscalable(api *context.API, k8sDeployment *kapps.Deployment) bool {
+	if k8sDeployment == nil || k8sDeployment.Labels["resourceID"] != api.ID || k8sDeployment.DeletionTimestamp != nil {
+		return false
+	}
+	if k8sDeployment.Spec.Replicas == nil || k8sDeployment.Spec.Paused {
+		return false
+	}
+	if k8sDeployment.Status.ObservedGeneration < k8sDeployment.Generation {
+		return false
+	}
+	return k8sDeployment.Status.UpdatedReplicas >= *k8sDeployment.Spec.Replicas
+}
+
+func gpuReplicaRecommendation(api *context.API, currentReplicas int32, readyPods int32, utilizationSum float64) int32 {
+	target := float64(*api.Compute.TargetGPUUtilization)
+	usageRatio := utilizationSum / float64(readyPods) / target
+
+	recommendation := currentReplicas
+	if math.Abs(usageRatio-1) > _gpuUtilizationTolerance {
+		recommendation = int32(math.Ceil(usageRatio * float64(readyPods)))
+	}
+
+	// Replicas which aren't ready yet don't report utilization, so don't scale down until they are
+	if recommendation < currentReplicas && readyPods < currentReplicas {
+		recommendation = currentReplicas
+	}
+
+	minReplicas, maxReplicas := apiReplicaBounds(api)
+	if recommendation < minReplicas {
+		recommendation = minReplicas
+	}
+	if recommendation > maxReplicas {
+		recommendation = maxReplicas
+	}
+	return recommendation
+}
@@ -0,0 +1,324 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	_grafanaInterval               = 1 * time.Minute
+	_grafanaDashboardWorkloadType  = "grafana-dashboard"
+	_grafanaDatasourceWorkloadType = "grafana-datasource"
+	_grafanaDatasourceName         = "cortex-prometheus-datasource"
+	_grafanaPanelWidth             = 12 // dashboards are 24 units wide, so panels are laid out in two columns
+	_grafanaPanelHeight            = 8
+)
+
+var _lastGrafanaCron time.Time
+
+type grafanaDashboard struct {
+	UID           string         `json:"uid"`
+	Title         string         `json:"title"`
+	Tags          []string       `json:"tags"`
+	Timezone      string         `json:"timezone"`
+	Refresh       string         `json:"refresh"`
+	SchemaVersion int            `json:"schemaVersion"`
+	Time          grafanaTime    `json:"time"`
+	Panels        []grafanaPanel `json:"panels"`
+}
+
+type grafanaTime struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type grafanaPanel struct {
+	ID         int             `json:"id"`
+	Type       string          `json:"type"`
+	Title      string          `json:"title"`
+	Datasource string          `json:"datasource"`
+	GridPos    grafanaGridPos  `json:"gridPos"`
+	Yaxes      []grafanaYaxis  `json:"yaxes"`
+	Targets    []grafanaTarget `json:"targets"`
+}
+
+type grafanaGridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type grafanaYaxis struct {
+	Format string `json:"format"`
+	Show   bool   `json:"show"`
+}
+
+type grafanaTarget struct {
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+	RefID        string `json:"refId"`
+}
+
+func grafanaDashboardName(appName string, apiName string) string {
+	return "cortex-dashboard-" + internalAPIName(apiName, appName)
+}
+
+// reconcileGrafana applies the ConfigMap of each API's Grafana dashboard (and of the Prometheus datasource, if configured),
+// and deletes the dashboards of APIs which have been deleted
+func reconcileGrafana() error {
+	if config.GrafanaKubernetes == nil {
+		return nil
+	}
+
+	if err := reconcileGrafanaDatasource(); err != nil {
+		return err
+	}
+
+	existingConfigMaps, err := config.GrafanaKubernetes.ListConfigMapsByLabels(map[string]string{
+		"workloadType": _grafanaDashboardWorkloadType,
+	})
+	if err != nil {
+		return err
+	}
+	existingData := make(map[string]map[string]string, len(existingConfigMaps))
+	for _, configMap := range existingConfigMaps {
+		existingData[configMap.Name] = configMap.Data
+	}
+
+	dashboardNames := strset.New()
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			configMap, err := grafanaDashboardConfigMap(ctx, api)
+			if err != nil {
+				return err
+			}
+			dashboardNames.Add(configMap.Name)
+
+			if data, ok := existingData[configMap.Name]; ok && reflect.DeepEqual(data, configMap.Data) {
+				continue
+			}
+			if _, err := config.GrafanaKubernetes.ApplyConfigMap(configMap); err != nil {
+				return err
+			}
+		}
+	}
+
+	for name := range existingData {
+		if !dashboardNames.Has(name) {
+			if _, err := config.GrafanaKubernetes.DeleteConfigMap(name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// reconcileGrafanaDatasource applies the ConfigMap of the Prometheus datasource if prometheus_url is configured, and deletes it otherwise
+func reconcileGrafanaDatasource() error {
+	if config.Cluster.Grafana.PrometheusURL == nil {
+		_, err := config.GrafanaKubernetes.DeleteConfigMap(_grafanaDatasourceName)
+		return err
+	}
+
+	datasources, err := json.Marshal(map[string]interface{}{
+		"apiVersion": 1,
+		"datasources": []map[string]interface{}{
+			{
+				"name":     config.Cluster.Grafana.Datasource,
+				"type":     "prometheus",
+				"access":   "proxy",
+				"url":      *config.Cluster.Grafana.PrometheusURL,
+				"editable": false,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	data := map[string]string{"cortex-prometheus.yaml": string(datasources)} // JSON is valid YAML
+
+	existing, err := config.GrafanaKubernetes.GetConfigMap(_grafanaDatasourceName)
+	if err != nil {
+		return err
+	}
+	if existing != nil && reflect.DeepEqual(existing.Data, data) {
+		return nil
+	}
+
+	_, err = config.GrafanaKubernetes.ApplyConfigMap(k8s.ConfigMap(&k8s.ConfigMapSpec{
+		Name:      _grafanaDatasourceName,
+		Namespace: config.Cluster.Grafana.Namespace,
+		Data:      data,
+		Labels: map[string]string{
+			"workloadType":                         _grafanaDatasourceWorkloadType,
+			config.Cluster.Grafana.DatasourceLabel: "1",
+		},
+	}))
+	return err
+}
+
+func grafanaDashboardConfigMap(ctx *context.Context, api *context.API) (*kcore.ConfigMap, error) {
+	dashboard, err := json.Marshal(apiGrafanaDashboard(ctx, api))
+	if err != nil {
+		return nil, err
+	}
+
+	return k8s.ConfigMap(&k8s.ConfigMapSpec{
+		Name:      grafanaDashboardName(ctx.App.Name, api.Name),
+		Namespace: config.Cluster.Grafana.Namespace,
+		Data: map[string]string{
+			internalAPIName(api.Name, ctx.App.Name) + ".json": string(dashboard),
+		},
+		Labels: map[string]string{
+			"workloadType":                        _grafanaDashboardWorkloadType,
+			"appName":                             ctx.App.Name,
+			"apiName":                             api.Name,
+			config.Cluster.Grafana.DashboardLabel: "1",
+		},
+		Annotations: map[string]string{
+			"grafana_folder": config.Cluster.Grafana.Folder,
+		},
+	}), nil
+}
+
+// apiGrafanaDashboard returns the API's dashboard, whose panels query the metrics which a typical Prometheus installation collects
+// (from cAdvisor and kube-state-metrics), along with the dcgm-exporter's metrics for GPU APIs, and Triton's metrics for Triton APIs
+func apiGrafanaDashboard(ctx *context.Context, api *context.API) *grafanaDashboard {
+	deploymentName := internalAPIName(api.Name, ctx.App.Name)
+	deploymentSelector := fmt.Sprintf("namespace=\"%s\", deployment=~\"%s(-blue|-green)?\"", consts.K8sNamespace, deploymentName)
+	podSelector := fmt.Sprintf("namespace=\"%s\", pod=~\"%s(-blue|-green)?-[a-z0-9]+-[a-z0-9]+\"", consts.K8sNamespace, deploymentName)
+
+	panels := []grafanaPanel{
+		{
+			Title: "replicas",
+			Yaxes: grafanaYaxes("short"),
+			Targets: []grafanaTarget{
+				{Expr: fmt.Sprintf("sum(kube_deployment_spec_replicas{%s})", deploymentSelector), LegendFormat: "requested"},
+				{Expr: fmt.Sprintf("sum(kube_deployment_status_replicas_available{%s})", deploymentSelector), LegendFormat: "available"},
+			},
+		},
+		{
+			Title: "container restarts",
+			Yaxes: grafanaYaxes("short"),
+			Targets: []grafanaTarget{
+				{Expr: fmt.Sprintf("sum by (container) (increase(kube_pod_container_status_restarts_total{%s}[5m]))", podSelector), LegendFormat: "{{container}}"},
+			},
+		},
+	}
+
+	// the model server's usage is graphed separately from the API container's for predictor types which serve the model in a sidecar
+	containers := []string{apiContainerName}
+	if api.Predictor.Type == userconfig.TensorFlowPredictorType || api.Predictor.Type == userconfig.TritonPredictorType {
+		containers = append(containers, servingContainerName)
+	}
+	for _, container := range containers {
+		containerSelector := fmt.Sprintf("%s, container=\"%s\"", podSelector, container)
+		panels = append(panels,
+			grafanaPanel{
+				Title: fmt.Sprintf("%s container cpu (cores)", container),
+				Yaxes: grafanaYaxes("short"),
+				Targets: []grafanaTarget{
+					{Expr: fmt.Sprintf("sum by (pod) (rate(container_cpu_usage_seconds_total{%s}[1m]))", containerSelector), LegendFormat: "{{pod}}"},
+				},
+			},
+			grafanaPanel{
+				Title: fmt.Sprintf("%s container memory", container),
+				Yaxes: grafanaYaxes("bytes"),
+				Targets: []grafanaTarget{
+					{Expr: fmt.Sprintf("sum by (pod) (container_memory_working_set_bytes{%s})", containerSelector), LegendFormat: "{{pod}}"},
+				},
+			},
+		)
+	}
+
+	if api.Compute.GPU > 0 {
+		panels = append(panels, grafanaPanel{
+			Title: "gpu utilization",
+			Yaxes: grafanaYaxes("percent"),
+			Targets: []grafanaTarget{
+				{Expr: fmt.Sprintf("avg by (pod) (DCGM_FI_DEV_GPU_UTIL{%s})", podSelector), LegendFormat: "{{pod}}"},
+			},
+		})
+	}
+
+	if api.Predictor.Type == userconfig.TritonPredictorType {
+		panels = append(panels,
+			grafanaPanel{
+				Title: "inferences per second",
+				Yaxes: grafanaYaxes("reqps"),
+				Targets: []grafanaTarget{
+					{Expr: fmt.Sprintf("sum by (model) (rate(nv_inference_request_success{%s}[1m]))", podSelector), LegendFormat: "{{model}} succeeded"},
+					{Expr: fmt.Sprintf("sum by (model) (rate(nv_inference_request_failure{%s}[1m]))", podSelector), LegendFormat: "{{model}} failed"},
+				},
+			},
+			grafanaPanel{
+				Title: "inference latency",
+				Yaxes: grafanaYaxes("µs"),
+				Targets: []grafanaTarget{
+					{Expr: fmt.Sprintf("sum by (model) (rate(nv_inference_request_duration_us{%s}[1m])) / sum by (model) (rate(nv_inference_request_success{%s}[1m]))", podSelector, podSelector), LegendFormat: "{{model}} request"},
+					{Expr: fmt.Sprintf("sum by (model) (rate(nv_inference_queue_duration_us{%s}[1m])) / sum by (model) (rate(nv_inference_request_success{%s}[1m]))", podSelector, podSelector), LegendFormat: "{{model}} queue"},
+				},
+			},
+		)
+	}
+
+	for i := range panels {
+		panels[i].ID = i + 1
+		panels[i].Type = "graph"
+		panels[i].Datasource = config.Cluster.Grafana.Datasource
+		panels[i].GridPos = grafanaGridPos{
+			X: (i % 2) * _grafanaPanelWidth,
+			Y: (i / 2) * _grafanaPanelHeight,
+			W: _grafanaPanelWidth,
+			H: _grafanaPanelHeight,
+		}
+		for j := range panels[i].Targets {
+			panels[i].Targets[j].RefID = string(rune('A' + j))
+		}
+	}
+
+	return &grafanaDashboard{
+		UID:           "cortex-" + hash.String(ctx.App.Name + "/" + api.Name)[:32],
+		Title:         fmt.Sprintf("%s / %s", ctx.App.Name, api.Name),
+		Tags:          []string{"cortex", api.Predictor.Type.String()},
+		Timezone:      "browser",
+		Refresh:       "30s",
+		SchemaVersion: 22,
+		Time:          grafanaTime{From: "now-1h", To: "now"},
+		Panels:        panels,
+	}
+}
+
+func grafanaYaxes(format string) []grafanaYaxis {
+	return []grafanaYaxis{{Format: format, Show: true}, {Format: "short", Show: false}}
+}
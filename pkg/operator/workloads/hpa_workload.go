@@ -50,7 +50,17 @@ func extractHPAWorkloads(ctx *context.Context) []Workload {
 func (hw *HPAWorkload) Start(ctx *context.Context) error {
 	api := ctx.APIs.OneByID(hw.APIID)
 
-	_, err := config.Kubernetes.ApplyHPA(hpaSpec(ctx, api))
+	k8sDeloymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return err
+	}
+
+	// APIs which scale on GPU utilization are scaled by the operator's cron instead of an HPA, and paused APIs aren't scaled
+	if usesGPUAutoscaling(api) || isAPIPaused(api) {
+		_, err = config.Kubernetes.DeleteHPA(k8sDeloymentName)
+	} else {
+		_, err = config.Kubernetes.ApplyHPA(hpaSpec(ctx, api, k8sDeloymentName))
+	}
 	if err != nil {
 		return err
 	}
@@ -60,14 +70,22 @@ func (hw *HPAWorkload) Start(ctx *context.Context) error {
 
 func (hw *HPAWorkload) IsSucceeded(ctx *context.Context) (bool, error) {
 	api := ctx.APIs.OneByID(hw.APIID)
-	k8sDeloymentName := internalAPIName(api.Name, ctx.App.Name)
+	k8sDeloymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return false, err
+	}
 
 	hpa, err := config.Kubernetes.GetHPA(k8sDeloymentName)
 	if err != nil {
 		return false, err
 	}
 
-	return k8s.IsHPAUpToDate(hpa, api.Compute.MinReplicas, api.Compute.MaxReplicas, api.Compute.TargetCPUUtilization), nil
+	if usesGPUAutoscaling(api) || isAPIPaused(api) {
+		return hpa == nil, nil
+	}
+
+	minReplicas, maxReplicas := apiReplicaBounds(api)
+	return k8s.IsHPAUpToDate(hpa, minReplicas, maxReplicas, api.Compute.TargetCPUUtilization), nil
 }
 
 func (hw *HPAWorkload) IsRunning(ctx *context.Context) (bool, error) {
@@ -80,7 +98,10 @@ func (hw *HPAWorkload) IsStarted(ctx *context.Context) (bool, error) {
 
 func (hw *HPAWorkload) CanRun(ctx *context.Context) (bool, error) {
 	api := ctx.APIs.OneByID(hw.APIID)
-	k8sDeloymentName := internalAPIName(api.Name, ctx.App.Name)
+	k8sDeloymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return false, err
+	}
 
 	k8sDeployment, err := config.Kubernetes.GetDeployment(k8sDeloymentName)
 	if err != nil {
@@ -119,11 +140,12 @@ func (hw *HPAWorkload) IsFailed(ctx *context.Context) (bool, error) {
 	return false, nil
 }
 
-func hpaSpec(ctx *context.Context, api *context.API) *kautoscaling.HorizontalPodAutoscaler {
+func hpaSpec(ctx *context.Context, api *context.API, deploymentName string) *kautoscaling.HorizontalPodAutoscaler {
+	minReplicas, maxReplicas := apiReplicaBounds(api)
 	return k8s.HPA(&k8s.HPASpec{
-		DeploymentName:       internalAPIName(api.Name, ctx.App.Name),
-		MinReplicas:          api.Compute.MinReplicas,
-		MaxReplicas:          api.Compute.MaxReplicas,
+		DeploymentName:       deploymentName,
+		MinReplicas:          minReplicas,
+		MaxReplicas:          maxReplicas,
 		TargetCPUUtilization: api.Compute.TargetCPUUtilization,
 		Labels: map[string]string{
 			"appName":      ctx.App.Name,
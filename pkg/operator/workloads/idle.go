@@ -0,0 +1,183 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+	"github.com/cortexlabs/cortex/pkg/lib/webhooks"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	_idleInterval     = 5 * time.Minute
+	_idleMetricPeriod = 3600 // seconds
+)
+
+var _lastIdleCron time.Time
+
+// The APIs which haven't received any requests during their idle window (workload ID -> idle); redeploying an API with a new workload ID resets it
+var _idleAPIs = make(map[string]*schema.Idle)
+var _idleAPIsMutex = &sync.Mutex{}
+
+func getAPIIdle(api *context.API) *schema.Idle {
+	if api.Compute.Idle == nil {
+		return nil
+	}
+	_idleAPIsMutex.Lock()
+	defer _idleAPIsMutex.Unlock()
+	return _idleAPIs[api.WorkloadID]
+}
+
+// resetAPIIdle clears the API's idleness (e.g. when it's resumed)
+func resetAPIIdle(api *context.API) {
+	_idleAPIsMutex.Lock()
+	defer _idleAPIsMutex.Unlock()
+	delete(_idleAPIs, api.WorkloadID)
+}
+
+// idleReplicaBounds overrides the API's replica bounds according to its idle action, if it's idle
+func idleReplicaBounds(api *context.API, minReplicas int32, maxReplicas int32) (int32, int32) {
+	if getAPIIdle(api) == nil {
+		return minReplicas, maxReplicas
+	}
+	switch api.Compute.Idle.Action {
+	case userconfig.ScaleToMinIdleAction:
+		return minReplicas, minReplicas
+	case userconfig.PauseIdleAction:
+		return 0, 0
+	}
+	return minReplicas, maxReplicas
+}
+
+// updateIdleAPIs checks whether each API which configures compute.idle has received any requests during its idle window,
+// and emits an event when an API becomes idle
+func updateIdleAPIs() {
+	now := time.Now()
+	currentWorkloadIDs := strset.New()
+
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			if api.Compute.Idle == nil || api.Pause != nil {
+				continue
+			}
+			currentWorkloadIDs.Add(api.WorkloadID)
+
+			idle, err := checkAPIIdle(ctx, api, now)
+			if err != nil {
+				apiCronLog(ctx, api).Error(err, "idle detection")
+				continue
+			}
+
+			previous := getAPIIdle(api)
+			if idle != nil && previous != nil {
+				idle.Since = previous.Since
+			}
+
+			_idleAPIsMutex.Lock()
+			if idle == nil {
+				delete(_idleAPIs, api.WorkloadID)
+			} else {
+				_idleAPIs[api.WorkloadID] = idle
+			}
+			_idleAPIsMutex.Unlock()
+
+			if idle == nil && previous != nil {
+				apiCronLog(ctx, api).Info("api is no longer idle")
+			}
+			if idle != nil && previous == nil {
+				apiCronLog(ctx, api).Warn("api is idle")
+				emitEvent(ctx, api, webhooks.IdleEvent, idle.Recommendation, map[string]interface{}{
+					"window": idle.Window,
+					"action": idle.Action,
+				})
+			}
+		}
+	}
+
+	_idleAPIsMutex.Lock()
+	for workloadID := range _idleAPIs {
+		if !currentWorkloadIDs.Has(workloadID) {
+			delete(_idleAPIs, workloadID)
+		}
+	}
+	_idleAPIsMutex.Unlock()
+}
+
+// checkAPIIdle returns nil if the API has received a request during its idle window, or if it hasn't been running (or hasn't been resumed) for the whole window
+func checkAPIIdle(ctx *context.Context, api *context.API, now time.Time) (*schema.Idle, error) {
+	idleConfig := api.Compute.Idle
+
+	apiSavedStatus, err := getAPISavedStatus(api.ID, api.WorkloadID, ctx.App.Name)
+	if err != nil {
+		return nil, err
+	}
+	if apiSavedStatus == nil || apiSavedStatus.Start == nil {
+		return nil, nil
+	}
+	runningSince := *apiSavedStatus.Start
+	if api.LastResumed != nil && api.LastResumed.After(runningSince) {
+		runningSince = *api.LastResumed
+	}
+	if now.Sub(runningSince) < idleConfig.Window {
+		return nil, nil
+	}
+
+	windowStart := now.Add(-idleConfig.Window)
+	output, err := config.AWS.CloudWatchMetrics.GetMetricData(&cloudwatch.GetMetricDataInput{
+		StartTime:         &windowStart,
+		EndTime:           &now,
+		MetricDataQueries: getNetworkStatsDef(ctx.App.Name, api, _idleMetricPeriod),
+	})
+	if err != nil {
+		return nil, err
+	}
+	networkStats, err := extractNetworkMetrics(output.MetricDataResults)
+	if err != nil {
+		return nil, err
+	}
+	if networkStats.Total > 0 {
+		return nil, nil
+	}
+
+	window := libtime.Difference(&windowStart, &now)
+	recommendation := fmt.Sprintf("%s hasn't received any requests in the last %s", api.Name, window)
+	switch idleConfig.Action {
+	case userconfig.ScaleToMinIdleAction:
+		recommendation += fmt.Sprintf(", so it has been scaled to its %s until it receives a request", userconfig.MinReplicasKey)
+	case userconfig.PauseIdleAction:
+		recommendation += fmt.Sprintf(", so it has been paused; run `cortex resume %s` to resume it", api.Name)
+	default:
+		recommendation += fmt.Sprintf("; run `cortex delete %s` if it's no longer needed, or set %s.%s.%s to %s or %s", api.Name, userconfig.ComputeKey, userconfig.IdleKey, userconfig.ActionKey, userconfig.ScaleToMinIdleAction.String(), userconfig.PauseIdleAction.String())
+	}
+
+	return &schema.Idle{
+		Window:         window,
+		Action:         idleConfig.Action.String(),
+		Since:          now,
+		Recommendation: recommendation,
+	}, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const _logGroupsInterval = 1 * time.Minute
+
+var _lastLogGroupsCron time.Time
+
+// The API log groups' names are <cluster log group>.<deployment name>.<api name> (see context.LogGroupName)
+func logGroupNamePrefix() string {
+	return config.Cluster.LogGroup + "."
+}
+
+// isAPILogGroupName returns whether the log group is one of the cluster's API log groups (deployment and API names can't contain periods,
+// so this excludes the log groups of other clusters whose log groups' names start with this cluster's log group's name)
+func isAPILogGroupName(name string) bool {
+	return strings.HasPrefix(name, logGroupNamePrefix()) && strings.Count(strings.TrimPrefix(name, logGroupNamePrefix()), ".") == 1
+}
+
+// reconcileLogGroups creates each API's log group, sets its retention, and deletes the log groups of APIs which have been deleted
+func reconcileLogGroups() error {
+	retentions := make(map[string]*int64) // log group name -> retention days
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			retentions[ctx.LogGroupName(api.Name)] = api.LogRetentionDays()
+		}
+	}
+
+	existingRetentions, err := config.AWS.ListLogGroups(logGroupNamePrefix())
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for name, retentionDays := range retentions {
+		existingRetentionDays, ok := existingRetentions[name]
+		if !ok {
+			if err := config.AWS.CreateLogGroup(name); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if retentionDays == nil {
+				continue // new log groups' logs never expire
+			}
+		} else if equalRetentionDays(existingRetentionDays, retentionDays) {
+			continue
+		}
+		if err := config.AWS.SetLogGroupRetention(name, retentionDays); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for name := range existingRetentions {
+		if _, ok := retentions[name]; !ok && isAPILogGroupName(name) {
+			if err := config.AWS.DeleteLogGroup(name); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.FirstError(errs...)
+}
+
+func equalRetentionDays(a *int64, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
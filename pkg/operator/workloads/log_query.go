@@ -0,0 +1,130 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+
+	awslib "github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	DefaultLogsQuerySince = time.Hour
+	DefaultLogsQueryLimit = 100
+	MaxLogsQueryLimit     = 9999 // CloudWatch Logs Insights returns at most 10000 results, and one more than the limit is queried to tell whether the results were truncated
+
+	_logsInsightsTimestampFormat = "2006-01-02 15:04:05.000"
+)
+
+// LogsQuery filters an API's historical log records
+type LogsQuery struct {
+	Since time.Duration // how far back to query
+	Level string        // the least severe level of the records (optional)
+	Pod   string        // the prefix of the names of the records' pods (optional)
+	Text  string        // text which the records contain (optional)
+	Limit int64
+}
+
+// QueryLogs queries the API's log group with CloudWatch Logs Insights, and returns the latest records which match the query
+func QueryLogs(ctx *context.Context, apiName string, query LogsQuery) (*schema.LogsResponse, error) {
+	logGroupName := ctx.LogGroupName(apiName)
+	endTime := time.Now()
+
+	results, err := config.AWS.QueryLogs(logGroupName, logsInsightsQuery(query), endTime.Add(-query.Since), endTime)
+	if err != nil {
+		if awslib.CheckErrCode(err, cloudwatchlogs.ErrCodeResourceNotFoundException) {
+			// the log group is created once the API's containers log, or by the operator's cron
+			return &schema.LogsResponse{APIName: apiName, Records: []schema.LogRecord{}}, nil
+		}
+		return nil, err
+	}
+
+	response := schema.LogsResponse{APIName: apiName}
+	if int64(len(results)) > query.Limit {
+		response.Truncated = true
+		results = results[:query.Limit]
+	}
+
+	// the results are sorted from the latest to the earliest
+	response.Records = make([]schema.LogRecord, len(results))
+	for i, result := range results {
+		timestamp, _ := time.Parse(_logsInsightsTimestampFormat, result["@timestamp"])
+		response.Records[len(results)-1-i] = schema.ParseLogRecord(timestamp, result["@logStream"], result["@message"])
+	}
+
+	return &response, nil
+}
+
+func logsInsightsQuery(query LogsQuery) string {
+	var sb strings.Builder
+	sb.WriteString("fields @timestamp, @logStream, @message")
+
+	if query.Pod != "" {
+		// the log streams are named <pod>_<container>
+		sb.WriteString(fmt.Sprintf("\n| filter @logStream like /^%s/", logsInsightsRegexEscape(query.Pod)))
+	}
+
+	if levels := schema.LogLevelsAtOrAbove(query.Level); len(levels) > 0 {
+		// JSON lines' levels are fields of their log objects, whereas text lines' levels are formatted by cortex's python logger
+		jsonLevels := append([]string{"fatal"}, levels...) // "critical" is always included
+		if slices.HasString(levels, "warning") {
+			jsonLevels = append(jsonLevels, "warn")
+		}
+		quotedLevels := make([]string, len(jsonLevels))
+		for i, level := range jsonLevels {
+			quotedLevels[i] = logsInsightsStringLiteral(level)
+		}
+		levelList := "[" + strings.Join(quotedLevels, ", ") + "]"
+
+		textLevels := make([]string, len(levels))
+		for i, level := range levels {
+			textLevels[i] = strings.ToUpper(level)
+		}
+
+		sb.WriteString(fmt.Sprintf("\n| filter tolower(log.level) in %s or tolower(log.levelname) in %s or log like /:(%s):/", levelList, levelList, strings.Join(textLevels, "|")))
+	}
+
+	if query.Text != "" {
+		sb.WriteString(fmt.Sprintf("\n| filter @message like %s", logsInsightsStringLiteral(query.Text)))
+	}
+
+	sb.WriteString("\n| sort @timestamp desc")
+	sb.WriteString(fmt.Sprintf("\n| limit %d", query.Limit+1))
+
+	return sb.String()
+}
+
+var _logsInsightsRegexSpecialChars = regexp.MustCompile(`[\\/.+*?()|\[\]{}^$]`)
+
+func logsInsightsRegexEscape(str string) string {
+	return _logsInsightsRegexSpecialChars.ReplaceAllString(str, `\$0`)
+}
+
+func logsInsightsStringLiteral(str string) string {
+	str = strings.ReplaceAll(str, `\`, `\\`)
+	str = strings.ReplaceAll(str, `"`, `\"`)
+	return `"` + str + `"`
+}
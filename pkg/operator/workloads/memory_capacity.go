@@ -27,6 +27,7 @@ import (
 const memConfigMapName = "cortex-instance-memory"
 const memConfigMapKey = "capacity"
 
+// GetMemoryCapacityFromNodes returns the smallest memory capacity of the worker nodes of the cluster's configured instance type
 func GetMemoryCapacityFromNodes() (*kresource.Quantity, error) {
 	opts := kmeta.ListOptions{
 		LabelSelector: k8s.LabelSelector(map[string]string{
@@ -38,20 +39,28 @@ func GetMemoryCapacityFromNodes() (*kresource.Quantity, error) {
 		return nil, err
 	}
 
-	var minMem *kresource.Quantity
-	for _, node := range nodes {
-		curMem := node.Status.Capacity.Memory()
-
-		if curMem != nil && minMem == nil {
-			minMem = curMem
+	var memCapacities []*kresource.Quantity
+	for i := range nodes {
+		if instanceType := nodeInstanceType(&nodes[i]); instanceType != "" && instanceType != config.Cluster.InstanceMetadata.Type {
+			continue // the node belongs to another node group
 		}
+		memCapacities = append(memCapacities, nodes[i].Status.Capacity.Memory())
+	}
+	return minMemoryCapacity(memCapacities...), nil
+}
 
-		if curMem != nil && minMem.Cmp(*curMem) < 0 {
-			minMem = curMem
+// minMemoryCapacity returns a copy of the smallest of the memory capacities (nil capacities are ignored, and nil is returned if all of them are nil)
+func minMemoryCapacity(memCapacities ...*kresource.Quantity) *kresource.Quantity {
+	var minMem *kresource.Quantity
+	for _, mem := range memCapacities {
+		if mem != nil && (minMem == nil || minMem.Cmp(*mem) > 0) {
+			minMem = mem
 		}
 	}
-
-	return minMem, nil
+	if minMem == nil {
+		return nil
+	}
+	return k8s.QuantityPtr(minMem.DeepCopy())
 }
 
 func GetMemoryCapacityFromConfigMap() (*kresource.Quantity, error) {
@@ -84,15 +93,7 @@ func UpdateMemoryCapacityConfigMap() (*kresource.Quantity, error) {
 		return nil, err
 	}
 
-	minMem := memFromConfig.Copy()
-
-	if memFromNodes != nil && minMem.Cmp(*memFromNodes) > 0 {
-		minMem = memFromNodes
-	}
-
-	if memFromConfigMap != nil && minMem.Cmp(*memFromConfigMap) > 0 {
-		minMem = memFromConfigMap
-	}
+	minMem := minMemoryCapacity(&memFromConfig, memFromNodes, memFromConfigMap)
 
 	if memFromConfigMap == nil || minMem.Cmp(*memFromConfigMap) != 0 {
 		configMap := k8s.ConfigMap(&k8s.ConfigMapSpec{
@@ -0,0 +1,209 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	_memoryUsageInterval          = 30 * time.Second
+	_memoryRecommendationFactor   = 1.5
+	_memoryRecommendationMultiple = 256 * _mi
+	_mi                           = 1024 * 1024
+	_gi                           = 1024 * _mi
+)
+
+var _lastMemoryUsageCron time.Time
+
+// resourceID -> peak memory usage (in bytes) of any of the API's replicas
+var apiPeakMemoryCache = struct {
+	m map[string]int64
+	sync.RWMutex
+}{m: make(map[string]int64)}
+
+// IDs of the OOM kills which have already been reported (only accessed by the cron)
+var reportedOOMKills = strset.New()
+
+func updateAPIPeakMemory(apiPods []kcore.Pod) error {
+	memoryUsage, err := config.Kubernetes.ListPodsMemoryUsage(map[string]string{
+		"workloadType": workloadTypeAPI,
+		"userFacing":   "true",
+	})
+	if err != nil {
+		return err
+	}
+
+	apiPeakMemoryCache.Lock()
+	defer apiPeakMemoryCache.Unlock()
+
+	currentResourceIDs := strset.New()
+	for _, pod := range apiPods {
+		resourceID := pod.Labels["resourceID"]
+		currentResourceIDs.Add(resourceID)
+
+		var podMem int64
+		for containerName, mem := range memoryUsage[pod.Name] {
+			if containerName == apiContainerName || containerName == servingContainerName {
+				podMem += mem.Value()
+			}
+		}
+		if podMem > apiPeakMemoryCache.m[resourceID] {
+			apiPeakMemoryCache.m[resourceID] = podMem
+		}
+	}
+
+	for resourceID := range apiPeakMemoryCache.m {
+		if !currentResourceIDs.Has(resourceID) {
+			delete(apiPeakMemoryCache.m, resourceID)
+		}
+	}
+
+	return nil
+}
+
+func getAPIPeakMemory(resourceID string) int64 {
+	apiPeakMemoryCache.RLock()
+	defer apiPeakMemoryCache.RUnlock()
+	return apiPeakMemoryCache.m[resourceID]
+}
+
+// oomKilledTermination returns the container's most recent termination if it was due to running out of memory
+func oomKilledTermination(containerStatus kcore.ContainerStatus) *kcore.ContainerStateTerminated {
+	if terminated := containerStatus.State.Terminated; terminated != nil {
+		if terminated.Reason == "OOMKilled" {
+			return terminated
+		}
+		return nil
+	}
+	if terminated := containerStatus.LastTerminationState.Terminated; terminated != nil && terminated.Reason == "OOMKilled" {
+		return terminated
+	}
+	return nil
+}
+
+func isAPIPodOOMKilled(pod *kcore.Pod) bool {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if oomKilledTermination(containerStatus) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func reportOOMKills(apiPods []kcore.Pod) {
+	currentOOMKills := strset.New()
+
+	for i := range apiPods {
+		pod := &apiPods[i]
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			terminated := oomKilledTermination(containerStatus)
+			if terminated == nil {
+				continue
+			}
+
+			oomKillID := fmt.Sprintf("%s/%s/%d", pod.Name, containerStatus.Name, terminated.FinishedAt.Unix())
+			currentOOMKills.Add(oomKillID)
+			if reportedOOMKills.Has(oomKillID) {
+				continue
+			}
+
+			properties := map[string]interface{}{
+				"container": containerStatus.Name,
+			}
+
+			if ctx := CurrentContext(pod.Labels["appName"]); ctx != nil {
+				if api := ctx.APIs.OneByID(pod.Labels["resourceID"]); api != nil {
+					if recommendation := apiMemoryRecommendation(api); recommendation != nil {
+						properties["requestedMem"] = recommendation.Requested
+						properties["peakMem"] = recommendation.PeakUsage
+						properties["recommendedMem"] = recommendation.Recommended
+					}
+				}
+			}
+
+			telemetry.Event("operator.api.oom", properties)
+		}
+	}
+
+	reportedOOMKills = currentOOMKills
+}
+
+// apiMemoryRecommendation returns how much memory the API should request, based on its requested memory and its peak memory usage
+func apiMemoryRecommendation(api *context.API) *schema.MemoryRecommendation {
+	peakMem := getAPIPeakMemory(api.ID)
+
+	baseMem := peakMem
+	if api.Compute.Mem != nil && api.Compute.Mem.Value() > baseMem {
+		baseMem = api.Compute.Mem.Value()
+	}
+	if baseMem == 0 {
+		return nil
+	}
+
+	recommendedMem := roundUpMem(int64(math.Ceil(float64(baseMem)*_memoryRecommendationFactor)), _memoryRecommendationMultiple)
+
+	recommendation := schema.MemoryRecommendation{
+		Recommended: memString(recommendedMem),
+	}
+	if api.Compute.Mem != nil {
+		recommendation.Requested = pointer.String(api.Compute.Mem.String())
+		recommendation.Message = fmt.Sprintf("increase mem from %s to ~%s", *recommendation.Requested, recommendation.Recommended)
+	} else {
+		recommendation.Message = fmt.Sprintf("set mem to ~%s", recommendation.Recommended)
+	}
+
+	if peakMem > 0 {
+		recommendation.PeakUsage = pointer.String(memString(roundUpMem(peakMem, _mi)))
+		recommendation.Message += fmt.Sprintf(" (peak usage: %s)", *recommendation.PeakUsage)
+	}
+
+	memCapacity, err := GetMemoryCapacityFromConfigMap()
+	if err == nil && memCapacity != nil && recommendedMem > memCapacity.Value() {
+		recommendation.InstanceMem = pointer.String(memCapacity.String())
+		recommendation.Message += fmt.Sprintf("; this is more than the memory available on your instances (%s), so a larger instance type is also required", *recommendation.InstanceMem)
+	}
+
+	return &recommendation
+}
+
+func roundUpMem(mem int64, multiple int64) int64 {
+	if mem%multiple == 0 {
+		return mem
+	}
+	return (mem/multiple + 1) * multiple
+}
+
+func memString(mem int64) string {
+	if mem >= _gi && mem%_gi == 0 {
+		return s.Int64(mem/_gi) + "Gi"
+	}
+	return s.Int64(roundUpMem(mem, _mi)/_mi) + "Mi"
+}
@@ -112,6 +112,13 @@ func getAPIMetricsFunc(ctx *context.Context, api *context.API, period int64, sta
 				}
 				apiMetrics.RegressionStats = regressionStats
 			}
+			if api.Feedback != nil {
+				feedbackStats, err := extractFeedbackMetrics(metricDataResults)
+				if err != nil {
+					return err
+				}
+				apiMetrics.FeedbackStats = feedbackStats
+			}
 		}
 		return nil
 	}
@@ -131,6 +138,9 @@ func queryMetrics(ctx *context.Context, api *context.API, period int64, startTim
 			regressionMetrics := getRegressionMetricDef(ctx.App.Name, api, period)
 			allMetrics = append(allMetrics, regressionMetrics...)
 		}
+		if api.Feedback != nil {
+			allMetrics = append(allMetrics, getFeedbackMetricDef(ctx.App.Name, api, period)...)
+		}
 	}
 
 	metricsDataQuery := cloudwatch.GetMetricDataInput{
@@ -226,6 +236,34 @@ func extractRegressionMetrics(metricsDataResults []*cloudwatch.MetricDataResult)
 	return &regressionStats, nil
 }
 
+func extractFeedbackMetrics(metricsDataResults []*cloudwatch.MetricDataResult) (*schema.FeedbackStats, error) {
+	var feedbackStats schema.FeedbackStats
+	var feedbackAvgs []*float64
+	var feedbackCounts []*float64
+
+	for _, metricData := range metricsDataResults {
+		if metricData.Values == nil {
+			continue
+		}
+
+		switch {
+		case *metricData.Label == "FeedbackSampleCount":
+			feedbackStats.SampleCount = slices.Float64PtrSumInt(metricData.Values...)
+			feedbackCounts = metricData.Values
+		case *metricData.Label == "FeedbackAvg":
+			feedbackAvgs = metricData.Values
+		}
+	}
+
+	avg, err := slices.Float64PtrAvg(feedbackAvgs, feedbackCounts)
+	if err != nil {
+		return nil, err
+	}
+	feedbackStats.Avg = avg
+
+	return &feedbackStats, nil
+}
+
 func getAPIDimensions(appName string, api *context.API) []*cloudwatch.Dimension {
 	return []*cloudwatch.Dimension{
 		{
@@ -312,6 +350,41 @@ func getRegressionMetricDef(appName string, api *context.API, period int64) []*c
 	return regressionMetric
 }
 
+// feedback on classification predictions is published as 1 (correct) or 0 (incorrect), so its average is the accuracy
+func getFeedbackMetricDef(appName string, api *context.API, period int64) []*cloudwatch.MetricDataQuery {
+	metricName := "AbsoluteError"
+	if api.Tracker.ModelType == userconfig.ClassificationModelType {
+		metricName = "Accuracy"
+	}
+
+	metric := &cloudwatch.Metric{
+		Namespace:  aws.String(config.Cluster.LogGroup),
+		MetricName: aws.String(metricName),
+		Dimensions: getAPIDimensionsHistogram(appName, api),
+	}
+
+	return []*cloudwatch.MetricDataQuery{
+		{
+			Id:    aws.String("feedback_sample_count"),
+			Label: aws.String("FeedbackSampleCount"),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: metric,
+				Stat:   aws.String("SampleCount"),
+				Period: aws.Int64(period),
+			},
+		},
+		{
+			Id:    aws.String("feedback_avg"),
+			Label: aws.String("FeedbackAvg"),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: metric,
+				Stat:   aws.String("Average"),
+				Period: aws.Int64(period),
+			},
+		},
+	}
+}
+
 func getNetworkStatsDef(appName string, api *context.API, period int64) []*cloudwatch.MetricDataQuery {
 	statusCodes := []string{"2XX", "4XX", "5XX"}
 	networkDataQueries := make([]*cloudwatch.MetricDataQuery, len(statusCodes)+2)
@@ -0,0 +1,69 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// the peer authentication which requires mutual TLS for the APIs' pods (when the cluster's mtls is enabled)
+const _apisPeerAuthenticationName = "apis"
+
+// applyPeerAuthentication requires mutual TLS for the APIs' pods if the cluster's mtls is enabled, and deletes the requirement otherwise
+func applyPeerAuthentication() error {
+	if !config.Cluster.MTLS {
+		_, err := config.Kubernetes.DeletePeerAuthentication(_apisPeerAuthenticationName, consts.K8sNamespace)
+		return err
+	}
+
+	_, err := config.Kubernetes.ApplyPeerAuthentication(k8s.PeerAuthentication(&k8s.PeerAuthenticationSpec{
+		Name:      _apisPeerAuthenticationName,
+		Namespace: consts.K8sNamespace,
+		Selector: map[string]string{
+			"workloadType": workloadTypeAPI,
+		},
+		Mode: k8s.PeerAuthenticationStrict,
+	}))
+	return err
+}
+
+// apiPodAnnotations configures the istio proxy of the API's pods; the pods are only injected with a proxy
+// when mtls is enabled or the API requires a JWT (the proxy validates the requests' tokens)
+func apiPodAnnotations(api *context.API) map[string]string {
+	if !config.Cluster.MTLS && api.JWTAuth() == nil {
+		return map[string]string{
+			"traffic.sidecar.istio.io/excludeOutboundIPRanges": "0.0.0.0/0",
+		}
+	}
+
+	// the kubelet's HTTP probes don't use mutual TLS or have a token, so the proxy rewrites them to be sent through it
+	annotations := map[string]string{
+		"sidecar.istio.io/inject":                "true",
+		"sidecar.istio.io/rewriteAppHTTPProbers": "true",
+	}
+	if config.Cluster.MTLS {
+		// requests to other APIs are sent through the proxy (so that they use mutual TLS), but HTTPS requests bypass it,
+		// since the init containers (e.g. the downloader) run before the proxy has started
+		annotations["traffic.sidecar.istio.io/excludeOutboundPorts"] = "443"
+	} else {
+		annotations["traffic.sidecar.istio.io/excludeOutboundIPRanges"] = "0.0.0.0/0"
+	}
+	return annotations
+}
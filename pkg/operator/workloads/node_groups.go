@@ -0,0 +1,168 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awslib "github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	_clusterNameTag   = "alpha.eksctl.io/cluster-name"
+	_nodeGroupNameTag = "eksctl.io/v1alpha2/nodegroup-name"
+	_workloadLabelTag = "k8s.io/cluster-autoscaler/node-template/label/workload"
+)
+
+// NodeGroupCapacity is the compute which is available to API replicas on a single node of a node group
+type NodeGroupCapacity struct {
+	Name         string
+	InstanceType string
+	Arch         string
+	CPU          kresource.Quantity
+	Mem          kresource.Quantity
+	GPU          int64
+	Reserve      NodeReserve // the compute on the node which isn't available to API replicas
+}
+
+func (ng *NodeGroupCapacity) String() string {
+	str := fmt.Sprintf("%s (%s): %s CPU, %s Memory", ng.Name, ng.InstanceType, ng.CPU.String(), ng.Mem.String())
+	if ng.GPU > 0 {
+		str += fmt.Sprintf(", %d GPU", ng.GPU)
+	}
+	return str
+}
+
+// GetNodeGroupCapacities returns the capacity of each worker node group that the cluster autoscaler can scale up;
+// if the node groups can't be discovered, only the cluster's configured instance types are considered
+func GetNodeGroupCapacities() ([]*NodeGroupCapacity, error) {
+	calculator, err := newCapacityCalculator()
+	if err != nil {
+		return nil, err
+	}
+
+	configuredNodeGroups := []*NodeGroupCapacity{calculator.NodeGroupCapacity("ng-cortex-worker-on-demand", &config.Cluster.InstanceMetadata)}
+	if config.Cluster.ARMInstanceType != nil {
+		if armInstanceMetadata, ok := awsInstanceMetadata(*config.Cluster.ARMInstanceType); ok {
+			configuredNodeGroups = append(configuredNodeGroups, calculator.NodeGroupCapacity("ng-cortex-worker-arm64", armInstanceMetadata))
+		}
+	}
+
+	nodeGroups, err := discoverNodeGroupCapacities(calculator)
+	if err != nil {
+		logging.PrintError(err, "discovering node groups")
+		return configuredNodeGroups, nil
+	}
+	if len(nodeGroups) == 0 {
+		return configuredNodeGroups, nil
+	}
+
+	return nodeGroups, nil
+}
+
+// newCapacityCalculator measures the cluster's nodes (and records the memory capacity of its instance type)
+func newCapacityCalculator() (*CapacityCalculator, error) {
+	memCapacity, err := UpdateMemoryCapacityConfigMap()
+	if err != nil {
+		return nil, err
+	}
+
+	reserves, err := discoverNodeReserves()
+	if err != nil {
+		logging.PrintError(err, "discovering node reserves") // the default reserves are used
+	}
+
+	calculator := &CapacityCalculator{
+		InstanceType: config.Cluster.InstanceMetadata.Type,
+		InstanceMem:  config.Cluster.InstanceMetadata.Memory.DeepCopy(),
+		MemCapacity:  memCapacity.DeepCopy(),
+		Reserves:     reserves,
+	}
+	if config.Cluster.CPUReserve != nil {
+		cpuReserve := kresource.MustParse(*config.Cluster.CPUReserve) // validated by the cluster config
+		calculator.CPUReserve = &cpuReserve
+	}
+	if config.Cluster.MemReserve != nil {
+		memReserve := kresource.MustParse(*config.Cluster.MemReserve) // validated by the cluster config
+		calculator.MemReserve = &memReserve
+	}
+
+	return calculator, nil
+}
+
+func discoverNodeGroupCapacities(calculator *CapacityCalculator) ([]*NodeGroupCapacity, error) {
+	asgs, err := config.AWS.AutoscalingGroups(map[string]string{
+		_clusterNameTag:   config.Cluster.ClusterName,
+		_workloadLabelTag: "true",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var nodeGroups []*NodeGroupCapacity
+	for _, asg := range asgs {
+		if aws.Int64Value(asg.MaxSize) == 0 {
+			continue
+		}
+
+		name := aws.StringValue(asg.AutoScalingGroupName)
+		for _, tag := range asg.Tags {
+			if aws.StringValue(tag.Key) == _nodeGroupNameTag && aws.StringValue(tag.Value) != "" {
+				name = aws.StringValue(tag.Value)
+			}
+		}
+
+		instanceType, err := config.AWS.AutoscalingGroupInstanceType(asg)
+		if err != nil {
+			return nil, errors.Wrap(err, name)
+		}
+
+		instanceMetadata, ok := awsInstanceMetadata(instanceType)
+		if !ok {
+			return nil, errors.New(name, fmt.Sprintf("unsupported instance type %s", instanceType)) // unexpected
+		}
+
+		nodeGroups = append(nodeGroups, calculator.NodeGroupCapacity(name, instanceMetadata))
+	}
+
+	return nodeGroups, nil
+}
+
+func awsInstanceMetadata(instanceType string) (*awslib.InstanceMetadata, bool) {
+	if instanceType == config.Cluster.InstanceMetadata.Type {
+		return &config.Cluster.InstanceMetadata, true
+	}
+	instanceMetadata, ok := awslib.InstanceMetadatas[*config.Cluster.Region][instanceType]
+	if !ok {
+		return nil, false
+	}
+	return &instanceMetadata, true
+}
+
+func nodeGroupsStr(nodeGroups []*NodeGroupCapacity) string {
+	strs := make([]string, len(nodeGroups))
+	for i, nodeGroup := range nodeGroups {
+		strs[i] = nodeGroup.String()
+	}
+	return strings.Join(strs, "; ")
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// isAPIPaused returns whether the API was paused with `cortex pause` or by its idle action
+func isAPIPaused(api *context.API) bool {
+	_, maxReplicas := apiReplicaBounds(api)
+	return maxReplicas == 0
+}
+
+// PauseAPI scales the API to 0 replicas until it's resumed (its spec and endpoint are kept, and requests to it fail with 503 errors)
+func PauseAPI(ctx *context.Context, apiName string) error {
+	api := ctx.APIs[apiName]
+	if api.Pause != nil {
+		return ErrorAPIAlreadyPaused(apiName)
+	}
+
+	k8sDeploymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return err
+	}
+	k8sDeployment, err := config.Kubernetes.GetDeployment(k8sDeploymentName)
+	if err != nil {
+		return err
+	}
+
+	updatedAPI := *api
+	updatedAPI.Pause = &context.APIPause{
+		Replicas: getRequestedReplicasFromDeployment(api, k8sDeployment, nil),
+		Time:     time.Now(),
+	}
+	updatedCtx, err := setCurrentContextAPI(ctx, &updatedAPI)
+	if err != nil {
+		return err
+	}
+
+	// The HPA is deleted by the workflow if the API's deployment is being updated
+	return applyReplicaSchedule(updatedCtx, updatedCtx.APIs[apiName])
+}
+
+// ResumeAPI restores the replicas which a paused API had when it was paused (APIs which were paused by their idle action are scaled to init_replicas),
+// and restarts its idle window
+func ResumeAPI(ctx *context.Context, apiName string) error {
+	api := ctx.APIs[apiName]
+	idlePaused := getAPIIdle(api) != nil && api.Compute.Idle.Action == userconfig.PauseIdleAction
+	if api.Pause == nil && !idlePaused {
+		return ErrorAPINotPaused(apiName)
+	}
+
+	replicas := api.Compute.InitReplicas
+	if api.Pause != nil && api.Pause.Replicas > 0 {
+		replicas = api.Pause.Replicas
+	}
+
+	now := time.Now()
+	updatedAPI := *api
+	updatedAPI.Pause = nil
+	updatedAPI.LastResumed = &now
+	if err := ValidateQuotas(contextWithAPI(ctx, &updatedAPI)); err != nil {
+		return err
+	}
+	updatedCtx, err := setCurrentContextAPI(ctx, &updatedAPI)
+	if err != nil {
+		return err
+	}
+	resetAPIIdle(api)
+
+	return restoreAPIReplicas(updatedCtx, updatedCtx.APIs[apiName], replicas)
+}
+
+// restoreAPIReplicas scales the API's deployment to the replicas (within its bounds); its HPA is recreated by the workflow
+func restoreAPIReplicas(ctx *context.Context, api *context.API, replicas int32) error {
+	k8sDeploymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return err
+	}
+
+	k8sDeployment, err := config.Kubernetes.GetDeployment(k8sDeploymentName)
+	if err != nil {
+		return err
+	}
+	if k8sDeployment == nil || k8sDeployment.Labels["resourceID"] != api.ID || k8sDeployment.DeletionTimestamp != nil {
+		return nil
+	}
+
+	minReplicas, maxReplicas := apiReplicaBounds(api)
+	if replicas < minReplicas {
+		replicas = minReplicas
+	}
+	if replicas > maxReplicas {
+		replicas = maxReplicas
+	}
+
+	k8sDeployment.Spec.Replicas = &replicas
+	if _, err := config.Kubernetes.ApplyDeployment(k8sDeployment); err != nil {
+		return err
+	}
+
+	return nil
+}
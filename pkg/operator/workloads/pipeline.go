@@ -0,0 +1,289 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// Pipelines are served by a router, which runs the pipeline's steps in order and passes their outputs to the steps which take them as inputs.
+// In the chained mode, the router calls the steps' APIs at their endpoints (so that requests are routed like any other request to the APIs);
+// in the colocated mode, the steps' APIs' containers run in the pipeline's pods, and the router calls them over localhost.
+
+// The port of the first colocated API (each of the pipeline's APIs is served at the next port)
+const colocatedPipelineBasePort = 9100
+
+type pipelineStepConfig struct {
+	Name           string   `json:"name"`
+	Inputs         []string `json:"inputs"`
+	URL            string   `json:"url"`
+	ForwardHeaders []string `json:"forward_headers"` // request headers which the step's API forwards to its predictor
+}
+
+type colocatedPipelinePod struct {
+	initContainers []kcore.Container
+	containers     []kcore.Container
+	volumes        []kcore.Volume
+	urls           map[string]string // API name -> URL of the API's predict route
+}
+
+func pipelineAPISpec(
+	ctx *context.Context,
+	api *context.API,
+	workloadID string,
+	deploymentName string,
+	desiredReplicas int32,
+) (*kapps.Deployment, error) {
+	colocated := &colocatedPipelinePod{}
+	var err error
+	if api.Pipeline.Mode == userconfig.ColocatedPipelineMode {
+		colocated, err = colocatedPipelineSteps(ctx, api, deploymentName)
+	} else {
+		colocated.urls, err = chainedPipelineStepURLs(ctx, api)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stages, err := api.Pipeline.Stages()
+	if err != nil {
+		return nil, err
+	}
+	stageConfigs := make([][]pipelineStepConfig, len(stages))
+	for i, stage := range stages {
+		for _, step := range stage {
+			var forwardHeaders []string
+			if stepAPI := ctx.APIs[step.API]; stepAPI != nil && stepAPI.Networking != nil {
+				for _, forwardHeader := range stepAPI.Networking.ForwardHeaders {
+					forwardHeaders = append(forwardHeaders, forwardHeader.Header)
+				}
+			}
+			stageConfigs[i] = append(stageConfigs[i], pipelineStepConfig{
+				Name:           step.Name,
+				Inputs:         step.Inputs,
+				URL:            colocated.urls[step.API],
+				ForwardHeaders: forwardHeaders,
+			})
+		}
+	}
+	stagesBytes, _ := json.Marshal(stageConfigs)
+	stagesStr := base64.URLEncoding.EncodeToString(stagesBytes)
+
+	resourceList := kcore.ResourceList{}
+	resourceList[kcore.ResourceCPU] = api.Compute.CPU.Quantity
+	if api.Compute.Mem != nil {
+		resourceList[kcore.ResourceMemory] = api.Compute.Mem.Quantity
+	}
+
+	envVars := []kcore.EnvVar{
+		{
+			Name: "HOST_IP",
+			ValueFrom: &kcore.EnvVarSource{
+				FieldRef: &kcore.ObjectFieldSelector{
+					FieldPath: "status.hostIP",
+				},
+			},
+		},
+	}
+	envVars = append(envVars, tracingEnvVars()...)
+
+	return k8s.Deployment(&k8s.DeploymentSpec{
+		Name:             deploymentName,
+		Replicas:         desiredReplicas,
+		ProgressDeadline: apiProgressDeadline(api),
+		Annotations:      apiDeploymentAnnotations(api),
+		Labels: map[string]string{
+			"appName":       ctx.App.Name,
+			"workloadType":  workloadTypeAPI,
+			"apiName":       api.Name,
+			"apiDeployment": deploymentName,
+			"resourceID":    ctx.APIs[api.Name].ID,
+			"workloadID":    workloadID,
+		},
+		Selector: map[string]string{
+			"appName":       ctx.App.Name,
+			"workloadType":  workloadTypeAPI,
+			"apiName":       api.Name,
+			"apiDeployment": deploymentName,
+		},
+		PodSpec: k8s.PodSpec{
+			Labels: map[string]string{
+				"appName":       ctx.App.Name,
+				"workloadType":  workloadTypeAPI,
+				"apiName":       api.Name,
+				"apiDeployment": deploymentName,
+				"resourceID":    ctx.APIs[api.Name].ID,
+				"workloadID":    workloadID,
+				"userFacing":    "true",
+				"logGroupName":  ctx.LogGroupName(api.Name),
+				"logFormat":     api.LogFormat(),
+			},
+			Annotations: apiPodAnnotations(api),
+			K8sPodSpec: kcore.PodSpec{
+				InitContainers: colocated.initContainers,
+				Containers: append([]kcore.Container{
+					{
+						Name:            apiContainerName,
+						Image:           archImage(config.Cluster.ImagePipelineAPI, api.Compute.Arch),
+						ImagePullPolicy: kcore.PullAlways,
+						Args: []string{
+							"--workload-id=" + workloadID,
+							"--port=" + defaultPortStr,
+							"--context=" + config.AWS.S3Path(ctx.Key),
+							"--api=" + ctx.APIs[api.Name].ID,
+							"--cache-dir=" + consts.ContextCacheDir,
+							"--stages=" + stagesStr,
+						},
+						Env:             envVars,
+						EnvFrom:         baseEnvVars(),
+						VolumeMounts:    defaultVolumeMounts(),
+						SecurityContext: apiContainerSecurityContext(),
+						ReadinessProbe: &kcore.Probe{
+							InitialDelaySeconds: 5,
+							TimeoutSeconds:      5,
+							PeriodSeconds:       5,
+							SuccessThreshold:    1,
+							FailureThreshold:    2,
+							Handler: kcore.Handler{
+								Exec: &kcore.ExecAction{
+									Command: []string{"/bin/bash", "-c", "/bin/ps aux | grep \"api.py\" && test -f /health_check.txt"},
+								},
+							},
+						},
+						Resources: kcore.ResourceRequirements{
+							Requests: resourceList,
+						},
+						Ports: []kcore.ContainerPort{
+							{
+								ContainerPort: defaultPortInt32,
+							},
+						},
+					},
+				}, colocated.containers...),
+				NodeSelector:       apiNodeSelector(api),
+				Tolerations:        tolerations,
+				Volumes:            append(defaultVolumes(), colocated.volumes...),
+				ServiceAccountName: "default",
+				PriorityClassName:  apiPriorityClassName(api),
+			},
+		},
+		Namespace: consts.K8sNamespace,
+	}), nil
+}
+
+// chainedPipelineStepURLs returns the URLs of the steps' APIs' endpoints (private APIs are called through the internal load balancer)
+func chainedPipelineStepURLs(ctx *context.Context, api *context.API) (map[string]string, error) {
+	urls := map[string]string{}
+	for _, apiName := range api.Pipeline.StepAPINames() {
+		url, err := apiURL(ctx, ctx.APIs[apiName])
+		if err != nil {
+			return nil, err
+		}
+		urls[apiName] = url
+	}
+	return urls, nil
+}
+
+// apiURL is the URL at which other APIs call the API (through the internal load balancer if the API is private, or at its service if it's cluster-local)
+func apiURL(ctx *context.Context, api *context.API) (string, error) {
+	if api.IsClusterLocal() {
+		return ClusterLocalAPIURL(ctx.App.Name, api.Name), nil
+	}
+	baseURL, err := routes().baseURL(apiGateway(api) == apisInternalGateway)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(baseURL, "/") + *api.Endpoint, nil
+}
+
+// colocatedPipelineSteps returns the containers and volumes of the steps' APIs' pods, which are renamed (prefixed with the API's name)
+// so that they don't conflict with each other, and which serve each API at its own port
+func colocatedPipelineSteps(ctx *context.Context, api *context.API, deploymentName string) (*colocatedPipelinePod, error) {
+	colocated := &colocatedPipelinePod{
+		urls: map[string]string{},
+	}
+
+	for i, apiName := range api.Pipeline.StepAPINames() {
+		stepAPI := ctx.APIs[apiName]
+		stepDeployment, err := apiDeploymentSpec(ctx, stepAPI, stepAPI.WorkloadID, deploymentName, 1)
+		if err != nil {
+			return nil, err
+		}
+		podSpec := stepDeployment.Spec.Template.Spec
+
+		port := int32(colocatedPipelineBasePort + i)
+		prefix := apiName + "-"
+
+		for _, container := range podSpec.InitContainers {
+			colocated.initContainers = append(colocated.initContainers, colocatedContainer(container, prefix, port))
+		}
+		for _, container := range podSpec.Containers {
+			colocated.containers = append(colocated.containers, colocatedContainer(container, prefix, port))
+		}
+		for _, volume := range podSpec.Volumes {
+			volume.Name = prefix + volume.Name
+			colocated.volumes = append(colocated.volumes, volume)
+		}
+
+		colocated.urls[apiName] = fmt.Sprintf("http://localhost:%d/predict", port)
+	}
+
+	return colocated, nil
+}
+
+func colocatedContainer(container kcore.Container, prefix string, port int32) kcore.Container {
+	container.Name = prefix + container.Name
+
+	args := make([]string, len(container.Args))
+	for i, arg := range container.Args {
+		if arg == "--port="+defaultPortStr {
+			arg = fmt.Sprintf("--port=%d", port)
+		}
+		args[i] = arg
+	}
+	container.Args = args
+
+	ports := make([]kcore.ContainerPort, len(container.Ports))
+	for i, containerPort := range container.Ports {
+		if containerPort.ContainerPort == defaultPortInt32 {
+			containerPort.ContainerPort = port
+		}
+		ports[i] = containerPort
+	}
+	container.Ports = ports
+
+	volumeMounts := make([]kcore.VolumeMount, len(container.VolumeMounts))
+	for i, volumeMount := range container.VolumeMounts {
+		volumeMount.Name = prefix + volumeMount.Name
+		volumeMounts[i] = volumeMount
+	}
+	container.VolumeMounts = volumeMounts
+
+	return container
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"encoding/json"
+	"path"
+
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	predictorConfigKey        = "config.json"
+	predictorConfigVolumeName = "predictor-config"
+	predictorConfigEnvVar     = "CORTEX_PREDICTOR_CONFIG_PATH"
+	// changing a pod's annotations prompts the kubelet to sync its config map volume instead of waiting for the next periodic sync
+	predictorConfigAnnotation = "predictorConfigID"
+)
+
+// updateAPIConfigMaps stores the predictor config of each API with config_reload in a k8s config map which is mounted into the API's replicas
+func updateAPIConfigMaps(ctx *context.Context) error {
+	for _, api := range ctx.APIs {
+		configMapName := apiConfigMapName(api.Name, ctx.App.Name)
+
+		if !api.Predictor.ConfigReload {
+			config.Kubernetes.DeleteConfigMap(configMapName)
+			continue
+		}
+
+		configBytes, err := json.Marshal(api.Predictor.Config)
+		if err != nil {
+			return errors.Wrap(err, userconfig.Identify(api), userconfig.PredictorKey, userconfig.ConfigKey)
+		}
+
+		_, err = config.Kubernetes.ApplyConfigMap(k8s.ConfigMap(&k8s.ConfigMapSpec{
+			Name:      configMapName,
+			Namespace: consts.K8sNamespace,
+			Data: map[string]string{
+				predictorConfigKey: string(configBytes),
+			},
+			Labels: map[string]string{
+				"appName":      ctx.App.Name,
+				"workloadType": workloadTypeAPI,
+				"apiName":      api.Name,
+			},
+		}))
+		if err != nil {
+			return errors.Wrap(err, userconfig.Identify(api))
+		}
+
+		pods, err := config.Kubernetes.ListPodsByLabels(map[string]string{
+			"appName":      ctx.App.Name,
+			"workloadType": workloadTypeAPI,
+			"apiName":      api.Name,
+		})
+		if err != nil {
+			return errors.Wrap(err, userconfig.Identify(api))
+		}
+
+		configID := api.ConfigID()
+		for _, pod := range pods {
+			if pod.Annotations[predictorConfigAnnotation] == configID {
+				continue
+			}
+			if _, err := config.Kubernetes.AnnotatePod(pod.Name, predictorConfigAnnotation, configID); err != nil {
+				return errors.Wrap(err, userconfig.Identify(api))
+			}
+		}
+	}
+
+	return nil
+}
+
+func predictorConfigVolumes(api *context.API, appName string) []kcore.Volume {
+	if !api.Predictor.ConfigReload {
+		return nil
+	}
+	return []kcore.Volume{
+		k8s.ConfigMapVolume(predictorConfigVolumeName, apiConfigMapName(api.Name, appName)),
+	}
+}
+
+func predictorConfigVolumeMounts(api *context.API) []kcore.VolumeMount {
+	if !api.Predictor.ConfigReload {
+		return nil
+	}
+	return []kcore.VolumeMount{
+		k8s.VolumeMount(predictorConfigVolumeName, consts.PredictorConfigMountPath, true),
+	}
+}
+
+func predictorConfigEnvVars(api *context.API) []kcore.EnvVar {
+	if !api.Predictor.ConfigReload {
+		return nil
+	}
+	return []kcore.EnvVar{
+		{
+			Name:  predictorConfigEnvVar,
+			Value: path.Join(consts.PredictorConfigMountPath, predictorConfigKey),
+		},
+	}
+}
+
+func apiConfigMapName(apiName string, appName string) string {
+	return internalAPIName(apiName, appName) + "-config"
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// The values of the APIs' priority classes; medium is the priority of pods which don't have a priority class, so medium priority APIs behave as if priorities
+// weren't configured (cortex's daemon sets use the cortex-system priority class, so that they aren't preempted by high priority APIs)
+var _priorityClassValues = map[userconfig.Priority]int32{
+	userconfig.LowPriority:    -1000,
+	userconfig.MediumPriority: 0,
+	userconfig.HighPriority:   1000,
+}
+
+func priorityClassName(priority userconfig.Priority) string {
+	return "cortex-api-" + priority.String()
+}
+
+func apiPriorityClassName(api *context.API) string {
+	if api.Compute.Priority == userconfig.UnknownPriority {
+		return priorityClassName(userconfig.MediumPriority) // APIs which were deployed before priorities were supported
+	}
+	return priorityClassName(api.Compute.Priority)
+}
+
+// applyPriorityClasses creates (or updates) the priority class of each API priority
+func applyPriorityClasses() error {
+	for _, priorityStr := range userconfig.PriorityStrings() {
+		priority := userconfig.PriorityFromString(priorityStr)
+		_, err := config.Kubernetes.ApplyPriorityClass(k8s.PriorityClass(&k8s.PriorityClassSpec{
+			Name:        priorityClassName(priority),
+			Value:       _priorityClassValues[priority],
+			Description: fmt.Sprintf("cortex APIs with %s priority", priority.String()),
+			Labels: map[string]string{
+				"cortex.dev/api-priority": priority.String(),
+			},
+		}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	ocontext "github.com/cortexlabs/cortex/pkg/operator/context"
+)
+
+// Types of replica profiles
+const (
+	CPUProfileType  = "cpu"  // a flamegraph (svg) of where the replica's python processes spent their time (py-spy record)
+	DumpProfileType = "dump" // the current stack of each of the replica's python threads (py-spy dump)
+)
+
+var ProfileTypes = []string{CPUProfileType, DumpProfileType}
+
+const (
+	DefaultProfileDuration = 30 * time.Second
+	MaxProfileDuration     = 5 * time.Minute
+
+	_profileURLExpiration = 24 * time.Hour
+
+	// the oldest of the api container's python processes is the one which started the others (e.g. its worker processes)
+	_profiledPIDCommand = "pid=$(pgrep -o -f 'cortex/.*api\\.py') || { echo 'the api process is not running' >&2; exit 1; }"
+)
+
+// ProfileReplica profiles the replica's python processes with py-spy (without pausing them), uploads the profile to the cluster's bucket,
+// and returns a URL which downloads it; if replica is empty, one of the API's running replicas is profiled
+func ProfileReplica(ctx *context.Context, apiName string, replica string, profileType string, duration time.Duration) (*schema.ProfileResponse, error) {
+	pod, err := replicaPod(ctx, apiName, replica)
+	if err != nil {
+		return nil, err
+	}
+
+	var command string
+	var fileExt string
+	switch profileType {
+	case CPUProfileType:
+		outputPath := "/tmp/cortex-profile.svg"
+		command = fmt.Sprintf("%s && py-spy record --pid $pid --subprocesses --nonblocking --duration %d --format flamegraph --output %s 1>&2 && cat %s && rm -f %s",
+			_profiledPIDCommand, int64(duration.Seconds()), outputPath, outputPath, outputPath)
+		fileExt = ".svg"
+	case DumpProfileType:
+		command = fmt.Sprintf("%s && py-spy dump --pid $pid --subprocesses --nonblocking", _profiledPIDCommand)
+		fileExt = ".txt"
+		duration = 0
+	}
+
+	profile, err := config.Kubernetes.ExecPodContainer(pod.Name, apiContainerName, []string{"/bin/sh", "-c", command})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	key := ocontext.ProfileKey(apiName, fmt.Sprintf("%s_%s_%s%s", now.Format("20060102T150405Z"), pod.Name, profileType, fileExt), ctx.App.Name)
+	if err := config.AWS.UploadBytesToS3(profile, key); err != nil {
+		return nil, err
+	}
+
+	url, err := config.AWS.PresignS3GetURL(key, _profileURLExpiration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.ProfileResponse{
+		APIName:   apiName,
+		Replica:   pod.Name,
+		Type:      profileType,
+		Duration:  duration,
+		S3Path:    config.AWS.S3Path(key),
+		URL:       url,
+		ExpiresAt: now.Add(_profileURLExpiration),
+	}, nil
+}
+
+func replicaPod(ctx *context.Context, apiName string, replica string) (*kcore.Pod, error) {
+	if replica != "" {
+		pod, err := config.Kubernetes.GetPod(replica)
+		if err != nil {
+			return nil, err
+		}
+		if pod == nil || pod.Labels["appName"] != ctx.App.Name || pod.Labels["apiName"] != apiName || pod.Labels["workloadType"] != workloadTypeAPI {
+			return nil, ErrorReplicaNotFound(replica, apiName)
+		}
+		if k8s.GetPodStatus(pod) != k8s.PodStatusRunning {
+			return nil, ErrorNoRunningReplicas(apiName)
+		}
+		return pod, nil
+	}
+
+	pods, err := config.Kubernetes.ListPodsByLabels(map[string]string{
+		"appName":      ctx.App.Name,
+		"workloadType": workloadTypeAPI,
+		"apiName":      apiName,
+		"userFacing":   "true",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// prefer ready replicas, since they're serving requests
+	sort.Slice(pods, func(i, j int) bool {
+		if k8s.IsPodReady(&pods[i]) != k8s.IsPodReady(&pods[j]) {
+			return k8s.IsPodReady(&pods[i])
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	for i := range pods {
+		if k8s.GetPodStatus(&pods[i]) == k8s.PodStatusRunning {
+			return &pods[i], nil
+		}
+	}
+	return nil, ErrorNoRunningReplicas(apiName)
+}
+
+// py-spy reads the memory of the api container's python processes (with ptrace) to profile them
+func apiContainerSecurityContext() *kcore.SecurityContext {
+	return &kcore.SecurityContext{
+		Capabilities: &kcore.Capabilities{
+			Add: []kcore.Capability{"SYS_PTRACE"},
+		},
+	}
+}
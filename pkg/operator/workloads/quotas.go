@@ -0,0 +1,176 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	awslib "github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+// quotaUsage is the compute which APIs request at their maximum replicas
+type quotaUsage struct {
+	CPU      kresource.Quantity
+	Mem      kresource.Quantity
+	GPU      int64
+	Replicas int64
+}
+
+func (usage *quotaUsage) addAPI(ctx *context.Context, api *context.API) {
+	replicas := apiQuotaReplicas(api)
+	if replicas == 0 {
+		return
+	}
+
+	cpu, mem, gpu := apiPodRequests(ctx, api)
+	if mem == nil {
+		mem = &kresource.Quantity{}
+	}
+
+	usage.CPU.Add(*kresource.NewMilliQuantity(cpu.MilliValue()*replicas, kresource.DecimalSI))
+	usage.Mem.Add(*kresource.NewQuantity(mem.Value()*replicas, kresource.BinarySI))
+	usage.GPU += gpu * replicas
+	usage.Replicas += replicas
+}
+
+// apiQuotaReplicas returns the most replicas which the API can scale up to, including during its replica schedules (APIs which are paused with `cortex pause` don't count towards quotas)
+func apiQuotaReplicas(api *context.API) int64 {
+	if api.Pause != nil {
+		return 0
+	}
+	maxReplicas := api.Compute.MaxReplicas
+	for _, schedule := range api.Compute.Schedules {
+		if schedule.MaxReplicas > maxReplicas {
+			maxReplicas = schedule.MaxReplicas
+		}
+	}
+	return int64(maxReplicas)
+}
+
+func quotaAppliesToAPI(quota *clusterconfig.Quota, appName string, api *context.API) bool {
+	if quota.Deployment != nil {
+		return *quota.Deployment == appName
+	}
+	return quota.Owner != nil && api.Owner != "" && awslib.PrincipalMatches([]string{*quota.Owner}, api.Owner)
+}
+
+func quotaAppliesToContext(quota *clusterconfig.Quota, ctx *context.Context) bool {
+	for _, api := range ctx.APIs {
+		if quotaAppliesToAPI(quota, ctx.App.Name, api) {
+			return true
+		}
+	}
+	return false
+}
+
+func getQuotaUsage(quota *clusterconfig.Quota, ctxs []*context.Context) *quotaUsage {
+	usage := &quotaUsage{}
+	for _, ctx := range ctxs {
+		for _, api := range ctx.APIs {
+			if quotaAppliesToAPI(quota, ctx.App.Name, api) {
+				usage.addAPI(ctx, api)
+			}
+		}
+	}
+	return usage
+}
+
+func quotaStr(quota *clusterconfig.Quota) string {
+	if quota.Deployment != nil {
+		return *quota.Deployment + " deployment's"
+	}
+	return *quota.Owner + "'s"
+}
+
+// ValidateQuotas returns an error if ctx would exceed one of the cluster's quotas, and uses more of it than the app's current context
+// (so that APIs which are already over a quota, e.g. because it was lowered, can still be updated as long as they don't request more compute)
+func ValidateQuotas(ctx *context.Context) error {
+	if len(config.Cluster.Quotas) == 0 {
+		return nil
+	}
+
+	currentCtxs := CurrentContexts()
+	ctxs := []*context.Context{ctx}
+	for _, currentCtx := range currentCtxs {
+		if currentCtx.App.Name != ctx.App.Name {
+			ctxs = append(ctxs, currentCtx)
+		}
+	}
+
+	for _, quota := range config.Cluster.Quotas {
+		if !quotaAppliesToContext(quota, ctx) {
+			continue
+		}
+
+		prevUsage := getQuotaUsage(quota, currentCtxs)
+		usage := getQuotaUsage(quota, ctxs)
+
+		if quota.CPU != nil {
+			limit := kresource.MustParse(*quota.CPU) // validated by the cluster config
+			if usage.CPU.Cmp(limit) > 0 && usage.CPU.Cmp(prevUsage.CPU) > 0 {
+				return ErrorQuotaExceeded(quotaStr(quota), "CPU", usage.CPU.String(), limit.String())
+			}
+		}
+		if quota.Mem != nil {
+			limit := kresource.MustParse(*quota.Mem) // validated by the cluster config
+			if usage.Mem.Cmp(limit) > 0 && usage.Mem.Cmp(prevUsage.Mem) > 0 {
+				return ErrorQuotaExceeded(quotaStr(quota), "memory", usage.Mem.String(), limit.String())
+			}
+		}
+		if quota.GPU != nil && usage.GPU > *quota.GPU && usage.GPU > prevUsage.GPU {
+			return ErrorQuotaExceeded(quotaStr(quota), "GPU", s.Int64(usage.GPU), s.Int64(*quota.GPU))
+		}
+		if quota.Replicas != nil && usage.Replicas > *quota.Replicas && usage.Replicas > prevUsage.Replicas {
+			return ErrorQuotaExceeded(quotaStr(quota), "replicas", s.Int64(usage.Replicas), s.Int64(*quota.Replicas))
+		}
+	}
+
+	return nil
+}
+
+// GetQuotaStatuses returns the usage of each of the cluster's quotas by the current contexts
+func GetQuotaStatuses() []schema.QuotaStatus {
+	currentCtxs := CurrentContexts()
+
+	statuses := make([]schema.QuotaStatus, len(config.Cluster.Quotas))
+	for i, quota := range config.Cluster.Quotas {
+		usage := getQuotaUsage(quota, currentCtxs)
+		statuses[i] = schema.QuotaStatus{
+			Deployment: aws.StringValue(quota.Deployment),
+			Owner:      aws.StringValue(quota.Owner),
+			Limit: schema.QuotaCompute{
+				CPU:      quota.CPU,
+				Mem:      quota.Mem,
+				GPU:      quota.GPU,
+				Replicas: quota.Replicas,
+			},
+			Used: schema.QuotaCompute{
+				CPU:      pointer.String(usage.CPU.String()),
+				Mem:      pointer.String(usage.Mem.String()),
+				GPU:      pointer.Int64(usage.GPU),
+				Replicas: pointer.Int64(usage.Replicas),
+			},
+		}
+	}
+	return statuses
+}
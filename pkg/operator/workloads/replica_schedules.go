@@ -0,0 +1,105 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// apiReplicaBounds returns the API's min and max replicas, taking its active replica schedule (if any) and its idle action (if it's idle) into account
+// (the bounds of paused APIs are 0, whether they were paused with `cortex pause` or by their idle action)
+func apiReplicaBounds(api *context.API) (int32, int32) {
+	if api.Pause != nil {
+		return 0, 0
+	}
+	minReplicas, maxReplicas := api.Compute.ReplicaBounds(time.Now())
+	return idleReplicaBounds(api, minReplicas, maxReplicas)
+}
+
+// applyReplicaSchedules updates the HPA bounds and replica counts of APIs whose active replica schedule or idleness has changed
+func applyReplicaSchedules() {
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			if len(api.Compute.Schedules) == 0 && api.Compute.Idle == nil && api.Pause == nil {
+				continue
+			}
+			if err := applyReplicaSchedule(ctx, api); err != nil {
+				telemetry.Error(errors.Wrap(err, ctx.App.Name, api.Name))
+				apiCronLog(ctx, api).Error(err)
+			}
+		}
+	}
+}
+
+func applyReplicaSchedule(ctx *context.Context, api *context.API) error {
+	k8sDeploymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return err
+	}
+
+	k8sDeployment, err := config.Kubernetes.GetDeployment(k8sDeploymentName)
+	if err != nil {
+		return err
+	}
+	if k8sDeployment == nil || k8sDeployment.Labels["resourceID"] != api.ID || k8sDeployment.DeletionTimestamp != nil {
+		return nil
+	}
+
+	minReplicas, maxReplicas := apiReplicaBounds(api)
+
+	hpa, err := config.Kubernetes.GetHPA(k8sDeploymentName)
+	if err != nil {
+		return err
+	}
+	if hpa != nil && maxReplicas == 0 {
+		if _, err := config.Kubernetes.DeleteHPA(k8sDeploymentName); err != nil {
+			return err
+		}
+	} else if hpa != nil && !usesGPUAutoscaling(api) && !k8s.IsHPAUpToDate(hpa, minReplicas, maxReplicas, api.Compute.TargetCPUUtilization) {
+		if _, err := config.Kubernetes.ApplyHPA(hpaSpec(ctx, api, k8sDeploymentName)); err != nil {
+			return err
+		}
+	}
+
+	// Update the deployment directly (rather than waiting for the HPA) so that the API isn't redeployed to reach its new bounds
+	if k8sDeployment.Spec.Replicas == nil {
+		return nil
+	}
+	replicas := *k8sDeployment.Spec.Replicas
+	if replicas < minReplicas {
+		replicas = minReplicas
+	}
+	if replicas > maxReplicas {
+		replicas = maxReplicas
+	}
+	if replicas == *k8sDeployment.Spec.Replicas {
+		return nil
+	}
+
+	k8sDeployment.Spec.Replicas = &replicas
+	if _, err := config.Kubernetes.ApplyDeployment(k8sDeployment); err != nil {
+		return err
+	}
+
+	return nil
+}
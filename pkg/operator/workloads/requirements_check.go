@@ -0,0 +1,174 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"strings"
+	"time"
+
+	kbatch "k8s.io/api/batch/v1"
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	requirementsCheckTimeout      = 5 * time.Minute
+	requirementsCheckPollInterval = 2 * time.Second
+	requirementsCheckLogLines     = 20
+)
+
+// Resolve (and download) the packages without installing them, so that the image is not modified
+const requirementsCheckScript = `printf '%s' "$CORTEX_REQUIREMENTS" > /tmp/requirements.txt && pip download --no-cache-dir --quiet --dest /tmp/packages -r /tmp/requirements.txt`
+
+// the requirements are resolved in each image which installs them
+type requirementsImage struct {
+	predictorType userconfig.PredictorType
+	pythonVersion string
+}
+
+// CheckRequirements resolves the project's requirements.txt in a short-lived job for each predictor type (and version of Python) that is used in the deployment
+func CheckRequirements(ctx *context.Context, requirementsBytes []byte) error {
+	images := map[requirementsImage]bool{}
+	for _, api := range ctx.APIs {
+		if api.Pipeline != nil {
+			continue // pipelines don't install the project's requirements
+		}
+		pythonVersion := userconfig.DefaultPythonVersion
+		if api.Predictor.PythonVersion != nil {
+			pythonVersion = *api.Predictor.PythonVersion
+		}
+		images[requirementsImage{predictorType: api.Predictor.Type, pythonVersion: pythonVersion}] = true
+	}
+
+	var fns []func() error
+	for image := range images {
+		image := image
+		fns = append(fns, func() error {
+			return checkRequirements(ctx.App.Name, image.predictorType, image.pythonVersion, string(requirementsBytes))
+		})
+	}
+
+	return parallel.RunFirstErr(fns...)
+}
+
+func checkRequirements(appName string, predictorType userconfig.PredictorType, pythonVersion string, requirements string) error {
+	jobName := appName + "-requirements-check-" + predictorType.String()
+	if pythonVersion != userconfig.DefaultPythonVersion {
+		jobName += "-py" + strings.Replace(pythonVersion, ".", "", -1)
+	}
+	config.Kubernetes.DeleteJob(jobName)
+
+	_, err := config.Kubernetes.CreateJob(requirementsCheckJobSpec(jobName, appName, predictorType, pythonVersion, requirements))
+	if err != nil {
+		return err
+	}
+	defer config.Kubernetes.DeleteJob(jobName)
+
+	start := time.Now()
+	for time.Since(start) < requirementsCheckTimeout {
+		time.Sleep(requirementsCheckPollInterval)
+
+		job, err := config.Kubernetes.GetJob(jobName)
+		if err != nil {
+			return err
+		}
+		if job == nil {
+			return errors.New(jobName, "job not found") // unexpected
+		}
+		if job.Status.Succeeded > 0 {
+			return nil
+		}
+		if job.Status.Failed > 0 {
+			return errors.Wrap(ErrorRequirementsCheckFailed(predictorType.String(), requirementsCheckLogs(jobName)), userconfig.RequirementsFileName)
+		}
+	}
+
+	return errors.Wrap(ErrorRequirementsCheckTimeout(predictorType.String(), requirementsCheckTimeout), userconfig.RequirementsFileName)
+}
+
+func requirementsCheckLogs(jobName string) string {
+	pods, _ := config.Kubernetes.ListPodsByLabel("job-name", jobName)
+	for _, pod := range pods {
+		logs, err := config.Kubernetes.GetPodLogs(pod.Name, requirementsCheckLogLines)
+		if err == nil && strings.TrimSpace(logs) != "" {
+			return strings.TrimSpace(logs)
+		}
+	}
+	return ""
+}
+
+func requirementsCheckJobSpec(jobName string, appName string, predictorType userconfig.PredictorType, pythonVersion string, requirements string) *kbatch.Job {
+	var image string
+	switch predictorType {
+	case userconfig.TensorFlowPredictorType:
+		image = config.Cluster.ImageTFAPI
+	case userconfig.ONNXPredictorType:
+		image = config.Cluster.ImageONNXServe
+	case userconfig.SKLearnPredictorType, userconfig.XGBoostPredictorType, userconfig.LightGBMPredictorType:
+		image = frameworkServingImage(predictorType)
+	case userconfig.TritonPredictorType:
+		image = config.Cluster.ImageTritonAPI
+	default:
+		image = config.Cluster.ImagePythonServe
+	}
+	image = pythonVersionedImage(image, &pythonVersion)
+
+	return k8s.Job(&k8s.JobSpec{
+		Name: jobName,
+		PodSpec: k8s.PodSpec{
+			Labels: map[string]string{
+				"appName":      appName,
+				"workloadType": workloadTypeRequirementsCheck,
+			},
+			K8sPodSpec: kcore.PodSpec{
+				RestartPolicy: "Never",
+				Containers: []kcore.Container{
+					{
+						Name:            "requirements-check",
+						Image:           image,
+						ImagePullPolicy: kcore.PullAlways,
+						Command:         []string{"/bin/bash", "-c", requirementsCheckScript},
+						Env: []kcore.EnvVar{
+							{
+								Name:  "CORTEX_REQUIREMENTS",
+								Value: requirements,
+							},
+						},
+					},
+				},
+				NodeSelector: map[string]string{
+					"workload":           "true",
+					"kubernetes.io/arch": userconfig.AMD64Arch.String(), // requirements are checked with the amd64 images
+				},
+				Tolerations:        tolerations,
+				ServiceAccountName: "default",
+			},
+		},
+		Labels: map[string]string{
+			"appName":      appName,
+			"workloadType": workloadTypeRequirementsCheck,
+		},
+		Namespace: consts.K8sNamespace,
+	})
+}
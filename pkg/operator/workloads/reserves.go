@@ -0,0 +1,158 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+var _instanceTypeLabels = []string{"node.kubernetes.io/instance-type", "beta.kubernetes.io/instance-type"}
+
+/*
+CPU Reservations (used for instance types which don't have any running nodes):
+
+FluentD 200
+StatsD 100
+KubeProxy 100
+Reserved (150 + 150) see eks.yaml for details
+Buffer (100)
+*/
+var cortexCPUReserve = kresource.MustParse("800m")
+
+/*
+Memory Reservations (used for instance types which don't have any running nodes):
+
+FluentD 200
+StatsD 100
+Reserved (300 + 300 + 200) see eks.yaml for details
+Buffer (100)
+*/
+var cortexMemReserve = kresource.MustParse("1200Mi")
+
+var nvidiaCPUReserve = kresource.MustParse("100m")
+var nvidiaMemReserve = kresource.MustParse("100Mi")
+
+// The buffer which is added to the reserves which are derived from running nodes
+var reserveCPUBuffer = kresource.MustParse("100m")
+var reserveMemBuffer = kresource.MustParse("100Mi")
+
+// NodeReserve is the compute on a node which isn't available to API replicas
+type NodeReserve struct {
+	CPU kresource.Quantity
+	Mem kresource.Quantity
+}
+
+func (reserve *NodeReserve) add(cpu kresource.Quantity, mem kresource.Quantity) {
+	reserve.CPU.Add(cpu)
+	reserve.Mem.Add(mem)
+}
+
+// discoverNodeReserves returns the reserve of each instance type which has running worker nodes: the compute which kubernetes reserves on the node
+// (its capacity minus its allocatable compute), plus the requests of the daemon set pods which are running on it, plus a buffer
+func discoverNodeReserves() (map[string]*NodeReserve, error) {
+	nodes, err := config.Kubernetes.ListNodes(&kmeta.ListOptions{
+		LabelSelector: k8s.LabelSelector(map[string]string{
+			"workload": "true",
+		}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := config.Kubernetes.ListPodsInAllNamespaces(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodeReserves(nodes, pods), nil
+}
+
+// nodeReserves returns the reserve of each instance type of the nodes (instance types which don't have any of the nodes aren't included)
+func nodeReserves(nodes []kcore.Node, pods []kcore.Pod) map[string]*NodeReserve {
+	daemonSetRequests := make(map[string]*NodeReserve) // node name -> requests
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || !isDaemonSetPod(&pod) {
+			continue
+		}
+		if pod.Status.Phase == kcore.PodSucceeded || pod.Status.Phase == kcore.PodFailed {
+			continue
+		}
+		if _, ok := daemonSetRequests[pod.Spec.NodeName]; !ok {
+			daemonSetRequests[pod.Spec.NodeName] = &NodeReserve{}
+		}
+		for _, container := range pod.Spec.Containers {
+			daemonSetRequests[pod.Spec.NodeName].add(*container.Resources.Requests.Cpu(), *container.Resources.Requests.Memory())
+		}
+	}
+
+	reserves := make(map[string]*NodeReserve)
+	for _, node := range nodes {
+		instanceType := nodeInstanceType(&node)
+		if instanceType == "" {
+			continue
+		}
+
+		cpu := node.Status.Capacity.Cpu().DeepCopy()
+		cpu.Sub(*node.Status.Allocatable.Cpu())
+		mem := node.Status.Capacity.Memory().DeepCopy()
+		mem.Sub(*node.Status.Allocatable.Memory())
+
+		reserve := &NodeReserve{CPU: cpu, Mem: mem}
+		if requests, ok := daemonSetRequests[node.Name]; ok {
+			reserve.add(requests.CPU, requests.Mem)
+		}
+		reserve.add(reserveCPUBuffer, reserveMemBuffer)
+
+		// nodes of the same instance type may be running different daemon sets (e.g. while one is being rolled out), so the largest reserve is used
+		existing, ok := reserves[instanceType]
+		if !ok {
+			reserves[instanceType] = reserve
+			continue
+		}
+		if reserve.CPU.Cmp(existing.CPU) > 0 {
+			existing.CPU = reserve.CPU
+		}
+		if reserve.Mem.Cmp(existing.Mem) > 0 {
+			existing.Mem = reserve.Mem
+		}
+	}
+
+	return reserves
+}
+
+func isDaemonSetPod(pod *kcore.Pod) bool {
+	for _, ownerReference := range pod.OwnerReferences {
+		if ownerReference.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeInstanceType(node *kcore.Node) string {
+	for _, label := range _instanceTypeLabels {
+		if instanceType := node.Labels[label]; instanceType != "" {
+			return instanceType
+		}
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	cacheContainerName = "cache"
+	cachePort          = 6379 // the api server connects to the cache at localhost:6379 (see cortex/lib/response_cache.py)
+)
+
+// The compute which the cache container requests (it's added to the API's requests when checking whether the API fits on the cluster's nodes)
+var cacheCPURequest = kresource.MustParse("100m")
+var cacheMemRequest = kresource.MustParse("64Mi")
+
+// apiSidecarContainers returns the sidecars of the API's pods: the API's response cache (if it has one) and the user's sidecars
+func apiSidecarContainers(api *context.API) []kcore.Container {
+	if api.Cache == nil {
+		return userSidecarContainers(api)
+	}
+	return append([]kcore.Container{cacheContainer(api)}, userSidecarContainers(api)...)
+}
+
+// cacheContainer runs a redis server which only stores the API's responses in memory, and is only reachable from within the pod;
+// the number of entries and their TTL are enforced by the api server
+func cacheContainer(api *context.API) kcore.Container {
+	return kcore.Container{
+		Name:            cacheContainerName,
+		Image:           archImage(config.Cluster.ImageRedis, api.Compute.Arch),
+		ImagePullPolicy: kcore.PullAlways,
+		Args: []string{
+			"redis-server",
+			"--port", s.Int32(cachePort),
+			"--bind", "127.0.0.1",
+			"--save", "",
+			"--appendonly", "no",
+		},
+		Resources: kcore.ResourceRequirements{
+			Requests: kcore.ResourceList{
+				kcore.ResourceCPU:    cacheCPURequest.DeepCopy(),
+				kcore.ResourceMemory: cacheMemRequest.DeepCopy(),
+			},
+		},
+	}
+}
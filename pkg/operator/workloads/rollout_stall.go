@@ -0,0 +1,123 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"strings"
+
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// If the API's replicas are blocked for different reasons, the first of these is reported
+var rolloutStallReasonPriorities = []string{
+	schema.RolloutStallReasonImagePull,
+	schema.RolloutStallReasonUnschedulableGPU,
+	schema.RolloutStallReasonInsufficientNodes,
+	schema.RolloutStallReasonNotReady,
+}
+
+func setStalledAPIStatusCodes(apiStatuses map[string]*resource.APIStatus, deployments map[string]*kapps.Deployment) {
+	for _, deployment := range deployments {
+		if k8s.DeploymentStalledTime(deployment) == nil {
+			continue
+		}
+		apiStatus, ok := apiStatuses[deployment.Labels["resourceID"]]
+		if !ok {
+			continue
+		}
+		switch apiStatus.Code {
+		case resource.StatusPending, resource.StatusWaiting, resource.StatusUpdating, resource.StatusPendingCompute:
+			apiStatus.Code = resource.StatusStalled
+		}
+	}
+}
+
+// getAPIRolloutStall returns why the API's rollout exceeded its progress deadline (nil if it hasn't)
+func getAPIRolloutStall(ctx *context.Context, api *context.API, pods []kcore.Pod) (*schema.RolloutStall, error) {
+	deploymentName, err := apiDeploymentName(ctx, api)
+	if err != nil {
+		return nil, err
+	}
+	deployment, err := config.Kubernetes.GetDeployment(deploymentName)
+	if err != nil {
+		return nil, err
+	}
+	if deployment == nil || deployment.Labels["resourceID"] != api.ID {
+		return nil, nil
+	}
+
+	stalledTime := k8s.DeploymentStalledTime(deployment)
+	if stalledTime == nil {
+		return nil, nil
+	}
+
+	stall := &schema.RolloutStall{
+		Reason: schema.RolloutStallReasonUnknown,
+		Time:   *stalledTime,
+	}
+
+	stallPriority := len(rolloutStallReasonPriorities)
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Labels["resourceID"] != api.ID || pod.DeletionTimestamp != nil {
+			continue
+		}
+		reason, message := podRolloutStallReason(pod)
+		for priority, prioritizedReason := range rolloutStallReasonPriorities {
+			if reason == prioritizedReason && priority < stallPriority {
+				stallPriority = priority
+				stall.Reason = reason
+				stall.Replica = pod.Name
+				stall.Message = message
+			}
+		}
+	}
+
+	return stall, nil
+}
+
+// podRolloutStallReason returns why the pod is not ready ("" if it is ready)
+func podRolloutStallReason(pod *kcore.Pod) (string, string) {
+	containerStatuses := append(pod.Status.InitContainerStatuses[:len(pod.Status.InitContainerStatuses):len(pod.Status.InitContainerStatuses)], pod.Status.ContainerStatuses...)
+	for _, containerStatus := range containerStatuses {
+		if waiting := containerStatus.State.Waiting; waiting != nil && imagePullReasons.Has(waiting.Reason) {
+			return schema.RolloutStallReasonImagePull, waiting.Message
+		}
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == kcore.PodScheduled && condition.Status == kcore.ConditionFalse && condition.Reason == kcore.PodReasonUnschedulable {
+			if strings.Contains(condition.Message, "nvidia.com/gpu") {
+				return schema.RolloutStallReasonUnschedulableGPU, condition.Message
+			}
+			return schema.RolloutStallReasonInsufficientNodes, condition.Message
+		}
+	}
+
+	if !k8s.IsPodReady(pod) {
+		return schema.RolloutStallReasonNotReady, ""
+	}
+
+	return "", ""
+}
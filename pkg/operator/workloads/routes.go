@@ -0,0 +1,360 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// Requests to the API's endpoint are rewritten to /predict (the path which the API containers serve)
+const _nginxRewriteAnnotation = "nginx.ingress.kubernetes.io/rewrite-target"
+
+// routeBackend manages the resources which route requests from the load balancer to the APIs; it's selected by the cluster's ingress_backend
+type routeBackend interface {
+	// validateAPI returns an error if the API uses a feature which the backend doesn't support
+	validateAPI(api *context.API) error
+	// listener is the gateway (or ingress class) which serves the API's endpoints
+	listener(api *context.API) string
+	// applyRoutes routes the API's endpoints to the given deployment
+	applyRoutes(ctx *context.Context, api *context.API, deploymentName string) error
+	// deleteRoutes deletes the routing resources which have the labels and for which shouldDelete returns true;
+	// it continues when a resource can't be deleted, and returns the first error
+	deleteRoutes(labels map[string]string, shouldDelete func(resourceLabels map[string]string) bool) error
+	// listRoutes returns the routes of all deployed APIs
+	listRoutes() ([]apiRoute, error)
+	// baseURL is the URL of the load balancer which serves the public (or, if private is true, the private) APIs
+	baseURL(private bool) (string, error)
+}
+
+type apiRoute struct {
+	labels    map[string]string
+	listeners strset.Set // endpoints on different listeners are served by different load balancers, so they can't collide
+	endpoints strset.Set
+}
+
+func routes() routeBackend {
+	switch config.Cluster.IngressBackend {
+	case clusterconfig.KubernetesIngressBackend:
+		return ingressRoutes{}
+	case clusterconfig.GatewayAPIIngressBackend:
+		return gatewayAPIRoutes{}
+	default:
+		return istioRoutes{}
+	}
+}
+
+func isIstioBackend() bool {
+	return config.Cluster.IngressBackend == "" || config.Cluster.IngressBackend == clusterconfig.IstioIngressBackend
+}
+
+func routeLabels(ctx *context.Context, api *context.API, deploymentName string) map[string]string {
+	return map[string]string{
+		"appName":       ctx.App.Name,
+		"workloadType":  workloadTypeAPI,
+		"apiName":       api.Name,
+		"apiDeployment": deploymentName,
+	}
+}
+
+type istioRoutes struct{}
+
+func (istioRoutes) validateAPI(api *context.API) error {
+	return nil
+}
+
+func (istioRoutes) listener(api *context.API) string {
+	return apiGateway(api)
+}
+
+func (istioRoutes) applyRoutes(ctx *context.Context, api *context.API, deploymentName string) error {
+	var err error
+	if isWebSocketAPI(api) || config.Cluster.MTLS || hasCircuitBreaker(api) {
+		_, err = config.Kubernetes.ApplyDestinationRule(destinationRuleSpec(ctx, api, deploymentName))
+	} else {
+		_, err = config.Kubernetes.DeleteDestinationRule(deploymentName, consts.K8sNamespace)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := applyVirtualServices(ctx, api, deploymentName); err != nil {
+		return err
+	}
+
+	return applyAuthorizationPolicy(ctx, api)
+}
+
+func (istioRoutes) deleteRoutes(labels map[string]string, shouldDelete func(resourceLabels map[string]string) bool) error {
+	var firstErr error
+	recordErr := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	virtualServices, err := config.Kubernetes.ListVirtualServicesByLabels(consts.K8sNamespace, labels)
+	recordErr(err)
+	for _, virtualService := range virtualServices {
+		if shouldDelete(virtualService.GetLabels()) {
+			_, err := config.Kubernetes.DeleteVirtualService(virtualService.GetName(), consts.K8sNamespace)
+			recordErr(err)
+		}
+	}
+
+	destinationRules, err := config.Kubernetes.ListDestinationRulesByLabels(consts.K8sNamespace, labels)
+	recordErr(err)
+	for _, destinationRule := range destinationRules {
+		if shouldDelete(destinationRule.GetLabels()) {
+			_, err := config.Kubernetes.DeleteDestinationRule(destinationRule.GetName(), consts.K8sNamespace)
+			recordErr(err)
+		}
+	}
+
+	authorizationPolicies, err := config.IstioKubernetes.ListAuthorizationPoliciesByLabels(istioNamespace, labels)
+	recordErr(err)
+	for _, authorizationPolicy := range authorizationPolicies {
+		if shouldDelete(authorizationPolicy.GetLabels()) {
+			_, err := config.IstioKubernetes.DeleteAuthorizationPolicy(authorizationPolicy.GetName(), istioNamespace)
+			recordErr(err)
+		}
+	}
+
+	return firstErr
+}
+
+func (istioRoutes) listRoutes() ([]apiRoute, error) {
+	virtualServices, err := config.Kubernetes.ListIstioVirtualServices(consts.K8sNamespace, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	apiRoutes := make([]apiRoute, len(virtualServices))
+	for i, virtualService := range virtualServices {
+		apiRoutes[i] = apiRoute{
+			labels:    virtualService.Labels,
+			listeners: virtualService.GatewaySet(),
+			endpoints: virtualService.Endpoints(),
+		}
+	}
+	return apiRoutes, nil
+}
+
+func (istioRoutes) baseURL(private bool) (string, error) {
+	if private {
+		return loadBalancerURL(config.IstioKubernetes, "apis-internal-ingressgateway")
+	}
+	return loadBalancerURL(config.IstioKubernetes, "apis-ingressgateway")
+}
+
+// ingressRoutes routes each API with a Kubernetes ingress, which is served by the user's ingress controller
+type ingressRoutes struct{}
+
+func (ingressRoutes) validateAPI(api *context.API) error {
+	return validateNonIstioAPI(api, clusterconfig.KubernetesIngressBackend)
+}
+
+func (ingressRoutes) listener(api *context.API) string {
+	return config.Cluster.IngressClass
+}
+
+func (ingressRoutes) applyRoutes(ctx *context.Context, api *context.API, deploymentName string) error {
+	_, err := config.Kubernetes.ApplyIngress(k8s.Ingress(&k8s.IngressSpec{
+		Name:         internalAPIName(api.Name, ctx.App.Name),
+		Namespace:    consts.K8sNamespace,
+		IngressClass: config.Cluster.IngressClass,
+		ServiceName:  deploymentName,
+		ServicePort:  defaultPortInt32,
+		Path:         urls.CanonicalizeEndpoint(*api.Endpoint),
+		Labels:       routeLabels(ctx, api, deploymentName),
+		Annotations: map[string]string{
+			_nginxRewriteAnnotation: "/predict",
+		},
+	}))
+	return err
+}
+
+func (ingressRoutes) deleteRoutes(labels map[string]string, shouldDelete func(resourceLabels map[string]string) bool) error {
+	var firstErr error
+
+	ingresses, err := config.Kubernetes.ListIngressesByLabels(labels)
+	if err != nil {
+		firstErr = err
+	}
+	for _, ingress := range ingresses {
+		if shouldDelete(ingress.Labels) {
+			if _, err := config.Kubernetes.DeleteIngress(ingress.Name); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+func (ingressRoutes) listRoutes() ([]apiRoute, error) {
+	ingresses, err := config.Kubernetes.ListIngresses(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	apiRoutes := make([]apiRoute, len(ingresses))
+	for i, ingress := range ingresses {
+		endpoints := strset.New()
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				endpoints.Add(urls.CanonicalizeEndpoint(path.Path))
+			}
+		}
+
+		apiRoutes[i] = apiRoute{
+			labels:    ingress.Labels,
+			listeners: strset.New(ingress.Annotations["kubernetes.io/ingress.class"]),
+			endpoints: endpoints,
+		}
+	}
+	return apiRoutes, nil
+}
+
+func (ingressRoutes) baseURL(private bool) (string, error) {
+	_, serviceName, _ := clusterconfig.SplitNamespacedName(config.Cluster.IngressService)
+	return loadBalancerURL(config.IngressKubernetes, serviceName)
+}
+
+// gatewayAPIRoutes routes each API with a Gateway API HTTP route, which is attached to the user's gateway
+type gatewayAPIRoutes struct{}
+
+func (gatewayAPIRoutes) validateAPI(api *context.API) error {
+	return validateNonIstioAPI(api, clusterconfig.GatewayAPIIngressBackend)
+}
+
+func (gatewayAPIRoutes) listener(api *context.API) string {
+	return *config.Cluster.IngressGateway
+}
+
+func (gatewayAPIRoutes) applyRoutes(ctx *context.Context, api *context.API, deploymentName string) error {
+	gatewayNamespace, gatewayName, _ := clusterconfig.SplitNamespacedName(*config.Cluster.IngressGateway)
+	_, err := config.Kubernetes.ApplyHTTPRoute(k8s.GatewayHTTPRoute(&k8s.GatewayHTTPRouteSpec{
+		Name:             internalAPIName(api.Name, ctx.App.Name),
+		Namespace:        consts.K8sNamespace,
+		GatewayName:      gatewayName,
+		GatewayNamespace: gatewayNamespace,
+		ServiceName:      deploymentName,
+		ServicePort:      defaultPortInt32,
+		Path:             *api.Endpoint,
+		Rewrite:          pointer.String("predict"),
+		Labels:           routeLabels(ctx, api, deploymentName),
+	}))
+	return err
+}
+
+func (gatewayAPIRoutes) deleteRoutes(labels map[string]string, shouldDelete func(resourceLabels map[string]string) bool) error {
+	var firstErr error
+
+	httpRoutes, err := config.Kubernetes.ListHTTPRoutesByLabels(consts.K8sNamespace, labels)
+	if err != nil {
+		firstErr = err
+	}
+	for _, httpRoute := range httpRoutes {
+		if shouldDelete(httpRoute.GetLabels()) {
+			if _, err := config.Kubernetes.DeleteHTTPRoute(httpRoute.GetName(), consts.K8sNamespace); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+func (gatewayAPIRoutes) listRoutes() ([]apiRoute, error) {
+	httpRoutes, err := config.Kubernetes.ListHTTPRoutesByLabels(consts.K8sNamespace, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	apiRoutes := make([]apiRoute, len(httpRoutes))
+	for i := range httpRoutes {
+		apiRoutes[i] = apiRoute{
+			labels:    httpRoutes[i].GetLabels(),
+			listeners: strset.New(k8s.GetHTTPRouteGateways(&httpRoutes[i])...),
+			endpoints: strset.New(k8s.GetHTTPRouteEndpoints(&httpRoutes[i])...),
+		}
+	}
+	return apiRoutes, nil
+}
+
+func (gatewayAPIRoutes) baseURL(private bool) (string, error) {
+	gatewayNamespace, gatewayName, _ := clusterconfig.SplitNamespacedName(*config.Cluster.IngressGateway)
+	address, err := config.Kubernetes.GetGatewayAddress(gatewayName, gatewayNamespace)
+	if err != nil {
+		return "", errors.Wrap(err, *config.Cluster.IngressGateway)
+	}
+	if address == "" {
+		return "", ErrorLoadBalancerInitializing()
+	}
+	return "http://" + address, nil
+}
+
+// The ingress and Gateway API backends route each API's endpoint to a single deployment, without Istio's traffic policies
+func validateNonIstioAPI(api *context.API, ingressBackend string) error {
+	if api.Networking != nil {
+		if api.Networking.Visibility == userconfig.PrivateVisibility {
+			return errors.Wrap(ErrorUnsupportedByIngressBackend(ingressBackend), userconfig.Identify(api), userconfig.NetworkingKey, userconfig.VisibilityKey, api.Networking.Visibility.String())
+		}
+		if len(api.Networking.IPAllowlist) > 0 {
+			return errors.Wrap(ErrorUnsupportedByIngressBackend(ingressBackend), userconfig.Identify(api), userconfig.NetworkingKey, userconfig.IPAllowlistKey)
+		}
+		if api.Networking.CORS != nil {
+			return errors.Wrap(ErrorUnsupportedByIngressBackend(ingressBackend), userconfig.Identify(api), userconfig.NetworkingKey, userconfig.CORSKey)
+		}
+		// circuit breakers are enforced by the istio gateway's destination rules
+		if api.Networking.CircuitBreaker != nil {
+			return errors.Wrap(ErrorUnsupportedByIngressBackend(ingressBackend), userconfig.Identify(api), userconfig.NetworkingKey, userconfig.CircuitBreakerKey)
+		}
+		if api.Networking.Timeout != 0 {
+			return errors.Wrap(ErrorUnsupportedByIngressBackend(ingressBackend), userconfig.Identify(api), userconfig.NetworkingKey, userconfig.TimeoutKey)
+		}
+		if api.Networking.Retries != nil {
+			return errors.Wrap(ErrorUnsupportedByIngressBackend(ingressBackend), userconfig.Identify(api), userconfig.NetworkingKey, userconfig.RetriesKey)
+		}
+	}
+
+	// the explain and feedback endpoints are routed alongside the API's endpoint by its virtual service
+	if api.Predictor.Explainer != nil {
+		return errors.Wrap(ErrorUnsupportedByIngressBackend(ingressBackend), userconfig.Identify(api), userconfig.PredictorKey, userconfig.ExplainerKey)
+	}
+	if api.Feedback != nil {
+		return errors.Wrap(ErrorUnsupportedByIngressBackend(ingressBackend), userconfig.Identify(api), userconfig.FeedbackKey)
+	}
+
+	if hasDeploymentSlots(api) {
+		return errors.Wrap(ErrorUnsupportedByIngressBackend(ingressBackend), userconfig.Identify(api), userconfig.UpdateStrategyKey, userconfig.ModeKey, api.UpdateStrategy.Mode.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"encoding/json"
+
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	predictorSecretsKey    = "predictor_secrets"
+	predictorSecretsEnvVar = "CORTEX_PREDICTOR_SECRETS"
+)
+
+// updateAPISecrets resolves the secret references in each API's predictor (and experiment variant) configs and stores the values in a k8s secret
+func updateAPISecrets(ctx *context.Context) error {
+	for _, api := range ctx.APIs {
+		secretName := apiSecretName(api.Name, ctx.App.Name)
+
+		refs := api.SecretReferences()
+		if len(refs) == 0 {
+			config.Kubernetes.DeleteSecret(secretName)
+			continue
+		}
+
+		secretValues, err := resolveSecretReferences(refs)
+		if err != nil {
+			return errors.Wrap(err, userconfig.Identify(api), userconfig.PredictorKey, userconfig.ConfigKey)
+		}
+
+		secretValuesBytes, err := json.Marshal(secretValues)
+		if err != nil {
+			return errors.Wrap(err, userconfig.Identify(api))
+		}
+
+		_, err = config.Kubernetes.ApplySecret(k8s.Secret(&k8s.SecretSpec{
+			Name:      secretName,
+			Namespace: consts.K8sNamespace,
+			Data: map[string]string{
+				predictorSecretsKey: string(secretValuesBytes),
+			},
+			Labels: map[string]string{
+				"appName":      ctx.App.Name,
+				"workloadType": workloadTypeAPI,
+				"apiName":      api.Name,
+			},
+		}))
+		if err != nil {
+			return errors.Wrap(err, userconfig.Identify(api))
+		}
+	}
+
+	return nil
+}
+
+// resolveSecretReferences returns a map of reference -> value
+func resolveSecretReferences(refs []*userconfig.SecretReference) (map[string]string, error) {
+	secretValues := make(map[string]string, len(refs))
+	for _, ref := range refs {
+		var value string
+		var err error
+		switch ref.Source {
+		case userconfig.SecretsManagerSecretSource:
+			value, err = config.AWS.GetSecretString(ref.ID)
+		case userconfig.SSMParameterSecretSource:
+			value, err = config.AWS.GetSSMParameter(ref.ID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		secretValues[ref.Reference] = value
+	}
+	return secretValues, nil
+}
+
+// ResolveSecretValue returns the value of the secret if str is a reference to AWS Secrets Manager or Systems Manager Parameter Store, otherwise str itself
+func ResolveSecretValue(str string) (string, error) {
+	ref, ok := userconfig.ParseSecretReference(str)
+	if !ok {
+		return str, nil
+	}
+
+	secretValues, err := resolveSecretReferences([]*userconfig.SecretReference{ref})
+	if err != nil {
+		return "", err
+	}
+	return secretValues[ref.Reference], nil
+}
+
+func predictorSecretsEnvVars(api *context.API, appName string) []kcore.EnvVar {
+	if len(api.SecretReferences()) == 0 {
+		return nil
+	}
+	return []kcore.EnvVar{
+		{
+			Name: predictorSecretsEnvVar,
+			ValueFrom: &kcore.EnvVarSource{
+				SecretKeyRef: &kcore.SecretKeySelector{
+					LocalObjectReference: kcore.LocalObjectReference{
+						Name: apiSecretName(api.Name, appName),
+					},
+					Key: predictorSecretsKey,
+				},
+			},
+		},
+	}
+}
+
+func apiSecretName(apiName string, appName string) string {
+	return internalAPIName(apiName, appName) + "-secrets"
+}
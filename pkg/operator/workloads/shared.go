@@ -17,12 +17,18 @@ limitations under the License.
 package workloads
 
 import (
+	"fmt"
+	"path"
+
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/lib/random"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/operator/api/context"
 	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
 	kcore "k8s.io/api/core/v1"
 )
 
@@ -100,6 +106,23 @@ func baseEnvVars() []kcore.EnvFromSource {
 	}
 }
 
+// tracingEnvVars configures the serving containers to export their spans to the cluster's collector (if tracing is enabled)
+func tracingEnvVars() []kcore.EnvVar {
+	if config.Cluster.Tracing == nil {
+		return nil
+	}
+	return []kcore.EnvVar{
+		{
+			Name:  "CORTEX_TRACING_ENDPOINT",
+			Value: config.Cluster.Tracing.Endpoint,
+		},
+		{
+			Name:  "CORTEX_TRACING_SAMPLE_RATE",
+			Value: s.Float64(config.Cluster.Tracing.SampleRate),
+		},
+	}
+}
+
 func defaultVolumes() []kcore.Volume {
 	return []kcore.Volume{
 		k8s.EmptyDirVolume(consts.EmptyDirVolumeName),
@@ -111,3 +134,40 @@ func defaultVolumeMounts() []kcore.VolumeMount {
 		k8s.EmptyDirVolumeMount(consts.EmptyDirVolumeName, consts.EmptyDirMountPath),
 	}
 }
+
+func apiVolumes(api *context.API, appName string) []kcore.Volume {
+	volumes := defaultVolumes()
+	for i, volume := range api.Volumes {
+		volumes = append(volumes, userVolume(userVolumeName(i), volume))
+	}
+	volumes = append(volumes, predictorConfigVolumes(api, appName)...)
+	volumes = append(volumes, apiFileVolumes(api, appName)...)
+	return volumes
+}
+
+func apiVolumeMounts(api *context.API) []kcore.VolumeMount {
+	volumeMounts := defaultVolumeMounts()
+	for i, volume := range api.Volumes {
+		volumeMounts = append(volumeMounts, k8s.VolumeMount(userVolumeName(i), volume.MountPath, volume.ReadOnly))
+	}
+	volumeMounts = append(volumeMounts, predictorConfigVolumeMounts(api)...)
+	volumeMounts = append(volumeMounts, apiFileVolumeMounts(api)...)
+	return volumeMounts
+}
+
+func userVolumeName(index int) string {
+	return fmt.Sprintf("user-volume-%d", index)
+}
+
+// EFS is mounted over NFS; FSx for Lustre requires the FSx CSI driver to be installed on the cluster
+func userVolume(volumeName string, volume *userconfig.Volume) kcore.Volume {
+	switch volume.Type {
+	case userconfig.FSxVolumeType:
+		return k8s.CSIVolume(volumeName, "fsx.csi.aws.com", map[string]string{
+			"dnsname":   fmt.Sprintf("%s.fsx.%s.amazonaws.com", volume.FileSystemID, *config.Cluster.Region),
+			"mountname": path.Join(*volume.MountName, volume.SubPath),
+		}, volume.ReadOnly)
+	default:
+		return k8s.NFSVolume(volumeName, fmt.Sprintf("%s.efs.%s.amazonaws.com", volume.FileSystemID, *config.Cluster.Region), volume.SubPath, volume.ReadOnly)
+	}
+}
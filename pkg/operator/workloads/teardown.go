@@ -0,0 +1,239 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	// appName -> whether to keep the app's cache (for deployments whose deletion hasn't completed)
+	_pendingTeardownsConfigMapName = "cortex-pending-teardowns"
+	_orphanGCInterval              = 1 * time.Minute
+)
+
+var _lastOrphanGCCron time.Time
+
+var _pendingTeardownsMutex = &sync.Mutex{}
+
+// teardownApp deletes all of the app's kubernetes resources and (unless keepCache is true) its files in S3;
+// it's idempotent, so it can be retried until it succeeds
+func teardownApp(appName string, keepCache bool) error {
+	var firstErr error
+
+	if err := deleteResources(map[string]string{"appName": appName}, nil); err != nil {
+		firstErr = err
+	}
+
+	if isIstioBackend() {
+		if err := updateGatewayAuthorizationPolicies(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if !keepCache {
+		if err := config.AWS.DeleteFromS3ByPrefix(filepath.Join(consts.AppsDir, appName), true); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// deleteResources deletes the kubernetes resources which have the labels, unless keep returns true for the resource's labels;
+// it continues when a resource can't be listed or deleted (so that as much as possible is cleaned up), and returns the first error
+func deleteResources(labels map[string]string, keep func(resourceLabels map[string]string) bool) error {
+	var firstErr error
+	recordErr := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	shouldDelete := func(resourceLabels map[string]string) bool {
+		return keep == nil || !keep(resourceLabels)
+	}
+
+	recordErr(routes().deleteRoutes(labels, shouldDelete))
+
+	services, err := config.Kubernetes.ListServicesByLabels(labels)
+	recordErr(err)
+	for _, service := range services {
+		if shouldDelete(service.Labels) {
+			_, err := config.Kubernetes.DeleteService(service.Name)
+			recordErr(err)
+		}
+	}
+
+	hpas, err := config.Kubernetes.ListHPAsByLabels(labels)
+	recordErr(err)
+	for _, hpa := range hpas {
+		if shouldDelete(hpa.Labels) {
+			_, err := config.Kubernetes.DeleteHPA(hpa.Name)
+			recordErr(err)
+		}
+	}
+
+	jobs, err := config.Kubernetes.ListJobsByLabels(labels)
+	recordErr(err)
+	for _, job := range jobs {
+		if shouldDelete(job.Labels) {
+			_, err := config.Kubernetes.DeleteJob(job.Name)
+			recordErr(err)
+		}
+	}
+
+	deployments, err := config.Kubernetes.ListDeploymentsByLabels(labels)
+	recordErr(err)
+	for _, deployment := range deployments {
+		if shouldDelete(deployment.Labels) {
+			_, err := config.Kubernetes.DeleteDeployment(deployment.Name)
+			recordErr(err)
+		}
+	}
+
+	secrets, err := config.Kubernetes.ListSecretsByLabels(labels)
+	recordErr(err)
+	for _, secret := range secrets {
+		if shouldDelete(secret.Labels) {
+			_, err := config.Kubernetes.DeleteSecret(secret.Name)
+			recordErr(err)
+		}
+	}
+
+	// the request authentications and authorization policies of APIs which require a JWT (the gateways' authorization policies are deleted with the routes)
+	requestAuthentications, err := config.Kubernetes.ListRequestAuthenticationsByLabels(consts.K8sNamespace, labels)
+	recordErr(err)
+	for _, requestAuthentication := range requestAuthentications {
+		if shouldDelete(requestAuthentication.GetLabels()) {
+			_, err := config.Kubernetes.DeleteRequestAuthentication(requestAuthentication.GetName(), consts.K8sNamespace)
+			recordErr(err)
+		}
+	}
+
+	authorizationPolicies, err := config.Kubernetes.ListAuthorizationPoliciesByLabels(consts.K8sNamespace, labels)
+	recordErr(err)
+	for _, authorizationPolicy := range authorizationPolicies {
+		if shouldDelete(authorizationPolicy.GetLabels()) {
+			_, err := config.Kubernetes.DeleteAuthorizationPolicy(authorizationPolicy.GetName(), consts.K8sNamespace)
+			recordErr(err)
+		}
+	}
+
+	configMaps, err := config.Kubernetes.ListConfigMapsByLabels(labels)
+	recordErr(err)
+	for _, configMap := range configMaps {
+		if shouldDelete(configMap.Labels) {
+			_, err := config.Kubernetes.DeleteConfigMap(configMap.Name)
+			recordErr(err)
+		}
+	}
+
+	return firstErr
+}
+
+func getPendingTeardowns() (map[string]string, error) {
+	data, err := config.Kubernetes.GetConfigMapData(_pendingTeardownsConfigMapName)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = make(map[string]string)
+	}
+	return data, nil
+}
+
+// updatePendingTeardown records (or, if done is true, clears) the app's teardown, so that it's retried if it doesn't complete
+func updatePendingTeardown(appName string, keepCache bool, done bool) error {
+	_pendingTeardownsMutex.Lock()
+	defer _pendingTeardownsMutex.Unlock()
+
+	pendingTeardowns, err := getPendingTeardowns()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := pendingTeardowns[appName]; done && !ok {
+		return nil
+	}
+
+	if done {
+		delete(pendingTeardowns, appName)
+	} else {
+		pendingTeardowns[appName] = strconv.FormatBool(keepCache)
+	}
+
+	_, err = config.Kubernetes.ApplyConfigMap(k8s.ConfigMap(&k8s.ConfigMapSpec{
+		Name:      _pendingTeardownsConfigMapName,
+		Namespace: consts.K8sNamespace,
+		Data:      pendingTeardowns,
+	}))
+	return err
+}
+
+// retryPendingTeardowns retries the teardowns of deleted apps which didn't complete, and deletes the kubernetes
+// resources of APIs which are no longer deployed (e.g. if the operator was restarted while an API was being deleted)
+func retryPendingTeardowns() error {
+	pendingTeardowns, err := getPendingTeardowns()
+	if err != nil {
+		return err
+	}
+
+	for appName, keepCacheStr := range pendingTeardowns {
+		if CurrentContext(appName) == nil {
+			keepCache, _ := strconv.ParseBool(keepCacheStr)
+			if err := teardownApp(appName, keepCache); err != nil {
+				_cronLog.With(logging.Fields{"app": appName}).Error(err, "teardown")
+				continue
+			}
+			_cronLog.With(logging.Fields{"app": appName}).Info("completed teardown of deleted deployment")
+		}
+		// the app was re-deployed, or its teardown completed
+		if err := updatePendingTeardown(appName, false, true); err != nil {
+			return err
+		}
+	}
+
+	return deleteOrphanedResources()
+}
+
+// deleteOrphanedResources deletes the kubernetes resources of APIs which aren't in the current contexts
+func deleteOrphanedResources() error {
+	ctxs := CurrentContexts()
+	if len(ctxs) == 0 {
+		return nil // don't delete everything if the current contexts weren't loaded (deleted deployments are cleaned up by their teardowns)
+	}
+
+	apiNames := make(map[string]map[string]bool, len(ctxs))
+	for _, ctx := range ctxs {
+		apiNames[ctx.App.Name] = make(map[string]bool, len(ctx.APIs))
+		for apiName := range ctx.APIs {
+			apiNames[ctx.App.Name][apiName] = true
+		}
+	}
+
+	return deleteResources(map[string]string{"workloadType": workloadTypeAPI}, func(resourceLabels map[string]string) bool {
+		return apiNames[resourceLabels["appName"]][resourceLabels["apiName"]]
+	})
+}
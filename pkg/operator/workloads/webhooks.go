@@ -0,0 +1,238 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	kapps "k8s.io/api/apps/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/lib/webhooks"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const _webhookAttempts = 3
+
+// APIs whose rollouts have started but haven't yet succeeded or failed (resourceID + workloadID -> app name)
+var _pendingRollouts = struct {
+	sync.Mutex
+	m map[string]string
+}{m: make(map[string]string)}
+
+// The replica count of each API deployment as of the last cron (deployment name -> replicas)
+var _deploymentReplicas = make(map[string]int32)
+
+// emitEvent sends the event to each of the cluster's and the API's webhooks which subscribe to it,
+// and publishes it to the cluster's SNS topic if it is an alert (in the background)
+func emitEvent(ctx *context.Context, api *context.API, event string, message string, details map[string]interface{}) {
+	payload := &webhooks.Payload{
+		Event:      event,
+		Timestamp:  time.Now(),
+		Cluster:    config.Cluster.ClusterName,
+		Deployment: ctx.App.Name,
+		API:        api.Name,
+		Message:    message,
+		Details:    details,
+	}
+
+	allWebhooks := append(config.Cluster.Webhooks[:len(config.Cluster.Webhooks):len(config.Cluster.Webhooks)], api.Webhooks...)
+	for _, webhook := range allWebhooks {
+		if webhook.Subscribes(event) {
+			go sendWebhook(webhook, payload)
+		}
+	}
+
+	if config.Cluster.SNSTopicARN != nil && _alertEvents.Has(event) {
+		go publishAlert(*config.Cluster.SNSTopicARN, payload)
+	}
+}
+
+func sendWebhook(webhook *webhooks.Webhook, payload *webhooks.Payload) {
+	secret, err := ResolveSecretValue(webhook.Secret)
+	if err != nil {
+		logging.With(webhookLogFields(payload)).Error(err, "webhook")
+		return
+	}
+
+	for attempt := 1; attempt <= _webhookAttempts; attempt++ {
+		err = webhooks.Send(webhook.URL, secret, payload)
+		if err == nil {
+			return
+		}
+		if attempt < _webhookAttempts {
+			time.Sleep(time.Duration(attempt*attempt) * time.Second)
+		}
+	}
+
+	logging.With(webhookLogFields(payload)).Error(err, "webhook")
+}
+
+func webhookLogFields(payload *webhooks.Payload) logging.Fields {
+	return logging.Fields{"event": payload.Event, "app": payload.Deployment, "api": payload.API}
+}
+
+func rolloutKey(api *context.API) string {
+	return api.ID + api.WorkloadID
+}
+
+// emitDeployStartedEvents emits an event for each API in ctx which is new or is being redeployed
+func emitDeployStartedEvents(prevCtx *context.Context, ctx *context.Context) {
+	_pendingRollouts.Lock()
+	defer _pendingRollouts.Unlock()
+
+	for apiName, api := range ctx.APIs {
+		if prevCtx != nil {
+			if prevAPI, ok := prevCtx.APIs[apiName]; ok && prevAPI.ID == api.ID && prevAPI.WorkloadID == api.WorkloadID && prevAPI.Compute.ID() == api.Compute.ID() {
+				continue
+			}
+		}
+
+		_pendingRollouts.m[rolloutKey(api)] = ctx.App.Name
+		emitEvent(ctx, api, webhooks.DeployStartedEvent, fmt.Sprintf("%s is being deployed", api.Name), map[string]interface{}{
+			"revision": api.Revision,
+			"owner":    api.Owner,
+		})
+	}
+}
+
+// emitDeployOutcomeEvents emits an event for each pending rollout which has become live or has failed
+func emitDeployOutcomeEvents() error {
+	_pendingRollouts.Lock()
+	defer _pendingRollouts.Unlock()
+
+	appNames := make(map[string]bool)
+	for _, appName := range _pendingRollouts.m {
+		appNames[appName] = true
+	}
+
+	for appName := range appNames {
+		ctx := CurrentContext(appName)
+		if ctx == nil {
+			continue
+		}
+
+		dataStatuses, err := GetCurrentDataStatuses(ctx)
+		if err != nil {
+			return err
+		}
+		apiStatuses, _, err := GetCurrentAPIAndGroupStatuses(dataStatuses, ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, api := range ctx.APIs {
+			if _, ok := _pendingRollouts.m[rolloutKey(api)]; !ok {
+				continue
+			}
+
+			apiStatus := apiStatuses[api.ID]
+			if apiStatus == nil {
+				continue
+			}
+
+			switch {
+			case apiStatus.Code == resource.StatusLive:
+				emitEvent(ctx, api, webhooks.DeploySucceededEvent, fmt.Sprintf("%s is live", api.Name), map[string]interface{}{
+					"revision": api.Revision,
+				})
+			case isFailedRolloutStatus(apiStatus.Code):
+				emitEvent(ctx, api, webhooks.DeployFailedEvent, fmt.Sprintf("%s failed to deploy: %s", api.Name, apiStatus.Code.Message()), map[string]interface{}{
+					"revision": api.Revision,
+					"status":   apiStatus.Code.String(),
+				})
+			default:
+				continue
+			}
+
+			delete(_pendingRollouts.m, rolloutKey(api))
+		}
+	}
+
+	// forget rollouts which have been replaced or deleted
+	currentRolloutKeys := make(map[string]bool)
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			currentRolloutKeys[rolloutKey(api)] = true
+		}
+	}
+	for key := range _pendingRollouts.m {
+		if !currentRolloutKeys[key] {
+			delete(_pendingRollouts.m, key)
+		}
+	}
+
+	return nil
+}
+
+// emitScaledEvents emits an event for each API deployment whose replica count has changed since the last cron
+func emitScaledEvents(deployments []kapps.Deployment) {
+	deploymentReplicas := make(map[string]int32, len(deployments))
+
+	for _, deployment := range deployments {
+		if deployment.Spec.Replicas == nil {
+			continue
+		}
+		replicas := *deployment.Spec.Replicas
+		deploymentReplicas[deployment.Name] = replicas
+
+		prevReplicas, ok := _deploymentReplicas[deployment.Name]
+		if !ok || prevReplicas == replicas {
+			continue
+		}
+
+		ctx := CurrentContext(deployment.Labels["appName"])
+		if ctx == nil {
+			continue
+		}
+		api := ctx.APIs[deployment.Labels["apiName"]]
+		if api == nil || api.ID != deployment.Labels["resourceID"] {
+			continue
+		}
+
+		emitEvent(ctx, api, webhooks.ScaledEvent, fmt.Sprintf("%s scaled from %d to %d replicas", api.Name, prevReplicas, replicas), map[string]interface{}{
+			"from": prevReplicas,
+			"to":   replicas,
+		})
+
+		if minReplicas, maxReplicas := apiReplicaBounds(api); replicas == maxReplicas && minReplicas != maxReplicas {
+			emitEvent(ctx, api, webhooks.MaxReplicasEvent, fmt.Sprintf("%s has been autoscaled to its max_replicas (%d)", api.Name, maxReplicas), map[string]interface{}{
+				"max_replicas": maxReplicas,
+			})
+		}
+	}
+
+	_deploymentReplicas = deploymentReplicas
+}
+
+func updateWebhookEvents() error {
+	if err := emitDeployOutcomeEvents(); err != nil {
+		return err
+	}
+
+	deployments, err := config.Kubernetes.ListDeploymentsByLabel("workloadType", workloadTypeAPI)
+	if err != nil {
+		return err
+	}
+	emitScaledEvents(deployments)
+
+	return nil
+}
@@ -18,44 +18,19 @@ package workloads
 
 import (
 	"fmt"
-	"path/filepath"
 
 	kresource "k8s.io/apimachinery/pkg/api/resource"
 
-	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
-	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/operator/api/context"
 	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
 	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
 )
 
-/*
-CPU Reservations:
-
-FluentD 200
-StatsD 100
-KubeProxy 100
-Reserved (150 + 150) see eks.yaml for details
-Buffer (100)
-*/
-var cortexCPUReserve = kresource.MustParse("800m")
-
-/*
-Memory Reservations:
-
-FluentD 200
-StatsD 100
-Reserved (300 + 300 + 200) see eks.yaml for details
-Buffer (100)
-*/
-var cortexMemReserve = kresource.MustParse("1200Mi")
-
-var nvidiaCPUReserve = kresource.MustParse("100m")
-var nvidiaMemReserve = kresource.MustParse("100Mi")
-
 func Init() error {
 	err := reloadCurrentContexts()
 	if err != nil {
@@ -65,6 +40,14 @@ func Init() error {
 	if err != nil {
 		return errors.Wrap(err, "init")
 	}
+	err = applyPriorityClasses()
+	if err != nil {
+		return errors.Wrap(err, "init")
+	}
+	err = applyPeerAuthentication()
+	if err != nil {
+		return errors.Wrap(err, "init")
+	}
 
 	go cronRunner()
 
@@ -98,19 +81,45 @@ func Run(ctx *context.Context) error {
 		return err
 	}
 
+	// the app may have been re-deployed before its previous teardown completed
+	err := updatePendingTeardown(ctx.App.Name, false, true)
+	if err != nil {
+		return err
+	}
+
+	err = updateAPISecrets(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = updateAPIConfigMaps(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = updateAPIFileConfigMaps(ctx)
+	if err != nil {
+		return err
+	}
+
 	prevCtx := CurrentContext(ctx.App.Name)
-	err := deleteOldDataJobs(prevCtx)
+	err = deleteOldDataJobs(prevCtx)
 	if err != nil {
 		return err
 	}
 
 	deleteOldAPIs(ctx)
+	if isIstioBackend() {
+		updateGatewayAuthorizationPolicies()
+	}
 
 	err = setCurrentContext(ctx)
 	if err != nil {
 		return err
 	}
 
+	emitDeployStartedEvents(prevCtx, ctx)
+
 	resourceWorkloadIDs := ctx.ComputedResourceResourceWorkloadIDs()
 	err = uploadLatestWorkloadIDs(resourceWorkloadIDs, ctx.App.Name)
 	if err != nil {
@@ -154,29 +163,15 @@ func DeleteApp(appName string, keepCache bool) bool {
 	uncacheDataSavedStatuses(nil, appName)
 	uncacheLatestWorkloadIDs(nil, appName)
 
-	virtualServices, _ := config.Kubernetes.ListVirtualServicesByLabel(consts.K8sNamespace, "appName", appName)
-	for _, virtualService := range virtualServices {
-		config.Kubernetes.DeleteVirtualService(virtualService.GetName(), consts.K8sNamespace)
+	// the teardown is recorded so that it's retried by the cron if it fails (or if the operator restarts)
+	if err := updatePendingTeardown(appName, keepCache, false); err != nil {
+		logging.With(logging.Fields{"app": appName}).Error(err, "teardown")
 	}
-	services, _ := config.Kubernetes.ListServicesByLabel("appName", appName)
-	for _, service := range services {
-		config.Kubernetes.DeleteService(service.Name)
-	}
-	hpas, _ := config.Kubernetes.ListHPAsByLabel("appName", appName)
-	for _, hpa := range hpas {
-		config.Kubernetes.DeleteHPA(hpa.Name)
-	}
-	jobs, _ := config.Kubernetes.ListJobsByLabel("appName", appName)
-	for _, job := range jobs {
-		config.Kubernetes.DeleteJob(job.Name)
-	}
-	deployments, _ := config.Kubernetes.ListDeploymentsByLabel("appName", appName)
-	for _, deployment := range deployments {
-		config.Kubernetes.DeleteDeployment(deployment.Name)
-	}
-
-	if !keepCache {
-		config.AWS.DeleteFromS3ByPrefix(filepath.Join(consts.AppsDir, appName), true)
+	if err := teardownApp(appName, keepCache); err != nil {
+		telemetry.Error(err)
+		logging.With(logging.Fields{"app": appName}).Error(err, "teardown")
+	} else if err := updatePendingTeardown(appName, keepCache, true); err != nil {
+		logging.With(logging.Fields{"app": appName}).Error(err, "teardown")
 	}
 
 	return wasDeployed
@@ -327,78 +322,194 @@ func GetDeploymentStatus(appName string) (resource.DeploymentStatus, error) {
 }
 
 func ValidateDeploy(ctx *context.Context) error {
+	for _, api := range ctx.APIs {
+		if err := routes().validateAPI(api); err != nil {
+			return err
+		}
+		if err := validateTritonCompute(api); err != nil {
+			return errors.Wrap(err, userconfig.Identify(api), userconfig.ComputeKey, userconfig.GPUKey)
+		}
+	}
+
 	if err := CheckAPIEndpointCollisions(ctx); err != nil {
 		return err
 	}
 
-	maxCPU := config.Cluster.InstanceMetadata.CPU
-	maxCPU.Sub(cortexCPUReserve)
-	maxMem, err := UpdateMemoryCapacityConfigMap()
+	nodeGroups, err := GetNodeGroupCapacities()
 	if err != nil {
-		return errors.Wrap(err, "validating memory constraint")
-	}
-	maxMem.Sub(cortexMemReserve)
-	maxGPU := config.Cluster.InstanceMetadata.GPU
-	if maxGPU > 0 {
-		// Reserve resources for nvidia device plugin daemonset
-		maxCPU.Sub(nvidiaCPUReserve)
-		maxMem.Sub(nvidiaMemReserve)
+		return errors.Wrap(err, "validating compute constraints")
 	}
 
 	for _, api := range ctx.APIs {
-		if maxCPU.Cmp(api.Compute.CPU.Quantity) < 0 {
-			return errors.Wrap(ErrorNoAvailableNodeComputeLimit("CPU", api.Compute.CPU.String(), maxCPU.String()), userconfig.Identify(api))
+		if err := validateAPICompute(ctx, api, nodeGroups); err != nil {
+			return errors.Wrap(err, userconfig.Identify(api))
 		}
-		if api.Compute.Mem != nil {
-			if maxMem.Cmp(api.Compute.Mem.Quantity) < 0 {
-				return errors.Wrap(ErrorNoAvailableNodeComputeLimit("Memory", api.Compute.Mem.String(), maxMem.String()), userconfig.Identify(api))
-			}
+	}
+	return nil
+}
+
+// validateTritonCompute checks that triton APIs request a gpu, unless the cluster is configured with a cpu-only build of Triton
+func validateTritonCompute(api *context.API) error {
+	if api.Predictor.Type != userconfig.TritonPredictorType || api.Compute.GPU > 0 {
+		return nil
+	}
+	if config.Cluster.ImageTritonServeCPU == nil {
+		return ErrorTritonGPURequired()
+	}
+	return nil
+}
+
+// validateAPICompute succeeds if at least one node group of the API's architecture can fit the API's replicas and init containers,
+// since the cluster autoscaler will scale up whichever node group can schedule them
+func validateAPICompute(ctx *context.Context, api *context.API, allNodeGroups []*NodeGroupCapacity) error {
+	cpu, mem, gpu := apiPodRequests(ctx, api)
+
+	var nodeGroups []*NodeGroupCapacity
+	for _, nodeGroup := range allNodeGroups {
+		if nodeGroup.Arch == api.Compute.Arch.String() {
+			nodeGroups = append(nodeGroups, nodeGroup)
+		}
+	}
+	if len(nodeGroups) == 0 {
+		return errors.Wrap(ErrorNoNodeGroupForArch(api.Compute.Arch.String()), userconfig.ComputeKey, userconfig.ArchKey)
+	}
+
+	var nodeGroupErr error
+	for _, nodeGroup := range nodeGroups {
+		nodeGroupErr = validateNodeGroupCompute(api, nodeGroup, cpu, mem, gpu)
+		if nodeGroupErr == nil {
+			return nil
 		}
-		gpu := api.Compute.GPU
-		if gpu > maxGPU {
-			return errors.Wrap(ErrorNoAvailableNodeComputeLimit("GPU", fmt.Sprintf("%d", gpu), fmt.Sprintf("%d", maxGPU)), userconfig.Identify(api))
+	}
+
+	if len(nodeGroups) == 1 {
+		return nodeGroupErr
+	}
+
+	reqStr := fmt.Sprintf("%s CPU", cpu.String())
+	if mem != nil {
+		reqStr += fmt.Sprintf(", %s Memory", mem.String())
+	}
+	if gpu > 0 {
+		reqStr += fmt.Sprintf(", %d GPU", gpu)
+	}
+	return ErrorNoNodeGroupComputeLimit(reqStr, nodeGroupsStr(nodeGroups))
+}
+
+func validateNodeGroupCompute(api *context.API, nodeGroup *NodeGroupCapacity, cpu kresource.Quantity, mem *kresource.Quantity, gpu int64) error {
+	if nodeGroup.CPU.Cmp(cpu) < 0 {
+		return ErrorNoAvailableNodeComputeLimit("CPU", cpu.String(), nodeGroup.CPU.String())
+	}
+	if mem != nil && nodeGroup.Mem.Cmp(*mem) < 0 {
+		return ErrorNoAvailableNodeComputeLimit("Memory", mem.String(), nodeGroup.Mem.String())
+	}
+	if gpu > nodeGroup.GPU {
+		return ErrorNoAvailableNodeComputeLimit("GPU", fmt.Sprintf("%d", gpu), fmt.Sprintf("%d", nodeGroup.GPU))
+	}
+	for _, container := range api.Init {
+		if err := validateContainerCompute(container, nodeGroup.CPU, nodeGroup.Mem); err != nil {
+			return errors.Wrap(err, userconfig.InitKey, container.Name)
 		}
 	}
 	return nil
 }
 
+func validateContainerCompute(container *userconfig.Container, maxCPU kresource.Quantity, maxMem kresource.Quantity) error {
+	if container.Compute == nil {
+		return nil
+	}
+	if container.Compute.CPU != nil && maxCPU.Cmp(container.Compute.CPU.Quantity) < 0 {
+		return ErrorNoAvailableNodeComputeLimit("CPU", container.Compute.CPU.String(), maxCPU.String())
+	}
+	if container.Compute.Mem != nil && maxMem.Cmp(container.Compute.Mem.Quantity) < 0 {
+		return ErrorNoAvailableNodeComputeLimit("Memory", container.Compute.Mem.String(), maxMem.String())
+	}
+	return nil
+}
+
 func CheckAPIEndpointCollisions(ctx *context.Context) error {
-	apiEndpoints := map[string]string{} // endpoint -> API identifiction string
+	backend := routes()
+
+	apiEndpoints := map[string]map[string]string{} // listener -> endpoint -> API identifiction string
 	for _, api := range ctx.APIs {
-		apiEndpoints[*api.Endpoint] = userconfig.Identify(api)
+		listener := backend.listener(api)
+		if apiEndpoints[listener] == nil {
+			apiEndpoints[listener] = map[string]string{}
+		}
+		for _, endpoint := range api.Endpoints() {
+			apiEndpoints[listener][endpoint] = userconfig.Identify(api)
+		}
 	}
 
-	virtualServices, err := config.Kubernetes.ListVirtualServices(consts.K8sNamespace, nil)
+	apiRoutes, err := backend.listRoutes()
 	if err != nil {
 		return err
 	}
 
-	for _, virtualService := range virtualServices {
-		gateways, err := k8s.GetVirtualServiceGateways(&virtualService)
-		if err != nil {
-			return err
-		}
-		if !gateways.Has("apis-gateway") {
-			continue
-		}
-
+	for _, route := range apiRoutes {
 		// Collisions within a deployment will already have been caught by config validation
-		labels := virtualService.GetLabels()
+		labels := route.labels
 		if labels["appName"] == ctx.App.Name {
 			continue
 		}
 
-		endpoints, err := k8s.GetVirtualServiceEndpoints(&virtualService)
-		if err != nil {
-			return err
+		// APIs on different listeners (e.g. gateways) are served by different load balancers, so their endpoints can't collide
+		for listener, listenerEndpoints := range apiEndpoints {
+			if !route.listeners.Has(listener) {
+				continue
+			}
+			for endpoint := range route.endpoints {
+				if apiIdentifier, ok := listenerEndpoints[endpoint]; ok {
+					return errors.Wrap(ErrorDuplicateEndpointOtherDeployment(labels["appName"], labels["apiName"]), apiIdentifier, userconfig.EndpointKey, endpoint)
+				}
+			}
 		}
+	}
+
+	return nil
+}
 
-		for endpoint := range endpoints {
-			if apiIdentifier, ok := apiEndpoints[endpoint]; ok {
-				return errors.Wrap(ErrorDuplicateEndpointOtherDeployment(labels["appName"], labels["apiName"]), apiIdentifier, userconfig.EndpointKey, endpoint)
+// apiPodRequests returns the compute which each of the API's replicas requests: sidecars (including the response cache) run alongside the predictor, so their requests
+// are added to the predictor's (as are the requests of the steps' APIs of colocated pipelines, whose containers run in the pipeline's pods)
+func apiPodRequests(ctx *context.Context, api *context.API) (kresource.Quantity, *kresource.Quantity, int64) {
+	cpu := api.Compute.CPU.Quantity.DeepCopy()
+	var mem *kresource.Quantity
+	if api.Compute.Mem != nil {
+		apiMem := api.Compute.Mem.Quantity.DeepCopy()
+		mem = &apiMem
+	}
+	sidecarCPU, sidecarMem := api.Sidecars.TotalCompute()
+	if sidecarCPU != nil {
+		cpu.Add(sidecarCPU.Quantity)
+	}
+	if sidecarMem != nil {
+		if mem == nil {
+			mem = &kresource.Quantity{}
+		}
+		mem.Add(sidecarMem.Quantity)
+	}
+	if api.Cache != nil {
+		cpu.Add(cacheCPURequest)
+		if mem == nil {
+			mem = &kresource.Quantity{}
+		}
+		mem.Add(cacheMemRequest)
+	}
+	gpu := api.Compute.GPU
+
+	if api.Pipeline != nil && api.Pipeline.Mode == userconfig.ColocatedPipelineMode {
+		for _, apiName := range api.Pipeline.StepAPINames() {
+			stepCPU, stepMem, stepGPU := apiPodRequests(ctx, ctx.APIs[apiName])
+			cpu.Add(stepCPU)
+			if stepMem != nil {
+				if mem == nil {
+					mem = &kresource.Quantity{}
+				}
+				mem.Add(*stepMem)
 			}
+			gpu += stepGPU
 		}
 	}
 
-	return nil
+	return cpu, mem, gpu
 }
@@ -22,8 +22,9 @@ import (
 )
 
 const (
-	workloadTypeAPI = "api"
-	workloadTypeHPA = "hpa"
+	workloadTypeAPI               = "api"
+	workloadTypeHPA               = "hpa"
+	workloadTypeRequirementsCheck = "requirements-check"
 )
 
 type Workload interface {
@@ -181,3 +182,8 @@ func singleBaseWorkload(res context.ComputedResource, appName string, workloadTy
 	bw.AddResource(res)
 	return bw
 }
+
+// IsAPIResource returns whether the labels belong to a Kubernetes resource which the operator manages for an API
+func IsAPIResource(labels map[string]string) bool {
+	return labels["workloadType"] == workloadTypeAPI
+}
@@ -53,6 +53,10 @@ def start(args):
                 src = os.path.join(dir_path, entries[0])
                 os.rename(src, dest)
 
+    for file in download_config.get("files") or []:
+        with open(file["path"], "w") as f:
+            f.write(file["contents"])
+
     if download_config.get("last_log", "") != "":
         cx_logger().info(download_config["last_log"])
 
@@ -15,7 +15,21 @@
 
 import os
 import base64
+import gzip
+import hashlib
+import json
+import math
+import queue
+import re
+import signal
+import socket
+import sys
+import threading
 import time
+from http.cookies import SimpleCookie
+
+import brotli
+from flask import jsonify, g
 
 from cortex.lib.exceptions import UserException, CortexException
 from cortex.lib.log import cx_logger
@@ -26,6 +40,527 @@ API_SUMMARY_MESSAGE = (
 )
 
 
+def is_prediction_request(request):
+    # requests for explanations are served (and limited) like predictions
+    return request.method == "POST" and request.path in ("/predict", "/explain")
+
+
+def is_cors_configured(api):
+    # if the API configures CORS, the CORS headers are added by the load balancer
+    if api is None or api.get("networking") is None:
+        return False
+    return api["networking"].get("cors") is not None
+
+
+def serve_processes(port, processes_per_replica, start_process):
+    # start_process(sock, health_check_file) loads the predictor, creates health_check_file, and
+    # serves requests on sock (which is bound to the port and shared by the replica's processes)
+    sock = socket.socket(socket.AF_INET, socket.SOCK_STREAM)
+    sock.setsockopt(socket.SOL_SOCKET, socket.SO_REUSEADDR, 1)
+    sock.bind(("0.0.0.0", port))
+
+    if processes_per_replica == 1:
+        start_process(sock, "/health_check.txt")
+        return
+
+    # each process loads its own predictor, since models and gpu contexts can't be shared by forks
+    health_check_files = []
+    pids = []
+    for i in range(processes_per_replica):
+        health_check_file = "/health_check_{}.txt".format(i)
+        health_check_files.append(health_check_file)
+        pid = os.fork()
+        if pid == 0:
+            try:
+                start_process(sock, health_check_file)
+            finally:
+                os._exit(1)
+        pids.append(pid)
+
+    threading.Thread(target=_await_processes, args=(health_check_files,), daemon=True).start()
+
+    # if any of the processes exits, the container is restarted
+    pid, _ = os.wait()
+    cx_logger().error("process {} exited".format(pid))
+    for other_pid in pids:
+        if other_pid != pid:
+            try:
+                os.kill(other_pid, signal.SIGTERM)
+            except ProcessLookupError:
+                pass
+    sys.exit(1)
+
+
+def _await_processes(health_check_files):
+    # the replica is live once each of its processes is
+    while not all(os.path.isfile(f) for f in health_check_files):
+        time.sleep(1)
+    open("/health_check.txt", "a").close()
+
+
+def configure_batching_threads(api, waitress_kwargs):
+    # a batch can only fill up if at least max_batch_size requests are handled at once
+    batching = api["predictor"].get("batching")
+    if batching is None:
+        return
+    threads = waitress_kwargs.get("threads", 4)  # waitress's default
+    waitress_kwargs["threads"] = max(threads, batching["max_batch_size"])
+
+
+def configure_request_size(app, api, waitress_kwargs):
+    # limit the size of request bodies (waitress spools large bodies to disk, so streamed requests aren't read into memory)
+    networking = api.get("networking") or {}
+    max_request_size = networking.get("max_request_size")
+    if max_request_size is None:
+        return
+
+    app.config["MAX_CONTENT_LENGTH"] = max_request_size
+    waitress_kwargs.setdefault("max_request_body_size", max_request_size)
+
+
+def compress_response(api, request, response):
+    networking = api.get("networking") or {}
+    compression = networking.get("compression")
+    if compression is None or not (compression.get("gzip") or compression.get("brotli")):
+        return response
+
+    if response.direct_passthrough or "Content-Encoding" in response.headers:
+        return response
+    if response.status_code < 200 or response.status_code >= 300:
+        return response
+
+    response.headers.add("Vary", "Accept-Encoding")
+
+    data = response.get_data()
+    if len(data) < compression.get("min_size", 0):
+        return response
+
+    if compression.get("brotli") and request.accept_encodings.quality("br") > 0:
+        response.set_data(brotli.compress(data))
+        response.headers["Content-Encoding"] = "br"
+    elif compression.get("gzip") and request.accept_encodings.quality("gzip") > 0:
+        response.set_data(gzip.compress(data))
+        response.headers["Content-Encoding"] = "gzip"
+
+    return response
+
+
+class RateLimiter:
+    # token buckets per client; buckets which have refilled are dropped to bound memory usage
+    _prune_threshold = 10000
+
+    def __init__(self, requests_per_second, burst):
+        self.rate = requests_per_second
+        self.burst = burst
+        self.buckets = {}  # client -> (tokens, last update time)
+        self.lock = threading.Lock()
+
+    def acquire(self, client):
+        # returns the number of seconds until the client may retry, or 0 if the request is allowed
+        now = time.time()
+        with self.lock:
+            tokens, last = self.buckets.get(client, (self.burst, now))
+            tokens = min(self.burst, tokens + (now - last) * self.rate)
+
+            if tokens >= 1:
+                self.buckets[client] = (tokens - 1, now)
+                retry_after = 0
+            else:
+                self.buckets[client] = (tokens, now)
+                retry_after = (1 - tokens) / self.rate
+
+            if len(self.buckets) > self._prune_threshold:
+                self._prune(now)
+
+        return retry_after
+
+    def _prune(self, now):
+        for client, (tokens, last) in list(self.buckets.items()):
+            if tokens + (now - last) * self.rate >= self.burst:
+                del self.buckets[client]
+
+
+def get_rate_limiter(api):
+    networking = api.get("networking") or {}
+    rate_limit = networking.get("rate_limit")
+    if rate_limit is None:
+        return None
+    return RateLimiter(rate_limit["requests_per_second"], rate_limit["burst"])
+
+
+def rate_limit_request(api, rate_limiter, request):
+    # returns a response if the request exceeds the client's rate limit, otherwise None
+    if rate_limiter is None:
+        return None
+
+    client_header = api["networking"]["rate_limit"].get("client_header")
+    if client_header is not None:
+        client = request.headers.get(client_header, "")
+    else:
+        client = request.access_route[0] if len(request.access_route) > 0 else ""
+
+    retry_after = rate_limiter.acquire(client)
+    if retry_after == 0:
+        return None
+
+    response = jsonify(error="rate limit exceeded")
+    response.status_code = 429
+    response.headers["Retry-After"] = str(math.ceil(retry_after))
+    return response
+
+
+class LoadShedder:
+    # requests beyond the process's concurrency wait for a slot in a queue of up to max_queue_depth requests,
+    # and requests which arrive when the queue is full are rejected right away rather than waiting behind it;
+    # low priority requests are rejected once the queue is half full, and high priority requests are always queued
+    HIGH_PRIORITY = "high"
+    LOW_PRIORITY = "low"
+
+    def __init__(self, concurrency, max_queue_depth, priority_header=None):
+        self.concurrency = concurrency
+        self.max_queue_depth = max_queue_depth
+        self.priority_header = priority_header
+        self.in_flight = 0  # requests which are being handled or are queued
+        self.lock = threading.Lock()
+        self.slots = threading.Semaphore(concurrency)
+
+    def priority(self, headers):
+        if self.priority_header is None:
+            return None
+        return headers.get(self.priority_header, "").strip().lower()
+
+    def limit(self, priority):
+        # the number of requests in flight at which requests with this priority are shed (None if they're never shed)
+        if priority == self.HIGH_PRIORITY:
+            return None
+        if priority == self.LOW_PRIORITY:
+            return self.concurrency + self.max_queue_depth // 2
+        return self.concurrency + self.max_queue_depth
+
+    def admit(self, headers):
+        # returns False if the request should be shed, otherwise waits for a slot and returns True
+        limit = self.limit(self.priority(headers))
+        with self.lock:
+            if limit is not None and self.in_flight >= limit:
+                return False
+            self.in_flight += 1
+
+        self.slots.acquire()
+        return True
+
+    def release(self):
+        self.slots.release()
+        with self.lock:
+            self.in_flight -= 1
+
+
+# threads which respond to requests that arrive while the queue is full (otherwise they'd wait for a queued request to finish)
+_load_shedding_threads = 4
+
+
+def configure_load_shedding(api, waitress_kwargs):
+    # must be called after the rest of the waitress threads are configured, since they determine the process's concurrency
+    networking = api.get("networking") or {}
+    load_shedding = networking.get("load_shedding")
+    if load_shedding is None:
+        return None
+
+    concurrency = waitress_kwargs.get("threads", 4)  # waitress's default
+    max_queue_depth = load_shedding["max_queue_depth"]
+
+    # queued requests hold one of waitress's threads while they wait for a slot
+    waitress_kwargs["threads"] = concurrency + max_queue_depth + _load_shedding_threads
+    return LoadShedder(concurrency, max_queue_depth, load_shedding.get("priority_header"))
+
+
+def shed_request(api, load_shedder, request):
+    # returns a response if the request is shed, otherwise None (once the request has a slot, which is freed by release_request())
+    if load_shedder is None:
+        return None
+
+    if load_shedder.admit(request.headers):
+        g.load_shedding_slot = True
+        return None
+
+    response = jsonify(error="the api is overloaded, please try again later")
+    response.status_code = api["networking"]["load_shedding"]["status_code"]
+    response.headers["Retry-After"] = "1"
+    return response
+
+
+def release_request(load_shedder):
+    if g.pop("load_shedding_slot", False):
+        load_shedder.release()
+
+
+VARIANT_HEADER = "X-Cortex-Variant"
+
+
+class VariantAssigner:
+    # requests are assigned to variants by a consistent hash of the experiment's header or cookie,
+    # so that requests with the same value always get the same variant
+    # (requests without a value get the first variant)
+
+    def __init__(self, experiment):
+        self.header = experiment.get("header")
+        self.cookie = experiment.get("cookie")
+        self.buckets = []  # (upper bound of the variant's hash buckets, variant name)
+        upper = 0
+        for variant in experiment["variants"]:
+            if variant["weight"] == 0:
+                continue
+            upper += variant["weight"]
+            self.buckets.append((upper, variant["name"]))
+
+    def assign(self, headers):
+        if self.header is not None:
+            value = headers.get(self.header)
+        else:
+            cookie = SimpleCookie(headers.get("Cookie", "")).get(self.cookie)
+            value = cookie.value if cookie is not None else None
+
+        if not value:
+            return self.buckets[0][1]
+
+        bucket = int(hashlib.sha256(value.encode("utf-8")).hexdigest(), 16) % 100
+        for upper, name in self.buckets:
+            if bucket < upper:
+                return name
+        return self.buckets[-1][1]  # unexpected, since the weights add up to 100
+
+
+def get_variant_assigner(api):
+    if api.get("experiment") is None:
+        return None
+    return VariantAssigner(api["experiment"])
+
+
+def variant_configs(api):
+    # returns variant name -> predictor config (for variants which receive traffic)
+    configs = {}
+    for variant in api["experiment"]["variants"]:
+        if variant["weight"] == 0:
+            continue
+        config = dict(api["predictor"]["config"] or {})
+        config.update(variant["config"] or {})
+        configs[variant["name"]] = config
+    return configs
+
+
+def forwards_headers(api):
+    return len(api["networking"].get("forward_headers") or []) > 0
+
+
+def forwarded_metadata(api, headers):
+    """Returns the metadata which is passed to the predictor (None if the API doesn't forward headers)
+
+    Raises a ValueError if a required header is missing, or if a header doesn't match its pattern
+    """
+    if not forwards_headers(api):
+        return None
+
+    metadata = {}
+    for forward_header in api["networking"]["forward_headers"]:
+        value = headers.get(forward_header["header"])
+        if value is None or value == "":
+            if forward_header["required"]:
+                raise ValueError("missing required header: {}".format(forward_header["header"]))
+            value = None
+        elif forward_header.get("pattern") is not None:
+            if re.fullmatch(forward_header["pattern"], value) is None:
+                raise ValueError(
+                    "invalid value for header {}: must match {}".format(
+                        forward_header["header"], forward_header["pattern"]
+                    )
+                )
+        metadata[forward_header["name"]] = value
+    return metadata
+
+
+def call_predict(predict_fn, payload, metadata):
+    if metadata is None:
+        return predict_fn(payload)
+    return predict_fn(payload, metadata)
+
+
+class Batcher:
+    # concurrent predictions are queued, and the queue is predicted as one batch once it has
+    # max_batch_size payloads, or once batch_interval has passed since its first payload was queued
+
+    def __init__(self, predict_fn, max_batch_size, batch_interval, with_metadata=False):
+        # predict_fn receives a list of payloads (and a list of their metadata if with_metadata),
+        # and returns a list of predictions
+        self.predict_fn = predict_fn
+        self.max_batch_size = max_batch_size
+        self.batch_interval = batch_interval  # seconds
+        self.with_metadata = with_metadata
+        self.queue = queue.Queue()
+        thread = threading.Thread(target=self._run, daemon=True)
+        thread.start()
+
+    def predict(self, payload, metadata=None):
+        item = _BatchItem(payload, metadata)
+        self.queue.put(item)
+        item.done.wait()
+        if item.error is not None:
+            raise item.error
+        return item.prediction
+
+    def _run(self):
+        while True:
+            batch = [self.queue.get()]
+            deadline = time.time() + self.batch_interval
+            while len(batch) < self.max_batch_size:
+                timeout = deadline - time.time()
+                if timeout <= 0:
+                    break
+                try:
+                    batch.append(self.queue.get(timeout=timeout))
+                except queue.Empty:
+                    break
+            self._predict(batch)
+
+    def _predict(self, batch):
+        try:
+            payloads = [item.payload for item in batch]
+            if self.with_metadata:
+                predictions = self.predict_fn(payloads, [item.metadata for item in batch])
+            else:
+                predictions = self.predict_fn(payloads)
+            if not isinstance(predictions, (list, tuple)) or len(predictions) != len(batch):
+                raise UserException(
+                    "predict() must return a list with one prediction per payload when batching is "
+                    + "configured (received {} payloads)".format(len(batch))
+                )
+        except Exception as e:
+            for item in batch:
+                item.error = e
+                item.done.set()
+            return
+
+        for item, prediction in zip(batch, predictions):
+            item.prediction = prediction
+            item.done.set()
+
+
+class _BatchItem:
+    def __init__(self, payload, metadata):
+        self.payload = payload
+        self.metadata = metadata
+        self.prediction = None
+        self.error = None
+        self.done = threading.Event()
+
+
+def get_batcher(api, predict_fn):
+    batching = api["predictor"].get("batching")
+    if batching is None:
+        return None
+    # durations are serialized in nanoseconds
+    return Batcher(
+        predict_fn,
+        batching["max_batch_size"],
+        batching["batch_interval"] / 1e9,
+        with_metadata=forwards_headers(api),
+    )
+
+
+def resolve_config_secrets(config):
+    # replace secret references (e.g. ${secret:my-secret}) with the values resolved by the operator
+    if config is None:
+        return config
+
+    secrets_json = os.environ.get("CORTEX_PREDICTOR_SECRETS")
+    if secrets_json is None:
+        return config
+
+    try:
+        secrets = json.loads(secrets_json)
+    except Exception as e:
+        raise CortexException("unable to parse predictor secrets") from e
+
+    return _substitute_secrets(config, secrets)
+
+
+def _substitute_secrets(value, secrets):
+    if isinstance(value, dict):
+        return {k: _substitute_secrets(v, secrets) for k, v in value.items()}
+    if isinstance(value, list):
+        return [_substitute_secrets(v, secrets) for v in value]
+    if isinstance(value, str) and value in secrets:
+        return secrets[value]
+    return value
+
+
+def predictor_config(api):
+    # the config is read from the API's config map if it can be reloaded (the operator keeps the
+    # config map up to date, whereas the context has the config which the replica started with)
+    config_path = os.environ.get("CORTEX_PREDICTOR_CONFIG_PATH")
+    if api["predictor"].get("config_reload") and config_path is not None:
+        with open(config_path, "rb") as f:
+            return resolve_config_secrets(json.loads(f.read()))
+    return resolve_config_secrets(api["predictor"]["config"])
+
+
+class ConfigReloader:
+    # polls the API's config map (which the kubelet updates in place when the operator changes it),
+    # and calls reload_fn with the new config (with its secret references resolved)
+
+    def __init__(self, config_path, reload_fn, interval=5):
+        self.config_path = config_path
+        self.reload_fn = reload_fn
+        self.interval = interval  # seconds
+        self.config_hash = hashlib.sha256(self._read()).hexdigest()
+        thread = threading.Thread(target=self._run, daemon=True)
+        thread.start()
+
+    def _read(self):
+        with open(self.config_path, "rb") as f:
+            return f.read()
+
+    def _run(self):
+        while True:
+            time.sleep(self.interval)
+            try:
+                config_bytes = self._read()
+            except Exception:
+                cx_logger().exception("failed to read the predictor's config")
+                continue
+
+            config_hash = hashlib.sha256(config_bytes).hexdigest()
+            if config_hash == self.config_hash:
+                continue
+            # if the reload fails, the previous config is kept until the config changes again
+            self.config_hash = config_hash
+
+            try:
+                self.reload_fn(resolve_config_secrets(json.loads(config_bytes)))
+                cx_logger().info("reloaded the predictor's config")
+            except Exception:
+                cx_logger().exception("failed to reload the predictor's config")
+
+
+def start_config_reloader(api, local_cache, new_predictor):
+    # the predictor's reload_config(config) method is called if it's defined, otherwise the
+    # predictor is replaced with new_predictor(config) (the previous one serves requests until then)
+    config_path = os.environ.get("CORTEX_PREDICTOR_CONFIG_PATH")
+    if not api["predictor"].get("config_reload") or config_path is None:
+        return None
+
+    def reload(config):
+        predictor = local_cache["predictor"]
+        if hasattr(predictor, "reload_config"):
+            predictor.reload_config(config)
+            return
+        predictor = new_predictor(config)
+        local_cache["predictor"] = predictor
+        batcher = local_cache.get("batchers", {}).get(None)
+        if batcher is not None:
+            batcher.predict_fn = predictor.predict
+
+    return ConfigReloader(config_path, reload)
+
+
 def get_classes(ctx, api_name):
     api = ctx.apis[api_name]
     prefix = os.path.join(ctx.metadata_root, api["id"], "classes")
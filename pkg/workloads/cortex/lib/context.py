@@ -139,37 +139,70 @@ class Context:
         elif api["predictor"]["type"] == "python":
             target_class_name = "PythonPredictor"
             validations = PYTHON_CLASS_VALIDATION
+        elif api["predictor"]["type"] == "triton":
+            target_class_name = "TritonPredictor"
+            validations = TRITON_CLASS_VALIDATION
+        elif api["predictor"]["type"] in MODEL_PREDICTOR_CLASS_NAMES:
+            target_class_name = MODEL_PREDICTOR_CLASS_NAMES[api["predictor"]["type"]]
+            validations = MODEL_CLASS_VALIDATION
+
+        if len(api["networking"].get("forward_headers") or []) > 0:
+            # the predictor also receives the metadata from the forwarded headers
+            validations = _with_metadata_arg(validations)
+
+        return self._get_class(
+            api_name,
+            "predictor",
+            project_dir,
+            api["predictor"]["path"],
+            target_class_name,
+            validations,
+        )
+
+    def get_explainer_class(self, api_name, project_dir):
+        api = self.apis[api_name]
+        explainer = api["predictor"]["explainer"]
+        return self._get_class(
+            api_name,
+            "explainer",
+            project_dir,
+            explainer["path"],
+            "Explainer",
+            EXPLAINER_CLASS_VALIDATION,
+        )
 
+    def _get_class(
+        self, api_name, module_prefix, project_dir, impl_path, target_class_name, validations
+    ):
+        # impl_path is relative to the project directory
         try:
-            impl = self.load_module(
-                "predictor", api["name"], os.path.join(project_dir, api["predictor"]["path"])
-            )
+            impl = self.load_module(module_prefix, api_name, os.path.join(project_dir, impl_path))
         except CortexException as e:
-            e.wrap("api " + api_name, "error in " + api["predictor"]["path"])
+            e.wrap("api " + api_name, "error in " + impl_path)
             raise
         finally:
             refresh_logger()
 
         try:
             classes = inspect.getmembers(impl, inspect.isclass)
-            predictor_class = None
+            target_class = None
             for class_df in classes:
                 if class_df[0] == target_class_name:
-                    if predictor_class is not None:
+                    if target_class is not None:
                         raise UserException(
-                            "multiple definitions for {} class found; please check your imports and class definitions and ensure that there is only one Predictor class definition".format(
-                                target_class_name
+                            "multiple definitions for {} class found; please check your imports and class definitions and ensure that there is only one {} class definition".format(
+                                target_class_name, target_class_name
                             )
                         )
-                    predictor_class = class_df[1]
-            if predictor_class is None:
+                    target_class = class_df[1]
+            if target_class is None:
                 raise UserException("{} class is not defined".format(target_class_name))
 
-            _validate_impl(predictor_class, validations)
+            _validate_impl(target_class, validations)
         except CortexException as e:
-            e.wrap("api " + api_name, "error in " + api["predictor"]["path"])
+            e.wrap("api " + api_name, "error in " + impl_path)
             raise
-        return predictor_class
+        return target_class
 
     def get_resource_status(self, resource):
         key = self.resource_status_key(resource)
@@ -239,23 +272,64 @@ PYTHON_CLASS_VALIDATION = {
     "required": [
         {"name": "__init__", "args": ["self", "config"]},
         {"name": "predict", "args": ["self", "payload"]},
-    ]
+    ],
+    "optional": [{"name": "reload_config", "args": ["self", "config"]}],
 }
 
 TENSORFLOW_CLASS_VALIDATION = {
     "required": [
         {"name": "__init__", "args": ["self", "tensorflow_client", "config"]},
         {"name": "predict", "args": ["self", "payload"]},
-    ]
+    ],
+    "optional": [{"name": "reload_config", "args": ["self", "config"]}],
 }
 
 ONNX_CLASS_VALIDATION = {
     "required": [
         {"name": "__init__", "args": ["self", "onnx_client", "config"]},
         {"name": "predict", "args": ["self", "payload"]},
+    ],
+    "optional": [{"name": "reload_config", "args": ["self", "config"]}],
+}
+
+TRITON_CLASS_VALIDATION = {
+    "required": [
+        {"name": "__init__", "args": ["self", "triton_client", "config"]},
+        {"name": "predict", "args": ["self", "payload"]},
+    ],
+    "optional": [{"name": "reload_config", "args": ["self", "config"]}],
+}
+
+MODEL_CLASS_VALIDATION = {
+    "required": [
+        {"name": "__init__", "args": ["self", "model", "config"]},
+        {"name": "predict", "args": ["self", "payload"]},
+    ],
+    "optional": [{"name": "reload_config", "args": ["self", "config"]}],
+}
+
+EXPLAINER_CLASS_VALIDATION = {
+    "required": [
+        {"name": "__init__", "args": ["self", "predictor", "config"]},
+        {"name": "explain", "args": ["self", "payload"]},
     ]
 }
 
+MODEL_PREDICTOR_CLASS_NAMES = {
+    "sklearn": "SKLearnPredictor",
+    "xgboost": "XGBoostPredictor",
+    "lightgbm": "LightGBMPredictor",
+}
+
+
+def _with_metadata_arg(impl_req):
+    required = []
+    for required_func in impl_req["required"]:
+        if required_func["name"] == "predict":
+            required_func = {"name": "predict", "args": required_func["args"] + ["metadata"]}
+        required.append(required_func)
+    return {"required": required, "optional": impl_req.get("optional", [])}
+
 
 def _validate_impl(impl, impl_req):
     for optional_func in impl_req.get("optional", []):
@@ -0,0 +1,143 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+import math
+import random
+import threading
+import time
+import uuid
+
+from cortex.lib.api_utils import extract_prediction
+from cortex.lib.log import cx_logger
+
+
+OTHER_CATEGORY = "__other__"
+
+_FLUSH_INTERVAL = 60  # seconds
+_MAX_SAMPLES = 200  # the maximum number of numeric values which are sampled per field per flush
+_MAX_CATEGORIES = 100  # additional categories are counted as OTHER_CATEGORY
+
+
+def get_field(obj, path):
+    """Returns the value at the dot-separated path in obj (None if it isn't present)"""
+    for key in path.split("."):
+        if not isinstance(obj, dict) or key not in obj:
+            return None
+        obj = obj[key]
+    return obj
+
+
+class Distribution:
+    """Summarizes values: numeric values are reservoir sampled, and all other values are counted"""
+
+    def __init__(self):
+        self.samples = []
+        self.numeric_count = 0
+        self.categories = {}
+
+    def add(self, value):
+        if value is None or isinstance(value, (dict, list)):
+            return
+
+        if isinstance(value, bool) or not isinstance(value, (int, float)):
+            category = str(value)
+            if category not in self.categories and len(self.categories) >= _MAX_CATEGORIES:
+                category = OTHER_CATEGORY
+            self.categories[category] = self.categories.get(category, 0) + 1
+            return
+
+        if math.isnan(value) or math.isinf(value):
+            return
+
+        self.numeric_count += 1
+        if len(self.samples) < _MAX_SAMPLES:
+            self.samples.append(float(value))
+        else:
+            i = random.randrange(self.numeric_count)
+            if i < _MAX_SAMPLES:
+                self.samples[i] = float(value)
+
+    def count(self):
+        return self.numeric_count + sum(self.categories.values())
+
+    def to_dict(self):
+        return {
+            "samples": self.samples,
+            "numeric_count": self.numeric_count,
+            "categories": self.categories,
+        }
+
+
+class DriftRecorder:
+    """Periodically writes the distributions of the tracked values to S3 (for the operator)"""
+
+    def __init__(self, ctx, api):
+        self.api = api
+        self.storage = ctx.storage
+        self.prefix = os.path.join(ctx.metadata_root, api["id"], "drift", "samples")
+        self.features = api["tracker"]["drift"].get("features") or []
+
+        self._reset()
+        self._lock = threading.Lock()
+        threading.Thread(target=self._flush_periodically, daemon=True).start()
+
+    def _reset(self):
+        self._start = int(time.time())
+        self._prediction = Distribution()
+        self._features = {feature: Distribution() for feature in self.features}
+
+    def record(self, payload, prediction):
+        """Adds the tracked value of the prediction, and the features of the payload"""
+        try:
+            value = extract_prediction(self.api, prediction)
+        except ValueError:
+            return  # post_request_metrics() warns about predictions which can't be tracked
+
+        with self._lock:
+            self._prediction.add(value)
+            for feature, dist in self._features.items():
+                dist.add(get_field(payload, feature))
+
+    def flush(self):
+        with self._lock:
+            start, prediction, features = self._start, self._prediction, self._features
+            self._reset()
+        if prediction.count() == 0:
+            return
+
+        end = int(time.time())
+        sample = {
+            "start": start,
+            "end": end,
+            "prediction": prediction.to_dict(),
+            "features": {feature: dist.to_dict() for feature, dist in features.items()},
+        }
+        # the key starts with the time, so that the operator can list the samples in a window
+        key = os.path.join(self.prefix, "{}-{}.json".format(end, uuid.uuid4().hex[:8]))
+        self.storage.put_json(sample, key)
+
+    def _flush_periodically(self):
+        while True:
+            time.sleep(_FLUSH_INTERVAL)
+            try:
+                self.flush()
+            except Exception:
+                cx_logger().warn("unable to write data drift samples", exc_info=True)
+
+
+def get_drift_recorder(ctx, api):
+    if api.get("tracker") is None or api["tracker"].get("drift") is None:
+        return None
+    return DriftRecorder(ctx, api)
@@ -0,0 +1,267 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+import csv
+
+import numpy as np
+from flask import jsonify
+from flask_api import status
+
+from cortex.lib import api_utils
+from cortex.lib.exceptions import UserException, UserRuntimeException
+from cortex.lib.log import cx_logger, debug_obj
+
+
+def get_explainer(ctx, api, predictor, project_dir):
+    # returns the api's explainer (None if it isn't configured), which explains the predictions of predictor
+    explainer = api["predictor"].get("explainer")
+    if explainer is None:
+        return None
+
+    cx_logger().info("initializing the {} explainer".format(explainer["type"]))
+    config = explainer.get("config") or {}
+
+    if explainer["type"] == "python":
+        explainer_class = ctx.get_explainer_class(api["name"], project_dir)
+        try:
+            return PythonExplainer(explainer_class(predictor, config))
+        except Exception as e:
+            raise UserRuntimeException(explainer["path"], "__init__", str(e)) from e
+
+    try:
+        if explainer["type"] == "shap":
+            return SHAPExplainer(*read_background(project_dir, explainer["background"]), config)
+        if explainer["type"] == "lime":
+            return LIMEExplainer(*read_background(project_dir, explainer["background"]), config)
+        if explainer["type"] == "captum":
+            return CaptumExplainer(predictor, config)
+    except ImportError as e:
+        raise UserException(
+            "the {} explainer's package is not installed (add it to your project's requirements.txt)".format(
+                explainer["type"]
+            ),
+            str(e),
+        ) from e
+
+    raise UserException("unknown explainer type: {}".format(explainer["type"]))
+
+
+def read_background(project_dir, path):
+    # returns the feature names (from the csv file's header row) and the sample inputs
+    with open(os.path.join(project_dir, path), newline="") as f:
+        rows = list(csv.reader(f))
+
+    if len(rows) < 2:
+        raise UserException(
+            path, "the background file must have a header row and at least one sample"
+        )
+
+    try:
+        samples = np.array([[float(value) for value in row] for row in rows[1:]])
+    except ValueError as e:
+        raise UserException(path, "the background file's samples must be numeric", str(e)) from e
+
+    return rows[0], samples
+
+
+class PayloadError(ValueError):
+    pass
+
+
+def payload_row(payload, feature_names):
+    # the payload is a json object of the features, as it would be passed to predict()
+    if not isinstance(payload, dict):
+        raise PayloadError(
+            "the payload must be a json object with these features: {}".format(
+                ", ".join(feature_names)
+            )
+        )
+    missing = [name for name in feature_names if name not in payload]
+    if len(missing) > 0:
+        raise PayloadError("the payload is missing these features: {}".format(", ".join(missing)))
+    try:
+        return [float(payload[name]) for name in feature_names]
+    except (TypeError, ValueError) as e:
+        raise PayloadError("the payload's features must be numeric: {}".format(str(e))) from e
+
+
+def rows_predict_fn(predict_fn, feature_names, prediction_key=None):
+    # shap and lime predict many perturbed copies of the payload, which are passed to predict() one at a time
+    # (if prediction_key is set, predict() returns a json object, and the prediction is its value at the key)
+    def predict_rows(rows):
+        predictions = []
+        for row in rows:
+            payload = {name: float(value) for name, value in zip(feature_names, row)}
+            prediction = predict_fn(payload)
+            if prediction_key is not None:
+                prediction = prediction[prediction_key]
+            predictions.append(prediction)
+        return np.array(predictions, dtype=float)
+
+    return predict_rows
+
+
+class PythonExplainer:
+    def __init__(self, impl):
+        self.impl = impl
+
+    def explain(self, payload, predict_fn):
+        # the user's explainer calls its predictor itself
+        return self.impl.explain(payload)
+
+
+class SHAPExplainer:
+    def __init__(self, feature_names, background, config):
+        import shap
+
+        self.shap = shap
+        self.feature_names = feature_names
+        # the time to explain a prediction grows with the number of background samples
+        max_samples = config.get("background_samples", 100)
+        if len(background) > max_samples:
+            background = shap.sample(background, max_samples)
+        self.background = background
+        self.nsamples = config.get("nsamples", "auto")
+        self.prediction_key = config.get("prediction_key")
+
+    def explain(self, payload, predict_fn):
+        row = np.array([payload_row(payload, self.feature_names)])
+        explainer = self.shap.KernelExplainer(
+            rows_predict_fn(predict_fn, self.feature_names, self.prediction_key), self.background
+        )
+        shap_values = explainer.shap_values(row, nsamples=self.nsamples)
+
+        # models with multiple outputs have a list of values per output
+        if isinstance(shap_values, list):
+            shap_values = [values[0].tolist() for values in shap_values]
+        else:
+            shap_values = shap_values[0].tolist()
+
+        return {
+            "feature_names": self.feature_names,
+            "shap_values": shap_values,
+            "expected_value": np.array(explainer.expected_value).tolist(),
+        }
+
+
+class LIMEExplainer:
+    def __init__(self, feature_names, background, config):
+        from lime.lime_tabular import LimeTabularExplainer
+
+        self.feature_names = feature_names
+        self.mode = config.get("mode", "regression")
+        self.explainer = LimeTabularExplainer(
+            background,
+            feature_names=feature_names,
+            class_names=config.get("class_names"),
+            mode=self.mode,
+        )
+        self.num_features = config.get("num_features", len(feature_names))
+        self.num_samples = config.get("num_samples", 5000)
+        self.label = config.get("label", 1)  # the class which is explained (classification only)
+        self.prediction_key = config.get("prediction_key")
+
+    def explain(self, payload, predict_fn):
+        row = np.array(payload_row(payload, self.feature_names))
+        kwargs = {"num_features": self.num_features, "num_samples": self.num_samples}
+        if self.mode == "classification":
+            # predict() must return the probability of each class
+            kwargs["labels"] = (self.label,)
+
+        explanation = self.explainer.explain_instance(
+            row, rows_predict_fn(predict_fn, self.feature_names, self.prediction_key), **kwargs
+        )
+
+        if self.mode == "classification":
+            weights = explanation.as_list(label=self.label)
+        else:
+            weights = explanation.as_list()
+
+        return {
+            "weights": [[feature, weight] for feature, weight in weights],
+            "score": explanation.score,
+        }
+
+
+class CaptumExplainer:
+    # explains the predictions of a pytorch model (an attribute of the predictor) with integrated gradients
+    def __init__(self, predictor, config):
+        from captum.attr import IntegratedGradients
+
+        model_attribute = config.get("model_attribute", "model")
+        model = getattr(predictor, model_attribute, None)
+        if model is None:
+            raise UserException(
+                "the predictor must have a {} attribute with its pytorch model (the attribute can be set with the explainer's model_attribute config)".format(
+                    model_attribute
+                )
+            )
+
+        self.integrated_gradients = IntegratedGradients(model)
+        self.target = config.get("target")
+        self.n_steps = config.get("n_steps", 50)
+
+    def explain(self, payload, predict_fn):
+        import torch
+
+        # the payload is the model's input: a list of features, or a list of lists of features for multiple inputs
+        inputs = torch.tensor(payload, dtype=torch.float32)
+        if inputs.dim() == 1:
+            inputs = inputs.unsqueeze(0)
+
+        attributions, delta = self.integrated_gradients.attribute(
+            inputs, target=self.target, n_steps=self.n_steps, return_convergence_delta=True
+        )
+        return {"attributions": attributions.tolist(), "convergence_delta": delta.tolist()}
+
+
+def explain_request(api, explainer, predict_fn, request):
+    # responds to a request to the api's explain endpoint; predict_fn(payload, metadata) is the predictor's predict()
+    if explainer is None:
+        return "explanations are not configured for this api", status.HTTP_404_NOT_FOUND
+
+    debug = request.args.get("debug", "false").lower() == "true"
+
+    try:
+        payload = request.get_json()
+    except:
+        return "malformed json", status.HTTP_400_BAD_REQUEST
+
+    try:
+        metadata = api_utils.forwarded_metadata(api, request.headers)
+    except ValueError as e:
+        return str(e), status.HTTP_400_BAD_REQUEST
+
+    def predict(payload):
+        return api_utils.call_predict(predict_fn, payload, metadata)
+
+    explainer_config = api["predictor"]["explainer"]
+    try:
+        debug_obj("payload", payload, debug)
+        try:
+            explanation = explainer.explain(payload, predict)
+        except PayloadError as e:
+            return str(e), status.HTTP_400_BAD_REQUEST
+        except Exception as e:
+            raise UserRuntimeException(
+                explainer_config.get("path") or explainer_config["type"], "explain", str(e)
+            ) from e
+        debug_obj("explanation", explanation, debug)
+    except Exception as e:
+        cx_logger().exception("explanation failed")
+        message = "explanation failed: {}".format(str(e))
+        return message, status.HTTP_406_NOT_ACCEPTABLE
+
+    return jsonify(explanation)
@@ -0,0 +1,331 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import os
+import base64
+import json
+import threading
+import time
+import uuid
+from datetime import datetime
+
+import boto3
+from flask import jsonify
+from flask_api import status
+
+from cortex.lib import api_utils
+from cortex.lib.log import cx_logger
+from cortex.lib.prediction_logging import split_s3_prefix
+from cortex.lib.storage import S3
+
+
+PREDICTION_ID_HEADER = "X-Prediction-ID"
+
+_FLUSH_INTERVAL = 10  # seconds
+_SAMPLE_INTERVAL = 60  # seconds
+_MAX_BUFFERED_RECORDS = 500  # records are flushed early once this many are buffered (firehose accepts at most 500 records per batch)
+_MAX_FEEDBACK_PER_REQUEST = 500
+
+
+class FeedbackError(ValueError):
+    pass
+
+
+def new_prediction_id(api, prediction, variant=None):
+    """Returns an ID for the prediction, which includes the tracked prediction (so that feedback can be joined with it by any of the API's replicas)"""
+    info = {"t": int(time.time())}
+    if variant is not None:
+        info["v"] = variant
+    if api.get("tracker") is not None and prediction is not None:
+        try:
+            info["p"] = api_utils.extract_prediction(api, prediction)
+        except Exception:
+            pass  # the prediction can't be tracked (which is logged when its metrics are posted)
+
+    encoded_info = base64.urlsafe_b64encode(json.dumps(info, separators=(",", ":")).encode())
+    return "{}.{}".format(uuid.uuid4().hex, encoded_info.decode().rstrip("="))
+
+
+def parse_prediction_id(prediction_id):
+    """Returns the prediction's uuid, and the information which is encoded in its ID"""
+    if not isinstance(prediction_id, str):
+        raise FeedbackError("prediction_id must be a string")
+
+    prediction_uuid, _, encoded_info = prediction_id.partition(".")
+    try:
+        uuid.UUID(hex=prediction_uuid)
+        info = json.loads(base64.urlsafe_b64decode(encoded_info + "=" * (-len(encoded_info) % 4)))
+    except Exception:
+        info = None
+    if len(prediction_uuid) != 32 or not isinstance(info, dict) or "t" not in info:
+        raise FeedbackError("invalid prediction_id: {}".format(prediction_id))
+
+    return prediction_uuid, info
+
+
+def validate_ground_truth(api, ground_truth):
+    """Checks that the ground truth can be compared with the API's tracked predictions (any ground truth is recorded for untracked APIs)"""
+    tracker = api.get("tracker")
+    if tracker is None:
+        return ground_truth
+
+    if tracker["model_type"] == "classification":
+        if type(ground_truth) != str and type(ground_truth) != int:
+            raise FeedbackError(
+                "expected the ground truth of a classification prediction to be a string or an integer, but found {}".format(
+                    type(ground_truth).__name__
+                )
+            )
+        return str(ground_truth)
+
+    if type(ground_truth) != float and type(ground_truth) != int:
+        raise FeedbackError(
+            "expected the ground truth of a regression prediction to be a number, but found {}".format(
+                type(ground_truth).__name__
+            )
+        )
+    return ground_truth
+
+
+def feedback_metrics(dimensions, api, prediction, ground_truth):
+    """Returns the accuracy (1 or 0) of a classification prediction, or the absolute error of a regression prediction"""
+    if api["tracker"]["model_type"] == "classification":
+        correct = 1 if prediction == ground_truth else 0
+        return [{"MetricName": "Accuracy", "Dimensions": dimensions, "Value": correct}]
+    error = abs(float(ground_truth) - float(prediction))
+    return [{"MetricName": "AbsoluteError", "Dimensions": dimensions, "Value": error}]
+
+
+class FeedbackSampler:
+    """Periodically writes summaries of the feedback on tracked predictions to S3 (for the operator, which computes the API's rolling metrics)"""
+
+    def __init__(self, ctx, api):
+        self.model_type = api["tracker"]["model_type"]
+        self.storage = ctx.storage
+        self.prefix = os.path.join(ctx.metadata_root, api["id"], "feedback", "samples")
+
+        self._reset()
+        self._lock = threading.Lock()
+        threading.Thread(target=self._flush_periodically, daemon=True).start()
+
+    def _reset(self):
+        self._start = int(time.time())
+        self._count = 0
+        self._confusion = {}  # predicted class -> ground truth class -> count
+        self._absolute_error = 0.0
+        self._squared_error = 0.0
+
+    def add(self, prediction, ground_truth):
+        if self.model_type == "classification":
+            with self._lock:
+                self._count += 1
+                counts = self._confusion.setdefault(prediction, {})
+                counts[ground_truth] = counts.get(ground_truth, 0) + 1
+            return
+
+        error = float(ground_truth) - float(prediction)
+        with self._lock:
+            self._count += 1
+            self._absolute_error += abs(error)
+            self._squared_error += error * error
+
+    def to_dict(self):
+        return {
+            "start": self._start,
+            "end": int(time.time()),
+            "count": self._count,
+            "confusion": self._confusion,
+            "absolute_error": self._absolute_error,
+            "squared_error": self._squared_error,
+        }
+
+    def flush(self):
+        with self._lock:
+            sample = self.to_dict()
+            self._reset()
+        if sample["count"] == 0:
+            return
+
+        # the key starts with the time, so that the operator can list the samples in its window
+        key = os.path.join(self.prefix, "{}-{}.json".format(sample["end"], uuid.uuid4().hex[:8]))
+        self.storage.put_json(sample, key)
+
+    def _flush_periodically(self):
+        while True:
+            time.sleep(_SAMPLE_INTERVAL)
+            try:
+                self.flush()
+            except Exception:
+                cx_logger().warn("unable to write feedback samples", exc_info=True)
+
+
+class FeedbackRecorder:
+    """Writes the ground truth of the API's predictions to S3 as JSON lines files, or to a Kinesis Data Firehose delivery stream"""
+
+    def __init__(self, ctx, api):
+        destination = api["feedback"]["destination"]
+        self.api_name = api["name"]
+
+        self.sampler = None
+        if api.get("tracker") is not None and len(api["feedback"].get("metrics") or []) > 0:
+            self.sampler = FeedbackSampler(ctx, api)
+
+        if destination.startswith("s3://"):
+            bucket, prefix = split_s3_prefix(destination)
+            self.storage = S3(bucket)
+            self.prefix = os.path.join(prefix, self.api_name)
+            self.firehose = None
+        else:
+            # arn:aws:firehose:<region>:<account>:deliverystream/<name>
+            self.firehose = boto3.client("firehose", region_name=destination.split(":")[3])
+            self.stream_name = destination.split("/")[-1]
+
+        self._records = []
+        self._lock = threading.Lock()
+        self._flush_event = threading.Event()
+        threading.Thread(target=self._flush_periodically, daemon=True).start()
+
+    def record(self, prediction_uuid, info, ground_truth):
+        record = {
+            "timestamp": datetime.utcnow().isoformat() + "Z",
+            "api": self.api_name,
+            "prediction_id": prediction_uuid,
+            "prediction_timestamp": datetime.utcfromtimestamp(info["t"]).isoformat() + "Z",
+            "ground_truth": ground_truth,
+        }
+        if "v" in info:
+            record["variant"] = info["v"]
+        if "p" in info:
+            record["prediction"] = info["p"]
+
+        with self._lock:
+            self._records.append(json.dumps(record))
+            if len(self._records) >= _MAX_BUFFERED_RECORDS:
+                self._flush_event.set()
+
+    def flush(self):
+        with self._lock:
+            records, self._records = self._records, []
+        if len(records) == 0:
+            return
+
+        if self.firehose is not None:
+            response = self.firehose.put_record_batch(
+                DeliveryStreamName=self.stream_name,
+                Records=[{"Data": (line + "\n").encode()} for line in records],
+            )
+            if response.get("FailedPutCount", 0) > 0:
+                cx_logger().warn(
+                    "unable to write {} feedback records to firehose".format(
+                        response["FailedPutCount"]
+                    )
+                )
+            return
+
+        # the keys are partitioned by hour, like the prediction logs
+        now = datetime.utcnow()
+        key = os.path.join(
+            self.prefix,
+            now.strftime("%Y/%m/%d/%H"),
+            "{}-{}.jsonl".format(now.strftime("%Y%m%dT%H%M%S"), uuid.uuid4().hex[:8]),
+        )
+        self.storage.put_str("\n".join(records) + "\n", key)
+
+    def _flush_periodically(self):
+        while True:
+            self._flush_event.wait(_FLUSH_INTERVAL)
+            self._flush_event.clear()
+            try:
+                while True:
+                    self.flush()
+                    with self._lock:
+                        if len(self._records) == 0:
+                            break
+            except Exception:
+                cx_logger().warn("unable to write feedback", exc_info=True)
+
+
+def get_feedback_recorder(ctx, api):
+    if api.get("feedback") is None:
+        return None
+    return FeedbackRecorder(ctx, api)
+
+
+def set_prediction_id(api, response, prediction, variant=None):
+    """Sets the ID of a successful prediction in the response's headers, and returns it"""
+    if response.status_code != 200:
+        return None
+    prediction_id = new_prediction_id(api, prediction, variant)
+    response.headers[PREDICTION_ID_HEADER] = prediction_id
+    exposed_headers = response.headers.get("Access-Control-Expose-Headers")
+    response.headers["Access-Control-Expose-Headers"] = ", ".join(
+        filter(None, [exposed_headers, PREDICTION_ID_HEADER])
+    )
+    return prediction_id
+
+
+def feedback_request(ctx, api, recorder, request):
+    # responds to a request to the api's feedback endpoint, whose body is {"prediction_id": ..., "ground_truth": ...} or a list of them
+    if recorder is None:
+        return "feedback is not configured for this api", status.HTTP_404_NOT_FOUND
+
+    try:
+        body = request.get_json()
+    except:
+        return "malformed json", status.HTTP_400_BAD_REQUEST
+
+    items = body if isinstance(body, list) else [body]
+    if len(items) > _MAX_FEEDBACK_PER_REQUEST:
+        message = "at most {} feedback records can be sent per request".format(
+            _MAX_FEEDBACK_PER_REQUEST
+        )
+        return message, status.HTTP_400_BAD_REQUEST
+
+    feedback = []
+    try:
+        for item in items:
+            if (
+                not isinstance(item, dict)
+                or "prediction_id" not in item
+                or "ground_truth" not in item
+            ):
+                raise FeedbackError(
+                    'expected an object with "prediction_id" and "ground_truth" fields, or a list of them'
+                )
+            prediction_uuid, info = parse_prediction_id(item["prediction_id"])
+            ground_truth = validate_ground_truth(api, item["ground_truth"])
+            feedback.append((prediction_uuid, info, ground_truth))
+    except FeedbackError as e:
+        return str(e), status.HTTP_400_BAD_REQUEST
+
+    dimensions = api_utils.api_metric_dimensions(ctx, api["name"])
+    metrics = []
+    for prediction_uuid, info, ground_truth in feedback:
+        recorder.record(prediction_uuid, info, ground_truth)
+        if "p" in info and api.get("tracker") is not None:
+            try:
+                metrics += feedback_metrics(dimensions, api, info["p"], ground_truth)
+                if recorder.sampler is not None:
+                    recorder.sampler.add(info["p"], ground_truth)
+            except Exception:
+                cx_logger().warn("unable to record feedback metric", exc_info=True)
+
+    if len(metrics) > 0:
+        try:
+            ctx.publish_metrics(metrics)
+        except Exception:
+            cx_logger().warn("failure encountered while publishing metrics", exc_info=True)
+
+    return jsonify({"recorded": len(feedback)})
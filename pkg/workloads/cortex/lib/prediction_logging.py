@@ -0,0 +1,151 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import os
+import copy
+import json
+import random
+import threading
+import time
+import uuid
+from datetime import datetime
+
+from cortex.lib import util
+from cortex.lib.log import cx_logger
+from cortex.lib.storage import S3
+
+
+REDACTED = "[REDACTED]"
+
+_FLUSH_INTERVAL = 10  # seconds
+_MAX_BUFFERED_RECORDS = 1000  # records are flushed early once this many are buffered
+
+
+def redact(obj, fields):
+    """Returns a copy of obj in which the fields (dot-separated paths, which are applied to each item of lists) are replaced"""
+    if len(fields) == 0 or not isinstance(obj, (dict, list)):
+        return obj
+    obj = copy.deepcopy(obj)
+    for field in fields:
+        _redact_path(obj, field.split("."))
+    return obj
+
+
+def _redact_path(obj, path):
+    if isinstance(obj, list):
+        for item in obj:
+            _redact_path(item, path)
+        return
+    if not isinstance(obj, dict) or path[0] not in obj:
+        return
+    if len(path) == 1:
+        obj[path[0]] = REDACTED
+    else:
+        _redact_path(obj[path[0]], path[1:])
+
+
+def split_s3_prefix(s3_path):
+    path = util.trim_prefix(s3_path, "s3://")
+    bucket, _, prefix = path.partition("/")
+    return bucket, prefix.strip("/")
+
+
+class PredictionLogger:
+    """Writes a sample of the API's requests and responses to S3 as JSON lines files (one file per flush)"""
+
+    def __init__(self, api):
+        config = api["prediction_logging"]
+        self.api_name = api["name"]
+        self.sample_rate = config["sample_rate"]
+        self.redact_fields = config.get("redact") or []
+
+        bucket, prefix = split_s3_prefix(config["destination"])
+        self.storage = S3(bucket)
+        self.prefix = os.path.join(prefix, self.api_name)
+
+        self._records = []
+        self._lock = threading.Lock()
+        self._flush_event = threading.Event()
+        threading.Thread(target=self._flush_periodically, daemon=True).start()
+
+    def log(
+        self,
+        payload,
+        prediction,
+        status_code,
+        start_time,
+        variant=None,
+        prediction_id=None,
+        trace_id=None,
+    ):
+        """Buffers the request and response if they're sampled (they're written to S3 in the background)"""
+        if random.random() >= self.sample_rate:
+            return
+
+        record = {
+            "timestamp": datetime.utcnow().isoformat() + "Z",
+            "api": self.api_name,
+            "status_code": status_code,
+            "latency_ms": round((time.time() - start_time) * 1000, 3),
+            "payload": redact(payload, self.redact_fields),
+            "prediction": redact(prediction, self.redact_fields),
+        }
+        if variant is not None:
+            record["variant"] = variant
+        if prediction_id is not None:
+            record["prediction_id"] = prediction_id.partition(".")[0]  # the prediction's uuid, which feedback records also have
+        if trace_id is not None:
+            record["trace_id"] = trace_id
+
+        try:
+            line = json.dumps(record, cls=util.json_tricks_encoder)
+        except Exception:
+            cx_logger().warn("unable to serialize the prediction log record", exc_info=True)
+            return
+
+        with self._lock:
+            self._records.append(line)
+            if len(self._records) >= _MAX_BUFFERED_RECORDS:
+                self._flush_event.set()
+
+    def flush(self):
+        with self._lock:
+            records, self._records = self._records, []
+        if len(records) == 0:
+            return
+
+        # the keys are partitioned by hour, so that the logs can be queried by time (e.g. with Athena)
+        now = datetime.utcnow()
+        key = os.path.join(
+            self.prefix,
+            now.strftime("%Y/%m/%d/%H"),
+            "{}-{}.jsonl".format(now.strftime("%Y%m%dT%H%M%S"), uuid.uuid4().hex[:8]),
+        )
+        self.storage.put_str("\n".join(records) + "\n", key)
+
+    def _flush_periodically(self):
+        while True:
+            self._flush_event.wait(_FLUSH_INTERVAL)
+            self._flush_event.clear()
+            try:
+                self.flush()
+            except Exception:
+                cx_logger().warn("unable to write prediction logs to S3", exc_info=True)
+
+
+def get_prediction_logger(api):
+    if api.get("prediction_logging") is None:
+        return None
+    return PredictionLogger(api)
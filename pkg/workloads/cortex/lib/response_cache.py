@@ -0,0 +1,100 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import hashlib
+import json
+import re
+import time
+
+import redis
+from flask import Response
+
+from cortex.lib.log import cx_logger
+
+
+CACHE_PORT = 6379  # the port of the cache sidecar (see pkg/operator/workloads/response_cache.go)
+CACHE_HEADER = "X-Cortex-Cache"  # whether the response was served from the cache (hit) or predicted (miss)
+
+_KEY_VARIABLE_REGEX = re.compile(r"\$\{([^{}]*)\}")
+_HEADER_VARIABLE_PREFIX = "header:"
+_KEY_PREFIX = "cortex:response:"
+_ENTRIES_KEY = "cortex:entries"  # a sorted set of the cached keys, scored by the time they were cached
+
+
+class ResponseCache:
+    # responses are cached by a redis sidecar which is shared by the API server's processes; the cache is best effort,
+    # so requests are predicted as usual if it can't be reached
+
+    def __init__(self, cache, client=None):
+        self.ttl = cache["ttl"] / 1e9  # durations are serialized in nanoseconds
+        self.max_entries = cache["max_entries"]
+        self.key_template = cache["key"]
+        if client is None:
+            client = redis.Redis(host="localhost", port=CACHE_PORT, socket_timeout=1)
+        self.client = client
+
+    def key(self, body, headers, variant=None):
+        """Returns the cache key of a request, from the API's key template (requests receive the same response if their keys are equal)"""
+        body_hash = hashlib.sha256(body).hexdigest()
+
+        def substitute(match):
+            variable = match.group(1)
+            if variable == "body:hash":
+                return body_hash
+            # values are quoted so that adjacent headers' values can't be confused (and missing headers are null)
+            return json.dumps(headers.get(variable[len(_HEADER_VARIABLE_PREFIX) :]))
+
+        key = _KEY_VARIABLE_REGEX.sub(substitute, self.key_template)
+        if variant is not None:
+            # each variant of an experiment predicts with its own config
+            key = "{}:{}".format(variant, key)
+        return _KEY_PREFIX + hashlib.sha256(key.encode("utf-8")).hexdigest()
+
+    def get(self, key):
+        try:
+            return self.client.get(key)
+        except redis.exceptions.RedisError:
+            cx_logger().exception("failed to read from the response cache")
+            return None
+
+    def set(self, key, response_body):
+        try:
+            now = time.time()
+            pipeline = self.client.pipeline()
+            pipeline.set(key, response_body, px=int(self.ttl * 1000))
+            pipeline.zadd(_ENTRIES_KEY, {key: now})
+            pipeline.zremrangebyscore(_ENTRIES_KEY, "-inf", now - self.ttl)  # expired entries
+            pipeline.zcard(_ENTRIES_KEY)
+            num_entries = pipeline.execute()[-1]
+
+            # the oldest entries are evicted once there are more than max_entries
+            if num_entries > self.max_entries:
+                evicted = self.client.zpopmin(_ENTRIES_KEY, num_entries - self.max_entries)
+                if len(evicted) > 0:
+                    self.client.delete(*[evicted_key for evicted_key, _ in evicted])
+        except redis.exceptions.RedisError:
+            cx_logger().exception("failed to write to the response cache")
+
+
+def cached_response(response_body):
+    response = Response(response_body, mimetype="application/json")
+    response.headers[CACHE_HEADER] = "hit"
+    return response
+
+
+def get_response_cache(api):
+    if api.get("cache") is None:
+        return None
+    return ResponseCache(api["cache"])
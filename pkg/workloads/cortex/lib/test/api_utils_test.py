@@ -0,0 +1,87 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import pytest
+
+from cortex.lib import api_utils
+
+
+API = {
+    "networking": {
+        "forward_headers": [
+            {"header": "x-user-id", "name": "user_id", "required": True, "pattern": None},
+            {
+                "header": "x-experiment-id",
+                "name": "experiment_id",
+                "required": False,
+                "pattern": "[a-z]+",
+            },
+        ]
+    }
+}
+
+
+def test_forwarded_metadata():
+    assert api_utils.forwarded_metadata({"networking": {"forward_headers": None}}, {}) is None
+
+    assert api_utils.forwarded_metadata(API, {"x-user-id": "123", "x-experiment-id": "abc"}) == {
+        "user_id": "123",
+        "experiment_id": "abc",
+    }
+    assert api_utils.forwarded_metadata(API, {"x-user-id": "123"}) == {
+        "user_id": "123",
+        "experiment_id": None,
+    }
+
+    with pytest.raises(ValueError):
+        api_utils.forwarded_metadata(API, {"x-experiment-id": "abc"})
+    with pytest.raises(ValueError):
+        api_utils.forwarded_metadata(API, {"x-user-id": "", "x-experiment-id": "abc"})
+    with pytest.raises(ValueError):
+        api_utils.forwarded_metadata(API, {"x-user-id": "123", "x-experiment-id": "abc1"})
+
+
+def test_load_shedder():
+    load_shedder = api_utils.LoadShedder(2, 2, "x-priority")
+
+    # two requests are being handled, and one is queued
+    load_shedder.in_flight = 3
+
+    assert load_shedder.admit({"x-priority": "low"}) is False
+    assert load_shedder.admit({}) is True
+    assert load_shedder.admit({"x-priority": "normal"}) is False
+    assert load_shedder.admit({"x-priority": " High"}) is True
+    assert load_shedder.in_flight == 5
+
+    load_shedder.release()
+    load_shedder.release()
+    assert load_shedder.in_flight == 3
+
+    # the priority header is ignored if it isn't configured
+    load_shedder = api_utils.LoadShedder(1, 0)
+    load_shedder.in_flight = 1
+    assert load_shedder.admit({"x-priority": "high"}) is False
+
+
+def test_configure_load_shedding():
+    waitress_kwargs = {"threads": 8}
+    assert api_utils.configure_load_shedding({"networking": {}}, waitress_kwargs) is None
+    assert waitress_kwargs["threads"] == 8
+
+    api = {"networking": {"load_shedding": {"max_queue_depth": 2, "status_code": 429}}}
+    load_shedder = api_utils.configure_load_shedding(api, waitress_kwargs)
+    assert load_shedder.concurrency == 8
+    assert load_shedder.priority_header is None
+    assert waitress_kwargs["threads"] == 14
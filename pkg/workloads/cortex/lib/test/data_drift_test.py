@@ -0,0 +1,88 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from cortex.lib.data_drift import get_field, Distribution, DriftRecorder, OTHER_CATEGORY
+import cortex.lib.data_drift as data_drift
+
+
+class FakeContext:
+    def __init__(self):
+        self.metadata_root = "metadata"
+        self.storage = self
+        self.objects = {}
+
+    def put_json(self, obj, key):
+        self.objects[key] = obj
+
+
+def test_get_field():
+    payload = {"user": {"age": 30, "tags": ["a"]}, "country": "us"}
+    assert get_field(payload, "country") == "us"
+    assert get_field(payload, "user.age") == 30
+    assert get_field(payload, "user.tags") == ["a"]
+    assert get_field(payload, "user.missing") is None
+    assert get_field(payload, "country.code") is None
+    assert get_field(["a"], "country") is None
+
+
+def test_distribution():
+    dist = Distribution()
+    for value in [1, 2.5, "a", "a", True, None, {"x": 1}, [1], float("nan")]:
+        dist.add(value)
+    assert dist.samples == [1.0, 2.5]
+    assert dist.numeric_count == 2
+    assert dist.categories == {"a": 2, "True": 1}
+    assert dist.count() == 5
+
+
+def test_distribution_limits():
+    dist = Distribution()
+    for i in range(data_drift._MAX_SAMPLES * 2):
+        dist.add(i)
+    assert len(dist.samples) == data_drift._MAX_SAMPLES
+    assert dist.numeric_count == data_drift._MAX_SAMPLES * 2
+
+    dist = Distribution()
+    for i in range(data_drift._MAX_CATEGORIES + 5):
+        dist.add(str(i))
+    assert len(dist.categories) == data_drift._MAX_CATEGORIES + 1
+    assert dist.categories[OTHER_CATEGORY] == 5
+
+
+def test_drift_recorder():
+    ctx = FakeContext()
+    api = {
+        "id": "abc",
+        "tracker": {
+            "key": "label",
+            "model_type": "classification",
+            "drift": {"features": ["age", "user.country"]},
+        },
+    }
+    recorder = DriftRecorder(ctx, api)
+
+    recorder.flush()
+    assert ctx.objects == {}  # nothing is written if no predictions were recorded
+
+    recorder.record({"age": 30, "user": {"country": "us"}}, {"label": "cat"})
+    recorder.record({"age": 40}, {"label": 1})
+    recorder.record({"age": 50}, {"other": "dog"})  # the prediction can't be tracked
+    recorder.flush()
+
+    assert len(ctx.objects) == 1
+    key, sample = list(ctx.objects.items())[0]
+    assert key.startswith("metadata/abc/drift/samples/{}-".format(sample["end"]))
+    assert sample["prediction"]["categories"] == {"cat": 1, "1": 1}
+    assert sample["features"]["age"]["samples"] == [30.0, 40.0]
+    assert sample["features"]["user.country"]["categories"] == {"us": 1}
@@ -0,0 +1,53 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import pytest
+
+from cortex.lib import explainers
+
+
+def test_payload_row():
+    feature_names = ["sepal_length", "sepal_width"]
+    payload = {"sepal_width": 3, "sepal_length": "5.1"}
+    assert explainers.payload_row(payload, feature_names) == [5.1, 3.0]
+
+    with pytest.raises(explainers.PayloadError, match="json object"):
+        explainers.payload_row([5.1, 3], feature_names)
+    with pytest.raises(explainers.PayloadError, match="missing these features: sepal_width"):
+        explainers.payload_row({"sepal_length": 5.1}, feature_names)
+    with pytest.raises(explainers.PayloadError, match="numeric"):
+        explainers.payload_row({"sepal_length": 5.1, "sepal_width": "wide"}, feature_names)
+
+
+def test_rows_predict_fn():
+    payloads = []
+
+    def predict(payload):
+        payloads.append(payload)
+        return {"score": payload["a"] + payload["b"]}
+
+    predict_rows = explainers.rows_predict_fn(predict, ["a", "b"], prediction_key="score")
+    assert predict_rows([[1, 2], [3, 4]]).tolist() == [3.0, 7.0]
+    assert payloads == [{"a": 1.0, "b": 2.0}, {"a": 3.0, "b": 4.0}]
+
+
+def test_read_background(tmp_path):
+    (tmp_path / "background.csv").write_text("a,b\n1,2\n3,4.5\n")
+    feature_names, samples = explainers.read_background(str(tmp_path), "background.csv")
+    assert feature_names == ["a", "b"]
+    assert samples.tolist() == [[1.0, 2.0], [3.0, 4.5]]
+
+    (tmp_path / "empty.csv").write_text("a,b\n")
+    with pytest.raises(Exception, match="at least one sample"):
+        explainers.read_background(str(tmp_path), "empty.csv")
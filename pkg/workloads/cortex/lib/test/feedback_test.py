@@ -0,0 +1,118 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import pytest
+
+from cortex.lib.feedback import (
+    FeedbackError,
+    new_prediction_id,
+    parse_prediction_id,
+    validate_ground_truth,
+    feedback_metrics,
+    FeedbackSampler,
+)
+
+
+classification_api = {"name": "iris", "tracker": {"key": "class", "model_type": "classification"}}
+regression_api = {"name": "price", "tracker": {"model_type": "regression"}}
+
+
+def test_prediction_id():
+    prediction_uuid, info = parse_prediction_id(
+        new_prediction_id(classification_api, {"class": "setosa"}, "v1")
+    )
+    assert len(prediction_uuid) == 32
+    assert info["p"] == "setosa"
+    assert info["v"] == "v1"
+
+    _, info = parse_prediction_id(new_prediction_id(regression_api, 2.5))
+    assert info["p"] == 2.5
+    assert "v" not in info
+
+    # predictions which can't be tracked still have IDs
+    _, info = parse_prediction_id(new_prediction_id(classification_api, {"label": "setosa"}))
+    assert "p" not in info
+    _, info = parse_prediction_id(new_prediction_id({"name": "untracked"}, "setosa"))
+    assert "p" not in info
+
+    for prediction_id in [None, 1, "", "abc", "{}.e30".format("0" * 32), "{}.!!".format("0" * 32)]:
+        with pytest.raises(FeedbackError):
+            parse_prediction_id(prediction_id)
+
+
+def test_validate_ground_truth():
+    assert validate_ground_truth(classification_api, "setosa") == "setosa"
+    assert validate_ground_truth(classification_api, 2) == "2"
+    assert validate_ground_truth(regression_api, 2) == 2
+    assert validate_ground_truth({"name": "untracked"}, {"any": "value"}) == {"any": "value"}
+
+    with pytest.raises(FeedbackError):
+        validate_ground_truth(classification_api, 2.5)
+    with pytest.raises(FeedbackError):
+        validate_ground_truth(regression_api, "2.5")
+
+
+def test_feedback_metrics():
+    metrics = feedback_metrics([], classification_api, "setosa", "setosa")
+    assert metrics == [{"MetricName": "Accuracy", "Dimensions": [], "Value": 1}]
+    assert feedback_metrics([], classification_api, "setosa", "virginica")[0]["Value"] == 0
+
+    metrics = feedback_metrics([], regression_api, 2.5, 1)
+    assert metrics == [{"MetricName": "AbsoluteError", "Dimensions": [], "Value": 1.5}]
+
+
+class _Storage:
+    def __init__(self):
+        self.objects = {}
+
+    def put_json(self, obj, key):
+        self.objects[key] = obj
+
+
+class _Context:
+    def __init__(self):
+        self.storage = _Storage()
+        self.metadata_root = "apps/app/metadata"
+
+
+def test_feedback_sampler():
+    ctx = _Context()
+    sampler = FeedbackSampler(ctx, dict(classification_api, id="api-id"))
+    sampler.add("setosa", "setosa")
+    sampler.add("setosa", "virginica")
+    sampler.add("virginica", "virginica")
+    sampler.flush()
+
+    assert len(ctx.storage.objects) == 1
+    key, sample = list(ctx.storage.objects.items())[0]
+    assert key.startswith("apps/app/metadata/api-id/feedback/samples/{}-".format(sample["end"]))
+    assert sample["count"] == 3
+    assert sample["confusion"] == {
+        "setosa": {"setosa": 1, "virginica": 1},
+        "virginica": {"virginica": 1},
+    }
+
+    # empty samples aren't written
+    sampler.flush()
+    assert len(ctx.storage.objects) == 1
+
+    ctx = _Context()
+    sampler = FeedbackSampler(ctx, dict(regression_api, id="api-id"))
+    sampler.add(2.5, 1)
+    sampler.add(1, 2)
+    sampler.flush()
+    sample = list(ctx.storage.objects.values())[0]
+    assert sample["count"] == 2
+    assert sample["absolute_error"] == 2.5
+    assert sample["squared_error"] == 3.25
@@ -0,0 +1,42 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+from cortex.lib.prediction_logging import redact, split_s3_prefix, REDACTED
+
+
+def test_redact():
+    payload = {
+        "user": {"email": "a@b.com", "id": 1},
+        "items": [{"name": "x", "price": 1}, {"name": "y"}],
+        "text": "hello",
+    }
+
+    redacted = redact(payload, ["user.email", "items.price", "text", "missing.field"])
+    assert redacted == {
+        "user": {"email": REDACTED, "id": 1},
+        "items": [{"name": "x", "price": REDACTED}, {"name": "y"}],
+        "text": REDACTED,
+    }
+    assert payload["user"]["email"] == "a@b.com"
+
+    assert redact(payload, []) is payload
+    assert redact("text", ["text"]) == "text"
+    assert redact([{"a": 1}, 2], ["a"]) == [{"a": REDACTED}, 2]
+
+
+def test_split_s3_prefix():
+    assert split_s3_prefix("s3://my-bucket/predictions/") == ("my-bucket", "predictions")
+    assert split_s3_prefix("s3://my-bucket/a/b") == ("my-bucket", "a/b")
+    assert split_s3_prefix("s3://my-bucket") == ("my-bucket", "")
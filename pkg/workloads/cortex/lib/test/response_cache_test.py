@@ -0,0 +1,36 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+from cortex.lib.response_cache import ResponseCache
+
+
+def response_cache(key):
+    return ResponseCache({"ttl": 3600 * 1e9, "max_entries": 100, "key": key}, client=object())
+
+
+def test_key():
+    cache = response_cache("${body:hash}")
+    assert cache.key(b'{"a": 1}', {}) == cache.key(b'{"a": 1}', {"x-user-id": "1"})
+    assert cache.key(b'{"a": 1}', {}) != cache.key(b'{"a": 2}', {})
+    assert cache.key(b'{"a": 1}', {}, "a") != cache.key(b'{"a": 1}', {}, "b")
+
+    cache = response_cache("${header:x-tenant-id}/${body:hash}")
+    assert cache.key(b"{}", {"x-tenant-id": "a"}) == cache.key(b"{}", {"x-tenant-id": "a"})
+    assert cache.key(b"{}", {"x-tenant-id": "a"}) != cache.key(b"{}", {"x-tenant-id": "b"})
+    assert cache.key(b"{}", {"x-tenant-id": "a"}) != cache.key(b"{}", {})
+
+    # adjacent headers' values can't be confused
+    cache = response_cache("${header:a}${header:b}")
+    assert cache.key(b"", {"a": "x", "b": "yz"}) != cache.key(b"", {"a": "xy", "b": "z"})
@@ -0,0 +1,85 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+from cortex.lib import tracing
+
+
+TRACE_ID = "4bf92f3577b34da6a3ce929d0e0e4736"
+SPAN_ID = "00f067aa0ba902b7"
+
+
+def test_parse_traceparent():
+    assert tracing.parse_traceparent("00-{}-{}-01".format(TRACE_ID, SPAN_ID)) == (
+        TRACE_ID,
+        SPAN_ID,
+        True,
+    )
+    assert tracing.parse_traceparent("00-{}-{}-00".format(TRACE_ID, SPAN_ID)) == (
+        TRACE_ID,
+        SPAN_ID,
+        False,
+    )
+
+    assert tracing.parse_traceparent(None) is None
+    assert tracing.parse_traceparent("00-{}-{}-01".format("0" * 32, SPAN_ID)) is None
+    assert tracing.parse_traceparent("00-{}-{}".format(TRACE_ID, SPAN_ID)) is None
+    assert tracing.parse_traceparent("ff-{}-{}-01".format(TRACE_ID, SPAN_ID)) is None
+
+
+def test_start_span_from_b3_headers():
+    tracer = tracing.Tracer("iris-classifier")
+    span = tracer.start_span(
+        "predict",
+        {
+            "X-B3-TraceId": "463ac35c9f6413ad",
+            "X-B3-SpanId": "a2fb4a1d1a96d312",
+            "X-B3-Sampled": "1",
+            "X-Request-Id": "8f6bcd0b",
+            "Content-Type": "application/json",
+        },
+    )
+
+    assert span.trace_id == "0000000000000000463ac35c9f6413ad"
+    assert span.parent_span_id == "a2fb4a1d1a96d312"
+    assert span.sampled
+
+    headers = span.headers()
+    assert headers["x-request-id"] == "8f6bcd0b"
+    assert "content-type" not in headers
+    assert tracing.parse_traceparent(headers["traceparent"]) == (span.trace_id, span.span_id, True)
+
+
+def test_traceparent_takes_precedence():
+    tracer = tracing.Tracer("iris-classifier")
+    span = tracer.start_span(
+        "predict",
+        {
+            "traceparent": "00-{}-{}-01".format(TRACE_ID, SPAN_ID),
+            "x-b3-traceid": "463ac35c9f6413ad",
+            "x-b3-spanid": "a2fb4a1d1a96d312",
+        },
+    )
+    assert span.trace_id == TRACE_ID
+    assert span.parent_span_id == SPAN_ID
+
+
+def test_root_spans_are_not_sampled_without_an_endpoint():
+    tracer = tracing.Tracer("iris-classifier")
+    span = tracer.start_span("predict", {})
+    assert span.parent_span_id is None
+    assert not span.sampled
+
+    span.finish()
+    assert tracer._spans == []
@@ -0,0 +1,273 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import os
+import json
+import random
+import threading
+import time
+
+import requests
+
+from cortex.lib.log import cx_logger
+
+
+TRACEPARENT_HEADER = "traceparent"
+
+# the headers which identify the request's trace, which are forwarded so that downstream spans join the trace
+# (istio's proxies use the b3 headers and x-request-id)
+PROPAGATION_HEADERS = [
+    "traceparent",
+    "tracestate",
+    "x-request-id",
+    "x-b3-traceid",
+    "x-b3-spanid",
+    "x-b3-parentspanid",
+    "x-b3-sampled",
+    "x-b3-flags",
+    "b3",
+    "x-ot-span-context",
+]
+
+_FLUSH_INTERVAL = 5  # seconds
+_MAX_BUFFERED_SPANS = 2000
+
+_current = threading.local()
+
+
+def _random_id(num_bytes):
+    id = 0
+    while id == 0:
+        id = random.getrandbits(num_bytes * 8)
+    return "{:0{}x}".format(id, num_bytes * 2)
+
+
+def _is_hex_id(value, length):
+    if len(value) != length or value == "0" * length:
+        return False
+    try:
+        int(value, 16)
+        return True
+    except ValueError:
+        return False
+
+
+def parse_traceparent(header):
+    """Returns (trace_id, span_id, sampled), or None if the traceparent header is missing or malformed"""
+    if not header:
+        return None
+    parts = header.strip().lower().split("-")
+    if len(parts) < 4 or len(parts[0]) != 2 or parts[0] == "ff":
+        return None
+    if parts[0] == "00" and len(parts) != 4:
+        return None
+    trace_id, span_id, flags = parts[1], parts[2], parts[3]
+    if not _is_hex_id(trace_id, 32) or not _is_hex_id(span_id, 16) or len(flags) != 2:
+        return None
+    try:
+        sampled = int(flags, 16) & 1 == 1
+    except ValueError:
+        return None
+    return trace_id, span_id, sampled
+
+
+def parse_b3(headers):
+    """Returns (trace_id, span_id, sampled) from the b3 headers which istio's proxies set, or None if they're missing"""
+    trace_id = headers.get("x-b3-traceid", "").lower()
+    span_id = headers.get("x-b3-spanid", "").lower()
+    if len(trace_id) == 16:
+        trace_id = "0" * 16 + trace_id
+    if not _is_hex_id(trace_id, 32) or not _is_hex_id(span_id, 16):
+        return None
+    sampled = headers.get("x-b3-sampled", "1") in ("1", "true") or headers.get("x-b3-flags") == "1"
+    return trace_id, span_id, sampled
+
+
+class Span:
+    def __init__(self, tracer, name, parent, propagated_headers):
+        self.tracer = tracer
+        self.name = name
+        self.start_time = time.time()
+        self.end_time = None
+        self.attributes = {}
+        self.error = None
+        self.propagated_headers = propagated_headers
+
+        if parent is not None:
+            self.trace_id, self.parent_span_id, self.sampled = parent
+        else:
+            self.trace_id = _random_id(16)
+            self.parent_span_id = None
+            self.sampled = tracer.should_sample()
+        self.span_id = _random_id(8)
+
+    def traceparent(self):
+        return "00-{}-{}-{}".format(self.trace_id, self.span_id, "01" if self.sampled else "00")
+
+    def headers(self):
+        """The headers to send with requests which are made while handling this span's request, so that they're part of its trace"""
+        headers = dict(self.propagated_headers)
+        headers[TRACEPARENT_HEADER] = self.traceparent()
+        return headers
+
+    def set_attribute(self, key, value):
+        self.attributes[key] = str(value)
+
+    def finish(self, error=None):
+        if self.end_time is not None:
+            return
+        self.end_time = time.time()
+        self.error = error
+        if self.sampled:
+            self.tracer.buffer(self)
+
+    def to_otlp(self):
+        span = {
+            "traceId": self.trace_id,
+            "spanId": self.span_id,
+            "name": self.name,
+            "kind": 2,  # server
+            "startTimeUnixNano": str(int(self.start_time * 1e9)),
+            "endTimeUnixNano": str(int(self.end_time * 1e9)),
+            "attributes": _otlp_attributes(self.attributes),
+            "status": {"code": 1},
+        }
+        if self.parent_span_id is not None:
+            span["parentSpanId"] = self.parent_span_id
+        if self.error is not None:
+            span["status"] = {"code": 2, "message": str(self.error)}
+        return span
+
+
+def _otlp_attributes(attributes):
+    return [{"key": key, "value": {"stringValue": attributes[key]}} for key in sorted(attributes)]
+
+
+class Tracer:
+    """Exports sampled spans to an OTLP/HTTP collector in the background (spans are only propagated if endpoint is None)"""
+
+    def __init__(self, service_name, endpoint=None, sample_rate=1.0):
+        self.service_name = service_name
+        self.endpoint = endpoint
+        self.sample_rate = sample_rate
+        self._spans = []
+        self._lock = threading.Lock()
+
+        if endpoint is not None:
+            thread = threading.Thread(target=self._flush_periodically, daemon=True)
+            thread.start()
+
+    def should_sample(self):
+        return self.endpoint is not None and random.random() < self.sample_rate
+
+    def start_span(self, name, headers):
+        """Starts a span which is part of the request's trace (or a new trace if the request doesn't have trace headers)"""
+        headers = {key.lower(): value for key, value in headers.items()}
+        parent = parse_traceparent(headers.get(TRACEPARENT_HEADER))
+        if parent is None:
+            parent = parse_b3(headers)
+        propagated_headers = {
+            key: headers[key]
+            for key in PROPAGATION_HEADERS
+            if key in headers and key != TRACEPARENT_HEADER
+        }
+        return Span(self, name, parent, propagated_headers)
+
+    def buffer(self, span):
+        if self.endpoint is None:
+            return
+        with self._lock:
+            if len(self._spans) >= _MAX_BUFFERED_SPANS:
+                self._spans.pop(0)
+            self._spans.append(span)
+
+    def flush(self):
+        with self._lock:
+            spans, self._spans = self._spans, []
+        if self.endpoint is None or len(spans) == 0:
+            return
+
+        body = {
+            "resourceSpans": [
+                {
+                    "resource": {
+                        "attributes": _otlp_attributes({"service.name": self.service_name})
+                    },
+                    "scopeSpans": [
+                        {"scope": {"name": "cortex"}, "spans": [span.to_otlp() for span in spans]}
+                    ],
+                }
+            ]
+        }
+        response = requests.post(
+            self.endpoint.rstrip("/") + "/v1/traces",
+            data=json.dumps(body),
+            headers={"Content-Type": "application/json"},
+            timeout=5,
+        )
+        response.raise_for_status()
+
+    def _flush_periodically(self):
+        while True:
+            time.sleep(_FLUSH_INTERVAL)
+            try:
+                self.flush()
+            except Exception:
+                cx_logger().warn("unable to export spans", exc_info=True)
+
+
+def get_tracer(api):
+    """Returns a tracer which exports the API's spans if tracing is configured for the cluster"""
+    endpoint = os.environ.get("CORTEX_TRACING_ENDPOINT")
+    if not endpoint:
+        return Tracer(api["name"])
+    sample_rate = float(os.environ.get("CORTEX_TRACING_SAMPLE_RATE", "1"))
+    return Tracer(api["name"], endpoint, sample_rate)
+
+
+def set_current_span(span):
+    _current.span = span
+
+
+def current_span():
+    return getattr(_current, "span", None)
+
+
+def current_headers():
+    """Returns the headers which predictors should send with their requests to other services, so that the requests are part of the prediction's trace"""
+    span = current_span()
+    if span is None:
+        return {}
+    return span.headers()
+
+
+def start_request_span(tracer, request):
+    """Starts the span of a request to the API, which is the current span until finish_request_span() is called"""
+    span = tracer.start_span("predict", request.headers)
+    span.set_attribute("http.method", request.method)
+    span.set_attribute("http.target", request.path)
+    set_current_span(span)
+    return span
+
+
+def finish_request_span(span, response):
+    """Finishes the span of the request which the response answers, and returns the span's traceparent header to the client"""
+    span.set_attribute("http.status_code", response.status_code)
+    error = None
+    if response.status_code >= 400:
+        error = response.status
+    span.finish(error)
+    set_current_span(None)
+    response.headers[TRACEPARENT_HEADER] = span.traceparent()
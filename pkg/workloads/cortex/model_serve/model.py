@@ -0,0 +1,52 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+from cortex.lib.exceptions import UserException
+
+# each predictor type's image only installs its framework, so frameworks are imported when a model is loaded
+MODEL_PREDICTOR_TYPES = ["sklearn", "xgboost", "lightgbm"]
+
+
+def load_model(predictor_type, model_path):
+    """Load a model which was exported by the predictor type's framework.
+
+    Args:
+        predictor_type (string): sklearn, xgboost, or lightgbm.
+        model_path (string): Path to the model in the local file system.
+
+    Returns:
+        The model: an estimator for sklearn, an xgboost.Booster for xgboost, or a lightgbm.Booster for lightgbm.
+    """
+    try:
+        if predictor_type == "sklearn":
+            import joblib
+
+            return joblib.load(model_path)  # also loads models which were saved with pickle
+
+        if predictor_type == "xgboost":
+            import xgboost
+
+            booster = xgboost.Booster()
+            booster.load_model(model_path)
+            return booster
+
+        if predictor_type == "lightgbm":
+            import lightgbm
+
+            return lightgbm.Booster(model_file=model_path)
+    except Exception as e:
+        raise UserException("unable to load the {} model".format(predictor_type), str(e)) from e
+
+    raise UserException("unsupported predictor type: {}".format(predictor_type))
@@ -19,4 +19,4 @@ export PYTHONPATH=$PYTHONPATH:$PYTHON_PATH
 if [ -f "/mnt/project/requirements.txt" ]; then
     pip --no-cache-dir install -r /mnt/project/requirements.txt
 fi
-/usr/bin/python3.6 /src/cortex/onnx_serve/api.py "$@"
+$CORTEX_PYTHON_BIN /src/cortex/onnx_serve/api.py "$@"
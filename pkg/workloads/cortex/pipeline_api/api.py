@@ -0,0 +1,322 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import sys
+import argparse
+import base64
+import json
+import time
+from concurrent.futures import ThreadPoolExecutor
+
+import requests
+from flask import Flask, request, jsonify, g
+from flask_api import status
+from waitress import serve
+
+from cortex.lib import (
+    util,
+    Context,
+    api_utils,
+    tracing,
+    prediction_logging,
+    data_drift,
+    feedback,
+)
+from cortex.lib.log import cx_logger, debug_obj
+from cortex.lib.exceptions import CortexException
+
+app = Flask(__name__)
+app.json_encoder = util.json_tricks_encoder
+
+# the input which refers to the payload of the request to the pipeline
+PAYLOAD_INPUT = "payload"
+
+local_cache = {
+    "ctx": None,
+    "api": None,
+    "rate_limiter": None,
+    "load_shedder": None,
+    "tracer": None,
+    "prediction_logger": None,
+    "drift_recorder": None,
+    "feedback_recorder": None,
+    "stages": None,
+    "executor": None,
+    "session": None,
+    "class_set": set(),
+}
+
+
+class StepException(Exception):
+    def __init__(self, step_name, status_code, message):
+        super().__init__("step {}: {}".format(step_name, message))
+        self.status_code = status_code
+
+
+@app.before_request
+def before_request():
+    g.start_time = time.time()
+
+    if api_utils.is_prediction_request(request):
+        g.span = tracing.start_request_span(local_cache["tracer"], request)
+        response = api_utils.rate_limit_request(
+            local_cache["api"], local_cache["rate_limiter"], request
+        )
+        if response is None:
+            response = api_utils.shed_request(
+                local_cache["api"], local_cache["load_shedder"], request
+            )
+        return response
+
+
+@app.teardown_request
+def teardown_request(exception):
+    api_utils.release_request(local_cache["load_shedder"])
+
+
+@app.after_request
+def after_request(response):
+    if not api_utils.is_cors_configured(local_cache["api"]):
+        response.headers["Access-Control-Allow-Origin"] = "*"
+        response.headers["Access-Control-Allow-Headers"] = request.headers.get(
+            "Access-Control-Request-Headers", "*"
+        )
+
+    if "span" in g:
+        tracing.finish_request_span(g.span, response)
+
+    if not (request.path == "/predict" and request.method == "POST"):
+        return response
+
+    api = local_cache["api"]
+    ctx = local_cache["ctx"]
+
+    cx_logger().info(response.status)
+
+    prediction = None
+    if "prediction" in g:
+        prediction = g.prediction
+
+    api_utils.post_request_metrics(
+        ctx, api, response, prediction, g.start_time, local_cache["class_set"]
+    )
+
+    prediction_id = None
+    if local_cache["feedback_recorder"] is not None and request.method == "POST":
+        prediction_id = feedback.set_prediction_id(api, response, prediction, None)
+
+    if local_cache["prediction_logger"] is not None and "payload" in g:
+        local_cache["prediction_logger"].log(
+            g.payload,
+            prediction,
+            response.status_code,
+            g.start_time,
+            prediction_id=prediction_id,
+            trace_id=g.span.trace_id if "span" in g else None,
+        )
+
+    if local_cache["drift_recorder"] is not None and "payload" in g and prediction is not None:
+        local_cache["drift_recorder"].record(g.payload, prediction)
+
+    return api_utils.compress_response(api, request, response)
+
+
+def step_input(step, outputs):
+    """A step with one input receives it as is; a step with multiple inputs receives an object with each input's value under its name"""
+    if len(step["inputs"]) == 1:
+        return outputs[step["inputs"][0]]
+    return {input_name: outputs[input_name] for input_name in step["inputs"]}
+
+
+def step_headers(step, headers):
+    """The request's headers which the step's API forwards to its predictor are passed on to the step"""
+    headers = dict(headers)
+    for header in step.get("forward_headers") or []:
+        if header in request.headers:
+            headers[header] = request.headers[header]
+    return headers
+
+
+def run_step(step, outputs, headers):
+    try:
+        response = local_cache["session"].post(
+            step["url"], json=step_input(step, outputs), headers=step_headers(step, headers)
+        )
+    except requests.exceptions.RequestException as e:
+        raise StepException(step["name"], status.HTTP_502_BAD_GATEWAY, str(e)) from e
+
+    if response.status_code != 200:
+        raise StepException(
+            step["name"],
+            response.status_code,
+            "responded with status {}: {}".format(response.status_code, response.text),
+        )
+
+    try:
+        return response.json()
+    except ValueError:
+        return response.text
+
+
+def run_pipeline(payload, debug):
+    """Runs the pipeline's stages in order (the steps within a stage don't depend on each other, so they run concurrently)"""
+    outputs = {PAYLOAD_INPUT: payload}
+    headers = dict(tracing.current_headers())
+    # the request's token is forwarded, since steps may require a JWT
+    if "Authorization" in request.headers:
+        headers["Authorization"] = request.headers["Authorization"]
+
+    for stage in local_cache["stages"]:
+        if len(stage) == 1:
+            results = [run_step(stage[0], outputs, headers)]
+        else:
+            results = list(
+                local_cache["executor"].map(lambda step: run_step(step, outputs, headers), stage)
+            )
+
+        for step, result in zip(stage, results):
+            debug_obj(step["name"], result, debug)
+            outputs[step["name"]] = result
+
+    return outputs[local_cache["api"]["pipeline"]["output"]]
+
+
+@app.route("/predict", methods=["POST"])
+def predict():
+    debug = request.args.get("debug", "false").lower() == "true"
+
+    try:
+        payload = request.get_json()
+    except:
+        return "malformed json", status.HTTP_400_BAD_REQUEST
+    g.payload = payload
+
+    try:
+        api_utils.forwarded_metadata(local_cache["api"], request.headers)
+    except ValueError as e:
+        return str(e), status.HTTP_400_BAD_REQUEST
+
+    try:
+        debug_obj("payload", payload, debug)
+        output = run_pipeline(payload, debug)
+    except StepException as e:
+        cx_logger().exception("pipeline failed")
+        return "pipeline failed: {}".format(str(e)), e.status_code
+    except Exception as e:
+        cx_logger().exception("pipeline failed")
+        return "pipeline failed: {}".format(str(e)), status.HTTP_500_INTERNAL_SERVER_ERROR
+
+    g.prediction = output
+    return jsonify(output)
+
+
+@app.route("/feedback", methods=["POST"])
+def record_feedback():
+    return feedback.feedback_request(
+        local_cache["ctx"], local_cache["api"], local_cache["feedback_recorder"], request
+    )
+
+
+@app.route("/predict", methods=["GET"])
+def get_summary():
+    pipeline = local_cache["api"]["pipeline"]
+    response = {
+        "mode": pipeline["mode"],
+        "steps": pipeline["steps"],
+        "output": pipeline["output"],
+        "message": api_utils.API_SUMMARY_MESSAGE,
+    }
+    return jsonify(response)
+
+
+@app.errorhandler(Exception)
+def exceptions(e):
+    cx_logger().exception(e)
+    return jsonify(error=str(e)), 500
+
+
+def start(args):
+    api = None
+    try:
+        ctx = Context(s3_path=args.context, cache_dir=args.cache_dir, workload_id=args.workload_id)
+        api = ctx.apis_id_map[args.api]
+        local_cache["api"] = api
+        local_cache["rate_limiter"] = api_utils.get_rate_limiter(api)
+        local_cache["tracer"] = tracing.get_tracer(api)
+        local_cache["prediction_logger"] = prediction_logging.get_prediction_logger(api)
+        local_cache["drift_recorder"] = data_drift.get_drift_recorder(ctx, api)
+        local_cache["feedback_recorder"] = feedback.get_feedback_recorder(ctx, api)
+        local_cache["ctx"] = ctx
+
+        if api.get("pipeline") is None:
+            raise CortexException(api["name"], "api is not a pipeline")
+
+        local_cache["stages"] = json.loads(base64.urlsafe_b64decode(args.stages.encode()))
+        max_stage_len = max(len(stage) for stage in local_cache["stages"])
+        local_cache["executor"] = ThreadPoolExecutor(max_workers=max_stage_len)
+        local_cache["session"] = requests.Session()
+
+    except Exception as e:
+        cx_logger().exception("failed to start api")
+        sys.exit(1)
+
+    if api.get("tracker") is not None and api["tracker"].get("model_type") == "classification":
+        try:
+            local_cache["class_set"] = api_utils.get_classes(ctx, api["name"])
+        except Exception as e:
+            cx_logger().warn("an error occurred while attempting to load classes", exc_info=True)
+
+    for i, stage in enumerate(local_cache["stages"]):
+        for step in stage:
+            cx_logger().info(
+                "stage {}: step {} ({} -> {})".format(
+                    i + 1, step["name"], ", ".join(step["inputs"]), step["url"]
+                )
+            )
+
+    waitress_kwargs = {}
+    api_utils.configure_request_size(app, api, waitress_kwargs)
+    local_cache["load_shedder"] = api_utils.configure_load_shedding(api, waitress_kwargs)
+    waitress_kwargs["listen"] = "*:{}".format(args.port)
+
+    cx_logger().info("{} api is live".format(api["name"]))
+    open("/health_check.txt", "a").close()
+    serve(app, **waitress_kwargs)
+
+
+def main():
+    parser = argparse.ArgumentParser()
+    na = parser.add_argument_group("required named arguments")
+    na.add_argument("--workload-id", required=True, help="workload id")
+    na.add_argument("--port", type=int, required=True, help="port (on localhost) to use")
+    na.add_argument(
+        "--context",
+        required=True,
+        help="s3 path to context (e.g. s3://bucket/path/to/context.json)",
+    )
+    na.add_argument("--api", required=True, help="resource id of api to serve")
+    na.add_argument("--cache-dir", required=True, help="local path for the context cache")
+    na.add_argument(
+        "--stages",
+        required=True,
+        help="base64-encoded json list of the pipeline's stages (lists of steps with their urls)",
+    )
+    parser.set_defaults(func=start)
+
+    args = parser.parse_args()
+    args.func(args)
+
+
+if __name__ == "__main__":
+    main()
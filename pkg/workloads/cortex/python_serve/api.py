@@ -16,12 +16,26 @@ import os
 import sys
 import argparse
 import time
+import json
+import asyncio
+import inspect
 
 from flask import Flask, request, jsonify, g
 from flask_api import status
 from waitress import serve
-
-from cortex.lib import util, Context, api_utils
+import websockets
+
+from cortex.lib import (
+    util,
+    Context,
+    api_utils,
+    tracing,
+    prediction_logging,
+    data_drift,
+    response_cache,
+    explainers,
+    feedback,
+)
 from cortex.lib.log import cx_logger, debug_obj, refresh_logger
 from cortex.lib.exceptions import CortexException, UserRuntimeException
 
@@ -29,24 +43,65 @@ app = Flask(__name__)
 
 app.json_encoder = util.json_tricks_encoder
 
-local_cache = {"ctx": None, "api": None, "class_set": set()}
+local_cache = {
+    "ctx": None,
+    "api": None,
+    "rate_limiter": None,
+    "load_shedder": None,
+    "tracer": None,
+    "prediction_logger": None,
+    "drift_recorder": None,
+    "feedback_recorder": None,
+    "response_cache": None,
+    "variant_assigner": None,
+    "predictors": {},  # variant name -> predictor (only used for experiments)
+    "batchers": {},  # variant name (None without an experiment) -> batcher (None without batching)
+    "explainers": {},  # variant name (None without an experiment) -> explainer (None without an explainer)
+    "class_set": set(),
+}
 
 
 @app.before_request
 def before_request():
     g.start_time = time.time()
 
+    if api_utils.is_prediction_request(request):
+        g.span = tracing.start_request_span(local_cache["tracer"], request)
+        response = api_utils.rate_limit_request(
+            local_cache["api"], local_cache["rate_limiter"], request
+        )
+        if response is None:
+            response = api_utils.shed_request(
+                local_cache["api"], local_cache["load_shedder"], request
+            )
+        return response
+
+
+@app.teardown_request
+def teardown_request(exception):
+    api_utils.release_request(local_cache["load_shedder"])
+
 
 @app.after_request
 def after_request(response):
-    response.headers["Access-Control-Allow-Origin"] = "*"
-    response.headers["Access-Control-Allow-Headers"] = request.headers.get(
-        "Access-Control-Request-Headers", "*"
-    )
+    if not api_utils.is_cors_configured(local_cache["api"]):
+        response.headers["Access-Control-Allow-Origin"] = "*"
+        response.headers["Access-Control-Allow-Headers"] = request.headers.get(
+            "Access-Control-Request-Headers", "*"
+        )
+
+    if "span" in g:
+        if "variant" in g:
+            g.span.set_attribute("variant", g.variant)
+        tracing.finish_request_span(g.span, response)
 
     if request.path != "/predict":
         return response
 
+    if "variant" in g:
+        response.headers[api_utils.VARIANT_HEADER] = g.variant
+        response.headers["Access-Control-Expose-Headers"] = api_utils.VARIANT_HEADER
+
     api = local_cache["api"]
     ctx = local_cache["ctx"]
 
@@ -60,7 +115,25 @@ def after_request(response):
         ctx, api, response, prediction, g.start_time, local_cache["class_set"]
     )
 
-    return response
+    prediction_id = None
+    if local_cache["feedback_recorder"] is not None and request.method == "POST":
+        prediction_id = feedback.set_prediction_id(api, response, prediction, g.get("variant"))
+
+    if local_cache["prediction_logger"] is not None and "payload" in g:
+        local_cache["prediction_logger"].log(
+            g.payload,
+            prediction,
+            response.status_code,
+            g.start_time,
+            variant=g.get("variant"),
+            prediction_id=prediction_id,
+            trace_id=g.span.trace_id if "span" in g else None,
+        )
+
+    if local_cache["drift_recorder"] is not None and "payload" in g and prediction is not None:
+        local_cache["drift_recorder"].record(g.payload, prediction)
+
+    return api_utils.compress_response(api, request, response)
 
 
 def prediction_failed(reason):
@@ -78,18 +151,47 @@ def health():
 def predict():
     debug = request.args.get("debug", "false").lower() == "true"
 
-    try:
-        payload = request.get_json()
-    except:
-        return "malformed json", status.HTTP_400_BAD_REQUEST
-
     api = local_cache["api"]
     predictor = local_cache["predictor"]
+    variant = None
+
+    if local_cache["variant_assigner"] is not None:
+        g.variant = local_cache["variant_assigner"].assign(request.headers)
+        variant = g.variant
+        predictor = local_cache["predictors"][variant]
+
+    predict_fn = predictor.predict
+    if local_cache["batchers"].get(variant) is not None:
+        # the predictor receives this payload in a batch with concurrent requests' payloads
+        predict_fn = local_cache["batchers"][variant].predict
+
+    try:
+        metadata = api_utils.forwarded_metadata(api, request.headers)
+    except ValueError as e:
+        return str(e), status.HTTP_400_BAD_REQUEST
+
+    # debug requests are always predicted, so that their payloads and predictions are logged
+    cache_key = None
+    if local_cache["response_cache"] is not None and not debug:
+        cache_key = local_cache["response_cache"].key(request.get_data(), request.headers, variant)
+        cached = local_cache["response_cache"].get(cache_key)
+        if cached is not None:
+            return response_cache.cached_response(cached)
+
+    if api["networking"]["stream_requests"]:
+        # the predictor reads the request body from a file-like object
+        payload = request.stream
+    else:
+        try:
+            payload = request.get_json()
+        except:
+            return "malformed json", status.HTTP_400_BAD_REQUEST
+        g.payload = payload
 
     try:
         try:
             debug_obj("payload", payload, debug)
-            output = predictor.predict(payload)
+            output = api_utils.call_predict(predict_fn, payload, metadata)
             debug_obj("prediction", output, debug)
         except Exception as e:
             raise UserRuntimeException(api["predictor"]["path"], "predict", str(e)) from e
@@ -98,7 +200,37 @@ def predict():
         return prediction_failed(str(e))
 
     g.prediction = output
-    return jsonify(output)
+    response = jsonify(output)
+    if cache_key is not None:
+        local_cache["response_cache"].set(cache_key, response.get_data())
+        response.headers[response_cache.CACHE_HEADER] = "miss"
+    return response
+
+
+@app.route("/explain", methods=["POST"])
+def explain():
+    predictor = local_cache["predictor"]
+    variant = None
+
+    if local_cache["variant_assigner"] is not None:
+        g.variant = local_cache["variant_assigner"].assign(request.headers)
+        variant = g.variant
+        predictor = local_cache["predictors"][variant]
+
+    predict_fn = predictor.predict
+    if local_cache["batchers"].get(variant) is not None:
+        predict_fn = local_cache["batchers"][variant].predict
+
+    return explainers.explain_request(
+        local_cache["api"], local_cache["explainers"].get(variant), predict_fn, request
+    )
+
+
+@app.route("/feedback", methods=["POST"])
+def record_feedback():
+    return feedback.feedback_request(
+        local_cache["ctx"], local_cache["api"], local_cache["feedback_recorder"], request
+    )
 
 
 @app.route("/predict", methods=["GET"])
@@ -112,12 +244,89 @@ def exceptions(e):
     return jsonify(error=str(e)), 500
 
 
-def start(args):
+_generator_exhausted = object()
+
+
+def websocket_variant_headers(path, request_headers):
+    if local_cache["variant_assigner"] is None:
+        return {}
+    return {api_utils.VARIANT_HEADER: local_cache["variant_assigner"].assign(request_headers)}
+
+
+async def websocket_predict(websocket, path):
+    api = local_cache["api"]
+    predictor = local_cache["predictor"]
+    if local_cache["variant_assigner"] is not None:
+        # the variant is assigned once per connection
+        variant = local_cache["variant_assigner"].assign(websocket.request_headers)
+        predictor = local_cache["predictors"][variant]
+
+    # the forwarded headers are the connection's headers, so the metadata is the same for each message
+    try:
+        metadata = api_utils.forwarded_metadata(api, websocket.request_headers)
+    except ValueError as e:
+        await websocket.send(json.dumps({"error": str(e)}))
+        return
+
+    loop = asyncio.get_event_loop()
+
+    async for message in websocket:
+        try:
+            payload = json.loads(message)
+        except:
+            await websocket.send(json.dumps({"error": "malformed json"}))
+            continue
+
+        try:
+            try:
+                # the predictor runs in a thread so that other connections aren't blocked
+                output = await loop.run_in_executor(
+                    None, api_utils.call_predict, predictor.predict, payload, metadata
+                )
+                if inspect.isgenerator(output):
+                    # each yielded value is sent as its own message, and an empty message marks the end
+                    while True:
+                        item = await loop.run_in_executor(None, next, output, _generator_exhausted)
+                        if item is _generator_exhausted:
+                            break
+                        await websocket.send(json.dumps(item, cls=util.json_tricks_encoder))
+                    await websocket.send("")
+                else:
+                    await websocket.send(json.dumps(output, cls=util.json_tricks_encoder))
+            except websockets.exceptions.ConnectionClosed:
+                return
+            except Exception as e:
+                raise UserRuntimeException(api["predictor"]["path"], "predict", str(e)) from e
+        except Exception as e:
+            cx_logger().exception("prediction failed")
+            await websocket.send(json.dumps({"error": "prediction failed: {}".format(str(e))}))
+
+
+def serve_websocket(api, sock):
+    server = websockets.serve(
+        websocket_predict,
+        sock=sock,
+        max_size=api["networking"]["max_request_size"],
+        ping_interval=None,  # idle connections are closed by the load balancer (networking.idle_timeout)
+        extra_headers=websocket_variant_headers,
+    )
+    loop = asyncio.get_event_loop()
+    loop.run_until_complete(server)
+    loop.run_forever()
+
+
+def start_process(args, sock, health_check_file):
     api = None
     try:
         ctx = Context(s3_path=args.context, cache_dir=args.cache_dir, workload_id=args.workload_id)
         api = ctx.apis_id_map[args.api]
         local_cache["api"] = api
+        local_cache["rate_limiter"] = api_utils.get_rate_limiter(api)
+        local_cache["tracer"] = tracing.get_tracer(api)
+        local_cache["prediction_logger"] = prediction_logging.get_prediction_logger(api)
+        local_cache["drift_recorder"] = data_drift.get_drift_recorder(ctx, api)
+        local_cache["feedback_recorder"] = feedback.get_feedback_recorder(ctx, api)
+        local_cache["response_cache"] = response_cache.get_response_cache(api)
         local_cache["ctx"] = ctx
 
         if api["predictor"]["type"] != "python":
@@ -125,9 +334,33 @@ def start(args):
 
         cx_logger().info("loading the predictor from {}".format(api["predictor"]["path"]))
         predictor_class = ctx.get_predictor_class(api["name"], args.project_dir)
+        predictor_config = api_utils.predictor_config(api)
 
         try:
-            local_cache["predictor"] = predictor_class(api["predictor"]["config"])
+            if api.get("experiment") is not None:
+                local_cache["variant_assigner"] = api_utils.get_variant_assigner(api)
+                for variant, config in api_utils.variant_configs(api).items():
+                    cx_logger().info("initializing the {} variant".format(variant))
+                    config = api_utils.resolve_config_secrets(config)
+                    local_cache["predictors"][variant] = predictor_class(config)
+                    local_cache["batchers"][variant] = api_utils.get_batcher(
+                        api, local_cache["predictors"][variant].predict
+                    )
+                    local_cache["explainers"][variant] = explainers.get_explainer(
+                        ctx, api, local_cache["predictors"][variant], args.project_dir
+                    )
+                local_cache["predictor"] = local_cache["predictors"][
+                    local_cache["variant_assigner"].buckets[0][1]
+                ]
+            else:
+                local_cache["predictor"] = predictor_class(predictor_config)
+                local_cache["batchers"][None] = api_utils.get_batcher(
+                    api, local_cache["predictor"].predict
+                )
+                local_cache["explainers"][None] = explainers.get_explainer(
+                    ctx, api, local_cache["predictor"], args.project_dir
+                )
+                api_utils.start_config_reloader(api, local_cache, predictor_class)
         except Exception as e:
             raise UserRuntimeException(api["predictor"]["path"], "__init__", str(e)) from e
         finally:
@@ -142,6 +375,12 @@ def start(args):
         except Exception as e:
             cx_logger().warn("an error occurred while attempting to load classes", exc_info=True)
 
+    if api["networking"]["protocol"] == "websocket":
+        cx_logger().info("{} api is live".format(api["name"]))
+        open(health_check_file, "a").close()
+        serve_websocket(api, sock)
+        return
+
     waitress_kwargs = {}
     if api["predictor"].get("config") is not None:
         for key, value in api["predictor"]["config"].items():
@@ -151,13 +390,26 @@ def start(args):
     if len(waitress_kwargs) > 0:
         cx_logger().info("waitress parameters: {}".format(waitress_kwargs))
 
-    waitress_kwargs["listen"] = "*:{}".format(args.port)
+    # the waitress_threads config takes precedence over predictor.threads_per_process
+    waitress_kwargs.setdefault("threads", args.threads_per_process)
+    api_utils.configure_request_size(app, api, waitress_kwargs)
+    api_utils.configure_batching_threads(api, waitress_kwargs)
+    local_cache["load_shedder"] = api_utils.configure_load_shedding(api, waitress_kwargs)
+    waitress_kwargs["sockets"] = [sock]
 
     cx_logger().info("{} api is live".format(api["name"]))
-    open("/health_check.txt", "a").close()
+    open(health_check_file, "a").close()
     serve(app, **waitress_kwargs)
 
 
+def start(args):
+    api_utils.serve_processes(
+        args.port,
+        args.processes_per_replica,
+        lambda sock, health_check_file: start_process(args, sock, health_check_file),
+    )
+
+
 def main():
     parser = argparse.ArgumentParser()
     na = parser.add_argument_group("required named arguments")
@@ -171,6 +423,12 @@ def main():
     na.add_argument("--api", required=True, help="resource id of api to serve")
     na.add_argument("--cache-dir", required=True, help="local path for the context cache")
     na.add_argument("--project-dir", required=True, help="local path for the project zip file")
+    na.add_argument(
+        "--processes-per-replica", type=int, required=True, help="number of serving processes"
+    )
+    na.add_argument(
+        "--threads-per-process", type=int, required=True, help="number of threads per process"
+    )
 
     parser.set_defaults(func=start)
 
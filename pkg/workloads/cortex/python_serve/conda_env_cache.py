@@ -0,0 +1,53 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import argparse
+import sys
+
+from cortex.lib.storage import S3
+from cortex.lib.log import cx_logger
+
+
+def download(args):
+    bucket_name, key = S3.deconstruct_s3_path(args.s3_path)
+    s3_client = S3(bucket_name, client_config={})
+    if not s3_client._file_exists(key):
+        return 1
+    cx_logger().info("downloading cached conda environment")
+    s3_client.download_file(key, args.local_path)
+    return 0
+
+
+def upload(args):
+    bucket_name, key = S3.deconstruct_s3_path(args.s3_path)
+    s3_client = S3(bucket_name, client_config={})
+    cx_logger().info("caching conda environment")
+    s3_client.upload_file(args.local_path, key)
+    return 0
+
+
+def main():
+    parser = argparse.ArgumentParser()
+    parser.add_argument("action", choices=["download", "upload"])
+    parser.add_argument("s3_path", help="the s3 path of the cached conda environment archive")
+    parser.add_argument("local_path", help="the local path of the conda environment archive")
+    args = parser.parse_args()
+
+    if args.action == "download":
+        sys.exit(download(args))
+    sys.exit(upload(args))
+
+
+if __name__ == "__main__":
+    main()
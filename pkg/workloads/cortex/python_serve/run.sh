@@ -17,7 +17,33 @@
 
 export PYTHONPATH=$PYTHONPATH:$PYTHON_PATH
 
+python_bin=$CORTEX_PYTHON_BIN
+
+# conda images: restore the project's conda environment from the cache, or build and cache it
+if [ -d "/opt/conda" ]; then
+    python_bin=/opt/conda/bin/python
+    conda_env_archive=/tmp/conda_env.tar.gz
+
+    if [ -n "$CORTEX_CONDA_ENV_CACHE" ] && $python_bin /src/cortex/python_serve/conda_env_cache.py download "$CORTEX_CONDA_ENV_CACHE" $conda_env_archive; then
+        tar -xzf $conda_env_archive -C /opt/conda && rm $conda_env_archive
+    else
+        if [ -f "/mnt/project/conda-packages.txt" ]; then
+            /opt/conda/bin/conda install --yes --file /mnt/project/conda-packages.txt
+        fi
+        if [ -f "/mnt/project/environment.yml" ]; then
+            /opt/conda/bin/conda env update --name base --file /mnt/project/environment.yml
+        fi
+        /opt/conda/bin/conda clean --all --yes
+
+        if [ -n "$CORTEX_CONDA_ENV_CACHE" ]; then
+            tar -czf $conda_env_archive -C /opt/conda .
+            $python_bin /src/cortex/python_serve/conda_env_cache.py upload "$CORTEX_CONDA_ENV_CACHE" $conda_env_archive
+            rm -f $conda_env_archive
+        fi
+    fi
+fi
+
 if [ -f "/mnt/project/requirements.txt" ]; then
-    pip --no-cache-dir install -r /mnt/project/requirements.txt
+    $python_bin -m pip --no-cache-dir install -r /mnt/project/requirements.txt
 fi
-/usr/bin/python3.6 /src/cortex/python_serve/api.py "$@"
+$python_bin /src/cortex/python_serve/api.py "$@"
@@ -0,0 +1,321 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import sys
+import argparse
+import time
+
+from flask import Flask, request, jsonify, g
+from flask_api import status
+from waitress import serve
+
+from cortex.lib import (
+    util,
+    Context,
+    api_utils,
+    tracing,
+    prediction_logging,
+    data_drift,
+    response_cache,
+    explainers,
+    feedback,
+)
+from cortex.lib.log import cx_logger, debug_obj, refresh_logger
+from cortex.lib.exceptions import UserRuntimeException, CortexException
+from cortex.triton_api.client import TritonClient
+
+app = Flask(__name__)
+app.json_encoder = util.json_tricks_encoder
+
+
+local_cache = {
+    "ctx": None,
+    "api": None,
+    "rate_limiter": None,
+    "load_shedder": None,
+    "tracer": None,
+    "prediction_logger": None,
+    "drift_recorder": None,
+    "feedback_recorder": None,
+    "response_cache": None,
+    "explainer": None,
+    "client": None,
+    "class_set": set(),
+}
+
+
+@app.before_request
+def before_request():
+    g.start_time = time.time()
+
+    if api_utils.is_prediction_request(request):
+        g.span = tracing.start_request_span(local_cache["tracer"], request)
+        response = api_utils.rate_limit_request(
+            local_cache["api"], local_cache["rate_limiter"], request
+        )
+        if response is None:
+            response = api_utils.shed_request(
+                local_cache["api"], local_cache["load_shedder"], request
+            )
+        return response
+
+
+@app.teardown_request
+def teardown_request(exception):
+    api_utils.release_request(local_cache["load_shedder"])
+
+
+@app.after_request
+def after_request(response):
+    if not api_utils.is_cors_configured(local_cache["api"]):
+        response.headers["Access-Control-Allow-Origin"] = "*"
+        response.headers["Access-Control-Allow-Headers"] = request.headers.get(
+            "Access-Control-Request-Headers", "*"
+        )
+
+    if "span" in g:
+        tracing.finish_request_span(g.span, response)
+
+    if not (request.path == "/predict" and request.method == "POST"):
+        return response
+
+    api = local_cache["api"]
+    ctx = local_cache["ctx"]
+
+    cx_logger().info(response.status)
+
+    prediction = None
+    if "prediction" in g:
+        prediction = g.prediction
+
+    api_utils.post_request_metrics(
+        ctx, api, response, prediction, g.start_time, local_cache["class_set"]
+    )
+
+    prediction_id = None
+    if local_cache["feedback_recorder"] is not None and request.method == "POST":
+        prediction_id = feedback.set_prediction_id(api, response, prediction, g.get("variant"))
+
+    if local_cache["prediction_logger"] is not None and "payload" in g:
+        local_cache["prediction_logger"].log(
+            g.payload,
+            prediction,
+            response.status_code,
+            g.start_time,
+            variant=g.get("variant"),
+            prediction_id=prediction_id,
+            trace_id=g.span.trace_id if "span" in g else None,
+        )
+
+    if local_cache["drift_recorder"] is not None and "payload" in g and prediction is not None:
+        local_cache["drift_recorder"].record(g.payload, prediction)
+
+    return api_utils.compress_response(api, request, response)
+
+
+@app.route("/predict", methods=["POST"])
+def predict():
+    debug = request.args.get("debug", "false").lower() == "true"
+
+    try:
+        payload = request.get_json()
+    except:
+        return "malformed json", status.HTTP_400_BAD_REQUEST
+    g.payload = payload
+
+    api = local_cache["api"]
+    predictor = local_cache["predictor"]
+
+    try:
+        metadata = api_utils.forwarded_metadata(api, request.headers)
+    except ValueError as e:
+        return str(e), status.HTTP_400_BAD_REQUEST
+
+    # debug requests are always predicted, so that their payloads and predictions are logged
+    cache_key = None
+    if local_cache["response_cache"] is not None and not debug:
+        cache_key = local_cache["response_cache"].key(request.get_data(), request.headers, None)
+        cached = local_cache["response_cache"].get(cache_key)
+        if cached is not None:
+            return response_cache.cached_response(cached)
+
+    try:
+        debug_obj("payload", payload, debug)
+        try:
+            output = api_utils.call_predict(predictor.predict, payload, metadata)
+        except Exception as e:
+            raise UserRuntimeException(api["predictor"]["path"], "predict", str(e)) from e
+        debug_obj("prediction", output, debug)
+    except Exception as e:
+        cx_logger().exception("prediction failed")
+        return prediction_failed(str(e))
+
+    g.prediction = output
+    response = jsonify(output)
+    if cache_key is not None:
+        local_cache["response_cache"].set(cache_key, response.get_data())
+        response.headers[response_cache.CACHE_HEADER] = "miss"
+    return response
+
+
+def prediction_failed(reason):
+    message = "prediction failed: {}".format(reason)
+    cx_logger().error(message)
+    return message, status.HTTP_406_NOT_ACCEPTABLE
+
+
+@app.route("/explain", methods=["POST"])
+def explain():
+    return explainers.explain_request(
+        local_cache["api"], local_cache["explainer"], local_cache["predictor"].predict, request
+    )
+
+
+@app.route("/feedback", methods=["POST"])
+def record_feedback():
+    return feedback.feedback_request(
+        local_cache["ctx"], local_cache["api"], local_cache["feedback_recorder"], request
+    )
+
+
+@app.route("/predict", methods=["GET"])
+def get_summary():
+    response = {
+        "model_signatures": local_cache["client"].input_signatures,
+        "message": api_utils.API_SUMMARY_MESSAGE,
+    }
+    return jsonify(response)
+
+
+@app.errorhandler(Exception)
+def exceptions(e):
+    cx_logger().exception(e)
+    return jsonify(error=str(e)), 500
+
+
+def start_process(args, sock, health_check_file):
+    api = None
+    try:
+        ctx = Context(s3_path=args.context, cache_dir=args.cache_dir, workload_id=args.workload_id)
+        api = ctx.apis_id_map[args.api]
+        local_cache["api"] = api
+        local_cache["rate_limiter"] = api_utils.get_rate_limiter(api)
+        local_cache["tracer"] = tracing.get_tracer(api)
+        local_cache["prediction_logger"] = prediction_logging.get_prediction_logger(api)
+        local_cache["drift_recorder"] = data_drift.get_drift_recorder(ctx, api)
+        local_cache["feedback_recorder"] = feedback.get_feedback_recorder(ctx, api)
+        local_cache["response_cache"] = response_cache.get_response_cache(api)
+        local_cache["ctx"] = ctx
+
+        if api["predictor"]["type"] != "triton":
+            raise CortexException(api["name"], "predictor type is not triton")
+
+        local_cache["client"] = TritonClient("localhost:" + str(args.triton_http_port))
+
+        cx_logger().info("loading the predictor from {}".format(api["predictor"]["path"]))
+
+        predictor_class = ctx.get_predictor_class(api["name"], args.project_dir)
+        predictor_config = api_utils.predictor_config(api)
+
+        try:
+            local_cache["predictor"] = predictor_class(local_cache["client"], predictor_config)
+            api_utils.start_config_reloader(
+                api, local_cache, lambda config: predictor_class(local_cache["client"], config)
+            )
+        except Exception as e:
+            raise UserRuntimeException(api["predictor"]["path"], "__init__", str(e)) from e
+        finally:
+            refresh_logger()
+
+        local_cache["explainer"] = explainers.get_explainer(
+            ctx, api, local_cache["predictor"], args.project_dir
+        )
+
+    except Exception as e:
+        cx_logger().exception("failed to start api")
+        sys.exit(1)
+
+    if api.get("tracker") is not None and api["tracker"].get("model_type") == "classification":
+        try:
+            local_cache["class_set"] = api_utils.get_classes(ctx, api["name"])
+        except Exception as e:
+            cx_logger().warn("an error occurred while attempting to load classes", exc_info=True)
+
+    for model_name, signature in local_cache["client"].input_signatures.items():
+        cx_logger().info("{} model signature: {}".format(model_name, signature))
+
+    waitress_kwargs = {}
+    if api["predictor"].get("config") is not None:
+        for key, value in api["predictor"]["config"].items():
+            if key.startswith("waitress_"):
+                waitress_kwargs[key[len("waitress_") :]] = value
+
+    if len(waitress_kwargs) > 0:
+        cx_logger().info("waitress parameters: {}".format(waitress_kwargs))
+
+    # the waitress_threads config takes precedence over predictor.threads_per_process
+    waitress_kwargs.setdefault("threads", args.threads_per_process)
+    api_utils.configure_request_size(app, api, waitress_kwargs)
+    local_cache["load_shedder"] = api_utils.configure_load_shedding(api, waitress_kwargs)
+    waitress_kwargs["sockets"] = [sock]
+
+    cx_logger().info("{} api is live".format(api["name"]))
+    open(health_check_file, "a").close()
+    serve(app, **waitress_kwargs)
+
+
+def start(args):
+    api_utils.serve_processes(
+        args.port,
+        args.processes_per_replica,
+        lambda sock, health_check_file: start_process(args, sock, health_check_file),
+    )
+
+
+def main():
+    parser = argparse.ArgumentParser()
+    na = parser.add_argument_group("required named arguments")
+    na.add_argument("--workload-id", required=True, help="workload id")
+    na.add_argument("--port", type=int, required=True, help="port (on localhost) to use")
+    na.add_argument(
+        "--triton-http-port",
+        type=int,
+        required=True,
+        help="port (on localhost) where triton serves http requests",
+    )
+    na.add_argument(
+        "--context",
+        required=True,
+        help="s3 path to context (e.g. s3://bucket/path/to/context.json)",
+    )
+    na.add_argument("--api", required=True, help="resource id of api to serve")
+    na.add_argument(
+        "--model-dir", required=True, help="directory to download the model repository to"
+    )
+    na.add_argument("--cache-dir", required=True, help="local path for the context cache")
+    na.add_argument("--project-dir", required=True, help="local path for the project zip file")
+    na.add_argument(
+        "--processes-per-replica", type=int, required=True, help="number of serving processes"
+    )
+    na.add_argument(
+        "--threads-per-process", type=int, required=True, help="number of threads per process"
+    )
+    parser.set_defaults(func=start)
+
+    args = parser.parse_args()
+    args.func(args)
+
+
+if __name__ == "__main__":
+    main()
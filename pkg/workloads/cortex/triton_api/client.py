@@ -0,0 +1,205 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import time
+
+import numpy as np
+import requests
+
+from cortex.lib.exceptions import UserException, CortexException
+from cortex.lib.log import cx_logger
+
+
+class TritonClient:
+    def __init__(self, triton_url):
+        """Setup a connection to the Triton Inference Server container's HTTP endpoint.
+
+        Args:
+            triton_url (string): Localhost URL to the Triton container (e.g. localhost:8000).
+        """
+        self._base_url = "http://" + triton_url
+        self._session = requests.Session()
+        wait_until_ready(self._session, self._base_url)
+        self._models = get_models(self._session, self._base_url)
+        self._input_signatures = {
+            model_name: parse_signature(metadata) for model_name, metadata in self._models.items()
+        }
+
+    def predict(self, model_name, payload, version=None, outputs=None):
+        """Validate payload, convert it to a Triton inference request, and make a request to Triton.
+
+        Args:
+            model_name (string): Name of one of the models in the model repository.
+            payload (dict): Input name to value (a nested list which is reshaped to the input's shape, or a numpy array).
+            version (string, optional): Version of the model to use (defaults to the version which Triton serves by default).
+            outputs (list, optional): Names of the outputs to return (defaults to all outputs).
+
+        Returns:
+            dict: Output name to a nested list of the output's values.
+        """
+        if model_name not in self._models:
+            raise UserException(
+                'model "{}" is not in the model repository (available models: {})'.format(
+                    model_name, ", ".join(sorted(self._models.keys()))
+                )
+            )
+
+        input_signature = self._input_signatures[model_name]
+        validate_payload(input_signature, payload)
+
+        request_body = {"inputs": create_inputs(input_signature, payload)}
+        if outputs is not None:
+            request_body["outputs"] = [{"name": output_name} for output_name in outputs]
+
+        url = "{}/v2/models/{}".format(self._base_url, model_name)
+        if version is not None:
+            url += "/versions/{}".format(version)
+        response = self._session.post(url + "/infer", json=request_body, timeout=300.0)
+        if response.status_code != 200:
+            raise UserException(
+                'model "{}"'.format(model_name),
+                parse_error(response),
+                "status {}".format(response.status_code),
+            )
+
+        return parse_outputs(response.json())
+
+    @property
+    def models(self):
+        return sorted(self._models.keys())
+
+    @property
+    def input_signatures(self):
+        return self._input_signatures
+
+    def input_signature(self, model_name):
+        return self._input_signatures[model_name]
+
+
+# Triton's data types, and the numpy types which are used to build inputs of each type
+DATATYPE_TO_NP_TYPE = {
+    "BOOL": np.bool_,
+    "UINT8": np.uint8,
+    "UINT16": np.uint16,
+    "UINT32": np.uint32,
+    "UINT64": np.uint64,
+    "INT8": np.int8,
+    "INT16": np.int16,
+    "INT32": np.int32,
+    "INT64": np.int64,
+    "FP16": np.float16,
+    "FP32": np.float32,
+    "FP64": np.float64,
+    "BYTES": np.object_,
+}
+
+
+def wait_until_ready(session, base_url):
+    limit = 60
+    for i in range(limit):
+        try:
+            if session.get(base_url + "/v2/health/ready", timeout=10.0).status_code == 200:
+                return
+        except:
+            pass
+
+        if i > 6:
+            cx_logger().warn("unable to reach triton - models are still loading, retrying...")
+        time.sleep(5)
+
+    raise CortexException("timeout: triton is not ready")
+
+
+def get_models(session, base_url):
+    response = session.post(base_url + "/v2/repository/index", json={"ready": True}, timeout=10.0)
+    if response.status_code != 200:
+        raise CortexException("unable to list the models served by triton", parse_error(response))
+
+    models = {}
+    for model in response.json():
+        model_name = model["name"]
+        if model_name in models:
+            continue  # the index has an entry per version
+        metadata = session.get("{}/v2/models/{}".format(base_url, model_name), timeout=10.0)
+        if metadata.status_code != 200:
+            raise UserException('model "{}"'.format(model_name), parse_error(metadata))
+        models[model_name] = metadata.json()
+
+    if len(models) == 0:
+        raise UserException("triton didn't load any of the models in the model repository")
+
+    return models
+
+
+def parse_signature(metadata):
+    parsed_signature = {}
+    for model_input in metadata.get("inputs", []):
+        parsed_signature[model_input["name"]] = {
+            "shape": [int(dim) for dim in model_input["shape"]],
+            "type": model_input["datatype"],
+        }
+    return parsed_signature
+
+
+def create_inputs(input_signature, payload):
+    inputs = []
+    for input_name, value in payload.items():
+        signature = input_signature[input_name]
+        try:
+            value = np.asarray(value, dtype=DATATYPE_TO_NP_TYPE[signature["type"]])
+        except Exception as e:
+            raise UserException(
+                'key "{}"'.format(input_name), "expected type {}".format(signature["type"]), str(e)
+            ) from e
+
+        inputs.append(
+            {
+                "name": input_name,
+                "shape": list(value.shape),
+                "datatype": signature["type"],
+                "data": value.flatten().tolist(),
+            }
+        )
+    return inputs
+
+
+def parse_outputs(response_body):
+    outputs = {}
+    for output in response_body.get("outputs", []):
+        outputs[output["name"]] = np.asarray(output["data"]).reshape(output["shape"]).tolist()
+    return outputs
+
+
+def parse_error(response):
+    try:
+        return response.json()["error"]
+    except:
+        return response.text
+
+
+def validate_payload(input_signature, payload):
+    if not isinstance(payload, dict):
+        raise UserException("the payload must be a dictionary of input names to values")
+
+    for input_name in input_signature:
+        if input_name not in payload:
+            raise UserException('missing key "{}"'.format(input_name))
+
+    for input_name in payload:
+        if input_name not in input_signature:
+            raise UserException(
+                'unexpected key "{}" (expected keys: {})'.format(
+                    input_name, ", ".join(sorted(input_signature.keys()))
+                )
+            )